@@ -0,0 +1,98 @@
+// The API for arrays which are indexed by variables, i.e. at positions which are only determined
+// when the circuit is solved.
+//
+// Small arrays are accessed with a multiplexer, which costs O(n) constraints per access. If the
+// circuit supports commitments, large arrays are accessed with a lookup table based on a
+// log-derivative argument, which costs O(n) constraints once for the table plus O(1) constraints
+// per access, so it pays off as soon as an array is read more than a few times.
+package array
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/selector"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum length of an array whose accesses use a multiplexer instead of a lookup table.
+const MaxMuxLength = 32
+
+// An array of variables which supports reads and writes at variable indices. Reading an index out
+// of range makes the circuit unsatisfiable, and so does writing one.
+type Array struct {
+	api    builder.API
+	values []vars.Variable
+
+	// The lookup table of the values, which is created by the first read of a large array. Since
+	// a table is immutable, a write discards it and the next read creates a new one.
+	table *logderivlookup.Table
+}
+
+// Creates a new array with the given values.
+func NewArray(api builder.API, values []vars.Variable) *Array {
+	copied := make([]vars.Variable, len(values))
+	copy(copied, values)
+	return &Array{api: api, values: copied}
+}
+
+// Creates a new array with the given bytes.
+func NewByteArray(api builder.API, bytes []vars.Byte) *Array {
+	values := make([]vars.Variable, len(bytes))
+	for i := range bytes {
+		values[i] = bytes[i].Value
+	}
+	return &Array{api: api, values: values}
+}
+
+// Returns the length of the array.
+func (arr *Array) Len() int {
+	return len(arr.values)
+}
+
+// Returns the current values of the array.
+func (arr *Array) Values() []vars.Variable {
+	values := make([]vars.Variable, len(arr.values))
+	copy(values, arr.values)
+	return values
+}
+
+// Returns the value at the index, which must be in [0, Len()).
+func (arr *Array) Get(index vars.Variable) vars.Variable {
+	if len(arr.values) == 0 {
+		panic("reading from an empty array")
+	}
+	api := arr.api.FrontendAPI()
+	if len(arr.values) <= MaxMuxLength || !arr.api.SupportsCommitments() {
+		inputs := make([]frontend.Variable, len(arr.values))
+		for i := range arr.values {
+			inputs[i] = arr.values[i].Value
+		}
+		return vars.Variable{Value: selector.Mux(api, index.Value, inputs...)}
+	}
+	if arr.table == nil {
+		arr.table = logderivlookup.New(api)
+		for i := range arr.values {
+			arr.table.Insert(arr.values[i].Value)
+		}
+	}
+	return vars.Variable{Value: arr.table.Lookup(index.Value)[0]}
+}
+
+// Returns the byte at the index, which must be in [0, Len()). The array must only contain bytes.
+func (arr *Array) GetByte(index vars.Variable) vars.Byte {
+	return vars.Byte{Value: arr.Get(index)}
+}
+
+// Sets the value at the index, which must be in [0, Len()). A write always costs O(n)
+// constraints, since every value may change.
+func (arr *Array) Set(index vars.Variable, value vars.Variable) {
+	nbSelected := vars.ZERO
+	for i := range arr.values {
+		isSelected := arr.api.IsZero(arr.api.Sub(index, vars.NewVariableFromInt(i)))
+		arr.values[i] = arr.api.Select(isSelected, value, arr.values[i])
+		nbSelected = arr.api.Add(nbSelected, isSelected.Value)
+	}
+	arr.api.AssertIsEqual(nbSelected, vars.ONE)
+	arr.table = nil
+}
@@ -0,0 +1,87 @@
+package array
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestArrayCircuit struct {
+	Values   []vars.Variable
+	Indices  []vars.Variable
+	Expected []vars.Variable
+	// A write of Value at WriteIndex, after which the value at Indices[0] is ExpectedAfterWrite.
+	WriteIndex         vars.Variable
+	Value              vars.Variable
+	ExpectedAfterWrite vars.Variable
+}
+
+func (circuit *TestArrayCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	arr := NewArray(*succinctAPI, circuit.Values)
+	for i := range circuit.Indices {
+		succinctAPI.AssertIsEqual(arr.Get(circuit.Indices[i]), circuit.Expected[i])
+	}
+	arr.Set(circuit.WriteIndex, circuit.Value)
+	succinctAPI.AssertIsEqual(arr.Get(circuit.Indices[0]), circuit.ExpectedAfterWrite)
+	return nil
+}
+
+func newTestCircuit(n int, indices []int, writeIndex int) *TestArrayCircuit {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i*i*7 + 3
+	}
+	circuit := &TestArrayCircuit{
+		WriteIndex: vars.NewVariableFromInt(writeIndex),
+		Value:      vars.NewVariableFromInt(1000000),
+	}
+	for i := range values {
+		circuit.Values = append(circuit.Values, vars.NewVariableFromInt(values[i]))
+	}
+	for _, index := range indices {
+		circuit.Indices = append(circuit.Indices, vars.NewVariableFromInt(index))
+		if index < n {
+			circuit.Expected = append(circuit.Expected, vars.NewVariableFromInt(values[index]))
+		} else {
+			circuit.Expected = append(circuit.Expected, vars.ZERO)
+		}
+	}
+	if writeIndex < n {
+		values[writeIndex] = 1000000
+	}
+	if indices[0] < n {
+		circuit.ExpectedAfterWrite = vars.NewVariableFromInt(values[indices[0]])
+	} else {
+		circuit.ExpectedAfterWrite = vars.ZERO
+	}
+	return circuit
+}
+
+func TestArray(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Both a small array which uses a multiplexer and a large one which uses a lookup table.
+	for _, n := range []int{5, MaxMuxLength, 100} {
+		circuit := newTestCircuit(n, []int{n - 1, 0, 2, n / 2}, n-1)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "n = %d", n)
+
+		circuit = newTestCircuit(n, []int{1, 0, 2, n / 2}, n-1)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "n = %d", n)
+
+		// A wrong value is rejected.
+		circuit = newTestCircuit(n, []int{1, 0, 2, n / 2}, 0)
+		circuit.Expected[2] = vars.NewVariableFromInt(1)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "n = %d", n)
+
+		// Reads and writes out of range are rejected.
+		circuit = newTestCircuit(n, []int{1, n, 2, n / 2}, 0)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "n = %d", n)
+		circuit = newTestCircuit(n, []int{1, 0, 2, n / 2}, n)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "n = %d", n)
+	}
+}
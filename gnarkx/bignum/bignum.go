@@ -0,0 +1,376 @@
+// The API for arithmetic on big non-negative integers modulo a variable modulus, e.g. for
+// verifying RSA signatures or computing the MODEXP precompile of EIP-198. Unlike the emulated
+// fields of nonnative, the modulus is not fixed at compile time of the circuit but only its
+// number of limbs.
+//
+// An integer is represented by limbs of LimbBits bits, least significant limb first. A product is
+// checked by computing the quotient and the remainder with a hint and asserting that
+// x * y - q * m - r is zero as a polynomial in 2^LimbBits, whose coefficients are carried with
+// hinted and range checked carries.
+package bignum
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bits of a limb, which is a whole number of bytes so that conversions from and to
+// bytes are free of range checks.
+const LimbBits = 120
+
+// The number of bytes of a limb.
+const LimbBytes = LimbBits / 8
+
+// The maximum number of limbs of an integer, which bounds the coefficients of products so that
+// they do not overflow the field. This allows moduli of up to 7680 bits.
+const MaxLimbs = 64
+
+// A non-negative integer as limbs of LimbBits bits, least significant limb first.
+type Int struct {
+	Limbs []vars.Variable
+}
+
+// Returns the number of limbs needed for integers of nbBits bits.
+func NbLimbs(nbBits int) int {
+	return (nbBits + LimbBits - 1) / LimbBits
+}
+
+// Creates a new Int with nbLimbs limbs, e.g. as a placeholder for a circuit.
+func NewInt(nbLimbs int) Int {
+	limbs := make([]vars.Variable, nbLimbs)
+	for i := range limbs {
+		limbs[i] = vars.NewVariable()
+	}
+	return Int{Limbs: limbs}
+}
+
+// Creates a new Int with nbLimbs limbs assigned to the value, which must fit into them.
+func NewIntFrom(value *big.Int, nbLimbs int) Int {
+	if value.Sign() < 0 || value.BitLen() > nbLimbs*LimbBits {
+		panic(fmt.Sprintf("value does not fit into %d limbs", nbLimbs))
+	}
+	limbs := make([]vars.Variable, nbLimbs)
+	for i, limb := range split(value, nbLimbs) {
+		limbs[i] = vars.Variable{Value: limb}
+	}
+	return Int{Limbs: limbs}
+}
+
+// Returns the number of limbs of the integer.
+func (x Int) NbLimbs() int {
+	return len(x.Limbs)
+}
+
+// An API used for arithmetic on big integers.
+type API struct {
+	api builder.API
+}
+
+// Creates a new bignum.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api}
+}
+
+// Converts big-endian bytes to an integer of NbLimbs(8 * len(in)) limbs. The bytes are assumed to
+// be range checked, so the conversion is free.
+func (a *API) FromBytesBE(in []vars.Byte) Int {
+	nbLimbs := NbLimbs(8 * len(in))
+	limbs := make([]vars.Variable, nbLimbs)
+	for i := range limbs {
+		limb := vars.ZERO
+		for j := LimbBytes - 1; j >= 0; j-- {
+			k := len(in) - 1 - (i*LimbBytes + j)
+			if k < 0 {
+				continue
+			}
+			limb = a.api.Add(a.api.Mul(limb, vars.NewVariableFromInt(256)), in[k].Value)
+		}
+		limbs[i] = limb
+	}
+	return Int{Limbs: limbs}
+}
+
+// Converts an integer to nbBytes big-endian bytes, asserting that it fits into them.
+func (a *API) ToBytesBE(x Int, nbBytes int) []vars.Byte {
+	out := make([]vars.Byte, 0, x.NbLimbs()*LimbBytes)
+	for i := range x.Limbs {
+		out = append(out, a.api.ToBytesLE(x.Limbs[i], LimbBytes)...)
+	}
+	for i := nbBytes; i < len(out); i++ {
+		a.api.AssertIsEqual(out[i].Value, vars.ZERO)
+	}
+	for len(out) < nbBytes {
+		out = append(out, vars.ZERO_BYTE)
+	}
+	out = out[:nbBytes]
+	for i := 0; i < nbBytes/2; i++ {
+		out[i], out[nbBytes-1-i] = out[nbBytes-1-i], out[i]
+	}
+	return out
+}
+
+// Asserts that the limbs of the integer are in [0, 2^LimbBits).
+func (a *API) AssertIsNormalized(x Int) {
+	for i := range x.Limbs {
+		a.api.AssertIsInRange(x.Limbs[i], LimbBits)
+	}
+}
+
+// Asserts that the integers, whose limbs must be normalized, are equal.
+func (a *API) AssertIsEqual(x, y Int) {
+	for i := 0; i < x.NbLimbs() || i < y.NbLimbs(); i++ {
+		a.api.AssertIsEqual(limb(x, i), limb(y, i))
+	}
+}
+
+// Asserts that x < y, where the limbs of both must be normalized.
+func (a *API) AssertIsLess(x, y Int) {
+	nbLimbs := x.NbLimbs()
+	if y.NbLimbs() > nbLimbs {
+		nbLimbs = y.NbLimbs()
+	}
+	// Compute d = y - x - 1 with a hint and assert that it is a normalized integer.
+	inputs := append(append([]vars.Variable{}, padded(x, nbLimbs).Limbs...), padded(y, nbLimbs).Limbs...)
+	d := Int{Limbs: a.api.Hint(differenceHint, nbLimbs, inputs...)}
+	a.AssertIsNormalized(d)
+	coefficients := make([]vars.Variable, nbLimbs)
+	for i := range coefficients {
+		coefficients[i] = a.api.Sub(limb(y, i), limb(x, i), limb(d, i))
+	}
+	coefficients[0] = a.api.Sub(coefficients[0], vars.ONE)
+	a.assertIsZero(coefficients, LimbBits+2)
+}
+
+// Computes x mod m, where the limbs of x and m must be normalized and m must be positive. The
+// result has the limbs of m.
+func (a *API) Reduce(x Int, m Int) Int {
+	one := NewIntFrom(big.NewInt(1), 1)
+	return a.mulMod(x, one, m, x.NbLimbs())
+}
+
+// Computes x * y mod m, where the limbs of x, y and m must be normalized, m must be positive and
+// x must be less than m, which holds for the results of this API. The result has the limbs of m.
+func (a *API) ModMul(x, y, m Int) Int {
+	// Since x < m, the quotient is less than y.
+	return a.mulMod(x, y, m, y.NbLimbs())
+}
+
+// Computes base^exponent mod m with the square and multiply algorithm, where the limbs of all
+// integers must be normalized and m must be positive. The cost is two modular multiplications
+// per bit of the exponent, i.e. LimbBits times its number of limbs.
+func (a *API) ModExp(base, exponent, m Int) Int {
+	bits := make([]vars.Bool, 0, exponent.NbLimbs()*LimbBits)
+	for i := range exponent.Limbs {
+		bits = append(bits, a.api.ToBinaryLE(exponent.Limbs[i], LimbBits)...)
+	}
+	result := a.Reduce(NewIntFrom(big.NewInt(1), 1), m)
+	power := a.Reduce(base, m)
+	for i, bit := range bits {
+		product := a.ModMul(power, result, m)
+		result = a.selectInt(bit, product, result)
+		if i < len(bits)-1 {
+			power = a.ModMul(power, power, m)
+		}
+	}
+	return result
+}
+
+// Computes base^exponent mod m for a constant exponent, e.g. the public exponent 65537 of RSA
+// keys, which only needs a multiplication for the set bits of the exponent.
+func (a *API) ModExpConstant(base Int, exponent *big.Int, m Int) Int {
+	if exponent.Sign() < 0 {
+		panic("exponent must be non-negative")
+	}
+	result := a.Reduce(NewIntFrom(big.NewInt(1), 1), m)
+	power := a.Reduce(base, m)
+	for i := 0; i < exponent.BitLen(); i++ {
+		if exponent.Bit(i) == 1 {
+			result = a.ModMul(power, result, m)
+		}
+		if i < exponent.BitLen()-1 {
+			power = a.ModMul(power, power, m)
+		}
+	}
+	return result
+}
+
+// Computes x * y mod m, where the quotient has nbQuotientLimbs limbs.
+func (a *API) mulMod(x, y, m Int, nbQuotientLimbs int) Int {
+	n := m.NbLimbs()
+	if x.NbLimbs() > MaxLimbs || y.NbLimbs() > MaxLimbs || n > MaxLimbs || nbQuotientLimbs > MaxLimbs {
+		panic(fmt.Sprintf("integers may have at most %d limbs", MaxLimbs))
+	}
+
+	// Compute the quotient and the remainder with a hint.
+	inputs := []vars.Variable{
+		vars.NewVariableFromInt(x.NbLimbs()),
+		vars.NewVariableFromInt(y.NbLimbs()),
+		vars.NewVariableFromInt(nbQuotientLimbs),
+	}
+	inputs = append(append(append(inputs, x.Limbs...), y.Limbs...), m.Limbs...)
+	outputs := a.api.Hint(divModHint, nbQuotientLimbs+n, inputs...)
+	q := Int{Limbs: outputs[:nbQuotientLimbs]}
+	r := Int{Limbs: outputs[nbQuotientLimbs:]}
+	a.AssertIsNormalized(q)
+	a.AssertIsNormalized(r)
+	a.AssertIsLess(r, m)
+
+	// Assert that x * y - q * m - r is zero.
+	nbCoefficients := x.NbLimbs() + y.NbLimbs() - 1
+	if nbQuotientLimbs+n-1 > nbCoefficients {
+		nbCoefficients = nbQuotientLimbs + n - 1
+	}
+	coefficients := make([]vars.Variable, nbCoefficients)
+	for k := range coefficients {
+		coefficients[k] = a.api.Neg(limb(r, k))
+	}
+	for i := range x.Limbs {
+		for j := range y.Limbs {
+			coefficients[i+j] = a.api.Add(coefficients[i+j], a.api.Mul(x.Limbs[i], y.Limbs[j]))
+		}
+	}
+	for i := range q.Limbs {
+		for j := range m.Limbs {
+			coefficients[i+j] = a.api.Sub(coefficients[i+j], a.api.Mul(q.Limbs[i], m.Limbs[j]))
+		}
+	}
+	a.assertIsZero(coefficients, 2*LimbBits+bitLen(MaxLimbs)+1)
+	return r
+}
+
+// Asserts that sum(coefficients[k] * 2^(LimbBits * k)) is zero, where the coefficients are in
+// (-2^nbBits, 2^nbBits). The coefficients are carried from the least significant one with hinted
+// carries, which are range checked to be in (-2^(nbBits - LimbBits + 1), 2^(nbBits - LimbBits + 1)).
+func (a *API) assertIsZero(coefficients []vars.Variable, nbBits int) {
+	nbCarryBits := nbBits - LimbBits + 2
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(nbCarryBits-1))
+	inputs := append([]vars.Variable{{Value: offset}}, coefficients...)
+	carries := a.api.Hint(carriesHint, len(coefficients)-1, inputs...)
+	shift := vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), LimbBits)}
+	carry := vars.ZERO
+	for k := range coefficients {
+		sum := a.api.Add(coefficients[k], carry)
+		if k == len(coefficients)-1 {
+			a.api.AssertIsEqual(sum, vars.ZERO)
+			break
+		}
+		// The carry is offset so that it is non-negative.
+		a.api.AssertIsInRange(carries[k], nbCarryBits)
+		carry = a.api.Sub(carries[k], vars.Variable{Value: offset})
+		a.api.AssertIsEqual(sum, a.api.Mul(carry, shift))
+	}
+}
+
+// Returns i1 if selector is true and i2 otherwise, where both have the same number of limbs.
+func (a *API) selectInt(selector vars.Bool, i1, i2 Int) Int {
+	limbs := make([]vars.Variable, i1.NbLimbs())
+	for i := range limbs {
+		limbs[i] = a.api.Select(selector, i1.Limbs[i], i2.Limbs[i])
+	}
+	return Int{Limbs: limbs}
+}
+
+// Returns the limb of x at the index, which is zero beyond its limbs.
+func limb(x Int, i int) vars.Variable {
+	if i < x.NbLimbs() {
+		return x.Limbs[i]
+	}
+	return vars.ZERO
+}
+
+// Returns x with nbLimbs limbs, which must be at least its number of limbs.
+func padded(x Int, nbLimbs int) Int {
+	limbs := make([]vars.Variable, nbLimbs)
+	for i := range limbs {
+		limbs[i] = limb(x, i)
+	}
+	return Int{Limbs: limbs}
+}
+
+func bitLen(x int) int {
+	return new(big.Int).SetInt64(int64(x)).BitLen()
+}
+
+// Splits the value into nbLimbs limbs of LimbBits bits.
+func split(value *big.Int, nbLimbs int) []*big.Int {
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), LimbBits), big.NewInt(1))
+	limbs := make([]*big.Int, nbLimbs)
+	v := new(big.Int).Set(value)
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(v, mask)
+		v.Rsh(v, LimbBits)
+	}
+	return limbs
+}
+
+// Joins limbs of LimbBits bits into a value.
+func join(limbs []*big.Int) *big.Int {
+	value := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		value.Lsh(value, LimbBits)
+		value.Add(value, limbs[i])
+	}
+	return value
+}
+
+// Computes the quotient and the remainder of x * y divided by m. The inputs are the numbers of
+// limbs of x, y and the quotient followed by the limbs of x, y and m.
+var divModHint = builder.NewHint("bignum.divMod", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	nbX, nbY, nbQ := int(inputs[0].Int64()), int(inputs[1].Int64()), int(inputs[2].Int64())
+	limbs := inputs[3:]
+	x := join(limbs[:nbX])
+	y := join(limbs[nbX : nbX+nbY])
+	m := join(limbs[nbX+nbY:])
+	if m.Sign() == 0 {
+		return fmt.Errorf("modulus is zero")
+	}
+	q, r := new(big.Int).DivMod(new(big.Int).Mul(x, y), m, new(big.Int))
+	if q.BitLen() > nbQ*LimbBits {
+		return fmt.Errorf("quotient does not fit into %d limbs", nbQ)
+	}
+	for i, l := range append(split(q, nbQ), split(r, len(outputs)-nbQ)...) {
+		outputs[i].Set(l)
+	}
+	return nil
+})
+
+// Computes the limbs of y - x - 1 from the limbs of x followed by the limbs of y.
+var differenceHint = builder.NewHint("bignum.difference", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	x := join(inputs[:len(outputs)])
+	y := join(inputs[len(outputs):])
+	d := new(big.Int).Sub(new(big.Int).Sub(y, x), big.NewInt(1))
+	if d.Sign() < 0 {
+		return fmt.Errorf("%s is not less than %s", x, y)
+	}
+	for i, l := range split(d, len(outputs)) {
+		outputs[i].Set(l)
+	}
+	return nil
+})
+
+// Computes the carries of the coefficients of a polynomial in 2^LimbBits which evaluates to zero.
+// The first input is the offset added to the carries, followed by the coefficients, which are
+// interpreted as signed integers.
+var carriesHint = builder.NewHint("bignum.carries", func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	offset := inputs[0]
+	half := new(big.Int).Rsh(field, 1)
+	carry := new(big.Int)
+	for k := range outputs {
+		c := new(big.Int).Set(inputs[1+k])
+		if c.Cmp(half) > 0 {
+			c.Sub(c, field)
+		}
+		c.Add(c, carry)
+		// The sum must be divisible by 2^LimbBits for the polynomial to evaluate to zero.
+		carry.Rsh(c, LimbBits)
+		if new(big.Int).Lsh(carry, LimbBits).Cmp(c) != 0 {
+			return fmt.Errorf("coefficient %d is not carried", k)
+		}
+		outputs[k].Add(carry, offset)
+		outputs[k].Mod(outputs[k], field)
+	}
+	return nil
+})
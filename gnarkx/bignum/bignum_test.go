@@ -0,0 +1,121 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestModExpCircuit struct {
+	Base     Int
+	Exponent Int
+	Modulus  Int
+	Expected Int
+}
+
+func (circuit *TestModExpCircuit) Define(api frontend.API) error {
+	bignumAPI := NewAPI(builder.NewAPI(api))
+	bignumAPI.AssertIsNormalized(circuit.Base)
+	bignumAPI.AssertIsNormalized(circuit.Exponent)
+	bignumAPI.AssertIsNormalized(circuit.Modulus)
+	result := bignumAPI.ModExp(circuit.Base, circuit.Exponent, circuit.Modulus)
+	bignumAPI.AssertIsEqual(result, circuit.Expected)
+	return nil
+}
+
+type TestModExpConstantCircuit struct {
+	Base     []vars.Byte
+	Modulus  []vars.Byte
+	Expected []vars.Byte
+}
+
+func (circuit *TestModExpConstantCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bignumAPI := NewAPI(succinctAPI)
+	base := bignumAPI.FromBytesBE(circuit.Base)
+	modulus := bignumAPI.FromBytesBE(circuit.Modulus)
+	result := bignumAPI.ModExpConstant(base, big.NewInt(65537), modulus)
+	bytes := bignumAPI.ToBytesBE(result, len(circuit.Expected))
+	for i := range bytes {
+		succinctAPI.AssertIsEqualByte(bytes[i], circuit.Expected[i])
+	}
+	return nil
+}
+
+func randomInt(t *testing.T, nbBits int) *big.Int {
+	value, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(nbBits)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func toBytes(value *big.Int, nbBytes int) []vars.Byte {
+	return vars.NewBytesFrom(value.FillBytes(make([]byte, nbBytes)))
+}
+
+func TestModExp(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	nbLimbs := NbLimbs(256)
+	modulus := randomInt(t, 256)
+	modulus.SetBit(modulus, 255, 1)
+	base := randomInt(t, 256)
+	exponent := randomInt(t, 64)
+	expected := new(big.Int).Exp(base, exponent, modulus)
+
+	circuit := &TestModExpCircuit{
+		Base:     NewInt(nbLimbs),
+		Exponent: NewInt(1),
+		Modulus:  NewInt(nbLimbs),
+		Expected: NewInt(nbLimbs),
+	}
+	assignment := &TestModExpCircuit{
+		Base:     NewIntFrom(base, nbLimbs),
+		Exponent: NewIntFrom(exponent, 1),
+		Modulus:  NewIntFrom(modulus, nbLimbs),
+		Expected: NewIntFrom(expected, nbLimbs),
+	}
+	assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A result which is congruent to the expected one but not reduced is rejected.
+	assignment.Expected = NewIntFrom(new(big.Int).Add(expected, modulus), nbLimbs+1)
+	circuit.Expected = NewInt(nbLimbs + 1)
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	wrong := new(big.Int).Add(expected, big.NewInt(1))
+	circuit.Expected = NewInt(nbLimbs)
+	assignment.Expected = NewIntFrom(wrong, nbLimbs)
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+func TestModExpConstant(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	nbBytes := 256
+	modulus := randomInt(t, 8*nbBytes)
+	modulus.SetBit(modulus, 8*nbBytes-1, 1)
+	base := randomInt(t, 8*nbBytes)
+	expected := new(big.Int).Exp(base, big.NewInt(65537), modulus)
+
+	circuit := &TestModExpConstantCircuit{
+		Base:     make([]vars.Byte, nbBytes),
+		Modulus:  make([]vars.Byte, nbBytes),
+		Expected: make([]vars.Byte, nbBytes),
+	}
+	assignment := &TestModExpConstantCircuit{
+		Base:     toBytes(base, nbBytes),
+		Modulus:  toBytes(modulus, nbBytes),
+		Expected: toBytes(expected, nbBytes),
+	}
+	assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	assignment.Expected = toBytes(new(big.Int).Xor(expected, big.NewInt(1)), nbBytes)
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
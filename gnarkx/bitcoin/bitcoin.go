@@ -0,0 +1,168 @@
+// The API for Bitcoin SPV proofs, i.e. parsing block headers, checking their proof of work against
+// the difficulty target encoded in the header and verifying the inclusion of transactions in a
+// block through the Merkle branch of the transaction.
+//
+// Hashes are in the internal byte order of Bitcoin, i.e. the reverse of the order in which block
+// hashes and transaction IDs are usually displayed.
+package bitcoin
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/merkle"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The length of a serialized block header.
+const HeaderLength = 80
+
+// The fields of a block header along with its hash. The integers are serialized in little-endian
+// order.
+type Header struct {
+	Hash          [32]vars.Byte
+	Version       vars.U32
+	PrevBlockHash [32]vars.Byte
+	MerkleRoot    [32]vars.Byte
+	Timestamp     vars.U32
+	Bits          vars.U32
+	Nonce         vars.U32
+}
+
+// An API used for Bitcoin SPV proofs.
+type API struct {
+	api builder.API
+}
+
+// Creates a new bitcoin.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api}
+}
+
+// Decodes the serialized block header and computes its hash.
+func (a *API) DecodeHeader(header [HeaderLength]vars.Byte) Header {
+	defer a.api.Scope("bitcoin_header")()
+	var result Header
+	result.Hash = sha256.DoubleHash(a.api, header[:])
+	result.Version = a.u32(header[0:4])
+	copy(result.PrevBlockHash[:], header[4:36])
+	copy(result.MerkleRoot[:], header[36:68])
+	result.Timestamp = a.u32(header[68:72])
+	result.Bits = a.u32(header[72:76])
+	result.Nonce = a.u32(header[76:80])
+	return result
+}
+
+// Decodes the difficulty target from the compact encoding in the bits field of the header, i.e.
+// target = mantissa * 256^(exponent - 3), where the exponent is the most significant byte and the
+// mantissa is the other three bytes. Asserts that the exponent is in [3, 32] and that the sign bit
+// of the mantissa is not set, which holds for every valid target.
+func (a *API) DecodeTarget(header [HeaderLength]vars.Byte) vars.U256 {
+	defer a.api.Scope("bitcoin_target")()
+	mantissa := header[72:75]
+	exponent := header[75].Value
+	signBits := a.api.ToBitsFromByte(mantissa[2])
+	a.api.AssertIsEqual(signBits[7].Value, vars.ZERO)
+
+	// isExponent[e] is set iff the exponent is e, where exactly one of them must be set.
+	isExponent := make([]vars.Variable, 33)
+	sum := vars.ZERO
+	for e := 3; e <= 32; e++ {
+		isExponent[e] = a.api.IsZero(a.api.Sub(exponent, vars.NewVariableFromInt(e))).Value
+		sum = a.api.Add(sum, isExponent[e])
+	}
+	a.api.AssertIsEqual(sum, vars.ONE)
+
+	// The byte j of the mantissa is the byte exponent - 3 + j of the target in little-endian order.
+	var target [32]vars.Variable
+	for i := range target {
+		target[i] = vars.ZERO
+		for j := range mantissa {
+			if e := i + 3 - j; e >= 3 && e <= 32 {
+				target[i] = a.api.Add(target[i], a.api.Mul(isExponent[e], mantissa[j].Value))
+			}
+		}
+	}
+	var result vars.U256
+	for i := range result.Limbs {
+		result.Limbs[i] = vars.U64{Value: a.fromBytesLE(target[8*i : 8*i+8])}
+	}
+	return result
+}
+
+// Asserts that the hash of the header, as a little-endian integer, is at most the difficulty
+// target of the header, and returns the decoded header.
+func (a *API) VerifyProofOfWork(header [HeaderLength]vars.Byte) Header {
+	result := a.DecodeHeader(header)
+	target := a.DecodeTarget(header)
+	var hash vars.U256
+	for i := range hash.Limbs {
+		limb := make([]vars.Variable, 8)
+		for j := range limb {
+			limb[j] = result.Hash[8*i+j].Value
+		}
+		hash.Limbs[i] = vars.U64{Value: a.fromBytesLE(limb)}
+	}
+	a.api.AssertIsEqual(a.api.IsLessOrEqualU256(hash, target).Value, vars.ONE)
+	return result
+}
+
+// Verifies the proof of work of a chain of headers, where the first header builds on the block
+// with the given hash and each other header builds on the previous one. Returns the decoded
+// headers. Note that the difficulty targets are not checked against the retargeting rules, so the
+// caller must bind them, e.g. to the target of a known block of the same difficulty period.
+func (a *API) VerifyHeaderChain(prevBlockHash [32]vars.Byte, headers [][HeaderLength]vars.Byte) []Header {
+	defer a.api.Scope("bitcoin_header_chain")()
+	result := make([]Header, len(headers))
+	for i := range headers {
+		result[i] = a.VerifyProofOfWork(headers[i])
+		for j := range prevBlockHash {
+			a.api.AssertIsEqualByte(result[i].PrevBlockHash[j], prevBlockHash[j])
+		}
+		prevBlockHash = result[i].Hash
+	}
+	return result
+}
+
+// Computes the ID of a serialized transaction without witness data, i.e. its double SHA256 hash,
+// where len(tx) is the maximum length of the transaction and length is its actual length.
+func (a *API) ComputeTxID(tx []vars.Byte, length vars.Variable) [32]vars.Byte {
+	return sha256.DoubleHashVariable(a.api, tx, length)
+}
+
+// Verifies that the transaction with the given ID is at the index of the transaction tree with
+// the given Merkle root. The branch contains the siblings of the nodes on the path from the
+// transaction to the root, where a node without a sibling is its own sibling as Bitcoin duplicates
+// the last node of a level with an odd number of nodes. The depth of the tree is len(branch),
+// which must be a constant at compile time of the circuit.
+//
+// Since an inner node is the hash of 64 bytes, the transaction ID must be that of a transaction
+// which is not 64 bytes long, see CVE-2017-12842.
+func (a *API) VerifyMerkleBranch(merkleRoot [32]vars.Byte, txID [32]vars.Byte, branch [][32]vars.Byte, index vars.Variable) {
+	defer a.api.Scope("bitcoin_merkle_branch")()
+	merkle.VerifyProof(a.api, merkle.DoubleSHA256, merkleRoot, txID, branch, index)
+}
+
+// Verifies the proof of work of the header and that the transaction with the given ID is included
+// in its block. Returns the decoded header.
+func (a *API) VerifyTransaction(header [HeaderLength]vars.Byte, txID [32]vars.Byte, branch [][32]vars.Byte, index vars.Variable) Header {
+	result := a.VerifyProofOfWork(header)
+	a.VerifyMerkleBranch(result.MerkleRoot, txID, branch, index)
+	return result
+}
+
+func (a *API) u32(data []vars.Byte) vars.U32 {
+	in := make([]vars.Variable, len(data))
+	for i := range data {
+		in[i] = data[i].Value
+	}
+	return vars.U32{Value: a.fromBytesLE(in)}
+}
+
+// Returns the integer of the little-endian bytes.
+func (a *API) fromBytesLE(data []vars.Variable) vars.Variable {
+	result := vars.ZERO
+	for i := len(data) - 1; i >= 0; i-- {
+		result = a.api.Add(a.api.Mul(result, vars.NewVariableFromInt(256)), data[i])
+	}
+	return result
+}
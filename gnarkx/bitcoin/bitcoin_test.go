@@ -0,0 +1,202 @@
+package bitcoin
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The headers of the genesis block and of block 1 of the Bitcoin mainnet.
+var testHeaders = []string{
+	"0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c",
+	"010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299",
+}
+
+// The hash of the genesis block in display order.
+const genesisHash = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+
+func decodeHeader(s string) [HeaderLength]byte {
+	var header [HeaderLength]byte
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != HeaderLength {
+		panic("invalid header")
+	}
+	copy(header[:], b)
+	return header
+}
+
+func toVars(header [HeaderLength]byte) [HeaderLength]vars.Byte {
+	var result [HeaderLength]vars.Byte
+	copy(result[:], vars.NewBytesFrom(header[:]))
+	return result
+}
+
+// Decodes the target from the bits of the header natively.
+func targetNative(header [HeaderLength]byte) *big.Int {
+	bits := binary.LittleEndian.Uint32(header[72:76])
+	mantissa := big.NewInt(int64(bits & 0xffffff))
+	return mantissa.Lsh(mantissa, 8*(uint(bits>>24)-3))
+}
+
+type TestHeaderChainCircuit struct {
+	PrevBlockHash [32]vars.Byte
+	Headers       [][HeaderLength]vars.Byte
+	Hash          [32]vars.Byte
+	Target        vars.U256
+	Timestamp     vars.U32
+}
+
+func (circuit *TestHeaderChainCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bitcoin := NewAPI(succinctAPI)
+	headers := bitcoin.VerifyHeaderChain(circuit.PrevBlockHash, circuit.Headers)
+	for i := range circuit.Hash {
+		succinctAPI.AssertIsEqualByte(headers[0].Hash[i], circuit.Hash[i])
+	}
+	succinctAPI.AssertIsEqualU256(bitcoin.DecodeTarget(circuit.Headers[0]), circuit.Target)
+	succinctAPI.AssertIsEqual(headers[len(headers)-1].Timestamp.Value, circuit.Timestamp.Value)
+	return nil
+}
+
+func TestHeaderChain(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	headers := make([][HeaderLength]byte, len(testHeaders))
+	for i := range testHeaders {
+		headers[i] = decodeHeader(testHeaders[i])
+	}
+	hash := sha256.DoubleHashNative(headers[0][:])
+	for i := 0; i < 16; i++ {
+		hash[i], hash[31-i] = hash[31-i], hash[i]
+	}
+	assert.Equal(genesisHash, hex.EncodeToString(hash[:]))
+
+	newCircuit := func(headers [][HeaderLength]byte) *TestHeaderChainCircuit {
+		circuit := &TestHeaderChainCircuit{PrevBlockHash: vars.NewBytes32()}
+		for i := range headers {
+			circuit.Headers = append(circuit.Headers, toVars(headers[i]))
+		}
+		vars.SetBytes32(&circuit.Hash, sha256.DoubleHashNative(headers[0][:]))
+		circuit.Target.Set(targetNative(headers[0]))
+		circuit.Timestamp.Set(binary.LittleEndian.Uint32(headers[len(headers)-1][68:72]))
+		return circuit
+	}
+	circuit := newCircuit(headers)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A header with another nonce does not meet the target.
+	tampered := [][HeaderLength]byte{headers[0], headers[1]}
+	tampered[1][76]++
+	witness := newCircuit(tampered)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// Headers out of order do not form a chain.
+	witness = newCircuit([][HeaderLength]byte{headers[1], headers[0]})
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
+
+const testDepth = 3
+
+type TestTransactionCircuit struct {
+	Header [HeaderLength]vars.Byte
+	Tx     []vars.Byte
+	Length vars.Variable
+	Branch [testDepth][32]vars.Byte
+	Index  vars.Variable
+}
+
+func (circuit *TestTransactionCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bitcoin := NewAPI(succinctAPI)
+	txID := bitcoin.ComputeTxID(circuit.Tx, circuit.Length)
+	bitcoin.VerifyTransaction(circuit.Header, txID, circuit.Branch[:], circuit.Index)
+	return nil
+}
+
+// Computes the Merkle root of the transaction IDs and the branch of the one at the index, where
+// the last node of a level with an odd number of nodes is duplicated.
+func merkleBranch(txIDs [][32]byte, index int) ([32]byte, [][32]byte) {
+	var branch [][32]byte
+	layer := txIDs
+	for len(layer) > 1 {
+		if len(layer)%2 == 1 {
+			layer = append(layer, layer[len(layer)-1])
+		}
+		branch = append(branch, layer[index^1])
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.DoubleHashNative(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+		index /= 2
+	}
+	return layer[0], branch
+}
+
+func TestTransaction(t *testing.T) {
+	assert := test.NewAssert(t)
+	const maxTxLength = 100
+
+	// A block with 5 transactions has a tree of depth 3. The header of the block is mined by
+	// lowering the difficulty to the maximum target of the regtest network.
+	txs := make([][]byte, 5)
+	txIDs := make([][32]byte, len(txs))
+	for i := range txs {
+		txs[i] = make([]byte, 60+5*i)
+		for j := range txs[i] {
+			txs[i][j] = byte(i*j + 1)
+		}
+		txIDs[i] = sha256.DoubleHashNative(txs[i])
+	}
+	newCircuit := func(index int, bits uint32) *TestTransactionCircuit {
+		root, branch := merkleBranch(txIDs, index)
+		header := decodeHeader(testHeaders[1])
+		copy(header[36:68], root[:])
+		binary.LittleEndian.PutUint32(header[72:76], bits)
+		target := targetNative(header)
+		for nonce := uint32(0); ; nonce++ {
+			binary.LittleEndian.PutUint32(header[76:80], nonce)
+			hash := sha256.DoubleHashNative(header[:])
+			for i := 0; i < 16; i++ {
+				hash[i], hash[31-i] = hash[31-i], hash[i]
+			}
+			if new(big.Int).SetBytes(hash[:]).Cmp(target) <= 0 {
+				break
+			}
+		}
+		tx := make([]byte, maxTxLength)
+		copy(tx, txs[index])
+		circuit := &TestTransactionCircuit{
+			Header: toVars(header),
+			Tx:     vars.NewBytesFrom(tx),
+			Length: vars.NewVariableFromInt(len(txs[index])),
+			Index:  vars.NewVariableFromInt(index),
+		}
+		for i := range branch {
+			vars.SetBytes32(&circuit.Branch[i], branch[i])
+		}
+		return circuit
+	}
+
+	for _, index := range []int{0, 3, 4} {
+		circuit := newCircuit(index, 0x207fffff)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// The branch of another transaction is rejected.
+	circuit := newCircuit(3, 0x207fffff)
+	circuit.Index = vars.NewVariableFromInt(2)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A target with the sign bit of the mantissa set is rejected, even though the hash is below it.
+	circuit = newCircuit(3, 0x20ffffff)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
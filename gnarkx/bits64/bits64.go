@@ -10,6 +10,11 @@ type API struct {
 	api builder.API
 }
 
+// Creates a new bits64.API.
+func NewAPI(api builder.API) API {
+	return API{api: api}
+}
+
 // Computes the xor of 64 bit arrays.
 func (a *API) Xor64(in ...[64]vars.Bool) [64]vars.Bool {
 	if len(in) < 2 {
@@ -37,3 +42,21 @@ func (a *API) And64(in ...[64]vars.Bool) [64]vars.Bool {
 	}
 	return result
 }
+
+// Computes the not of a 64 bit array.
+func (a *API) Not64(i1 [64]vars.Bool) [64]vars.Bool {
+	var result [64]vars.Bool
+	for i := 0; i < 64; i++ {
+		result[i] = a.api.Not(i1[i])
+	}
+	return result
+}
+
+// Rotates a 64-length bit array by a given offset to the right.
+func (a *API) Rotate64(i1 [64]vars.Bool, offset int) [64]vars.Bool {
+	var result [64]vars.Bool
+	for i := 0; i < 64; i++ {
+		result[(i+offset)%len(i1)] = i1[i]
+	}
+	return result
+}
@@ -0,0 +1,276 @@
+// The API for bitwise operations on vars.U32 and vars.U64, i.e. and, or, xor, not, shifts and
+// rotations, which hash functions and other bit-twiddling circuits can share.
+//
+// The operations are implemented with one of two strategies. The decomposition strategy splits the
+// operands into bits, which costs about one constraint per bit and operand. The lookup strategy
+// splits the operands into bytes and looks up the result of each pair of bytes in a table of 2^16
+// entries, which costs a few constraints per byte. The tables are created on their first use and
+// shared by all APIs of a circuit, but cost about 2^16 constraints each, so they only pay off for
+// circuits with many operations, see StrategyFor.
+package bitwise
+
+import (
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/lookup"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The strategy used to implement the bitwise operations.
+type Strategy int
+
+const (
+	// Decomposes the operands into bits.
+	Decomposition Strategy = iota
+	// Decomposes the operands into bytes and looks up the results in tables.
+	Lookup
+)
+
+// The number of operations from which the lookup strategy is cheaper than the decomposition
+// strategy, since a table costs about 2^16 constraints and a lookup saves about 100 constraints
+// over a decomposition.
+const LookupThreshold = 1 << 10
+
+// Returns the cheaper strategy for a circuit with the given number of bitwise operations.
+func StrategyFor(nbOps int) Strategy {
+	if nbOps >= LookupThreshold {
+		return Lookup
+	}
+	return Decomposition
+}
+
+// An API used for bitwise operations.
+type API struct {
+	api      builder.API
+	strategy Strategy
+}
+
+// Creates a new bitwise.API with the decomposition strategy. Circuits with many operations should
+// use NewAPIWithStrategy with the strategy of StrategyFor instead.
+func NewAPI(api *builder.API) *API {
+	return NewAPIWithStrategy(api, Decomposition)
+}
+
+// Creates a new bitwise.API with the given strategy. The lookup strategy falls back to
+// decompositions if the circuit does not support commitments, since its tables would then cost
+// 2^16 constraints per lookup.
+func NewAPIWithStrategy(api *builder.API, strategy Strategy) *API {
+	if !api.SupportsCommitments() {
+		strategy = Decomposition
+	}
+	return &API{api: *api, strategy: strategy}
+}
+
+// Returns the strategy of the API.
+func (a *API) Strategy() Strategy {
+	return a.strategy
+}
+
+// Computes i1 & i2.
+func (a *API) AndU32(i1, i2 vars.U32) vars.U32 {
+	return vars.U32{Value: a.and(i1.Value, i2.Value, 32)}
+}
+
+// Computes i1 | i2.
+func (a *API) OrU32(i1, i2 vars.U32) vars.U32 {
+	return vars.U32{Value: a.or(i1.Value, i2.Value, 32)}
+}
+
+// Computes i1 ^ i2.
+func (a *API) XorU32(i1, i2 vars.U32) vars.U32 {
+	return vars.U32{Value: a.xor(i1.Value, i2.Value, 32)}
+}
+
+// Computes ^i1.
+func (a *API) NotU32(i1 vars.U32) vars.U32 {
+	return vars.U32{Value: a.not(i1.Value, 32)}
+}
+
+// Computes i1 << offset.
+func (a *API) ShlU32(i1 vars.U32, offset int) vars.U32 {
+	return vars.U32{Value: a.shl(i1.Value, offset, 32)}
+}
+
+// Computes i1 >> offset.
+func (a *API) ShrU32(i1 vars.U32, offset int) vars.U32 {
+	return vars.U32{Value: a.shr(i1.Value, offset, 32)}
+}
+
+// Rotates i1 by offset bits to the left.
+func (a *API) RotateLeftU32(i1 vars.U32, offset int) vars.U32 {
+	return vars.U32{Value: a.rotateLeft(i1.Value, offset, 32)}
+}
+
+// Rotates i1 by offset bits to the right.
+func (a *API) RotateRightU32(i1 vars.U32, offset int) vars.U32 {
+	return vars.U32{Value: a.rotateLeft(i1.Value, 32-offset%32, 32)}
+}
+
+// Computes i1 & i2.
+func (a *API) AndU64(i1, i2 vars.U64) vars.U64 {
+	return vars.U64{Value: a.and(i1.Value, i2.Value, 64)}
+}
+
+// Computes i1 | i2.
+func (a *API) OrU64(i1, i2 vars.U64) vars.U64 {
+	return vars.U64{Value: a.or(i1.Value, i2.Value, 64)}
+}
+
+// Computes i1 ^ i2.
+func (a *API) XorU64(i1, i2 vars.U64) vars.U64 {
+	return vars.U64{Value: a.xor(i1.Value, i2.Value, 64)}
+}
+
+// Computes ^i1.
+func (a *API) NotU64(i1 vars.U64) vars.U64 {
+	return vars.U64{Value: a.not(i1.Value, 64)}
+}
+
+// Computes i1 << offset.
+func (a *API) ShlU64(i1 vars.U64, offset int) vars.U64 {
+	return vars.U64{Value: a.shl(i1.Value, offset, 64)}
+}
+
+// Computes i1 >> offset.
+func (a *API) ShrU64(i1 vars.U64, offset int) vars.U64 {
+	return vars.U64{Value: a.shr(i1.Value, offset, 64)}
+}
+
+// Rotates i1 by offset bits to the left.
+func (a *API) RotateLeftU64(i1 vars.U64, offset int) vars.U64 {
+	return vars.U64{Value: a.rotateLeft(i1.Value, offset, 64)}
+}
+
+// Rotates i1 by offset bits to the right.
+func (a *API) RotateRightU64(i1 vars.U64, offset int) vars.U64 {
+	return vars.U64{Value: a.rotateLeft(i1.Value, 64-offset%64, 64)}
+}
+
+func (a *API) and(i1, i2 vars.Variable, nbBits int) vars.Variable {
+	return a.binaryOp(i1, i2, nbBits, "and", func(x, y byte) byte { return x & y }, func(x, y vars.Variable) vars.Variable {
+		return a.api.Mul(x, y)
+	})
+}
+
+func (a *API) or(i1, i2 vars.Variable, nbBits int) vars.Variable {
+	return a.binaryOp(i1, i2, nbBits, "or", func(x, y byte) byte { return x | y }, func(x, y vars.Variable) vars.Variable {
+		return a.api.Sub(a.api.Add(x, y), a.api.Mul(x, y))
+	})
+}
+
+func (a *API) xor(i1, i2 vars.Variable, nbBits int) vars.Variable {
+	return a.binaryOp(i1, i2, nbBits, "xor", func(x, y byte) byte { return x ^ y }, func(x, y vars.Variable) vars.Variable {
+		return a.api.Sub(a.api.Add(x, y), a.api.Mul(x, y, vars.TWO))
+	})
+}
+
+// Computes 2^nbBits - 1 - i1, which flips all bits of i1 without any constraints.
+func (a *API) not(i1 vars.Variable, nbBits int) vars.Variable {
+	return a.api.Sub(vars.Variable{Value: mask(nbBits)}, i1)
+}
+
+func (a *API) shl(i1 vars.Variable, offset int, nbBits int) vars.Variable {
+	if offset >= nbBits {
+		return vars.ZERO
+	}
+	low, _ := a.split(i1, nbBits-offset, nbBits)
+	return a.api.Mul(low, powerOfTwo(offset))
+}
+
+func (a *API) shr(i1 vars.Variable, offset int, nbBits int) vars.Variable {
+	if offset >= nbBits {
+		return vars.ZERO
+	}
+	_, high := a.split(i1, offset, nbBits)
+	return high
+}
+
+func (a *API) rotateLeft(i1 vars.Variable, offset int, nbBits int) vars.Variable {
+	offset %= nbBits
+	if offset == 0 {
+		return i1
+	}
+	low, high := a.split(i1, nbBits-offset, nbBits)
+	return a.api.Add(a.api.Mul(low, powerOfTwo(offset)), high)
+}
+
+// The key of the table of an operation in the key-value store of the compiler.
+type tableKey struct {
+	name string
+}
+
+// Computes the bitwise operation, where f is the operation on bytes for the lookup strategy and
+// g is the operation on bits for the decomposition strategy.
+func (a *API) binaryOp(
+	i1, i2 vars.Variable,
+	nbBits int,
+	name string,
+	f func(x, y byte) byte,
+	g func(x, y vars.Variable) vars.Variable,
+) vars.Variable {
+	if a.strategy == Decomposition {
+		x := a.api.ToBinaryLE(i1, nbBits)
+		y := a.api.ToBinaryLE(i2, nbBits)
+		result := vars.ZERO
+		for i := nbBits - 1; i >= 0; i-- {
+			result = a.api.Add(a.api.Mul(result, vars.TWO), g(x[i].Value, y[i].Value))
+		}
+		return result
+	}
+
+	table := builder.CompilerValue(&a.api, tableKey{name}, func() *lookup.ByteOpTable {
+		return lookup.NewByteOpTable(a.api, f)
+	})
+	x := a.toBytes(i1, nbBits)
+	y := a.toBytes(i2, nbBits)
+	result := vars.ZERO
+	for i := len(x) - 1; i >= 0; i-- {
+		result = a.api.Add(a.api.Mul(result, vars.NewVariableFromInt(256)), table.Lookup(x[i], y[i]).Value)
+	}
+	return result
+}
+
+// Decomposes i1 in [0, 2^nbBits) into i1 % 2^offset and i1 / 2^offset.
+func (a *API) split(i1 vars.Variable, offset int, nbBits int) (vars.Variable, vars.Variable) {
+	if a.strategy == Decomposition {
+		bits := a.api.ToBinaryLE(i1, nbBits)
+		return fromBits(a.api, bits[:offset]), fromBits(a.api, bits[offset:])
+	}
+
+	limbs := a.api.Decompose(i1, offset, nbBits-offset)
+	return limbs[0], limbs[1]
+}
+
+// Decomposes i1 in [0, 2^nbBits) into little-endian bytes.
+func (a *API) toBytes(i1 vars.Variable, nbBits int) []vars.Byte {
+	widths := make([]int, nbBits/8)
+	for i := range widths {
+		widths[i] = 8
+	}
+	limbs := a.api.Decompose(i1, widths...)
+	bytes := make([]vars.Byte, len(limbs))
+	for i := range limbs {
+		bytes[i] = vars.Byte{Value: limbs[i]}
+	}
+	return bytes
+}
+
+// Recomposes little-endian bits into a variable.
+func fromBits(api builder.API, bits []vars.Bool) vars.Variable {
+	result := vars.ZERO
+	for i := len(bits) - 1; i >= 0; i-- {
+		result = api.Add(api.Mul(result, vars.TWO), bits[i].Value)
+	}
+	return result
+}
+
+// Returns 2^n as a constant in a circuit.
+func powerOfTwo(n int) vars.Variable {
+	return vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), uint(n))}
+}
+
+// Returns 2^n - 1.
+func mask(n int) *big.Int {
+	return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(n)), big.NewInt(1))
+}
@@ -0,0 +1,158 @@
+package bitwise
+
+import (
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/gnarkxtest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestBitwiseCircuit struct {
+	X32, Y32                     vars.U32
+	And32, Or32, Xor32, Not32    vars.U32
+	Shl32, Shr32, Rotl32, Rotr32 vars.U32
+	X64, Y64                     vars.U64
+	And64, Or64, Xor64, Not64    vars.U64
+	Shl64, Shr64, Rotl64, Rotr64 vars.U64
+	Strategy                     Strategy `gnark:"-"`
+}
+
+func (circuit *TestBitwiseCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bitwiseAPI := NewAPIWithStrategy(succinctAPI, circuit.Strategy)
+	assertIsEqualU32 := func(i1, i2 vars.U32) {
+		succinctAPI.AssertIsEqual(i1.Value, i2.Value)
+	}
+	assertIsEqualU32(bitwiseAPI.AndU32(circuit.X32, circuit.Y32), circuit.And32)
+	assertIsEqualU32(bitwiseAPI.OrU32(circuit.X32, circuit.Y32), circuit.Or32)
+	assertIsEqualU32(bitwiseAPI.XorU32(circuit.X32, circuit.Y32), circuit.Xor32)
+	assertIsEqualU32(bitwiseAPI.NotU32(circuit.X32), circuit.Not32)
+	assertIsEqualU32(bitwiseAPI.ShlU32(circuit.X32, 7), circuit.Shl32)
+	assertIsEqualU32(bitwiseAPI.ShrU32(circuit.X32, 13), circuit.Shr32)
+	assertIsEqualU32(bitwiseAPI.RotateLeftU32(circuit.X32, 5), circuit.Rotl32)
+	assertIsEqualU32(bitwiseAPI.RotateRightU32(circuit.X32, 22), circuit.Rotr32)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.AndU64(circuit.X64, circuit.Y64), circuit.And64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.OrU64(circuit.X64, circuit.Y64), circuit.Or64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.XorU64(circuit.X64, circuit.Y64), circuit.Xor64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.NotU64(circuit.X64), circuit.Not64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.ShlU64(circuit.X64, 1), circuit.Shl64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.ShrU64(circuit.X64, 63), circuit.Shr64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.RotateLeftU64(circuit.X64, 44), circuit.Rotl64)
+	succinctAPI.AssertIsEqualU64(bitwiseAPI.RotateRightU64(circuit.X64, 64), circuit.Rotr64)
+	return nil
+}
+
+// Creates a new u64, since vars.U64.Set only supports values up to 2^63.
+func newU64(i1 uint64) vars.U64 {
+	return vars.U64{Value: vars.Variable{Value: new(big.Int).SetUint64(i1)}}
+}
+
+func newTestCircuit(x32, y32 uint32, x64, y64 uint64, strategy Strategy) *TestBitwiseCircuit {
+	circuit := &TestBitwiseCircuit{Strategy: strategy}
+	circuit.X32.Set(x32)
+	circuit.Y32.Set(y32)
+	circuit.And32.Set(AndU32Native(x32, y32))
+	circuit.Or32.Set(OrU32Native(x32, y32))
+	circuit.Xor32.Set(XorU32Native(x32, y32))
+	circuit.Not32.Set(NotU32Native(x32))
+	circuit.Shl32.Set(ShlU32Native(x32, 7))
+	circuit.Shr32.Set(ShrU32Native(x32, 13))
+	circuit.Rotl32.Set(RotateLeftU32Native(x32, 5))
+	circuit.Rotr32.Set(RotateRightU32Native(x32, 22))
+	circuit.X64 = newU64(x64)
+	circuit.Y64 = newU64(y64)
+	circuit.And64 = newU64(AndU64Native(x64, y64))
+	circuit.Or64 = newU64(OrU64Native(x64, y64))
+	circuit.Xor64 = newU64(XorU64Native(x64, y64))
+	circuit.Not64 = newU64(NotU64Native(x64))
+	circuit.Shl64 = newU64(ShlU64Native(x64, 1))
+	circuit.Shr64 = newU64(ShrU64Native(x64, 63))
+	circuit.Rotl64 = newU64(RotateLeftU64Native(x64, 44))
+	circuit.Rotr64 = newU64(RotateRightU64Native(x64, 64))
+	return circuit
+}
+
+func TestBitwise(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, strategy := range []Strategy{Decomposition, Lookup} {
+		circuit := newTestCircuit(0xdeadbeef, 0x12345678, 0xfedcba9876543210, 0x0f0f0f0ff0f0f0f0, strategy)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+		// A wrong result is rejected.
+		circuit.Xor64 = newU64(0)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+		// An operand which is not a u32 is rejected.
+		circuit = newTestCircuit(0xdeadbeef, 0x12345678, 0xfedcba9876543210, 0x0f0f0f0ff0f0f0f0, strategy)
+		circuit.Y32.Value = vars.NewVariableFromInt(1 << 32)
+		circuit.Or32.Set(0xdeadbeef)
+		circuit.Xor32.Set(0xdeadbeef)
+		circuit.And32.Set(0)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestBitwiseFuzz(t *testing.T) {
+	for _, strategy := range []Strategy{Decomposition, Lookup} {
+		strategy := strategy
+		generate := func(rng *rand.Rand) frontend.Circuit {
+			return newTestCircuit(rng.Uint32(), rng.Uint32(), rng.Uint64(), rng.Uint64(), strategy)
+		}
+		tester := gnarkxtest.New(t, &TestBitwiseCircuit{Strategy: strategy})
+		tester.Fuzz(0, 5, generate)
+		tester.FuzzMutations(0, 5, generate, "And32", "Xor32", "Rotr32", "Or64", "Not64", "Shr64")
+	}
+}
+
+// Xors the values with one API per operation, or one API for all operations.
+type TestSharedTablesCircuit struct {
+	X, Y   [4]vars.U64
+	Shared bool `gnark:"-"`
+}
+
+func (circuit *TestSharedTablesCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bitwiseAPI := NewAPIWithStrategy(succinctAPI, Lookup)
+	for i := range circuit.X {
+		if !circuit.Shared {
+			bitwiseAPI = NewAPIWithStrategy(succinctAPI, Lookup)
+		}
+		bitwiseAPI.XorU64(circuit.X[i], circuit.Y[i])
+	}
+	return nil
+}
+
+func TestSharedTables(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The tables are created once per circuit, not once per API.
+	nbConstraints := func(shared bool) int {
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &TestSharedTablesCircuit{Shared: shared})
+		assert.NoError(err)
+		return ccs.GetNbConstraints()
+	}
+	assert.Equal(nbConstraints(true), nbConstraints(false))
+}
+
+func TestStrategyFor(t *testing.T) {
+	assert := test.NewAssert(t)
+	assert.Equal(Decomposition, StrategyFor(LookupThreshold-1))
+	assert.Equal(Lookup, StrategyFor(LookupThreshold))
+}
+
+func TestBitwiseNative(t *testing.T) {
+	assert := test.NewAssert(t)
+	assert.Equal(uint32(0xd5b7ddfb), RotateLeftU32Native(0xdeadbeef, 5))
+	assert.Equal(uint32(0xb6fbbf7a), RotateRightU32Native(0xdeadbeef, 22))
+	assert.Equal(bits.RotateLeft64(0xfedcba9876543210, 20), RotateRightU64Native(0xfedcba9876543210, 44))
+	assert.Equal(uint64(0), ShlU64Native(1, 64))
+}
@@ -0,0 +1,86 @@
+package bitwise
+
+import "math/bits"
+
+// The out-of-circuit counterparts of the operations of API, which compute the same values on
+// native integers.
+
+// Computes i1 & i2 like API.AndU32.
+func AndU32Native(i1, i2 uint32) uint32 {
+	return i1 & i2
+}
+
+// Computes i1 | i2 like API.OrU32.
+func OrU32Native(i1, i2 uint32) uint32 {
+	return i1 | i2
+}
+
+// Computes i1 ^ i2 like API.XorU32.
+func XorU32Native(i1, i2 uint32) uint32 {
+	return i1 ^ i2
+}
+
+// Computes ^i1 like API.NotU32.
+func NotU32Native(i1 uint32) uint32 {
+	return ^i1
+}
+
+// Computes i1 << offset like API.ShlU32.
+func ShlU32Native(i1 uint32, offset int) uint32 {
+	return i1 << offset
+}
+
+// Computes i1 >> offset like API.ShrU32.
+func ShrU32Native(i1 uint32, offset int) uint32 {
+	return i1 >> offset
+}
+
+// Rotates i1 by offset bits to the left like API.RotateLeftU32.
+func RotateLeftU32Native(i1 uint32, offset int) uint32 {
+	return bits.RotateLeft32(i1, offset)
+}
+
+// Rotates i1 by offset bits to the right like API.RotateRightU32.
+func RotateRightU32Native(i1 uint32, offset int) uint32 {
+	return bits.RotateLeft32(i1, -offset)
+}
+
+// Computes i1 & i2 like API.AndU64.
+func AndU64Native(i1, i2 uint64) uint64 {
+	return i1 & i2
+}
+
+// Computes i1 | i2 like API.OrU64.
+func OrU64Native(i1, i2 uint64) uint64 {
+	return i1 | i2
+}
+
+// Computes i1 ^ i2 like API.XorU64.
+func XorU64Native(i1, i2 uint64) uint64 {
+	return i1 ^ i2
+}
+
+// Computes ^i1 like API.NotU64.
+func NotU64Native(i1 uint64) uint64 {
+	return ^i1
+}
+
+// Computes i1 << offset like API.ShlU64.
+func ShlU64Native(i1 uint64, offset int) uint64 {
+	return i1 << offset
+}
+
+// Computes i1 >> offset like API.ShrU64.
+func ShrU64Native(i1 uint64, offset int) uint64 {
+	return i1 >> offset
+}
+
+// Rotates i1 by offset bits to the left like API.RotateLeftU64.
+func RotateLeftU64Native(i1 uint64, offset int) uint64 {
+	return bits.RotateLeft64(i1, offset)
+}
+
+// Rotates i1 by offset bits to the right like API.RotateRightU64.
+func RotateRightU64Native(i1 uint64, offset int) uint64 {
+	return bits.RotateLeft64(i1, -offset)
+}
@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/utils/byteutils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The labeled assertions add the same constraints as the plain ones, and before them a check which
+// fails with the label and the offending values when the assertion does not hold, e.g.
+// "header.parentHash mismatch: byte 3 is 0x01, expected 0x02". The label is stored in the
+// constraint system as constant inputs of the check, so it survives the serialization of the
+// constraint system and is reported by any solver of it. The check adds a single constraint.
+
+// The kinds of labeled assertions, which are the first input of assertHint.
+const (
+	assertIsEqual = iota
+	assertIsLessThan
+	assertIsEqualBytes
+)
+
+// Asserts that i1 == i2, and fails with the label when solving otherwise.
+func (a *API) AssertIsEqualLabeled(i1, i2 vars.Variable, label string) {
+	a.checkLabeled(assertIsEqual, label, i1, i2)
+	a.AssertIsEqual(i1, i2)
+}
+
+// Asserts that i1 < i2 for i1 and i2 in [0, 2^nbBits), which must be less than the number of bits
+// of the field, and fails with the label when solving otherwise.
+func (a *API) AssertIsLessThanLabeled(i1, i2 vars.Variable, nbBits int, label string) {
+	if nbBits <= 0 || nbBits >= a.api.Compiler().FieldBitLen() {
+		panic(fmt.Sprintf("cannot compare integers of %d bits", nbBits))
+	}
+	a.checkLabeled(assertIsLessThan, label, i1, i2)
+	a.AssertIsInRange(i1, nbBits)
+	a.AssertIsInRange(i2, nbBits)
+	// i2 - i1 - 1 is in [0, 2^nbBits) if i1 < i2, and wraps around the field otherwise.
+	a.AssertIsInRange(a.Sub(i2, i1, vars.ONE), nbBits)
+}
+
+// Asserts that the bytes are equal, and fails with the label and the first byte which differs when
+// solving otherwise.
+func (a *API) AssertIsEqualBytesLabeled(i1, i2 []vars.Byte, label string) {
+	if len(i1) != len(i2) {
+		panic(fmt.Sprintf("%s: cannot compare %d bytes to %d bytes", label, len(i1), len(i2)))
+	}
+	operands := make([]vars.Variable, 0, 2*len(i1))
+	for i := range i1 {
+		operands = append(operands, i1[i].Value)
+	}
+	for i := range i2 {
+		operands = append(operands, i2[i].Value)
+	}
+	a.checkLabeled(assertIsEqualBytes, label, operands...)
+	for i := range i1 {
+		a.AssertIsEqualByte(i1[i], i2[i])
+	}
+}
+
+// Adds the check of a labeled assertion. The check is a hint, which is solved before the
+// constraints of the assertion since it is added first, and whose only output is zero.
+func (a *API) checkLabeled(kind int, label string, operands ...vars.Variable) {
+	elements := byteutils.PackBytes([]byte(label))
+	inputs := make([]vars.Variable, 0, 2+len(elements)+len(operands))
+	inputs = append(inputs, vars.NewVariableFromInt(kind), vars.NewVariableFromInt(len(label)))
+	for i := range elements {
+		inputs = append(inputs, vars.Variable{Value: elements[i]})
+	}
+	inputs = append(inputs, operands...)
+	a.AssertIsEqual(a.Hint(assertHint, 1, inputs...)[0], vars.ZERO)
+}
+
+// Checks a labeled assertion, whose inputs are its kind, the length of the label, the label packed
+// into field elements, and its operands.
+var assertHint = NewHint("builder.assert", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].SetUint64(0)
+	kind, nbLabelBytes := inputs[0].Int64(), int(inputs[1].Int64())
+	nbElements := byteutils.NbPackedElements(nbLabelBytes)
+	label, err := byteutils.UnpackBytes(inputs[2:2+nbElements], nbLabelBytes)
+	if err != nil {
+		return fmt.Errorf("invalid label: %w", err)
+	}
+	operands := inputs[2+nbElements:]
+	switch kind {
+	case assertIsEqual:
+		if operands[0].Cmp(operands[1]) != 0 {
+			return fmt.Errorf("%s: %s, expected %s", label, operands[0], operands[1])
+		}
+	case assertIsLessThan:
+		if operands[0].Cmp(operands[1]) >= 0 {
+			return fmt.Errorf("%s: %s is not less than %s", label, operands[0], operands[1])
+		}
+	case assertIsEqualBytes:
+		n := len(operands) / 2
+		for i := 0; i < n; i++ {
+			if operands[i].Cmp(operands[n+i]) != 0 {
+				return fmt.Errorf("%s: byte %d is 0x%02x, expected 0x%02x", label, i, operands[i], operands[n+i])
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unknown assertion %d", label, kind)
+	}
+	return nil
+})
@@ -0,0 +1,68 @@
+package builder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestLabeledAssertCircuit struct {
+	X, Y       vars.Variable
+	ParentHash [4]vars.Byte
+	Expected   [4]vars.Byte
+}
+
+func (c *TestLabeledAssertCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	a.AssertIsEqualLabeled(a.Mul(c.X, c.X), c.Y, "square")
+	a.AssertIsLessThanLabeled(c.X, c.Y, 8, "x < y")
+	a.AssertIsEqualBytesLabeled(c.ParentHash[:], c.Expected[:], "header.parentHash mismatch")
+	return nil
+}
+
+func TestLabeledAssertions(t *testing.T) {
+	newAssignment := func(x, y int, parentHash [4]byte) *TestLabeledAssertCircuit {
+		c := &TestLabeledAssertCircuit{X: vars.NewVariableFromInt(x), Y: vars.NewVariableFromInt(y)}
+		for i := 0; i < 4; i++ {
+			c.ParentHash[i].Set(parentHash[i])
+			c.Expected[i].Set(byte(i + 1))
+		}
+		return c
+	}
+	field := ecc.BN254.ScalarField()
+	assert.NoError(t, test.IsSolved(&TestLabeledAssertCircuit{}, newAssignment(3, 9, [4]byte{1, 2, 3, 4}), field))
+
+	// The failures are labeled by the test engine, and by the solver of the constraint system
+	// after it has been serialized.
+	ccs, err := frontend.Compile(field, r1cs.NewBuilder, &TestLabeledAssertCircuit{})
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = ccs.WriteTo(&buf)
+	assert.NoError(t, err)
+	decoded := groth16.NewCS(ecc.BN254)
+	_, err = decoded.ReadFrom(&buf)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		assignment *TestLabeledAssertCircuit
+		message    string
+	}{
+		{newAssignment(3, 10, [4]byte{1, 2, 3, 4}), "square: 9, expected 10"},
+		{newAssignment(1, 1, [4]byte{1, 2, 3, 4}), "x < y: 1 is not less than 1"},
+		{newAssignment(3, 9, [4]byte{1, 2, 0xab, 4}), "header.parentHash mismatch: byte 2 is 0xab, expected 0x03"},
+	} {
+		assert.ErrorContains(t, test.IsSolved(&TestLabeledAssertCircuit{}, tc.assignment, field), tc.message)
+		witness, err := frontend.NewWitness(tc.assignment, field)
+		assert.NoError(t, err)
+		assert.ErrorContains(t, ccs.IsSolved(witness), tc.message)
+		assert.ErrorContains(t, decoded.IsSolved(witness), tc.message)
+	}
+}
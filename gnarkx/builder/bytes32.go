@@ -0,0 +1,143 @@
+package builder
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The conversions and comparisons of vars.Bytes32 and vars.Address treat their bytes as big-endian
+// integers like Solidity does, and assume that the bytes are in [0, 256).
+
+// Converts a bytes32 to a u256, like uint256(b) in Solidity.
+func (a *API) ToU256FromBytes32(i1 vars.Bytes32) vars.U256 {
+	var result vars.U256
+	for i := range result.Limbs {
+		result.Limbs[i] = vars.U64{Value: a.fromBytesBE(i1[32-8*(i+1) : 32-8*i])}
+	}
+	return result
+}
+
+// Converts a u256 to a bytes32, like bytes32(u) in Solidity.
+func (a *API) ToBytes32FromU256(i1 vars.U256) vars.Bytes32 {
+	var result vars.Bytes32
+	for i := range i1.Limbs {
+		bytes := a.ToBytes32FromU64LE(i1.Limbs[i])
+		for j := 0; j < 8; j++ {
+			result[32-8*i-j-1] = bytes[j]
+		}
+	}
+	return result
+}
+
+// Converts a bytes32 to the field element it encodes. The circuit is not satisfiable if the
+// bytes32 is not less than the modulus of the field, so that every field element has a single
+// encoding.
+func (a *API) ToVariableFromBytes32(i1 vars.Bytes32) vars.Variable {
+	result := a.fromBytesBE(i1[:])
+	a.AssertIsEqualBytes32(a.ToBytes32FromVariable(result), i1)
+	return result
+}
+
+// Converts a field element to its canonical encoding as a bytes32.
+func (a *API) ToBytes32FromVariable(i1 vars.Variable) vars.Bytes32 {
+	bits := a.ToBinaryLE(i1, a.api.Compiler().FieldBitLen())
+	var result vars.Bytes32
+	for i := 0; i < 32; i++ {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			if k := 8*i + j; k < len(bits) {
+				byteBits[j] = bits[k]
+			} else {
+				byteBits[j] = vars.FALSE
+			}
+		}
+		result[32-i-1] = a.ToByteFromBits(byteBits)
+	}
+	return result
+}
+
+// Returns whether i1 == i2.
+func (a *API) IsEqualBytes32(i1, i2 vars.Bytes32) vars.Bool {
+	return a.IsEqualU256(a.ToU256FromBytes32(i1), a.ToU256FromBytes32(i2))
+}
+
+// Returns whether i1 < i2, like uint256(i1) < uint256(i2) in Solidity.
+func (a *API) IsLessThanBytes32(i1, i2 vars.Bytes32) vars.Bool {
+	return a.IsLessThanU256(a.ToU256FromBytes32(i1), a.ToU256FromBytes32(i2))
+}
+
+// Asserts that i1 == i2.
+func (a *API) AssertIsEqualBytes32(i1, i2 vars.Bytes32) {
+	a.AssertIsEqualU256(a.ToU256FromBytes32(i1), a.ToU256FromBytes32(i2))
+}
+
+// Converts an address to a bytes32 left-padded with zeros, like bytes32(uint256(uint160(address)))
+// in Solidity.
+func (a *API) ToBytes32FromAddress(i1 vars.Address) vars.Bytes32 {
+	var result vars.Bytes32
+	for i := 0; i < 12; i++ {
+		result[i] = vars.ZERO_BYTE
+	}
+	copy(result[12:], i1[:])
+	return result
+}
+
+// Converts a bytes32 to an address by truncating it to its last 20 bytes, like
+// address(uint160(uint256(b))) in Solidity.
+func (a *API) ToAddressFromBytes32(i1 vars.Bytes32) vars.Address {
+	return vars.ToAddress(i1[12:])
+}
+
+// Converts an address to a u256, like uint256(uint160(address)) in Solidity.
+func (a *API) ToU256FromAddress(i1 vars.Address) vars.U256 {
+	return a.ToU256FromBytes32(a.ToBytes32FromAddress(i1))
+}
+
+// Converts a u256 to an address. The circuit is not satisfiable if the u256 does not fit in 160
+// bits.
+func (a *API) ToAddressFromU256(i1 vars.U256) vars.Address {
+	a.AssertIsEqual(i1.Limbs[3].Value, vars.ZERO)
+	a.AssertIsInRange(i1.Limbs[2].Value, 32)
+	return a.ToAddressFromBytes32(a.ToBytes32FromU256(i1))
+}
+
+// Converts an address to a field element, which it always fits in.
+func (a *API) ToVariableFromAddress(i1 vars.Address) vars.Variable {
+	return a.fromBytesBE(i1[:])
+}
+
+// Converts a field element to an address. The circuit is not satisfiable if the field element does
+// not fit in 160 bits.
+func (a *API) ToAddressFromVariable(i1 vars.Variable) vars.Address {
+	bits := a.ToBinaryLE(i1, 160)
+	var result vars.Address
+	for i := 0; i < 20; i++ {
+		var byteBits [8]vars.Bool
+		copy(byteBits[:], bits[8*i:8*(i+1)])
+		result[20-i-1] = a.ToByteFromBits(byteBits)
+	}
+	return result
+}
+
+// Returns whether i1 == i2.
+func (a *API) IsEqualAddress(i1, i2 vars.Address) vars.Bool {
+	return a.IsZero(a.Sub(a.ToVariableFromAddress(i1), a.ToVariableFromAddress(i2)))
+}
+
+// Returns whether i1 < i2, like i1 < i2 in Solidity.
+func (a *API) IsLessThanAddress(i1, i2 vars.Address) vars.Bool {
+	return a.IsLessThanU256(a.ToU256FromAddress(i1), a.ToU256FromAddress(i2))
+}
+
+// Asserts that i1 == i2.
+func (a *API) AssertIsEqualAddress(i1, i2 vars.Address) {
+	a.AssertIsEqual(a.ToVariableFromAddress(i1), a.ToVariableFromAddress(i2))
+}
+
+// Computes the value of big-endian bytes.
+func (a *API) fromBytesBE(bytes []vars.Byte) vars.Variable {
+	result := vars.ZERO
+	for i := range bytes {
+		result = a.Add(a.Mul(result, vars.NewVariableFromInt(256)), bytes[i].Value)
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestBytes32Circuit struct {
+	A, B            vars.Bytes32
+	U256            vars.U256
+	Variable        vars.Variable
+	Equal, LessThan vars.Bool
+	Address, Other  vars.Address
+	AddressWord     vars.Bytes32
+	AddressLessThan vars.Bool
+	AddressVariable vars.Variable
+	AddressU256     vars.U256
+	CheckedVariable bool `gnark:"-"`
+	CheckedAddress  bool `gnark:"-"`
+}
+
+func (c *TestBytes32Circuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	a.AssertIsEqualU256(a.ToU256FromBytes32(c.A), c.U256)
+	a.AssertIsEqualBytes32(a.ToBytes32FromU256(c.U256), c.A)
+	a.AssertIsEqualBool(a.IsEqualBytes32(c.A, c.B), c.Equal)
+	a.AssertIsEqualBool(a.IsLessThanBytes32(c.A, c.B), c.LessThan)
+	if c.CheckedVariable {
+		a.AssertIsEqualBytes32(a.ToBytes32FromVariable(c.Variable), c.B)
+		a.AssertIsEqual(a.ToVariableFromBytes32(c.B), c.Variable)
+	}
+
+	// The bytes32 returned by the gadgets are bytes32 variables, and the other way around.
+	var word [32]vars.Byte = a.ToBytes32FromAddress(c.Address)
+	a.AssertIsEqualBytes32(word, c.AddressWord)
+	a.AssertIsEqualAddress(a.ToAddressFromBytes32(c.AddressWord), c.Address)
+	a.AssertIsEqualBool(a.IsLessThanAddress(c.Address, c.Other), c.AddressLessThan)
+	a.AssertIsEqualBool(a.IsEqualAddress(c.Address, c.Address), vars.TRUE)
+	a.AssertIsEqual(a.ToVariableFromAddress(c.Address), c.AddressVariable)
+	a.AssertIsEqualU256(a.ToU256FromAddress(c.Address), c.AddressU256)
+	if c.CheckedAddress {
+		a.AssertIsEqualAddress(a.ToAddressFromVariable(c.AddressVariable), c.Address)
+		a.AssertIsEqualAddress(a.ToAddressFromU256(c.AddressU256), c.Address)
+	}
+	return nil
+}
+
+func assignBytes32Circuit(x, y *big.Int, address, other common.Address) *TestBytes32Circuit {
+	c := &TestBytes32Circuit{
+		U256:            newU256(x),
+		Variable:        vars.Variable{Value: new(big.Int).Mod(y, ecc.BN254.ScalarField())},
+		Equal:           vars.NewBool(x.Cmp(y) == 0),
+		LessThan:        vars.NewBool(x.Cmp(y) < 0),
+		AddressLessThan: vars.NewBool(address.Big().Cmp(other.Big()) < 0),
+		AddressVariable: vars.Variable{Value: address.Big()},
+		AddressU256:     newU256(address.Big()),
+	}
+	c.A.Set(common.BigToHash(x))
+	c.B.Set(common.BigToHash(y))
+	c.Address.Set(address)
+	c.Other.Set(other)
+	c.AddressWord.Set(common.BytesToHash(address.Bytes()))
+	return c
+}
+
+func TestBytes32Conversions(t *testing.T) {
+	assert := test.NewAssert(t)
+	x, _ := new(big.Int).SetString("d1b3a7f5e0c2f86a0b7a2c5e9f1d3b7a4c6e8f0a2b4d6f8091a3c5e7f9b1d3e5", 16)
+	y, _ := new(big.Int).SetString("1d3b7a4c6e8f0a2b4d6f8091a3c5e7f9b1d3e5d1b3a7f5e0c2f86a0b7a2c5e9f", 16)
+	address := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	other := common.HexToAddress("0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984")
+
+	// Only y is less than the modulus, so B is the encoding of a field element if it is y.
+	for _, tc := range [][2]*big.Int{{x, y}, {y, y}, {big.NewInt(0), big.NewInt(1)}} {
+		circuit := &TestBytes32Circuit{CheckedVariable: true, CheckedAddress: true}
+		assert.NoError(test.IsSolved(circuit, assignBytes32Circuit(tc[0], tc[1], address, other), ecc.BN254.ScalarField()))
+		circuit = &TestBytes32Circuit{CheckedAddress: true}
+		assert.NoError(test.IsSolved(circuit, assignBytes32Circuit(tc[1], tc[0], other, address), ecc.BN254.ScalarField()))
+	}
+
+	// A bytes32 which is not less than the modulus is not the encoding of a field element.
+	assert.Error(test.IsSolved(&TestBytes32Circuit{CheckedVariable: true}, assignBytes32Circuit(y, x, address, other), ecc.BN254.ScalarField()))
+
+	// A wrong comparison is rejected.
+	circuit := assignBytes32Circuit(x, y, address, other)
+	circuit.LessThan = vars.NewBool(true)
+	assert.Error(test.IsSolved(&TestBytes32Circuit{}, circuit, ecc.BN254.ScalarField()))
+
+	// A value of more than 160 bits is not an address.
+	circuit = assignBytes32Circuit(x, y, address, other)
+	circuit.AddressVariable = vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), 160)}
+	circuit.AddressU256 = newU256(new(big.Int).Lsh(big.NewInt(1), 160))
+	assert.Error(test.IsSolved(&TestBytes32Circuit{CheckedAddress: true}, circuit, ecc.BN254.ScalarField()))
+}
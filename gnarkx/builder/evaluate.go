@@ -0,0 +1,144 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An evaluation runs the circuit on the values of an assignment without compiling it, i.e. with
+// the test engine of gnark, where every variable has a concrete value. The values passed to
+// API.Inspect are recorded along with the scopes in which they were inspected, so intermediate
+// values of gadgets can be compared with a reference implementation when debugging a mismatch.
+//
+// The evaluation stops at the first assertion which does not hold, so the snapshots recorded until
+// then show how far the circuit got.
+type Evaluation struct {
+	Snapshots []Snapshot
+	stack     []string
+}
+
+// The values inspected at one point of an evaluation.
+type Snapshot struct {
+	// The path of the scopes in which the values were inspected, e.g. "mpt/keccak256".
+	Scope  string
+	Name   string
+	Values []*big.Int
+}
+
+// The running evaluation, if any.
+var activeEvaluation *Evaluation
+
+// Evaluates the circuit on the assignment. Returns the recorded snapshots along with the error of
+// the first assertion which does not hold, if any.
+func Evaluate(field *big.Int, circuit frontend.Circuit, assignment frontend.Circuit) (*Evaluation, error) {
+	if activeEvaluation != nil {
+		panic("an evaluation is already running")
+	}
+	e := &Evaluation{}
+	activeEvaluation = e
+	defer func() {
+		activeEvaluation = nil
+	}()
+	err := test.IsSolved(circuit, assignment, field)
+	return e, err
+}
+
+func (e *Evaluation) open(name string) func() {
+	e.stack = append(e.stack, name)
+	depth := len(e.stack)
+	return func() {
+		if len(e.stack) != depth || e.stack[depth-1] != name {
+			panic(fmt.Sprintf("scope %s is closed out of order", name))
+		}
+		e.stack = e.stack[:depth-1]
+	}
+}
+
+// Returns the snapshots with the name, in the order in which they were recorded.
+func (e *Evaluation) Find(name string) []Snapshot {
+	var result []Snapshot
+	for _, snapshot := range e.Snapshots {
+		if snapshot.Name == name {
+			result = append(result, snapshot)
+		}
+	}
+	return result
+}
+
+// Returns the snapshots as text, one per line.
+func (e *Evaluation) String() string {
+	var sb strings.Builder
+	for _, snapshot := range e.Snapshots {
+		values := make([]string, len(snapshot.Values))
+		for i := range snapshot.Values {
+			values[i] = snapshot.Values[i].String()
+		}
+		fmt.Fprintf(&sb, "%s %s: [%s]\n", snapshot.Scope, snapshot.Name, strings.Join(values, " "))
+	}
+	return sb.String()
+}
+
+// Returns whether the circuit is being evaluated, in which case inspections are recorded. Gadgets
+// use it to skip preparing values for inspection which would add constraints to a compiled circuit.
+func (a *API) IsEvaluating() bool {
+	return activeEvaluation != nil
+}
+
+// Records the values under the name if the circuit is being evaluated, and does nothing otherwise,
+// so inspections may stay in gadgets at no cost.
+func (a *API) Inspect(name string, values ...vars.Variable) {
+	e := activeEvaluation
+	if e == nil {
+		return
+	}
+	snapshot := Snapshot{Scope: strings.Join(e.stack, "/"), Name: name, Values: make([]*big.Int, len(values))}
+	for i := range values {
+		snapshot.Values[i] = a.valueOf(values[i])
+	}
+	e.Snapshots = append(e.Snapshots, snapshot)
+}
+
+// Records the words under the name like Inspect, where each word is given by its bits in
+// big-endian order, e.g. the state of SHA-256. The words are packed outside of the circuit.
+func (a *API) InspectWords(name string, words ...[]vars.Bool) {
+	e := activeEvaluation
+	if e == nil {
+		return
+	}
+	snapshot := Snapshot{Scope: strings.Join(e.stack, "/"), Name: name, Values: make([]*big.Int, len(words))}
+	for i := range words {
+		snapshot.Values[i] = new(big.Int)
+		for j := range words[i] {
+			snapshot.Values[i].Lsh(snapshot.Values[i], 1)
+			snapshot.Values[i].Add(snapshot.Values[i], a.valueOf(words[i][j].Value))
+		}
+	}
+	e.Snapshots = append(e.Snapshots, snapshot)
+}
+
+// Returns the value of the variable while the circuit is being evaluated.
+func (a *API) valueOf(v vars.Variable) *big.Int {
+	// The test engine knows the value of every variable, even if it is not a constant.
+	value, _ := a.api.Compiler().ConstantValue(v.Value)
+	if value == nil {
+		panic("inspected values are only known when evaluating a circuit with Evaluate")
+	}
+	return new(big.Int).Set(value)
+}
+
+// Records the bytes under the name like Inspect.
+func (a *API) InspectBytes(name string, bytes []vars.Byte) {
+	if activeEvaluation == nil {
+		return
+	}
+	values := make([]vars.Variable, len(bytes))
+	for i := range bytes {
+		values[i] = bytes[i].Value
+	}
+	a.Inspect(name, values...)
+}
@@ -0,0 +1,54 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestEvaluateCircuit struct {
+	X, Y vars.Variable
+}
+
+func (circuit *TestEvaluateCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	x := circuit.X
+	for i := 0; i < 3; i++ {
+		endScope := succinctAPI.Scope("square")
+		x = succinctAPI.Mul(x, x)
+		succinctAPI.Inspect("x", x)
+		endScope()
+	}
+	bits := succinctAPI.ToBinaryBE(circuit.X, 8)
+	succinctAPI.InspectWords("bits", bits[:4], bits[4:])
+	succinctAPI.AssertIsEqual(x, circuit.Y)
+	succinctAPI.Inspect("done", x, circuit.Y)
+	return nil
+}
+
+func TestEvaluate(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	evaluation, err := builder.Evaluate(field, &TestEvaluateCircuit{}, &TestEvaluateCircuit{X: vars.NewVariableFromInt(3), Y: vars.NewVariableFromInt(6561)})
+	assert.NoError(t, err)
+	assert.Len(t, evaluation.Snapshots, 5)
+	assert.Equal(t, builder.Snapshot{Scope: "square", Name: "x", Values: []*big.Int{big.NewInt(81)}}, evaluation.Find("x")[1])
+	assert.Equal(t, []*big.Int{big.NewInt(0), big.NewInt(3)}, evaluation.Find("bits")[0].Values)
+	assert.Equal(t, "", evaluation.Find("done")[0].Scope)
+	assert.Contains(t, evaluation.String(), "square x: [6561]\n")
+
+	// The evaluation stops at the assertion which does not hold, with the snapshots recorded so far.
+	evaluation, err = builder.Evaluate(field, &TestEvaluateCircuit{}, &TestEvaluateCircuit{X: vars.NewVariableFromInt(3), Y: vars.NewVariableFromInt(6560)})
+	assert.Error(t, err)
+	assert.Len(t, evaluation.Snapshots, 4)
+	assert.Empty(t, evaluation.Find("done"))
+
+	// Inspections are no-ops when the circuit is compiled.
+	_, err = frontend.Compile(field, r1cs.NewBuilder, &TestEvaluateCircuit{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Applies the transition to the accumulator and each of the first length inputs in order, starting
+// from init, and returns the final accumulator. The remaining inputs are padding, for which the
+// transition is still applied in the circuit but its result is discarded, so len(inputs) is the
+// maximum number of inputs and must be a constant at compile time of the circuit while length may
+// be any variable in [0, len(inputs)].
+//
+// The accumulator may be of any type supported by SelectValue, where the shape of the accumulator
+// must not depend on the input.
+func Fold[A, I any](a *API, init A, inputs []I, length vars.Variable, transition func(api *API, acc A, input I) A) A {
+	return FoldIf(a, init, inputs, a.enableBits(length, len(inputs)), transition)
+}
+
+// Applies the transition to the accumulator and each of the inputs in order like Fold, but only
+// for the inputs whose enable bit is set. The accumulator is kept for the other inputs.
+func FoldIf[A, I any](a *API, init A, inputs []I, isEnabled []vars.Bool, transition func(api *API, acc A, input I) A) A {
+	if len(isEnabled) != len(inputs) {
+		panic("the numbers of inputs and enable bits must be equal")
+	}
+	defer a.Scope("fold")()
+	acc := init
+	for i := range inputs {
+		next := transition(a, acc, inputs[i])
+		acc = SelectValue(a, isEnabled[i], next, acc)
+	}
+	return acc
+}
+
+// Maps each of the first length inputs and reduces the results pairwise in a balanced tree, where
+// the results of the padding inputs are replaced by the identity of the reduction. The reduction
+// must be associative and the identity must be neutral for it, e.g. zero for a sum. The lengths
+// are as in Fold.
+//
+// Unlike Fold, the depth of the reductions is logarithmic in the number of inputs, which matters
+// when the reduction is expensive or grows the bounds of its operands, e.g. additions of limbs.
+func MapReduce[I, O any](
+	a *API,
+	inputs []I,
+	length vars.Variable,
+	identity O,
+	mapFn func(api *API, input I) O,
+	reduceFn func(api *API, left, right O) O,
+) O {
+	if len(inputs) == 0 {
+		return identity
+	}
+	defer a.Scope("map_reduce")()
+	isEnabled := a.enableBits(length, len(inputs))
+	layer := make([]O, len(inputs))
+	for i := range inputs {
+		layer[i] = SelectValue(a, isEnabled[i], mapFn(a, inputs[i]), identity)
+	}
+	for len(layer) > 1 {
+		next := make([]O, (len(layer)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(layer) {
+				next[i] = reduceFn(a, layer[2*i], layer[2*i+1])
+			} else {
+				next[i] = layer[2*i]
+			}
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// Splits the inputs into chunks of chunkSize inputs, maps each of the first nbChunks chunks and
+// reduces the results like MapReduce, which saves the reductions within a chunk when mapping a
+// chunk at once is cheaper than mapping and reducing its inputs, e.g. hashing them. The number of
+// inputs must be a multiple of chunkSize, as is the case for inputs padded with PadChunks.
+func MapReduceChunks[I, O any](
+	a *API,
+	inputs []I,
+	chunkSize int,
+	nbChunks vars.Variable,
+	identity O,
+	mapFn func(api *API, chunk []I) O,
+	reduceFn func(api *API, left, right O) O,
+) O {
+	if chunkSize <= 0 || len(inputs)%chunkSize != 0 {
+		panic(fmt.Sprintf("%d inputs can not be split into chunks of %d inputs", len(inputs), chunkSize))
+	}
+	chunks := make([][]I, len(inputs)/chunkSize)
+	for i := range chunks {
+		chunks[i] = inputs[i*chunkSize : (i+1)*chunkSize]
+	}
+	return MapReduce(a, chunks, nbChunks, identity, mapFn, reduceFn)
+}
+
+// Pads the inputs with the padding to maxChunks chunks of chunkSize inputs for MapReduceChunks,
+// and returns them along with the number of chunks which contain inputs. The last of these chunks
+// is padded as well if the number of inputs is not a multiple of chunkSize, so the padding must
+// not change the result of the map of a chunk, e.g. zero for a sum.
+func PadChunks[I any](inputs []I, chunkSize int, maxChunks int, padding I) ([]I, int) {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("invalid chunk size %d", chunkSize))
+	}
+	nbChunks := (len(inputs) + chunkSize - 1) / chunkSize
+	if nbChunks > maxChunks {
+		panic(fmt.Sprintf("%d inputs do not fit into %d chunks of %d inputs", len(inputs), maxChunks, chunkSize))
+	}
+	result := make([]I, maxChunks*chunkSize)
+	copy(result, inputs)
+	for i := len(inputs); i < len(result); i++ {
+		result[i] = padding
+	}
+	return result, nbChunks
+}
+
+// Returns the enable bits of n inputs of which the first length are enabled.
+func (a *API) enableBits(length vars.Variable, n int) []vars.Bool {
+	mask := a.PrefixMask(length, n)
+	result := make([]vars.Bool, n)
+	for i := range mask {
+		result[i] = vars.Bool{Value: mask[i]}
+	}
+	return result
+}
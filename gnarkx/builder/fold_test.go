@@ -0,0 +1,156 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The state of a machine which sums the squares of its inputs and remembers the last two inputs.
+type testFoldState struct {
+	Sum   vars.Variable
+	Count vars.U64
+	Last  [2]vars.Byte
+}
+
+type TestFoldCircuit struct {
+	Inputs    []vars.Byte
+	Length    vars.Variable
+	Sum       vars.Variable
+	Last      [2]vars.Byte
+	MaxSquare vars.Variable
+}
+
+func (c *TestFoldCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	init := testFoldState{Sum: vars.ZERO, Count: vars.U64{Value: vars.ZERO}, Last: [2]vars.Byte{vars.ZERO_BYTE, vars.ZERO_BYTE}}
+	state := builder.Fold(a, init, c.Inputs, c.Length, func(api *builder.API, acc testFoldState, input vars.Byte) testFoldState {
+		return testFoldState{
+			Sum:   api.Add(acc.Sum, api.Mul(input.Value, input.Value)),
+			Count: api.AddU64(acc.Count, vars.U64{Value: vars.ONE}),
+			Last:  [2]vars.Byte{acc.Last[1], input},
+		}
+	})
+	a.AssertIsEqual(state.Sum, c.Sum)
+	a.AssertIsEqual(state.Count.Value, c.Length)
+	a.AssertIsEqualByte(state.Last[0], c.Last[0])
+	a.AssertIsEqualByte(state.Last[1], c.Last[1])
+
+	// The maximum of the squares, where the reduction of the padding is the identity zero.
+	square := func(api *builder.API, input vars.Byte) vars.Variable {
+		return api.Mul(input.Value, input.Value)
+	}
+	maximum := func(api *builder.API, left, right vars.Variable) vars.Variable {
+		isLess := api.IsZero(api.Sub(api.Cmp(left, right), vars.NewVariableFromInt(-1)))
+		return api.Select(isLess, right, left)
+	}
+	a.AssertIsEqual(builder.MapReduce(a, c.Inputs, c.Length, vars.ZERO, square, maximum), c.MaxSquare)
+	return nil
+}
+
+func newTestFoldCircuit(inputs []byte, maxLength int) *TestFoldCircuit {
+	padded := make([]byte, maxLength)
+	copy(padded, inputs)
+	for i := len(inputs); i < maxLength; i++ {
+		padded[i] = 0xff
+	}
+	sum, maxSquare := 0, 0
+	var last [2]byte
+	for _, b := range inputs {
+		sum += int(b) * int(b)
+		if int(b)*int(b) > maxSquare {
+			maxSquare = int(b) * int(b)
+		}
+		last = [2]byte{last[1], b}
+	}
+	circuit := &TestFoldCircuit{
+		Inputs:    vars.NewBytesFrom(padded),
+		Length:    vars.NewVariableFromInt(len(inputs)),
+		Sum:       vars.NewVariableFromInt(sum),
+		MaxSquare: vars.NewVariableFromInt(maxSquare),
+	}
+	copy(circuit.Last[:], vars.NewBytesFrom(last[:]))
+	return circuit
+}
+
+func TestFold(t *testing.T) {
+	assert := test.NewAssert(t)
+	const maxLength = 7
+
+	inputs := []byte{3, 1, 4, 1, 5, 9, 2}
+	for _, length := range []int{0, 1, 2, 5, maxLength} {
+		circuit := newTestFoldCircuit(inputs[:length], maxLength)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "length %d", length)
+	}
+
+	// The padding does not contribute to the state.
+	circuit := newTestFoldCircuit(inputs[:3], maxLength)
+	witness := newTestFoldCircuit(inputs[:3], maxLength)
+	witness.Sum = vars.NewVariableFromInt(9 + 1 + 16 + 0xff*0xff)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// A length out of range is rejected.
+	witness = newTestFoldCircuit(inputs, maxLength)
+	witness.Length = vars.NewVariableFromInt(maxLength + 1)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
+
+type TestMapReduceChunksCircuit struct {
+	Inputs   []vars.Byte
+	NbChunks vars.Variable
+	Sum      vars.Variable
+}
+
+func (c *TestMapReduceChunksCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	sumOfSquares := func(api *builder.API, chunk []vars.Byte) vars.Variable {
+		sum := vars.ZERO
+		for i := range chunk {
+			sum = api.Add(sum, api.Mul(chunk[i].Value, chunk[i].Value))
+		}
+		return sum
+	}
+	add := func(api *builder.API, left, right vars.Variable) vars.Variable {
+		return api.Add(left, right)
+	}
+	a.AssertIsEqual(builder.MapReduceChunks(a, c.Inputs, 4, c.NbChunks, vars.ZERO, sumOfSquares, add), c.Sum)
+	return nil
+}
+
+func TestMapReduceChunks(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const chunkSize, maxChunks = 4, 5
+	for _, n := range []int{0, 1, 4, 5, 19, 20} {
+		inputs := make([]byte, n)
+		sum := 0
+		for i := range inputs {
+			inputs[i] = byte(3*i + 1)
+			sum += int(inputs[i]) * int(inputs[i])
+		}
+		padded, nbChunks := builder.PadChunks(inputs, chunkSize, maxChunks, 0)
+		assert.Len(padded, chunkSize*maxChunks)
+		assert.Equal((n+chunkSize-1)/chunkSize, nbChunks)
+
+		// The chunks after the first nbChunks ones are ignored, whatever their inputs.
+		for i := chunkSize * nbChunks; i < len(padded); i++ {
+			padded[i] = 0xff
+		}
+		circuit := &TestMapReduceChunksCircuit{
+			Inputs:   vars.NewBytesFrom(padded),
+			NbChunks: vars.NewVariableFromInt(nbChunks),
+			Sum:      vars.NewVariableFromInt(sum),
+		}
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "%d inputs", n)
+	}
+
+	assert.Panics(func() { builder.PadChunks(make([]byte, 21), chunkSize, maxChunks, 0) })
+	assert.Panics(func() {
+		circuit := &TestMapReduceChunksCircuit{Inputs: make([]vars.Byte, 6)}
+		_ = circuit.Define(nil)
+	})
+}
@@ -0,0 +1,186 @@
+package builder
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A hint computes values outside of the circuit, e.g. a quotient or an inverse, which the circuit
+// then only has to verify. The outputs of a hint are unconstrained witnesses, so the circuit must
+// always constrain them, apart from the range checks done by the typed APIs below.
+//
+// Hints are identified by their name instead of their function, so that hints created from
+// closures do not collide. A hint must be created at package initialization, e.g. as a package
+// level variable, so that it is registered before any circuit is solved.
+type Hint struct {
+	id   solver.HintID
+	name string
+}
+
+// Creates a new hint from a function on field elements and registers it under the name, which
+// must be unique. The function gets the inputs and must set all of the outputs.
+func NewHint(name string, f func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error) Hint {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	hint := Hint{id: solver.HintID(h.Sum32()), name: name}
+	if solver.GetRegisteredHint(hint.id) != nil {
+		panic(fmt.Sprintf("hint %s is already registered", name))
+	}
+	solver.RegisterNamedHint(f, hint.id)
+	return hint
+}
+
+// Creates a new hint from a function on u64s and registers it under the name, which must be
+// unique. The function must return exactly as many outputs as requested.
+func NewU64Hint(name string, f func(inputs []uint64) ([]uint64, error)) Hint {
+	return NewHint(name, func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+		in := make([]uint64, len(inputs))
+		for i := range inputs {
+			if !inputs[i].IsUint64() {
+				return fmt.Errorf("hint %s: input %d is not a u64", name, i)
+			}
+			in[i] = inputs[i].Uint64()
+		}
+		out, err := f(in)
+		if err != nil {
+			return fmt.Errorf("hint %s: %w", name, err)
+		}
+		if len(out) != len(outputs) {
+			return fmt.Errorf("hint %s: expected %d outputs, got %d", name, len(outputs), len(out))
+		}
+		for i := range out {
+			outputs[i].SetUint64(out[i])
+		}
+		return nil
+	})
+}
+
+// Creates a new hint from a function on bytes and registers it under the name, which must be
+// unique. The function must return exactly as many outputs as requested.
+func NewBytesHint(name string, f func(inputs []byte) ([]byte, error)) Hint {
+	return NewHint(name, func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+		in := make([]byte, len(inputs))
+		for i := range inputs {
+			if !inputs[i].IsUint64() || inputs[i].Uint64() > 255 {
+				return fmt.Errorf("hint %s: input %d is not a byte", name, i)
+			}
+			in[i] = byte(inputs[i].Uint64())
+		}
+		out, err := f(in)
+		if err != nil {
+			return fmt.Errorf("hint %s: %w", name, err)
+		}
+		if len(out) != len(outputs) {
+			return fmt.Errorf("hint %s: expected %d outputs, got %d", name, len(outputs), len(out))
+		}
+		for i := range out {
+			outputs[i].SetUint64(uint64(out[i]))
+		}
+		return nil
+	})
+}
+
+// Returns the name of the hint.
+func (h Hint) Name() string {
+	return h.name
+}
+
+// Returns the ID the hint is registered under, e.g. to override it with solver.OverrideHint.
+func (h Hint) ID() solver.HintID {
+	return h.id
+}
+
+// Calls the hint and returns its outputs, which are not constrained in any way.
+func (a *API) Hint(hint Hint, nbOutputs int, inputs ...vars.Variable) []vars.Variable {
+	in := make([]frontend.Variable, len(inputs))
+	for i := range inputs {
+		in[i] = inputs[i].Value
+	}
+	outputs, err := a.api.Compiler().NewHintForId(hint.id, nbOutputs, in...)
+	if err != nil {
+		panic(err)
+	}
+	result := make([]vars.Variable, nbOutputs)
+	for i := range outputs {
+		result[i] = vars.Variable{Value: outputs[i]}
+	}
+	return result
+}
+
+// Calls the hint and returns its outputs, which are range checked to be u64s.
+func (a *API) HintU64(hint Hint, nbOutputs int, inputs ...vars.U64) []vars.U64 {
+	in := make([]vars.Variable, len(inputs))
+	for i := range inputs {
+		in[i] = inputs[i].Value
+	}
+	outputs := a.Hint(hint, nbOutputs, in...)
+	result := make([]vars.U64, nbOutputs)
+	for i := range outputs {
+		a.AssertIsU64(outputs[i])
+		result[i] = vars.U64{Value: outputs[i]}
+	}
+	return result
+}
+
+// Calls the hint and returns its outputs, which are range checked to be bytes.
+func (a *API) HintBytes(hint Hint, nbOutputs int, inputs ...vars.Byte) []vars.Byte {
+	in := make([]vars.Variable, len(inputs))
+	for i := range inputs {
+		in[i] = inputs[i].Value
+	}
+	outputs := a.Hint(hint, nbOutputs, in...)
+	result := make([]vars.Byte, nbOutputs)
+	for i := range outputs {
+		a.AssertIsByte(outputs[i])
+		result[i] = vars.Byte{Value: outputs[i]}
+	}
+	return result
+}
+
+// Calls the hint and returns its outputs, which are constrained to be booleans.
+func (a *API) HintBools(hint Hint, nbOutputs int, inputs ...vars.Variable) []vars.Bool {
+	outputs := a.Hint(hint, nbOutputs, inputs...)
+	result := make([]vars.Bool, nbOutputs)
+	for i := range outputs {
+		a.AssertIsBoolean(outputs[i])
+		result[i] = vars.Bool{Value: outputs[i]}
+	}
+	return result
+}
+
+// Decomposes the value into bytes in little-endian order. Asserts that the value is in
+// [0, 256^nbBytes), where nbBytes must be less than 32 so that the bytes do not overflow the field.
+func (a *API) ToBytesLE(i1 vars.Variable, nbBytes int) []vars.Byte {
+	if nbBytes >= 32 {
+		panic("nbBytes must be less than 32")
+	}
+	outputs := a.Hint(toBytesLEHint, nbBytes, i1)
+	bytes := make([]vars.Byte, nbBytes)
+	sum := vars.ZERO
+	for i := nbBytes - 1; i >= 0; i-- {
+		a.AssertIsByte(outputs[i])
+		bytes[i] = vars.Byte{Value: outputs[i]}
+		sum = a.Add(a.Mul(sum, vars.NewVariableFromInt(256)), outputs[i])
+	}
+	a.AssertIsEqual(sum, i1)
+	return bytes
+}
+
+// Decomposes its single input into len(outputs) bytes in little-endian order.
+var toBytesLEHint = NewHint("builder.toBytesLE", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	value := new(big.Int).Set(inputs[0])
+	mask := big.NewInt(255)
+	for i := range outputs {
+		outputs[i].And(value, mask)
+		value.Rsh(value, 8)
+	}
+	if value.Sign() != 0 {
+		return fmt.Errorf("value does not fit into %d bytes", len(outputs))
+	}
+	return nil
+})
@@ -0,0 +1,104 @@
+package builder_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the integer square root of a u64.
+var sqrtHint = builder.NewU64Hint("builder_test.sqrt", func(inputs []uint64) ([]uint64, error) {
+	root := uint64(math.Sqrt(float64(inputs[0])))
+	for root*root > inputs[0] {
+		root--
+	}
+	for (root+1)*(root+1) <= inputs[0] {
+		root++
+	}
+	return []uint64{root}, nil
+})
+
+// Reverses the bytes.
+var reverseHint = builder.NewBytesHint("builder_test.reverse", func(inputs []byte) ([]byte, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("no inputs")
+	}
+	result := make([]byte, len(inputs))
+	for i := range inputs {
+		result[i] = inputs[len(inputs)-1-i]
+	}
+	return result, nil
+})
+
+type TestHintCircuit struct {
+	X        vars.U64
+	Sqrt     vars.U64
+	Bytes    [4]vars.Byte
+	Reversed [4]vars.Byte
+	Value    vars.Variable
+	ValueLE  [3]vars.Byte
+}
+
+func (c *TestHintCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+
+	// The square root is constrained by root^2 <= x < (root + 1)^2.
+	root := a.HintU64(sqrtHint, 1, c.X)[0]
+	a.AssertIsLessOrEqual(a.Mul(root.Value, root.Value), c.X.Value)
+	next := a.Add(root.Value, vars.ONE)
+	a.AssertIsLessOrEqual(a.Add(c.X.Value, vars.ONE), a.Mul(next, next))
+	a.AssertIsEqualU64(root, c.Sqrt)
+
+	reversed := a.HintBytes(reverseHint, 4, c.Bytes[:]...)
+	for i := 0; i < 4; i++ {
+		a.AssertIsEqualByte(reversed[i], c.Reversed[i])
+	}
+
+	bytes := a.ToBytesLE(c.Value, 3)
+	for i := 0; i < 3; i++ {
+		a.AssertIsEqualByte(bytes[i], c.ValueLE[i])
+	}
+	return nil
+}
+
+func TestHint(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func(x uint64, sqrt uint64, value int) *TestHintCircuit {
+		circuit := &TestHintCircuit{
+			X:     vars.U64{Value: vars.NewVariableFromInt(int(x))},
+			Sqrt:  vars.U64{Value: vars.NewVariableFromInt(int(sqrt))},
+			Value: vars.NewVariableFromInt(value),
+		}
+		for i := 0; i < 4; i++ {
+			circuit.Bytes[i].Set(byte(i + 1))
+			circuit.Reversed[i].Set(byte(4 - i))
+		}
+		for i := 0; i < 3; i++ {
+			circuit.ValueLE[i].Set(byte(value >> (8 * i)))
+		}
+		return circuit
+	}
+
+	circuit := newCircuit(1000000007, 31622, 0x123456)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A wrong result of a hint is rejected.
+	circuit = newCircuit(1000000007, 31623, 0x123456)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A value which does not fit into the bytes is rejected.
+	circuit = newCircuit(16, 4, 0x1000000)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A hint name can only be registered once.
+	assert.Panics(func() {
+		builder.NewU64Hint("builder_test.sqrt", func(inputs []uint64) ([]uint64, error) { return inputs, nil })
+	})
+}
@@ -12,8 +12,9 @@ type InputReader struct {
 	bytes []vars.Byte
 }
 
-// Creates a new InputReader.
+// Creates a new InputReader, which registers the bytes as the input bytes of the circuit.
 func NewInputReader(api API, bytes []vars.Byte) *InputReader {
+	api.registerInputs(bytes)
 	return &InputReader{
 		api:   api,
 		ptr:   0,
@@ -21,6 +22,12 @@ func NewInputReader(api API, bytes []vars.Byte) *InputReader {
 	}
 }
 
+// Records the field at the current position in the schema of the circuit.
+func (r *InputReader) record(name string, t FieldType) {
+	schema := r.api.Schema()
+	schema.Inputs = appendField(schema.Inputs, name, "input", t, r.ptr)
+}
+
 // Reads a single byte from the input stream.
 func (r *InputReader) readByte() vars.Byte {
 	out := r.bytes[r.ptr]
@@ -30,6 +37,12 @@ func (r *InputReader) readByte() vars.Byte {
 
 // Reads a byte32 from the input stream.
 func (r *InputReader) ReadBytes32() [32]vars.Byte {
+	return r.ReadNamedBytes32("")
+}
+
+// Reads a byte32 from the input stream, which is named in the schema of the circuit.
+func (r *InputReader) ReadNamedBytes32(name string) [32]vars.Byte {
+	r.record(name, Bytes32Field)
 	var out [32]vars.Byte
 	for i := 0; i < 32; i++ {
 		out[i] = r.readByte()
@@ -39,6 +52,12 @@ func (r *InputReader) ReadBytes32() [32]vars.Byte {
 
 // ReadUint64 reads a uint64 in big-endian from the input stream.
 func (r *InputReader) ReadUint64() vars.U64 {
+	return r.ReadNamedUint64("")
+}
+
+// Reads a uint64 in big-endian from the input stream, which is named in the schema of the circuit.
+func (r *InputReader) ReadNamedUint64(name string) vars.U64 {
+	r.record(name, Uint64Field)
 	out := vars.NewU64()
 	for i := 0; i < 8; i++ {
 		out = r.api.MulU64(out, vars.U64{Value: vars.Variable{Value: 256}})
@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bits of the SHA256 hashes of the input and output bytes which are kept by the
+// function verifier onchain, i.e. it checks sha256(bytes) & ((1 << 253) - 1) so that the hashes
+// fit into the scalar field of BN254.
+const IOHashBits = 253
+
+// A hash function of the input and output bytes, which truncates the hash to its nbBits lowest
+// bits so that it fits into a field element, e.g. sha256.HashAndTruncate. The builder can not
+// depend on the hash functions, so the hash is passed by the caller, e.g. a succinct circuit
+// function.
+type IOHashFunc func(api API, in []vars.Byte, nbBits int) vars.Variable
+
+// The input and output bytes registered by InputReader and OutputWriter.
+type ioBytes struct {
+	inputs  []vars.Byte
+	outputs *OutputWriter
+}
+
+// The key of the registered input and output bytes in the key-value store of the compiler.
+type ioBytesKey struct{}
+
+// Returns the input and output bytes registered so far, which are shared by all instances of API
+// on the same compiler.
+func (a *API) ioBytes() *ioBytes {
+	store, ok := a.api.Compiler().(keyValueStore)
+	if !ok {
+		return &ioBytes{}
+	}
+	if io, ok := store.GetKeyValue(ioBytesKey{}).(*ioBytes); ok {
+		return io
+	}
+	io := &ioBytes{}
+	store.SetKeyValue(ioBytesKey{}, io)
+	return io
+}
+
+// Registers the bytes of an InputReader as the input bytes of the circuit. All input readers of a
+// circuit must read from the same bytes.
+func (a *API) registerInputs(bytes []vars.Byte) {
+	io := a.ioBytes()
+	if io.inputs != nil && !sameBytes(io.inputs, bytes) {
+		panic("input readers must read from the same input bytes")
+	}
+	io.inputs = bytes
+}
+
+// Registers an OutputWriter as the writer of the output bytes of the circuit, of which there may
+// only be one.
+func (a *API) registerOutputs(w *OutputWriter) {
+	io := a.ioBytes()
+	if io.outputs != nil {
+		panic("a circuit may only have one output writer")
+	}
+	io.outputs = w
+}
+
+// Returns whether the slices are the same bytes in memory.
+func sameBytes(b1, b2 []vars.Byte) bool {
+	if len(b1) != len(b2) {
+		return false
+	}
+	return len(b1) == 0 || &b1[0] == &b2[0]
+}
+
+// Computes the hash of all input bytes of the circuit exactly as the function verifier onchain,
+// e.g. sha256(inputBytes) & ((1 << 253) - 1) for sha256.HashAndTruncate, where the input bytes
+// are the bytes read by the InputReader, including the ones which have not been read.
+func (a *API) HashInput(hash IOHashFunc) vars.Variable {
+	io := a.ioBytes()
+	if io.inputs == nil {
+		panic("no input bytes are registered with an InputReader")
+	}
+	return hash(*a, io.inputs, IOHashBits)
+}
+
+// Computes the hash of all output bytes of the circuit exactly as the function verifier onchain,
+// e.g. sha256(outputBytes) & ((1 << 253) - 1) for sha256.HashAndTruncate, where the output bytes
+// are the bytes written to the OutputWriter so far.
+func (a *API) HashOutput(hash IOHashFunc) vars.Variable {
+	io := a.ioBytes()
+	if io.outputs == nil {
+		panic("no output bytes are registered with an OutputWriter")
+	}
+	return hash(*a, io.outputs.bytes, IOHashBits)
+}
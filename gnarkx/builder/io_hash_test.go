@@ -0,0 +1,77 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestIOHashCircuit struct {
+	InputHash   vars.Variable `gnark:",public"`
+	OutputHash  vars.Variable `gnark:",public"`
+	InputBytes  [20]vars.Byte
+	OutputBytes [8]vars.Byte
+}
+
+func (circuit *TestIOHashCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	reader := builder.NewInputReader(*succinctAPI, circuit.InputBytes[:])
+	writer := builder.NewOutputWriter(*succinctAPI)
+
+	// Only the first 8 of the 20 input bytes are read, but all of them are hashed.
+	writer.WriteU64(reader.ReadUint64())
+	writer.Close(circuit.OutputBytes[:])
+
+	succinctAPI.AssertIsEqual(succinctAPI.HashInput(sha256.HashAndTruncate), circuit.InputHash)
+	succinctAPI.AssertIsEqual(builder.NewAPI(api).HashOutput(sha256.HashAndTruncate), circuit.OutputHash)
+	return nil
+}
+
+func newIOHashCircuit(inputBytes []byte, hashedInputBytes []byte) *TestIOHashCircuit {
+	circuit := &TestIOHashCircuit{}
+	for i := range circuit.InputBytes {
+		circuit.InputBytes[i].Set(inputBytes[i])
+	}
+	for i := range circuit.OutputBytes {
+		circuit.OutputBytes[i].Set(inputBytes[i])
+	}
+	circuit.InputHash.Set(sha256utils.HashAndTruncate(hashedInputBytes, builder.IOHashBits))
+	circuit.OutputHash.Set(sha256utils.HashAndTruncate(inputBytes[:8], builder.IOHashBits))
+	return circuit
+}
+
+func TestHashIO(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	inputBytes := make([]byte, 20)
+	for i := range inputBytes {
+		inputBytes[i] = byte(13*i + 250)
+	}
+	assert.NoError(test.IsSolved(&TestIOHashCircuit{}, newIOHashCircuit(inputBytes, inputBytes), ecc.BN254.ScalarField()))
+
+	// The hash of only the bytes which are read, or with a trailing padding byte, is rejected.
+	assert.Error(test.IsSolved(&TestIOHashCircuit{}, newIOHashCircuit(inputBytes, inputBytes[:8]), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(&TestIOHashCircuit{}, newIOHashCircuit(inputBytes, append(inputBytes, 0)), ecc.BN254.ScalarField()))
+}
+
+type TestTwoOutputWritersCircuit struct {
+	X vars.Variable
+}
+
+func (circuit *TestTwoOutputWritersCircuit) Define(api frontend.API) error {
+	builder.NewOutputWriter(*builder.NewAPI(api))
+	builder.NewOutputWriter(*builder.NewAPI(api))
+	return nil
+}
+
+func TestHashIOTwoOutputWriters(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit := &TestTwoOutputWritersCircuit{X: vars.NewVariableFromInt(0)}
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
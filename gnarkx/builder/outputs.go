@@ -13,29 +13,51 @@ type OutputWriter struct {
 	bytes []vars.Byte
 }
 
-// Creates a new OutputWriter.
+// Creates a new OutputWriter, which registers itself as the writer of the output bytes of the
+// circuit.
 func NewOutputWriter(api API) *OutputWriter {
-	return &OutputWriter{
+	w := &OutputWriter{
 		api:   api,
 		ptr:   0,
 		bytes: make([]vars.Byte, 0),
 	}
+	api.registerOutputs(w)
+	return w
 }
 
 // Writes a single u64 to the output stream.
 func (w *OutputWriter) WriteU64(i1 vars.U64) {
+	w.WriteNamedU64("", i1)
+}
+
+// Writes a single u64 to the output stream, which is named in the schema of the circuit.
+func (w *OutputWriter) WriteNamedU64(name string, i1 vars.U64) {
+	w.record(name, Uint64Field)
 	bytes := w.api.ToBytes32FromU64LE(i1)
 	for i := 0; i < 8; i++ {
 		w.bytes = append(w.bytes, bytes[8-i-1])
 	}
 }
 
+// Writes a bytes32 to the output stream.
 func (w *OutputWriter) WriteBytes32(bytes [32]vars.Byte) {
+	w.WriteNamedBytes32("", bytes)
+}
+
+// Writes a bytes32 to the output stream, which is named in the schema of the circuit.
+func (w *OutputWriter) WriteNamedBytes32(name string, bytes [32]vars.Byte) {
+	w.record(name, Bytes32Field)
 	for i := 0; i < 32; i++ {
 		w.bytes = append(w.bytes, bytes[i])
 	}
 }
 
+// Records the field at the current position in the schema of the circuit.
+func (w *OutputWriter) record(name string, t FieldType) {
+	schema := w.api.Schema()
+	schema.Outputs = appendField(schema.Outputs, name, "output", t, len(w.bytes))
+}
+
 func (w *OutputWriter) Close(expectedBytes []vars.Byte) {
 	if len(w.bytes) != len(expectedBytes) {
 		panic("unexpected number of output bytes")
@@ -0,0 +1,65 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/utils/byteutils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes packed into a field element, which is the largest number of bytes whose
+// values are all less than the modulus of the scalar field of BN254.
+const BytesPerElement = byteutils.BytesPerElement
+
+// Returns the number of field elements nbBytes bytes are packed into.
+func NbPackedElements(nbBytes int) int {
+	return byteutils.NbPackedElements(nbBytes)
+}
+
+// Packs the bytes into field elements, e.g. to pass them as 31 times fewer public inputs than the
+// bytes. Each element is the big-endian value of 31 consecutive bytes, except for the last
+// one, which is the big-endian value of the remaining bytes. The bytes are assumed to be in
+// [0, 256). byteutils.PackBytes computes the same elements outside of a circuit.
+func (a *API) PackBytes(data []vars.Byte) []vars.Variable {
+	result := make([]vars.Variable, NbPackedElements(len(data)))
+	for i := range result {
+		end := (i + 1) * BytesPerElement
+		if end > len(data) {
+			end = len(data)
+		}
+		result[i] = a.fromBytesBE(data[i*BytesPerElement : end])
+	}
+	return result
+}
+
+// Unpacks nbBytes bytes from field elements packed like PackBytes. The circuit is not satisfiable
+// if an element is not the value of its bytes, so every sequence of bytes has a single packing.
+func (a *API) UnpackBytes(elements []vars.Variable, nbBytes int) []vars.Byte {
+	if len(elements) != NbPackedElements(nbBytes) {
+		panic(fmt.Sprintf("%d bytes are packed into %d elements, not %d", nbBytes, NbPackedElements(nbBytes), len(elements)))
+	}
+	result := make([]vars.Byte, 0, nbBytes)
+	for i := range elements {
+		n := nbBytes - i*BytesPerElement
+		if n > BytesPerElement {
+			n = BytesPerElement
+		}
+		bytes := a.ToBytesLE(elements[i], n)
+		for j := n - 1; j >= 0; j-- {
+			result = append(result, bytes[j])
+		}
+	}
+	return result
+}
+
+// Asserts that the elements are the packing of the bytes, which is cheaper than comparing the
+// bytes to UnpackBytes if the bytes are already known to be in [0, 256).
+func (a *API) AssertIsPackedBytes(elements []vars.Variable, data []vars.Byte) {
+	packed := a.PackBytes(data)
+	if len(elements) != len(packed) {
+		panic(fmt.Sprintf("%d bytes are packed into %d elements, not %d", len(data), len(packed), len(elements)))
+	}
+	for i := range packed {
+		a.AssertIsEqual(elements[i], packed[i])
+	}
+}
@@ -0,0 +1,75 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/byteutils"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestPackBytesCircuit struct {
+	Packed []vars.Variable `gnark:",public"`
+	Data   []vars.Byte
+}
+
+func (c *TestPackBytesCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	a.AssertIsPackedBytes(c.Packed, c.Data)
+	unpacked := a.UnpackBytes(c.Packed, len(c.Data))
+	for i := range unpacked {
+		a.AssertIsEqualByte(unpacked[i], c.Data[i])
+	}
+	return nil
+}
+
+func TestPackBytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func(data []byte) *TestPackBytesCircuit {
+		elements := byteutils.PackBytes(data)
+		packed := make([]vars.Variable, len(elements))
+		for i := range elements {
+			packed[i] = vars.Variable{Value: elements[i]}
+		}
+		return &TestPackBytesCircuit{Packed: packed, Data: vars.NewBytesFrom(data)}
+	}
+
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(255 - i)
+	}
+	for _, n := range []int{0, 1, 30, 31, 32, 62, 63, 100} {
+		elements := byteutils.PackBytes(data[:n])
+		assert.Len(elements, builder.NbPackedElements(n))
+		unpacked, err := byteutils.UnpackBytes(elements, n)
+		assert.NoError(err)
+		assert.Equal(data[:n], unpacked)
+
+		circuit := newCircuit(data[:n])
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "%d bytes", n)
+	}
+
+	// The elements are big-endian.
+	elements := byteutils.PackBytes(data[:33])
+	assert.Equal(new(big.Int).SetBytes(data[:31]), elements[0])
+	assert.Equal(big.NewInt(0xe0df), elements[1])
+
+	// Elements which do not fit into their bytes are rejected.
+	_, err := byteutils.UnpackBytes(elements, 32)
+	assert.Error(err)
+	_, err = byteutils.UnpackBytes(elements, 64)
+	assert.Error(err)
+	circuit := newCircuit(data[:33])
+	circuit.Packed[1].Value = new(big.Int).Add(elements[1], big.NewInt(1<<16))
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// So are elements which are not the packing of the bytes.
+	circuit = newCircuit(data[:33])
+	circuit.Data[32].Set(0)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,193 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/profile"
+	"github.com/rs/zerolog"
+)
+
+// A profiler attributes the constraints of the circuits compiled while it is running to the named
+// scopes opened with API.Scope, e.g. "sha256" or "mpt". Scopes nest, and scopes with the same name
+// under the same parent are merged, so the report shows where the constraint budget goes.
+//
+// Like the compiler of gnark, the profiler is not thread safe, and at most one profiler may run at
+// a time.
+type Profiler struct {
+	root    *ProfileScope
+	stack   []*ProfileScope
+	session *profile.Profile
+	logger  zerolog.Logger
+}
+
+// The constraints of a named scope and of its children.
+type ProfileScope struct {
+	Name string
+	// The number of times the scope has been opened.
+	NbCalls int
+	// The number of constraints added within the scope, including those of its children.
+	NbConstraints int
+	Children      []*ProfileScope
+}
+
+// The running profiler, if any.
+var activeProfiler *Profiler
+
+// Starts a new profiler, which records constraints until it is stopped.
+func StartProfiler() *Profiler {
+	if activeProfiler != nil {
+		panic("a profiler is already running")
+	}
+	// Every scope is a profiling session of gnark, which logs whenever it starts and stops.
+	previousLogger := logger.Logger()
+	logger.Disable()
+	p := &Profiler{
+		root:    &ProfileScope{Name: "circuit", NbCalls: 1},
+		session: profile.Start(profile.WithNoOutput()),
+		logger:  previousLogger,
+	}
+	p.stack = []*ProfileScope{p.root}
+	activeProfiler = p
+	return p
+}
+
+// Stops the profiler. All scopes must have been closed.
+func (p *Profiler) Stop() {
+	if activeProfiler != p {
+		panic("profiler is not running")
+	}
+	if len(p.stack) != 1 {
+		panic(fmt.Sprintf("scope %s has not been closed", p.stack[len(p.stack)-1].Name))
+	}
+	p.session.Stop()
+	p.root.NbConstraints = p.session.NbConstraints()
+	logger.Set(p.logger)
+	activeProfiler = nil
+}
+
+// Returns the root scope, which contains all constraints of the profiled circuits.
+func (p *Profiler) Root() *ProfileScope {
+	return p.root
+}
+
+// Opens a scope with the name, to which all constraints are attributed until the returned function
+// is called, e.g. defer api.Scope("sha256")(). If neither a profiler, a tracer nor an evaluation is
+// running, this is a no-op.
+func (a *API) Scope(name string) func() {
+	p, t, e := activeProfiler, activeTracer, activeEvaluation
+	if p == nil && t == nil && e == nil {
+		return func() {}
+	}
+	var closers []func()
+	if p != nil {
+		closers = append(closers, p.open(name))
+	}
+	if t != nil {
+		// The location of the gadget which opens the scope.
+		location := "unknown"
+		if _, file, line, ok := runtime.Caller(1); ok {
+			location = fmt.Sprintf("%s:%d", file, line)
+		}
+		closers = append(closers, t.open(name, location))
+	}
+	if e != nil {
+		closers = append(closers, e.open(name))
+	}
+	return func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+}
+
+func (p *Profiler) open(name string) func() {
+	parent := p.stack[len(p.stack)-1]
+	var scope *ProfileScope
+	for _, child := range parent.Children {
+		if child.Name == name {
+			scope = child
+			break
+		}
+	}
+	if scope == nil {
+		scope = &ProfileScope{Name: name}
+		parent.Children = append(parent.Children, scope)
+	}
+	scope.NbCalls++
+	p.stack = append(p.stack, scope)
+
+	// The samples of a session are collected asynchronously, so the count is only read after the
+	// session has been stopped.
+	session := profile.Start(profile.WithNoOutput())
+	return func() {
+		if p.stack[len(p.stack)-1] != scope {
+			panic(fmt.Sprintf("scope %s is closed out of order", name))
+		}
+		session.Stop()
+		scope.NbConstraints += session.NbConstraints()
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+}
+
+// Returns the number of constraints of the scope which are not attributed to any of its children.
+func (s *ProfileScope) NbSelfConstraints() int {
+	nbConstraints := s.NbConstraints
+	for _, child := range s.Children {
+		nbConstraints -= child.NbConstraints
+	}
+	return nbConstraints
+}
+
+// Returns a report of the scopes as a tree, where the children of each scope are sorted by their
+// number of constraints.
+func (p *Profiler) Report() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%12s %7s %8s  %s\n", "constraints", "share", "calls", "scope")
+	p.root.report(&report, p.root.NbConstraints, 0)
+	return report.String()
+}
+
+func (s *ProfileScope) report(w io.Writer, total int, depth int) {
+	share := 100.0
+	if total > 0 {
+		share = 100 * float64(s.NbConstraints) / float64(total)
+	}
+	fmt.Fprintf(w, "%12d %6.2f%% %8d  %s%s\n", s.NbConstraints, share, s.NbCalls, strings.Repeat("  ", depth), s.Name)
+	children := make([]*ProfileScope, len(s.Children))
+	copy(children, s.Children)
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].NbConstraints > children[j].NbConstraints
+	})
+	for _, child := range children {
+		child.report(w, total, depth+1)
+	}
+}
+
+// Writes the scopes in the folded stack format, i.e. one line "circuit;sha256;... count" per
+// scope with the constraints of the scope itself, which flame graph tools take as input.
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	return p.root.writeFolded(w, "")
+}
+
+func (s *ProfileScope) writeFolded(w io.Writer, prefix string) error {
+	stack := s.Name
+	if prefix != "" {
+		stack = prefix + ";" + s.Name
+	}
+	if nbSelf := s.NbSelfConstraints(); nbSelf > 0 {
+		if _, err := fmt.Fprintf(w, "%s %d\n", stack, nbSelf); err != nil {
+			return err
+		}
+	}
+	for _, child := range s.Children {
+		if err := child.writeFolded(w, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package builder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestProfileCircuit struct {
+	X vars.Variable
+}
+
+// Adds n constraints.
+func addConstraints(api *builder.API, x vars.Variable, n int) vars.Variable {
+	for i := 0; i < n; i++ {
+		x = api.Mul(x, x)
+	}
+	return x
+}
+
+func (circuit *TestProfileCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	x := addConstraints(succinctAPI, circuit.X, 1)
+	for i := 0; i < 2; i++ {
+		endOuter := succinctAPI.Scope("outer")
+		x = addConstraints(succinctAPI, x, 3)
+		endInner := succinctAPI.Scope("inner")
+		x = addConstraints(succinctAPI, x, 2)
+		endInner()
+		endOuter()
+	}
+	succinctAPI.AssertIsDifferent(x, vars.ZERO)
+	return nil
+}
+
+func TestProfiler(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	profiler := builder.StartProfiler()
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestProfileCircuit{})
+	profiler.Stop()
+	assert.NoError(err)
+
+	root := profiler.Root()
+	assert.Equal(ccs.GetNbConstraints(), root.NbConstraints)
+	assert.Len(root.Children, 1)
+	outer := root.Children[0]
+	assert.Equal("outer", outer.Name)
+	assert.Equal(2, outer.NbCalls)
+	assert.Equal(10, outer.NbConstraints)
+	assert.Equal(6, outer.NbSelfConstraints())
+	assert.Len(outer.Children, 1)
+	inner := outer.Children[0]
+	assert.Equal("inner", inner.Name)
+	assert.Equal(4, inner.NbConstraints)
+
+	var folded bytes.Buffer
+	assert.NoError(profiler.WriteFolded(&folded))
+	assert.Contains(folded.String(), "circuit;outer 6\ncircuit;outer;inner 4\n")
+	assert.Contains(profiler.Report(), "    inner")
+
+	// Without a profiler, scopes are no-ops.
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestProfileCircuit{})
+	assert.NoError(err)
+}
@@ -0,0 +1,194 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The range checks of a circuit are collected by a single range checker, which is shared by all
+// instances of API on the same compiler. If the circuit supports commitments, the checks are
+// batched into one log-derivative lookup of small limbs when the circuit is compiled, which costs
+// only a few constraints per check instead of one per bit. Otherwise, e.g. for Groth16
+// circuits, each check decomposes the variable into bits. Repeated checks of the same variable
+// are only done once.
+type rangeChecker struct {
+	checker frontend.Rangechecker
+	// The smallest number of bits each variable has been checked against, by canonical variable.
+	checked map[string]int
+}
+
+// The key of the range checker in the key-value store of the compiler.
+type rangeCheckerKey struct{}
+
+// The key-value store implemented by the compilers of gnark, which keeps the state of gadgets
+// for the duration of a compilation.
+type keyValueStore interface {
+	SetKeyValue(key, value any)
+	GetKeyValue(key any) any
+}
+
+// Returns the value of the key in the key-value store of the compiler, which is created on its
+// first use. This allows gadgets to share state, e.g. lookup tables, between all instances of
+// their APIs on the same compiler. The key should be of an unexported type of the gadget.
+func CompilerValue[T any](a *API, key any, create func() T) T {
+	store, ok := a.api.Compiler().(keyValueStore)
+	if !ok {
+		return create()
+	}
+	if value, ok := store.GetKeyValue(key).(T); ok {
+		return value
+	}
+	value := create()
+	store.SetKeyValue(key, value)
+	return value
+}
+
+// Returns the range checker of the compiler, which is created on its first use.
+func (a *API) rangeChecker() *rangeChecker {
+	return CompilerValue(a, rangeCheckerKey{}, a.newRangeChecker)
+}
+
+func (a *API) newRangeChecker() *rangeChecker {
+	var checker frontend.Rangechecker = bitsChecker{api: a.api}
+	if a.SupportsCommitments() {
+		checker = newLookupChecker(a.api)
+	}
+	return &rangeChecker{checker: checker, checked: make(map[string]int)}
+}
+
+// A range checker which decomposes each variable into bits, which needs no commitment.
+type bitsChecker struct {
+	api frontend.API
+}
+
+func (c bitsChecker) Check(v frontend.Variable, nbBits int) {
+	c.api.ToBinary(v, nbBits)
+}
+
+// The number of bits of the limbs of lookupChecker.
+const limbBits = 8
+
+// A range checker which decomposes the variables into limbs which are looked up in a table of all
+// limbs, i.e. in a log-derivative argument which is proven when the circuit is compiled. Unlike
+// the range checker of gnark, the top limb of a check whose number of bits is not a multiple of
+// the size of the limbs is also looked up shifted to the top of a limb, since the lookup alone
+// would only bound it by the size of a limb.
+type lookupChecker struct {
+	api   frontend.API
+	table *logderivlookup.Table
+}
+
+func newLookupChecker(api frontend.API) *lookupChecker {
+	table := logderivlookup.New(api)
+	for i := 0; i < 1<<limbBits; i++ {
+		table.Insert(i)
+	}
+	return &lookupChecker{api: api, table: table}
+}
+
+func (c *lookupChecker) Check(v frontend.Variable, nbBits int) {
+	// The limbs of a check close to the size of the field could sum up to more than it.
+	if nbBits+limbBits >= c.api.Compiler().FieldBitLen() {
+		c.api.ToBinary(v, nbBits)
+		return
+	}
+	nbLimbs := (nbBits + limbBits - 1) / limbBits
+	inputs := []frontend.Variable{v}
+	for i := 0; i < nbLimbs; i++ {
+		inputs = append(inputs, limbBits)
+	}
+	limbs, err := c.api.Compiler().NewHintForId(DecomposeHint.id, nbLimbs, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	var composed frontend.Variable = 0
+	for i := nbLimbs - 1; i >= 0; i-- {
+		composed = c.api.Add(c.api.Mul(composed, 1<<limbBits), limbs[i])
+	}
+	c.api.AssertIsEqual(composed, v)
+	c.table.Lookup(limbs...)
+	if rem := nbBits % limbBits; rem != 0 {
+		c.table.Lookup(c.api.Mul(limbs[nbLimbs-1], 1<<(limbBits-rem)))
+	}
+}
+
+// Decomposes its first input into len(outputs) little-endian limbs, where the i-th limb has as many
+// bits as the (i + 1)-th input. The limbs are not constrained, see Decompose.
+var DecomposeHint = NewHint("builder.decompose", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	value := new(big.Int).Set(inputs[0])
+	for i := range outputs {
+		width := uint(inputs[i+1].Uint64())
+		mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), width), big.NewInt(1))
+		outputs[i].And(value, mask)
+		value.Rsh(value, width)
+	}
+	return nil
+})
+
+// Decomposes the value into little-endian limbs of the given widths, whose sum must be less than
+// the size of the field. Each limb is checked with AssertIsInRange, so the decomposition is batched
+// with all other range checks of the circuit.
+func (a *API) Decompose(i1 vars.Variable, widths ...int) []vars.Variable {
+	inputs := []vars.Variable{i1}
+	for _, width := range widths {
+		inputs = append(inputs, vars.NewVariableFromInt(width))
+	}
+	limbs := a.Hint(DecomposeHint, len(widths), inputs...)
+	sum := vars.ZERO
+	for i := len(limbs) - 1; i >= 0; i-- {
+		a.AssertIsInRange(limbs[i], widths[i])
+		shift := vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), uint(widths[i]))}
+		sum = a.Add(a.Mul(sum, shift), limbs[i])
+	}
+	a.AssertIsEqual(sum, i1)
+	return limbs
+}
+
+// Asserts that the value is in [0, 2^nbBits). Unlike ToBinaryLE, the check does not return the
+// bits, which allows it to be batched with all other range checks of the circuit and to be skipped
+// if the variable has already been checked.
+func (a *API) AssertIsInRange(i1 vars.Variable, nbBits int) {
+	if value, ok := a.api.Compiler().ConstantValue(i1.Value); ok {
+		if value.Sign() < 0 || value.BitLen() > nbBits {
+			panic(fmt.Sprintf("constant %s is not in range of %d bits", value, nbBits))
+		}
+		return
+	}
+
+	r := a.rangeChecker()
+	key := a.canonicalKey(i1)
+	if checkedBits, ok := r.checked[key]; ok && checkedBits <= nbBits {
+		return
+	}
+	r.checked[key] = nbBits
+	r.checker.Check(i1.Value, nbBits)
+}
+
+// Asserts that the value is a byte.
+func (a *API) AssertIsByte(i1 vars.Variable) {
+	a.AssertIsInRange(i1, 8)
+}
+
+// Asserts that the value is a u64.
+func (a *API) AssertIsU64(i1 vars.Variable) {
+	a.AssertIsInRange(i1, 64)
+}
+
+// Returns a key which is equal for two variables if they are the same expression in the
+// constraint system.
+func (a *API) canonicalKey(i1 vars.Variable) string {
+	var words []uint32
+	a.api.Compiler().ToCanonicalVariable(i1.Value).Compress(&words)
+	var key strings.Builder
+	for _, word := range words {
+		key.WriteString(strconv.FormatUint(uint64(word), 16))
+		key.WriteByte(',')
+	}
+	return key.String()
+}
@@ -0,0 +1,164 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestRangeCheckCircuit struct {
+	Bytes     []vars.Variable
+	U64       vars.Variable
+	NbRepeats int `gnark:"-"`
+}
+
+func (circuit *TestRangeCheckCircuit) Define(api frontend.API) error {
+	// The checks are shared by different instances of the API.
+	for i := 0; i < circuit.NbRepeats; i++ {
+		succinctAPI := builder.NewAPI(api)
+		for j := range circuit.Bytes {
+			succinctAPI.AssertIsByte(circuit.Bytes[j])
+		}
+		succinctAPI.AssertIsU64(circuit.U64)
+		succinctAPI.AssertIsInRange(vars.NewVariableFromInt(255), 8)
+	}
+	return nil
+}
+
+type TestConstantRangeCheckCircuit struct {
+	X vars.Variable
+}
+
+func (circuit *TestConstantRangeCheckCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	succinctAPI.AssertIsInRange(circuit.X, 8)
+	succinctAPI.AssertIsInRange(vars.NewVariableFromInt(256), 8)
+	return nil
+}
+
+func newRangeCheckCircuit(nbBytes int, nbRepeats int) *TestRangeCheckCircuit {
+	circuit := &TestRangeCheckCircuit{Bytes: make([]vars.Variable, nbBytes), NbRepeats: nbRepeats}
+	for i := range circuit.Bytes {
+		circuit.Bytes[i] = vars.NewVariableFromInt(i % 256)
+	}
+	circuit.U64 = vars.NewVariableFromString("18446744073709551615")
+	return circuit
+}
+
+func TestAssertIsInRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := newRangeCheckCircuit(300, 1)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A value out of range is rejected.
+	circuit = newRangeCheckCircuit(300, 1)
+	circuit.Bytes[123] = vars.NewVariableFromInt(256)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newRangeCheckCircuit(300, 1)
+	circuit.U64 = vars.NewVariableFromString("18446744073709551616")
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A constant out of range is rejected when the circuit is built.
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestConstantRangeCheckCircuit{})
+	assert.Error(err)
+}
+
+type TestRangeCheckLimbsCircuit struct {
+	Values []vars.Variable
+	X      vars.Variable
+	NbBits int `gnark:"-"`
+}
+
+func (circuit *TestRangeCheckLimbsCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	for i := range circuit.Values {
+		succinctAPI.AssertIsInRange(circuit.Values[i], 48)
+	}
+	succinctAPI.AssertIsInRange(circuit.X, circuit.NbBits)
+	return nil
+}
+
+func TestAssertIsInRangeLimbs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The checks of a number of bits which is not a multiple of the size of the limbs are exact.
+	for _, nbBits := range []int{5, 8, 13, 20} {
+		newCircuit := func(x *big.Int) *TestRangeCheckLimbsCircuit {
+			circuit := &TestRangeCheckLimbsCircuit{Values: make([]vars.Variable, 100), X: vars.Variable{Value: x}, NbBits: nbBits}
+			for i := range circuit.Values {
+				circuit.Values[i] = vars.NewVariableFromInt(i << 40)
+			}
+			return circuit
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+		circuit := newCircuit(new(big.Int).Sub(limit, big.NewInt(1)))
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "nbBits = %d", nbBits)
+		circuit = newCircuit(limit)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "nbBits = %d", nbBits)
+	}
+}
+
+type TestDecomposeCircuit struct {
+	X     vars.Variable
+	Limbs [3]vars.Variable
+}
+
+func (circuit *TestDecomposeCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	limbs := succinctAPI.Decompose(circuit.X, 4, 8, 12)
+	for i := range limbs {
+		succinctAPI.AssertIsEqual(limbs[i], circuit.Limbs[i])
+	}
+	return nil
+}
+
+func TestDecompose(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func(x int, limbs [3]int) *TestDecomposeCircuit {
+		circuit := &TestDecomposeCircuit{X: vars.NewVariableFromInt(x)}
+		for i := range limbs {
+			circuit.Limbs[i] = vars.NewVariableFromInt(limbs[i])
+		}
+		return circuit
+	}
+	circuit := newCircuit(0xabcdef, [3]int{0xf, 0xde, 0xabc})
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newCircuit(0xabcdef, [3]int{0xf, 0xde, 0xabd})
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A value which does not fit into the limbs is rejected.
+	circuit = newCircuit(1<<24, [3]int{0, 0, 1 << 12})
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+func TestAssertIsInRangeConstraints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	compile := func(newBuilder frontend.NewBuilder, circuit frontend.Circuit) (int, int) {
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), newBuilder, circuit)
+		assert.NoError(err)
+		return ccs.GetNbConstraints(), len(ccs.GetCommitments().CommitmentIndexes())
+	}
+
+	// With PLONK, batched checks are cheaper than decomposing every byte into bits, which costs
+	// two constraints per bit.
+	nbConstraints, _ := compile(scs.NewBuilder, newRangeCheckCircuit(300, 1))
+	assert.Less(nbConstraints, 2*300*8)
+
+	// With Groth16, the checks decompose into bits, since the verifiers do not support commitments.
+	nbConstraints, nbCommitments := compile(r1cs.NewBuilder, newRangeCheckCircuit(300, 1))
+	assert.Equal(0, nbCommitments)
+
+	// Repeated checks of the same variables are free.
+	nbRepeatedConstraints, _ := compile(r1cs.NewBuilder, newRangeCheckCircuit(300, 3))
+	assert.Equal(nbConstraints, nbRepeatedConstraints)
+}
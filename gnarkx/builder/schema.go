@@ -0,0 +1,118 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// The type of a field of the inputs or outputs of a circuit, which is named after its type in
+// Solidity.
+type FieldType string
+
+const (
+	// A big-endian u64 of 8 bytes.
+	Uint64Field FieldType = "uint64"
+	// A bytes32 of 32 bytes.
+	Bytes32Field FieldType = "bytes32"
+)
+
+// Returns the number of bytes of the field type.
+func (t FieldType) Width() int {
+	switch t {
+	case Uint64Field:
+		return 8
+	case Bytes32Field:
+		return 32
+	}
+	panic(fmt.Sprintf("unknown field type %s", t))
+}
+
+// A field of the inputs or outputs of a circuit, which is encoded as Width bytes at Offset.
+type SchemaField struct {
+	Name   string    `json:"name"`
+	Type   FieldType `json:"type"`
+	Offset int       `json:"offset"`
+	Width  int       `json:"width"`
+}
+
+// The schema of the inputs and outputs of a circuit, i.e. the fields read by InputReader and
+// written by OutputWriter in the order of their bytes, which are packed without any padding as by
+// abi.encodePacked.
+type Schema struct {
+	Inputs  []SchemaField `json:"inputs"`
+	Outputs []SchemaField `json:"outputs"`
+}
+
+// The key of the schema in the key-value store of the compiler.
+type schemaKey struct{}
+
+// Returns the schema of the inputs and outputs read and written so far in the circuit. The schema
+// is shared by all instances of API on the same compiler, so it is complete once the circuit has
+// been defined.
+func (a *API) Schema() *Schema {
+	store, ok := a.api.Compiler().(keyValueStore)
+	if !ok {
+		return &Schema{}
+	}
+	if schema, ok := store.GetKeyValue(schemaKey{}).(*Schema); ok {
+		return schema
+	}
+	schema := &Schema{}
+	store.SetKeyValue(schemaKey{}, schema)
+	return schema
+}
+
+// Appends a field to the fields, naming it prefix followed by its index if the name is empty.
+func appendField(fields []SchemaField, name string, prefix string, t FieldType, offset int) []SchemaField {
+	if name == "" {
+		name = fmt.Sprintf("%s%d", prefix, len(fields))
+	}
+	return append(fields, SchemaField{Name: name, Type: t, Offset: offset, Width: t.Width()})
+}
+
+// Returns the total number of bytes of the fields.
+func schemaSize(fields []SchemaField) int {
+	size := 0
+	for _, field := range fields {
+		if end := field.Offset + field.Width; end > size {
+			size = end
+		}
+	}
+	return size
+}
+
+// Returns the number of bytes of the inputs.
+func (s *Schema) InputSize() int {
+	return schemaSize(s.Inputs)
+}
+
+// Returns the number of bytes of the outputs.
+func (s *Schema) OutputSize() int {
+	return schemaSize(s.Outputs)
+}
+
+// Exports the schema to a JSON file.
+func (s *Schema) Export(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
+
+// Imports the schema from a JSON file.
+func ImportSchema(file string) (*Schema, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	schema := &Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,65 @@
+package builder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestSchemaCircuit struct {
+	InputBytes  [48]vars.Byte
+	OutputBytes [40]vars.Byte
+	Schema      *builder.Schema `gnark:"-"`
+}
+
+func (circuit *TestSchemaCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	reader := builder.NewInputReader(*succinctAPI, circuit.InputBytes[:])
+	root := reader.ReadNamedBytes32("root")
+	number := reader.ReadNamedUint64("number")
+	reader.ReadUint64()
+
+	// The outputs are written with a different instance of the API.
+	writer := builder.NewOutputWriter(*builder.NewAPI(api))
+	writer.WriteU64(number)
+	writer.WriteNamedBytes32("root", root)
+	writer.Close(circuit.OutputBytes[:])
+
+	circuit.Schema = succinctAPI.Schema()
+	return nil
+}
+
+func TestSchema(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := &TestSchemaCircuit{}
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+
+	expected := &builder.Schema{
+		Inputs: []builder.SchemaField{
+			{Name: "root", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+			{Name: "number", Type: builder.Uint64Field, Offset: 32, Width: 8},
+			{Name: "input2", Type: builder.Uint64Field, Offset: 40, Width: 8},
+		},
+		Outputs: []builder.SchemaField{
+			{Name: "output0", Type: builder.Uint64Field, Offset: 0, Width: 8},
+			{Name: "root", Type: builder.Bytes32Field, Offset: 8, Width: 32},
+		},
+	}
+	assert.Equal(expected, circuit.Schema)
+	assert.Equal(48, circuit.Schema.InputSize())
+	assert.Equal(40, circuit.Schema.OutputSize())
+
+	file := filepath.Join(t.TempDir(), "schema.json")
+	assert.NoError(circuit.Schema.Export(file))
+	imported, err := builder.ImportSchema(file)
+	assert.NoError(err)
+	assert.Equal(expected, imported)
+}
@@ -0,0 +1,73 @@
+package builder
+
+import (
+	"reflect"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var (
+	variableType         = reflect.TypeOf(vars.Variable{})
+	frontendVariableType = reflect.TypeOf((*frontend.Variable)(nil)).Elem()
+)
+
+// Returns i1 if the selector is set and i2 otherwise, where the values may be of any variable type
+// of the vars package or any struct, array or slice built from them, e.g. a decoded header. Both
+// values must have the same shape, i.e. slices of the same lengths, and the fields which are not
+// variables, e.g. constant parameters, must be equal. Structs must not have unexported fields.
+//
+// Every variable is selected with one constraint, so selecting a struct costs as much as
+// selecting each of its fields by hand.
+func SelectValue[T any](a *API, selector vars.Bool, i1, i2 T) T {
+	result := a.selectReflect(selector, reflect.ValueOf(&i1).Elem(), reflect.ValueOf(&i2).Elem())
+	return result.Interface().(T)
+}
+
+// Returns the value of the then branch if the condition is set and the value of the otherwise
+// branch if not, where the values are of a type supported by SelectValue. Note that both branches
+// are always evaluated, so the constraints of both must be satisfiable regardless of the
+// condition, e.g. a branch must not assert something which only holds if it is taken.
+func If[T any](a *API, condition vars.Bool, then func(api *API) T, otherwise func(api *API) T) T {
+	return SelectValue(a, condition, then(a), otherwise(a))
+}
+
+func (a *API) selectReflect(selector vars.Bool, i1, i2 reflect.Value) reflect.Value {
+	t := i1.Type()
+	switch t {
+	case variableType:
+		return reflect.ValueOf(a.Select(selector, i1.Interface().(vars.Variable), i2.Interface().(vars.Variable)))
+	case frontendVariableType:
+		result := reflect.New(t).Elem()
+		result.Set(reflect.ValueOf(a.api.Select(selector.Value.Value, i1.Interface(), i2.Interface())))
+		return result
+	}
+	result := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				panic("cannot select a struct with unexported field " + t.Field(i).Name)
+			}
+			result.Field(i).Set(a.selectReflect(selector, i1.Field(i), i2.Field(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < t.Len(); i++ {
+			result.Index(i).Set(a.selectReflect(selector, i1.Index(i), i2.Index(i)))
+		}
+	case reflect.Slice:
+		if i1.Len() != i2.Len() {
+			panic("cannot select slices of different lengths")
+		}
+		result.Set(reflect.MakeSlice(t, i1.Len(), i1.Len()))
+		for i := 0; i < i1.Len(); i++ {
+			result.Index(i).Set(a.selectReflect(selector, i1.Index(i), i2.Index(i)))
+		}
+	default:
+		if !reflect.DeepEqual(i1.Interface(), i2.Interface()) {
+			panic("cannot select differing values of type " + t.String())
+		}
+		result.Set(i1)
+	}
+	return result
+}
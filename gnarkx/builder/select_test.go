@@ -0,0 +1,79 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type testSelectValue struct {
+	Hash   [2]vars.Byte
+	Number vars.U64
+	Flags  []vars.Bool
+	Raw    frontend.Variable
+	Depth  int `gnark:"-"`
+}
+
+type TestSelectCircuit struct {
+	Condition vars.Bool
+	X, Y      testSelectValue
+	Expected  testSelectValue
+	Sum       vars.Variable
+}
+
+func (c *TestSelectCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	result := builder.SelectValue(a, c.Condition, c.X, c.Y)
+	for i := range result.Hash {
+		a.AssertIsEqualByte(result.Hash[i], c.Expected.Hash[i])
+	}
+	a.AssertIsEqualU64(result.Number, c.Expected.Number)
+	for i := range result.Flags {
+		a.AssertIsEqualBool(result.Flags[i], c.Expected.Flags[i])
+	}
+	api.AssertIsEqual(result.Raw, c.Expected.Raw)
+
+	// The branches compute a sum and a difference of the numbers.
+	sum := builder.If(
+		a,
+		c.Condition,
+		func(api *builder.API) vars.Variable { return api.Add(c.X.Number.Value, c.Y.Number.Value) },
+		func(api *builder.API) vars.Variable { return api.Sub(c.X.Number.Value, c.Y.Number.Value) },
+	)
+	a.AssertIsEqual(sum, c.Sum)
+	return nil
+}
+
+func newTestSelectValue(hash [2]byte, number uint64, flags []bool, raw int) testSelectValue {
+	value := testSelectValue{Raw: raw, Depth: 3}
+	copy(value.Hash[:], vars.NewBytesFrom(hash[:]))
+	value.Number.Set(number)
+	for _, flag := range flags {
+		value.Flags = append(value.Flags, vars.NewBool(flag))
+	}
+	return value
+}
+
+func TestSelect(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	x := newTestSelectValue([2]byte{1, 2}, 100, []bool{true, false, true}, 7)
+	y := newTestSelectValue([2]byte{3, 4}, 40, []bool{false, false, true}, 9)
+	for _, condition := range []bool{true, false} {
+		circuit := &TestSelectCircuit{Condition: vars.NewBool(condition), X: x, Y: y, Expected: y, Sum: vars.NewVariableFromInt(60)}
+		if condition {
+			circuit.Expected = x
+			circuit.Sum = vars.NewVariableFromInt(140)
+		}
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A mix of both values is not selected.
+	mixed := newTestSelectValue([2]byte{1, 2}, 40, []bool{true, false, true}, 7)
+	circuit := &TestSelectCircuit{Condition: vars.NewBool(true), X: x, Y: y, Expected: mixed, Sum: vars.NewVariableFromInt(140)}
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,139 @@
+package builder
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// A sub-circuit is a gadget which is defined once, registered under a unique name, and
+// instantiated any number of times within the circuits which use it, e.g. an audited hash function
+// distributed as a library. Each instance adds the constraints of the gadget for its own inputs,
+// within a scope named after the sub-circuit, so that the profiler, the tracer and the evaluation
+// attribute them to it.
+//
+// A sub-circuit may have shared state, which is set up once per compilation by its first instance
+// and passed to all instances, e.g. a lookup table whose cost is paid once for all lookups. The
+// state lives in the key-value store of the compiler, so it is not shared between compilations.
+//
+// Like hints, sub-circuits must be created at package initialization, e.g. as package level
+// variables, so that the registry lists every sub-circuit a program may use.
+type SubCircuit[S, I, O any] struct {
+	name   string
+	setup  func(api *API) S
+	define func(api *API, shared S, input I) O
+}
+
+// The registered sub-circuits.
+var (
+	subCircuitsMu sync.Mutex
+	subCircuits   = make(map[string]SubCircuitInfo)
+)
+
+// The entry of a sub-circuit in the registry.
+type SubCircuitInfo struct {
+	Name string
+	// The source location which created the sub-circuit, i.e. file:line.
+	Location string
+}
+
+// Creates a new sub-circuit without shared state and registers it under the name, which must be
+// unique.
+func NewSubCircuit[I, O any](name string, define func(api *API, input I) O) *SubCircuit[struct{}, I, O] {
+	s := &SubCircuit[struct{}, I, O]{
+		name:  name,
+		setup: func(*API) struct{} { return struct{}{} },
+		define: func(api *API, _ struct{}, input I) O {
+			return define(api, input)
+		},
+	}
+	registerSubCircuit(name)
+	return s
+}
+
+// Creates a new sub-circuit whose state is set up once per compilation, and registers it under
+// the name, which must be unique.
+func NewSharedSubCircuit[S, I, O any](name string, setup func(api *API) S, define func(api *API, shared S, input I) O) *SubCircuit[S, I, O] {
+	s := &SubCircuit[S, I, O]{name: name, setup: setup, define: define}
+	registerSubCircuit(name)
+	return s
+}
+
+func registerSubCircuit(name string) {
+	// The location of the caller of the constructor.
+	location := "unknown"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		location = fmt.Sprintf("%s:%d", file, line)
+	}
+	subCircuitsMu.Lock()
+	defer subCircuitsMu.Unlock()
+	if existing, ok := subCircuits[name]; ok {
+		panic(fmt.Sprintf("sub-circuit %s is already registered at %s", name, existing.Location))
+	}
+	subCircuits[name] = SubCircuitInfo{Name: name, Location: location}
+}
+
+// Returns the registered sub-circuits, sorted by name.
+func SubCircuits() []SubCircuitInfo {
+	subCircuitsMu.Lock()
+	defer subCircuitsMu.Unlock()
+	result := make([]SubCircuitInfo, 0, len(subCircuits))
+	for _, info := range subCircuits {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Returns the name of the sub-circuit.
+func (s *SubCircuit[S, I, O]) Name() string {
+	return s.name
+}
+
+// Instantiates the sub-circuit for the input and returns its output. The shared state of the
+// sub-circuit is set up by its first instance in the circuit.
+func (s *SubCircuit[S, I, O]) Call(a *API, input I) O {
+	defer a.Scope(s.name)()
+	instances := a.subCircuitInstances()
+	instance, ok := instances[s.name]
+	if !ok {
+		instance = &subCircuitInstance{shared: s.setup(a)}
+		instances[s.name] = instance
+	}
+	instance.nbInstances++
+	return s.define(a, instance.shared.(S), input)
+}
+
+// The instances of a sub-circuit in the circuit being compiled.
+type subCircuitInstance struct {
+	shared      any
+	nbInstances int
+}
+
+// The key of the instances of the sub-circuits in the key-value store of the compiler.
+type subCircuitsKey struct{}
+
+// Returns the instances of the sub-circuits by name, which are shared by all instances of API on
+// the same compiler.
+func (a *API) subCircuitInstances() map[string]*subCircuitInstance {
+	store, ok := a.api.Compiler().(keyValueStore)
+	if !ok {
+		return make(map[string]*subCircuitInstance)
+	}
+	if instances, ok := store.GetKeyValue(subCircuitsKey{}).(map[string]*subCircuitInstance); ok {
+		return instances
+	}
+	instances := make(map[string]*subCircuitInstance)
+	store.SetKeyValue(subCircuitsKey{}, instances)
+	return instances
+}
+
+// Returns the number of instances of each sub-circuit in the circuit so far, by name.
+func (a *API) SubCircuitInstances() map[string]int {
+	result := make(map[string]int)
+	for name, instance := range a.subCircuitInstances() {
+		result[name] = instance.nbInstances
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/lookup"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Squares a byte with a lookup into a table of all squares, which is shared by all instances.
+var squareSubCircuit = builder.NewSharedSubCircuit(
+	"builder_test.square",
+	func(api *builder.API) *lookup.Table {
+		nbSquareTables++
+		return lookup.NewFunctionTable(*api, 8, func(x uint64) uint64 { return x * x })
+	},
+	func(api *builder.API, table *lookup.Table, input vars.Byte) vars.Variable {
+		return table.Lookup(input.Value)
+	},
+)
+
+// Computes the sum of two variables.
+var sumSubCircuit = builder.NewSubCircuit("builder_test.sum", func(api *builder.API, input [2]vars.Variable) vars.Variable {
+	return api.Add(input[0], input[1])
+})
+
+// The number of times the table of squares has been set up.
+var nbSquareTables int
+
+type TestSubCircuitCircuit struct {
+	X, Y        vars.Byte
+	SumOfSquare vars.Variable
+	instances   map[string]int
+}
+
+func (c *TestSubCircuitCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	x2 := squareSubCircuit.Call(a, c.X)
+	y2 := squareSubCircuit.Call(builder.NewAPI(api), c.Y)
+	a.AssertIsEqual(sumSubCircuit.Call(a, [2]vars.Variable{x2, y2}), c.SumOfSquare)
+	c.instances = a.SubCircuitInstances()
+	return nil
+}
+
+func TestSubCircuit(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	newAssignment := func(x, y byte, sum int) *TestSubCircuitCircuit {
+		c := &TestSubCircuitCircuit{SumOfSquare: vars.NewVariableFromInt(sum)}
+		c.X.Set(x)
+		c.Y.Set(y)
+		return c
+	}
+	assert.NoError(t, test.IsSolved(&TestSubCircuitCircuit{}, newAssignment(3, 200, 40009), field))
+	assert.Error(t, test.IsSolved(&TestSubCircuitCircuit{}, newAssignment(3, 200, 40008), field))
+
+	// The shared table is set up once per compilation, with both backends.
+	for _, newBuilder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+		nbSquareTables = 0
+		circuit := &TestSubCircuitCircuit{}
+		_, err := frontend.Compile(field, newBuilder, circuit)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, nbSquareTables)
+		assert.Equal(t, map[string]int{"builder_test.square": 2, "builder_test.sum": 1}, circuit.instances)
+	}
+
+	// The sub-circuits are registered with the location which created them.
+	var names []string
+	for _, info := range builder.SubCircuits() {
+		names = append(names, info.Name)
+		if info.Name == "builder_test.sum" {
+			assert.Contains(t, info.Location, "subcircuit_test.go:30")
+		}
+	}
+	assert.Subset(t, names, []string{"builder_test.square", "builder_test.sum"})
+	assert.Panics(t, func() {
+		builder.NewSubCircuit("builder_test.sum", func(api *builder.API, input vars.Variable) vars.Variable { return input })
+	})
+}
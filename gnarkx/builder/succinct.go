@@ -3,6 +3,8 @@
 package builder
 
 import (
+	"reflect"
+
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -23,3 +25,21 @@ func NewAPI(api frontend.API) *API {
 func (a *API) FrontendAPI() frontend.API {
 	return a.api
 }
+
+// The package of the R1CS compiler of gnark.
+const r1csPkgPath = "github.com/consensys/gnark/frontend/cs/r1cs"
+
+// Returns whether gadgets may commit to the witness, e.g. for log-derivative lookups and range
+// checks. This is not the case for R1CS: the Groth16 verifiers, i.e. the exported Solidity
+// verifier and the recursive verifier, do not check commitments, so gadgets fall back to
+// constraints without commitments when the circuit is compiled for Groth16.
+func (a *API) SupportsCommitments() bool {
+	if _, ok := a.api.(frontend.Committer); !ok {
+		return false
+	}
+	t := reflect.TypeOf(a.api.Compiler())
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.PkgPath() != r1csPkgPath
+}
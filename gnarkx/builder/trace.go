@@ -0,0 +1,188 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/profile"
+	"github.com/rs/zerolog"
+)
+
+// A tracer records the scopes opened with API.Scope while a circuit is compiled, along with the
+// range of constraints added within each of them and the source location which opened it. When
+// solving the witness of the circuit fails, the tracer explains the unsatisfied constraint by the
+// chain of scopes, i.e. gadgets, which added it.
+//
+// Like the profiler, the tracer counts the constraints with the profiling sessions of gnark, so it
+// is not thread safe, and at most one tracer may run at a time.
+type Tracer struct {
+	spans []TraceSpan
+	stack []int
+	// The number of constraints counted so far, and the session counting the next ones.
+	nbConstraints int
+	session       *profile.Profile
+	logger        zerolog.Logger
+}
+
+// A scope opened while the circuit was compiled, which added the constraints in [Start, End)
+// including those of the scopes nested in it.
+type TraceSpan struct {
+	Name string
+	// The source location which opened the scope, i.e. file:line.
+	Location string
+	// The index of the span of the enclosing scope, or -1 for the root.
+	Parent int
+	Start  int
+	End    int
+}
+
+// The running tracer, if any.
+var activeTracer *Tracer
+
+// Starts a new tracer, which records the scopes of the circuit compiled until it is stopped.
+func StartTracer() *Tracer {
+	if activeTracer != nil {
+		panic("a tracer is already running")
+	}
+	previousLogger := logger.Logger()
+	logger.Disable()
+	t := &Tracer{
+		spans:   []TraceSpan{{Name: "circuit", Parent: -1}},
+		stack:   []int{0},
+		session: profile.Start(profile.WithNoOutput()),
+		logger:  previousLogger,
+	}
+	activeTracer = t
+	return t
+}
+
+// Stops the tracer. All scopes must have been closed.
+func (t *Tracer) Stop() {
+	if activeTracer != t {
+		panic("tracer is not running")
+	}
+	if len(t.stack) != 1 {
+		panic(fmt.Sprintf("scope %s has not been closed", t.spans[t.stack[len(t.stack)-1]].Name))
+	}
+	t.session.Stop()
+	t.nbConstraints += t.session.NbConstraints()
+	t.spans[0].End = t.nbConstraints
+	logger.Set(t.logger)
+	activeTracer = nil
+}
+
+// Returns the spans of the scopes in the order in which they were opened, where the first span is
+// the root which contains all constraints.
+func (t *Tracer) Spans() []TraceSpan {
+	return t.spans
+}
+
+// Returns the number of constraints added so far. Since the samples of a profiling session are
+// only complete once it has been stopped, the current session is replaced by a new one.
+func (t *Tracer) count() int {
+	t.session.Stop()
+	t.nbConstraints += t.session.NbConstraints()
+	t.session = profile.Start(profile.WithNoOutput())
+	return t.nbConstraints
+}
+
+func (t *Tracer) open(name string, location string) func() {
+	index := len(t.spans)
+	t.spans = append(t.spans, TraceSpan{Name: name, Location: location, Parent: t.stack[len(t.stack)-1], Start: t.count()})
+	t.stack = append(t.stack, index)
+	return func() {
+		if t.stack[len(t.stack)-1] != index {
+			panic(fmt.Sprintf("scope %s is closed out of order", name))
+		}
+		t.spans[index].End = t.count()
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+}
+
+// Returns the spans of the scopes which added the constraint, from the innermost to the root.
+func (t *Tracer) Scopes(constraintID int) []TraceSpan {
+	// The innermost scope is the last opened one which contains the constraint, since a scope is
+	// opened after its parent.
+	innermost := 0
+	for i := range t.spans {
+		if t.spans[i].Start <= constraintID && constraintID < t.spans[i].End {
+			innermost = i
+		}
+	}
+	var result []TraceSpan
+	for i := innermost; i >= 0; i = t.spans[i].Parent {
+		result = append(result, t.spans[i])
+	}
+	return result
+}
+
+// The error of an unsatisfied constraint, explained by the scopes which added it.
+type TraceError struct {
+	// The error of the solver.
+	Err error
+	// The index of the unsatisfied constraint.
+	ConstraintID int
+	// The values of the unsatisfied constraint as printed by the solver, e.g. "3 ⋅ 1 != 4".
+	Values string
+	// The scopes which added the constraint, from the innermost to the root.
+	Scopes []TraceSpan
+}
+
+func (e *TraceError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "constraint #%d is not satisfied: %s", e.ConstraintID, e.Values)
+	for _, scope := range e.Scopes {
+		if scope.Location == "" {
+			fmt.Fprintf(&sb, "\n\tin %s", scope.Name)
+		} else {
+			fmt.Fprintf(&sb, "\n\tin %s (%s)", scope.Name, scope.Location)
+		}
+	}
+	return sb.String()
+}
+
+func (e *TraceError) Unwrap() error {
+	return e.Err
+}
+
+// The message of the solver for an unsatisfied constraint, for any curve.
+var unsatisfiedConstraintRegexp = regexp.MustCompile(`constraint #(\d+) is not satisfied: (.*)`)
+
+// Explains the error of solving the witness of the traced circuit if it is caused by an
+// unsatisfied constraint, and returns any other error as is.
+func (t *Tracer) Explain(err error) error {
+	if err == nil {
+		return nil
+	}
+	match := unsatisfiedConstraintRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	constraintID, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return err
+	}
+	return &TraceError{Err: err, ConstraintID: constraintID, Values: match[2], Scopes: t.Scopes(constraintID)}
+}
+
+// Compiles the circuit with a tracer and solves it for the assignment, which is the debug mode of
+// witness solving. If a constraint is not satisfied, the error is a *TraceError.
+func TraceSolve(field *big.Int, circuit frontend.Circuit, assignment frontend.Circuit) error {
+	tracer := StartTracer()
+	ccs, err := frontend.Compile(field, r1cs.NewBuilder, circuit)
+	tracer.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to compile circuit: %w", err)
+	}
+	witness, err := frontend.NewWitness(assignment, field)
+	if err != nil {
+		return fmt.Errorf("failed to create witness: %w", err)
+	}
+	return tracer.Explain(ccs.IsSolved(witness))
+}
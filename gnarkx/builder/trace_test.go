@@ -0,0 +1,76 @@
+package builder_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestTraceCircuit struct {
+	X, Y, Z vars.Variable
+}
+
+// Asserts that x^8 == y within nested scopes.
+func assertEighthPower(api *builder.API, x, y vars.Variable) {
+	defer api.Scope("eighth_power")()
+	x = addConstraints(api, x, 2)
+	endInner := api.Scope("square")
+	x = addConstraints(api, x, 1)
+	api.AssertIsEqual(x, y)
+	endInner()
+}
+
+func (circuit *TestTraceCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	for i := 0; i < 2; i++ {
+		assertEighthPower(succinctAPI, circuit.X, circuit.Y)
+	}
+	succinctAPI.AssertIsEqual(succinctAPI.Add(circuit.X, circuit.Y), circuit.Z)
+	return nil
+}
+
+func TestTracer(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	newAssignment := func(x, y, z int) *TestTraceCircuit {
+		return &TestTraceCircuit{X: vars.NewVariableFromInt(x), Y: vars.NewVariableFromInt(y), Z: vars.NewVariableFromInt(z)}
+	}
+	assert.NoError(t, builder.TraceSolve(field, &TestTraceCircuit{}, newAssignment(2, 256, 258)))
+
+	// The assertion in the nested scope is attributed to both scopes and the location of each.
+	err := builder.TraceSolve(field, &TestTraceCircuit{}, newAssignment(2, 255, 257))
+	var traceErr *builder.TraceError
+	assert.True(t, errors.As(err, &traceErr))
+	assert.Len(t, traceErr.Scopes, 3)
+	assert.Equal(t, "square", traceErr.Scopes[0].Name)
+	assert.Contains(t, traceErr.Scopes[0].Location, "trace_test.go:23")
+	assert.Equal(t, "eighth_power", traceErr.Scopes[1].Name)
+	assert.Contains(t, traceErr.Scopes[1].Location, "trace_test.go:21")
+	assert.Equal(t, "circuit", traceErr.Scopes[2].Name)
+	assert.Contains(t, traceErr.Values, "!=")
+	assert.Contains(t, err.Error(), "\tin square (")
+	assert.Contains(t, err.Error(), "\tin circuit")
+
+	// An assertion outside of any scope is attributed to the root.
+	err = builder.TraceSolve(field, &TestTraceCircuit{}, newAssignment(2, 256, 257))
+	assert.True(t, errors.As(err, &traceErr))
+	assert.Len(t, traceErr.Scopes, 1)
+	assert.Equal(t, "circuit", traceErr.Scopes[0].Name)
+
+	// The spans cover all constraints, where the scopes opened twice have a span per call.
+	tracer := builder.StartTracer()
+	_, err = frontend.Compile(field, r1cs.NewBuilder, &TestTraceCircuit{})
+	tracer.Stop()
+	assert.NoError(t, err)
+	spans := tracer.Spans()
+	assert.Len(t, spans, 5)
+	assert.Equal(t, 0, spans[0].Start)
+	assert.Equal(t, builder.TraceSpan{Name: "square", Location: spans[2].Location, Parent: 1, Start: 2, End: 4}, spans[2])
+	assert.Equal(t, 4, spans[3].Start)
+	assert.Equal(t, 3, spans[4].Parent)
+}
@@ -0,0 +1,82 @@
+package builder
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes i1 + i2 mod 2^128.
+func (a *API) AddU128(i1, i2 vars.U128) vars.U128 {
+	result, _ := a.addLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U128{Limbs: [2]vars.U64(result)}
+}
+
+// Computes i1 + i2 and fails if the sum overflows 128 bits.
+func (a *API) AddU128Checked(i1, i2 vars.U128) vars.U128 {
+	result, carry := a.addLimbs(i1.Limbs[:], i2.Limbs[:])
+	a.AssertIsEqual(carry.Value, vars.ZERO)
+	return vars.U128{Limbs: [2]vars.U64(result)}
+}
+
+// Computes i1 - i2 mod 2^128.
+func (a *API) SubU128(i1, i2 vars.U128) vars.U128 {
+	result, _ := a.subLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U128{Limbs: [2]vars.U64(result)}
+}
+
+// Computes i1 - i2 and fails if i1 < i2.
+func (a *API) SubU128Checked(i1, i2 vars.U128) vars.U128 {
+	result, borrow := a.subLimbs(i1.Limbs[:], i2.Limbs[:])
+	a.AssertIsEqual(borrow.Value, vars.ZERO)
+	return vars.U128{Limbs: [2]vars.U64(result)}
+}
+
+// Computes i1 * i2 mod 2^128.
+func (a *API) MulU128(i1, i2 vars.U128) vars.U128 {
+	result := a.mulLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U128{Limbs: [2]vars.U64(result[:2])}
+}
+
+// Computes i1 * i2 and fails if the product overflows 128 bits.
+func (a *API) MulU128Checked(i1, i2 vars.U128) vars.U128 {
+	result := a.mulLimbs(i1.Limbs[:], i2.Limbs[:])
+	a.assertIsZeroLimbs(result[2:])
+	return vars.U128{Limbs: [2]vars.U64(result[:2])}
+}
+
+// Computes i1 / i2 and i1 % i2. The circuit is not satisfiable if i2 is zero.
+func (a *API) DivModU128(i1, i2 vars.U128) (vars.U128, vars.U128) {
+	q, r := a.divModLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U128{Limbs: [2]vars.U64(q)}, vars.U128{Limbs: [2]vars.U64(r)}
+}
+
+// Computes i1 / i2, rounded down. The circuit is not satisfiable if i2 is zero.
+func (a *API) DivU128(i1, i2 vars.U128) vars.U128 {
+	q, _ := a.DivModU128(i1, i2)
+	return q
+}
+
+// Computes i1 % i2. The circuit is not satisfiable if i2 is zero.
+func (a *API) ModU128(i1, i2 vars.U128) vars.U128 {
+	_, r := a.DivModU128(i1, i2)
+	return r
+}
+
+// Returns whether i1 == i2.
+func (a *API) IsEqualU128(i1, i2 vars.U128) vars.Bool {
+	return a.isEqualLimbs(i1.Limbs[:], i2.Limbs[:])
+}
+
+// Returns whether i1 < i2.
+func (a *API) IsLessThanU128(i1, i2 vars.U128) vars.Bool {
+	return a.isLessThanLimbs(i1.Limbs[:], i2.Limbs[:])
+}
+
+// Returns whether i1 <= i2.
+func (a *API) IsLessOrEqualU128(i1, i2 vars.U128) vars.Bool {
+	return a.Not(a.isLessThanLimbs(i2.Limbs[:], i1.Limbs[:]))
+}
+
+// Asserts that i1 == i2.
+func (a *API) AssertIsEqualU128(i1, i2 vars.U128) {
+	a.assertIsEqualLimbs(i1.Limbs[:], i2.Limbs[:])
+}
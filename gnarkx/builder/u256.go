@@ -0,0 +1,82 @@
+package builder
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes i1 + i2 mod 2^256.
+func (a *API) AddU256(i1, i2 vars.U256) vars.U256 {
+	result, _ := a.addLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U256{Limbs: [4]vars.U64(result)}
+}
+
+// Computes i1 + i2 and fails if the sum overflows 256 bits.
+func (a *API) AddU256Checked(i1, i2 vars.U256) vars.U256 {
+	result, carry := a.addLimbs(i1.Limbs[:], i2.Limbs[:])
+	a.AssertIsEqual(carry.Value, vars.ZERO)
+	return vars.U256{Limbs: [4]vars.U64(result)}
+}
+
+// Computes i1 - i2 mod 2^256.
+func (a *API) SubU256(i1, i2 vars.U256) vars.U256 {
+	result, _ := a.subLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U256{Limbs: [4]vars.U64(result)}
+}
+
+// Computes i1 - i2 and fails if i1 < i2.
+func (a *API) SubU256Checked(i1, i2 vars.U256) vars.U256 {
+	result, borrow := a.subLimbs(i1.Limbs[:], i2.Limbs[:])
+	a.AssertIsEqual(borrow.Value, vars.ZERO)
+	return vars.U256{Limbs: [4]vars.U64(result)}
+}
+
+// Computes i1 * i2 mod 2^256.
+func (a *API) MulU256(i1, i2 vars.U256) vars.U256 {
+	result := a.mulLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U256{Limbs: [4]vars.U64(result[:4])}
+}
+
+// Computes i1 * i2 and fails if the product overflows 256 bits.
+func (a *API) MulU256Checked(i1, i2 vars.U256) vars.U256 {
+	result := a.mulLimbs(i1.Limbs[:], i2.Limbs[:])
+	a.assertIsZeroLimbs(result[4:])
+	return vars.U256{Limbs: [4]vars.U64(result[:4])}
+}
+
+// Computes i1 / i2 and i1 % i2. The circuit is not satisfiable if i2 is zero.
+func (a *API) DivModU256(i1, i2 vars.U256) (vars.U256, vars.U256) {
+	q, r := a.divModLimbs(i1.Limbs[:], i2.Limbs[:])
+	return vars.U256{Limbs: [4]vars.U64(q)}, vars.U256{Limbs: [4]vars.U64(r)}
+}
+
+// Computes i1 / i2, rounded down. The circuit is not satisfiable if i2 is zero.
+func (a *API) DivU256(i1, i2 vars.U256) vars.U256 {
+	q, _ := a.DivModU256(i1, i2)
+	return q
+}
+
+// Computes i1 % i2. The circuit is not satisfiable if i2 is zero.
+func (a *API) ModU256(i1, i2 vars.U256) vars.U256 {
+	_, r := a.DivModU256(i1, i2)
+	return r
+}
+
+// Returns whether i1 == i2.
+func (a *API) IsEqualU256(i1, i2 vars.U256) vars.Bool {
+	return a.isEqualLimbs(i1.Limbs[:], i2.Limbs[:])
+}
+
+// Returns whether i1 < i2.
+func (a *API) IsLessThanU256(i1, i2 vars.U256) vars.Bool {
+	return a.isLessThanLimbs(i1.Limbs[:], i2.Limbs[:])
+}
+
+// Returns whether i1 <= i2.
+func (a *API) IsLessOrEqualU256(i1, i2 vars.U256) vars.Bool {
+	return a.Not(a.isLessThanLimbs(i2.Limbs[:], i1.Limbs[:]))
+}
+
+// Asserts that i1 == i2.
+func (a *API) AssertIsEqualU256(i1, i2 vars.U256) {
+	a.assertIsEqualLimbs(i1.Limbs[:], i2.Limbs[:])
+}
@@ -75,3 +75,68 @@ func (a *API) ToBytes32FromU64LE(i1 vars.U64) [32]vars.Byte {
 	}
 	return bytes
 }
+
+// Computes i1 + i2 and fails if the sum overflows 64 bits.
+func (a *API) AddU64Checked(i1, i2 vars.U64) vars.U64 {
+	result, carry := a.addLimbs([]vars.U64{i1}, []vars.U64{i2})
+	a.AssertIsEqual(carry.Value, vars.ZERO)
+	return result[0]
+}
+
+// Computes i1 - i2 mod 2^64.
+func (a *API) SubU64(i1, i2 vars.U64) vars.U64 {
+	result, _ := a.subLimbs([]vars.U64{i1}, []vars.U64{i2})
+	return result[0]
+}
+
+// Computes i1 - i2 and fails if i1 < i2.
+func (a *API) SubU64Checked(i1, i2 vars.U64) vars.U64 {
+	result, borrow := a.subLimbs([]vars.U64{i1}, []vars.U64{i2})
+	a.AssertIsEqual(borrow.Value, vars.ZERO)
+	return result[0]
+}
+
+// Computes i1 * i2 and fails if the product overflows 64 bits.
+func (a *API) MulU64Checked(i1, i2 vars.U64) vars.U64 {
+	result := a.mulLimbs([]vars.U64{i1}, []vars.U64{i2})
+	a.assertIsZeroLimbs(result[1:])
+	return result[:1][0]
+}
+
+// Computes i1 / i2 and i1 % i2. The circuit is not satisfiable if i2 is zero.
+func (a *API) DivModU64(i1, i2 vars.U64) (vars.U64, vars.U64) {
+	q, r := a.divModLimbs([]vars.U64{i1}, []vars.U64{i2})
+	return q[0], r[0]
+}
+
+// Computes i1 / i2, rounded down. The circuit is not satisfiable if i2 is zero.
+func (a *API) DivU64(i1, i2 vars.U64) vars.U64 {
+	q, _ := a.DivModU64(i1, i2)
+	return q
+}
+
+// Computes i1 % i2. The circuit is not satisfiable if i2 is zero.
+func (a *API) ModU64(i1, i2 vars.U64) vars.U64 {
+	_, r := a.DivModU64(i1, i2)
+	return r
+}
+
+// Returns whether i1 == i2.
+func (a *API) IsEqualU64(i1, i2 vars.U64) vars.Bool {
+	return a.isEqualLimbs([]vars.U64{i1}, []vars.U64{i2})
+}
+
+// Returns whether i1 < i2.
+func (a *API) IsLessThanU64(i1, i2 vars.U64) vars.Bool {
+	return a.isLessThanLimbs([]vars.U64{i1}, []vars.U64{i2})
+}
+
+// Returns whether i1 <= i2.
+func (a *API) IsLessOrEqualU64(i1, i2 vars.U64) vars.Bool {
+	return a.Not(a.isLessThanLimbs([]vars.U64{i2}, []vars.U64{i1}))
+}
+
+// Asserts that i1 == i2.
+func (a *API) AssertIsEqualU64(i1, i2 vars.U64) {
+	a.assertIsEqualLimbs([]vars.U64{i1}, []vars.U64{i2})
+}
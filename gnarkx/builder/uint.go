@@ -0,0 +1,170 @@
+package builder
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The arithmetic on vars.U64, vars.U128 and vars.U256 is implemented on little-endian u64 limbs.
+// The limbs of the inputs are assumed to be in [0, 2^64), which holds for every value returned by
+// these functions.
+
+// The 2^64 constant, i.e. the base of the limbs.
+var limbBase = vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), 64)}
+
+// Decomposes i1 in [0, 2^nbBits) into i1 % 2^64 and i1 / 2^64, where nbBits > 64.
+func (a *API) splitLimb(i1 vars.Variable, nbBits int) (vars.U64, vars.Variable) {
+	values := a.api.ToBinary(i1.Value, nbBits)
+	low := a.api.FromBinary(values[:64]...)
+	high := a.api.FromBinary(values[64:]...)
+	return vars.U64{Value: vars.Variable{Value: low}}, vars.Variable{Value: high}
+}
+
+// Computes x + y and the carry out of the most significant limb.
+func (a *API) addLimbs(x, y []vars.U64) ([]vars.U64, vars.Bool) {
+	result := make([]vars.U64, len(x))
+	carry := vars.ZERO
+	for i := range x {
+		sum := a.Add(x[i].Value, y[i].Value, carry)
+		result[i], carry = a.splitLimb(sum, 65)
+	}
+	return result, vars.Bool{Value: carry}
+}
+
+// Computes x - y and the borrow out of the most significant limb, which is set iff x < y.
+func (a *API) subLimbs(x, y []vars.U64) ([]vars.U64, vars.Bool) {
+	result := make([]vars.U64, len(x))
+	borrow := vars.ZERO
+	for i := range x {
+		diff := a.Sub(a.Add(x[i].Value, limbBase), y[i].Value, borrow)
+		var noBorrow vars.Variable
+		result[i], noBorrow = a.splitLimb(diff, 65)
+		borrow = a.Sub(vars.ONE, noBorrow)
+	}
+	return result, vars.Bool{Value: borrow}
+}
+
+// Computes the full product x * y, which has twice as many limbs as x and y.
+func (a *API) mulLimbs(x, y []vars.U64) []vars.U64 {
+	n := len(x)
+	result := make([]vars.U64, 2*n)
+	// Each column is the sum of at most n products of two limbs plus the carry of the previous
+	// column, so it is less than (n + 1) * 2^128.
+	nbBits := 128 + bits.Len(uint(n)) + 1
+	carry := vars.ZERO
+	for k := 0; k < 2*n-1; k++ {
+		column := carry
+		for i := 0; i < n; i++ {
+			if j := k - i; j >= 0 && j < n {
+				column = a.Add(column, a.Mul(x[i].Value, y[j].Value))
+			}
+		}
+		result[k], carry = a.splitLimb(column, nbBits)
+	}
+	// The product is less than 2^(128n), so the last carry is a limb.
+	result[2*n-1] = vars.U64{Value: carry}
+	return result
+}
+
+// Computes q = x / y and r = x % y. The quotient and remainder are computed by a hint and
+// constrained by x = q * y + r and r < y, so the circuit is not satisfiable if y is zero.
+func (a *API) divModLimbs(x, y []vars.U64) ([]vars.U64, []vars.U64) {
+	n := len(x)
+	inputs := make([]frontend.Variable, 2*n)
+	for i := 0; i < n; i++ {
+		inputs[i] = x[i].Value.Value
+		inputs[n+i] = y[i].Value.Value
+	}
+	outputs, err := a.api.Compiler().NewHintForId(divModHint.id, 2*n, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	q := make([]vars.U64, n)
+	r := make([]vars.U64, 2*n)
+	for i := 0; i < n; i++ {
+		q[i] = vars.U64{Value: vars.Variable{Value: outputs[i]}}
+		r[i] = vars.U64{Value: vars.Variable{Value: outputs[n+i]}}
+		a.AssertIsU64(q[i].Value)
+		a.AssertIsU64(r[i].Value)
+		r[n+i] = vars.NewU64()
+	}
+
+	// x = q * y + r, where the sum must not overflow n limbs.
+	sum, carry := a.addLimbs(a.mulLimbs(q, y), r)
+	a.AssertIsEqual(carry.Value, vars.ZERO)
+	for i := 0; i < n; i++ {
+		a.AssertIsEqual(sum[i].Value, x[i].Value)
+		a.AssertIsEqual(sum[n+i].Value, vars.ZERO)
+	}
+
+	// r < y.
+	_, borrow := a.subLimbs(r[:n], y)
+	a.AssertIsEqual(borrow.Value, vars.ONE)
+
+	return q, r[:n]
+}
+
+// Returns whether x == y.
+func (a *API) isEqualLimbs(x, y []vars.U64) vars.Bool {
+	result := vars.TRUE
+	for i := range x {
+		result = a.And(result, a.IsZero(a.Sub(x[i].Value, y[i].Value)))
+	}
+	return result
+}
+
+// Returns whether x < y.
+func (a *API) isLessThanLimbs(x, y []vars.U64) vars.Bool {
+	_, borrow := a.subLimbs(x, y)
+	return borrow
+}
+
+// Asserts that x == y.
+func (a *API) assertIsEqualLimbs(x, y []vars.U64) {
+	for i := range x {
+		a.AssertIsEqual(x[i].Value, y[i].Value)
+	}
+}
+
+// Asserts that the limbs are zero, i.e. that an operation did not overflow.
+func (a *API) assertIsZeroLimbs(x []vars.U64) {
+	for i := range x {
+		a.AssertIsEqual(x[i].Value, vars.ZERO)
+	}
+}
+
+// Computes the quotient and remainder of two integers given as n little-endian u64 limbs each. If
+// the divisor is zero, the quotient is zero and the remainder is the dividend.
+var divModHint = NewHint("builder.divMod", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	n := len(inputs) / 2
+	x := fromLimbs(inputs[:n])
+	y := fromLimbs(inputs[n:])
+	q, r := new(big.Int), new(big.Int).Set(x)
+	if y.Sign() != 0 {
+		q.DivMod(x, y, r)
+	}
+	toLimbs(q, outputs[:n])
+	toLimbs(r, outputs[n:])
+	return nil
+})
+
+func fromLimbs(limbs []*big.Int) *big.Int {
+	result := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		result.Lsh(result, 64)
+		result.Add(result, limbs[i])
+	}
+	return result
+}
+
+func toLimbs(x *big.Int, limbs []*big.Int) {
+	mask := new(big.Int).SetUint64(^uint64(0))
+	value := new(big.Int).Set(x)
+	for i := range limbs {
+		limbs[i].And(value, mask)
+		value.Rsh(value, 64)
+	}
+}
@@ -0,0 +1,136 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestU256Circuit struct {
+	A, B                   vars.U256
+	Sum, Diff, Prod        vars.U256
+	Quotient, Remainder    vars.U256
+	LessThan, LessOrEqual  vars.Bool
+	Equal                  vars.Bool
+	SumU128, ProdU128      vars.U128
+	QuotientU64, DiffU64   vars.U64
+	ALow, BLow             vars.U128
+	AU64, BU64             vars.U64
+	CheckedSum, CheckedMul bool `gnark:"-"`
+}
+
+func (c *TestU256Circuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	a.AssertIsEqualU256(a.AddU256(c.A, c.B), c.Sum)
+	a.AssertIsEqualU256(a.SubU256(c.A, c.B), c.Diff)
+	a.AssertIsEqualU256(a.MulU256(c.A, c.B), c.Prod)
+	q, r := a.DivModU256(c.A, c.B)
+	a.AssertIsEqualU256(q, c.Quotient)
+	a.AssertIsEqualU256(r, c.Remainder)
+	a.AssertIsEqualBool(a.IsLessThanU256(c.A, c.B), c.LessThan)
+	a.AssertIsEqualBool(a.IsLessOrEqualU256(c.A, c.B), c.LessOrEqual)
+	a.AssertIsEqualBool(a.IsEqualU256(c.A, c.B), c.Equal)
+	if c.CheckedSum {
+		a.AddU256Checked(c.A, c.B)
+	}
+	if c.CheckedMul {
+		a.MulU256Checked(c.A, c.B)
+	}
+
+	a.AssertIsEqualU128(a.AddU128(c.ALow, c.BLow), c.SumU128)
+	a.AssertIsEqualU128(a.MulU128(c.ALow, c.BLow), c.ProdU128)
+	a.AssertIsEqualU64(a.DivU64(c.AU64, c.BU64), c.QuotientU64)
+	a.AssertIsEqualU64(a.SubU64(c.AU64, c.BU64), c.DiffU64)
+	return nil
+}
+
+func newU256(v *big.Int) vars.U256 {
+	u := vars.NewU256()
+	u.Set(v)
+	return u
+}
+
+func newU128(v *big.Int) vars.U128 {
+	u := vars.NewU128()
+	u.Set(v)
+	return u
+}
+
+func newU64(v *big.Int) vars.U64 {
+	return vars.U64{Value: vars.Variable{Value: v}}
+}
+
+func assignU256Circuit(x, y *big.Int) *TestU256Circuit {
+	mod := func(bits uint) *big.Int {
+		return new(big.Int).Lsh(big.NewInt(1), bits)
+	}
+	wrap := func(v *big.Int, bits uint) *big.Int {
+		return new(big.Int).Mod(v, mod(bits))
+	}
+	q, r := new(big.Int).DivMod(x, y, new(big.Int))
+	xLow, yLow := wrap(x, 128), wrap(y, 128)
+	xU64, yU64 := wrap(x, 64), new(big.Int).Add(wrap(y, 32), big.NewInt(1))
+	return &TestU256Circuit{
+		A:           newU256(x),
+		B:           newU256(y),
+		Sum:         newU256(wrap(new(big.Int).Add(x, y), 256)),
+		Diff:        newU256(wrap(new(big.Int).Sub(x, y), 256)),
+		Prod:        newU256(wrap(new(big.Int).Mul(x, y), 256)),
+		Quotient:    newU256(q),
+		Remainder:   newU256(r),
+		LessThan:    vars.NewBool(x.Cmp(y) < 0),
+		LessOrEqual: vars.NewBool(x.Cmp(y) <= 0),
+		Equal:       vars.NewBool(x.Cmp(y) == 0),
+		ALow:        newU128(xLow),
+		BLow:        newU128(yLow),
+		SumU128:     newU128(wrap(new(big.Int).Add(xLow, yLow), 128)),
+		ProdU128:    newU128(wrap(new(big.Int).Mul(xLow, yLow), 128)),
+		AU64:        newU64(xU64),
+		BU64:        newU64(yU64),
+		QuotientU64: newU64(new(big.Int).Div(xU64, yU64)),
+		DiffU64:     newU64(wrap(new(big.Int).Sub(xU64, yU64), 64)),
+	}
+}
+
+func TestUintArithmetic(t *testing.T) {
+	assert := test.NewAssert(t)
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	x, _ := new(big.Int).SetString("d1b3a7f5e0c2f86a0b7a2c5e9f1d3b7a4c6e8f0a2b4d6f8091a3c5e7f9b1d3e5", 16)
+	y, _ := new(big.Int).SetString("3a5c7e9f0b2d4f6a8c0e1f3b5d7f9a1c", 16)
+
+	testCases := [][2]*big.Int{
+		{x, y},
+		{y, x},
+		{x, x},
+		{max, max},
+		{big.NewInt(0), big.NewInt(1)},
+	}
+	for _, tc := range testCases {
+		circuit := assignU256Circuit(tc[0], tc[1])
+		assert.NoError(test.IsSolved(&TestU256Circuit{}, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A wrong quotient or comparison is rejected.
+	circuit := assignU256Circuit(x, y)
+	circuit.Quotient = newU256(big.NewInt(1))
+	assert.Error(test.IsSolved(&TestU256Circuit{}, circuit, ecc.BN254.ScalarField()))
+	circuit = assignU256Circuit(x, y)
+	circuit.LessThan = vars.TRUE
+	assert.Error(test.IsSolved(&TestU256Circuit{}, circuit, ecc.BN254.ScalarField()))
+
+	// The checked variants fail on overflow.
+	small := big.NewInt(1 << 40)
+	assert.NoError(test.IsSolved(&TestU256Circuit{CheckedSum: true, CheckedMul: true}, assignU256Circuit(small, small), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(&TestU256Circuit{CheckedSum: true}, assignU256Circuit(max, x), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(&TestU256Circuit{CheckedMul: true}, assignU256Circuit(x, y), ecc.BN254.ScalarField()))
+
+	// Division by zero is not satisfiable.
+	circuit = assignU256Circuit(x, y)
+	circuit.B = newU256(big.NewInt(0))
+	assert.Error(test.IsSolved(&TestU256Circuit{}, circuit, ecc.BN254.ScalarField()))
+}
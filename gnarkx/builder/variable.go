@@ -1,6 +1,9 @@
 package builder
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/consensys/gnark/frontend"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
@@ -32,7 +35,8 @@ func (a *API) Mul(i1 vars.Variable, i2 vars.Variable, in ...vars.Variable) vars.
 	return vars.Variable{Value: a.api.Mul(i1.Value, i2.Value, els...)}
 }
 
-// Div returns i1 / i2.
+// Div returns i1 / i2 in the field, i.e. i1 times the inverse of i2. This is not the integer
+// division of i1 by i2 unless i2 divides i1, see DivModVariable for the latter.
 func (a *API) Div(i1 vars.Variable, i2 vars.Variable) vars.Variable {
 	return vars.Variable{Value: a.api.Div(i1.Value, i2.Value)}
 }
@@ -88,3 +92,52 @@ func (a *API) AssertIsBoolean(i1 vars.Variable) {
 func (a *API) AssertIsLessOrEqual(i1, i2 vars.Variable) {
 	a.api.AssertIsLessOrEqual(i1.Value, i2.Value)
 }
+
+// Computes i1 / i2 and i1 % i2 of the integers i1 and i2 in [0, 2^nbBits), where nbBits must be
+// small enough for the product of two such integers not to wrap around the field. The quotient
+// and remainder are computed by a hint and constrained by i1 = q * i2 + r, with q in
+// [0, 2^nbBits) and r < i2, so the circuit is not satisfiable if i2 is zero.
+func (a *API) DivModVariable(i1, i2 vars.Variable, nbBits int) (vars.Variable, vars.Variable) {
+	if nbBits <= 0 || 2*nbBits >= a.api.Compiler().FieldBitLen() {
+		panic(fmt.Sprintf("cannot divide integers of %d bits", nbBits))
+	}
+	a.AssertIsInRange(i1, nbBits)
+	a.AssertIsInRange(i2, nbBits)
+	outputs := a.Hint(divModVariableHint, 2, i1, i2)
+	q, r := outputs[0], outputs[1]
+	a.AssertIsInRange(q, nbBits)
+	a.AssertIsInRange(r, nbBits)
+
+	// q * i2 + r < 2^(2 * nbBits), which does not wrap around the field.
+	a.AssertIsEqual(a.Add(a.Mul(q, i2), r), i1)
+
+	// r < i2, i.e. i2 - r - 1 is in [0, 2^nbBits). If r >= i2, the difference wraps around the
+	// field and is not in range.
+	a.AssertIsInRange(a.Sub(i2, r, vars.ONE), nbBits)
+	return q, r
+}
+
+// Computes i1 / i2, rounded down, of the integers i1 and i2 in [0, 2^nbBits). The circuit is not
+// satisfiable if i2 is zero.
+func (a *API) DivVariable(i1, i2 vars.Variable, nbBits int) vars.Variable {
+	q, _ := a.DivModVariable(i1, i2, nbBits)
+	return q
+}
+
+// Computes i1 % i2 of the integers i1 and i2 in [0, 2^nbBits). The circuit is not satisfiable if
+// i2 is zero.
+func (a *API) ModVariable(i1, i2 vars.Variable, nbBits int) vars.Variable {
+	_, r := a.DivModVariable(i1, i2, nbBits)
+	return r
+}
+
+// Computes the quotient and remainder of two integers. If the divisor is zero, the quotient is
+// zero and the remainder is the dividend.
+var divModVariableHint = NewHint("builder.divModVariable", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].SetUint64(0)
+	outputs[1].Set(inputs[0])
+	if inputs[1].Sign() != 0 {
+		outputs[0].DivMod(inputs[0], inputs[1], outputs[1])
+	}
+	return nil
+})
@@ -0,0 +1,176 @@
+package builder
+
+import (
+	"math/bits"
+
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Creates variable bytes from the first length bytes of data, where length is in [0, len(data)].
+// The bytes after the length are zeroed, so data may contain arbitrary padding.
+func (a *API) ToVariableBytes(data []vars.Byte, length vars.Variable) vars.VariableBytes {
+	isContent := a.PrefixMask(length, len(data))
+	result := make([]vars.Byte, len(data))
+	for i := range data {
+		result[i] = vars.Byte{Value: a.Mul(isContent[i], data[i].Value)}
+	}
+	return vars.VariableBytes{Data: result, Length: length}
+}
+
+// Asserts that the length of the variable bytes is at most the maximum length and that the bytes
+// after the length are zero. This must be checked for variable bytes which are witnesses.
+func (a *API) AssertIsValidVariableBytes(b vars.VariableBytes) {
+	isContent := a.PrefixMask(b.Length, len(b.Data))
+	for i := range b.Data {
+		a.AssertIsEqual(a.Mul(a.Sub(vars.ONE, isContent[i]), b.Data[i].Value), vars.ZERO)
+	}
+}
+
+// Changes the maximum length of the variable bytes. If the maximum length shrinks, asserts that
+// the length fits.
+func (a *API) ResizeVariableBytes(b vars.VariableBytes, maxLength int) vars.VariableBytes {
+	if maxLength < len(b.Data) {
+		a.AssertIsLessOrEqual(b.Length, vars.NewVariableFromInt(maxLength))
+	}
+	result := make([]vars.Byte, maxLength)
+	for i := range result {
+		if i < len(b.Data) {
+			result[i] = b.Data[i]
+		} else {
+			result[i] = vars.ZERO_BYTE
+		}
+	}
+	return vars.VariableBytes{Data: result, Length: b.Length}
+}
+
+// Returns data[start:start + length] with the given maximum length, where start and length are
+// variables, e.g. to extract a field of an RLP or ABI encoded payload. Asserts that the slice is
+// within the data, i.e. start + length <= len(data), and that length is at most maxLength.
+func (a *API) SliceBytes(data []vars.Byte, start vars.Variable, length vars.Variable, maxLength int) vars.VariableBytes {
+	b := vars.VariableBytes{Data: data, Length: vars.NewVariableFromInt(len(data))}
+	return a.SliceVariableBytes(b, start, length, maxLength)
+}
+
+// Returns b[start:start + length] with the given maximum length. Asserts that the slice is within
+// the bytes, i.e. start + length <= b.Length, and that length is at most maxLength. The bytes are
+// shifted by start with a barrel shifter, which costs O(n log n) constraints for n = len(b.Data)
+// instead of selecting each byte of the slice among all the offsets. The shifter also bounds start
+// by 2^bits.Len(len(b.Data)), so start + length cannot wrap around the field.
+func (a *API) SliceVariableBytes(b vars.VariableBytes, start vars.Variable, length vars.Variable, maxLength int) vars.VariableBytes {
+	end := a.Add(start, length)
+	a.AssertIsLessOrEqual(end, b.Length)
+	isContent := a.PrefixMask(length, maxLength)
+	shifted := a.shiftLeft(b.Data, start, maxLength)
+	result := make([]vars.Byte, maxLength)
+	for i := range result {
+		result[i] = vars.Byte{Value: a.Mul(isContent[i], shifted[i].Value)}
+	}
+	return vars.VariableBytes{Data: result, Length: length}
+}
+
+// Returns the first n bytes of data[shift:], padded with zeros. The data is shifted by each bit of
+// shift in turn, which asserts that shift is less than 2^bits.Len(len(data)).
+func (a *API) shiftLeft(data []vars.Byte, shift vars.Variable, n int) []vars.Byte {
+	nbBits := bits.Len(uint(len(data)))
+	shiftBits := a.ToBinaryLE(shift, nbBits)
+
+	// The shift by bit k only needs to keep the bytes which the shifts by the higher bits can still
+	// move into the first n bytes.
+	widths := make([]int, nbBits+1)
+	widths[nbBits] = n
+	for k := nbBits - 1; k >= 0; k-- {
+		widths[k] = widths[k+1] + 1<<k
+	}
+	current := data
+	for k := 0; k < nbBits; k++ {
+		width := widths[k+1]
+		if width > len(data) {
+			width = len(data)
+		}
+		next := make([]vars.Byte, width)
+		for i := range next {
+			shifted := vars.ZERO
+			if j := i + 1<<k; j < len(current) {
+				shifted = current[j].Value
+			}
+			next[i] = vars.Byte{Value: a.Select(shiftBits[k], shifted, current[i].Value)}
+		}
+		current = next
+	}
+
+	result := make([]vars.Byte, n)
+	for i := range result {
+		if i < len(current) {
+			result[i] = current[i]
+		} else {
+			result[i] = vars.ZERO_BYTE
+		}
+	}
+	return result
+}
+
+// Concatenates the variable bytes. The maximum length of the result is the sum of the maximum
+// lengths.
+func (a *API) ConcatVariableBytes(in ...vars.VariableBytes) vars.VariableBytes {
+	maxLength := 0
+	for i := range in {
+		maxLength += len(in[i].Data)
+	}
+	result := make([]vars.Byte, maxLength)
+	for i := range result {
+		result[i] = vars.ZERO_BYTE
+	}
+
+	// Since the bytes after the length are zero, every part can be added to the result at its
+	// offset without affecting the other parts.
+	offset := vars.ZERO
+	for _, b := range in {
+		sel := a.Selector(offset, maxLength)
+		for j := range result {
+			value := result[j].Value
+			for k := 0; k <= j && k < len(b.Data); k++ {
+				value = a.Add(value, a.Mul(sel[j-k], b.Data[k].Value))
+			}
+			result[j] = vars.Byte{Value: value}
+		}
+		offset = a.Add(offset, b.Length)
+	}
+	return vars.VariableBytes{Data: result, Length: offset}
+}
+
+// Asserts that the variable bytes are equal, i.e. that they have the same length and content.
+func (a *API) AssertIsEqualVariableBytes(i1, i2 vars.VariableBytes) {
+	a.AssertIsEqual(i1.Length, i2.Length)
+	for i := 0; i < len(i1.Data) || i < len(i2.Data); i++ {
+		x, y := vars.ZERO_BYTE, vars.ZERO_BYTE
+		if i < len(i1.Data) {
+			x = i1.Data[i]
+		}
+		if i < len(i2.Data) {
+			y = i2.Data[i]
+		}
+		a.AssertIsEqualByte(x, y)
+	}
+}
+
+// Returns the one-hot selector of the index over [0, n), i.e. result[i] is set iff i == index. If
+// the index is out of range, no selector is set.
+func (a *API) Selector(index vars.Variable, n int) []vars.Variable {
+	result := make([]vars.Variable, n)
+	for i := 0; i < n; i++ {
+		result[i] = a.IsZero(a.Sub(index, vars.NewVariableFromInt(i))).Value
+	}
+	return result
+}
+
+// Returns result[i] = i < length for i in [0, n), and asserts that the length is at most n.
+func (a *API) PrefixMask(length vars.Variable, n int) []vars.Variable {
+	a.AssertIsLessOrEqual(length, vars.NewVariableFromInt(n))
+	result := make([]vars.Variable, n)
+	isBefore := vars.ONE
+	for i := 0; i < n; i++ {
+		isBefore = a.Sub(isBefore, a.IsZero(a.Sub(length, vars.NewVariableFromInt(i))).Value)
+		result[i] = isBefore
+	}
+	return result
+}
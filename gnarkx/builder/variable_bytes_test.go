@@ -0,0 +1,132 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestVariableBytesCircuit struct {
+	X, Y     vars.VariableBytes
+	Padded   []vars.Byte
+	Start    vars.Variable
+	Length   vars.Variable
+	Concat   vars.VariableBytes
+	Slice    vars.VariableBytes
+	Prefix   vars.VariableBytes
+	MaxSlice int `gnark:"-"`
+}
+
+func (c *TestVariableBytesCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	a.AssertIsValidVariableBytes(c.X)
+	a.AssertIsValidVariableBytes(c.Y)
+	a.AssertIsEqualVariableBytes(a.ConcatVariableBytes(c.X, c.Y), c.Concat)
+	a.AssertIsEqualVariableBytes(a.SliceVariableBytes(c.Concat, c.Start, c.Length, c.MaxSlice), c.Slice)
+	a.AssertIsEqualVariableBytes(a.ToVariableBytes(c.Padded, c.X.Length), c.Prefix)
+	a.AssertIsEqualVariableBytes(a.ResizeVariableBytes(c.X, len(c.X.Data)+5), c.X)
+	return nil
+}
+
+func TestVariableBytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	x := []byte("hello, ")
+	y := []byte("world")
+	xy := append(append([]byte{}, x...), y...)
+	newCircuit := func() *TestVariableBytesCircuit {
+		padded := append(append([]byte{}, x...), 0xff, 0xff, 0xff)
+		return &TestVariableBytesCircuit{
+			X:        vars.NewVariableBytesFrom(x, 10),
+			Y:        vars.NewVariableBytesFrom(y, 8),
+			Padded:   vars.NewBytesFrom(padded),
+			Start:    vars.NewVariableFromInt(5),
+			Length:   vars.NewVariableFromInt(4),
+			Concat:   vars.NewVariableBytesFrom(xy, 18),
+			Slice:    vars.NewVariableBytesFrom(xy[5:9], 6),
+			Prefix:   vars.NewVariableBytesFrom(x, len(padded)),
+			MaxSlice: 6,
+		}
+	}
+
+	circuit := newCircuit()
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// Variable bytes with nonzero padding are rejected.
+	circuit = newCircuit()
+	circuit.X.Data[9].Set(1)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A slice past the end is rejected.
+	circuit = newCircuit()
+	circuit.Start = vars.NewVariableFromInt(10)
+	circuit.Slice = vars.NewVariableBytesFrom(xy[10:], 6)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A slice longer than its maximum length is rejected.
+	circuit = newCircuit()
+	circuit.Start = vars.NewVariableFromInt(0)
+	circuit.Length = vars.NewVariableFromInt(7)
+	circuit.Slice = vars.NewVariableBytesFrom(xy[:6], 6)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestSliceBytesCircuit struct {
+	Data     []vars.Byte
+	Start    vars.Variable
+	Length   vars.Variable
+	Slice    vars.VariableBytes
+	MaxSlice int `gnark:"-"`
+}
+
+func (c *TestSliceBytesCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	a.AssertIsEqualVariableBytes(a.SliceBytes(c.Data, c.Start, c.Length, c.MaxSlice), c.Slice)
+	return nil
+}
+
+func TestSliceBytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	data := []byte("the quick brown fox")
+	const maxSlice = 7
+	newCircuit := func(start, length int) *TestSliceBytesCircuit {
+		end := start + length
+		if end > len(data) {
+			end = len(data)
+		}
+		return &TestSliceBytesCircuit{
+			Data:     vars.NewBytesFrom(data),
+			Start:    vars.NewVariableFromInt(start),
+			Length:   vars.NewVariableFromInt(length),
+			Slice:    vars.NewVariableBytesFrom(data[start:end], maxSlice),
+			MaxSlice: maxSlice,
+		}
+	}
+
+	// Every slice within the data is extracted, including empty ones at the end.
+	for start := 0; start <= len(data); start++ {
+		for length := 0; length <= maxSlice && start+length <= len(data); length++ {
+			circuit := newCircuit(start, length)
+			assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "start %d, length %d", start, length)
+		}
+	}
+
+	// A slice past the end of the data is rejected.
+	circuit := newCircuit(15, 4)
+	circuit.Length = vars.NewVariableFromInt(5)
+	circuit.Slice.Length = circuit.Length
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// So is a start which wraps around the field to make the end of the slice small.
+	circuit = newCircuit(0, 1)
+	circuit.Start = vars.Variable{Value: new(big.Int).Sub(ecc.BN254.ScalarField(), big.NewInt(1))}
+	circuit.Length = vars.NewVariableFromInt(2)
+	circuit.Slice.Length = circuit.Length
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
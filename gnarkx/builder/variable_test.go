@@ -0,0 +1,72 @@
+package builder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestDivModVariableCircuit struct {
+	X, Y                vars.Variable
+	Quotient, Remainder vars.Variable
+	NbBits              int `gnark:"-"`
+}
+
+func (c *TestDivModVariableCircuit) Define(api frontend.API) error {
+	a := builder.NewAPI(api)
+	q, r := a.DivModVariable(c.X, c.Y, c.NbBits)
+	a.AssertIsEqual(q, c.Quotient)
+	a.AssertIsEqual(r, c.Remainder)
+	a.AssertIsEqual(a.DivVariable(c.X, c.Y, c.NbBits), c.Quotient)
+	a.AssertIsEqual(a.ModVariable(c.X, c.Y, c.NbBits), c.Remainder)
+	return nil
+}
+
+func TestDivModVariable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func(x, y *big.Int, nbBits int) *TestDivModVariableCircuit {
+		q, r := new(big.Int), new(big.Int).Set(x)
+		if y.Sign() != 0 {
+			q.DivMod(x, y, r)
+		}
+		return &TestDivModVariableCircuit{
+			X:         vars.Variable{Value: x},
+			Y:         vars.Variable{Value: y},
+			Quotient:  vars.Variable{Value: q},
+			Remainder: vars.Variable{Value: r},
+			NbBits:    nbBits,
+		}
+	}
+
+	for x := 0; x < 16; x++ {
+		for y := 1; y < 16; y++ {
+			circuit := newCircuit(big.NewInt(int64(x)), big.NewInt(int64(y)), 4)
+			assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "%d / %d", x, y)
+		}
+	}
+
+	// The largest integers whose product does not wrap around the field.
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 126), big.NewInt(1))
+	circuit := newCircuit(max, big.NewInt(3), 126)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newCircuit(max, new(big.Int).Rsh(max, 60), 126)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// Division by zero is not satisfiable.
+	circuit = newCircuit(big.NewInt(5), big.NewInt(0), 4)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// Neither are operands out of range.
+	circuit = newCircuit(big.NewInt(16), big.NewInt(3), 4)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// So are integers too wide for the product to fit in the field.
+	circuit = newCircuit(big.NewInt(5), big.NewInt(3), 127)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
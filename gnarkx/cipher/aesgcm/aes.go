@@ -0,0 +1,185 @@
+package aesgcm
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/lookup"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A block of 16 bytes as little-endian bits of its bytes, which is also the state of AES with
+// the bytes in column-major order.
+type block [16][8]vars.Bool
+
+// The AES block cipher with an expanded key. The S-box is looked up in a table of 256 entries,
+// while all other steps are xors of bits.
+type blockCipher struct {
+	api       builder.API
+	sbox      *lookup.Table
+	roundKeys []block
+}
+
+// Creates the block cipher for a key of 16, 24 or 32 bytes, i.e. AES-128, AES-192 or AES-256.
+func newBlockCipher(api builder.API, key []vars.Byte) *blockCipher {
+	nk := len(key) / 4
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		panic("key must have 16, 24 or 32 bytes")
+	}
+	c := &blockCipher{
+		api: api,
+		sbox: lookup.NewFunctionTable(api, 8, func(x uint64) uint64 {
+			return uint64(sbox(byte(x)))
+		}),
+	}
+
+	// Expand the key into the words of the round keys.
+	nbRounds := nk + 6
+	words := make([][4][8]vars.Bool, 4*(nbRounds+1))
+	for i := 0; i < nk; i++ {
+		for j := 0; j < 4; j++ {
+			words[i][j] = api.ToBitsFromByte(key[4*i+j])
+		}
+	}
+	rcon := byte(1)
+	for i := nk; i < len(words); i++ {
+		temp := words[i-1]
+		if i%nk == 0 {
+			temp = [4][8]vars.Bool{temp[1], temp[2], temp[3], temp[0]}
+			for j := range temp {
+				temp[j] = c.subByte(temp[j])
+			}
+			temp[0] = c.xor(temp[0], constantBits(rcon))
+			rcon = xtimeNative(rcon)
+		} else if nk > 6 && i%nk == 4 {
+			for j := range temp {
+				temp[j] = c.subByte(temp[j])
+			}
+		}
+		for j := range temp {
+			words[i][j] = c.xor(words[i-nk][j], temp[j])
+		}
+	}
+	c.roundKeys = make([]block, nbRounds+1)
+	for i := range c.roundKeys {
+		for j := 0; j < 16; j++ {
+			c.roundKeys[i][j] = words[4*i+j/4][j%4]
+		}
+	}
+	return c
+}
+
+// Encrypts a block.
+func (c *blockCipher) encrypt(in block) block {
+	state := c.addRoundKey(in, c.roundKeys[0])
+	for round := 1; round < len(c.roundKeys); round++ {
+		for i := range state {
+			state[i] = c.subByte(state[i])
+		}
+		state = shiftRows(state)
+		if round < len(c.roundKeys)-1 {
+			state = c.mixColumns(state)
+		}
+		state = c.addRoundKey(state, c.roundKeys[round])
+	}
+	return state
+}
+
+func (c *blockCipher) addRoundKey(state block, roundKey block) block {
+	for i := range state {
+		state[i] = c.xor(state[i], roundKey[i])
+	}
+	return state
+}
+
+// Looks up the byte in the S-box.
+func (c *blockCipher) subByte(in [8]vars.Bool) [8]vars.Bool {
+	return c.api.ToBitsFromByte(vars.Byte{Value: c.sbox.Lookup(c.api.ToByteFromBits(in).Value)})
+}
+
+// Rotates row r of the state to the left by r bytes.
+func shiftRows(state block) block {
+	var out block
+	for r := 0; r < 4; r++ {
+		for col := 0; col < 4; col++ {
+			out[r+4*col] = state[r+4*((col+r)%4)]
+		}
+	}
+	return out
+}
+
+// Multiplies each column of the state with the fixed polynomial of AES, i.e. computes
+// a_i ^ t ^ xtime(a_i ^ a_{i+1}) where t is the xor of the column.
+func (c *blockCipher) mixColumns(state block) block {
+	var out block
+	for col := 0; col < 4; col++ {
+		a := state[4*col : 4*col+4]
+		t := c.xor(c.xor(a[0], a[1]), c.xor(a[2], a[3]))
+		for i := 0; i < 4; i++ {
+			out[4*col+i] = c.xor(c.xor(a[i], t), c.xtime(c.xor(a[i], a[(i+1)%4])))
+		}
+	}
+	return out
+}
+
+// Multiplies the byte with x in GF(2^8), i.e. shifts it to the left and reduces it with 0x1b.
+func (c *blockCipher) xtime(in [8]vars.Bool) [8]vars.Bool {
+	return [8]vars.Bool{
+		in[7],
+		c.api.Xor(in[0], in[7]),
+		in[1],
+		c.api.Xor(in[2], in[7]),
+		c.api.Xor(in[3], in[7]),
+		in[4],
+		in[5],
+		in[6],
+	}
+}
+
+func (c *blockCipher) xor(i1, i2 [8]vars.Bool) [8]vars.Bool {
+	var out [8]vars.Bool
+	for i := range out {
+		out[i] = c.api.Xor(i1[i], i2[i])
+	}
+	return out
+}
+
+// Returns the little-endian bits of a constant byte.
+func constantBits(b byte) [8]vars.Bool {
+	var bits [8]vars.Bool
+	for i := range bits {
+		bits[i] = vars.NewBool(b>>i&1 == 1)
+	}
+	return bits
+}
+
+func xtimeNative(b byte) byte {
+	if b&0x80 != 0 {
+		return b<<1 ^ 0x1b
+	}
+	return b << 1
+}
+
+// Computes the S-box of AES, i.e. the affine transformation of the inverse in GF(2^8).
+func sbox(x byte) byte {
+	// Compute x^254, which is the inverse of x and maps 0 to 0.
+	inv := byte(1)
+	for i := 0; i < 254; i++ {
+		inv = mulNative(inv, x)
+	}
+	s := inv
+	for i := 1; i <= 4; i++ {
+		s ^= inv<<i | inv>>(8-i)
+	}
+	return s ^ 0x63
+}
+
+func mulNative(x, y byte) byte {
+	var product byte
+	for y != 0 {
+		if y&1 != 0 {
+			product ^= x
+		}
+		x = xtimeNative(x)
+		y >>= 1
+	}
+	return product
+}
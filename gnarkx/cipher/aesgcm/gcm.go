@@ -0,0 +1,170 @@
+// The API for decrypting ciphertexts of AES in Galois/Counter Mode with nonces of 12 bytes and
+// tags of 16 bytes, as used by TLS, given a witness key. Note that at compile time of the
+// circuit, the lengths of the ciphertext and the additional data must be constants.
+package aesgcm
+
+import (
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Decrypts the ciphertext with the key of 16, 24 or 32 bytes and the nonce, asserting that the
+// tag authenticates the ciphertext and the additional data, and returns the plaintext.
+func Open(
+	api builder.API,
+	key []vars.Byte,
+	nonce [12]vars.Byte,
+	ciphertext []vars.Byte,
+	tag [16]vars.Byte,
+	additionalData []vars.Byte,
+) []vars.Byte {
+	defer api.Scope("aesgcm")()
+	cipher := newBlockCipher(api, key)
+	g := &ghash{api: api, h: cipher.encrypt(zeroBlock())}
+
+	// The plaintext is the ciphertext xored with the encrypted counter blocks starting at 2.
+	plaintext := make([]vars.Byte, len(ciphertext))
+	for i := 0; i < len(ciphertext); i += 16 {
+		stream := cipher.encrypt(counterBlock(api, nonce, uint32(i/16+2)))
+		for j := i; j < len(ciphertext) && j < i+16; j++ {
+			bits := api.ToBitsFromByte(ciphertext[j])
+			plaintext[j] = api.ToByteFromBits(cipher.xor(bits, stream[j-i]))
+		}
+	}
+
+	// The tag is the GHASH of the additional data and the ciphertext, each padded to a multiple of
+	// 16 bytes, followed by their lengths in bits as big-endian u64s, xored with the encrypted
+	// counter block 1.
+	y := zeroBlock()
+	for _, in := range [][]vars.Byte{additionalData, ciphertext} {
+		for i := 0; i < len(in); i += 16 {
+			var x block
+			for j := range x {
+				if i+j < len(in) {
+					x[j] = api.ToBitsFromByte(in[i+j])
+				} else {
+					x[j] = constantBits(0)
+				}
+			}
+			y = g.update(y, x)
+		}
+	}
+	var lengths block
+	for j := 0; j < 8; j++ {
+		lengths[j] = constantBits(byte(uint64(8*len(additionalData)) >> (56 - 8*j)))
+		lengths[8+j] = constantBits(byte(uint64(8*len(ciphertext)) >> (56 - 8*j)))
+	}
+	y = g.update(y, lengths)
+	mask := cipher.encrypt(counterBlock(api, nonce, 1))
+	for i := range tag {
+		expected := api.ToByteFromBits(cipher.xor(y[i], mask[i]))
+		api.AssertIsEqualByte(tag[i], expected)
+	}
+	return plaintext
+}
+
+func zeroBlock() block {
+	var b block
+	for i := range b {
+		b[i] = constantBits(0)
+	}
+	return b
+}
+
+// Returns the nonce followed by the counter as a big-endian u32.
+func counterBlock(api builder.API, nonce [12]vars.Byte, counter uint32) block {
+	var b block
+	for i := range nonce {
+		b[i] = api.ToBitsFromByte(nonce[i])
+	}
+	for i := 0; i < 4; i++ {
+		b[12+i] = constantBits(byte(counter >> (24 - 8*i)))
+	}
+	return b
+}
+
+// The universal hash function of GCM, which multiplies by the hash key h in GF(2^128), where the
+// bit 7 - i % 8 of byte i / 8 of a block is the coefficient of x^i.
+type ghash struct {
+	api builder.API
+	h   block
+}
+
+// Returns (y ^ x) * h.
+func (g *ghash) update(y block, x block) block {
+	for i := range y {
+		for j := range y[i] {
+			y[i][j] = g.api.Xor(y[i][j], x[i][j])
+		}
+	}
+	return g.mul(y, g.h)
+}
+
+// Multiplies two blocks in GF(2^128). The coefficients of the product are xors of products of
+// bits, so each coefficient is computed as the parity of the sum of its products, which costs
+// one constraint per product and a few per coefficient.
+func (g *ghash) mul(i1, i2 block) block {
+	// Sum the products of the coefficients of x^k before the reduction.
+	var sums [255]vars.Variable
+	for k := range sums {
+		sums[k] = vars.ZERO
+	}
+	for i := 0; i < 128; i++ {
+		for j := 0; j < 128; j++ {
+			product := g.api.Mul(coefficient(i1, i).Value, coefficient(i2, j).Value)
+			sums[i+j] = g.api.Add(sums[i+j], product)
+		}
+	}
+
+	var out block
+	for k := 0; k < 128; k++ {
+		sum := vars.ZERO
+		for t := range sums {
+			if reductions[t].Bit(k) == 1 {
+				sum = g.api.Add(sum, sums[t])
+			}
+		}
+		out[k/8][7-k%8] = g.parity(sum)
+	}
+	return out
+}
+
+// Returns the parity of the sum, which must be less than 2^16.
+func (g *ghash) parity(sum vars.Variable) vars.Bool {
+	outputs := g.api.Hint(parityHint, 2, sum)
+	g.api.AssertIsBoolean(outputs[0])
+	g.api.AssertIsInRange(outputs[1], 15)
+	g.api.AssertIsEqual(sum, g.api.Add(outputs[0], g.api.Mul(outputs[1], vars.TWO)))
+	return vars.Bool{Value: outputs[0]}
+}
+
+// Returns the coefficient of x^i of the block.
+func coefficient(b block, i int) vars.Bool {
+	return b[i/8][7-i%8]
+}
+
+// The reductions of x^t modulo x^128 + x^7 + x^2 + x + 1 for t < 255, as bit masks of their
+// coefficients.
+var reductions = func() [255]*big.Int {
+	var r [255]*big.Int
+	for t := range r {
+		if t < 128 {
+			r[t] = new(big.Int).SetBit(new(big.Int), t, 1)
+			continue
+		}
+		// x^t = x^(t - 128) * (1 + x + x^2 + x^7).
+		r[t] = new(big.Int).Xor(r[t-128], r[t-127])
+		r[t].Xor(r[t], r[t-126])
+		r[t].Xor(r[t], r[t-121])
+	}
+	return r
+}()
+
+// Splits its single input into its parity and half of the remainder.
+var parityHint = builder.NewHint("aesgcm.parity", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].SetUint64(uint64(inputs[0].Bit(0)))
+	outputs[1].Rsh(inputs[0], 1)
+	return nil
+})
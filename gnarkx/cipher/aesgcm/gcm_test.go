@@ -0,0 +1,104 @@
+package aesgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestOpenCircuit struct {
+	Key            []vars.Byte
+	Nonce          [12]vars.Byte
+	Ciphertext     []vars.Byte
+	Tag            [16]vars.Byte
+	AdditionalData []vars.Byte
+	Plaintext      []vars.Byte
+}
+
+func (circuit *TestOpenCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	plaintext := Open(*succinctAPI, circuit.Key, circuit.Nonce, circuit.Ciphertext, circuit.Tag, circuit.AdditionalData)
+	for i := range plaintext {
+		succinctAPI.AssertIsEqualByte(plaintext[i], circuit.Plaintext[i])
+	}
+	return nil
+}
+
+func newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext []byte) *TestOpenCircuit {
+	circuit := &TestOpenCircuit{
+		Key:            vars.NewBytesFrom(key),
+		Ciphertext:     vars.NewBytesFrom(ciphertext),
+		AdditionalData: vars.NewBytesFrom(additionalData),
+		Plaintext:      vars.NewBytesFrom(plaintext),
+	}
+	copy(circuit.Nonce[:], vars.NewBytesFrom(nonce))
+	copy(circuit.Tag[:], vars.NewBytesFrom(tag))
+	return circuit
+}
+
+func TestSbox(t *testing.T) {
+	// Spot checks against the table of FIPS 197.
+	for x, expected := range map[byte]byte{0x00: 0x63, 0x01: 0x7c, 0x53: 0xed, 0xff: 0x16} {
+		if sbox(x) != expected {
+			t.Errorf("sbox(%#x) = %#x, expected %#x", x, sbox(x), expected)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	nonce := []byte("succinctlabs")
+	for _, keyLength := range []int{16, 32} {
+		key := make([]byte, keyLength)
+		for i := range key {
+			key[i] = byte(7 * i)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, length := range []int{0, 37} {
+			plaintext := make([]byte, length)
+			for i := range plaintext {
+				plaintext[i] = byte(3 * i)
+			}
+			additionalData := []byte("header")
+			sealed := aead.Seal(nil, nonce, plaintext, additionalData)
+			ciphertext, tag := sealed[:length], sealed[length:]
+
+			circuit := &TestOpenCircuit{
+				Key:            make([]vars.Byte, keyLength),
+				Ciphertext:     make([]vars.Byte, length),
+				AdditionalData: make([]vars.Byte, len(additionalData)),
+				Plaintext:      make([]vars.Byte, length),
+			}
+			assignment := newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext)
+			assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+			// A forged tag is rejected.
+			tag[15] ^= 1
+			assignment = newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext)
+			assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+			tag[15] ^= 1
+
+			// A different plaintext is rejected.
+			if length > 0 {
+				plaintext[0] ^= 1
+				assignment = newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext)
+				assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+			}
+		}
+	}
+}
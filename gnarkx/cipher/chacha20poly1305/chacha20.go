@@ -0,0 +1,102 @@
+package chacha20poly1305
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/bits32"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The constants of the first row of the state, i.e. "expand 32-byte k".
+var sigma = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+// Computes the keystream of ChaCha20 for the given number of bytes starting at the block counter,
+// as little-endian bits of its bytes.
+func keyStream(api builder.API, key [32]vars.Byte, nonce [12]vars.Byte, counter uint32, length int) [][8]vars.Bool {
+	bits32 := bits32.NewAPI(api)
+	var keyWords [8][32]vars.Bool
+	for i := range keyWords {
+		keyWords[i] = toWord(api, key[4*i:4*i+4])
+	}
+	var nonceWords [3][32]vars.Bool
+	for i := range nonceWords {
+		nonceWords[i] = toWord(api, nonce[4*i:4*i+4])
+	}
+
+	stream := make([][8]vars.Bool, 0, length+63)
+	for len(stream) < length {
+		out := block(&bits32, keyWords, counter, nonceWords)
+		for i := range out {
+			stream = append(stream, fromWord(out[i])...)
+		}
+		counter++
+	}
+	return stream[:length]
+}
+
+// Computes a block of the keystream with the ChaCha20 block function of RFC 8439.
+func block(bits32 *bits32.API, key [8][32]vars.Bool, counter uint32, nonce [3][32]vars.Bool) [16][32]vars.Bool {
+	var initial [16][32]vars.Bool
+	for i := 0; i < 4; i++ {
+		initial[i] = vars.NewBoolArrayFromU32(sigma[i])
+	}
+	copy(initial[4:12], key[:])
+	initial[12] = vars.NewBoolArrayFromU32(counter)
+	copy(initial[13:], nonce[:])
+
+	x := initial
+	for i := 0; i < 10; i++ {
+		// Column rounds.
+		quarterRound(bits32, &x, 0, 4, 8, 12)
+		quarterRound(bits32, &x, 1, 5, 9, 13)
+		quarterRound(bits32, &x, 2, 6, 10, 14)
+		quarterRound(bits32, &x, 3, 7, 11, 15)
+		// Diagonal rounds.
+		quarterRound(bits32, &x, 0, 5, 10, 15)
+		quarterRound(bits32, &x, 1, 6, 11, 12)
+		quarterRound(bits32, &x, 2, 7, 8, 13)
+		quarterRound(bits32, &x, 3, 4, 9, 14)
+	}
+	for i := range x {
+		x[i] = bits32.Add(x[i], initial[i])
+	}
+	return x
+}
+
+// Applies the quarter round to the words of the state at the indices a, b, c and d.
+func quarterRound(bits32 *bits32.API, x *[16][32]vars.Bool, a, b, c, d int) {
+	x[a] = bits32.Add(x[a], x[b])
+	x[d] = rotateLeft(bits32, bits32.Xor(x[d], x[a]), 16)
+	x[c] = bits32.Add(x[c], x[d])
+	x[b] = rotateLeft(bits32, bits32.Xor(x[b], x[c]), 12)
+	x[a] = bits32.Add(x[a], x[b])
+	x[d] = rotateLeft(bits32, bits32.Xor(x[d], x[a]), 8)
+	x[c] = bits32.Add(x[c], x[d])
+	x[b] = rotateLeft(bits32, bits32.Xor(x[b], x[c]), 7)
+}
+
+func rotateLeft(bits32 *bits32.API, i1 [32]vars.Bool, offset int) [32]vars.Bool {
+	return bits32.Rotate(i1, 32-offset)
+}
+
+// Converts 4 little-endian bytes to a word, whose bits are in big-endian order.
+func toWord(api builder.API, in []vars.Byte) [32]vars.Bool {
+	var word [32]vars.Bool
+	for i := 0; i < 4; i++ {
+		bits := api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			word[(3-i)*8+7-j] = bits[j]
+		}
+	}
+	return word
+}
+
+// Converts a word to the little-endian bits of its 4 little-endian bytes.
+func fromWord(word [32]vars.Bool) [][8]vars.Bool {
+	out := make([][8]vars.Bool, 4)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			out[i][j] = word[(3-i)*8+7-j]
+		}
+	}
+	return out
+}
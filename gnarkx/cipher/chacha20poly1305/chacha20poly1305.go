@@ -0,0 +1,68 @@
+// The API for decrypting ciphertexts of the ChaCha20-Poly1305 AEAD of RFC 8439, as used by TLS
+// 1.3, given a witness key. Note that at compile time of the circuit, the lengths of the
+// ciphertext and the additional data must be constants.
+package chacha20poly1305
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Decrypts the ciphertext with the key and the nonce, asserting that the tag authenticates the
+// ciphertext and the additional data, and returns the plaintext.
+func Open(
+	api builder.API,
+	key [32]vars.Byte,
+	nonce [12]vars.Byte,
+	ciphertext []vars.Byte,
+	tag [16]vars.Byte,
+	additionalData []vars.Byte,
+) []vars.Byte {
+	defer api.Scope("chacha20poly1305")()
+
+	// The one-time Poly1305 key is the first 32 bytes of the block with counter 0.
+	var polyKey [32]vars.Byte
+	for i, bits := range keyStream(api, key, nonce, 0, 32) {
+		polyKey[i] = api.ToByteFromBits(bits)
+	}
+
+	// The authenticated message is the additional data and the ciphertext, each padded to a
+	// multiple of 16 bytes, followed by their lengths as little-endian u64s.
+	msg := pad16(additionalData)
+	msg = append(msg, pad16(ciphertext)...)
+	msg = append(msg, uint64LE(len(additionalData))...)
+	msg = append(msg, uint64LE(len(ciphertext))...)
+	expected := poly1305(api, polyKey, msg)
+	for i := range tag {
+		api.AssertIsEqualByte(tag[i], expected[i])
+	}
+
+	// The plaintext is the ciphertext xored with the keystream starting at counter 1.
+	stream := keyStream(api, key, nonce, 1, len(ciphertext))
+	plaintext := make([]vars.Byte, len(ciphertext))
+	for i := range ciphertext {
+		bits := api.ToBitsFromByte(ciphertext[i])
+		for j := range bits {
+			bits[j] = api.Xor(bits[j], stream[i][j])
+		}
+		plaintext[i] = api.ToByteFromBits(bits)
+	}
+	return plaintext
+}
+
+// Pads the bytes with zeros to a multiple of 16 bytes.
+func pad16(in []vars.Byte) []vars.Byte {
+	out := append([]vars.Byte{}, in...)
+	for len(out)%16 != 0 {
+		out = append(out, vars.ZERO_BYTE)
+	}
+	return out
+}
+
+func uint64LE(value int) []vars.Byte {
+	bytes := make([]byte, 8)
+	for i := range bytes {
+		bytes[i] = byte(value >> (8 * i))
+	}
+	return vars.NewBytesFrom(bytes)
+}
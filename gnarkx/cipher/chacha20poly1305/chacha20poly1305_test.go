@@ -0,0 +1,92 @@
+package chacha20poly1305
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+type TestOpenCircuit struct {
+	Key            [32]vars.Byte
+	Nonce          [12]vars.Byte
+	Ciphertext     []vars.Byte
+	Tag            [16]vars.Byte
+	AdditionalData []vars.Byte
+	Plaintext      []vars.Byte
+}
+
+func (circuit *TestOpenCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	plaintext := Open(*succinctAPI, circuit.Key, circuit.Nonce, circuit.Ciphertext, circuit.Tag, circuit.AdditionalData)
+	for i := range plaintext {
+		succinctAPI.AssertIsEqualByte(plaintext[i], circuit.Plaintext[i])
+	}
+	return nil
+}
+
+func newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext []byte) *TestOpenCircuit {
+	circuit := &TestOpenCircuit{
+		Ciphertext:     vars.NewBytesFrom(ciphertext),
+		AdditionalData: vars.NewBytesFrom(additionalData),
+		Plaintext:      vars.NewBytesFrom(plaintext),
+	}
+	copy(circuit.Key[:], vars.NewBytesFrom(key))
+	copy(circuit.Nonce[:], vars.NewBytesFrom(nonce))
+	copy(circuit.Tag[:], vars.NewBytesFrom(tag))
+	return circuit
+}
+
+func TestOpen(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	nonce := []byte("succinctlabs")
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lengths which are and are not multiples of the block sizes of ChaCha20 and Poly1305.
+	for _, length := range []int{0, 16, 100} {
+		plaintext := make([]byte, length)
+		for i := range plaintext {
+			plaintext[i] = byte(3 * i)
+		}
+		additionalData := []byte("header")
+		sealed := aead.Seal(nil, nonce, plaintext, additionalData)
+		ciphertext, tag := sealed[:length], sealed[length:]
+
+		circuit := &TestOpenCircuit{
+			Ciphertext:     make([]vars.Byte, length),
+			AdditionalData: make([]vars.Byte, len(additionalData)),
+			Plaintext:      make([]vars.Byte, length),
+		}
+		assignment := newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext)
+		assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// A forged tag is rejected.
+		tag[0] ^= 1
+		assignment = newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext)
+		assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+		tag[0] ^= 1
+
+		// Modified additional data is rejected.
+		assignment = newOpenCircuit(key, nonce, ciphertext, tag, []byte("Header"), plaintext)
+		assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// A different plaintext is rejected.
+		if length > 0 {
+			plaintext[length-1] ^= 1
+			assignment = newOpenCircuit(key, nonce, ciphertext, tag, additionalData, plaintext)
+			assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+		}
+	}
+}
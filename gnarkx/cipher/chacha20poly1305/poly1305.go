@@ -0,0 +1,53 @@
+package chacha20poly1305
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/nonnative"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the Poly1305 tag of the message, whose length must be a multiple of 16 as for the
+// messages authenticated by ChaCha20-Poly1305, with the one-time key r || s.
+func poly1305(api builder.API, key [32]vars.Byte, msg []vars.Byte) [16]vars.Byte {
+	if len(msg)%16 != 0 {
+		panic("message length must be a multiple of 16")
+	}
+	field := nonnative.NewAPI[nonnative.Poly1305Fp](&api)
+
+	// Clamp r, i.e. clear the top 4 bits of every fourth byte and the bottom 2 bits of the bytes
+	// following them.
+	rBytes := make([]vars.Byte, 16)
+	for i := range rBytes {
+		bits := api.ToBitsFromByte(key[i])
+		if i%4 == 3 {
+			for j := 4; j < 8; j++ {
+				bits[j] = vars.FALSE
+			}
+		}
+		if i%4 == 0 && i > 0 {
+			bits[0], bits[1] = vars.FALSE, vars.FALSE
+		}
+		rBytes[i] = api.ToByteFromBits(bits)
+	}
+	r := field.FromBytesLE(rBytes)
+
+	// Evaluate the polynomial with the blocks as coefficients, each of which has a one appended.
+	acc := field.Zero()
+	one := vars.NewBytesFrom([]byte{1})
+	for i := 0; i < len(msg); i += 16 {
+		n := field.FromBytesLE(append(append([]vars.Byte{}, msg[i:i+16]...), one...))
+		acc = field.Mul(field.Add(acc, n), r)
+	}
+
+	// The tag is (acc + s) mod 2^128.
+	accBytes := field.ToBytesLE(acc)
+	sum := vars.ZERO
+	for i := 15; i >= 0; i-- {
+		sum = api.Mul(sum, vars.NewVariableFromInt(256))
+		sum = api.Add(sum, accBytes[i].Value, key[16+i].Value)
+	}
+	sumBytes := api.ToBytesLE(sum, 17)
+	var tag [16]vars.Byte
+	copy(tag[:], sumBytes)
+	return tag
+}
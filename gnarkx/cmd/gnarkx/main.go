@@ -0,0 +1,68 @@
+// The gnarkx command line tool.
+//
+// Usage:
+//
+//	gnarkx gen -name Header -package header [-schema build/schema.json] [-go header_io.go] [-sol HeaderIO.sol]
+//
+// The gen command generates the code of the inputs and outputs of a circuit from its schema, which
+// is exported by running the circuit with the schema flag. See the codegen package for the
+// generated code.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/codegen"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "gen" {
+		fmt.Fprintln(os.Stderr, "usage: gnarkx gen [flags]")
+		os.Exit(2)
+	}
+	if err := gen(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gnarkx gen:", err)
+		os.Exit(1)
+	}
+}
+
+func gen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	schemaPath := flags.String("schema", "build/schema.json", "the schema of the inputs and outputs of the circuit")
+	name := flags.String("name", "", "the name of the circuit, which prefixes the generated types")
+	pkg := flags.String("package", "", "the package of the generated Go code")
+	goPath := flags.String("go", "", "the file of the generated Go code, or empty to skip it")
+	solidityPath := flags.String("sol", "", "the file of the generated Solidity library, or empty to skip it")
+	flags.Parse(args)
+
+	if *goPath == "" && *solidityPath == "" {
+		return fmt.Errorf("nothing to generate, set -go or -sol")
+	}
+	schema, err := builder.ImportSchema(*schemaPath)
+	if err != nil {
+		return err
+	}
+	opts := codegen.Options{Package: *pkg, Name: *name}
+	if *goPath != "" {
+		code, err := codegen.GenerateGo(schema, opts)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*goPath, code, 0644); err != nil {
+			return fmt.Errorf("failed to write Go code: %w", err)
+		}
+	}
+	if *solidityPath != "" {
+		code, err := codegen.GenerateSolidity(schema, opts)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*solidityPath, code, 0644); err != nil {
+			return fmt.Errorf("failed to write Solidity code: %w", err)
+		}
+	}
+	return nil
+}
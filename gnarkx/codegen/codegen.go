@@ -0,0 +1,204 @@
+// The code generation for the inputs and outputs of circuits, which keeps circuits and their
+// consumers in lockstep. From the schema of a circuit, as exported by the schema flag of
+// succinct.Run, it generates:
+//
+//   - Go structs of the inputs and outputs with their packed encoding, decoding and JSON tags,
+//     which are used to assign the input bytes of the circuit and to read its output bytes.
+//   - Go structs of the variables of the inputs and outputs with functions which read them with an
+//     InputReader and write them with an OutputWriter under the names of the schema.
+//   - A Solidity library with structs of the inputs and outputs and their packed encoding and
+//     decoding, i.e. the input bytes of a request and the output bytes of its callback.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+// The options of the generated code.
+type Options struct {
+	// The name of the Go package of the generated code.
+	Package string
+	// The name of the circuit, which prefixes the generated types, e.g. Header for HeaderInput.
+	Name string
+}
+
+// A field of the schema as used by the templates.
+type field struct {
+	builder.SchemaField
+	// The exported Go identifier of the field.
+	GoName string
+	// The Solidity identifier of the field.
+	SolidityName string
+}
+
+// The types of a field in the generated code.
+type fieldType struct {
+	Go       string
+	Variable string
+	Solidity string
+	// The suffixes of the methods of InputReader and OutputWriter for the field.
+	Read  string
+	Write string
+}
+
+var fieldTypes = map[builder.FieldType]fieldType{
+	builder.Uint64Field:  {Go: "uint64", Variable: "vars.U64", Solidity: "uint64", Read: "Uint64", Write: "U64"},
+	builder.Bytes32Field: {Go: "common.Hash", Variable: "[32]vars.Byte", Solidity: "bytes32", Read: "Bytes32", Write: "Bytes32"},
+}
+
+// The inputs or the outputs of a circuit as used by the templates.
+type ioData struct {
+	// Either Input or Output.
+	Kind   string
+	Fields []field
+	Size   int
+}
+
+type templateData struct {
+	Options
+	IO []ioData
+}
+
+// Returns whether any of the inputs or outputs is of the type.
+func (d *templateData) Uses(t string) bool {
+	for _, io := range d.IO {
+		for _, f := range io.Fields {
+			if string(f.Type) == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var templateFuncs = template.FuncMap{
+	"goType":       func(t builder.FieldType) string { return fieldTypes[t].Go },
+	"variableType": func(t builder.FieldType) string { return fieldTypes[t].Variable },
+	"solidityType": func(t builder.FieldType) string { return fieldTypes[t].Solidity },
+	"readMethod":   func(t builder.FieldType) string { return fieldTypes[t].Read },
+	"writeMethod":  func(t builder.FieldType) string { return fieldTypes[t].Write },
+	"lower":        strings.ToLower,
+	"upper":        strings.ToUpper,
+	"fieldEnd":     func(f field) int { return f.Offset + f.Width },
+	"add":          func(i, j int) int { return i + j },
+}
+
+func newTemplateData(schema *builder.Schema, opts Options) (*templateData, error) {
+	if opts.Name == "" || !isIdentifier(opts.Name) {
+		return nil, fmt.Errorf("invalid circuit name %q", opts.Name)
+	}
+	inputs, err := newFields(schema.Inputs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inputs: %w", err)
+	}
+	outputs, err := newFields(schema.Outputs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outputs: %w", err)
+	}
+	return &templateData{
+		Options: opts,
+		IO: []ioData{
+			{Kind: "Input", Fields: inputs, Size: schema.InputSize()},
+			{Kind: "Output", Fields: outputs, Size: schema.OutputSize()},
+		},
+	}, nil
+}
+
+// Converts the fields of the schema, which must be packed in order and have unique names.
+func newFields(fields []builder.SchemaField) ([]field, error) {
+	result := make([]field, len(fields))
+	names := make(map[string]bool)
+	offset := 0
+	for i, f := range fields {
+		if _, ok := fieldTypes[f.Type]; !ok {
+			return nil, fmt.Errorf("field %s has unknown type %s", f.Name, f.Type)
+		}
+		if f.Offset != offset || f.Width != f.Type.Width() {
+			return nil, fmt.Errorf("field %s is not packed", f.Name)
+		}
+		offset += f.Width
+		goName := exportedName(f.Name)
+		if goName == "" || names[goName] {
+			return nil, fmt.Errorf("field %s has an invalid or duplicate name", f.Name)
+		}
+		names[goName] = true
+		result[i] = field{SchemaField: f, GoName: goName, SolidityName: solidityName(goName)}
+	}
+	return result, nil
+}
+
+// Returns the exported Go identifier of a name in camel case or snake case, e.g. BlockHash for
+// blockHash or block_hash.
+func exportedName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' || r == ' ' {
+			upper = true
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return ""
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	result := sb.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		return ""
+	}
+	return result
+}
+
+// Returns the Solidity identifier of an exported Go identifier, i.e. with a lower case initial.
+func solidityName(goName string) string {
+	return strings.ToLower(goName[:1]) + goName[1:]
+}
+
+func isIdentifier(name string) bool {
+	return exportedName(name) == name
+}
+
+// Generates the Go code of the inputs and outputs of the circuit with the schema.
+func GenerateGo(schema *builder.Schema, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("missing package name")
+	}
+	data, err := newTemplateData(schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// Generates the Solidity library of the inputs and outputs of the circuit with the schema, which
+// is named after the circuit followed by IO.
+func GenerateSolidity(schema *builder.Schema, opts Options) ([]byte, error) {
+	data, err := newTemplateData(schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := solidityTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
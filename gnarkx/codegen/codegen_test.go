@@ -0,0 +1,97 @@
+package codegen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+const (
+	goldenGoFile       = "internal/example/example_io.go"
+	goldenSolidityFile = "testdata/ExampleIO.sol"
+)
+
+// The schema of the example package, whose code is generated from it.
+func exampleSchema() *builder.Schema {
+	return &builder.Schema{
+		Inputs: []builder.SchemaField{
+			{Name: "blockHash", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+			{Name: "slot", Type: builder.Uint64Field, Offset: 32, Width: 8},
+		},
+		Outputs: []builder.SchemaField{
+			{Name: "state_root", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+			{Name: "output1", Type: builder.Uint64Field, Offset: 32, Width: 8},
+		},
+	}
+}
+
+// Compares the generated code with the golden file, or updates the golden file if UPDATE_GOLDEN
+// is set.
+func checkGolden(t *testing.T, file string, code []byte) {
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		assert.NoError(t, os.WriteFile(file, code, 0644))
+		return
+	}
+	expected, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(code), "%s is out of date", file)
+}
+
+func TestGenerate(t *testing.T) {
+	opts := Options{Package: "example", Name: "Example"}
+	code, err := GenerateGo(exampleSchema(), opts)
+	assert.NoError(t, err)
+	checkGolden(t, goldenGoFile, code)
+
+	code, err = GenerateSolidity(exampleSchema(), opts)
+	assert.NoError(t, err)
+	checkGolden(t, goldenSolidityFile, code)
+
+	// A circuit without outputs has no Solidity struct for them, which would be empty.
+	schema := &builder.Schema{Inputs: exampleSchema().Inputs}
+	code, err = GenerateSolidity(schema, opts)
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), "struct Input {")
+	assert.NotContains(t, string(code), "struct Output {")
+	_, err = GenerateGo(schema, opts)
+	assert.NoError(t, err)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	opts := Options{Package: "example", Name: "Example"}
+	for _, tc := range []struct {
+		name   string
+		fields []builder.SchemaField
+	}{
+		{"gap", []builder.SchemaField{{Name: "a", Type: builder.Uint64Field, Offset: 8, Width: 8}}},
+		{"width", []builder.SchemaField{{Name: "a", Type: builder.Uint64Field, Offset: 0, Width: 32}}},
+		{"type", []builder.SchemaField{{Name: "a", Type: "uint128", Offset: 0, Width: 16}}},
+		{"name", []builder.SchemaField{{Name: "0a", Type: builder.Uint64Field, Offset: 0, Width: 8}}},
+		{"duplicate", []builder.SchemaField{
+			{Name: "root", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+			{Name: "Root", Type: builder.Bytes32Field, Offset: 32, Width: 32},
+		}},
+	} {
+		_, err := GenerateGo(&builder.Schema{Inputs: tc.fields}, opts)
+		assert.Error(t, err, tc.name)
+	}
+
+	_, err := GenerateGo(exampleSchema(), Options{Package: "example", Name: "example circuit"})
+	assert.Error(t, err)
+	_, err = GenerateGo(exampleSchema(), Options{Name: "Example"})
+	assert.Error(t, err)
+}
+
+func TestExportedName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"blockHash":  "BlockHash",
+		"block_hash": "BlockHash",
+		"input0":     "Input0",
+		"a.b":        "",
+		"_":          "",
+	} {
+		assert.Equal(t, expected, exportedName(name), name)
+	}
+}
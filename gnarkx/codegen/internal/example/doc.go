@@ -0,0 +1,4 @@
+// The code generated for an example circuit, which is checked in so that the generated code is
+// compiled and tested along with the codegen package. It is regenerated with
+// UPDATE_GOLDEN=1 go test ./gnarkx/codegen.
+package example
@@ -0,0 +1,110 @@
+// Code generated by gnarkx gen. DO NOT EDIT.
+
+package example
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes of the encoded inputs of the Example circuit.
+const ExampleInputSize = 40
+
+// The inputs of the Example circuit.
+type ExampleInput struct {
+	BlockHash common.Hash `json:"blockHash"`
+	Slot      uint64      `json:"slot"`
+}
+
+// Encodes the inputs as the packed bytes of the circuit.
+func (v ExampleInput) Bytes() []byte {
+	data := make([]byte, ExampleInputSize)
+	copy(data[0:32], v.BlockHash[:])
+	binary.BigEndian.PutUint64(data[32:40], v.Slot)
+	return data
+}
+
+// Decodes the inputs from the packed bytes of the circuit.
+func DecodeExampleInput(data []byte) (ExampleInput, error) {
+	var v ExampleInput
+	if len(data) != ExampleInputSize {
+		return v, fmt.Errorf("invalid length of the inputs: expected %d bytes, got %d", ExampleInputSize, len(data))
+	}
+	copy(v.BlockHash[:], data[0:32])
+	v.Slot = binary.BigEndian.Uint64(data[32:40])
+	return v, nil
+}
+
+// The inputs of the Example circuit as variables.
+type ExampleInputVariables struct {
+	BlockHash [32]vars.Byte
+	Slot      vars.U64
+}
+
+// Returns the inputs as constant variables, e.g. to assign them in tests.
+func (v ExampleInput) Variables() ExampleInputVariables {
+	var result ExampleInputVariables
+	vars.SetBytes32(&result.BlockHash, v.BlockHash)
+	result.Slot.Set(v.Slot)
+	return result
+}
+
+// Reads the inputs of the Example circuit, which records their names in the schema.
+func ReadExampleInput(r *builder.InputReader) ExampleInputVariables {
+	var result ExampleInputVariables
+	result.BlockHash = r.ReadNamedBytes32("blockHash")
+	result.Slot = r.ReadNamedUint64("slot")
+	return result
+}
+
+// The number of bytes of the encoded outputs of the Example circuit.
+const ExampleOutputSize = 40
+
+// The outputs of the Example circuit.
+type ExampleOutput struct {
+	StateRoot common.Hash `json:"state_root"`
+	Output1   uint64      `json:"output1"`
+}
+
+// Encodes the outputs as the packed bytes of the circuit.
+func (v ExampleOutput) Bytes() []byte {
+	data := make([]byte, ExampleOutputSize)
+	copy(data[0:32], v.StateRoot[:])
+	binary.BigEndian.PutUint64(data[32:40], v.Output1)
+	return data
+}
+
+// Decodes the outputs from the packed bytes of the circuit.
+func DecodeExampleOutput(data []byte) (ExampleOutput, error) {
+	var v ExampleOutput
+	if len(data) != ExampleOutputSize {
+		return v, fmt.Errorf("invalid length of the outputs: expected %d bytes, got %d", ExampleOutputSize, len(data))
+	}
+	copy(v.StateRoot[:], data[0:32])
+	v.Output1 = binary.BigEndian.Uint64(data[32:40])
+	return v, nil
+}
+
+// The outputs of the Example circuit as variables.
+type ExampleOutputVariables struct {
+	StateRoot [32]vars.Byte
+	Output1   vars.U64
+}
+
+// Returns the outputs as constant variables, e.g. to assign them in tests.
+func (v ExampleOutput) Variables() ExampleOutputVariables {
+	var result ExampleOutputVariables
+	vars.SetBytes32(&result.StateRoot, v.StateRoot)
+	result.Output1.Set(v.Output1)
+	return result
+}
+
+// Writes the outputs of the Example circuit, which records their names in the schema.
+func (v ExampleOutputVariables) Write(w *builder.OutputWriter) {
+	w.WriteNamedBytes32("state_root", v.StateRoot)
+	w.WriteNamedU64("output1", v.Output1)
+}
@@ -0,0 +1,84 @@
+package example
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A circuit which outputs its inputs and the slot incremented by one.
+type TestExampleCircuit struct {
+	InputBytes  [ExampleInputSize]vars.Byte
+	OutputBytes [ExampleOutputSize]vars.Byte
+	Inputs      ExampleInputVariables
+	Schema      *builder.Schema `gnark:"-"`
+}
+
+func (circuit *TestExampleCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	inputs := ReadExampleInput(builder.NewInputReader(*succinctAPI, circuit.InputBytes[:]))
+	for i := range inputs.BlockHash {
+		succinctAPI.AssertIsEqualByte(inputs.BlockHash[i], circuit.Inputs.BlockHash[i])
+	}
+	succinctAPI.AssertIsEqualU64(inputs.Slot, circuit.Inputs.Slot)
+
+	outputs := ExampleOutputVariables{StateRoot: inputs.BlockHash, Output1: succinctAPI.AddU64(inputs.Slot, vars.U64{Value: vars.ONE})}
+	writer := builder.NewOutputWriter(*succinctAPI)
+	outputs.Write(writer)
+	writer.Close(circuit.OutputBytes[:])
+	circuit.Schema = succinctAPI.Schema()
+	return nil
+}
+
+func newTestExampleCircuit(input ExampleInput, output ExampleOutput) *TestExampleCircuit {
+	circuit := &TestExampleCircuit{Inputs: input.Variables()}
+	copy(circuit.InputBytes[:], vars.NewBytesFrom(input.Bytes()))
+	copy(circuit.OutputBytes[:], vars.NewBytesFrom(output.Bytes()))
+	return circuit
+}
+
+func TestExample(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	input := ExampleInput{BlockHash: common.HexToHash("0x1234"), Slot: 1 << 40}
+	output := ExampleOutput{StateRoot: input.BlockHash, Output1: input.Slot + 1}
+	circuit := newTestExampleCircuit(input, output)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	witness := newTestExampleCircuit(input, ExampleOutput{StateRoot: input.BlockHash, Output1: input.Slot})
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// The schema of the circuit has the names and the layout of the generated code.
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+	assert.Equal(&builder.Schema{
+		Inputs: []builder.SchemaField{
+			{Name: "blockHash", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+			{Name: "slot", Type: builder.Uint64Field, Offset: 32, Width: 8},
+		},
+		Outputs: []builder.SchemaField{
+			{Name: "state_root", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+			{Name: "output1", Type: builder.Uint64Field, Offset: 32, Width: 8},
+		},
+	}, circuit.Schema)
+
+	// The values survive a round trip through their encodings.
+	decoded, err := DecodeExampleInput(input.Bytes())
+	assert.NoError(err)
+	assert.Equal(input, decoded)
+	_, err = DecodeExampleOutput(input.Bytes()[1:])
+	assert.Error(err)
+	data, err := json.Marshal(output)
+	assert.NoError(err)
+	assert.JSONEq(`{"state_root":"0x0000000000000000000000000000000000000000000000000000000000001234","output1":1099511627777}`, string(data))
+	var unmarshaled ExampleOutput
+	assert.NoError(json.Unmarshal(data, &unmarshaled))
+	assert.Equal(output, unmarshaled)
+}
@@ -0,0 +1,153 @@
+package codegen
+
+import (
+	"text/template"
+)
+
+var goTemplate = template.Must(template.New("go").Funcs(templateFuncs).Parse(`// Code generated by gnarkx gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Uses "uint64"}}
+	"encoding/binary"
+{{- end}}
+	"fmt"
+{{if .Uses "bytes32"}}
+	"github.com/ethereum/go-ethereum/common"
+{{- end}}
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+{{- if or (.Uses "uint64") (.Uses "bytes32")}}
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+{{- end}}
+)
+{{$name := .Name}}
+{{- range .IO}}
+{{- $type := print $name .Kind}}
+{{- $kind := lower .Kind}}
+
+// The number of bytes of the encoded {{$kind}}s of the {{$name}} circuit.
+const {{$type}}Size = {{.Size}}
+
+// The {{$kind}}s of the {{$name}} circuit.
+type {{$type}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{goType .Type}} ` + "`" + `json:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+
+// Encodes the {{$kind}}s as the packed bytes of the circuit.
+func (v {{$type}}) Bytes() []byte {
+	data := make([]byte, {{$type}}Size)
+{{- range .Fields}}
+{{- if eq .Type "uint64"}}
+	binary.BigEndian.PutUint64(data[{{.Offset}}:{{fieldEnd .}}], v.{{.GoName}})
+{{- else}}
+	copy(data[{{.Offset}}:{{fieldEnd .}}], v.{{.GoName}}[:])
+{{- end}}
+{{- end}}
+	return data
+}
+
+// Decodes the {{$kind}}s from the packed bytes of the circuit.
+func Decode{{$type}}(data []byte) ({{$type}}, error) {
+	var v {{$type}}
+	if len(data) != {{$type}}Size {
+		return v, fmt.Errorf("invalid length of the {{$kind}}s: expected %d bytes, got %d", {{$type}}Size, len(data))
+	}
+{{- range .Fields}}
+{{- if eq .Type "uint64"}}
+	v.{{.GoName}} = binary.BigEndian.Uint64(data[{{.Offset}}:{{fieldEnd .}}])
+{{- else}}
+	copy(v.{{.GoName}}[:], data[{{.Offset}}:{{fieldEnd .}}])
+{{- end}}
+{{- end}}
+	return v, nil
+}
+
+// The {{$kind}}s of the {{$name}} circuit as variables.
+type {{$type}}Variables struct {
+{{- range .Fields}}
+	{{.GoName}} {{variableType .Type}}
+{{- end}}
+}
+
+// Returns the {{$kind}}s as constant variables, e.g. to assign them in tests.
+func (v {{$type}}) Variables() {{$type}}Variables {
+	var result {{$type}}Variables
+{{- range .Fields}}
+{{- if eq .Type "uint64"}}
+	result.{{.GoName}}.Set(v.{{.GoName}})
+{{- else}}
+	vars.SetBytes32(&result.{{.GoName}}, v.{{.GoName}})
+{{- end}}
+{{- end}}
+	return result
+}
+{{- if eq .Kind "Input"}}
+
+// Reads the inputs of the {{$name}} circuit, which records their names in the schema.
+func Read{{$type}}(r *builder.InputReader) {{$type}}Variables {
+	var result {{$type}}Variables
+{{- range .Fields}}
+	result.{{.GoName}} = r.ReadNamed{{readMethod .Type}}("{{.Name}}")
+{{- end}}
+	return result
+}
+{{- else}}
+
+// Writes the outputs of the {{$name}} circuit, which records their names in the schema.
+func (v {{$type}}Variables) Write(w *builder.OutputWriter) {
+{{- range .Fields}}
+	w.WriteNamed{{writeMethod .Type}}("{{.Name}}", v.{{.GoName}})
+{{- end}}
+}
+{{- end}}
+{{- end}}
+`))
+
+var solidityTemplate = template.Must(template.New("solidity").Funcs(templateFuncs).Parse(`// SPDX-License-Identifier: MIT
+// Code generated by gnarkx gen. DO NOT EDIT.
+pragma solidity ^0.8.16;
+
+/// @notice The inputs and outputs of the {{.Name}} circuit, which are packed as by abi.encodePacked.
+library {{.Name}}IO {
+{{- range .IO}}
+    uint256 internal constant {{upper .Kind}}_SIZE = {{.Size}};
+{{- end}}
+{{- range .IO}}
+{{- if .Fields}}
+{{- $kind := lower .Kind}}
+
+    struct {{.Kind}} {
+{{- range .Fields}}
+        {{solidityType .Type}} {{.SolidityName}};
+{{- end}}
+    }
+
+    /// @notice Encodes the {{$kind}}s as the packed bytes of the circuit.
+    function encode{{.Kind}}({{.Kind}} memory {{$kind}}) internal pure returns (bytes memory) {
+        return abi.encodePacked(
+{{- range $i, $f := .Fields}}{{if $i}}, {{end}}{{$kind}}.{{$f.SolidityName}}{{end -}}
+        );
+    }
+
+    /// @notice Decodes the {{$kind}}s from the packed bytes of the circuit.
+    function decode{{.Kind}}(bytes memory data) internal pure returns ({{.Kind}} memory {{$kind}}) {
+        require(data.length == {{upper .Kind}}_SIZE, "invalid {{$kind}} length");
+        bytes32 word;
+{{- range .Fields}}
+        assembly {
+            word := mload(add(data, {{add .Offset 32}}))
+        }
+{{- if eq .Type "uint64"}}
+        {{$kind}}.{{.SolidityName}} = uint64(bytes8(word));
+{{- else}}
+        {{$kind}}.{{.SolidityName}} = word;
+{{- end}}
+{{- end}}
+    }
+{{- end}}
+{{- end}}
+}
+`))
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: MIT
+// Code generated by gnarkx gen. DO NOT EDIT.
+pragma solidity ^0.8.16;
+
+/// @notice The inputs and outputs of the Example circuit, which are packed as by abi.encodePacked.
+library ExampleIO {
+    uint256 internal constant INPUT_SIZE = 40;
+    uint256 internal constant OUTPUT_SIZE = 40;
+
+    struct Input {
+        bytes32 blockHash;
+        uint64 slot;
+    }
+
+    /// @notice Encodes the inputs as the packed bytes of the circuit.
+    function encodeInput(Input memory input) internal pure returns (bytes memory) {
+        return abi.encodePacked(input.blockHash, input.slot);
+    }
+
+    /// @notice Decodes the inputs from the packed bytes of the circuit.
+    function decodeInput(bytes memory data) internal pure returns (Input memory input) {
+        require(data.length == INPUT_SIZE, "invalid input length");
+        bytes32 word;
+        assembly {
+            word := mload(add(data, 32))
+        }
+        input.blockHash = word;
+        assembly {
+            word := mload(add(data, 64))
+        }
+        input.slot = uint64(bytes8(word));
+    }
+
+    struct Output {
+        bytes32 stateRoot;
+        uint64 output1;
+    }
+
+    /// @notice Encodes the outputs as the packed bytes of the circuit.
+    function encodeOutput(Output memory output) internal pure returns (bytes memory) {
+        return abi.encodePacked(output.stateRoot, output.output1);
+    }
+
+    /// @notice Decodes the outputs from the packed bytes of the circuit.
+    function decodeOutput(bytes memory data) internal pure returns (Output memory output) {
+        require(data.length == OUTPUT_SIZE, "invalid output length");
+        bytes32 word;
+        assembly {
+            word := mload(add(data, 32))
+        }
+        output.stateRoot = word;
+        assembly {
+            word := mload(add(data, 64))
+        }
+        output.output1 = uint64(bytes8(word));
+    }
+}
@@ -0,0 +1,201 @@
+// The API for the Solidity contract ABI, which is how calldata and return data are encoded on the
+// Ethereum execution layer. See https://docs.soliditylang.org/en/latest/abi-spec.html.
+//
+// Only static types are supported, i.e. every value takes exactly one 32-byte word, so a static
+// tuple is encoded as the concatenation of the words of its values.
+package abi
+
+import (
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The size of a word of the encoding.
+const WordSize = 32
+
+// The size of the function selector which calldata starts with.
+const SelectorSize = 4
+
+// Computes the function selector of a canonical function signature such as
+// "transfer(address,uint256)", i.e. the first four bytes of its keccak256 hash.
+func Selector(signature string) [SelectorSize]byte {
+	var selector [SelectorSize]byte
+	copy(selector[:], crypto.Keccak256([]byte(signature)))
+	return selector
+}
+
+// Encoder is used for encoding a static tuple in a circuit, e.g. the outputs of a circuit exactly
+// as they are decoded by the contract consuming them.
+type Encoder struct {
+	api   builder.API
+	bytes []vars.Byte
+}
+
+// Creates a new Encoder.
+func NewEncoder(api builder.API) *Encoder {
+	return &Encoder{
+		api:   api,
+		bytes: make([]vars.Byte, 0),
+	}
+}
+
+// Writes the function selector of the signature, which must be written first for calldata.
+func (e *Encoder) WriteSelector(signature string) {
+	selector := Selector(signature)
+	for i := 0; i < SelectorSize; i++ {
+		e.bytes = append(e.bytes, vars.Byte{Value: vars.NewVariableFromInt(int(selector[i]))})
+	}
+}
+
+// Writes a uint64 as a big-endian word.
+func (e *Encoder) WriteUint64(i1 vars.U64) {
+	bytes := e.api.ToBytes32FromU64LE(i1)
+	for i := 0; i < WordSize-8; i++ {
+		e.bytes = append(e.bytes, vars.ZERO_BYTE)
+	}
+	for i := 0; i < 8; i++ {
+		e.bytes = append(e.bytes, bytes[8-i-1])
+	}
+}
+
+// Writes a uint256 as a big-endian word.
+func (e *Encoder) WriteUint256(i1 vars.U256) {
+	for i := len(i1.Limbs) - 1; i >= 0; i-- {
+		bytes := e.api.ToBytes32FromU64LE(i1.Limbs[i])
+		for j := 0; j < 8; j++ {
+			e.bytes = append(e.bytes, bytes[8-j-1])
+		}
+	}
+}
+
+// Writes an address left-padded with zeros to a word.
+func (e *Encoder) WriteAddress(address [20]vars.Byte) {
+	for i := 0; i < WordSize-20; i++ {
+		e.bytes = append(e.bytes, vars.ZERO_BYTE)
+	}
+	e.bytes = append(e.bytes, address[:]...)
+}
+
+// Writes a bool as the word 0 or 1.
+func (e *Encoder) WriteBool(i1 vars.Bool) {
+	for i := 0; i < WordSize-1; i++ {
+		e.bytes = append(e.bytes, vars.ZERO_BYTE)
+	}
+	e.bytes = append(e.bytes, vars.Byte{Value: i1.Value})
+}
+
+// Writes a bytes32 as is.
+func (e *Encoder) WriteBytes32(bytes [32]vars.Byte) {
+	e.bytes = append(e.bytes, bytes[:]...)
+}
+
+// Returns the encoding written so far.
+func (e *Encoder) Bytes() []vars.Byte {
+	return e.bytes
+}
+
+// Decoder is used for decoding a static tuple in a circuit, e.g. calldata whose layout is known at
+// compile time. Every read asserts that the word is a canonical encoding of its type.
+type Decoder struct {
+	api   builder.API
+	ptr   int
+	bytes []vars.Byte
+}
+
+// Creates a new Decoder.
+func NewDecoder(api builder.API, bytes []vars.Byte) *Decoder {
+	return &Decoder{
+		api:   api,
+		ptr:   0,
+		bytes: bytes,
+	}
+}
+
+// Reads the next n bytes.
+func (d *Decoder) read(n int) []vars.Byte {
+	if d.ptr+n > len(d.bytes) {
+		panic("read past the end of the encoding")
+	}
+	out := d.bytes[d.ptr : d.ptr+n]
+	d.ptr += n
+	return out
+}
+
+// Reads a word and asserts that its first n bytes are zero. Returns the remaining bytes.
+func (d *Decoder) readPadded(n int) []vars.Byte {
+	word := d.read(WordSize)
+	for i := 0; i < n; i++ {
+		d.api.AssertIsEqual(word[i].Value, vars.ZERO)
+	}
+	return word[n:]
+}
+
+// Returns the big-endian integer of the bytes.
+func (d *Decoder) fromBytesBE(bytes []vars.Byte) vars.Variable {
+	out := vars.ZERO
+	for i := range bytes {
+		out = d.api.Add(d.api.Mul(out, vars.NewVariableFromInt(256)), bytes[i].Value)
+	}
+	return out
+}
+
+// Reads the function selector which calldata starts with.
+func (d *Decoder) ReadSelector() [SelectorSize]vars.Byte {
+	var out [SelectorSize]vars.Byte
+	copy(out[:], d.read(SelectorSize))
+	return out
+}
+
+// Reads the function selector and asserts that it is the one of the signature.
+func (d *Decoder) AssertSelector(signature string) {
+	selector := Selector(signature)
+	out := d.ReadSelector()
+	for i := 0; i < SelectorSize; i++ {
+		d.api.AssertIsEqual(out[i].Value, vars.NewVariableFromInt(int(selector[i])))
+	}
+}
+
+// Reads a uint64, which is a big-endian word whose first 24 bytes are zero.
+func (d *Decoder) ReadUint64() vars.U64 {
+	return vars.U64{Value: d.fromBytesBE(d.readPadded(WordSize - 8))}
+}
+
+// Reads a uint256, which is a big-endian word.
+func (d *Decoder) ReadUint256() vars.U256 {
+	word := d.read(WordSize)
+	var out vars.U256
+	for i := range out.Limbs {
+		start := WordSize - 8*(i+1)
+		out.Limbs[i] = vars.U64{Value: d.fromBytesBE(word[start : start+8])}
+	}
+	return out
+}
+
+// Reads an address, which is a word whose first 12 bytes are zero.
+func (d *Decoder) ReadAddress() [20]vars.Byte {
+	var out [20]vars.Byte
+	copy(out[:], d.readPadded(WordSize-20))
+	return out
+}
+
+// Reads a bool, which is the word 0 or 1.
+func (d *Decoder) ReadBool() vars.Bool {
+	last := d.readPadded(WordSize - 1)[0]
+	d.api.FrontendAPI().AssertIsBoolean(last.Value.Value)
+	return vars.Bool{Value: last.Value}
+}
+
+// Reads a bytes32.
+func (d *Decoder) ReadBytes32() [32]vars.Byte {
+	var out [32]vars.Byte
+	copy(out[:], d.read(WordSize))
+	return out
+}
+
+// Asserts that the whole encoding has been read.
+func (d *Decoder) Close() {
+	if d.ptr != len(d.bytes) {
+		panic("unexpected number of bytes read")
+	}
+}
@@ -0,0 +1,121 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const testSignature = "commit(uint64,uint256,address,bool,bytes32)"
+
+type TestABICircuit struct {
+	Nonce    vars.U64
+	Amount   vars.U256
+	Address  [20]vars.Byte
+	Flag     vars.Bool
+	Root     [32]vars.Byte
+	Calldata []vars.Byte
+}
+
+func (circuit *TestABICircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+
+	encoder := NewEncoder(*succinctAPI)
+	encoder.WriteSelector(testSignature)
+	encoder.WriteUint64(circuit.Nonce)
+	encoder.WriteUint256(circuit.Amount)
+	encoder.WriteAddress(circuit.Address)
+	encoder.WriteBool(circuit.Flag)
+	encoder.WriteBytes32(circuit.Root)
+	encoded := encoder.Bytes()
+	if len(encoded) != len(circuit.Calldata) {
+		panic("unexpected length of the encoding")
+	}
+	for i := range encoded {
+		succinctAPI.AssertIsEqualByte(encoded[i], circuit.Calldata[i])
+	}
+
+	decoder := NewDecoder(*succinctAPI, circuit.Calldata)
+	decoder.AssertSelector(testSignature)
+	nonce := decoder.ReadUint64()
+	amount := decoder.ReadUint256()
+	address := decoder.ReadAddress()
+	flag := decoder.ReadBool()
+	root := decoder.ReadBytes32()
+	decoder.Close()
+
+	succinctAPI.AssertIsEqualU64(nonce, circuit.Nonce)
+	succinctAPI.AssertIsEqualU256(amount, circuit.Amount)
+	for i := 0; i < 20; i++ {
+		succinctAPI.AssertIsEqualByte(address[i], circuit.Address[i])
+	}
+	succinctAPI.AssertIsEqualBool(flag, circuit.Flag)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(root[i], circuit.Root[i])
+	}
+	return nil
+}
+
+func newCalldata(t *testing.T, values ...interface{}) []byte {
+	var arguments gethabi.Arguments
+	for _, name := range []string{"uint64", "uint256", "address", "bool", "bytes32"} {
+		typ, err := gethabi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arguments = append(arguments, gethabi.Argument{Type: typ})
+	}
+	packed, err := arguments.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector := Selector(testSignature)
+	return append(selector[:], packed...)
+}
+
+func TestABI(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	nonce := uint64(0xdeadbeef12345678)
+	amount, _ := new(big.Int).SetString("fedcba9876543210fedcba9876543210fedcba9876543210fedcba98765432", 16)
+	address := common.HexToAddress("0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5")
+	root := common.HexToHash("0x9b83c12c69edb74f6c8dd5d052765c1adf940e320bd1291696e6fa07829eee71")
+
+	newCircuit := func(calldata []byte) *TestABICircuit {
+		circuit := &TestABICircuit{
+			Nonce:    vars.U64{Value: vars.Variable{Value: new(big.Int).SetUint64(nonce)}},
+			Amount:   vars.NewU256(),
+			Flag:     vars.TRUE,
+			Calldata: vars.NewBytesFrom(calldata),
+		}
+		circuit.Amount.Set(amount)
+		for i := 0; i < 20; i++ {
+			circuit.Address[i].Set(address[i])
+		}
+		vars.SetBytes32(&circuit.Root, root)
+		return circuit
+	}
+
+	calldata := newCalldata(t, nonce, amount, address, true, root)
+	circuit := newCircuit(calldata)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A different selector is rejected.
+	wrong := append([]byte{}, calldata...)
+	wrong[0] ^= 1
+	circuit = newCircuit(wrong)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A word which is not the canonical encoding of a bool is rejected.
+	wrong = append([]byte{}, calldata...)
+	wrong[SelectorSize+4*WordSize-1] = 2
+	circuit = newCircuit(wrong)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,124 @@
+// The API for the components of an Ethereum consensus light client, i.e. hashing beacon block
+// headers and sync committees, computing the fork version and the domain of a signature, and
+// verifying the aggregate signature of a sync committee over a header. See
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md.
+package beacon
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/ssz"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/bls12381"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of slots of an epoch.
+const SlotsPerEpoch = 32
+
+// The number of members of a sync committee on mainnet.
+const SyncCommitteeSize = 512
+
+// A beacon block header, whose hash tree root is the block root signed by the sync committee.
+type BeaconBlockHeader struct {
+	Slot          vars.U64
+	ProposerIndex vars.U64
+	ParentRoot    [32]vars.Byte
+	StateRoot     [32]vars.Byte
+	BodyRoot      [32]vars.Byte
+}
+
+// A sync committee, whose public keys are compressed as in the beacon state.
+type SyncCommittee struct {
+	PubKeys         [][bls12381.PublicKeySize]vars.Byte
+	AggregatePubKey [bls12381.PublicKeySize]vars.Byte
+}
+
+// Creates a new sync committee with the given number of members, e.g. as a placeholder for a
+// circuit.
+func NewSyncCommittee(size int) SyncCommittee {
+	committee := SyncCommittee{PubKeys: make([][bls12381.PublicKeySize]vars.Byte, size)}
+	for i := range committee.PubKeys {
+		committee.PubKeys[i] = newPublicKeyBytes()
+	}
+	committee.AggregatePubKey = newPublicKeyBytes()
+	return committee
+}
+
+func newPublicKeyBytes() [bls12381.PublicKeySize]vars.Byte {
+	var result [bls12381.PublicKeySize]vars.Byte
+	copy(result[:], vars.NewBytes(bls12381.PublicKeySize))
+	return result
+}
+
+// An API used for the components of a light client.
+type API struct {
+	api builder.API
+	ssz *ssz.SimpleSerializeAPI
+	bls *bls12381.API
+}
+
+// Creates a new beacon.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api, ssz: ssz.NewAPI(api), bls: bls12381.NewAPI(api)}
+}
+
+// Computes the hash tree root of a beacon block header, i.e. the block root.
+func (a *API) HashTreeRootHeader(header BeaconBlockHeader) [32]vars.Byte {
+	defer a.api.Scope("beacon_header_root")()
+	return a.ssz.HashTreeRootContainer([][32]vars.Byte{
+		a.ssz.HashTreeRootUint64(header.Slot),
+		a.ssz.HashTreeRootUint64(header.ProposerIndex),
+		header.ParentRoot,
+		header.StateRoot,
+		header.BodyRoot,
+	})
+}
+
+// Computes the hash tree root of a sync committee, which is committed to by the current and the
+// next sync committee fields of the beacon state.
+func (a *API) HashTreeRootSyncCommittee(committee SyncCommittee) [32]vars.Byte {
+	defer a.api.Scope("sync_committee_root")()
+	roots := make([][32]vars.Byte, len(committee.PubKeys))
+	for i := range committee.PubKeys {
+		roots[i] = a.ssz.HashTreeRootByteVector(committee.PubKeys[i][:])
+	}
+	return a.ssz.HashTreeRootContainer([][32]vars.Byte{
+		a.ssz.HashTreeRootVector(roots),
+		a.ssz.HashTreeRootByteVector(committee.AggregatePubKey[:]),
+	})
+}
+
+// Asserts that sig is a valid aggregate signature by the participating members of the sync
+// committee over the root of the attested header, which was included in the block at
+// signatureSlot. The domain is computed from the fork version at the slot before signatureSlot as
+// in the light client protocol. Returns the number of participants, so that the caller can
+// enforce a minimum participation, e.g. a supermajority of the committee.
+func (a *API) VerifySyncAggregate(
+	committee SyncCommittee,
+	participation []vars.Bool,
+	attestedHeader BeaconBlockHeader,
+	signatureSlot vars.U64,
+	forks []Fork,
+	genesisValidatorsRoot [32]vars.Byte,
+	sig bls12381.Signature,
+) vars.Variable {
+	defer a.api.Scope("verify_sync_aggregate")()
+	if len(participation) != len(committee.PubKeys) {
+		panic("the number of participation bits must equal the size of the committee")
+	}
+	pubKeys := make([]bls12381.PublicKey, len(committee.PubKeys))
+	for i := range committee.PubKeys {
+		pubKeys[i] = a.bls.DecompressPublicKey(committee.PubKeys[i])
+	}
+	nbParticipants := vars.ZERO
+	for i := range participation {
+		nbParticipants = a.api.Add(nbParticipants, participation[i].Value)
+	}
+
+	// The fork version is the one of max(signatureSlot, 1) - 1.
+	isGenesis := a.api.IsZero(signatureSlot.Value)
+	forkSlot := vars.U64{Value: a.api.Select(isGenesis, vars.ZERO, a.api.Sub(signatureSlot.Value, vars.ONE))}
+	forkVersion := a.ComputeForkVersion(forks, a.ComputeEpochAtSlot(forkSlot))
+	domain := a.bls.ComputeDomain(bls12381.DOMAIN_SYNC_COMMITTEE, forkVersion, genesisValidatorsRoot)
+	a.bls.VerifyAggregateWithDomain(pubKeys, participation, a.HashTreeRootHeader(attestedHeader), domain, sig)
+	return nbParticipants
+}
@@ -0,0 +1,247 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	blsgadget "github.com/succinctlabs/succinctx/gnarkx/signature/bls12381"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Computes the root of the Merkle tree of the chunks padded with zero chunks to a power of two.
+func merkleize(chunks [][32]byte) [32]byte {
+	layer := append([][32]byte{}, chunks...)
+	var zeroHash [32]byte
+	for len(layer) > 1 {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHash)
+		}
+		zeroHash = sha256.Sum256(append(zeroHash[:], zeroHash[:]...))
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+func uint64Root(value uint64) [32]byte {
+	var root [32]byte
+	binary.LittleEndian.PutUint64(root[:], value)
+	return root
+}
+
+func publicKeyRoot(pubKey [48]byte) [32]byte {
+	var chunks [2][32]byte
+	copy(chunks[0][:], pubKey[:32])
+	copy(chunks[1][:], pubKey[32:])
+	return merkleize(chunks[:])
+}
+
+type testHeader struct {
+	slot, proposerIndex             uint64
+	parentRoot, stateRoot, bodyRoot [32]byte
+}
+
+func (h testHeader) root() [32]byte {
+	return merkleize([][32]byte{uint64Root(h.slot), uint64Root(h.proposerIndex), h.parentRoot, h.stateRoot, h.bodyRoot})
+}
+
+func (h testHeader) toVars() BeaconBlockHeader {
+	var header BeaconBlockHeader
+	header.Slot.Set(h.slot)
+	header.ProposerIndex.Set(h.proposerIndex)
+	vars.SetBytes32(&header.ParentRoot, h.parentRoot)
+	vars.SetBytes32(&header.StateRoot, h.stateRoot)
+	vars.SetBytes32(&header.BodyRoot, h.bodyRoot)
+	return header
+}
+
+var testHeaderValue = testHeader{
+	slot:          7_654_321,
+	proposerIndex: 123_456,
+	parentRoot:    sha256.Sum256([]byte("parent")),
+	stateRoot:     sha256.Sum256([]byte("state")),
+	bodyRoot:      sha256.Sum256([]byte("body")),
+}
+
+type TestHeaderCircuit struct {
+	Header BeaconBlockHeader
+	Root   [32]vars.Byte
+}
+
+func (circuit *TestHeaderCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	beacon := NewAPI(succinctAPI)
+	root := beacon.HashTreeRootHeader(circuit.Header)
+	for i := range root {
+		succinctAPI.AssertIsEqualByte(root[i], circuit.Root[i])
+	}
+	return nil
+}
+
+func TestHashTreeRootHeader(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit := &TestHeaderCircuit{Header: testHeaderValue.toVars()}
+	vars.SetBytes32(&circuit.Root, testHeaderValue.root())
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A header of another slot has a different root.
+	circuit.Header.Slot.Set(testHeaderValue.slot + 1)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestForkVersionCircuit struct {
+	Slot    vars.U64
+	Version [4]vars.Byte
+}
+
+func (circuit *TestForkVersionCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	beacon := NewAPI(succinctAPI)
+	version := beacon.ComputeForkVersion(MainnetForks, beacon.ComputeEpochAtSlot(circuit.Slot))
+	for i := range version {
+		succinctAPI.AssertIsEqualByte(version[i], circuit.Version[i])
+	}
+	return nil
+}
+
+func TestComputeForkVersion(t *testing.T) {
+	assert := test.NewAssert(t)
+	testCase := func(slot uint64, version byte) *TestForkVersionCircuit {
+		circuit := &TestForkVersionCircuit{}
+		circuit.Slot.Set(slot)
+		copy(circuit.Version[:], vars.NewBytesFrom([]byte{version, 0, 0, 0}))
+		return circuit
+	}
+
+	// The first and the last slot of each fork.
+	for i, fork := range MainnetForks {
+		first := testCase(fork.Epoch*SlotsPerEpoch, byte(i))
+		assert.NoError(test.IsSolved(first, first, ecc.BN254.ScalarField()))
+		if i > 0 {
+			last := testCase(fork.Epoch*SlotsPerEpoch-1, byte(i-1))
+			assert.NoError(test.IsSolved(last, last, ecc.BN254.ScalarField()))
+		}
+	}
+
+	wrong := testCase(MainnetForks[2].Epoch*SlotsPerEpoch, 1)
+	assert.Error(test.IsSolved(wrong, wrong, ecc.BN254.ScalarField()))
+}
+
+type TestSyncAggregateCircuit struct {
+	Committee             SyncCommittee
+	CommitteeRoot         [32]vars.Byte
+	Participation         []vars.Bool
+	NbParticipants        vars.Variable
+	AttestedHeader        BeaconBlockHeader
+	SignatureSlot         vars.U64
+	GenesisValidatorsRoot [32]vars.Byte
+	Sig                   blsgadget.Signature
+}
+
+func (circuit *TestSyncAggregateCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	beacon := NewAPI(succinctAPI)
+	root := beacon.HashTreeRootSyncCommittee(circuit.Committee)
+	for i := range root {
+		succinctAPI.AssertIsEqualByte(root[i], circuit.CommitteeRoot[i])
+	}
+	nbParticipants := beacon.VerifySyncAggregate(
+		circuit.Committee,
+		circuit.Participation,
+		circuit.AttestedHeader,
+		circuit.SignatureSlot,
+		MainnetForks,
+		circuit.GenesisValidatorsRoot,
+		circuit.Sig,
+	)
+	succinctAPI.AssertIsEqual(nbParticipants, circuit.NbParticipants)
+	return nil
+}
+
+// Signs the attested header with the participating members of a committee of the given size at
+// the signature slot, where the signature uses the given fork version.
+func newSyncAggregateCircuit(participation []bool, signatureSlot uint64, forkVersion [4]byte) *TestSyncAggregateCircuit {
+	genesisValidatorsRoot := sha256.Sum256([]byte("genesis"))
+	var forkData [64]byte
+	copy(forkData[:4], forkVersion[:])
+	copy(forkData[32:], genesisValidatorsRoot[:])
+	forkDataRoot := sha256.Sum256(forkData[:])
+	var signingData [64]byte
+	headerRoot := testHeaderValue.root()
+	copy(signingData[:32], headerRoot[:])
+	copy(signingData[32:36], blsgadget.DOMAIN_SYNC_COMMITTEE[:])
+	copy(signingData[36:], forkDataRoot[:28])
+	signingRoot := sha256.Sum256(signingData[:])
+	hash, err := bls12381.HashToG2(signingRoot[:], []byte(blsgadget.DST))
+	if err != nil {
+		panic(err)
+	}
+
+	circuit := &TestSyncAggregateCircuit{
+		Committee:      NewSyncCommittee(len(participation)),
+		AttestedHeader: testHeaderValue.toVars(),
+	}
+	circuit.SignatureSlot.Set(signatureSlot)
+	vars.SetBytes32(&circuit.GenesisValidatorsRoot, genesisValidatorsRoot)
+	var aggregate bls12381.G1Jac
+	var sig bls12381.G2Jac
+	pubKeyRoots := make([][32]byte, len(participation))
+	nbParticipants := 0
+	for i := range participation {
+		sk := big.NewInt(int64(1000003 * (i + 1)))
+		var pk bls12381.G1Affine
+		pk.ScalarMultiplicationBase(sk)
+		var pkJac bls12381.G1Jac
+		pkJac.FromAffine(&pk)
+		aggregate.AddAssign(&pkJac)
+		compressed := pk.Bytes()
+		copy(circuit.Committee.PubKeys[i][:], vars.NewBytesFrom(compressed[:]))
+		pubKeyRoots[i] = publicKeyRoot(compressed)
+		circuit.Participation = append(circuit.Participation, vars.NewBool(participation[i]))
+		if participation[i] {
+			var partial bls12381.G2Affine
+			partial.ScalarMultiplication(&hash, sk)
+			var partialJac bls12381.G2Jac
+			partialJac.FromAffine(&partial)
+			sig.AddAssign(&partialJac)
+			nbParticipants++
+		}
+	}
+	var aggregateAffine bls12381.G1Affine
+	aggregateAffine.FromJacobian(&aggregate)
+	compressed := aggregateAffine.Bytes()
+	copy(circuit.Committee.AggregatePubKey[:], vars.NewBytesFrom(compressed[:]))
+	committeeRoot := merkleize([][32]byte{merkleize(pubKeyRoots), publicKeyRoot(compressed)})
+	vars.SetBytes32(&circuit.CommitteeRoot, committeeRoot)
+	circuit.NbParticipants = vars.NewVariableFromInt(nbParticipants)
+
+	var sigAffine bls12381.G2Affine
+	sigAffine.FromJacobian(&sig)
+	circuit.Sig = sw_bls12381.NewG2Affine(sigAffine)
+	return circuit
+}
+
+func TestVerifySyncAggregate(t *testing.T) {
+	assert := test.NewAssert(t)
+	participation := []bool{true, true, false, true}
+
+	// The first slot of the Capella fork is signed with the version of the previous slot.
+	signatureSlot := MainnetForks[3].Epoch * SlotsPerEpoch
+	circuit := newSyncAggregateCircuit(participation, signatureSlot, MainnetForks[2].Version)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A signature with the version of the fork at the signature slot does not verify.
+	wrongFork := newSyncAggregateCircuit(participation, signatureSlot, MainnetForks[3].Version)
+	assert.Error(test.IsSolved(wrongFork, wrongFork, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,52 @@
+package beacon
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A fork of the beacon chain, which is active from its epoch on. A fork schedule is a list of
+// forks sorted by epoch, starting with the genesis fork at epoch 0.
+type Fork struct {
+	Epoch   uint64
+	Version [4]byte
+}
+
+// The fork schedule of mainnet, see
+// https://github.com/ethereum/consensus-specs/blob/dev/configs/mainnet.yaml.
+var MainnetForks = []Fork{
+	{Epoch: 0, Version: [4]byte{0x00, 0x00, 0x00, 0x00}},
+	{Epoch: 74240, Version: [4]byte{0x01, 0x00, 0x00, 0x00}},
+	{Epoch: 144896, Version: [4]byte{0x02, 0x00, 0x00, 0x00}},
+	{Epoch: 194048, Version: [4]byte{0x03, 0x00, 0x00, 0x00}},
+	{Epoch: 269568, Version: [4]byte{0x04, 0x00, 0x00, 0x00}},
+	{Epoch: 364032, Version: [4]byte{0x05, 0x00, 0x00, 0x00}},
+}
+
+// Computes the epoch of a slot, i.e. slot / SlotsPerEpoch.
+func (a *API) ComputeEpochAtSlot(slot vars.U64) vars.U64 {
+	return a.api.DivU64(slot, vars.U64{Value: vars.NewVariableFromInt(SlotsPerEpoch)})
+}
+
+// Computes the version of the fork which is active at the epoch, i.e. of the last fork of the
+// schedule whose epoch is at most the given one.
+func (a *API) ComputeForkVersion(forks []Fork, epoch vars.U64) [4]vars.Byte {
+	if len(forks) == 0 || forks[0].Epoch != 0 {
+		panic("the fork schedule must start at epoch 0")
+	}
+	var version [4]vars.Byte
+	for j := range version {
+		version[j] = vars.Byte{Value: vars.NewVariableFromInt(int(forks[0].Version[j]))}
+	}
+	for i := 1; i < len(forks); i++ {
+		if forks[i].Epoch <= forks[i-1].Epoch {
+			panic("the fork schedule must be sorted by epoch")
+		}
+		forkEpoch := vars.U64{Value: vars.Variable{Value: forks[i].Epoch}}
+		isActive := a.api.IsLessOrEqualU64(forkEpoch, epoch)
+		for j := range version {
+			forkByte := vars.NewVariableFromInt(int(forks[i].Version[j]))
+			version[j] = vars.Byte{Value: a.api.Select(isActive, forkByte, version[j].Value)}
+		}
+	}
+	return version
+}
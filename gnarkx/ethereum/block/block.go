@@ -0,0 +1,140 @@
+// The API for Ethereum block headers, which are the entry point of proofs about the state,
+// transactions and receipts of a block: a header is decoded from its RLP encoding and hashed, so
+// that its fields are bound to the block hash.
+package block
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum length of an encoded header, which is reached by a header of the Prague fork with
+// 21 fields, 256-bit integers and 32 bytes of extra data.
+const MaxHeaderLength = 742
+
+// The indices of the fields of a header, which are followed by the fields added by later forks.
+const (
+	parentHashIndex = iota
+	ommersHashIndex
+	coinbaseIndex
+	stateRootIndex
+	transactionsRootIndex
+	receiptsRootIndex
+	logsBloomIndex
+	difficultyIndex
+	numberIndex
+	gasLimitIndex
+	gasUsedIndex
+	timestampIndex
+	nbDecodedFields
+)
+
+// An encoded header padded with zeros to MaxHeaderLength bytes, whose first Length bytes are the
+// encoding.
+type EncodedHeader struct {
+	Data   []vars.Byte
+	Length vars.Variable
+}
+
+// Creates a new encoded header, e.g. as a placeholder for a circuit.
+func NewEncodedHeader() EncodedHeader {
+	return EncodedHeader{Data: vars.NewBytes(MaxHeaderLength), Length: vars.ZERO}
+}
+
+// Sets the encoded header, which must be at most MaxHeaderLength bytes.
+func (h *EncodedHeader) Set(encoded []byte) {
+	if len(encoded) > MaxHeaderLength {
+		panic("the header is longer than the maximum header length")
+	}
+	padded := make([]byte, MaxHeaderLength)
+	copy(padded, encoded)
+	h.Data = vars.NewBytesFrom(padded)
+	h.Length = vars.NewVariableFromInt(len(encoded))
+}
+
+// The fields of a header used by proofs about a block, along with its hash.
+type Header struct {
+	Hash             [32]vars.Byte
+	ParentHash       [32]vars.Byte
+	Coinbase         [20]vars.Byte
+	StateRoot        [32]vars.Byte
+	TransactionsRoot [32]vars.Byte
+	ReceiptsRoot     [32]vars.Byte
+	Number           vars.U64
+	GasLimit         vars.U64
+	GasUsed          vars.U64
+	Timestamp        vars.U64
+}
+
+// An API used for operations related to block headers.
+type API struct {
+	api builder.API
+	rlp *rlp.API
+}
+
+// Creates a new block.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api, rlp: rlp.NewAPI(api)}
+}
+
+// Decodes the header and computes its hash. The fields after the timestamp, which differ between
+// forks, are not decoded but bound by the hash, since the encoding must be a list spanning the
+// length of the header.
+func (a *API) DecodeHeader(header EncodedHeader) Header {
+	defer a.api.Scope("block_header")()
+	list := a.rlp.DecodeItem(header.Data, vars.ZERO)
+	a.api.AssertIsEqual(list.IsList.Value, vars.ONE)
+	a.api.AssertIsEqual(list.End, header.Length)
+	items := a.rlp.DecodeItems(header.Data, list, nbDecodedFields)
+	for i := range items {
+		a.api.AssertIsEqual(items[i].IsString.Value, vars.ONE)
+	}
+	a.api.AssertIsLessOrEqual(items[nbDecodedFields-1].End, list.End)
+
+	var result Header
+	result.Hash = keccak256.HashVariable(a.api, header.Data, header.Length)
+	result.ParentHash = a.bytes32(header.Data, items[parentHashIndex])
+	copy(result.Coinbase[:], a.fixedBytes(header.Data, items[coinbaseIndex], 20))
+	result.StateRoot = a.bytes32(header.Data, items[stateRootIndex])
+	result.TransactionsRoot = a.bytes32(header.Data, items[transactionsRootIndex])
+	result.ReceiptsRoot = a.bytes32(header.Data, items[receiptsRootIndex])
+	result.Number = a.u64(header.Data, items[numberIndex])
+	result.GasLimit = a.u64(header.Data, items[gasLimitIndex])
+	result.GasUsed = a.u64(header.Data, items[gasUsedIndex])
+	result.Timestamp = a.u64(header.Data, items[timestampIndex])
+	return result
+}
+
+// Asserts that the header has the given hash and returns its decoded fields.
+func (a *API) VerifyHeader(header EncodedHeader, blockHash [32]vars.Byte) Header {
+	result := a.DecodeHeader(header)
+	for i := range blockHash {
+		a.api.AssertIsEqualByte(result.Hash[i], blockHash[i])
+	}
+	return result
+}
+
+// Returns the content of a string of exactly n bytes.
+func (a *API) fixedBytes(data []vars.Byte, item rlp.Item, n int) []vars.Byte {
+	a.api.AssertIsEqual(item.Length, vars.NewVariableFromInt(n))
+	return a.rlp.Content(data, item, n)
+}
+
+func (a *API) bytes32(data []vars.Byte, item rlp.Item) [32]vars.Byte {
+	var result [32]vars.Byte
+	copy(result[:], a.fixedBytes(data, item, 32))
+	return result
+}
+
+// Returns the content of an integer of at most 8 bytes.
+func (a *API) u64(data []vars.Byte, item rlp.Item) vars.U64 {
+	a.api.AssertIsLessOrEqual(item.Length, vars.NewVariableFromInt(8))
+	bytes := a.rlp.ContentBytes32(data, item)
+	value := vars.ZERO
+	for i := 24; i < 32; i++ {
+		value = a.api.Add(a.api.Mul(value, vars.NewVariableFromInt(256)), bytes[i].Value)
+	}
+	return vars.U64{Value: value}
+}
@@ -0,0 +1,104 @@
+package block
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethrlp "github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestVerifyHeaderCircuit struct {
+	Header       EncodedHeader
+	BlockHash    [32]vars.Byte
+	StateRoot    [32]vars.Byte
+	ReceiptsRoot [32]vars.Byte
+	Coinbase     [20]vars.Byte
+	Number       vars.U64
+	Timestamp    vars.U64
+}
+
+func (circuit *TestVerifyHeaderCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	header := NewAPI(succinctAPI).VerifyHeader(circuit.Header, circuit.BlockHash)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(header.StateRoot[i], circuit.StateRoot[i])
+		succinctAPI.AssertIsEqualByte(header.ReceiptsRoot[i], circuit.ReceiptsRoot[i])
+	}
+	for i := 0; i < 20; i++ {
+		succinctAPI.AssertIsEqualByte(header.Coinbase[i], circuit.Coinbase[i])
+	}
+	succinctAPI.AssertIsEqualU64(header.Number, circuit.Number)
+	succinctAPI.AssertIsEqualU64(header.Timestamp, circuit.Timestamp)
+	return nil
+}
+
+func newVerifyHeaderCircuit(t *testing.T, header *types.Header) *TestVerifyHeaderCircuit {
+	encoded, err := gethrlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := &TestVerifyHeaderCircuit{Header: NewEncodedHeader()}
+	circuit.Header.Set(encoded)
+	vars.SetBytes32(&circuit.BlockHash, header.Hash())
+	vars.SetBytes32(&circuit.StateRoot, header.Root)
+	vars.SetBytes32(&circuit.ReceiptsRoot, header.ReceiptHash)
+	copy(circuit.Coinbase[:], vars.NewBytesFrom(header.Coinbase[:]))
+	circuit.Number.Set(header.Number.Uint64())
+	circuit.Timestamp.Set(header.Time)
+	return circuit
+}
+
+func TestVerifyHeader(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	withdrawalsHash := common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	headers := map[string]*types.Header{
+		// A header before London without a base fee.
+		"frontier": {
+			ParentHash: common.HexToHash("0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"),
+			Coinbase:   common.HexToAddress("0x05a56e2d52c817161883f50c441c3228cfe54d9f"),
+			Root:       common.HexToHash("0xd67e4d450343046425ae4271474353857ab860dbc0a1dde64b41b5cd3a532bf3"),
+			Difficulty: big.NewInt(17171480576),
+			Number:     big.NewInt(1),
+			GasLimit:   5000,
+			Time:       1438269988,
+			Extra:      []byte("Geth/v1.0.0/linux/go1.4.2"),
+		},
+		// A header of the Shanghai fork with a base fee and a withdrawals root.
+		"shanghai": {
+			ParentHash:      common.HexToHash("0x9b83c12c69edb74f6c8dd5d052765c1adf940e320bd1291696e6fa07829eee71"),
+			Coinbase:        common.HexToAddress("0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"),
+			Root:            common.HexToHash("0x6a2ef1bd1a4e8dd6f1e9c4b3d4b0a7e0d2c9e6cb4e01f5a0f7d1f9c5e3b2a1f0"),
+			ReceiptHash:     common.HexToHash("0x1f2e3d4c5b6a79880f1e2d3c4b5a69788f9e0d1c2b3a49586f7e8d9c0b1a2938"),
+			Difficulty:      big.NewInt(0),
+			Number:          big.NewInt(17034870),
+			GasLimit:        30000000,
+			GasUsed:         12345678,
+			Time:            1681338455,
+			Extra:           []byte("beaverbuild.org"),
+			BaseFee:         big.NewInt(24000000000),
+			WithdrawalsHash: &withdrawalsHash,
+		},
+	}
+	for name, header := range headers {
+		circuit := newVerifyHeaderCircuit(t, header)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), name)
+
+		// A wrong block hash is rejected.
+		circuit = newVerifyHeaderCircuit(t, header)
+		circuit.BlockHash[0] = vars.Byte{Value: vars.NewVariableFromInt(0)}
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), name)
+
+		// A wrong field is rejected.
+		circuit = newVerifyHeaderCircuit(t, header)
+		circuit.Timestamp.Set(header.Time + 1)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), name)
+	}
+}
@@ -1,3 +1,284 @@
 // The API for operations related to Merkle Patricia Tries, a serialization method used by the
 // Ethereum execution layer.
 package mpt
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum length of an encoded node, which is reached by a branch node with 16 children and
+// an empty value.
+const MaxNodeLength = 532
+
+// The maximum length of an encoded account, i.e. of the list [nonce, balance, storageRoot,
+// codeHash] with a 64-bit nonce and a 256-bit balance.
+const MaxAccountLength = 2 + 9 + 33 + 33 + 33
+
+// The maximum length of an encoded storage value, i.e. of a 256-bit integer.
+const MaxStorageValueLength = 33
+
+// A proof of inclusion or exclusion of a key as returned by eth_getProof, i.e. the encoded nodes
+// on the path from the root to the key. Each node is padded to the maximum node length, and only
+// the first Depth nodes are part of the proof, so both the maximum length and the maximum depth
+// are constants at compile time of the circuit.
+type Proof struct {
+	Nodes   [][]vars.Byte
+	Lengths []vars.Variable
+	Depth   vars.Variable
+}
+
+// An account of the state trie. The nonce and the balance are big-endian integers padded to 32
+// bytes.
+type Account struct {
+	Nonce       [32]vars.Byte
+	Balance     [32]vars.Byte
+	StorageRoot [32]vars.Byte
+	CodeHash    [32]vars.Byte
+}
+
+// Creates a new proof with at most maxDepth nodes of at most maxNodeLength bytes.
+func NewProof(maxDepth int, maxNodeLength int) Proof {
+	proof := Proof{
+		Nodes:   vars.NewBytesArray(maxDepth, maxNodeLength),
+		Lengths: make([]vars.Variable, maxDepth),
+		Depth:   vars.ZERO,
+	}
+	for i := 0; i < maxDepth; i++ {
+		proof.Lengths[i] = vars.ZERO
+	}
+	return proof
+}
+
+// Sets the proof from the encoded nodes, which must fit into the dimensions of the proof.
+func (p *Proof) Set(nodes [][]byte) {
+	if len(nodes) > len(p.Nodes) {
+		panic("the proof has more nodes than the maximum depth")
+	}
+	for i := range p.Nodes {
+		var node []byte
+		if i < len(nodes) {
+			node = nodes[i]
+		}
+		if len(node) > len(p.Nodes[i]) {
+			panic("the node is longer than the maximum node length")
+		}
+		padded := make([]byte, len(p.Nodes[i]))
+		copy(padded, node)
+		vars.SetBytes(&p.Nodes[i], padded)
+		p.Lengths[i] = vars.NewVariableFromInt(len(node))
+	}
+	p.Depth = vars.NewVariableFromInt(len(nodes))
+}
+
+// An API used for operations related to Merkle Patricia Tries.
+type API struct {
+	api builder.API
+	rlp *rlp.API
+}
+
+// Creates a new mpt.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api, rlp: rlp.NewAPI(api)}
+}
+
+// Verifies a proof for keccak256(key) in the secure trie with the given root. Returns whether the
+// key exists and its value, which is padded with zeros to maxValueLength bytes, along with the
+// length of the value. If the key does not exist, the value is empty.
+//
+// Nodes which are embedded in their parent because their encoding is shorter than 32 bytes are
+// not supported, and neither is the empty trie. Both do not occur for accounts and in practice
+// only very rarely for storage slots.
+func (a *API) VerifyProof(root [32]vars.Byte, key []vars.Byte, proof Proof, maxValueLength int) (vars.Bool, []vars.Byte, vars.Variable) {
+	hashedKey := keccak256.Hash(a.api, key)
+	return a.verifyProof(root, hashedKey[:], vars.NewVariableFromInt(64), proof, maxValueLength)
+}
+
+// Verifies a proof for the first length bytes of path in a trie whose keys are not hashed, e.g.
+// the transactions and receipts tries of a block, which are keyed by the encoded index. The keys
+// of the trie must be prefix-free, so that all values are stored in leaves. See VerifyProof.
+func (a *API) VerifyProofWithPath(root [32]vars.Byte, path []vars.Byte, length vars.Variable, proof Proof, maxValueLength int) (vars.Bool, []vars.Byte, vars.Variable) {
+	a.api.AssertIsLessOrEqual(length, vars.NewVariableFromInt(len(path)))
+	return a.verifyProof(root, path, a.api.Mul(length, vars.TWO), proof, maxValueLength)
+}
+
+// Verifies a proof for the key whose first keyLength nibbles are the nibbles of keyBytes.
+func (a *API) verifyProof(root [32]vars.Byte, keyBytes []vars.Byte, keyLength vars.Variable, proof Proof, maxValueLength int) (vars.Bool, []vars.Byte, vars.Variable) {
+	maxDepth := len(proof.Nodes)
+	maxNodeLength := 0
+	for i := range proof.Nodes {
+		if len(proof.Nodes[i]) > maxNodeLength {
+			maxNodeLength = len(proof.Nodes[i])
+		}
+	}
+
+	// The key as nibbles, followed by a zero nibble so that it can be indexed at its end. Nibbles
+	// after keyLength are ignored by the caller's choice of keyLength.
+	keyNibbles := make([]vars.Variable, 2*len(keyBytes)+1)
+	for i := range keyBytes {
+		keyNibbles[2*i], keyNibbles[2*i+1] = a.nibbles(keyBytes[i])
+	}
+	keyNibbles[2*len(keyBytes)] = vars.ZERO
+
+	// The selectors isLast[i] are set iff i == depth - 1, and exactly one of them must be set.
+	isLast := make([]vars.Variable, maxDepth)
+	nbLast := vars.ZERO
+	for i := 0; i < maxDepth; i++ {
+		isLast[i] = a.api.IsZero(a.api.Sub(proof.Depth, vars.NewVariableFromInt(i+1))).Value
+		nbLast = a.api.Add(nbLast, isLast[i])
+	}
+	a.api.AssertIsEqual(nbLast, vars.ONE)
+
+	expected := root[:]
+	keyIndex := vars.ZERO
+	isActive := vars.ONE
+	exists := vars.ZERO
+	lastNode := make([]vars.Byte, maxNodeLength)
+	for i := range lastNode {
+		lastNode[i] = vars.ZERO_BYTE
+	}
+	valueOffset, valueLength := vars.ZERO, vars.ZERO
+	for i := 0; i < maxDepth; i++ {
+		node := proof.Nodes[i]
+
+		// The node must be referenced by its hash, and it must be a list of 2 or 17 strings.
+		hash := keccak256.HashVariable(a.api, node, proof.Lengths[i])
+		for j := 0; j < 32; j++ {
+			a.assertIf(isActive, hash[j].Value, expected[j].Value)
+		}
+		list := a.rlp.DecodeItem(node, vars.ZERO)
+		a.assertIf(isActive, list.IsList.Value, vars.ONE)
+		a.assertIf(isActive, list.End, proof.Lengths[i])
+		items := a.rlp.DecodeItems(node, list, 17)
+		isPair := a.api.IsZero(a.api.Sub(items[1].End, list.End))
+		isBranch := a.api.Not(isPair)
+		isActiveBranch := a.api.Mul(isActive, isBranch.Value)
+		for j := range items {
+			if j < 2 {
+				a.assertIf(isActive, items[j].IsString.Value, vars.ONE)
+			} else {
+				a.assertIf(isActiveBranch, items[j].IsString.Value, vars.ONE)
+			}
+		}
+		a.assertIf(isActiveBranch, items[16].End, list.End)
+
+		// A branch node continues with the child at the next nibble of the key, unless it is empty.
+		keySel := a.api.Selector(keyIndex, len(keyNibbles))
+		nibble := vars.ZERO
+		for j := range keySel {
+			nibble = a.api.Add(nibble, a.api.Mul(keySel[j], keyNibbles[j]))
+		}
+		nibbleSel := a.api.Selector(nibble, 16)
+		childPrefix, childOffset := vars.ZERO, vars.ZERO
+		for j := 0; j < 16; j++ {
+			childPrefix = a.api.Add(childPrefix, a.api.Mul(nibbleSel[j], items[j].Prefix))
+			childOffset = a.api.Add(childOffset, a.api.Mul(nibbleSel[j], items[j].Offset))
+		}
+		isEmptyChild := a.api.IsZero(a.api.Sub(childPrefix, vars.NewVariableFromInt(0x80)))
+		isHashChild := a.api.IsZero(a.api.Sub(childPrefix, vars.NewVariableFromInt(0xa0)))
+
+		// The path of a leaf or extension node is hex-prefix encoded, where the high nibble of the
+		// first byte is 2 for a leaf and 1 for an odd number of nibbles, in which case the low
+		// nibble of the first byte is the first nibble of the path.
+		path := a.rlp.Content(node, items[0], 33)
+		flagBits := a.api.ToBitsFromByte(path[0])
+		isLeaf := a.api.And(isPair, flagBits[5])
+		isExtension := a.api.And(isPair, a.api.Not(flagBits[5]))
+		isOdd := flagBits[4]
+		pathNibbles := make([]vars.Variable, 66)
+		for j := 0; j < 33; j++ {
+			pathNibbles[2*j], pathNibbles[2*j+1] = a.nibbles(path[j])
+		}
+		pathLength := a.api.Add(a.api.Mul(a.api.Sub(items[0].Length, vars.ONE), vars.TWO), isOdd.Value)
+
+		// The path matches iff it is a prefix of the remaining key.
+		pathEndSel := a.api.Selector(pathLength, 64)
+		inPath := vars.ONE
+		isMatch := vars.TRUE
+		for j := 0; j < 64; j++ {
+			inPath = a.api.Sub(inPath, pathEndSel[j])
+			keyNibble := vars.ZERO
+			for k := 0; k+j < len(keyNibbles); k++ {
+				keyNibble = a.api.Add(keyNibble, a.api.Mul(keySel[k], keyNibbles[k+j]))
+			}
+			pathNibble := a.api.Select(isOdd, pathNibbles[j+1], pathNibbles[j+2])
+			isEqual := a.api.IsZero(a.api.Sub(pathNibble, keyNibble))
+			isMismatch := a.api.And(vars.Bool{Value: inPath}, a.api.Not(isEqual))
+			isMatch = a.api.And(isMatch, a.api.Not(isMismatch))
+		}
+		isKeyEnd := a.api.IsZero(a.api.Sub(a.api.Add(keyIndex, pathLength), keyLength))
+		isMatchingLeaf := a.api.And(isLeaf, a.api.And(isMatch, isKeyEnd))
+		isMatchingExtension := a.api.And(isExtension, isMatch)
+		isHashReference := a.api.IsZero(a.api.Sub(items[1].Prefix, vars.NewVariableFromInt(0xa0)))
+
+		// Every node but the last must continue to a child, and the last one must either contain
+		// the key or prove that it does not exist.
+		continues := a.api.Add(
+			a.api.Mul(isBranch.Value, isHashChild.Value),
+			a.api.Mul(isMatchingExtension.Value, isHashReference.Value),
+		)
+		includes := isMatchingLeaf.Value
+		excludes := a.api.Add(
+			a.api.Mul(isLeaf.Value, a.api.Not(isMatchingLeaf).Value),
+			a.api.Mul(isBranch.Value, isEmptyChild.Value),
+			a.api.Mul(isExtension.Value, a.api.Not(isMatch).Value),
+		)
+		a.assertIf(a.api.Sub(isActive, isLast[i]), continues, vars.ONE)
+		a.assertIf(isLast[i], a.api.Add(includes, excludes), vars.ONE)
+		exists = a.api.Add(exists, a.api.Mul(isLast[i], includes))
+
+		reference := rlp.Item{Offset: a.api.Select(isBranch, childOffset, items[1].Offset), Length: vars.NewVariableFromInt(32)}
+		expected = a.rlp.Content(node, reference, 32)
+		keyIndex = a.api.Add(keyIndex, isBranch.Value, a.api.Mul(isExtension.Value, pathLength))
+
+		// The value of a leaf is its second item.
+		for j := range node {
+			lastNode[j] = vars.Byte{Value: a.api.Add(lastNode[j].Value, a.api.Mul(isLast[i], node[j].Value))}
+		}
+		valueOffset = a.api.Add(valueOffset, a.api.Mul(isLast[i], items[1].Offset))
+		valueLength = a.api.Add(valueLength, a.api.Mul(isLast[i], items[1].Length))
+		isActive = a.api.Sub(isActive, isLast[i])
+	}
+
+	valueLength = a.api.Mul(exists, valueLength)
+	a.api.AssertIsLessOrEqual(valueLength, vars.NewVariableFromInt(maxValueLength))
+	value := a.rlp.Content(lastNode, rlp.Item{Offset: valueOffset, Length: valueLength}, maxValueLength)
+	return vars.Bool{Value: exists}, value, valueLength
+}
+
+// Verifies a proof for the account at the address in the state trie with the given root. Returns
+// whether the account exists and the account, which is zero if it does not exist.
+func (a *API) VerifyAccountProof(stateRoot [32]vars.Byte, address [20]vars.Byte, proof Proof) (vars.Bool, Account) {
+	exists, value, _ := a.VerifyProof(stateRoot, address[:], proof, MaxAccountLength)
+
+	// The account is the list [nonce, balance, storageRoot, codeHash].
+	list := a.rlp.DecodeItem(value, vars.ZERO)
+	a.assertIf(exists.Value, list.IsList.Value, vars.ONE)
+	fields := a.rlp.DecodeItems(value, list, 4)
+	for i := range fields {
+		a.assertIf(exists.Value, fields[i].IsString.Value, vars.ONE)
+	}
+	for i := 2; i < 4; i++ {
+		a.assertIf(exists.Value, fields[i].Length, vars.NewVariableFromInt(32))
+	}
+
+	var account Account
+	account.Nonce = a.rlp.ContentBytes32(value, fields[0])
+	account.Balance = a.rlp.ContentBytes32(value, fields[1])
+	copy(account.StorageRoot[:], a.rlp.Content(value, fields[2], 32))
+	copy(account.CodeHash[:], a.rlp.Content(value, fields[3], 32))
+	return exists, account
+}
+
+// Verifies a proof for the slot in the storage trie with the given root. Returns the value of the
+// slot, which is zero if it does not exist.
+func (a *API) VerifyStorageProof(storageRoot [32]vars.Byte, slot [32]vars.Byte, proof Proof) [32]vars.Byte {
+	exists, value, _ := a.VerifyProof(storageRoot, slot[:], proof, MaxStorageValueLength)
+
+	// The value is the encoding of an integer without leading zeros.
+	field := a.rlp.DecodeItem(value, vars.ZERO)
+	a.assertIf(exists.Value, field.IsString.Value, vars.ONE)
+	return a.rlp.ContentBytes32(value, field)
+}
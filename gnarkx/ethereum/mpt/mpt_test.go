@@ -0,0 +1,206 @@
+package mpt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const testMaxDepth = 4
+
+// Collects the nodes of a proof in the order in which they are written, i.e. from the root.
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// Builds a secure trie with the given keys and values and returns its root and a proof for key.
+func newTestProof(t *testing.T, keys [][]byte, values [][]byte, key []byte) (common.Hash, [][]byte) {
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := range keys {
+		if err := tr.Update(crypto.Keccak256(keys[i]), values[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var proof proofList
+	if err := tr.Prove(crypto.Keccak256(key), 0, &proof); err != nil {
+		t.Fatal(err)
+	}
+	return tr.Hash(), proof
+}
+
+func newBytes32(in []byte) [32]vars.Byte {
+	var result [32]vars.Byte
+	var value [32]byte
+	copy(value[32-len(in):], in)
+	vars.SetBytes32(&result, value)
+	return result
+}
+
+type TestStorageProofCircuit struct {
+	Root  [32]vars.Byte
+	Slot  [32]vars.Byte
+	Proof Proof
+	Value [32]vars.Byte
+}
+
+func (circuit *TestStorageProofCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	mpt := NewAPI(succinctAPI)
+	value := mpt.VerifyStorageProof(circuit.Root, circuit.Slot, circuit.Proof)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(value[i], circuit.Value[i])
+	}
+	return nil
+}
+
+func TestStorageProof(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var slots, values [][]byte
+	for i := 1; i <= 40; i++ {
+		slots = append(slots, common.BigToHash(big.NewInt(int64(i))).Bytes())
+		value, err := rlp.EncodeToBytes(new(big.Int).Lsh(big.NewInt(int64(i)), uint(6*i)))
+		assert.NoError(err)
+		values = append(values, value)
+	}
+
+	newCircuit := func(slot []byte, value *big.Int) *TestStorageProofCircuit {
+		root, nodes := newTestProof(t, slots, values, slot)
+		proof := NewProof(testMaxDepth, MaxNodeLength)
+		proof.Set(nodes)
+		return &TestStorageProofCircuit{
+			Root:  newBytes32(root.Bytes()),
+			Slot:  newBytes32(slot),
+			Proof: proof,
+			Value: newBytes32(value.Bytes()),
+		}
+	}
+
+	// A single byte value, a short value and a full word.
+	for _, i := range []int{0, 3, 39} {
+		expected := new(big.Int).Lsh(big.NewInt(int64(i+1)), uint(6*(i+1)))
+		circuit := newCircuit(slots[i], expected)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A slot which is not in the trie has the value zero.
+	missing := common.BigToHash(big.NewInt(1000)).Bytes()
+	circuit := newCircuit(missing, big.NewInt(0))
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A wrong value or a proof for another slot is rejected.
+	circuit = newCircuit(slots[3], big.NewInt(1))
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newCircuit(slots[3], new(big.Int).Lsh(big.NewInt(4), 24))
+	circuit.Slot = newBytes32(slots[4])
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A truncated proof is rejected.
+	circuit = newCircuit(slots[3], new(big.Int).Lsh(big.NewInt(4), 24))
+	circuit.Proof.Depth = vars.ONE
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestAccountProofCircuit struct {
+	Root    [32]vars.Byte
+	Address [20]vars.Byte
+	Proof   Proof
+	Exists  vars.Bool
+	Account Account
+}
+
+func (circuit *TestAccountProofCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	mpt := NewAPI(succinctAPI)
+	exists, account := mpt.VerifyAccountProof(circuit.Root, circuit.Address, circuit.Proof)
+	succinctAPI.AssertIsEqualBool(exists, circuit.Exists)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(account.Nonce[i], circuit.Account.Nonce[i])
+		succinctAPI.AssertIsEqualByte(account.Balance[i], circuit.Account.Balance[i])
+		succinctAPI.AssertIsEqualByte(account.StorageRoot[i], circuit.Account.StorageRoot[i])
+		succinctAPI.AssertIsEqualByte(account.CodeHash[i], circuit.Account.CodeHash[i])
+	}
+	return nil
+}
+
+func TestAccountProof(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var addresses, encoded [][]byte
+	var accounts []types.StateAccount
+	for i := 0; i < 20; i++ {
+		account := types.StateAccount{
+			Nonce:    uint64(i * 1000),
+			Balance:  new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(i+15)), nil),
+			Root:     crypto.Keccak256Hash([]byte{byte(i)}),
+			CodeHash: crypto.Keccak256([]byte{byte(i), 1}),
+		}
+		value, err := rlp.EncodeToBytes(&account)
+		assert.NoError(err)
+		addresses = append(addresses, crypto.Keccak256([]byte{byte(i)})[:20])
+		accounts = append(accounts, account)
+		encoded = append(encoded, value)
+	}
+
+	newCircuit := func(address []byte, account *types.StateAccount) *TestAccountProofCircuit {
+		root, nodes := newTestProof(t, addresses, encoded, address)
+		proof := NewProof(testMaxDepth, MaxNodeLength)
+		proof.Set(nodes)
+		circuit := &TestAccountProofCircuit{
+			Root:   newBytes32(root.Bytes()),
+			Proof:  proof,
+			Exists: vars.NewBool(account != nil),
+			Account: Account{
+				Nonce:       newBytes32(nil),
+				Balance:     newBytes32(nil),
+				StorageRoot: newBytes32(nil),
+				CodeHash:    newBytes32(nil),
+			},
+		}
+		for i := 0; i < 20; i++ {
+			circuit.Address[i] = vars.Byte{Value: vars.NewVariableFromInt(int(address[i]))}
+		}
+		if account != nil {
+			circuit.Account = Account{
+				Nonce:       newBytes32(new(big.Int).SetUint64(account.Nonce).Bytes()),
+				Balance:     newBytes32(account.Balance.Bytes()),
+				StorageRoot: newBytes32(account.Root.Bytes()),
+				CodeHash:    newBytes32(account.CodeHash),
+			}
+		}
+		return circuit
+	}
+
+	circuit := newCircuit(addresses[7], &accounts[7])
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// An account with a zero nonce.
+	circuit = newCircuit(addresses[0], &accounts[0])
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// An address which is not in the trie does not exist.
+	circuit = newCircuit(common.HexToAddress("0xdeadbeef").Bytes(), nil)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The proof does not show an account for an address which is not in the trie.
+	circuit = newCircuit(common.HexToAddress("0xdeadbeef").Bytes(), &accounts[7])
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,21 @@
+package mpt
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Returns the high and the low nibble of a byte.
+func (a *API) nibbles(b vars.Byte) (vars.Variable, vars.Variable) {
+	bits := a.api.ToBitsFromByte(b)
+	high, low := vars.ZERO, vars.ZERO
+	for i := 3; i >= 0; i-- {
+		low = a.api.Add(a.api.Mul(low, vars.TWO), bits[i].Value)
+		high = a.api.Add(a.api.Mul(high, vars.TWO), bits[i+4].Value)
+	}
+	return high, low
+}
+
+// Asserts that x == y if the condition is set.
+func (a *API) assertIf(condition vars.Variable, x, y vars.Variable) {
+	a.api.AssertIsEqual(a.api.Mul(condition, a.api.Sub(x, y)), vars.ZERO)
+}
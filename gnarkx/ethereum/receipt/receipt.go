@@ -0,0 +1,218 @@
+// The API for transaction receipts and their event logs, which are proven against the receipts
+// root of a block header: a receipt is verified by its inclusion in the receipts trie, keyed by
+// the encoded index of its transaction, and a log is then extracted from the receipt. This is
+// the basis of bridges relaying events emitted on the Ethereum execution layer.
+package receipt
+
+import (
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/mpt"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum number of topics of a log, i.e. the event signature and three indexed parameters.
+const MaxTopics = 4
+
+// The number of bytes a leaf node of the receipts trie has in addition to its receipt, i.e. the
+// headers of the node and the receipt and the hex-prefix encoded path of at most 3 bytes.
+const leafOverhead = 3 + 5 + 3
+
+// A log emitted by a transaction. Only the first NbTopics topics are set and the others are zero,
+// and the data is padded with zeros to its maximum length.
+type Log struct {
+	Address  [20]vars.Byte
+	Topics   [MaxTopics][32]vars.Byte
+	NbTopics vars.Variable
+	Data     vars.VariableBytes
+}
+
+// An API used for operations related to receipts.
+type API struct {
+	api builder.API
+	rlp *rlp.API
+	mpt *mpt.API
+}
+
+// Creates a new receipt.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api, rlp: rlp.NewAPI(api), mpt: mpt.NewAPI(api)}
+}
+
+// Creates a new proof of a receipt with at most maxDepth nodes, whose leaf holds an encoded
+// receipt of at most maxReceiptLength bytes.
+func NewProof(maxDepth int, maxReceiptLength int) mpt.Proof {
+	maxNodeLength := maxReceiptLength + leafOverhead
+	if maxNodeLength < mpt.MaxNodeLength {
+		maxNodeLength = mpt.MaxNodeLength
+	}
+	return mpt.NewProof(maxDepth, maxNodeLength)
+}
+
+// Computes the topic of an event with a canonical signature such as
+// "Transfer(address,address,uint256)", i.e. its keccak256 hash.
+func EventSignature(signature string) [32]byte {
+	var topic [32]byte
+	copy(topic[:], crypto.Keccak256([]byte(signature)))
+	return topic
+}
+
+// Verifies the proof of the receipt of the transaction at txIndex in the receipts trie with the
+// given root and returns the encoded receipt, which is padded to the maximum node length of the
+// proof. Typed receipts start with their type byte, as they are stored in the trie.
+func (a *API) VerifyReceipt(receiptsRoot [32]vars.Byte, txIndex vars.Variable, proof mpt.Proof) vars.VariableBytes {
+	defer a.api.Scope("verify_receipt")()
+	maxLength := 0
+	for i := range proof.Nodes {
+		if len(proof.Nodes[i]) > maxLength {
+			maxLength = len(proof.Nodes[i])
+		}
+	}
+	key, keyLength := a.encodeIndex(txIndex)
+	exists, value, length := a.mpt.VerifyProofWithPath(receiptsRoot, key, keyLength, proof, maxLength)
+	a.api.AssertIsEqual(exists.Value, vars.ONE)
+	return vars.VariableBytes{Data: value, Length: length}
+}
+
+// Decodes the log at logIndex of the encoded receipt, i.e. of the list [status, cumulativeGasUsed,
+// logsBloom, logs]. Asserts that the receipt has more than logIndex logs, where logIndex must be
+// less than maxLogs, and that the data of the log is at most maxDataLength bytes.
+func (a *API) DecodeLog(receipt vars.VariableBytes, logIndex vars.Variable, maxLogs int, maxDataLength int) Log {
+	defer a.api.Scope("decode_log")()
+	data := receipt.Data
+
+	// A typed receipt starts with its type byte, which is less than 0x80, before the list.
+	typeBits := a.api.ToBitsFromByte(data[0])
+	list := a.rlp.DecodeItem(data, a.api.Not(typeBits[7]).Value)
+	a.api.AssertIsEqual(list.IsList.Value, vars.ONE)
+	a.api.AssertIsEqual(list.End, receipt.Length)
+	fields := a.rlp.DecodeItems(data, list, 4)
+	for i := 0; i < 3; i++ {
+		a.api.AssertIsEqual(fields[i].IsString.Value, vars.ONE)
+	}
+	logs := fields[3]
+	a.api.AssertIsEqual(logs.IsList.Value, vars.ONE)
+	a.api.AssertIsEqual(logs.End, list.End)
+
+	// The logs are decoded in sequence, so the selected log is within the list iff it ends
+	// before the end of the list.
+	items := a.rlp.DecodeItems(data, logs, maxLogs)
+	logSel := a.api.Selector(logIndex, maxLogs)
+	log := a.selectItem(items, logSel)
+	nbSelected := vars.ZERO
+	for i := range logSel {
+		nbSelected = a.api.Add(nbSelected, logSel[i])
+	}
+	a.api.AssertIsEqual(nbSelected, vars.ONE)
+	a.api.AssertIsEqual(log.IsList.Value, vars.ONE)
+	a.api.AssertIsLessOrEqual(log.End, logs.End)
+
+	// The log is the list [address, topics, data].
+	logFields := a.rlp.DecodeItems(data, log, 3)
+	a.api.AssertIsEqual(logFields[0].IsString.Value, vars.ONE)
+	a.api.AssertIsEqual(logFields[1].IsList.Value, vars.ONE)
+	a.api.AssertIsEqual(logFields[2].IsString.Value, vars.ONE)
+	a.api.AssertIsEqual(logFields[2].End, log.End)
+
+	var result Log
+	a.api.AssertIsEqual(logFields[0].Length, vars.NewVariableFromInt(20))
+	copy(result.Address[:], a.rlp.Content(data, logFields[0], 20))
+
+	// A topic is present iff the previous topics end before the end of the list, and the present
+	// topics must span the list exactly.
+	topics := a.rlp.DecodeItems(data, logFields[1], MaxTopics)
+	isPresent := a.api.Not(a.api.IsZero(logFields[1].Length)).Value
+	nbTopics := vars.ZERO
+	for i := range topics {
+		if i > 0 {
+			isLastEnd := a.api.IsZero(a.api.Sub(topics[i-1].End, logFields[1].End))
+			isPresent = a.api.Mul(isPresent, a.api.Not(isLastEnd).Value)
+		}
+		a.assertIf(isPresent, topics[i].IsString.Value, vars.ONE)
+		a.assertIf(isPresent, topics[i].Length, vars.NewVariableFromInt(32))
+		content := a.rlp.Content(data, topics[i], 32)
+		for j := range content {
+			result.Topics[i][j] = vars.Byte{Value: a.api.Mul(isPresent, content[j].Value)}
+		}
+		nbTopics = a.api.Add(nbTopics, isPresent)
+	}
+	a.api.AssertIsEqual(logFields[1].Length, a.api.Mul(nbTopics, vars.NewVariableFromInt(33)))
+	result.NbTopics = nbTopics
+
+	a.api.AssertIsLessOrEqual(logFields[2].Length, vars.NewVariableFromInt(maxDataLength))
+	result.Data = vars.VariableBytes{
+		Data:   a.rlp.Content(data, logFields[2], maxDataLength),
+		Length: logFields[2].Length,
+	}
+	return result
+}
+
+// Verifies the receipt of the transaction at txIndex and returns its log at logIndex, which must
+// have been emitted by the event with the given signature, i.e. its first topic is the signature.
+// See VerifyReceipt and DecodeLog.
+func (a *API) VerifyLog(
+	receiptsRoot [32]vars.Byte,
+	txIndex vars.Variable,
+	logIndex vars.Variable,
+	eventSignature [32]vars.Byte,
+	proof mpt.Proof,
+	maxLogs int,
+	maxDataLength int,
+) Log {
+	receipt := a.VerifyReceipt(receiptsRoot, txIndex, proof)
+	log := a.DecodeLog(receipt, logIndex, maxLogs, maxDataLength)
+	a.api.AssertIsEqual(a.api.IsZero(log.NbTopics).Value, vars.ZERO)
+	for i := range eventSignature {
+		a.api.AssertIsEqualByte(log.Topics[0][i], eventSignature[i])
+	}
+	return log
+}
+
+// Returns the key of the transaction at the index in the receipts trie, i.e. the RLP encoding of
+// the index padded to 3 bytes, along with its length. The index must be less than 2^16.
+func (a *API) encodeIndex(index vars.Variable) ([]vars.Byte, vars.Variable) {
+	bytes := a.api.ToBytesLE(index, 2)
+	lowBits := a.api.ToBitsFromByte(bytes[0])
+	isZero := a.api.IsZero(index)
+	isByte := a.api.IsZero(bytes[1].Value)
+	isSingle := a.api.And(isByte, a.api.Not(lowBits[7]))
+
+	// 0 is encoded as the empty string 0x80, 1-127 as a single byte and larger indices as a string
+	// of their 1 or 2 big-endian bytes.
+	first := a.api.Select(
+		isSingle,
+		a.api.Select(isZero, vars.NewVariableFromInt(0x80), bytes[0].Value),
+		a.api.Select(isByte, vars.NewVariableFromInt(0x81), vars.NewVariableFromInt(0x82)),
+	)
+	second := a.api.Select(isSingle, vars.ZERO, a.api.Select(isByte, bytes[0].Value, bytes[1].Value))
+	third := a.api.Select(isByte, vars.ZERO, bytes[0].Value)
+	length := a.api.Select(isSingle, vars.ONE, a.api.Select(isByte, vars.TWO, vars.THREE))
+	return []vars.Byte{{Value: first}, {Value: second}, {Value: third}}, length
+}
+
+// Returns the item selected by the one-hot selector, or the zero item if no selector is set.
+func (a *API) selectItem(items []rlp.Item, sel []vars.Variable) rlp.Item {
+	result := rlp.Item{
+		Prefix:   vars.ZERO,
+		Offset:   vars.ZERO,
+		Length:   vars.ZERO,
+		End:      vars.ZERO,
+		IsString: vars.FALSE,
+		IsList:   vars.FALSE,
+	}
+	for i := range items {
+		result.Prefix = a.api.Add(result.Prefix, a.api.Mul(sel[i], items[i].Prefix))
+		result.Offset = a.api.Add(result.Offset, a.api.Mul(sel[i], items[i].Offset))
+		result.Length = a.api.Add(result.Length, a.api.Mul(sel[i], items[i].Length))
+		result.End = a.api.Add(result.End, a.api.Mul(sel[i], items[i].End))
+		result.IsString.Value = a.api.Add(result.IsString.Value, a.api.Mul(sel[i], items[i].IsString.Value))
+		result.IsList.Value = a.api.Add(result.IsList.Value, a.api.Mul(sel[i], items[i].IsList.Value))
+	}
+	return result
+}
+
+// Asserts that x == y if the condition is set.
+func (a *API) assertIf(condition vars.Variable, x, y vars.Variable) {
+	a.api.AssertIsEqual(a.api.Mul(condition, a.api.Sub(x, y)), vars.ZERO)
+}
@@ -0,0 +1,185 @@
+package receipt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/mpt"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const (
+	testMaxDepth         = 5
+	testMaxReceiptLength = 640
+	testMaxLogs          = 3
+	testMaxDataLength    = 64
+)
+
+var transferSignature = "Transfer(address,address,uint256)"
+
+// Collects the nodes of a proof in the order in which they are written, i.e. from the root.
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// Returns the receipts of a block with 130 transactions, so that the keys of the receipts trie
+// cover encoded indices of 1 and 2 bytes. Some of them emit transfer events.
+func newTestReceipts() types.Receipts {
+	receipts := make(types.Receipts, 130)
+	for i := range receipts {
+		receipts[i] = &types.Receipt{
+			Type:              uint8(i % 3),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs:              []*types.Log{},
+		}
+	}
+	transfer := common.Hash(EventSignature(transferSignature))
+	for _, c := range [][2]int{{0, 1}, {5, 1}, {7, 2}, {129, 1}} {
+		i := c[0]
+		for j := 0; j < c[1]; j++ {
+			amount := common.BigToHash(big.NewInt(int64(1000*i + j)))
+			receipts[i].Logs = append(receipts[i].Logs, &types.Log{
+				Address: common.BytesToAddress([]byte{byte(i), byte(j), 0xaa}),
+				Topics: []common.Hash{
+					transfer,
+					common.BytesToHash([]byte{byte(i)}),
+					common.BytesToHash([]byte{byte(j)}),
+				},
+				Data: amount.Bytes(),
+			})
+		}
+	}
+	// A log of another event without indexed parameters and with longer data.
+	receipts[5].Logs = append(receipts[5].Logs, &types.Log{
+		Address: common.BytesToAddress([]byte{0xbb}),
+		Topics:  []common.Hash{common.BytesToHash([]byte{0x01})},
+		Data:    make([]byte, 60),
+	})
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+	return receipts
+}
+
+// Builds the receipts trie of the receipts and returns its root and a proof for the receipt at
+// the index.
+func newTestProof(t *testing.T, receipts types.Receipts, index int) (common.Hash, [][]byte) {
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i := range receipts {
+		key, _ := rlp.EncodeToBytes(uint64(i))
+		value, err := receipts[i].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tr.Update(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key, _ := rlp.EncodeToBytes(uint64(index))
+	var proof proofList
+	if err := tr.Prove(key, 0, &proof); err != nil {
+		t.Fatal(err)
+	}
+	return tr.Hash(), proof
+}
+
+type TestLogCircuit struct {
+	Root     [32]vars.Byte
+	TxIndex  vars.Variable
+	LogIndex vars.Variable
+	Proof    mpt.Proof
+	Address  [20]vars.Byte
+	Topics   [MaxTopics][32]vars.Byte
+	NbTopics vars.Variable
+	Data     vars.VariableBytes
+}
+
+func (circuit *TestLogCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	receiptAPI := NewAPI(succinctAPI)
+	var signature [32]vars.Byte
+	vars.SetBytes32(&signature, EventSignature(transferSignature))
+	log := receiptAPI.VerifyLog(circuit.Root, circuit.TxIndex, circuit.LogIndex, signature, circuit.Proof, testMaxLogs, testMaxDataLength)
+	for i := range circuit.Address {
+		succinctAPI.AssertIsEqualByte(log.Address[i], circuit.Address[i])
+	}
+	for i := range circuit.Topics {
+		for j := range circuit.Topics[i] {
+			succinctAPI.AssertIsEqualByte(log.Topics[i][j], circuit.Topics[i][j])
+		}
+	}
+	succinctAPI.AssertIsEqual(log.NbTopics, circuit.NbTopics)
+	succinctAPI.AssertIsEqualVariableBytes(log.Data, circuit.Data)
+	return nil
+}
+
+func newTestLogCircuit(t *testing.T, receipts types.Receipts, txIndex int, logIndex int) *TestLogCircuit {
+	root, nodes := newTestProof(t, receipts, txIndex)
+	log := receipts[txIndex].Logs[logIndex]
+	circuit := &TestLogCircuit{
+		TxIndex:  vars.NewVariableFromInt(txIndex),
+		LogIndex: vars.NewVariableFromInt(logIndex),
+		Proof:    NewProof(testMaxDepth, testMaxReceiptLength),
+		NbTopics: vars.NewVariableFromInt(len(log.Topics)),
+		Data:     vars.NewVariableBytesFrom(log.Data, testMaxDataLength),
+	}
+	vars.SetBytes32(&circuit.Root, root)
+	circuit.Proof.Set(nodes)
+	copy(circuit.Address[:], vars.NewBytesFrom(log.Address.Bytes()))
+	for i := range circuit.Topics {
+		var topic [32]byte
+		if i < len(log.Topics) {
+			topic = log.Topics[i]
+		}
+		vars.SetBytes32(&circuit.Topics[i], topic)
+	}
+	return circuit
+}
+
+func TestVerifyLog(t *testing.T) {
+	assert := test.NewAssert(t)
+	receipts := newTestReceipts()
+
+	// The receipts trie agrees with the receipts root of a block.
+	root, _ := newTestProof(t, receipts, 0)
+	assert.Equal(types.DeriveSha(receipts, trie.NewStackTrie(nil)), root)
+
+	// Legacy, access list and dynamic fee receipts at indices encoded in 1 and 2 bytes.
+	for _, c := range [][2]int{{0, 0}, {5, 0}, {7, 1}, {129, 0}} {
+		circuit := newTestLogCircuit(t, receipts, c[0], c[1])
+		witness := newTestLogCircuit(t, receipts, c[0], c[1])
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "receipt %d, log %d", c[0], c[1])
+	}
+
+	circuit := newTestLogCircuit(t, receipts, 0, 0)
+
+	// A log of another event is rejected.
+	assert.Error(test.IsSolved(circuit, newTestLogCircuit(t, receipts, 5, 1), ecc.BN254.ScalarField()))
+
+	// A log past the end of the logs is rejected.
+	witness := newTestLogCircuit(t, receipts, 7, 1)
+	witness.LogIndex = vars.NewVariableFromInt(2)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// A receipt proven for another transaction is rejected.
+	witness = newTestLogCircuit(t, receipts, 5, 0)
+	witness.TxIndex = vars.NewVariableFromInt(6)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
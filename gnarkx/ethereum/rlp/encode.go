@@ -0,0 +1,96 @@
+package rlp
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Encodes the first length bytes of in as a string, where length is in [0, len(in)]. Returns the
+// encoding padded with zeros to len(in) + 3 bytes and its length.
+func (a *API) EncodeString(in []vars.Byte, length vars.Variable) ([]vars.Byte, vars.Variable) {
+	// A single byte below 0x80 is its own encoding.
+	isSingle := vars.FALSE
+	if len(in) > 0 {
+		bits := a.api.ToBitsFromByte(in[0])
+		isSingle = a.api.And(a.isEqual(length, 1), a.api.Not(bits[7]))
+	}
+	header, headerLength := a.encodeHeader(length, 0x80, isSingle)
+	return a.concat([][]vars.Byte{header, in}, []vars.Variable{headerLength, length})
+}
+
+// Encodes a list of items, which are given by their encodings and their lengths. Returns the
+// encoding padded with zeros to the sum of the maximum lengths of the items plus 3 bytes, and its
+// length.
+func (a *API) EncodeList(items [][]vars.Byte, lengths []vars.Variable) ([]vars.Byte, vars.Variable) {
+	payload, length := a.concat(items, lengths)
+	header, headerLength := a.encodeHeader(length, 0xc0, vars.FALSE)
+	return a.concat([][]vars.Byte{header, payload}, []vars.Variable{headerLength, length})
+}
+
+// Returns the header of a string or list of the given length padded to 3 bytes along with its
+// length, where base is 0x80 for strings and 0xc0 for lists. If isSingle is set, the header is
+// empty.
+func (a *API) encodeHeader(length vars.Variable, base int, isSingle vars.Bool) ([]vars.Byte, vars.Variable) {
+	// The bits of length also range check it to [0, 2^16).
+	bits := a.api.ToBinaryLE(length, 16)
+	var lowBits, highBits [8]vars.Bool
+	copy(lowBits[:], bits[:8])
+	copy(highBits[:], bits[8:])
+	low := a.api.ToByteFromBits(lowBits).Value
+	high := a.api.ToByteFromBits(highBits).Value
+
+	// length < 56 iff length + 2^16 - 56 < 2^16.
+	offsetBits := a.api.ToBinaryLE(a.api.Add(length, vars.NewVariableFromInt(1<<16-56)), 17)
+	isShort := a.api.And(a.api.Not(isSingle), a.api.Not(offsetBits[16]))
+	isLong2 := a.api.Not(a.api.IsZero(high))
+	isLong1 := a.api.And(a.api.Not(isSingle), a.api.And(a.api.Not(isShort), a.api.Not(isLong2)))
+
+	header := []vars.Byte{
+		{Value: a.api.Add(
+			a.api.Mul(isShort.Value, a.api.Add(length, vars.NewVariableFromInt(base))),
+			a.api.Mul(isLong1.Value, vars.NewVariableFromInt(base+0x38)),
+			a.api.Mul(isLong2.Value, vars.NewVariableFromInt(base+0x39)),
+		)},
+		{Value: a.api.Add(a.api.Mul(isLong1.Value, low), a.api.Mul(isLong2.Value, high))},
+		{Value: a.api.Mul(isLong2.Value, low)},
+	}
+	headerLength := a.api.Add(isShort.Value, a.api.Mul(isLong1.Value, vars.TWO), a.api.Mul(isLong2.Value, vars.THREE))
+	return header, headerLength
+}
+
+// Concatenates the first lengths[i] bytes of each part, where lengths[i] is in [0, len(parts[i])].
+// Returns the result padded with zeros to the sum of the lengths of the parts and its length.
+func (a *API) concat(parts [][]vars.Byte, lengths []vars.Variable) ([]vars.Byte, vars.Variable) {
+	total := 0
+	for i := range parts {
+		total += len(parts[i])
+	}
+	result := make([]vars.Byte, total)
+	for i := range result {
+		result[i] = vars.ZERO_BYTE
+	}
+
+	offset := vars.ZERO
+	for i, part := range parts {
+		// Zero the bytes after the length, so that they do not overlap with the next part.
+		a.api.AssertIsLessOrEqual(lengths[i], vars.NewVariableFromInt(len(part)))
+		endSel := a.api.Selector(lengths[i], len(part))
+		masked := make([]vars.Byte, len(part))
+		isContent := vars.ONE
+		for j := range part {
+			isContent = a.api.Sub(isContent, endSel[j])
+			masked[j] = vars.Byte{Value: a.api.Mul(isContent, part[j].Value)}
+		}
+
+		// result[j] += masked[j - offset].
+		sel := a.api.Selector(offset, total)
+		for j := range result {
+			value := result[j].Value
+			for k := 0; k <= j && k < len(masked); k++ {
+				value = a.api.Add(value, a.api.Mul(sel[j-k], masked[k].Value))
+			}
+			result[j] = vars.Byte{Value: value}
+		}
+		offset = a.api.Add(offset, lengths[i])
+	}
+	return result, offset
+}
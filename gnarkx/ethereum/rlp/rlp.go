@@ -0,0 +1,159 @@
+// The API for RLP, the serialization method used by the Ethereum execution layer for block
+// headers, transactions, receipts and trie nodes. See
+// https://ethereum.org/en/developers/docs/data-structures-and-encoding/rlp/.
+//
+// The encodings are byte arrays whose maximum length is a constant at compile time of the
+// circuit, while the actual lengths and the offsets of the items are variables. Only lengths of
+// less than 2^16 bytes are supported, which covers every encoding that fits into a circuit.
+package rlp
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An item of an encoding, whose content is data[Offset:Offset + Length].
+type Item struct {
+	// The first byte of the item.
+	Prefix vars.Variable
+	// The offset and the length of the content, i.e. of the payload of a list.
+	Offset vars.Variable
+	Length vars.Variable
+	// The offset of the byte after the item.
+	End vars.Variable
+	// Set iff the item is a string or a list, respectively. At most one of them is set, and neither
+	// is set if the length of the item is not supported.
+	IsString vars.Bool
+	IsList   vars.Bool
+}
+
+// An API used for operations related to RLP.
+type API struct {
+	api builder.API
+}
+
+// Creates a new rlp.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api}
+}
+
+// Decodes the item starting at the offset of data. Bytes out of range are treated as zeros.
+func (a *API) DecodeItem(data []vars.Byte, offset vars.Variable) Item {
+	sel := a.api.Selector(offset, len(data))
+	prefix, b1, b2 := a.lookup(data, sel, 0), a.lookup(data, sel, 1), a.lookup(data, sel, 2)
+	bits := a.api.ToBitsFromByte(vars.Byte{Value: prefix})
+
+	// 0x00-0x7f is a single byte, 0x80-0xb7 a string of up to 55 bytes and 0xb8-0xb9 a longer
+	// string with a 1 or 2 byte length. The same holds for lists starting at 0xc0.
+	isSingle := a.api.Not(bits[7])
+	isLong := a.api.And(bits[5], a.api.And(bits[4], bits[3]))
+	isShortString := a.api.And(bits[7], a.api.And(a.api.Not(bits[6]), a.api.Not(isLong)))
+	isShortList := a.api.And(bits[7], a.api.And(bits[6], a.api.Not(isLong)))
+	isLong1 := a.api.Or(a.isEqual(prefix, 0xb8), a.isEqual(prefix, 0xf8))
+	isLong2 := a.api.Or(a.isEqual(prefix, 0xb9), a.isEqual(prefix, 0xf9))
+	isShort := a.api.Add(isShortString.Value, isShortList.Value)
+
+	// The base of the prefix, i.e. 0x80 for strings and 0xc0 for lists.
+	base := a.api.Select(bits[6], vars.NewVariableFromInt(0xc0), vars.NewVariableFromInt(0x80))
+	headerLength := a.api.Add(isShort, a.api.Mul(isLong1.Value, vars.TWO), a.api.Mul(isLong2.Value, vars.THREE))
+	length := a.api.Add(
+		isSingle.Value,
+		a.api.Mul(isShort, a.api.Sub(prefix, base)),
+		a.api.Mul(isLong1.Value, b1),
+		a.api.Mul(isLong2.Value, a.api.Add(a.api.Mul(b1, vars.NewVariableFromInt(256)), b2)),
+	)
+	isLongList := a.api.And(bits[6], a.api.Or(isLong1, isLong2))
+	isList := a.api.Or(isShortList, isLongList)
+	isString := a.api.Sub(a.api.Add(isSingle.Value, isShort, isLong1.Value, isLong2.Value), isList.Value)
+
+	contentOffset := a.api.Add(offset, headerLength)
+	return Item{
+		Prefix:   prefix,
+		Offset:   contentOffset,
+		Length:   length,
+		End:      a.api.Add(contentOffset, length),
+		IsString: vars.Bool{Value: isString},
+		IsList:   isList,
+	}
+}
+
+// Decodes the list of nbItems items which is encoded in the first length bytes of data. Asserts
+// that the encoding is a list of exactly nbItems items spanning length bytes, where each item is
+// either a string or a list.
+func (a *API) DecodeList(data []vars.Byte, length vars.Variable, nbItems int) []Item {
+	list := a.DecodeItem(data, vars.ZERO)
+	a.api.AssertIsEqual(list.IsList.Value, vars.ONE)
+	a.api.AssertIsEqual(list.End, length)
+	items := a.DecodeItems(data, list, nbItems)
+	for i := range items {
+		a.api.AssertIsEqual(a.api.Add(items[i].IsString.Value, items[i].IsList.Value), vars.ONE)
+	}
+	if nbItems > 0 {
+		a.api.AssertIsEqual(items[nbItems-1].End, list.End)
+	} else {
+		a.api.AssertIsEqual(list.Length, vars.ZERO)
+	}
+	return items
+}
+
+// Decodes the first nbItems items of the payload of the list without any assertions, so the
+// items past the end of the list or the item types must be checked by the caller.
+func (a *API) DecodeItems(data []vars.Byte, list Item, nbItems int) []Item {
+	items := make([]Item, nbItems)
+	offset := list.Offset
+	for i := range items {
+		items[i] = a.DecodeItem(data, offset)
+		offset = items[i].End
+	}
+	return items
+}
+
+// Returns the content of the item padded with zeros to maxLength bytes. Note that the content is
+// truncated if it is longer than maxLength, so the caller must check the length if needed.
+func (a *API) Content(data []vars.Byte, item Item, maxLength int) []vars.Byte {
+	sel := a.api.Selector(item.Offset, len(data))
+	endSel := a.api.Selector(item.Length, maxLength)
+	result := make([]vars.Byte, maxLength)
+	isContent := vars.ONE
+	for i := 0; i < maxLength; i++ {
+		isContent = a.api.Sub(isContent, endSel[i])
+		result[i] = vars.Byte{Value: a.api.Mul(isContent, a.lookup(data, sel, i))}
+	}
+	return result
+}
+
+// Returns the content of the item as a big-endian integer padded to 32 bytes. The length of the
+// content must be at most 32.
+func (a *API) ContentBytes32(data []vars.Byte, item Item) [32]vars.Byte {
+	a.api.AssertIsLessOrEqual(item.Length, vars.NewVariableFromInt(32))
+
+	// Mask out the bytes before the offset, so that they are not included for short integers.
+	startSel := a.api.Selector(item.Offset, len(data))
+	masked := make([]vars.Byte, len(data))
+	isAfterStart := vars.ZERO
+	for i := range data {
+		isAfterStart = a.api.Add(isAfterStart, startSel[i])
+		masked[i] = vars.Byte{Value: a.api.Mul(isAfterStart, data[i].Value)}
+	}
+	endSel := a.api.Selector(item.End, len(data)+1)
+	var result [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		result[i] = vars.Byte{Value: a.lookup(masked, endSel, i-32)}
+	}
+	return result
+}
+
+// Returns data[index + shift], where index is given by its selector, or zero if it is out of range.
+func (a *API) lookup(data []vars.Byte, sel []vars.Variable, shift int) vars.Variable {
+	result := vars.ZERO
+	for i := range sel {
+		if j := i + shift; j >= 0 && j < len(data) {
+			result = a.api.Add(result, a.api.Mul(sel[i], data[j].Value))
+		}
+	}
+	return result
+}
+
+func (a *API) isEqual(x vars.Variable, c int) vars.Bool {
+	return a.api.IsZero(a.api.Sub(x, vars.NewVariableFromInt(c)))
+}
@@ -0,0 +1,162 @@
+package rlp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethrlp "github.com/ethereum/go-ethereum/rlp"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const testMaxHeaderLength = 640
+
+// Returns the bytes padded with zeros to n bytes.
+func newPaddedBytes(in []byte, n int) []vars.Byte {
+	padded := make([]byte, n)
+	copy(padded, in)
+	return vars.NewBytesFrom(padded)
+}
+
+type TestDecodeHeaderCircuit struct {
+	Header     []vars.Byte
+	Length     vars.Variable
+	ParentHash [32]vars.Byte
+	Number     [32]vars.Byte
+	Extra      []vars.Byte
+	ExtraSize  vars.Variable
+}
+
+func (circuit *TestDecodeHeaderCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	rlp := NewAPI(succinctAPI)
+	items := rlp.DecodeList(circuit.Header, circuit.Length, 16)
+	parentHash := rlp.ContentBytes32(circuit.Header, items[0])
+	number := rlp.ContentBytes32(circuit.Header, items[8])
+	extra := rlp.Content(circuit.Header, items[12], len(circuit.Extra))
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqualByte(parentHash[i], circuit.ParentHash[i])
+		succinctAPI.AssertIsEqualByte(number[i], circuit.Number[i])
+	}
+	for i := range extra {
+		succinctAPI.AssertIsEqualByte(extra[i], circuit.Extra[i])
+	}
+	succinctAPI.AssertIsEqual(items[12].Length, circuit.ExtraSize)
+	return nil
+}
+
+func TestDecodeHeader(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	header := types.Header{
+		ParentHash: common.HexToHash("0x9b83c12c69edb74f6c8dd5d052765c1adf940e320bd1291696e6fa07829eee71"),
+		Coinbase:   common.HexToAddress("0x95222290dd7278aa3ddd389cc1e1d165cc4bafe5"),
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(17034870),
+		GasLimit:   30000000,
+		GasUsed:    12345678,
+		Time:       1681338455,
+		Extra:      []byte("beaverbuild.org"),
+		BaseFee:    big.NewInt(24000000000),
+	}
+	encoded, err := gethrlp.EncodeToBytes(&header)
+	assert.NoError(err)
+
+	newCircuit := func() *TestDecodeHeaderCircuit {
+		var parentHash, number [32]vars.Byte
+		vars.SetBytes32(&parentHash, header.ParentHash)
+		vars.SetBytes32(&number, common.BigToHash(header.Number))
+		return &TestDecodeHeaderCircuit{
+			Header:     newPaddedBytes(encoded, testMaxHeaderLength),
+			Length:     vars.NewVariableFromInt(len(encoded)),
+			ParentHash: parentHash,
+			Number:     number,
+			Extra:      newPaddedBytes(header.Extra, 32),
+			ExtraSize:  vars.NewVariableFromInt(len(header.Extra)),
+		}
+	}
+
+	circuit := newCircuit()
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The list must span exactly the given length.
+	circuit = newCircuit()
+	circuit.Length = vars.NewVariableFromInt(len(encoded) + 1)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A wrong field is rejected.
+	circuit = newCircuit()
+	circuit.Number[31] = vars.Byte{Value: vars.NewVariableFromInt(0)}
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestEncodeCircuit struct {
+	In               []vars.Byte
+	Length           vars.Variable
+	Expected         []vars.Byte
+	ExpectedLength   vars.Variable
+	ExpectedList     []vars.Byte
+	ExpectedListSize vars.Variable
+}
+
+func (circuit *TestEncodeCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	rlp := NewAPI(succinctAPI)
+	encoded, length := rlp.EncodeString(circuit.In, circuit.Length)
+	for i := range encoded {
+		succinctAPI.AssertIsEqualByte(encoded[i], circuit.Expected[i])
+	}
+	succinctAPI.AssertIsEqual(length, circuit.ExpectedLength)
+
+	// The list [in, in].
+	list, listLength := rlp.EncodeList([][]vars.Byte{encoded, encoded}, []vars.Variable{length, length})
+	for i := range list {
+		succinctAPI.AssertIsEqualByte(list[i], circuit.ExpectedList[i])
+	}
+	succinctAPI.AssertIsEqual(listLength, circuit.ExpectedListSize)
+	return nil
+}
+
+func TestEncode(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const maxLength = 300
+	newCircuit := func(in []byte) *TestEncodeCircuit {
+		expected, err := gethrlp.EncodeToBytes(in)
+		assert.NoError(err)
+		expectedList, err := gethrlp.EncodeToBytes([][]byte{in, in})
+		assert.NoError(err)
+		return &TestEncodeCircuit{
+			In:               newPaddedBytes(in, maxLength),
+			Length:           vars.NewVariableFromInt(len(in)),
+			Expected:         newPaddedBytes(expected, maxLength+3),
+			ExpectedLength:   vars.NewVariableFromInt(len(expected)),
+			ExpectedList:     newPaddedBytes(expectedList, 2*(maxLength+3)+3),
+			ExpectedListSize: vars.NewVariableFromInt(len(expectedList)),
+		}
+	}
+
+	// The empty string, single bytes below and above 0x80 and the boundaries of the header sizes.
+	inputs := [][]byte{{}, {0x10}, {0x90}}
+	for _, n := range []int{55, 56, 255, 256, 300} {
+		in := make([]byte, n)
+		for i := range in {
+			in[i] = byte(i*7 + 1)
+		}
+		inputs = append(inputs, in)
+	}
+	for _, in := range inputs {
+		circuit := newCircuit(in)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A wrong length is rejected.
+	circuit := newCircuit([]byte{1, 2, 3})
+	circuit.ExpectedLength = vars.NewVariableFromInt(5)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
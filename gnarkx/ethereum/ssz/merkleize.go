@@ -0,0 +1,177 @@
+package ssz
+
+import (
+	gosha256 "crypto/sha256"
+
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The chunk size of SSZ, i.e. the size of a leaf of the Merkle trees.
+const BytesPerChunk = 32
+
+// The maximum depth of a Merkle tree, which is enough for any limit of a list.
+const maxDepth = 64
+
+// The roots of the Merkle trees of each depth whose leaves are all zero. They are constants, so
+// padding a tree to its limit does not require any hashes in the circuit.
+var zeroHashes [maxDepth + 1][32]byte
+
+func init() {
+	for i := 1; i <= maxDepth; i++ {
+		zeroHashes[i] = gosha256.Sum256(append(zeroHashes[i-1][:], zeroHashes[i-1][:]...))
+	}
+}
+
+// Returns the root of the Merkle tree of the given depth whose leaves are all zero.
+func zeroHash(depth int) [32]vars.Byte {
+	var result [32]vars.Byte
+	vars.SetBytes32(&result, zeroHashes[depth])
+	return result
+}
+
+// Returns the depth of a Merkle tree with the given number of leaves, i.e. ceil(log2(nbLeaves)).
+func depthOf(nbLeaves int) int {
+	depth := 0
+	for 1<<depth < nbLeaves {
+		depth++
+	}
+	return depth
+}
+
+// Computes the root of the Merkle tree of the chunks, which is padded with zero chunks to the next
+// power of two of the limit. The limit is a compile-time constant and must be at least the number
+// of chunks.
+func (a *SimpleSerializeAPI) Merkleize(chunks [][32]vars.Byte, limit int) [32]vars.Byte {
+	if len(chunks) > limit {
+		panic("number of chunks exceeds the limit")
+	}
+	depth := depthOf(limit)
+	if depth > maxDepth {
+		panic("limit exceeds the maximum depth")
+	}
+	if len(chunks) == 0 {
+		return zeroHash(depth)
+	}
+
+	layer := make([][32]vars.Byte, len(chunks))
+	copy(layer, chunks)
+	for i := 0; i < depth; i++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHash(i))
+		}
+		next := make([][32]vars.Byte, len(layer)/2)
+		for j := range next {
+			next[j] = sha256.Hash(a.api, append(layer[2*j][:], layer[2*j+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// Computes hash(root + length), where the length is serialized as a little-endian uint256. This
+// is how the length of a list is committed to.
+func (a *SimpleSerializeAPI) MixInLength(root [32]vars.Byte, length vars.Variable) [32]vars.Byte {
+	serialized := a.api.ToBytes32FromU64LE(vars.U64{Value: length})
+	return sha256.Hash(a.api, append(root[:], serialized[:]...))
+}
+
+// Packs the serialized values into chunks, where the last chunk is right-padded with zeros.
+func (a *SimpleSerializeAPI) Pack(data []vars.Byte) [][32]vars.Byte {
+	chunks := make([][32]vars.Byte, (len(data)+BytesPerChunk-1)/BytesPerChunk)
+	for i := range chunks {
+		for j := 0; j < BytesPerChunk; j++ {
+			if k := i*BytesPerChunk + j; k < len(data) {
+				chunks[i][j] = data[k]
+			} else {
+				chunks[i][j] = vars.ZERO_BYTE
+			}
+		}
+	}
+	return chunks
+}
+
+// Computes the hash tree root of a uint64.
+func (a *SimpleSerializeAPI) HashTreeRootUint64(i1 vars.U64) [32]vars.Byte {
+	return a.api.ToBytes32FromU64LE(i1)
+}
+
+// Computes the hash tree root of a container given the hash tree roots of its fields.
+func (a *SimpleSerializeAPI) HashTreeRootContainer(fields [][32]vars.Byte) [32]vars.Byte {
+	return a.Merkleize(fields, len(fields))
+}
+
+// Computes the hash tree root of a vector given the hash tree roots of its elements, which must be
+// of a composite type. A vector of basic types is merkleized from its packed serialization, see
+// HashTreeRootByteVector.
+func (a *SimpleSerializeAPI) HashTreeRootVector(elements [][32]vars.Byte) [32]vars.Byte {
+	return a.Merkleize(elements, len(elements))
+}
+
+// Computes the hash tree root of a list given the hash tree roots of its elements, which must be
+// of a composite type. Only the first length elements belong to the list, where the length is in
+// [0, len(elements)] and len(elements) is at most the limit.
+func (a *SimpleSerializeAPI) HashTreeRootList(elements [][32]vars.Byte, length vars.Variable, limit int) [32]vars.Byte {
+	isElement := a.api.PrefixMask(length, len(elements))
+	chunks := make([][32]vars.Byte, len(elements))
+	for i := range elements {
+		for j := 0; j < 32; j++ {
+			chunks[i][j] = vars.Byte{Value: a.api.Mul(isElement[i], elements[i][j].Value)}
+		}
+	}
+	return a.MixInLength(a.Merkleize(chunks, limit), length)
+}
+
+// Computes the hash tree root of a vector of bytes, e.g. a ByteVector or the serialization of a
+// vector of any other basic type.
+func (a *SimpleSerializeAPI) HashTreeRootByteVector(data []vars.Byte) [32]vars.Byte {
+	chunks := a.Pack(data)
+	return a.Merkleize(chunks, len(chunks))
+}
+
+// Computes the hash tree root of a ByteList with the given limit. Only the first length bytes
+// belong to the list, where the length is in [0, len(data)] and len(data) is at most the limit.
+func (a *SimpleSerializeAPI) HashTreeRootByteList(data []vars.Byte, length vars.Variable, limit int) [32]vars.Byte {
+	isElement := a.api.PrefixMask(length, len(data))
+	masked := make([]vars.Byte, len(data))
+	for i := range data {
+		masked[i] = vars.Byte{Value: a.api.Mul(isElement[i], data[i].Value)}
+	}
+	root := a.Merkleize(a.Pack(masked), (limit+BytesPerChunk-1)/BytesPerChunk)
+	return a.MixInLength(root, length)
+}
+
+// Computes the hash tree root of a Bitvector.
+func (a *SimpleSerializeAPI) HashTreeRootBitvector(bits []vars.Bool) [32]vars.Byte {
+	chunks := a.Pack(a.packBits(bits))
+	return a.Merkleize(chunks, (len(bits)+255)/256)
+}
+
+// Computes the hash tree root of a Bitlist with the given limit. Only the first length bits belong
+// to the list, where the length is in [0, len(bits)] and len(bits) is at most the limit.
+func (a *SimpleSerializeAPI) HashTreeRootBitlist(bits []vars.Bool, length vars.Variable, limit int) [32]vars.Byte {
+	isElement := a.api.PrefixMask(length, len(bits))
+	masked := make([]vars.Bool, len(bits))
+	for i := range bits {
+		masked[i] = vars.Bool{Value: a.api.Mul(isElement[i], bits[i].Value)}
+	}
+	root := a.Merkleize(a.Pack(a.packBits(masked)), (limit+255)/256)
+	return a.MixInLength(root, length)
+}
+
+// Packs the bits into bytes in little-endian order, where the last byte is padded with zeros.
+func (a *SimpleSerializeAPI) packBits(bits []vars.Bool) []vars.Byte {
+	result := make([]vars.Byte, (len(bits)+7)/8)
+	for i := range result {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			if k := 8*i + j; k < len(bits) {
+				byteBits[j] = bits[k]
+			} else {
+				byteBits[j] = vars.FALSE
+			}
+		}
+		result[i] = a.api.ToByteFromBits(byteBits)
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+package ssz_test
+
+import (
+	gosha256 "crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/ethereum/ssz"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A straightforward implementation of merkleize from the consensus specs.
+func merkleize(chunks [][32]byte, limit int) [32]byte {
+	size := 1
+	for size < limit {
+		size *= 2
+	}
+	layer := make([][32]byte, size)
+	copy(layer, chunks)
+	for len(layer) > 1 {
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = gosha256.Sum256(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+func mixInLength(root [32]byte, length int) [32]byte {
+	var serialized [32]byte
+	binary.LittleEndian.PutUint64(serialized[:], uint64(length))
+	return gosha256.Sum256(append(root[:], serialized[:]...))
+}
+
+func pack(data []byte) [][32]byte {
+	chunks := make([][32]byte, (len(data)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], data[32*i:])
+	}
+	return chunks
+}
+
+func packBits(bits []bool) []byte {
+	result := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			result[i/8] |= 1 << (i % 8)
+		}
+	}
+	return result
+}
+
+func newTestBytes(n int, seed byte) []byte {
+	result := make([]byte, n)
+	for i := range result {
+		result[i] = byte(i)*31 + seed
+	}
+	return result
+}
+
+func newTestBits(n int) []bool {
+	result := make([]bool, n)
+	for i := range result {
+		result[i] = i%3 == 0 || i%7 == 0
+	}
+	return result
+}
+
+const (
+	testByteListLimit = 100
+	testListLimit     = 16
+	testBitlistLimit  = 2048
+)
+
+type TestHashTreeRootCircuit struct {
+	// A BeaconBlockHeader.
+	Slot          vars.U64
+	ProposerIndex vars.U64
+	ParentRoot    [32]vars.Byte
+	StateRoot     [32]vars.Byte
+	BodyRoot      [32]vars.Byte
+	HeaderRoot    [32]vars.Byte
+
+	ByteList       []vars.Byte
+	ByteListLength vars.Variable
+	ByteListRoot   [32]vars.Byte
+
+	List       [][32]vars.Byte
+	ListLength vars.Variable
+	ListRoot   [32]vars.Byte
+
+	Bitvector     []vars.Bool
+	BitvectorRoot [32]vars.Byte
+
+	Bitlist       []vars.Bool
+	BitlistLength vars.Variable
+	BitlistRoot   [32]vars.Byte
+}
+
+func (circuit *TestHashTreeRootCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sszAPI := ssz.NewAPI(succinctAPI)
+	assertIsEqual := func(x, y [32]vars.Byte) {
+		for i := 0; i < 32; i++ {
+			succinctAPI.AssertIsEqualByte(x[i], y[i])
+		}
+	}
+
+	headerRoot := sszAPI.HashTreeRootContainer([][32]vars.Byte{
+		sszAPI.HashTreeRootUint64(circuit.Slot),
+		sszAPI.HashTreeRootUint64(circuit.ProposerIndex),
+		circuit.ParentRoot,
+		circuit.StateRoot,
+		circuit.BodyRoot,
+	})
+	assertIsEqual(headerRoot, circuit.HeaderRoot)
+	assertIsEqual(sszAPI.HashTreeRootByteList(circuit.ByteList, circuit.ByteListLength, testByteListLimit), circuit.ByteListRoot)
+	assertIsEqual(sszAPI.HashTreeRootList(circuit.List, circuit.ListLength, testListLimit), circuit.ListRoot)
+	assertIsEqual(sszAPI.HashTreeRootBitvector(circuit.Bitvector), circuit.BitvectorRoot)
+	assertIsEqual(sszAPI.HashTreeRootBitlist(circuit.Bitlist, circuit.BitlistLength, testBitlistLimit), circuit.BitlistRoot)
+	return nil
+}
+
+func newBytes32(in [32]byte) [32]vars.Byte {
+	var result [32]vars.Byte
+	vars.SetBytes32(&result, in)
+	return result
+}
+
+func newBools(in []bool) []vars.Bool {
+	result := make([]vars.Bool, len(in))
+	for i := range in {
+		result[i] = vars.NewBool(in[i])
+	}
+	return result
+}
+
+func TestHashTreeRoot(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var parentRoot, stateRoot, bodyRoot, slot, proposerIndex [32]byte
+	copy(parentRoot[:], newTestBytes(32, 1))
+	copy(stateRoot[:], newTestBytes(32, 2))
+	copy(bodyRoot[:], newTestBytes(32, 3))
+	binary.LittleEndian.PutUint64(slot[:], 7294931)
+	binary.LittleEndian.PutUint64(proposerIndex[:], 484410)
+	headerRoot := merkleize([][32]byte{slot, proposerIndex, parentRoot, stateRoot, bodyRoot}, 5)
+
+	// Only a prefix of the padded byte list, list and bitlist belongs to them.
+	byteList := newTestBytes(64, 4)
+	byteListLength := 40
+	byteListRoot := mixInLength(merkleize(pack(byteList[:byteListLength]), (testByteListLimit+31)/32), byteListLength)
+
+	list := make([][32]byte, 5)
+	for i := range list {
+		copy(list[i][:], newTestBytes(32, byte(10+i)))
+	}
+	listLength := 3
+	listRoot := mixInLength(merkleize(list[:listLength], testListLimit), listLength)
+
+	bitvector := newTestBits(300)
+	bitvectorRoot := merkleize(pack(packBits(bitvector)), 2)
+
+	bitlist := newTestBits(300)
+	bitlistLength := 261
+	bitlistRoot := mixInLength(merkleize(pack(packBits(bitlist[:bitlistLength])), testBitlistLimit/256), bitlistLength)
+
+	newCircuit := func() *TestHashTreeRootCircuit {
+		circuit := &TestHashTreeRootCircuit{
+			Slot:           vars.U64{Value: vars.NewVariableFromInt(7294931)},
+			ProposerIndex:  vars.U64{Value: vars.NewVariableFromInt(484410)},
+			ParentRoot:     newBytes32(parentRoot),
+			StateRoot:      newBytes32(stateRoot),
+			BodyRoot:       newBytes32(bodyRoot),
+			HeaderRoot:     newBytes32(headerRoot),
+			ByteList:       vars.NewBytesFrom(byteList),
+			ByteListLength: vars.NewVariableFromInt(byteListLength),
+			ByteListRoot:   newBytes32(byteListRoot),
+			ListLength:     vars.NewVariableFromInt(listLength),
+			ListRoot:       newBytes32(listRoot),
+			Bitvector:      newBools(bitvector),
+			BitvectorRoot:  newBytes32(bitvectorRoot),
+			Bitlist:        newBools(bitlist),
+			BitlistLength:  vars.NewVariableFromInt(bitlistLength),
+			BitlistRoot:    newBytes32(bitlistRoot),
+		}
+		for i := range list {
+			circuit.List = append(circuit.List, newBytes32(list[i]))
+		}
+		return circuit
+	}
+
+	circuit := newCircuit()
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The length of a list is committed to.
+	circuit = newCircuit()
+	circuit.ListLength = vars.NewVariableFromInt(listLength + 1)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The length must not exceed the number of elements.
+	circuit = newCircuit()
+	circuit.ByteListLength = vars.NewVariableFromInt(len(byteList) + 1)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A changed bit is detected.
+	circuit = newCircuit()
+	circuit.Bitvector[299] = vars.NewBool(!bitvector[299])
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,223 @@
+// The API for signed fixed-point arithmetic in a circuit, e.g. for prices, rates or weighted
+// averages computed over oracle data.
+//
+// A fixed-point number with f fractional bits is stored as the integer x * 2^f in a single field
+// element, where negative integers are represented by their negation modulo the field. Every
+// result is range-checked to nbBits bits including the sign, so that products of two numbers never
+// wrap around the field and the comparisons are sound. Numbers which are not results, e.g. inputs
+// of the witness, must be checked with AssertIsInRange before they are used.
+package fixedpoint
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum number of bits of a fixed-point number, including the sign bit, such that the
+// product of two numbers does not wrap around the field.
+const MaxBits = 124
+
+// A fixed-point number in a circuit.
+type Fixed struct {
+	Value vars.Variable
+}
+
+// Creates a new fixed-point number with fracBits fractional bits from a rational number, which is
+// rounded to the nearest representable number. Negative numbers are reduced modulo the scalar field
+// of BN254, which is the field of all circuits.
+func NewFixedFromRat(r *big.Rat, fracBits int) Fixed {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow2(fracBits)))
+	num := new(big.Int).Mul(scaled.Num(), big.NewInt(2))
+	num.Add(num, scaled.Denom())
+	den := new(big.Int).Mul(scaled.Denom(), big.NewInt(2))
+	value := floorDiv(num, den)
+	return Fixed{Value: vars.Variable{Value: value.Mod(value, ecc.BN254.ScalarField())}}
+}
+
+// Creates a new fixed-point number with fracBits fractional bits from a decimal string, e.g.
+// "-12.375", which is rounded to the nearest representable number.
+func NewFixedFromString(s string, fracBits int) Fixed {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("invalid decimal string")
+	}
+	return NewFixedFromRat(r, fracBits)
+}
+
+// The rounding of results which are not representable.
+type Rounding int
+
+const (
+	// Rounds towards negative infinity.
+	Floor Rounding = iota
+	// Rounds towards positive infinity.
+	Ceil
+	// Rounds to the nearest number, where ties are rounded towards positive infinity.
+	Nearest
+)
+
+// An API used for fixed-point arithmetic.
+type API struct {
+	api      builder.API
+	nbBits   int
+	fracBits int
+}
+
+// Creates a new fixedpoint.API for numbers of nbBits bits, including the sign bit, of which
+// fracBits are fractional, i.e. for numbers in [-2^(nbBits-fracBits-1), 2^(nbBits-fracBits-1)).
+func NewAPI(api *builder.API, nbBits int, fracBits int) *API {
+	if nbBits > MaxBits || fracBits < 0 || fracBits >= nbBits-1 {
+		panic("unsupported number of bits")
+	}
+	return &API{api: *api, nbBits: nbBits, fracBits: fracBits}
+}
+
+// Returns the fixed-point number with the value of the integer, which must be in range.
+func (a *API) FromInt(i1 vars.Variable) Fixed {
+	return a.checked(a.api.Mul(i1, powerOfTwo(a.fracBits)))
+}
+
+// Asserts that x is in the range of the numbers of the API, i.e. that it has at most nbBits bits
+// including the sign.
+func (a *API) AssertIsInRange(x Fixed) {
+	a.checked(x.Value)
+}
+
+// Returns the fixed-point number rounded to an integer.
+func (a *API) ToInt(x Fixed, rounding Rounding) vars.Variable {
+	return a.divRound(x.Value, powerOfTwo(a.fracBits), rounding)
+}
+
+// Computes x + y.
+func (a *API) Add(x, y Fixed) Fixed {
+	return a.checked(a.api.Add(x.Value, y.Value))
+}
+
+// Computes x - y.
+func (a *API) Sub(x, y Fixed) Fixed {
+	return a.checked(a.api.Sub(x.Value, y.Value))
+}
+
+// Computes -x.
+func (a *API) Neg(x Fixed) Fixed {
+	return a.checked(a.api.Neg(x.Value))
+}
+
+// Computes x * y rounded to the fractional bits.
+func (a *API) Mul(x, y Fixed, rounding Rounding) Fixed {
+	product := a.api.Mul(x.Value, y.Value)
+	return Fixed{Value: a.divRound(product, powerOfTwo(a.fracBits), rounding)}
+}
+
+// Computes x / y rounded to the fractional bits, where y must be positive.
+func (a *API) Div(x, y Fixed, rounding Rounding) Fixed {
+	a.assertIsNonNegative(a.api.Sub(y.Value, vars.ONE))
+	scaled := a.api.Mul(x.Value, powerOfTwo(a.fracBits))
+	return Fixed{Value: a.divRound(scaled, y.Value, rounding)}
+}
+
+// Returns x if the selector is true and y otherwise.
+func (a *API) Select(selector vars.Bool, x, y Fixed) Fixed {
+	return Fixed{Value: a.api.Select(selector, x.Value, y.Value)}
+}
+
+// Returns whether x < y.
+func (a *API) IsLess(x, y Fixed) vars.Bool {
+	// x - y + 2^nbBits is in [0, 2^(nbBits + 1)), and its top bit is set iff x >= y.
+	bits := a.api.ToBinaryLE(a.api.Add(a.api.Sub(x.Value, y.Value), powerOfTwo(a.nbBits)), a.nbBits+1)
+	return a.api.Not(bits[a.nbBits])
+}
+
+// Returns whether x <= y.
+func (a *API) IsLessOrEqual(x, y Fixed) vars.Bool {
+	return a.api.Not(a.IsLess(y, x))
+}
+
+// Asserts that x <= y.
+func (a *API) AssertIsLessOrEqual(x, y Fixed) {
+	a.assertIsNonNegative(a.api.Sub(y.Value, x.Value))
+}
+
+// Returns the minimum of x and y.
+func (a *API) Min(x, y Fixed) Fixed {
+	return a.Select(a.IsLess(x, y), x, y)
+}
+
+// Returns the maximum of x and y.
+func (a *API) Max(x, y Fixed) Fixed {
+	return a.Select(a.IsLess(x, y), y, x)
+}
+
+// Asserts that x == y.
+func (a *API) AssertIsEqual(x, y Fixed) {
+	a.api.AssertIsEqual(x.Value, y.Value)
+}
+
+// Computes num / den rounded to an integer, where den must be in (0, 2^(nbBits-1)] and the result
+// must be in range. The numerator must be less than 2^(2 * nbBits - 1) in absolute value.
+func (a *API) divRound(num, den vars.Variable, rounding Rounding) vars.Variable {
+	switch rounding {
+	case Floor:
+	case Ceil:
+		num = a.api.Sub(a.api.Add(num, den), vars.ONE)
+	case Nearest:
+		num = a.api.Add(a.api.Mul(num, vars.TWO), den)
+		den = a.api.Mul(den, vars.TWO)
+	default:
+		panic("unsupported rounding")
+	}
+
+	// Since the quotient and the remainder are range-checked, both sides of the equation are less
+	// than half of the field in absolute value, so the equation also holds over the integers.
+	outputs := a.api.Hint(divFloorHint, 2, num, den)
+	quotient, remainder := outputs[0], outputs[1]
+	a.api.ToBinaryLE(remainder, a.nbBits+1)
+	a.api.ToBinaryLE(a.api.Sub(a.api.Sub(den, remainder), vars.ONE), a.nbBits+1)
+	a.api.AssertIsEqual(num, a.api.Add(a.api.Mul(quotient, den), remainder))
+	return a.checked(quotient).Value
+}
+
+// Asserts that the value is in [-2^(nbBits-1), 2^(nbBits-1)) and returns it as a fixed-point number.
+func (a *API) checked(i1 vars.Variable) Fixed {
+	a.api.ToBinaryLE(a.api.Add(i1, powerOfTwo(a.nbBits-1)), a.nbBits)
+	return Fixed{Value: i1}
+}
+
+// Asserts that the value is in [0, 2^(nbBits-1)).
+func (a *API) assertIsNonNegative(i1 vars.Variable) {
+	a.api.ToBinaryLE(i1, a.nbBits-1)
+}
+
+// Returns 2^n as a constant in a circuit.
+func powerOfTwo(n int) vars.Variable {
+	return vars.Variable{Value: pow2(n)}
+}
+
+// Returns 2^n.
+func pow2(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n))
+}
+
+// Returns floor(num / den) for a positive denominator.
+func floorDiv(num, den *big.Int) *big.Int {
+	// The Euclidean division of big.Int has a non-negative remainder, which is the floor division
+	// for a positive denominator.
+	return new(big.Int).Div(num, den)
+}
+
+// Computes floor(num / den) and num - floor(num / den) * den of its inputs num and den, where num
+// is a signed integer and den is positive.
+var divFloorHint = builder.NewHint("fixedpoint.divFloor", func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	num := new(big.Int).Set(inputs[0])
+	if num.Cmp(new(big.Int).Rsh(field, 1)) > 0 {
+		num.Sub(num, field)
+	}
+	quotient := floorDiv(num, inputs[1])
+	remainder := new(big.Int).Sub(num, new(big.Int).Mul(quotient, inputs[1]))
+	outputs[0].Mod(quotient, field)
+	outputs[1].Set(remainder)
+	return nil
+})
@@ -0,0 +1,147 @@
+package fixedpoint
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const testNbBits = 64
+const testFracBits = 16
+
+type TestFixedPointCircuit struct {
+	X, Y                      Fixed
+	Sum, Difference           Fixed
+	ProductFloor, ProductCeil Fixed
+	Quotient                  Fixed
+	IntNearest                vars.Variable
+	IsLess                    vars.Bool
+	Min                       Fixed
+}
+
+func (circuit *TestFixedPointCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	fixedAPI := NewAPI(succinctAPI, testNbBits, testFracBits)
+	fixedAPI.AssertIsInRange(circuit.X)
+	fixedAPI.AssertIsInRange(circuit.Y)
+	fixedAPI.AssertIsEqual(fixedAPI.Add(circuit.X, circuit.Y), circuit.Sum)
+	fixedAPI.AssertIsEqual(fixedAPI.Sub(circuit.X, circuit.Y), circuit.Difference)
+	fixedAPI.AssertIsEqual(fixedAPI.Mul(circuit.X, circuit.Y, Floor), circuit.ProductFloor)
+	fixedAPI.AssertIsEqual(fixedAPI.Mul(circuit.X, circuit.Y, Ceil), circuit.ProductCeil)
+	fixedAPI.AssertIsEqual(fixedAPI.Div(circuit.X, circuit.Y, Nearest), circuit.Quotient)
+	succinctAPI.AssertIsEqual(fixedAPI.ToInt(circuit.X, Nearest), circuit.IntNearest)
+	succinctAPI.AssertIsEqualBool(fixedAPI.IsLess(circuit.X, circuit.Y), circuit.IsLess)
+	fixedAPI.AssertIsEqual(fixedAPI.Min(circuit.X, circuit.Y), circuit.Min)
+	return nil
+}
+
+type TestRangeCircuit struct {
+	X Fixed
+}
+
+func (circuit *TestRangeCircuit) Define(api frontend.API) error {
+	NewAPI(builder.NewAPI(api), testNbBits, testFracBits).AssertIsInRange(circuit.X)
+	return nil
+}
+
+// Returns the rational number rounded to a multiple of 2^-fracBits with the rounding.
+func round(r *big.Rat, fracBits int, rounding Rounding) *big.Rat {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow2(fracBits)))
+	num, den := scaled.Num(), scaled.Denom()
+	var value *big.Int
+	switch rounding {
+	case Floor:
+		value = floorDiv(num, den)
+	case Ceil:
+		value = floorDiv(new(big.Int).Sub(new(big.Int).Add(num, den), big.NewInt(1)), den)
+	case Nearest:
+		value = floorDiv(new(big.Int).Add(new(big.Int).Lsh(num, 1), den), new(big.Int).Lsh(den, 1))
+	}
+	return new(big.Rat).SetFrac(value, pow2(fracBits))
+}
+
+func newTestCircuit(x, y string) *TestFixedPointCircuit {
+	xRat, _ := new(big.Rat).SetString(x)
+	yRat, _ := new(big.Rat).SetString(y)
+	fixed := func(r *big.Rat) Fixed {
+		return NewFixedFromRat(r, testFracBits)
+	}
+	product := new(big.Rat).Mul(xRat, yRat)
+	minimum := xRat
+	if yRat.Cmp(xRat) < 0 {
+		minimum = yRat
+	}
+	intNearest := round(xRat, 0, Nearest)
+	return &TestFixedPointCircuit{
+		X:            fixed(xRat),
+		Y:            fixed(yRat),
+		Sum:          fixed(new(big.Rat).Add(xRat, yRat)),
+		Difference:   fixed(new(big.Rat).Sub(xRat, yRat)),
+		ProductFloor: fixed(round(product, testFracBits, Floor)),
+		ProductCeil:  fixed(round(product, testFracBits, Ceil)),
+		Quotient:     fixed(round(new(big.Rat).Quo(xRat, yRat), testFracBits, Nearest)),
+		IntNearest:   vars.Variable{Value: new(big.Int).Mod(intNearest.Num(), ecc.BN254.ScalarField())},
+		IsLess:       vars.NewBool(xRat.Cmp(yRat) < 0),
+		Min:          fixed(minimum),
+	}
+}
+
+func TestFixedPoint(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The values are representable, so the inputs are exact and only the results are rounded.
+	for _, c := range [][2]string{
+		{"3.25", "1.5"},
+		{"-3.25", "1.5"},
+		{"1234.0078125", "0.0625"},
+		{"-0.5", "3"},
+		{"7", "7"},
+		{"0.0001220703125", "1000"},
+	} {
+		circuit := newTestCircuit(c[0], c[1])
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), c)
+	}
+
+	// A result rounded the wrong way is rejected.
+	circuit := newTestCircuit("1.0000152587890625", "1.0000152587890625")
+	circuit.ProductFloor = circuit.ProductCeil
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// Division by a non-positive number is rejected.
+	circuit = newTestCircuit("1", "-2")
+	circuit.Quotient = NewFixedFromString("-0.5", testFracBits)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A sum out of range is rejected.
+	circuit = newTestCircuit("140737488355327", "1")
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+func TestAssertIsInRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	limit := new(big.Rat).SetInt(pow2(testNbBits - testFracBits - 1))
+	epsilon := new(big.Rat).SetFrac(big.NewInt(1), pow2(testFracBits))
+	for _, c := range []struct {
+		x       *big.Rat
+		inRange bool
+	}{
+		{new(big.Rat).Sub(limit, epsilon), true},
+		{limit, false},
+		{new(big.Rat).Neg(limit), true},
+		{new(big.Rat).Sub(new(big.Rat).Neg(limit), epsilon), false},
+	} {
+		circuit := &TestRangeCircuit{X: NewFixedFromRat(c.x, testFracBits)}
+		err := test.IsSolved(circuit, circuit, ecc.BN254.ScalarField())
+		if c.inRange {
+			assert.NoError(err, c.x)
+		} else {
+			assert.Error(err, c.x)
+		}
+	}
+}
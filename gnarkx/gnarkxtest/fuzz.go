@@ -0,0 +1,209 @@
+package gnarkxtest
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+// Generates a random assignment of a circuit, whose outputs are computed by a reference
+// implementation of the gadget under test.
+type Generator func(rng *rand.Rand) frontend.Circuit
+
+// Asserts that the circuit accepts nbRuns random assignments of the generator, i.e. that the
+// witness solver of the gadget agrees with the reference implementation. The runs are
+// deterministic for a seed, so that a failing run can be reproduced.
+func (tt *Tester) Fuzz(seed int64, nbRuns int, generate Generator) {
+	tt.t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < nbRuns; i++ {
+		if err := tt.solve(generate(rng)); err != nil {
+			tt.t.Errorf("run %d with seed %d: expected the assignment to be proven: %v", i, seed, err)
+			return
+		}
+	}
+}
+
+// Asserts that the circuit rejects nbRuns random assignments of the generator, each of which has a
+// random variable of the named fields mutated, e.g. the outputs of the gadget. If no fields are
+// given, any variable of the assignment may be mutated.
+func (tt *Tester) FuzzMutations(seed int64, nbRuns int, generate Generator, fields ...string) {
+	tt.t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < nbRuns; i++ {
+		assignment := generate(rng)
+		variables := collectVariables(reflect.ValueOf(assignment), fields)
+		if len(variables) == 0 {
+			tt.t.Fatalf("no variables to mutate in the fields %v", fields)
+		}
+		variable := variables[rng.Intn(len(variables))]
+		value := variable.Interface()
+		variable.Set(reflect.ValueOf(mutate(rng, value)))
+		if err := tt.solve(assignment); err == nil {
+			tt.t.Errorf("run %d with seed %d: expected the assignment to be rejected after mutating %v", i, seed, value)
+			return
+		}
+	}
+}
+
+// Asserts that the circuit rejects nbRuns random assignments of the generator, each of which is
+// solved with a random output of a call of the given hints mutated. Unlike FuzzMutations, this
+// detects hint outputs which the circuit does not constrain, since the solver recomputes the hints
+// from a mutated assignment. The outputs of the hints must be determined by the circuit, e.g. a
+// quotient or a decomposition, unlike e.g. an inverse which is only used if it exists. Note that
+// a run is also rejected if a later hint fails on the mutated output.
+func (tt *Tester) FuzzHintMutations(seed int64, nbRuns int, generate Generator, hints ...builder.Hint) {
+	tt.t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < nbRuns; i++ {
+		assignment := generate(rng)
+
+		// The honest run records the calls of the hints, one of which is mutated in the next run.
+		calls := &hintCalls{nbOutputs: make(map[string]int)}
+		if err := tt.solve(assignment, calls.options(hints)...); err != nil {
+			tt.t.Errorf("run %d with seed %d: expected the assignment to be proven: %v", i, seed, err)
+			return
+		}
+		keys := make([]string, 0, len(calls.nbOutputs))
+		for key, nbOutputs := range calls.nbOutputs {
+			if nbOutputs > 0 {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			tt.t.Fatalf("no outputs of the hints to mutate")
+		}
+		sort.Strings(keys)
+		calls.key = keys[rng.Intn(len(keys))]
+		calls.output = rng.Intn(calls.nbOutputs[calls.key])
+		calls.delta = mutate(rng, big.NewInt(0))
+		if err := tt.solve(assignment, calls.options(hints)...); err == nil {
+			tt.t.Errorf("run %d with seed %d: expected the assignment to be rejected after mutating output %d of %s", i, seed, calls.output, calls.key)
+			return
+		}
+	}
+}
+
+// The calls of hints in a run of the solver, which overrides the hints to record and mutate their
+// outputs. Calls are identified by the hint and its inputs rather than by their order, since the
+// solver may call hints concurrently.
+type hintCalls struct {
+	lock sync.Mutex
+	// The number of outputs of each call.
+	nbOutputs map[string]int
+	// The call whose output is mutated by adding delta to it, if any.
+	key    string
+	output int
+	delta  *big.Int
+}
+
+// Returns the options of the solver which override the hints.
+func (c *hintCalls) options(hints []builder.Hint) []solver.Option {
+	opts := make([]solver.Option, len(hints))
+	for i, hint := range hints {
+		id := hint.ID()
+		f := solver.GetRegisteredHint(id)
+		if f == nil {
+			panic(fmt.Sprintf("hint %s is not registered", hint.Name()))
+		}
+		name := hint.Name()
+		opts[i] = solver.OverrideHint(id, func(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+			if err := f(field, inputs, outputs); err != nil {
+				return err
+			}
+			key := fmt.Sprintf("%s%v", name, inputs)
+			c.lock.Lock()
+			defer c.lock.Unlock()
+			c.nbOutputs[key] = len(outputs)
+			if key == c.key {
+				output := outputs[c.output]
+				output.Add(output, c.delta).Mod(output, field)
+			}
+			return nil
+		})
+	}
+	return opts
+}
+
+var variableType = reflect.TypeOf((*frontend.Variable)(nil)).Elem()
+
+// Returns the assigned variables of v, restricted to the named fields of the top-level struct if
+// any are given.
+func collectVariables(v reflect.Value, fields []string) []reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if len(fields) == 0 {
+		return appendVariables(nil, v)
+	}
+	var variables []reflect.Value
+	for _, field := range fields {
+		f := v.FieldByName(field)
+		if !f.IsValid() {
+			panic(fmt.Sprintf("no field %s in the circuit", field))
+		}
+		variables = appendVariables(variables, f)
+	}
+	return variables
+}
+
+// Appends the assigned variables of v to variables.
+func appendVariables(variables []reflect.Value, v reflect.Value) []reflect.Value {
+	if v.Type() == variableType {
+		if !v.IsNil() && v.CanSet() {
+			variables = append(variables, v)
+		}
+		return variables
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			variables = appendVariables(variables, v.Elem())
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			variables = appendVariables(variables, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() && field.Tag.Get("gnark") != "-" {
+				variables = appendVariables(variables, v.Field(i))
+			}
+		}
+	}
+	return variables
+}
+
+// Returns the value plus a random non-zero delta, which is either small, so that range checks
+// still pass, or large, so that they fail.
+func mutate(rng *rand.Rand, value any) *big.Int {
+	i := new(big.Int)
+	switch value := value.(type) {
+	case *big.Int:
+		i.Set(value)
+	case big.Int:
+		i.Set(&value)
+	default:
+		if _, ok := i.SetString(fmt.Sprint(value), 0); !ok {
+			panic(fmt.Sprintf("unsupported value %v of type %T", value, value))
+		}
+	}
+	delta := big.NewInt(rng.Int63n(255) + 1)
+	if rng.Intn(2) == 0 {
+		delta.Lsh(delta, uint(rng.Intn(250)))
+	}
+	if rng.Intn(2) == 0 {
+		delta.Neg(delta)
+	}
+	i.Add(i, delta)
+	return i.Mod(i, ecc.BN254.ScalarField())
+}
@@ -0,0 +1,71 @@
+package gnarkxtest
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func generateSum(rng *rand.Rand) frontend.Circuit {
+	return newSumCircuit(rng.Intn(1<<20), rng.Intn(1<<20), rng.Intn(1<<20))
+}
+
+func TestFuzz(t *testing.T) {
+	tester := New(t, &TestSumCircuit{Values: make([]vars.U64, 3)})
+	tester.Fuzz(0, 20, generateSum)
+	tester.FuzzMutations(0, 20, generateSum, "Sum")
+	tester.FuzzMutations(1, 20, generateSum)
+
+	// An assignment which disagrees with the circuit is reported.
+	r := &recorder{TB: t}
+	tester.t = r
+	tester.Fuzz(0, 20, func(rng *rand.Rand) frontend.Circuit {
+		circuit := newSumCircuit(rng.Intn(10), rng.Intn(10), rng.Intn(10))
+		circuit.Sum = vars.U64{Value: vars.NewVariableFromInt(100)}
+		return circuit
+	})
+	assert.Len(t, r.errors, 1)
+}
+
+// Halves its single input.
+var halfHint = builder.NewHint("gnarkxtest.half", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].Rsh(inputs[0], 1)
+	return nil
+})
+
+// Halves an even value with a hint, whose output is only constrained if Constrained is set.
+type TestHalfCircuit struct {
+	X           vars.Variable
+	Constrained bool `gnark:"-"`
+}
+
+func (circuit *TestHalfCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	half := succinctAPI.Hint(halfHint, 1, circuit.X)[0]
+	succinctAPI.AssertIsU64(half)
+	if circuit.Constrained {
+		succinctAPI.AssertIsEqual(succinctAPI.Add(half, half), circuit.X)
+	}
+	return nil
+}
+
+func TestFuzzHintMutations(t *testing.T) {
+	generate := func(rng *rand.Rand) frontend.Circuit {
+		return &TestHalfCircuit{X: vars.NewVariableFromInt(2 * rng.Intn(1<<20)), Constrained: true}
+	}
+	New(t, &TestHalfCircuit{Constrained: true}).FuzzHintMutations(0, 20, generate, halfHint)
+
+	// An unconstrained hint output is reported, although mutating the assignment cannot find it.
+	r := &recorder{TB: t}
+	tester := New(t, &TestHalfCircuit{})
+	tester.t = r
+	tester.FuzzHintMutations(0, 20, func(rng *rand.Rand) frontend.Circuit {
+		return &TestHalfCircuit{X: vars.NewVariableFromInt(2 * rng.Intn(1<<20))}
+	}, halfHint)
+	assert.Len(t, r.errors, 1)
+}
@@ -0,0 +1,111 @@
+// Helpers for testing circuits and gadgets against their compiled constraint system.
+//
+// A circuit is compiled once per test binary and cached, so that a test can check many
+// assignments without recompiling the circuit for each of them:
+//
+//	tester := gnarkxtest.New(t, &TestCircuit{})
+//	tester.AssertProves(&TestCircuit{X: ...})
+//	tester.AssertConstraintFails(&TestCircuit{X: ...}, "AssertIsEqualU64")
+package gnarkxtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+var (
+	cacheLock sync.Mutex
+	cache     = make(map[string]constraint.ConstraintSystem)
+)
+
+// Tests assignments of a circuit against its compiled constraint system.
+type Tester struct {
+	t       testing.TB
+	circuit frontend.Circuit
+	cs      constraint.ConstraintSystem
+}
+
+// Creates a new Tester for the circuit, which is compiled to R1CS over BN254 unless a circuit of
+// the same type and shape has already been compiled by the test binary.
+func New(t testing.TB, circuit frontend.Circuit) *Tester {
+	t.Helper()
+	cs, err := compile(circuit)
+	if err != nil {
+		t.Fatalf("failed to compile circuit: %v", err)
+	}
+	return &Tester{t: t, circuit: circuit, cs: cs}
+}
+
+// Returns the compiled constraint system, e.g. for checking its number of constraints.
+func (tt *Tester) ConstraintSystem() constraint.ConstraintSystem {
+	return tt.cs
+}
+
+// Asserts that the assignment satisfies the constraints of the circuit, i.e. that the solver run
+// by the prover succeeds on it.
+func (tt *Tester) AssertProves(assignment frontend.Circuit) {
+	tt.t.Helper()
+	if err := tt.solve(assignment); err != nil {
+		tt.t.Errorf("expected the assignment to be proven: %v", err)
+	}
+}
+
+// Asserts that the assignment does not satisfy the constraints of the circuit. If expectedGadget
+// is not empty, the assignment is also run in the test engine of gnark and the stack of the failed
+// assertion must contain expectedGadget, e.g. the name of the package or the function of the
+// gadget which is expected to reject the assignment.
+func (tt *Tester) AssertConstraintFails(assignment frontend.Circuit, expectedGadget string) {
+	tt.t.Helper()
+	if err := tt.solve(assignment); err == nil {
+		tt.t.Errorf("expected the assignment to be rejected")
+		return
+	}
+	if expectedGadget == "" {
+		return
+	}
+	err := test.IsSolved(tt.circuit, assignment, ecc.BN254.ScalarField())
+	if err == nil {
+		tt.t.Errorf("expected the assignment to be rejected by %s, but the test engine accepted it", expectedGadget)
+		return
+	}
+	if !strings.Contains(err.Error(), expectedGadget) {
+		tt.t.Errorf("expected the assignment to be rejected by %s, got: %v", expectedGadget, err)
+	}
+}
+
+// Runs the solver of the constraint system on the assignment.
+func (tt *Tester) solve(assignment frontend.Circuit, opts ...solver.Option) error {
+	tt.t.Helper()
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		tt.t.Fatalf("failed to create witness: %v", err)
+	}
+	return tt.cs.IsSolved(witness, opts...)
+}
+
+// Compiles the circuit, or returns the cached constraint system of a circuit of the same type and
+// shape. The shape is given by the printed circuit, which includes the lengths of its slices and
+// the values of its fields which are not variables.
+func compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	key := fmt.Sprintf("%T%+v", circuit, circuit)
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if cs, ok := cache[key]; ok {
+		return cs, nil
+	}
+	cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, err
+	}
+	cache[key] = cs
+	return cs, nil
+}
@@ -0,0 +1,70 @@
+package gnarkxtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestSumCircuit struct {
+	Values []vars.U64
+	Sum    vars.U64
+}
+
+func (circuit *TestSumCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sum := vars.U64{Value: vars.NewVariableFromInt(0)}
+	for _, value := range circuit.Values {
+		sum = succinctAPI.AddU64(sum, value)
+	}
+	succinctAPI.AssertIsEqualU64(sum, circuit.Sum)
+	return nil
+}
+
+func newSumCircuit(values ...int) *TestSumCircuit {
+	circuit := &TestSumCircuit{Values: make([]vars.U64, len(values))}
+	sum := 0
+	for i, value := range values {
+		circuit.Values[i] = vars.U64{Value: vars.NewVariableFromInt(value)}
+		sum += value
+	}
+	circuit.Sum = vars.U64{Value: vars.NewVariableFromInt(sum)}
+	return circuit
+}
+
+// Records the errors of a test instead of failing it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestTester(t *testing.T) {
+	tester := New(t, &TestSumCircuit{Values: make([]vars.U64, 3)})
+
+	// The constraint system is only compiled once per shape of the circuit.
+	assert.Same(t, tester.ConstraintSystem(), New(t, &TestSumCircuit{Values: make([]vars.U64, 3)}).ConstraintSystem())
+	assert.NotSame(t, tester.ConstraintSystem(), New(t, &TestSumCircuit{Values: make([]vars.U64, 4)}).ConstraintSystem())
+
+	tester.AssertProves(newSumCircuit(1, 2, 3))
+
+	wrongSum := newSumCircuit(1, 2, 3)
+	wrongSum.Sum = vars.U64{Value: vars.NewVariableFromInt(7)}
+	tester.AssertConstraintFails(wrongSum, "AssertIsEqualU64")
+	tester.AssertConstraintFails(wrongSum, "")
+
+	// Failed assertions are reported.
+	r := &recorder{TB: t}
+	tester.t = r
+	tester.AssertProves(wrongSum)
+	tester.AssertConstraintFails(newSumCircuit(1, 2, 3), "")
+	tester.AssertConstraintFails(wrongSum, "AssertIsByte")
+	assert.Len(t, r.errors, 3)
+}
@@ -0,0 +1,296 @@
+// The API for BLAKE2b according to RFC 7693, unkeyed and with digests of up to 64 bytes, e.g.
+// BLAKE2b-256 as used by Polkadot. The words are big-endian bit arrays like in bits64, so the
+// rotations are free and the xors and additions cost about one constraint per bit.
+package blake2b
+
+import (
+	"fmt"
+
+	"github.com/succinctlabs/succinctx/gnarkx/bits64"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes of a block.
+const BlockSize = 128
+
+// The initialization vector, which is the initial hash value of SHA-512.
+var IV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// The permutations of the message words of the rounds, of which round i uses sigma[i % 10].
+var sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Hasher computes a BLAKE2b hash incrementally. Since the last block is compressed with a flag,
+// a full block is only compressed once more input has been written. Note that at compile time of
+// the circuit, the total length of the input must be a constant.
+type Hasher struct {
+	api    builder.API
+	bits64 bits64.API
+	size   int
+	h      [8][64]vars.Bool
+	buffer []vars.Byte
+	length uint64
+}
+
+// Creates a new Hasher for digests of size bytes, which must be in [1, 64].
+func NewHasher(api builder.API, size int) *Hasher {
+	if size < 1 || size > 64 {
+		panic(fmt.Sprintf("invalid digest size %d", size))
+	}
+	h := &Hasher{api: api, bits64: bits64.NewAPI(api), size: size}
+	for i := range h.h {
+		h.h[i] = constantWord(IV[i])
+	}
+	// The parameter block of an unkeyed hash only sets the digest size, the fanout and the depth.
+	h.h[0] = constantWord(IV[0] ^ 0x01010000 ^ uint64(size))
+	return h
+}
+
+// Writes the input bytes, compressing all full blocks but the last one.
+func (h *Hasher) Write(in []vars.Byte) {
+	h.buffer = append(h.buffer, in...)
+	for len(h.buffer) > BlockSize {
+		h.length += BlockSize
+		h.h = h.compress(h.h, h.buffer[:BlockSize], constantWord(h.length), vars.FALSE)
+		h.buffer = h.buffer[BlockSize:]
+	}
+}
+
+// Compresses the remaining input as the last block and returns the digest of size bytes. The
+// Hasher must not be used afterwards.
+func (h *Hasher) Sum() []vars.Byte {
+	h.length += uint64(len(h.buffer))
+	h.h = h.compress(h.h, padBlock(h.buffer), constantWord(h.length), vars.TRUE)
+	h.buffer = nil
+	return h.digest(h.h)
+}
+
+// Returns the first size bytes of the little-endian encoding of the hash value.
+func (h *Hasher) digest(state [8][64]vars.Bool) []vars.Byte {
+	out := make([]vars.Byte, 0, 64)
+	for i := range state {
+		for j := 0; j < 8; j++ {
+			var bits [8]vars.Bool
+			for k := range bits {
+				bits[k] = state[i][63-(8*j+k)]
+			}
+			out = append(out, h.api.ToByteFromBits(bits))
+		}
+	}
+	return out[:h.size]
+}
+
+// Applies the compression function F to the hash value, a block of BlockSize bytes, the number of
+// bytes compressed so far including the block, and the flag for the last block.
+func (h *Hasher) compress(state [8][64]vars.Bool, block []vars.Byte, counter [64]vars.Bool, isLast vars.Bool) [8][64]vars.Bool {
+	var m [16][64]vars.Bool
+	for i := range m {
+		for j := 0; j < 8; j++ {
+			bits := h.api.ToBitsFromByte(block[8*i+j])
+			for k := range bits {
+				m[i][63-(8*j+k)] = bits[k]
+			}
+		}
+	}
+
+	var v [16][64]vars.Bool
+	copy(v[:8], state[:])
+	for i := 0; i < 8; i++ {
+		v[8+i] = constantWord(IV[i])
+	}
+	// The counter is less than 2^64, so only its low word is xored. The flag of the last block
+	// negates v[14].
+	v[12] = h.bits64.Xor64(v[12], counter)
+	for i := range v[14] {
+		v[14][i] = h.api.Xor(v[14][i], isLast)
+	}
+
+	for round := 0; round < 12; round++ {
+		s := sigma[round%10]
+		h.g(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		h.g(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		h.g(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		h.g(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		h.g(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		h.g(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		h.g(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		h.g(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range state {
+		state[i] = h.bits64.Xor64(state[i], v[i], v[i+8])
+	}
+	return state
+}
+
+// Applies the mixing function G to the words of v at the indices a, b, c and d.
+func (h *Hasher) g(v *[16][64]vars.Bool, a, b, c, d int, x, y [64]vars.Bool) {
+	v[a] = h.add(v[a], v[b], x)
+	v[d] = h.bits64.Rotate64(h.bits64.Xor64(v[d], v[a]), 32)
+	v[c] = h.add(v[c], v[d])
+	v[b] = h.bits64.Rotate64(h.bits64.Xor64(v[b], v[c]), 24)
+	v[a] = h.add(v[a], v[b], y)
+	v[d] = h.bits64.Rotate64(h.bits64.Xor64(v[d], v[a]), 16)
+	v[c] = h.add(v[c], v[d])
+	v[b] = h.bits64.Rotate64(h.bits64.Xor64(v[b], v[c]), 63)
+}
+
+// Computes the sum of the words modulo 2^64 by decomposing their sum over the field, which costs
+// one constraint per bit of the sum.
+func (h *Hasher) add(in ...[64]vars.Bool) [64]vars.Bool {
+	sum := vars.ZERO
+	for i := range in {
+		power := vars.ONE
+		for j := 63; j >= 0; j-- {
+			sum = h.api.Add(sum, h.api.Mul(in[i][j].Value, power))
+			power = h.api.Mul(power, vars.TWO)
+		}
+	}
+	bits := h.api.ToBinaryLE(sum, 66)
+	var out [64]vars.Bool
+	for i := range out {
+		out[63-i] = bits[i]
+	}
+	return out
+}
+
+// Computes the BLAKE2b-512 hash of the input bytes. Note that at compile time of the circuit,
+// len(in) must be a constant.
+func Hash(api builder.API, in []vars.Byte) [64]vars.Byte {
+	var out [64]vars.Byte
+	copy(out[:], hash(api, in, 64))
+	return out
+}
+
+// Computes the BLAKE2b-256 hash of the input bytes like Hash.
+func Hash256(api builder.API, in []vars.Byte) [32]vars.Byte {
+	var out [32]vars.Byte
+	copy(out[:], hash(api, in, 32))
+	return out
+}
+
+func hash(api builder.API, in []vars.Byte, size int) []vars.Byte {
+	defer api.Scope("blake2b")()
+	h := NewHasher(api, size)
+	h.Write(in)
+	return h.Sum()
+}
+
+// Computes the BLAKE2b-512 hash of the first length bytes of in. The remaining bytes of in are
+// ignored, so len(in) is the maximum length of the message and must be a constant at compile time
+// of the circuit, while length may be any variable in [0, len(in)].
+func HashVariable(api builder.API, in []vars.Byte, length vars.Variable) [64]vars.Byte {
+	var out [64]vars.Byte
+	copy(out[:], hashVariable(api, in, length, 64))
+	return out
+}
+
+// Computes the BLAKE2b-256 hash of the first length bytes of in like HashVariable.
+func HashVariable256(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	var out [32]vars.Byte
+	copy(out[:], hashVariable(api, in, length, 32))
+	return out
+}
+
+// Computes the BLAKE2b-512 hash of variable bytes.
+func HashVariableBytes(api builder.API, in vars.VariableBytes) [64]vars.Byte {
+	return HashVariable(api, in.Data, in.Length)
+}
+
+func hashVariable(api builder.API, in []vars.Byte, length vars.Variable, size int) []vars.Byte {
+	defer api.Scope("blake2b")()
+	numBlocks := (len(in) + BlockSize - 1) / BlockSize
+	if numBlocks == 0 {
+		numBlocks = 1
+	}
+
+	// The selectors isEnd[i] are set iff i == length, and exactly one of them must be set.
+	isEnd := make([]vars.Bool, len(in)+1)
+	nbEnds := vars.ZERO
+	for i := 0; i <= len(in); i++ {
+		isEnd[i] = api.IsZero(api.Sub(length, vars.NewVariableFromInt(i)))
+		nbEnds = api.Add(nbEnds, isEnd[i].Value)
+	}
+	api.AssertIsEqual(nbEnds, vars.ONE)
+
+	// The block i is the last one iff length is in (BlockSize * i, BlockSize * (i + 1)], or if it
+	// is the first block and the message is empty.
+	isLastBlock := make([]vars.Bool, numBlocks)
+	for i := range isLastBlock {
+		isLast := vars.ZERO
+		for j := BlockSize*i + 1; j <= BlockSize*(i+1) && j <= len(in); j++ {
+			isLast = api.Add(isLast, isEnd[j].Value)
+		}
+		if i == 0 {
+			isLast = api.Add(isLast, isEnd[0].Value)
+		}
+		isLastBlock[i] = vars.Bool{Value: isLast}
+	}
+
+	// The bytes of the message after length are zeroed, and the counter of the last block is the
+	// length instead of the number of bytes of all blocks so far.
+	h := NewHasher(api, size)
+	state := h.h
+	result := h.h
+	isMessage := vars.ONE
+	for i := 0; i < numBlocks; i++ {
+		block := make([]vars.Byte, BlockSize)
+		for j := range block {
+			k := BlockSize*i + j
+			if k <= len(in) {
+				isMessage = api.Sub(isMessage, isEnd[k].Value)
+			}
+			if k < len(in) {
+				block[j] = vars.Byte{Value: api.Mul(isMessage, in[k].Value)}
+			} else {
+				block[j] = vars.ZERO_BYTE
+			}
+		}
+		counter := api.Select(isLastBlock[i], length, vars.NewVariableFromInt(BlockSize*(i+1)))
+		var counterWord [64]vars.Bool
+		copy(counterWord[64-32:], api.ToBinaryBE(counter, 32))
+		for j := 0; j < 64-32; j++ {
+			counterWord[j] = vars.FALSE
+		}
+		state = h.compress(state, block, counterWord, isLastBlock[i])
+		for j := range result {
+			for k := range result[j] {
+				result[j][k] = vars.Bool{Value: api.Select(isLastBlock[i], state[j][k].Value, result[j][k].Value)}
+			}
+		}
+	}
+	return h.digest(result)
+}
+
+// Pads the bytes with zeros to a block.
+func padBlock(in []vars.Byte) []vars.Byte {
+	block := make([]vars.Byte, BlockSize)
+	copy(block, in)
+	for i := len(in); i < BlockSize; i++ {
+		block[i] = vars.ZERO_BYTE
+	}
+	return block
+}
+
+// Returns the big-endian bits of a constant word.
+func constantWord(value uint64) [64]vars.Bool {
+	var word [64]vars.Bool
+	for i := range word {
+		word[63-i] = vars.NewBool(value>>i&1 == 1)
+	}
+	return word
+}
@@ -0,0 +1,125 @@
+package blake2b
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestBlake2bCircuit struct {
+	In  []vars.Byte
+	Out []vars.Byte
+	// If set, the input is written to the hasher in chunks of this size.
+	ChunkSize int `gnark:"-"`
+}
+
+func (circuit *TestBlake2bCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	var res []vars.Byte
+	if circuit.ChunkSize == 0 {
+		if len(circuit.Out) == 32 {
+			out := Hash256(*succinctAPI, circuit.In)
+			res = out[:]
+		} else {
+			out := Hash(*succinctAPI, circuit.In)
+			res = out[:]
+		}
+	} else {
+		hasher := NewHasher(*succinctAPI, len(circuit.Out))
+		for i := 0; i < len(circuit.In); i += circuit.ChunkSize {
+			end := i + circuit.ChunkSize
+			if end > len(circuit.In) {
+				end = len(circuit.In)
+			}
+			hasher.Write(circuit.In[i:end])
+		}
+		res = hasher.Sum()
+	}
+	for i := range res {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+type TestBlake2bVariableCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [64]vars.Byte
+}
+
+func (circuit *TestBlake2bVariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	for i := range res {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func TestHashNative(t *testing.T) {
+	// The test vector of RFC 7693.
+	expected := "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d1" +
+		"7d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+	out := HashNative([]byte("abc"))
+	if hex.EncodeToString(out[:]) != expected {
+		t.Errorf("unexpected hash %x", out)
+	}
+}
+
+func TestHash(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(in []byte, size int, chunkSize int) {
+		var out []byte
+		if size == 32 {
+			digest := Hash256Native(in)
+			out = digest[:]
+		} else {
+			digest := HashNative(in)
+			out = digest[:]
+		}
+		circuit := TestBlake2bCircuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out), ChunkSize: chunkSize}
+		witness := TestBlake2bCircuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out)}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+		out[0] ^= 1
+		witness = TestBlake2bCircuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out)}
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+
+	testCase([]byte(""), 64, 0)
+	testCase([]byte("Succinct Labs"), 32, 0)
+	// A full block, which is the last one, and more than one block written in chunks.
+	testCase(make([]byte, BlockSize), 64, 0)
+	long := make([]byte, 2*BlockSize+17)
+	for i := range long {
+		long[i] = byte(i * 7)
+	}
+	testCase(long, 64, 50)
+}
+
+func TestHashVariable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := make([]byte, 2*BlockSize)
+	for i := range in {
+		in[i] = byte(i * 13)
+	}
+	circuit := TestBlake2bVariableCircuit{In: make([]vars.Byte, len(in))}
+	for _, length := range []int{0, 5, BlockSize, BlockSize + 1, len(in)} {
+		var out [64]vars.Byte
+		digest := HashVariableNative(in, length)
+		copy(out[:], vars.NewBytesFrom(digest[:]))
+		witness := TestBlake2bVariableCircuit{
+			In:     vars.NewBytesFrom(in),
+			Length: vars.NewVariableFromInt(length),
+			Out:    out,
+		}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+}
@@ -0,0 +1,23 @@
+package blake2b
+
+import (
+	"golang.org/x/crypto/blake2b"
+)
+
+// Computes the BLAKE2b-512 hash of the input bytes like Hash.
+func HashNative(in []byte) [64]byte {
+	return blake2b.Sum512(in)
+}
+
+// Computes the BLAKE2b-256 hash of the input bytes like Hash256.
+func Hash256Native(in []byte) [32]byte {
+	return blake2b.Sum256(in)
+}
+
+// Computes the BLAKE2b-512 hash of the first length bytes of in like HashVariable.
+func HashVariableNative(in []byte, length int) [64]byte {
+	if length < 0 || length > len(in) {
+		panic("length is out of range")
+	}
+	return blake2b.Sum512(in[:length])
+}
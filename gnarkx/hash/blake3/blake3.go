@@ -0,0 +1,337 @@
+// The API for BLAKE3 according to https://github.com/BLAKE3-team/BLAKE3-specs, in the default
+// hash mode with digests of 32 bytes. Inputs of more than ChunkSize bytes are split into chunks,
+// which are merged in a binary tree. The words are big-endian bit arrays like in bits32.
+package blake3
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/bits32"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes of a block.
+const BlockSize = 64
+
+// The number of bytes of a chunk, i.e. 16 blocks.
+const ChunkSize = 1024
+
+// The initialization vector, which is the initial hash value of SHA-256.
+var IV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+// The permutation of the message words applied after each round.
+var permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+// The domain separation flags of a compression.
+const (
+	flagChunkStart uint32 = 1 << 0
+	flagChunkEnd   uint32 = 1 << 1
+	flagParent     uint32 = 1 << 2
+	flagRoot       uint32 = 1 << 3
+)
+
+type word = [32]vars.Bool
+
+// The input of a compression, which outputs either a chaining value or the root digest.
+type output struct {
+	cv       [8]word
+	block    [16]word
+	counter  uint64
+	blockLen word
+	flags    word
+}
+
+// Hasher computes a BLAKE3 hash incrementally. Since the last block of a chunk is compressed with
+// a flag, a full block is only compressed once more input has been written. Note that at compile
+// time of the circuit, the total length of the input must be a constant.
+type Hasher struct {
+	api    builder.API
+	bits32 bits32.API
+	// The chaining value of the current chunk, its index and the number of its compressed blocks.
+	cv               [8]word
+	chunkCounter     uint64
+	blocksCompressed int
+	buffer           []vars.Byte
+	// The chaining values of the complete subtrees to the left of the current chunk.
+	stack [][8]word
+}
+
+// Creates a new Hasher.
+func NewHasher(api builder.API) *Hasher {
+	return &Hasher{api: api, bits32: bits32.NewAPI(api), cv: constantCV()}
+}
+
+// Writes the input bytes, compressing all full blocks but the last one.
+func (h *Hasher) Write(in []vars.Byte) {
+	h.buffer = append(h.buffer, in...)
+	for len(h.buffer) > BlockSize {
+		flags := uint32(0)
+		if h.blocksCompressed == 0 {
+			flags |= flagChunkStart
+		}
+		// The last block of a full chunk ends it, since more input follows.
+		isChunkEnd := h.blocksCompressed == ChunkSize/BlockSize-1
+		if isChunkEnd {
+			flags |= flagChunkEnd
+		}
+		h.cv = h.chainingValue(output{
+			cv:       h.cv,
+			block:    toBlock(h.api, h.buffer[:BlockSize]),
+			counter:  h.chunkCounter,
+			blockLen: constantWord(BlockSize),
+			flags:    constantWord(flags),
+		})
+		h.buffer = h.buffer[BlockSize:]
+		h.blocksCompressed++
+		if isChunkEnd {
+			h.addChunk(h.cv)
+		}
+	}
+}
+
+// Pushes the chaining value of a complete chunk onto the stack, merging it with the complete
+// subtrees of the same size, and starts the next chunk.
+func (h *Hasher) addChunk(cv [8]word) {
+	h.chunkCounter++
+	for total := h.chunkCounter; total&1 == 0; total >>= 1 {
+		cv = h.chainingValue(parent(h.stack[len(h.stack)-1], cv))
+		h.stack = h.stack[:len(h.stack)-1]
+	}
+	h.stack = append(h.stack, cv)
+	h.cv = constantCV()
+	h.blocksCompressed = 0
+}
+
+// Compresses the remaining input as the last block of the last chunk, merges the tree and returns
+// the 32 byte digest of its root. The Hasher must not be used afterwards.
+func (h *Hasher) Sum() [32]vars.Byte {
+	flags := flagChunkEnd
+	if h.blocksCompressed == 0 {
+		flags |= flagChunkStart
+	}
+	out := output{
+		cv:       h.cv,
+		block:    toBlock(h.api, h.buffer),
+		counter:  h.chunkCounter,
+		blockLen: constantWord(uint32(len(h.buffer))),
+		flags:    constantWord(flags),
+	}
+	for i := len(h.stack) - 1; i >= 0; i-- {
+		out = parent(h.stack[i], h.chainingValue(out))
+	}
+	out.flags = h.bits32.Xor(out.flags, constantWord(flagRoot))
+	h.buffer = nil
+	return h.digest(h.chainingValue(out))
+}
+
+// Returns the output of the parent of two chaining values.
+func parent(left, right [8]word) output {
+	var block [16]word
+	copy(block[:8], left[:])
+	copy(block[8:], right[:])
+	return output{cv: constantCV(), block: block, blockLen: constantWord(BlockSize), flags: constantWord(flagParent)}
+}
+
+// Applies the compression function and returns the first 8 words of its output, which are the
+// chaining value or the digest.
+func (h *Hasher) chainingValue(o output) [8]word {
+	v := [16]word{
+		o.cv[0], o.cv[1], o.cv[2], o.cv[3], o.cv[4], o.cv[5], o.cv[6], o.cv[7],
+		constantWord(IV[0]), constantWord(IV[1]), constantWord(IV[2]), constantWord(IV[3]),
+		constantWord(uint32(o.counter)), constantWord(uint32(o.counter >> 32)), o.blockLen, o.flags,
+	}
+	m := o.block
+	for round := 0; round < 7; round++ {
+		h.g(&v, 0, 4, 8, 12, m[0], m[1])
+		h.g(&v, 1, 5, 9, 13, m[2], m[3])
+		h.g(&v, 2, 6, 10, 14, m[4], m[5])
+		h.g(&v, 3, 7, 11, 15, m[6], m[7])
+		h.g(&v, 0, 5, 10, 15, m[8], m[9])
+		h.g(&v, 1, 6, 11, 12, m[10], m[11])
+		h.g(&v, 2, 7, 8, 13, m[12], m[13])
+		h.g(&v, 3, 4, 9, 14, m[14], m[15])
+		var permuted [16]word
+		for i := range permuted {
+			permuted[i] = m[permutation[i]]
+		}
+		m = permuted
+	}
+	var cv [8]word
+	for i := range cv {
+		cv[i] = h.bits32.Xor(v[i], v[i+8])
+	}
+	return cv
+}
+
+// Applies the mixing function G to the words of v at the indices a, b, c and d.
+func (h *Hasher) g(v *[16]word, a, b, c, d int, x, y word) {
+	v[a] = h.add(v[a], v[b], x)
+	v[d] = h.bits32.Rotate(h.bits32.Xor(v[d], v[a]), 16)
+	v[c] = h.add(v[c], v[d])
+	v[b] = h.bits32.Rotate(h.bits32.Xor(v[b], v[c]), 12)
+	v[a] = h.add(v[a], v[b], y)
+	v[d] = h.bits32.Rotate(h.bits32.Xor(v[d], v[a]), 8)
+	v[c] = h.add(v[c], v[d])
+	v[b] = h.bits32.Rotate(h.bits32.Xor(v[b], v[c]), 7)
+}
+
+// Computes the sum of the words modulo 2^32 by decomposing their sum over the field, which costs
+// one constraint per bit of the sum instead of the two per bit of chained additions.
+func (h *Hasher) add(in ...word) word {
+	sum := vars.ZERO
+	for i := range in {
+		power := vars.ONE
+		for j := 31; j >= 0; j-- {
+			sum = h.api.Add(sum, h.api.Mul(in[i][j].Value, power))
+			power = h.api.Mul(power, vars.TWO)
+		}
+	}
+	bits := h.api.ToBinaryLE(sum, 34)
+	var out word
+	for i := range out {
+		out[31-i] = bits[i]
+	}
+	return out
+}
+
+// Returns the little-endian encoding of the words.
+func (h *Hasher) digest(cv [8]word) [32]vars.Byte {
+	var out [32]vars.Byte
+	for i := range cv {
+		for j := 0; j < 4; j++ {
+			var bits [8]vars.Bool
+			for k := range bits {
+				bits[k] = cv[i][31-(8*j+k)]
+			}
+			out[4*i+j] = h.api.ToByteFromBits(bits)
+		}
+	}
+	return out
+}
+
+// Computes the BLAKE3 hash of the input bytes. Note that at compile time of the circuit, len(in)
+// must be a constant.
+func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
+	defer api.Scope("blake3")()
+	h := NewHasher(api)
+	h.Write(in)
+	return h.Sum()
+}
+
+// Computes the BLAKE3 hash of the first length bytes of in. The remaining bytes of in are
+// ignored, so len(in) is the maximum length of the message and must be a constant at compile time
+// of the circuit, while length may be any variable in [0, len(in)]. Since the shape of the tree
+// depends on the length, len(in) must be at most ChunkSize.
+func HashVariable(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	defer api.Scope("blake3")()
+	if len(in) > ChunkSize {
+		panic("the maximum length must be at most ChunkSize")
+	}
+	numBlocks := (len(in) + BlockSize - 1) / BlockSize
+	if numBlocks == 0 {
+		numBlocks = 1
+	}
+
+	// The selectors isEnd[i] are set iff i == length, and exactly one of them must be set.
+	isEnd := make([]vars.Bool, len(in)+1)
+	nbEnds := vars.ZERO
+	for i := 0; i <= len(in); i++ {
+		isEnd[i] = api.IsZero(api.Sub(length, vars.NewVariableFromInt(i)))
+		nbEnds = api.Add(nbEnds, isEnd[i].Value)
+	}
+	api.AssertIsEqual(nbEnds, vars.ONE)
+
+	// The block i is the last one iff length is in (BlockSize * i, BlockSize * (i + 1)], or if it
+	// is the first block and the message is empty.
+	isLastBlock := make([]vars.Bool, numBlocks)
+	for i := range isLastBlock {
+		isLast := vars.ZERO
+		for j := BlockSize*i + 1; j <= BlockSize*(i+1) && j <= len(in); j++ {
+			isLast = api.Add(isLast, isEnd[j].Value)
+		}
+		if i == 0 {
+			isLast = api.Add(isLast, isEnd[0].Value)
+		}
+		isLastBlock[i] = vars.Bool{Value: isLast}
+	}
+
+	// The bytes of the message after length are zeroed, and the last block has the remaining
+	// length and the flags which end the chunk and mark the root.
+	h := NewHasher(api)
+	cv := h.cv
+	var result [8]word
+	isMessage := vars.ONE
+	for i := 0; i < numBlocks; i++ {
+		block := make([]vars.Byte, BlockSize)
+		for j := range block {
+			k := BlockSize*i + j
+			if k <= len(in) {
+				isMessage = api.Sub(isMessage, isEnd[k].Value)
+			}
+			if k < len(in) {
+				block[j] = vars.Byte{Value: api.Mul(isMessage, in[k].Value)}
+			} else {
+				block[j] = vars.ZERO_BYTE
+			}
+		}
+		blockLen := api.Select(isLastBlock[i], api.Sub(length, vars.NewVariableFromInt(BlockSize*i)), vars.NewVariableFromInt(BlockSize))
+		var blockLenWord word
+		copy(blockLenWord[:], api.ToBinaryBE(blockLen, 32))
+		flags := constantWord(0)
+		if i == 0 {
+			flags = constantWord(flagChunkStart)
+		}
+		flags[31-1] = isLastBlock[i]
+		flags[31-3] = isLastBlock[i]
+		cv = h.chainingValue(output{cv: cv, block: toBlock(api, block), blockLen: blockLenWord, flags: flags})
+		for j := range result {
+			for k := range result[j] {
+				if i == 0 {
+					result[j][k] = cv[j][k]
+				} else {
+					result[j][k] = vars.Bool{Value: api.Select(isLastBlock[i], cv[j][k].Value, result[j][k].Value)}
+				}
+			}
+		}
+	}
+	return h.digest(result)
+}
+
+// Computes the BLAKE3 hash of variable bytes.
+func HashVariableBytes(api builder.API, in vars.VariableBytes) [32]vars.Byte {
+	return HashVariable(api, in.Data, in.Length)
+}
+
+// Converts at most BlockSize bytes to the little-endian words of a block padded with zeros.
+func toBlock(api builder.API, in []vars.Byte) [16]word {
+	var block [16]word
+	for i := range block {
+		for j := 0; j < 4; j++ {
+			var bits [8]vars.Bool
+			if k := 4*i + j; k < len(in) {
+				bits = api.ToBitsFromByte(in[k])
+			} else {
+				for l := range bits {
+					bits[l] = vars.FALSE
+				}
+			}
+			for l := range bits {
+				block[i][31-(8*j+l)] = bits[l]
+			}
+		}
+	}
+	return block
+}
+
+func constantWord(value uint32) word {
+	return vars.NewBoolArrayFromU32(value)
+}
+
+func constantCV() [8]word {
+	var cv [8]word
+	for i := range cv {
+		cv[i] = constantWord(IV[i])
+	}
+	return cv
+}
@@ -0,0 +1,122 @@
+package blake3
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestBlake3Circuit struct {
+	In  []vars.Byte
+	Out [32]vars.Byte
+	// If set, the input is written to the hasher in chunks of this size.
+	ChunkSize int `gnark:"-"`
+}
+
+func (circuit *TestBlake3Circuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	var res [32]vars.Byte
+	if circuit.ChunkSize == 0 {
+		res = Hash(*succinctAPI, circuit.In)
+	} else {
+		hasher := NewHasher(*succinctAPI)
+		for i := 0; i < len(circuit.In); i += circuit.ChunkSize {
+			end := i + circuit.ChunkSize
+			if end > len(circuit.In) {
+				end = len(circuit.In)
+			}
+			hasher.Write(circuit.In[i:end])
+		}
+		res = hasher.Sum()
+	}
+	for i := range res {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+type TestBlake3VariableCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+}
+
+func (circuit *TestBlake3VariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	for i := range res {
+		succinctAPI.AssertIsEqualByte(res[i], circuit.Out[i])
+	}
+	return nil
+}
+
+func newBytes32(in [32]byte) [32]vars.Byte {
+	var out [32]vars.Byte
+	vars.SetBytes32(&out, in)
+	return out
+}
+
+func TestHashNative(t *testing.T) {
+	// The test vectors of the reference implementation.
+	for in, expected := range map[string]string{
+		"":    "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+		"abc": "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85",
+	} {
+		out := HashNative([]byte(in))
+		if hex.EncodeToString(out[:]) != expected {
+			t.Errorf("unexpected hash %x of %q", out, in)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(in []byte, chunkSize int) {
+		out := HashNative(in)
+		circuit := TestBlake3Circuit{In: vars.NewBytesFrom(in), Out: newBytes32(out), ChunkSize: chunkSize}
+		witness := TestBlake3Circuit{In: vars.NewBytesFrom(in), Out: newBytes32(out)}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+		out[0] ^= 1
+		witness = TestBlake3Circuit{In: vars.NewBytesFrom(in), Out: newBytes32(out)}
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+
+	testCase([]byte(""), 0)
+	testCase([]byte("abc"), 0)
+	// A full chunk, and three chunks written in pieces, which merges two of them in the tree.
+	full := make([]byte, ChunkSize)
+	for i := range full {
+		full[i] = byte(i % 251)
+	}
+	testCase(full, 0)
+	long := make([]byte, 2*ChunkSize+100)
+	for i := range long {
+		long[i] = byte(i % 251)
+	}
+	testCase(long, 300)
+}
+
+func TestHashVariable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := make([]byte, 3*BlockSize)
+	for i := range in {
+		in[i] = byte(i * 13)
+	}
+	circuit := TestBlake3VariableCircuit{In: make([]vars.Byte, len(in))}
+	for _, length := range []int{0, 5, BlockSize, BlockSize + 1, len(in)} {
+		witness := TestBlake3VariableCircuit{
+			In:     vars.NewBytesFrom(in),
+			Length: vars.NewVariableFromInt(length),
+			Out:    newBytes32(HashVariableNative(in, length)),
+		}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+}
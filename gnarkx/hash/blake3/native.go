@@ -0,0 +1,135 @@
+package blake3
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Computes the BLAKE3 hash of the input bytes like Hash.
+func HashNative(in []byte) [32]byte {
+	// Compress all chunks but the last one, merging the chaining values of complete subtrees.
+	var stack [][8]uint32
+	nbChunks := (len(in) + ChunkSize - 1) / ChunkSize
+	if nbChunks == 0 {
+		// The empty input is a single empty chunk.
+		nbChunks = 1
+	}
+	for i := 0; i < nbChunks-1; i++ {
+		cv := chunkNative(in[i*ChunkSize:(i+1)*ChunkSize], uint64(i)).chainingValue()
+		for total := uint64(i + 1); total&1 == 0; total >>= 1 {
+			cv = parentNative(stack[len(stack)-1], cv).chainingValue()
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, cv)
+	}
+
+	// The root is the last chunk if it is the only one, and otherwise the parent of the subtrees
+	// on the stack and the last chunk.
+	out := chunkNative(in[(nbChunks-1)*ChunkSize:], uint64(nbChunks-1))
+	for i := len(stack) - 1; i >= 0; i-- {
+		out = parentNative(stack[i], out.chainingValue())
+	}
+	out.flags |= flagRoot
+	var digest [32]byte
+	for i, word := range out.compress() {
+		if i < 8 {
+			binary.LittleEndian.PutUint32(digest[4*i:], word)
+		}
+	}
+	return digest
+}
+
+// Computes the BLAKE3 hash of the first length bytes of in like HashVariable.
+func HashVariableNative(in []byte, length int) [32]byte {
+	if length < 0 || length > len(in) {
+		panic("length is out of range")
+	}
+	return HashNative(in[:length])
+}
+
+// The input of a compression, which outputs either a chaining value or the root digest.
+type outputNative struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o outputNative) chainingValue() [8]uint32 {
+	out := o.compress()
+	var cv [8]uint32
+	copy(cv[:], out[:8])
+	return cv
+}
+
+func (o outputNative) compress() [16]uint32 {
+	v := [16]uint32{
+		o.cv[0], o.cv[1], o.cv[2], o.cv[3], o.cv[4], o.cv[5], o.cv[6], o.cv[7],
+		IV[0], IV[1], IV[2], IV[3],
+		uint32(o.counter), uint32(o.counter >> 32), o.blockLen, o.flags,
+	}
+	m := o.block
+	g := func(a, b, c, d int, x, y uint32) {
+		v[a] += v[b] + x
+		v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+		v[c] += v[d]
+		v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+		v[a] += v[b] + y
+		v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+		v[c] += v[d]
+		v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+	}
+	for round := 0; round < 7; round++ {
+		g(0, 4, 8, 12, m[0], m[1])
+		g(1, 5, 9, 13, m[2], m[3])
+		g(2, 6, 10, 14, m[4], m[5])
+		g(3, 7, 11, 15, m[6], m[7])
+		g(0, 5, 10, 15, m[8], m[9])
+		g(1, 6, 11, 12, m[10], m[11])
+		g(2, 7, 8, 13, m[12], m[13])
+		g(3, 4, 9, 14, m[14], m[15])
+		var permuted [16]uint32
+		for i := range permuted {
+			permuted[i] = m[permutation[i]]
+		}
+		m = permuted
+	}
+	for i := 0; i < 8; i++ {
+		v[i] ^= v[i+8]
+		v[i+8] ^= o.cv[i]
+	}
+	return v
+}
+
+// Returns the output of the last block of a chunk of at most ChunkSize bytes.
+func chunkNative(in []byte, counter uint64) outputNative {
+	cv := IV
+	flags := flagChunkStart
+	for len(in) > BlockSize {
+		o := outputNative{cv: cv, block: blockNative(in[:BlockSize]), counter: counter, blockLen: BlockSize, flags: flags}
+		cv = o.chainingValue()
+		flags = 0
+		in = in[BlockSize:]
+	}
+	return outputNative{cv: cv, block: blockNative(in), counter: counter, blockLen: uint32(len(in)), flags: flags | flagChunkEnd}
+}
+
+// Returns the output of the parent of two chaining values.
+func parentNative(left, right [8]uint32) outputNative {
+	var block [16]uint32
+	copy(block[:8], left[:])
+	copy(block[8:], right[:])
+	return outputNative{cv: IV, block: block, blockLen: BlockSize, flags: flagParent}
+}
+
+// Converts at most BlockSize bytes to the little-endian words of a block padded with zeros.
+func blockNative(in []byte) [16]uint32 {
+	var padded [BlockSize]byte
+	copy(padded[:], in)
+	var block [16]uint32
+	for i := range block {
+		block[i] = binary.LittleEndian.Uint32(padded[4*i:])
+	}
+	return block
+}
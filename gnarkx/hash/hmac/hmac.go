@@ -0,0 +1,66 @@
+// The API for HMAC according to https://datatracker.ietf.org/doc/html/rfc2104, instantiated with
+// SHA256-2.
+package hmac
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The block size of SHA256-2 in bytes.
+const blockSize = 64
+
+// The inner and outer padding bytes.
+const ipad = 0x36
+const opad = 0x5c
+
+// Computes HMAC-SHA256(key, in). Note that at compile time of the circuit, len(key) and len(in)
+// must be constants.
+func Sha256(api builder.API, key []vars.Byte, in []vars.Byte) [32]vars.Byte {
+	k := normalizeKey(api, key)
+	inner := sha256.Hash(api, append(xorKey(api, k, ipad), in...))
+	return sha256.Hash(api, append(xorKey(api, k, opad), inner[:]...))
+}
+
+// Computes HMAC-SHA256(key, in[:length]). The remaining bytes of in are ignored, so len(in) is
+// the maximum length of the message and must be a constant at compile time of the circuit, while
+// length may be any variable in [0, len(in)].
+func Sha256Variable(api builder.API, key []vars.Byte, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	k := normalizeKey(api, key)
+	innerLength := api.Add(length, vars.NewVariableFromInt(blockSize))
+	inner := sha256.HashVariable(api, append(xorKey(api, k, ipad), in...), innerLength)
+	return sha256.Hash(api, append(xorKey(api, k, opad), inner[:]...))
+}
+
+// Hashes keys longer than the block size and pads the key with zeros to the block size.
+func normalizeKey(api builder.API, key []vars.Byte) []vars.Byte {
+	if len(key) > blockSize {
+		digest := sha256.Hash(api, key)
+		key = digest[:]
+	}
+	result := make([]vars.Byte, blockSize)
+	for i := 0; i < blockSize; i++ {
+		if i < len(key) {
+			result[i] = key[i]
+		} else {
+			result[i] = vars.ZERO_BYTE
+		}
+	}
+	return result
+}
+
+// Computes the xor of every byte of the key with a constant padding byte.
+func xorKey(api builder.API, key []vars.Byte, pad byte) []vars.Byte {
+	result := make([]vars.Byte, len(key))
+	for i := 0; i < len(key); i++ {
+		bits := api.ToBitsFromByte(key[i])
+		for j := 0; j < 8; j++ {
+			if pad&(1<<j) != 0 {
+				bits[j] = api.Not(bits[j])
+			}
+		}
+		result[i] = api.ToByteFromBits(bits)
+	}
+	return result
+}
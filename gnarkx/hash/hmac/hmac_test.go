@@ -0,0 +1,97 @@
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestHmacCircuit struct {
+	Key    []vars.Byte
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+	Fixed  bool `gnark:"-"`
+}
+
+func (circuit *TestHmacCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	var res [32]vars.Byte
+	if circuit.Fixed {
+		res = Sha256(*succinctAPI, circuit.Key, circuit.In)
+	} else {
+		res = Sha256Variable(*succinctAPI, circuit.Key, circuit.In, circuit.Length)
+	}
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqual(res[i].Value, circuit.Out[i].Value)
+	}
+	return nil
+}
+
+func newHmacCircuit(key []byte, in []byte, length int, fixed bool) *TestHmacCircuit {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(in[:length])
+	var out [32]byte
+	copy(out[:], mac.Sum(nil))
+	var digest [32]vars.Byte
+	vars.SetBytes32(&digest, out)
+	return &TestHmacCircuit{
+		Key:    vars.NewBytesFrom(key),
+		In:     vars.NewBytesFrom(in),
+		Length: vars.NewVariableFromInt(length),
+		Out:    digest,
+		Fixed:  fixed,
+	}
+}
+
+func TestHmacSha256(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Keys shorter than, equal to and longer than the block size.
+	in := []byte("what do ya want for nothing?")
+	for _, key := range [][]byte{[]byte("Jefe"), make([]byte, blockSize), make([]byte, 100)} {
+		circuit := newHmacCircuit(key, in, len(in), true)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestHmacSha256Variable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	key := []byte("succinct")
+	in := []byte(`{"slot":7340032,"root":"0x5e1b","signature":"0xa1"}`)
+	for _, length := range []int{0, 10, len(in)} {
+		circuit := newHmacCircuit(key, in, length, false)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// The MAC of a different length is rejected.
+	circuit := newHmacCircuit(key, in, 10, false)
+	witness := newHmacCircuit(key, in, 10, false)
+	witness.Length = vars.NewVariableFromInt(11)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
+
+func TestHmacSha256Native(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The gadgets agree with their native counterparts on random keys and messages.
+	rng := rand.New(rand.NewSource(0))
+	for _, keyLength := range []int{8, 100} {
+		key := make([]byte, keyLength)
+		in := make([]byte, 80)
+		rng.Read(key)
+		rng.Read(in)
+		length := rng.Intn(len(in) + 1)
+		circuit := newHmacCircuit(key, in, length, false)
+		vars.SetBytes32(&circuit.Out, Sha256VariableNative(key, in, length))
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+}
@@ -0,0 +1,23 @@
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// Computes HMAC-SHA256(key, in) like Sha256.
+func Sha256Native(key []byte, in []byte) [32]byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(in)
+	var out [32]byte
+	copy(out[:], mac.Sum(nil))
+	return out
+}
+
+// Computes HMAC-SHA256(key, in[:length]) like Sha256Variable.
+func Sha256VariableNative(key []byte, in []byte, length int) [32]byte {
+	if length < 0 || length > len(in) {
+		panic("length is out of range")
+	}
+	return Sha256Native(key, in[:length])
+}
@@ -0,0 +1,234 @@
+// The API for Keccak-256 according to https://keccak.team/keccak_specs_summary.html, with the
+// original Keccak padding used by Ethereum (not the SHA3-256 padding).
+package keccak256
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/bits64"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The number of bytes absorbed per permutation, i.e. (1600 - 2 * 256) / 8.
+const Rate = 136
+
+// The round constants of the iota step.
+var RC = []uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// The rotation offsets of the rho step, indexed by x + 5 * y.
+var R = []int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// Hasher computes a Keccak-256 hash incrementally. The state is 25 lanes of 64 bits, stored as
+// big-endian bit arrays like in bits64, and lane x + 5 * y is A[x, y] in the specification.
+// Note that at compile time of the circuit, the total length of the input must be a constant.
+type Hasher struct {
+	api    builder.API
+	bits64 bits64.API
+	state  [25][64]vars.Bool
+	buffer []vars.Byte
+}
+
+// Creates a new Hasher with the zero state.
+func NewHasher(api builder.API) *Hasher {
+	h := &Hasher{api: api, bits64: bits64.NewAPI(api)}
+	for i := 0; i < 25; i++ {
+		for j := 0; j < 64; j++ {
+			h.state[i][j] = vars.FALSE
+		}
+	}
+	return h
+}
+
+// Absorbs the input bytes. The permutation is applied whenever a full block of Rate bytes has
+// been written.
+func (h *Hasher) Write(in []vars.Byte) {
+	h.buffer = append(h.buffer, in...)
+	for len(h.buffer) >= Rate {
+		h.absorb(h.buffer[:Rate])
+		h.buffer = h.buffer[Rate:]
+	}
+}
+
+// Pads the remaining input, absorbs it and squeezes the 32 byte digest. The Hasher must not be
+// used afterwards.
+func (h *Hasher) Sum() [32]vars.Byte {
+	block := make([]vars.Byte, Rate)
+	copy(block, h.buffer)
+	for i := len(h.buffer); i < Rate; i++ {
+		block[i] = vars.ZERO_BYTE
+	}
+	// The padding is 0x01 after the message and 0x80 at the end of the block, or 0x81 if the two
+	// coincide.
+	if len(h.buffer) == Rate-1 {
+		block[Rate-1] = vars.Byte{Value: vars.NewVariableFromInt(0x81)}
+	} else {
+		block[len(h.buffer)] = vars.Byte{Value: vars.ONE}
+		block[Rate-1] = vars.Byte{Value: vars.NewVariableFromInt(0x80)}
+	}
+	h.absorb(block)
+	h.buffer = nil
+	return h.digest()
+}
+
+// Squeezes the 32 byte digest from the state.
+func (h *Hasher) digest() [32]vars.Byte {
+	var digest [32]vars.Byte
+	for i := 0; i < 32; i++ {
+		lane := h.state[i/8]
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			byteBits[j] = lane[63-(8*(i%8)+j)]
+		}
+		digest[i] = h.api.ToByteFromBits(byteBits)
+	}
+	return digest
+}
+
+// Computes the Keccak-256 hash of the input bytes. Note that at compile time of the circuit,
+// len(in) must be a constant.
+func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
+	defer api.Scope("keccak256")()
+	h := NewHasher(api)
+	h.Write(in)
+	return h.Sum()
+}
+
+// Computes the Keccak-256 hash of the first length bytes of in. The remaining bytes of in are
+// ignored, so len(in) is the maximum length of the message and must be a constant at compile time
+// of the circuit, while length may be any variable in [0, len(in)].
+func HashVariable(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	defer api.Scope("keccak256")()
+	numBlocks := len(in)/Rate + 1
+
+	// The selectors isEnd[i] are set iff i == length, and exactly one of them must be set.
+	isEnd := make([]vars.Bool, len(in)+1)
+	nbEnds := vars.ZERO
+	for i := 0; i <= len(in); i++ {
+		isEnd[i] = api.IsZero(api.Sub(length, vars.NewVariableFromInt(i)))
+		nbEnds = api.Add(nbEnds, isEnd[i].Value)
+	}
+	api.AssertIsEqual(nbEnds, vars.ONE)
+
+	// The block i is the last one iff length is in [Rate * i, Rate * (i + 1)).
+	isLastBlock := make([]vars.Bool, numBlocks)
+	for i := 0; i < numBlocks; i++ {
+		isLast := vars.ZERO
+		for j := Rate * i; j < Rate*(i+1) && j <= len(in); j++ {
+			isLast = api.Add(isLast, isEnd[j].Value)
+		}
+		isLastBlock[i] = vars.Bool{Value: isLast}
+	}
+
+	// Each byte of the padded message is either a byte of the message, the 0x01 separator, the
+	// final 0x80 of the last block or zero. The separator and the final byte may coincide.
+	h := NewHasher(api)
+	var state [25][64]vars.Bool
+	isMessage := vars.ONE
+	for i := 0; i < numBlocks; i++ {
+		block := make([]vars.Byte, Rate)
+		for j := 0; j < Rate; j++ {
+			k := Rate*i + j
+			value := vars.ZERO
+			if k <= len(in) {
+				isMessage = api.Sub(isMessage, isEnd[k].Value)
+				value = isEnd[k].Value
+			}
+			if k < len(in) {
+				value = api.Add(value, api.Mul(isMessage, in[k].Value))
+			}
+			if j == Rate-1 {
+				value = api.Add(value, api.Mul(isLastBlock[i].Value, vars.NewVariableFromInt(0x80)))
+			}
+			block[j] = vars.Byte{Value: value}
+		}
+		h.absorb(block)
+		for j := 0; j < 25; j++ {
+			for k := 0; k < 64; k++ {
+				if i == 0 {
+					state[j][k] = h.state[j][k]
+				} else {
+					state[j][k] = vars.Bool{Value: api.Select(isLastBlock[i], h.state[j][k].Value, state[j][k].Value)}
+				}
+			}
+		}
+	}
+	h.state = state
+	return h.digest()
+}
+
+// Computes the Keccak-256 hash of variable bytes.
+func HashVariableBytes(api builder.API, in vars.VariableBytes) [32]vars.Byte {
+	return HashVariable(api, in.Data, in.Length)
+}
+
+// Xors a block of Rate bytes into the state and applies the permutation.
+func (h *Hasher) absorb(block []vars.Byte) {
+	for i := 0; i < Rate/8; i++ {
+		var lane [64]vars.Bool
+		for j := 0; j < 8; j++ {
+			bits := h.api.ToBitsFromByte(block[8*i+j])
+			for k := 0; k < 8; k++ {
+				lane[63-(8*j+k)] = bits[k]
+			}
+		}
+		h.state[i] = h.bits64.Xor64(h.state[i], lane)
+	}
+	h.permute()
+}
+
+// Applies the Keccak-f[1600] permutation to the state.
+func (h *Hasher) permute() {
+	rotl := func(lane [64]vars.Bool, offset int) [64]vars.Bool {
+		return h.bits64.Rotate64(lane, 64-offset)
+	}
+	a := h.state
+	for round := 0; round < 24; round++ {
+		// θ step.
+		var c [5][64]vars.Bool
+		for x := 0; x < 5; x++ {
+			c[x] = h.bits64.Xor64(a[x], a[x+5], a[x+10], a[x+15], a[x+20])
+		}
+		for x := 0; x < 5; x++ {
+			d := h.bits64.Xor64(c[(x+4)%5], rotl(c[(x+1)%5], 1))
+			for y := 0; y < 5; y++ {
+				a[x+5*y] = h.bits64.Xor64(a[x+5*y], d)
+			}
+		}
+
+		// ρ and π steps.
+		var b [25][64]vars.Bool
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = rotl(a[x+5*y], R[x+5*y])
+			}
+		}
+
+		// χ step.
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				notB := h.bits64.Not64(b[(x+1)%5+5*y])
+				a[x+5*y] = h.bits64.Xor64(b[x+5*y], h.bits64.And64(notB, b[(x+2)%5+5*y]))
+			}
+		}
+
+		// ι step. Xoring a constant bit is either the identity or a negation.
+		for i := 0; i < 64; i++ {
+			if RC[round]&(1<<i) != 0 {
+				a[0][63-i] = h.api.Not(a[0][63-i])
+			}
+		}
+	}
+	h.state = a
+}
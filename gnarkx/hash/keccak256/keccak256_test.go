@@ -0,0 +1,153 @@
+package keccak256
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestKeccak256Circuit struct {
+	In  []vars.Byte `gnark:"in"`
+	Out []vars.Byte `gnark:"out"`
+	// If set, the input is written to the hasher in chunks of this size.
+	ChunkSize int `gnark:"-"`
+}
+
+func (circuit *TestKeccak256Circuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	var res [32]vars.Byte
+	if circuit.ChunkSize == 0 {
+		res = Hash(*succinctAPI, circuit.In)
+	} else {
+		hasher := NewHasher(*succinctAPI)
+		for i := 0; i < len(circuit.In); i += circuit.ChunkSize {
+			end := i + circuit.ChunkSize
+			if end > len(circuit.In) {
+				end = len(circuit.In)
+			}
+			hasher.Write(circuit.In[i:end])
+		}
+		res = hasher.Sum()
+	}
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqual(res[i].Value, circuit.Out[i].Value)
+	}
+	return nil
+}
+
+func TestKeccak256Witness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(in []byte, chunkSize int) {
+		out := crypto.Keccak256(in)
+		circuit := TestKeccak256Circuit{
+			In:        vars.NewBytesFrom(in),
+			Out:       vars.NewBytesFrom(out),
+			ChunkSize: chunkSize,
+		}
+		witness := TestKeccak256Circuit{
+			In:  vars.NewBytesFrom(in),
+			Out: vars.NewBytesFrom(out),
+		}
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+
+	testCase([]byte(""), 0)
+	testCase([]byte("Succinct Labs"), 0)
+	// One byte short of a block, where both padding bytes coincide.
+	testCase(make([]byte, Rate-1), 0)
+	// More than one block, written in chunks that do not align with the rate.
+	long := make([]byte, 2*Rate+17)
+	for i := range long {
+		long[i] = byte(i * 7)
+	}
+	testCase(long, 50)
+}
+
+func TestKeccak256WrongOutput(t *testing.T) {
+	assert := test.NewAssert(t)
+	in := []byte("Succinct Labs")
+	out := crypto.Keccak256(in)
+	out[0] ^= 1
+	circuit := TestKeccak256Circuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out)}
+	witness := TestKeccak256Circuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out)}
+	assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+}
+
+type TestKeccak256VariableCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+}
+
+func (circuit *TestKeccak256VariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqual(res[i].Value, circuit.Out[i].Value)
+	}
+	return nil
+}
+
+func TestKeccak256VariableWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The message is padded with junk bytes up to the maximum length, which must be ignored.
+	const maxLength = 2*Rate + 5
+	data := make([]byte, maxLength)
+	for i := range data {
+		data[i] = byte(7*i + 3)
+	}
+	newCircuit := func(length int, out []byte) *TestKeccak256VariableCircuit {
+		var digest [32]vars.Byte
+		var value [32]byte
+		copy(value[:], out)
+		vars.SetBytes32(&digest, value)
+		return &TestKeccak256VariableCircuit{
+			In:     vars.NewBytesFrom(data),
+			Length: vars.NewVariableFromInt(length),
+			Out:    digest,
+		}
+	}
+
+	// Cover the lengths around the block boundaries, including the one where the separator and
+	// the final padding byte coincide.
+	for _, length := range []int{0, 1, Rate - 1, Rate, Rate + 1, 2*Rate - 1, maxLength} {
+		circuit := newCircuit(length, nil)
+		witness := newCircuit(length, crypto.Keccak256(data[:length]))
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+	}
+
+	// A digest of a different length or a length out of range is rejected.
+	circuit := newCircuit(0, nil)
+	assert.Error(test.IsSolved(circuit, newCircuit(10, crypto.Keccak256(data[:11])), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(circuit, newCircuit(maxLength+1, crypto.Keccak256(data)), ecc.BN254.ScalarField()))
+}
+
+func TestKeccak256Native(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The digest of the empty message.
+	digest := HashNative(nil)
+	assert.Equal("c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(digest[:]))
+
+	// The gadgets agree with their native counterparts on random messages.
+	rng := rand.New(rand.NewSource(0))
+	data := make([]byte, Rate+20)
+	rng.Read(data)
+	circuit := &TestKeccak256VariableCircuit{In: vars.NewBytes(len(data))}
+	for i := 0; i < 3; i++ {
+		length := rng.Intn(len(data) + 1)
+		witness := &TestKeccak256VariableCircuit{In: vars.NewBytesFrom(data), Length: vars.NewVariableFromInt(length)}
+		vars.SetBytes32(&witness.Out, HashVariableNative(data, length))
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+	}
+}
@@ -0,0 +1,18 @@
+package keccak256
+
+import (
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Computes the Keccak-256 hash of the input bytes like Hash.
+func HashNative(in []byte) [32]byte {
+	return crypto.Keccak256Hash(in)
+}
+
+// Computes the Keccak-256 hash of the first length bytes of in like HashVariable.
+func HashVariableNative(in []byte, length int) [32]byte {
+	if length < 0 || length > len(in) {
+		panic("length is out of range")
+	}
+	return crypto.Keccak256Hash(in[:length])
+}
@@ -0,0 +1,158 @@
+package poseidon
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// The number of full rounds, which is the same for every width.
+const FullRounds = 8
+
+// The number of partial rounds for the widths 2 to 17, as in circomlib.
+var PartialRounds = []int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+// The smallest and largest supported widths of the permutation.
+const MinWidth = 2
+const MaxWidth = 17
+
+// The round constants and the MDS matrix of the permutation for a given width.
+type params struct {
+	width         int
+	partialRounds int
+	c             []*big.Int
+	m             [][]*big.Int
+}
+
+var paramsCache = map[int]*params{}
+var paramsCacheLock sync.Mutex
+
+// Returns the parameters for the given width, which are generated once and cached.
+func getParams(width int) *params {
+	if width < MinWidth || width > MaxWidth {
+		panic("unsupported poseidon width")
+	}
+	paramsCacheLock.Lock()
+	defer paramsCacheLock.Unlock()
+	if p, ok := paramsCache[width]; ok {
+		return p
+	}
+	p := newParams(width)
+	paramsCache[width] = p
+	return p
+}
+
+// Generates the parameters with the Grain LFSR according to the reference implementation
+// https://extgit.iaik.tugraz.at/krypto/hadeshash/-/blob/master/code/generate_parameters_grain.sage,
+// which is also how the circomlib constants were generated.
+func newParams(width int) *params {
+	modulus := ecc.BN254.ScalarField()
+	const fieldSize = 254
+	partialRounds := PartialRounds[width-MinWidth]
+	g := newGrain(fieldSize, width, FullRounds, partialRounds)
+
+	c := make([]*big.Int, (FullRounds+partialRounds)*width)
+	for i := range c {
+		c[i] = g.nextInt(fieldSize)
+		for c[i].Cmp(modulus) >= 0 {
+			c[i] = g.nextInt(fieldSize)
+		}
+	}
+
+	// The MDS matrix is the Cauchy matrix 1 / (x_i + y_j) for distinct random x_i and y_j.
+	for {
+		xs := make([]*big.Int, 2*width)
+		for i := range xs {
+			xs[i] = new(big.Int).Mod(g.nextInt(fieldSize), modulus)
+		}
+		if !distinct(xs) {
+			continue
+		}
+		m := make([][]*big.Int, width)
+		ok := true
+		for i := 0; i < width && ok; i++ {
+			m[i] = make([]*big.Int, width)
+			for j := 0; j < width && ok; j++ {
+				sum := new(big.Int).Add(xs[i], xs[width+j])
+				sum.Mod(sum, modulus)
+				if sum.Sign() == 0 {
+					ok = false
+					break
+				}
+				m[i][j] = sum.ModInverse(sum, modulus)
+			}
+		}
+		if ok {
+			return &params{width: width, partialRounds: partialRounds, c: c, m: m}
+		}
+	}
+}
+
+func distinct(xs []*big.Int) bool {
+	for i := range xs {
+		for j := 0; j < i; j++ {
+			if xs[i].Cmp(xs[j]) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// An 80-bit Grain LFSR used to generate the parameters.
+type grain struct {
+	state []uint8
+}
+
+func newGrain(fieldSize, width, fullRounds, partialRounds int) *grain {
+	g := &grain{}
+	g.push(1, 2) // A prime field.
+	g.push(0, 4) // The x^alpha s-box.
+	g.push(fieldSize, 12)
+	g.push(width, 12)
+	g.push(fullRounds, 10)
+	g.push(partialRounds, 10)
+	g.push(1<<30-1, 30)
+	for i := 0; i < 160; i++ {
+		g.update()
+	}
+	return g
+}
+
+// Appends the nbBits lower bits of value in big-endian order to the initial state.
+func (g *grain) push(value int, nbBits int) {
+	for i := nbBits - 1; i >= 0; i-- {
+		g.state = append(g.state, uint8((value>>i)&1))
+	}
+}
+
+func (g *grain) update() uint8 {
+	s := g.state
+	bit := s[62] ^ s[51] ^ s[38] ^ s[23] ^ s[13] ^ s[0]
+	g.state = append(s[1:], bit)
+	return bit
+}
+
+// Returns the next output bit, where the bits are filtered in pairs: the second bit is output
+// iff the first one is set.
+func (g *grain) next() uint8 {
+	bit := g.update()
+	for bit == 0 {
+		g.update()
+		bit = g.update()
+	}
+	return g.update()
+}
+
+// Returns the integer formed by the next nbBits output bits in big-endian order.
+func (g *grain) nextInt(nbBits int) *big.Int {
+	result := new(big.Int)
+	for i := 0; i < nbBits; i++ {
+		result.Lsh(result, 1)
+		if g.next() == 1 {
+			result.SetBit(result, 0, 1)
+		}
+	}
+	return result
+}
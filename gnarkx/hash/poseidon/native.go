@@ -0,0 +1,98 @@
+package poseidon
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Applies the Poseidon permutation to the state like Permute.
+func PermuteNative(state []*big.Int) []*big.Int {
+	p := getParams(len(state))
+	modulus := ecc.BN254.ScalarField()
+	result := make([]*big.Int, p.width)
+	for i := range result {
+		result[i] = new(big.Int).Mod(state[i], modulus)
+	}
+	for round := 0; round < FullRounds+p.partialRounds; round++ {
+		for i := range result {
+			result[i].Add(result[i], p.c[round*p.width+i])
+			if round < FullRounds/2 || round >= FullRounds/2+p.partialRounds || i == 0 {
+				result[i].Exp(result[i], big.NewInt(5), modulus)
+			}
+		}
+		next := make([]*big.Int, p.width)
+		for i := range next {
+			next[i] = new(big.Int)
+			for j := range result {
+				next[i].Add(next[i], new(big.Int).Mul(p.m[i][j], result[j]))
+			}
+			next[i].Mod(next[i], modulus)
+		}
+		result = next
+	}
+	return result
+}
+
+// Computes the Poseidon hash of the inputs like Hash.
+func HashNative(in ...*big.Int) *big.Int {
+	state := make([]*big.Int, len(in)+1)
+	state[0] = new(big.Int)
+	copy(state[1:], in)
+	return PermuteNative(state)[0]
+}
+
+// NativeSponge is the out-of-circuit counterpart of Sponge.
+type NativeSponge struct {
+	state     []*big.Int
+	absorbed  int
+	squeezed  int
+	squeezing bool
+}
+
+// Creates a new NativeSponge for the permutation of the given width with the zero state.
+func NewNativeSponge(width int) *NativeSponge {
+	getParams(width)
+	state := make([]*big.Int, width)
+	for i := range state {
+		state[i] = new(big.Int)
+	}
+	return &NativeSponge{state: state}
+}
+
+// Returns the number of elements absorbed or squeezed per permutation.
+func (s *NativeSponge) Rate() int {
+	return len(s.state) - 1
+}
+
+// Absorbs the inputs like Sponge.Absorb.
+func (s *NativeSponge) Absorb(in ...*big.Int) {
+	if s.squeezing {
+		s.squeezing = false
+		s.absorbed = 0
+	}
+	for _, v := range in {
+		s.state[1+s.absorbed] = new(big.Int).Add(s.state[1+s.absorbed], v)
+		s.absorbed++
+		if s.absorbed == s.Rate() {
+			s.state = PermuteNative(s.state)
+			s.absorbed = 0
+		}
+	}
+}
+
+// Squeezes an element out of the rate like Sponge.Squeeze.
+func (s *NativeSponge) Squeeze() *big.Int {
+	if !s.squeezing {
+		s.state[1+s.absorbed] = new(big.Int).Add(s.state[1+s.absorbed], big.NewInt(1))
+		s.state = PermuteNative(s.state)
+		s.squeezing = true
+		s.squeezed = 0
+	} else if s.squeezed == s.Rate() {
+		s.state = PermuteNative(s.state)
+		s.squeezed = 0
+	}
+	result := new(big.Int).Set(s.state[1+s.squeezed])
+	s.squeezed++
+	return result
+}
@@ -0,0 +1,71 @@
+// The API for the Poseidon hash function over the BN254 scalar field according to
+// https://eprint.iacr.org/2019/458.pdf, with the same parameters as circomlib. Since Poseidon
+// works on native field elements, it is far cheaper inside circuits than bitwise hashes such as
+// SHA256-2 or Keccak-256.
+package poseidon
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Applies the Poseidon permutation to the state, whose length is the width of the permutation.
+func Permute(api builder.API, state []vars.Variable) []vars.Variable {
+	defer api.Scope("poseidon")()
+	if api.FrontendAPI().Compiler().Field().Cmp(ecc.BN254.ScalarField()) != 0 {
+		panic("poseidon is only supported over the BN254 scalar field")
+	}
+	p := getParams(len(state))
+	result := make([]vars.Variable, p.width)
+	copy(result, state)
+	for round := 0; round < FullRounds+p.partialRounds; round++ {
+		for i := 0; i < p.width; i++ {
+			result[i] = api.Add(result[i], constant(p.c[round*p.width+i]))
+		}
+		if round < FullRounds/2 || round >= FullRounds/2+p.partialRounds {
+			for i := 0; i < p.width; i++ {
+				result[i] = exp5(api, result[i])
+			}
+		} else {
+			result[0] = exp5(api, result[0])
+		}
+		result = mix(api, p, result)
+	}
+	return result
+}
+
+// Computes the Poseidon hash of the inputs with the permutation of width len(in) + 1, which is
+// compatible with the Poseidon template of circomlib. At most MaxWidth - 1 inputs are supported,
+// use a Sponge to hash longer inputs.
+func Hash(api builder.API, in ...vars.Variable) vars.Variable {
+	state := make([]vars.Variable, len(in)+1)
+	state[0] = vars.ZERO
+	copy(state[1:], in)
+	return Permute(api, state)[0]
+}
+
+func exp5(api builder.API, x vars.Variable) vars.Variable {
+	x2 := api.Mul(x, x)
+	x4 := api.Mul(x2, x2)
+	return api.Mul(x4, x)
+}
+
+// Multiplies the state by the MDS matrix. The products with constants are free in the
+// constraint system.
+func mix(api builder.API, p *params, state []vars.Variable) []vars.Variable {
+	result := make([]vars.Variable, p.width)
+	for i := 0; i < p.width; i++ {
+		result[i] = vars.ZERO
+		for j := 0; j < p.width; j++ {
+			result[i] = api.Add(result[i], api.Mul(constant(p.m[i][j]), state[j]))
+		}
+	}
+	return result
+}
+
+func constant(c *big.Int) vars.Variable {
+	return vars.Variable{Value: new(big.Int).Set(c)}
+}
@@ -0,0 +1,197 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestPoseidonCircuit struct {
+	In  []vars.Variable
+	Out vars.Variable
+}
+
+func (circuit *TestPoseidonCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	succinctAPI.AssertIsEqual(Hash(*succinctAPI, circuit.In...), circuit.Out)
+	return nil
+}
+
+func TestPoseidonHash(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The test vectors of circomlib.
+	testCase := func(in []int, out string) {
+		inputs := make([]vars.Variable, len(in))
+		for i := range in {
+			inputs[i] = vars.NewVariableFromInt(in[i])
+		}
+		circuit := TestPoseidonCircuit{In: inputs, Out: vars.NewVariableFromString(out)}
+		witness := TestPoseidonCircuit{In: inputs, Out: vars.NewVariableFromString(out)}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+	testCase([]int{1, 2}, "7853200120776062878684798364095072458815029376092732009249414926327459813530")
+	testCase([]int{1, 2, 3, 4}, "18821383157269793795438455681495246036402687001665670618754263018637548127333")
+}
+
+type TestPermuteCircuit struct {
+	In  [4]vars.Variable
+	Out [4]vars.Variable
+}
+
+func (circuit *TestPermuteCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	result := Permute(*succinctAPI, circuit.In[:])
+	for i := 0; i < 4; i++ {
+		succinctAPI.AssertIsEqual(result[i], circuit.Out[i])
+	}
+	return nil
+}
+
+// The plonky2 verifier uses an optimized implementation of the same permutation of width 4, so
+// the test vectors are taken from there.
+func TestPoseidonPermute(t *testing.T) {
+	assert := test.NewAssert(t)
+	witness := TestPermuteCircuit{
+		In: [4]vars.Variable{
+			vars.NewVariableFromInt(0),
+			vars.NewVariableFromInt(1),
+			vars.NewVariableFromInt(2),
+			vars.NewVariableFromInt(3),
+		},
+		Out: [4]vars.Variable{
+			vars.NewVariableFromString("6542985608222806190361240322586112750744169038454362455181422643027100751666"),
+			vars.NewVariableFromString("3478427836468552423396868478117894008061261013954248157992395910462939736589"),
+			vars.NewVariableFromString("1904980799580062506738911865015687096398867595589699208837816975692422464009"),
+			vars.NewVariableFromString("11971464497515232077059236682405357499403220967704831154657374522418385384151"),
+		},
+	}
+	assert.NoError(test.IsSolved(&TestPermuteCircuit{}, &witness, ecc.BN254.ScalarField()))
+}
+
+type TestSpongeCircuit struct {
+	In    []vars.Variable
+	Out   []vars.Variable
+	Width int `gnark:"-"`
+}
+
+func (circuit *TestSpongeCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sponge := NewSponge(*succinctAPI, circuit.Width)
+	half := len(circuit.In) / 2
+	sponge.Absorb(circuit.In[:half]...)
+	succinctAPI.AssertIsEqual(sponge.Squeeze(), circuit.Out[0])
+	sponge.Absorb(circuit.In[half:]...)
+	for i := 1; i < len(circuit.Out); i++ {
+		succinctAPI.AssertIsEqual(sponge.Squeeze(), circuit.Out[i])
+	}
+	return nil
+}
+
+// Absorbs in[:len(in)/2], squeezes one element, absorbs the rest and squeezes nbOut - 1 elements.
+func spongeNative(width int, in []*big.Int, nbOut int) []*big.Int {
+	rate := width - 1
+	state := make([]*big.Int, width)
+	for i := range state {
+		state[i] = new(big.Int)
+	}
+	var out []*big.Int
+	squeeze := func(nb int, absorbed int) {
+		state[1+absorbed].Add(state[1+absorbed], big.NewInt(1))
+		state = PermuteNative(state)
+		for i := 0; i < nb; i++ {
+			if i > 0 && i%rate == 0 {
+				state = PermuteNative(state)
+			}
+			out = append(out, new(big.Int).Set(state[1+i%rate]))
+		}
+	}
+	absorb := func(in []*big.Int) int {
+		absorbed := 0
+		for _, v := range in {
+			state[1+absorbed].Add(state[1+absorbed], v)
+			absorbed++
+			if absorbed == rate {
+				state = PermuteNative(state)
+				absorbed = 0
+			}
+		}
+		return absorbed
+	}
+	squeeze(1, absorb(in[:len(in)/2]))
+	squeeze(nbOut-1, absorb(in[len(in)/2:]))
+	return out
+}
+
+func TestPoseidonSponge(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, width := range []int{3, 5, 9} {
+		in := make([]*big.Int, 11)
+		inputs := make([]vars.Variable, len(in))
+		for i := range in {
+			in[i] = big.NewInt(int64(i*i + 1))
+			inputs[i] = vars.Variable{Value: in[i]}
+		}
+		expected := spongeNative(width, in, 2*width)
+		outputs := make([]vars.Variable, len(expected))
+		for i := range expected {
+			outputs[i] = vars.Variable{Value: expected[i]}
+		}
+		circuit := TestSpongeCircuit{In: inputs, Out: outputs, Width: width}
+		assert.NoError(test.IsSolved(&circuit, &circuit, ecc.BN254.ScalarField()))
+
+		// Trailing zeros change the output.
+		circuit.In = append(inputs, vars.ZERO)
+		assert.Error(test.IsSolved(&circuit, &circuit, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestPoseidonWidths(t *testing.T) {
+	assert := test.NewAssert(t)
+	for width := MinWidth; width <= MaxWidth; width++ {
+		p := getParams(width)
+		assert.Equal((FullRounds+p.partialRounds)*width, len(p.c))
+		assert.Equal(width, len(p.m))
+	}
+	assert.Panics(func() { getParams(MaxWidth + 1) })
+}
+
+func TestPoseidonNative(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The test vectors of circomlib and of the plonky2 verifier.
+	assert.Equal("7853200120776062878684798364095072458815029376092732009249414926327459813530", HashNative(big.NewInt(1), big.NewInt(2)).String())
+	permuted := PermuteNative([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3)})
+	assert.Equal("6542985608222806190361240322586112750744169038454362455181422643027100751666", permuted[0].String())
+
+	// The native sponge matches the gadget for interleaved absorbs and squeezes.
+	in := make([]*big.Int, 7)
+	for i := range in {
+		in[i] = big.NewInt(int64(3*i + 2))
+	}
+	sponge := NewNativeSponge(3)
+	sponge.Absorb(in[:3]...)
+	expected := []*big.Int{sponge.Squeeze()}
+	sponge.Absorb(in[3:]...)
+	for i := 0; i < 4; i++ {
+		expected = append(expected, sponge.Squeeze())
+	}
+	assert.Equal(spongeNative(3, in, 5), expected)
+
+	inputs := make([]vars.Variable, len(in))
+	for i := range in {
+		inputs[i] = vars.Variable{Value: in[i]}
+	}
+	outputs := make([]vars.Variable, len(expected))
+	for i := range expected {
+		outputs[i] = vars.Variable{Value: expected[i]}
+	}
+	circuit := TestSpongeCircuit{In: inputs, Out: outputs, Width: 3}
+	assert.NoError(test.IsSolved(&circuit, &circuit, ecc.BN254.ScalarField()))
+}
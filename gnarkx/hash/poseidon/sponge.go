@@ -0,0 +1,69 @@
+package poseidon
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Sponge is a duplex sponge over the Poseidon permutation. The first element of the state is the
+// capacity and the remaining width - 1 elements are the rate. Absorbing and squeezing may be
+// interleaved, and every squeeze commits to everything absorbed before it.
+type Sponge struct {
+	api       builder.API
+	state     []vars.Variable
+	absorbed  int
+	squeezed  int
+	squeezing bool
+}
+
+// Creates a new Sponge for the permutation of the given width with the zero state.
+func NewSponge(api builder.API, width int) *Sponge {
+	getParams(width)
+	state := make([]vars.Variable, width)
+	for i := range state {
+		state[i] = vars.ZERO
+	}
+	return &Sponge{api: api, state: state}
+}
+
+// Returns the number of elements absorbed or squeezed per permutation.
+func (s *Sponge) Rate() int {
+	return len(s.state) - 1
+}
+
+// Absorbs the inputs into the rate, applying the permutation whenever the rate is full.
+func (s *Sponge) Absorb(in ...vars.Variable) {
+	if s.squeezing {
+		s.squeezing = false
+		s.absorbed = 0
+	}
+	for _, v := range in {
+		s.state[1+s.absorbed] = s.api.Add(s.state[1+s.absorbed], v)
+		s.absorbed++
+		if s.absorbed == s.Rate() {
+			s.permute()
+			s.absorbed = 0
+		}
+	}
+}
+
+// Squeezes an element out of the rate. When switching from absorbing to squeezing, the absorbed
+// inputs are padded with a single one, so inputs which differ by trailing zeros are separated.
+func (s *Sponge) Squeeze() vars.Variable {
+	if !s.squeezing {
+		s.state[1+s.absorbed] = s.api.Add(s.state[1+s.absorbed], vars.ONE)
+		s.permute()
+		s.squeezing = true
+		s.squeezed = 0
+	} else if s.squeezed == s.Rate() {
+		s.permute()
+		s.squeezed = 0
+	}
+	result := s.state[1+s.squeezed]
+	s.squeezed++
+	return result
+}
+
+func (s *Sponge) permute() {
+	s.state = Permute(s.api, s.state)
+}
@@ -0,0 +1,38 @@
+package sha256
+
+import (
+	"crypto/sha256"
+	"math/big"
+)
+
+// The out-of-circuit counterparts of the gadgets of this package, which compute the same values
+// from Go types. They can be used to precompute the expected outputs of a circuit and serve as
+// the ground truth for the gadgets in tests.
+
+// Computes the SHA256-2 hash of the input bytes like Hash.
+func HashNative(in []byte) [32]byte {
+	return sha256.Sum256(in)
+}
+
+// Computes the SHA256-2 hash of the first length bytes of in like HashVariable.
+func HashVariableNative(in []byte, length int) [32]byte {
+	if length < 0 || length > len(in) {
+		panic("length is out of range")
+	}
+	return sha256.Sum256(in[:length])
+}
+
+// Computes SHA256(SHA256(in)) like DoubleHash.
+func DoubleHashNative(in []byte) [32]byte {
+	digest := sha256.Sum256(in)
+	return sha256.Sum256(digest[:])
+}
+
+// Computes sha256(in) && ((1 << nbBits) - 1) like HashAndTruncate.
+func HashAndTruncateNative(in []byte, nbBits int) *big.Int {
+	digest := sha256.Sum256(in)
+	value := new(big.Int).SetBytes(digest[:])
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+	mask.Sub(mask, big.NewInt(1))
+	return value.And(value, mask)
+}
@@ -2,6 +2,7 @@
 package sha256
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/succinctlabs/succinctx/gnarkx/bits32"
@@ -28,24 +29,48 @@ var K = []uint32{
 	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
 }
 
-// Computes the SHA256-2 hash of the input bytes. Note that at compile time of the circuit, len(in)
-// must be a constant.
-func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
-	bits32 := bits32.NewAPI(api)
+// Hasher computes a SHA256-2 hash incrementally. Every full chunk of the input is compressed as
+// soon as it has been written, so a message can be hashed as it is produced. The input written
+// with Write must have a constant length at compile time of the circuit, but the message may end
+// with bytes of a variable length, see SumVariable.
+type Hasher struct {
+	api    builder.API
+	bits32 bits32.API
+	h      [8][32]vars.Bool
+	// The bits of the input which have not been compressed yet, always less than a chunk.
+	buffer []vars.Bool
+	// The number of bytes written so far and the number of chunks compressed so far.
+	length int
+	chunks int
+}
+
+// Creates a new Hasher with the initial hash values.
+func NewHasher(api builder.API) *Hasher {
+	return &Hasher{api: api, bits32: bits32.NewAPI(api), h: initialState()}
+}
 
-	// Decompose bytes to bits.
-	inBits := make([]vars.Bool, len(in)*8)
+// Writes the input bytes, compressing every chunk which is full.
+func (h *Hasher) Write(in []vars.Byte) {
 	for i := 0; i < len(in); i++ {
-		bits := api.ToBitsFromByte(in[i])
+		bits := h.api.ToBitsFromByte(in[i])
 		for j := 0; j < 8; j++ {
-			inBits[i*8+j] = bits[7-j]
+			h.buffer = append(h.buffer, bits[7-j])
 		}
 	}
+	h.length += len(in)
+	for len(h.buffer) >= sha256ChunkLength {
+		h.compress(h.buffer[:sha256ChunkLength])
+		h.buffer = h.buffer[sha256ChunkLength:]
+	}
+}
 
+// Pads the input written so far, compresses the remaining chunks and returns the digest. The
+// Hasher must not be used afterwards.
+func (h *Hasher) Sum() [32]vars.Byte {
 	// The length-encoded message length ("L + 1 + 64").
 	const seperatorLength = 1
 	const u64BitLength = 64
-	encodedMessageLength := len(inBits) + seperatorLength + u64BitLength
+	encodedMessageLength := len(h.buffer) + seperatorLength + u64BitLength
 
 	// The multiple of 512-bit padded message length. Padding length is "K".
 	remainderLength := encodedMessageLength % 512
@@ -63,119 +88,186 @@ func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
 		paddedMessage[i] = vars.FALSE
 	}
 
-	// Begin with the original message of length "L".
-	copy(paddedMessage, inBits)
+	// Begin with the rest of the original message of length "L".
+	copy(paddedMessage, h.buffer)
 
 	// Append a single '1' bit.
-	paddedMessage[len(inBits)] = vars.TRUE
+	paddedMessage[len(h.buffer)] = vars.TRUE
 
 	// Append L as a 64-bit big-endian integer.
-	inputLengthBitsBE := api.ToBinaryBE(vars.NewVariableFromInt(len(inBits)), 64)
+	inputLengthBitsBE := h.api.ToBinaryBE(vars.NewVariableFromInt(8*h.length), 64)
 	for i := 0; i < len(inputLengthBitsBE); i++ {
-		paddedMessage[len(inBits)+i+1+paddingLength] = inputLengthBitsBE[i]
+		paddedMessage[len(h.buffer)+i+1+paddingLength] = inputLengthBitsBE[i]
 	}
 
-	// At this point, the padded message should be of the following form.
-	//      <message of length L> 1 <K zeros> <L as 64 bit integer>
-	// Now, we will process the padded message in 512 bit chunks and begin referring to the
-	// padded message as "message".
-	const sha256ChunkLength = 512
-	const sha256WordLength = 32
-	const sha256MessageScheduleArrayLength = 64
-
+	// At this point, the rest of the padded message should be of the following form.
+	//      <rest of the message> 1 <K zeros> <L as 64 bit integer>
+	// Now, we will process it in 512 bit chunks.
 	message := paddedMessage
 	numChunks := len(message) / sha256ChunkLength
+	for i := 0; i < numChunks; i++ {
+		h.compress(message[i*sha256ChunkLength : (i+1)*sha256ChunkLength])
+	}
+	h.buffer = nil
+	return digest(h.api, h.h)
+}
+
+// Writes the first length bytes of in, pads the message and returns the digest, like HashVariable
+// for the message which is the input written so far followed by in[:length]. The remaining bytes
+// of in are ignored, so len(in) is the maximum length of the tail of the message and must be a
+// constant at compile time of the circuit, while length may be any variable in [0, len(in)]. The
+// Hasher must not be used afterwards.
+func (h *Hasher) SumVariable(in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	message, isLastChunk := padVariable(h.api, h.buffer, h.length, in, length)
+
+	var result [8][32]vars.Bool
+	for i := 0; i < len(isLastChunk); i++ {
+		h.compress(message[i*sha256ChunkLength : (i+1)*sha256ChunkLength])
+		for j := 0; j < 8; j++ {
+			for k := 0; k < sha256WordLength; k++ {
+				if i == 0 {
+					result[j][k] = h.h[j][k]
+				} else {
+					result[j][k] = vars.Bool{Value: h.api.Select(isLastChunk[i], h.h[j][k].Value, result[j][k].Value)}
+				}
+			}
+		}
+	}
+	h.buffer = nil
+	return digest(h.api, result)
+}
+
+// Compresses a chunk into the hash values.
+func (h *Hasher) compress(chunk []vars.Bool) {
+	h.h = compress(&h.bits32, h.h, chunk)
+	inspectState(h.api, h.chunks, h.h)
+	h.chunks++
+}
+
+// Computes the SHA256-2 hash of the input bytes. Note that at compile time of the circuit, len(in)
+// must be a constant.
+func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
+	defer api.Scope("sha256")()
+	h := NewHasher(api)
+	h.Write(in)
+	return h.Sum()
+}
 
+const sha256ChunkLength = 512
+const sha256WordLength = 32
+const sha256MessageScheduleArrayLength = 64
+
+// Returns the initial hash values as bit arrays.
+func initialState() [8][32]vars.Bool {
 	var h [8][32]vars.Bool
 	for i := 0; i < 8; i++ {
 		h[i] = vars.NewBoolArrayFromU32(H[i])
 	}
+	return h
+}
 
-	for i := 0; i < numChunks; i++ {
-		// The 64-entry message schedule array of 32-bit words.
-		var w [sha256MessageScheduleArrayLength][sha256WordLength]vars.Bool
-		for j := 0; j < sha256MessageScheduleArrayLength; j++ {
-			for k := 0; k < sha256WordLength; k++ {
-				w[j][k] = vars.FALSE
-			}
+// Applies the compression function to the hash values h and a 512 bit chunk of the padded
+// message.
+func compress(bits32 *bits32.API, h [8][32]vars.Bool, chunk []vars.Bool) [8][32]vars.Bool {
+	// The 64-entry message schedule array of 32-bit words.
+	var w [sha256MessageScheduleArrayLength][sha256WordLength]vars.Bool
+	for j := 0; j < sha256MessageScheduleArrayLength; j++ {
+		for k := 0; k < sha256WordLength; k++ {
+			w[j][k] = vars.FALSE
 		}
+	}
 
-		// Copy chunk into first 16 words w[0..15] of the message schedule array.
-		chunkOffset := i * sha256ChunkLength
-		for j := 0; j < 16; j++ {
-			wordOffset := j * 32
-			for k := 0; k < 32; k++ {
-				w[j][k] = message[chunkOffset+wordOffset+k]
-			}
+	// Copy chunk into first 16 words w[0..15] of the message schedule array.
+	for j := 0; j < 16; j++ {
+		wordOffset := j * 32
+		for k := 0; k < 32; k++ {
+			w[j][k] = chunk[wordOffset+k]
 		}
+	}
 
-		// Extend the first 16 words into the remaining 48 words w[16..63].
-		for j := 16; j < sha256MessageScheduleArrayLength; j++ {
-			s0 := bits32.Xor(
-				bits32.Rotate(w[j-15], 7),
-				bits32.Rotate(w[j-15], 18),
-				bits32.Shr(w[j-15], 3),
-			)
-			s1 := bits32.Xor(
-				bits32.Rotate(w[j-2], 17),
-				bits32.Rotate(w[j-2], 19),
-				bits32.Shr(w[j-2], 10),
-			)
-			w[j] = bits32.Add(w[j-16], s0, w[j-7], s1)
-		}
+	// Extend the first 16 words into the remaining 48 words w[16..63].
+	for j := 16; j < sha256MessageScheduleArrayLength; j++ {
+		s0 := bits32.Xor(
+			bits32.Rotate(w[j-15], 7),
+			bits32.Rotate(w[j-15], 18),
+			bits32.Shr(w[j-15], 3),
+		)
+		s1 := bits32.Xor(
+			bits32.Rotate(w[j-2], 17),
+			bits32.Rotate(w[j-2], 19),
+			bits32.Shr(w[j-2], 10),
+		)
+		w[j] = bits32.Add(w[j-16], s0, w[j-7], s1)
+	}
 
-		sa := h[0]
-		sb := h[1]
-		sc := h[2]
-		sd := h[3]
-		se := h[4]
-		sf := h[5]
-		sg := h[6]
-		sh := h[7]
-
-		numCompressionRounds := 64
-		for j := 0; j < numCompressionRounds; j++ {
-			s1 := bits32.Xor(
-				bits32.Rotate(se, 6),
-				bits32.Rotate(se, 11),
-				bits32.Rotate(se, 25),
-			)
-			ch := bits32.Xor(
-				bits32.And(se, sf),
-				bits32.And(bits32.Not(se), sg),
-			)
-			temp := bits32.Add(sh, s1, ch, vars.NewBoolArrayFromU32(K[j]), w[j])
-			s0 := bits32.Xor(
-				bits32.Rotate(sa, 2),
-				bits32.Rotate(sa, 13),
-				bits32.Rotate(sa, 22),
-			)
-			maj := bits32.Xor(
-				bits32.And(sa, sb),
-				bits32.And(sa, sc),
-				bits32.And(sb, sc),
-			)
-			temp2 := bits32.Add(s0, maj)
-			sh = sg
-			sg = sf
-			sf = se
-			se = bits32.Add(sd, temp)
-			sd = sc
-			sc = sb
-			sb = sa
-			sa = bits32.Add(temp, temp2)
-		}
+	sa := h[0]
+	sb := h[1]
+	sc := h[2]
+	sd := h[3]
+	se := h[4]
+	sf := h[5]
+	sg := h[6]
+	sh := h[7]
 
-		h[0] = bits32.Add(h[0], sa)
-		h[1] = bits32.Add(h[1], sb)
-		h[2] = bits32.Add(h[2], sc)
-		h[3] = bits32.Add(h[3], sd)
-		h[4] = bits32.Add(h[4], se)
-		h[5] = bits32.Add(h[5], sf)
-		h[6] = bits32.Add(h[6], sg)
-		h[7] = bits32.Add(h[7], sh)
+	numCompressionRounds := 64
+	for j := 0; j < numCompressionRounds; j++ {
+		s1 := bits32.Xor(
+			bits32.Rotate(se, 6),
+			bits32.Rotate(se, 11),
+			bits32.Rotate(se, 25),
+		)
+		ch := bits32.Xor(
+			bits32.And(se, sf),
+			bits32.And(bits32.Not(se), sg),
+		)
+		temp := bits32.Add(sh, s1, ch, vars.NewBoolArrayFromU32(K[j]), w[j])
+		s0 := bits32.Xor(
+			bits32.Rotate(sa, 2),
+			bits32.Rotate(sa, 13),
+			bits32.Rotate(sa, 22),
+		)
+		maj := bits32.Xor(
+			bits32.And(sa, sb),
+			bits32.And(sa, sc),
+			bits32.And(sb, sc),
+		)
+		temp2 := bits32.Add(s0, maj)
+		sh = sg
+		sg = sf
+		sf = se
+		se = bits32.Add(sd, temp)
+		sd = sc
+		sc = sb
+		sb = sa
+		sa = bits32.Add(temp, temp2)
 	}
 
+	h[0] = bits32.Add(h[0], sa)
+	h[1] = bits32.Add(h[1], sb)
+	h[2] = bits32.Add(h[2], sc)
+	h[3] = bits32.Add(h[3], sd)
+	h[4] = bits32.Add(h[4], se)
+	h[5] = bits32.Add(h[5], sf)
+	h[6] = bits32.Add(h[6], sg)
+	h[7] = bits32.Add(h[7], sh)
+	return h
+}
+
+// Records the hash values after the chunk with the index when the circuit is evaluated with
+// builder.Evaluate, e.g. as "state[3]" after the fourth chunk.
+func inspectState(api builder.API, chunk int, h [8][32]vars.Bool) {
+	if !api.IsEvaluating() {
+		return
+	}
+	words := make([][]vars.Bool, 8)
+	for i := range h {
+		words[i] = h[i][:]
+	}
+	api.InspectWords(fmt.Sprintf("state[%d]", chunk), words...)
+}
+
+// Converts the final hash values to the digest bytes.
+func digest(api builder.API, h [8][32]vars.Bool) [32]vars.Byte {
 	var digestBits [256]vars.Bool
 	for i := 0; i < 8; i++ {
 		for j := 0; j < sha256WordLength; j++ {
@@ -183,18 +275,120 @@ func Hash(api builder.API, in []vars.Byte) [32]vars.Byte {
 		}
 	}
 
-	var digest [32]vars.Byte
+	var result [32]vars.Byte
 	for i := 0; i < 32; i++ {
 		var bits [8]vars.Bool
 		for j := 0; j < 8; j++ {
 			bits[7-j] = digestBits[i*8+j]
 		}
-		digest[i] = api.ToByteFromBits(bits)
+		result[i] = api.ToByteFromBits(bits)
+	}
+	return result
+}
+
+// Computes the SHA256-2 hash of the first length bytes of in. The remaining bytes of in are
+// ignored, so len(in) is the maximum length of the message and must be a constant at compile time
+// of the circuit, while length may be any variable in [0, len(in)].
+func HashVariable(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	defer api.Scope("sha256")()
+	return NewHasher(api).SumVariable(in, length)
+}
+
+// Computes the SHA256-2 hash of variable bytes.
+func HashVariableBytes(api builder.API, in vars.VariableBytes) [32]vars.Byte {
+	return HashVariable(api, in.Data, in.Length)
+}
+
+// Computes SHA256(SHA256(in)), which is the hash of Bitcoin's block headers and transactions.
+func DoubleHash(api builder.API, in []vars.Byte) [32]vars.Byte {
+	digest := Hash(api, in)
+	return Hash(api, digest[:])
+}
+
+// Computes SHA256(SHA256(in[:length])), where len(in) is the maximum length of the message like
+// in HashVariable.
+func DoubleHashVariable(api builder.API, in []vars.Byte, length vars.Variable) [32]vars.Byte {
+	digest := HashVariable(api, in, length)
+	return Hash(api, digest[:])
+}
+
+// Pads the message, which is the prefix of bits followed by the first length bytes of in, to the
+// maximum number of chunks it may need. The prefix is the rest of a message of written bytes, less
+// than a chunk, of which the previous chunks have already been compressed. Returns the bits of the
+// padded message along with a selector for each chunk, which is set iff it is the last chunk of the
+// padded message.
+func padVariable(api builder.API, prefix []vars.Bool, written int, in []vars.Byte, length vars.Variable) ([]vars.Bool, []vars.Bool) {
+	const chunkBytes = sha256ChunkLength / 8
+	prefixBytes := len(prefix) / 8
+	numChunks := (prefixBytes + len(in) + 9 + chunkBytes - 1) / chunkBytes
+
+	// The selectors isEnd[i] are set iff i == length, and exactly one of them must be set.
+	isEnd := make([]vars.Bool, len(in)+1)
+	nbEnds := vars.ZERO
+	for i := 0; i <= len(in); i++ {
+		isEnd[i] = api.IsZero(api.Sub(length, vars.NewVariableFromInt(i)))
+		nbEnds = api.Add(nbEnds, isEnd[i].Value)
+	}
+	api.AssertIsEqual(nbEnds, vars.ONE)
+
+	// The message length in bits as a 64-bit big-endian integer, which also range checks length.
+	messageLength := length
+	if written > 0 {
+		messageLength = api.Add(length, vars.NewVariableFromInt(written))
+	}
+	lengthBits := api.ToBinaryLE(messageLength, 61)
+	var lengthBytes [8]vars.Variable
+	for i := 0; i < 8; i++ {
+		var bits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			if k := 8*(7-i) + j - 3; k >= 0 {
+				bits[j] = lengthBits[k]
+			} else {
+				bits[j] = vars.FALSE
+			}
+		}
+		lengthBytes[i] = api.ToByteFromBits(bits).Value
+	}
+
+	// The chunk i is the last one iff len(prefix) + length + 9 is in (64 * i, 64 * (i + 1)].
+	isLastChunk := make([]vars.Bool, numChunks)
+	for i := 0; i < numChunks; i++ {
+		isLast := vars.ZERO
+		for j := chunkBytes*i - 8 - prefixBytes; j <= chunkBytes*i+chunkBytes-9-prefixBytes && j <= len(in); j++ {
+			if j >= 0 {
+				isLast = api.Add(isLast, isEnd[j].Value)
+			}
+		}
+		isLastChunk[i] = vars.Bool{Value: isLast}
+	}
+
+	// Each byte of the padded message after the prefix is either a byte of the message, the 0x80
+	// separator, a byte of the encoded length or zero. At most one of these terms is non-zero.
+	message := make([]vars.Bool, numChunks*sha256ChunkLength)
+	copy(message, prefix)
+	isMessage := vars.ONE
+	for i := prefixBytes; i < numChunks*chunkBytes; i++ {
+		value := vars.ZERO
+		if k := i - prefixBytes; k <= len(in) {
+			isMessage = api.Sub(isMessage, isEnd[k].Value)
+			value = api.Mul(isEnd[k].Value, vars.NewVariableFromInt(0x80))
+			if k < len(in) {
+				value = api.Add(value, api.Mul(isMessage, in[k].Value))
+			}
+		}
+		if offset := i % chunkBytes; offset >= chunkBytes-8 {
+			value = api.Add(value, api.Mul(isLastChunk[i/chunkBytes].Value, lengthBytes[offset-chunkBytes+8]))
+		}
+		bits := api.ToBitsFromByte(vars.Byte{Value: value})
+		for j := 0; j < 8; j++ {
+			message[i*8+j] = bits[7-j]
+		}
 	}
-	return digest
+	return message, isLastChunk
 }
 
-// Computes sha256(in) && ((1 << nbBits) - 1).
+// Computes sha256(in) && ((1 << nbBits) - 1), which is the hash of the input and output bytes of
+// succinct circuit functions, see builder.IOHashFunc.
 func HashAndTruncate(api builder.API, in []vars.Byte, nbBits int) vars.Variable {
 	// Compute the untruncated hash.
 	hash := vars.ReverseBytes32(Hash(api, in))
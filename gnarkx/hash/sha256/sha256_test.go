@@ -1,7 +1,11 @@
 package sha256
 
 import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
 	"encoding/hex"
+	"math/rand"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -9,6 +13,7 @@ import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/utils/sha256utils"
 	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
@@ -83,3 +88,165 @@ func TestSha256Proof(t *testing.T) {
 
 	testCase([]byte("Succinct Labs"), "7fb4acc57b9765e167a716dee0d19c5dce851cfa140dbce7fff42a3e589ab470")
 }
+
+type TestSha256VariableCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+}
+
+func (circuit *TestSha256VariableCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	res := HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqual(res[i].Value, circuit.Out[i].Value)
+	}
+	return nil
+}
+
+func TestSha256VariableWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The message is padded with junk bytes up to the maximum length, which must be ignored.
+	const maxLength = 130
+	data := make([]byte, maxLength)
+	for i := range data {
+		data[i] = byte(7*i + 3)
+	}
+	newCircuit := func(length int, out [32]byte) *TestSha256VariableCircuit {
+		var digest [32]vars.Byte
+		vars.SetBytes32(&digest, out)
+		return &TestSha256VariableCircuit{
+			In:     vars.NewBytesFrom(data),
+			Length: vars.NewVariableFromInt(length),
+			Out:    digest,
+		}
+	}
+
+	// Cover the lengths around the chunk boundaries, where the padding spills into a new chunk.
+	for _, length := range []int{0, 1, 55, 56, 63, 64, 119, 120, maxLength} {
+		circuit := newCircuit(length, [32]byte{})
+		witness := newCircuit(length, sha256.Sum256(data[:length]))
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+	}
+
+	// A digest of a different length or a length out of range is rejected.
+	circuit := newCircuit(0, [32]byte{})
+	assert.Error(test.IsSolved(circuit, newCircuit(10, sha256.Sum256(data[:11])), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(circuit, newCircuit(maxLength+1, sha256.Sum256(data)), ecc.BN254.ScalarField()))
+}
+
+type TestSha256HasherCircuit struct {
+	Prefix []vars.Byte
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [32]vars.Byte
+}
+
+func (circuit *TestSha256HasherCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	h := NewHasher(*succinctAPI)
+	// The prefix is written in two parts, which must not matter.
+	h.Write(circuit.Prefix[:len(circuit.Prefix)/3])
+	h.Write(circuit.Prefix[len(circuit.Prefix)/3:])
+	res := h.SumVariable(circuit.In, circuit.Length)
+	for i := 0; i < 32; i++ {
+		succinctAPI.AssertIsEqual(res[i].Value, circuit.Out[i].Value)
+	}
+	return nil
+}
+
+func TestSha256Hasher(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const maxLength = 70
+	data := make([]byte, 140+maxLength)
+	for i := range data {
+		data[i] = byte(5*i + 1)
+	}
+	newCircuit := func(prefixLength int, length int, out [32]byte) *TestSha256HasherCircuit {
+		var digest [32]vars.Byte
+		vars.SetBytes32(&digest, out)
+		return &TestSha256HasherCircuit{
+			Prefix: vars.NewBytesFrom(data[:prefixLength]),
+			In:     vars.NewBytesFrom(data[prefixLength : prefixLength+maxLength]),
+			Length: vars.NewVariableFromInt(length),
+			Out:    digest,
+		}
+	}
+
+	// The written prefix ends before, at and after a chunk boundary, and shifts where the padding
+	// of the variable tail spills into a new chunk.
+	for _, prefixLength := range []int{0, 10, 56, 64, 140} {
+		for _, length := range []int{0, 1, 45, 46, 54, 55, 56, maxLength} {
+			circuit := newCircuit(prefixLength, length, [32]byte{})
+			witness := newCircuit(prefixLength, length, sha256.Sum256(data[:prefixLength+length]))
+			assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "prefix %d, length %d", prefixLength, length)
+		}
+	}
+
+	// The digest of the tail alone, or of a length out of range, is rejected.
+	circuit := newCircuit(10, 0, [32]byte{})
+	assert.Error(test.IsSolved(circuit, newCircuit(10, 5, sha256.Sum256(data[10:15])), ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(circuit, newCircuit(10, maxLength+1, sha256.Sum256(data[:10+maxLength+1])), ecc.BN254.ScalarField()))
+
+	// With an empty tail, the message is the input written so far.
+	in := data[:150]
+	out := sha256.Sum256(in)
+	streamed := TestSha256HasherCircuit{Prefix: vars.NewBytesFrom(in), In: []vars.Byte{}, Length: vars.ZERO}
+	vars.SetBytes32(&streamed.Out, out)
+	assert.NoError(test.IsSolved(&streamed, &streamed, ecc.BN254.ScalarField()))
+}
+
+func TestSha256Native(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	expected, _ := hex.DecodeString("7fb4acc57b9765e167a716dee0d19c5dce851cfa140dbce7fff42a3e589ab470")
+	digest := HashNative([]byte("Succinct Labs"))
+	assert.Equal(expected, digest[:])
+
+	// The gadgets agree with their native counterparts on random messages.
+	rng := rand.New(rand.NewSource(0))
+	data := make([]byte, 100)
+	rng.Read(data)
+	circuit := &TestSha256VariableCircuit{In: vars.NewBytes(len(data))}
+	for i := 0; i < 3; i++ {
+		length := rng.Intn(len(data) + 1)
+		witness := &TestSha256VariableCircuit{In: vars.NewBytesFrom(data), Length: vars.NewVariableFromInt(length)}
+		vars.SetBytes32(&witness.Out, HashVariableNative(data, length))
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+	}
+
+	assert.Equal(sha256utils.HashAndTruncate(data, 253), HashAndTruncateNative(data, 253))
+}
+
+func TestSha256Evaluate(t *testing.T) {
+	// The state after the fourth chunk only depends on the first four chunks of the message.
+	in := make([]byte, 4*64+10)
+	rand.Read(in)
+	reference := sha256.New()
+	reference.Write(in[:4*64])
+	state, err := reference.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := sha256.Sum256(in)
+	circuit := TestSha256Circuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out[:])}
+	witness := TestSha256Circuit{In: vars.NewBytesFrom(in), Out: vars.NewBytesFrom(out[:])}
+	evaluation, err := builder.Evaluate(ecc.BN254.ScalarField(), &circuit, &witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots := evaluation.Find("state[3]")
+	if len(snapshots) != 1 || snapshots[0].Scope != "sha256" {
+		t.Fatalf("unexpected snapshots %v", snapshots)
+	}
+	// The marshaled state of crypto/sha256 is a magic string followed by the hash values.
+	for i := 0; i < 8; i++ {
+		expected := binary.BigEndian.Uint32(state[4+4*i:])
+		if snapshots[0].Values[i].Uint64() != uint64(expected) {
+			t.Fatalf("word %d of the state is %d, expected %d", i, snapshots[0].Values[i], expected)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package sha512
+
+import (
+	"crypto/sha512"
+)
+
+// Computes the SHA-512 hash of the input bytes like Hash.
+func HashNative(in []byte) [64]byte {
+	return sha512.Sum512(in)
+}
+
+// Computes the SHA-512 hash of the first length bytes of in like HashVariable.
+func HashVariableNative(in []byte, length int) [64]byte {
+	if length < 0 || length > len(in) {
+		panic("length is out of range")
+	}
+	return sha512.Sum512(in[:length])
+}
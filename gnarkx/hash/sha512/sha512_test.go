@@ -1,12 +1,16 @@
 package sha512
 
 import (
+	"crypto/sha512"
 	"encoding/hex"
+	"math/rand"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
 type TestSha512Circuit struct {
@@ -77,3 +81,85 @@ func decode(s string) []byte {
 	}
 	return result
 }
+
+type TestSha512BytesCircuit struct {
+	In     []vars.Byte
+	Length vars.Variable
+	Out    [64]vars.Byte
+	Fixed  bool `gnark:"-"`
+}
+
+func (circuit *TestSha512BytesCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	var res [64]vars.Byte
+	if circuit.Fixed {
+		res = Hash(*succinctAPI, circuit.In)
+	} else {
+		res = HashVariable(*succinctAPI, circuit.In, circuit.Length)
+	}
+	for i := 0; i < 64; i++ {
+		succinctAPI.AssertIsEqual(res[i].Value, circuit.Out[i].Value)
+	}
+	return nil
+}
+
+func newSha512BytesCircuit(in []byte, length int, out [64]byte) *TestSha512BytesCircuit {
+	var digest [64]vars.Byte
+	for i := 0; i < 64; i++ {
+		digest[i].Set(out[i])
+	}
+	return &TestSha512BytesCircuit{
+		In:     vars.NewBytesFrom(in),
+		Length: vars.NewVariableFromInt(length),
+		Out:    digest,
+	}
+}
+
+func TestSha512Bytes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	in := []byte("Succinct Labs")
+	circuit := newSha512BytesCircuit(in, len(in), [64]byte{})
+	circuit.Fixed = true
+	witness := newSha512BytesCircuit(in, len(in), sha512.Sum512(in))
+	assert.NoError(test.IsSolved(circuit, witness, testCurve.ScalarField()))
+}
+
+func TestSha512Variable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The message is padded with junk bytes up to the maximum length, which must be ignored.
+	const maxLength = 130
+	data := make([]byte, maxLength)
+	for i := range data {
+		data[i] = byte(7*i + 3)
+	}
+
+	// Cover the lengths around the chunk boundaries, where the padding spills into a new chunk.
+	for _, length := range []int{0, 5, 111, 112, 127, 128, maxLength} {
+		circuit := newSha512BytesCircuit(data, length, [64]byte{})
+		witness := newSha512BytesCircuit(data, length, sha512.Sum512(data[:length]))
+		assert.NoError(test.IsSolved(circuit, witness, testCurve.ScalarField()))
+	}
+
+	circuit := newSha512BytesCircuit(data, 0, [64]byte{})
+	witness := newSha512BytesCircuit(data, 10, sha512.Sum512(data[:11]))
+	assert.Error(test.IsSolved(circuit, witness, testCurve.ScalarField()))
+}
+
+func TestSha512Native(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.Equal(sha512.Sum512([]byte("Succinct Labs")), HashNative([]byte("Succinct Labs")))
+
+	// The gadgets agree with their native counterparts on random messages.
+	rng := rand.New(rand.NewSource(0))
+	data := make([]byte, 140)
+	rng.Read(data)
+	circuit := newSha512BytesCircuit(data, 0, [64]byte{})
+	for i := 0; i < 3; i++ {
+		length := rng.Intn(len(data) + 1)
+		witness := newSha512BytesCircuit(data, length, HashVariableNative(data, length))
+		assert.NoError(test.IsSolved(circuit, witness, testCurve.ScalarField()))
+	}
+}
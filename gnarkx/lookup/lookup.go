@@ -0,0 +1,100 @@
+// The API for lookup tables, which replace the constraints of a function on small integers, e.g.
+// an S-box or a bitwise operation on bytes, by a lookup into a table of all of its values. Range
+// checks are batched into a lookup by builder.API.AssertIsInRange.
+//
+// The lookups are proven with a log-derivative argument, which costs a few constraints per entry
+// of a table once plus a few constraints per lookup, independent of the function. A table of 2^8
+// or 2^16 entries therefore pays off as soon as it replaces enough bit decompositions, which is
+// typically the case for hash functions. The argument needs a commitment to the witness, which
+// the Groth16 verifiers do not support, see builder.API.SupportsCommitments. In R1CS, tables are
+// instead accessed with a multiplexer, which costs O(n) constraints per lookup.
+package lookup
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/selector"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum number of input bits of a table created from a function.
+const MaxBits = 16
+
+// A lookup table mapping each index in [0, Len()) to a value.
+type Table struct {
+	api   builder.API
+	table *logderivlookup.Table
+	size  int
+
+	// The values of the table, which are multiplexed if the circuit does not support commitments.
+	values []frontend.Variable
+}
+
+// Creates a new table with the given values, which may be constants or variables.
+func NewTable(api builder.API, values []vars.Variable) *Table {
+	if len(values) == 0 {
+		panic("table must not be empty")
+	}
+	t := &Table{api: api, size: len(values)}
+	if !api.SupportsCommitments() {
+		t.values = make([]frontend.Variable, len(values))
+		for i := range values {
+			t.values[i] = values[i].Value
+		}
+		return t
+	}
+	t.table = logderivlookup.New(api.FrontendAPI())
+	for i := range values {
+		t.table.Insert(values[i].Value)
+	}
+	return t
+}
+
+// Creates a new table of the function on nbBits-bit integers, where nbBits is at most MaxBits.
+func NewFunctionTable(api builder.API, nbBits int, f func(x uint64) uint64) *Table {
+	if nbBits < 0 || nbBits > MaxBits {
+		panic("unsupported number of bits")
+	}
+	values := make([]vars.Variable, 1<<nbBits)
+	for i := range values {
+		values[i] = vars.Variable{Value: new(big.Int).SetUint64(f(uint64(i)))}
+	}
+	return NewTable(api, values)
+}
+
+// Returns the number of entries of the table.
+func (t *Table) Len() int {
+	return t.size
+}
+
+// Returns the value at the index, which must be in [0, Len()) for the circuit to be satisfiable.
+func (t *Table) Lookup(index vars.Variable) vars.Variable {
+	if t.table == nil {
+		return vars.Variable{Value: selector.Mux(t.api.FrontendAPI(), index.Value, t.values...)}
+	}
+	return vars.Variable{Value: t.table.Lookup(index.Value)[0]}
+}
+
+// A lookup table of a function on two bytes, e.g. a bitwise operation.
+type ByteOpTable struct {
+	table *Table
+	api   builder.API
+}
+
+// Creates a new table of the function on two bytes, which has 2^16 entries.
+func NewByteOpTable(api builder.API, f func(x, y byte) byte) *ByteOpTable {
+	table := NewFunctionTable(api, 16, func(i uint64) uint64 {
+		return uint64(f(byte(i>>8), byte(i)))
+	})
+	return &ByteOpTable{table: table, api: api}
+}
+
+// Returns f(x, y). Both x and y must be bytes, which the lookup does not check on its own, since
+// 256 * x + y is in range for some non-bytes as well.
+func (t *ByteOpTable) Lookup(x, y vars.Byte) vars.Byte {
+	index := t.api.Add(t.api.Mul(x.Value, vars.NewVariableFromInt(256)), y.Value)
+	return vars.Byte{Value: t.table.Lookup(index)}
+}
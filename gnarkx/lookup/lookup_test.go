@@ -0,0 +1,104 @@
+package lookup
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An S-box-like permutation of the bytes.
+func testSbox(x uint64) uint64 {
+	return (x*167 + 13) % 256
+}
+
+type TestLookupCircuit struct {
+	X, Y     [4]vars.Byte
+	Xor      [4]vars.Byte
+	Sbox     [4]vars.Byte
+	InRange  vars.Variable
+	RangeLen int `gnark:"-"`
+}
+
+func (circuit *TestLookupCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	xor := NewByteOpTable(*succinctAPI, func(x, y byte) byte { return x ^ y })
+	sbox := NewFunctionTable(*succinctAPI, 8, testSbox)
+	for i := 0; i < 4; i++ {
+		succinctAPI.AssertIsEqualByte(xor.Lookup(circuit.X[i], circuit.Y[i]), circuit.Xor[i])
+		succinctAPI.AssertIsEqual(sbox.Lookup(circuit.X[i].Value), circuit.Sbox[i].Value)
+	}
+	succinctAPI.AssertIsInRange(circuit.InRange, circuit.RangeLen)
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func(inRange int, rangeLen int) *TestLookupCircuit {
+		circuit := &TestLookupCircuit{InRange: vars.NewVariableFromInt(inRange), RangeLen: rangeLen}
+		for i := 0; i < 4; i++ {
+			x, y := byte(37*i+200), byte(91*i+5)
+			circuit.X[i].Set(x)
+			circuit.Y[i].Set(y)
+			circuit.Xor[i].Set(x ^ y)
+			circuit.Sbox[i].Set(byte(testSbox(uint64(x))))
+		}
+		return circuit
+	}
+
+	// Ranges which are and are not multiples of the limb size.
+	for _, rangeLen := range []int{20, 24} {
+		circuit := newCircuit(1<<20-1, rangeLen)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A wrong value of a table is rejected.
+	circuit := newCircuit(5, 20)
+	circuit.Sbox[2].Set(0)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newCircuit(5, 20)
+	circuit.Xor[3].Set(0)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A value out of range is rejected.
+	circuit = newCircuit(1<<20, 20)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newCircuit(-1, 24)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestSboxCircuit struct {
+	X, Sbox vars.Byte
+}
+
+func (circuit *TestSboxCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sbox := NewFunctionTable(*succinctAPI, 8, testSbox)
+	succinctAPI.AssertIsEqual(sbox.Lookup(circuit.X.Value), circuit.Sbox.Value)
+	succinctAPI.AssertIsInRange(circuit.X.Value, 8)
+	return nil
+}
+
+func TestLookupR1CS(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// In R1CS, the lookups do not commit to the witness.
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestSboxCircuit{})
+	assert.NoError(err)
+	assert.Empty(ccs.GetCommitments().CommitmentIndexes())
+
+	solve := func(x, sbox int) error {
+		assignment := &TestSboxCircuit{X: vars.Byte{Value: vars.NewVariableFromInt(x)}, Sbox: vars.Byte{Value: vars.NewVariableFromInt(sbox)}}
+		witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		return ccs.IsSolved(witness)
+	}
+	assert.NoError(solve(200, int(testSbox(200))))
+	assert.Error(solve(200, 0))
+	assert.Error(solve(256, int(testSbox(0))))
+}
@@ -0,0 +1,58 @@
+package merkle
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/poseidon"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A Hasher whose nodes are 32 bytes, with the parent being the hash of the concatenation of the
+// children.
+type bytes32Hasher struct {
+	hash func(api builder.API, in []vars.Byte) [32]vars.Byte
+}
+
+func (h bytes32Hasher) HashPair(api builder.API, left, right [32]vars.Byte) [32]vars.Byte {
+	return h.hash(api, append(left[:], right[:]...))
+}
+
+func (h bytes32Hasher) Select(api builder.API, selector vars.Bool, i1, i2 [32]vars.Byte) [32]vars.Byte {
+	return api.SelectBytes32(selector, i1, i2)
+}
+
+func (h bytes32Hasher) AssertIsEqual(api builder.API, i1, i2 [32]vars.Byte) {
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqualByte(i1[i], i2[i])
+	}
+}
+
+// The Hasher for trees with SHA256-2, e.g. the SSZ Merkle trees of the Ethereum consensus layer.
+var SHA256 Hasher[[32]vars.Byte] = bytes32Hasher{hash: sha256.Hash}
+
+// The Hasher for trees with double SHA256-2, e.g. the transaction trees of Bitcoin blocks.
+var DoubleSHA256 Hasher[[32]vars.Byte] = bytes32Hasher{hash: sha256.DoubleHash}
+
+// The Hasher for trees with Keccak-256, e.g. the trees of OpenZeppelin's MerkleProof without
+// sorting the pairs.
+var Keccak256 Hasher[[32]vars.Byte] = bytes32Hasher{hash: keccak256.Hash}
+
+// A Hasher whose nodes are field elements, with the parent being the Poseidon hash of the
+// children.
+type poseidonHasher struct{}
+
+func (h poseidonHasher) HashPair(api builder.API, left, right vars.Variable) vars.Variable {
+	return poseidon.Hash(api, left, right)
+}
+
+func (h poseidonHasher) Select(api builder.API, selector vars.Bool, i1, i2 vars.Variable) vars.Variable {
+	return api.Select(selector, i1, i2)
+}
+
+func (h poseidonHasher) AssertIsEqual(api builder.API, i1, i2 vars.Variable) {
+	api.AssertIsEqual(i1, i2)
+}
+
+// The Hasher for trees with Poseidon, which is by far the cheapest inside circuits.
+var Poseidon Hasher[vars.Variable] = poseidonHasher{}
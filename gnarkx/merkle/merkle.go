@@ -0,0 +1,62 @@
+// The API for binary Merkle trees over any hash function. The hash functions are plugged in
+// through the Hasher interface, for which implementations with SHA256-2, Keccak-256 and Poseidon
+// are provided.
+//
+// The leaves are indexed from left to right starting at zero, and the proof of a leaf consists of
+// the siblings of the nodes on its path to the root starting at the leaf.
+package merkle
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A hash function for the nodes of a Merkle tree, where N is the type of a node.
+type Hasher[N any] interface {
+	// Returns the parent of the two children.
+	HashPair(api builder.API, left, right N) N
+	// Returns i1 if the selector is set and i2 otherwise.
+	Select(api builder.API, selector vars.Bool, i1, i2 N) N
+	// Asserts that the nodes are equal.
+	AssertIsEqual(api builder.API, i1, i2 N)
+}
+
+// Computes the root of the Merkle tree of the leaves, whose number must be a power of two.
+func ComputeRoot[N any](api builder.API, hasher Hasher[N], leaves []N) N {
+	if len(leaves) == 0 || len(leaves)&(len(leaves)-1) != 0 {
+		panic("number of leaves must be a power of two")
+	}
+	layer := make([]N, len(leaves))
+	copy(layer, leaves)
+	for len(layer) > 1 {
+		next := make([]N, len(layer)/2)
+		for i := range next {
+			next[i] = hasher.HashPair(api, layer[2*i], layer[2*i+1])
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// Computes the root of the Merkle tree from the leaf at the index and its proof. The depth of the
+// tree is len(proof), which must be a constant at compile time of the circuit, while the index may
+// be any variable in [0, 2^len(proof)).
+func RestoreRoot[N any](api builder.API, hasher Hasher[N], leaf N, proof []N, index vars.Variable) N {
+	// The bits of the index also range check it.
+	bits := api.ToBinaryLE(index, len(proof))
+	node := leaf
+	for i := range proof {
+		// If the bit is set, the node is the right child.
+		left := hasher.Select(api, bits[i], proof[i], node)
+		right := hasher.Select(api, bits[i], node, proof[i])
+		node = hasher.HashPair(api, left, right)
+	}
+	return node
+}
+
+// Verifies the proof of the leaf at the index in the Merkle tree with the given root. The depth of
+// the tree is len(proof), which must be a constant at compile time of the circuit, while the index
+// may be any variable in [0, 2^len(proof)).
+func VerifyProof[N any](api builder.API, hasher Hasher[N], root N, leaf N, proof []N, index vars.Variable) {
+	hasher.AssertIsEqual(api, RestoreRoot(api, hasher, leaf, proof, index), root)
+}
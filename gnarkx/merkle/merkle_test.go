@@ -0,0 +1,159 @@
+package merkle
+
+import (
+	gosha256 "crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+const testDepth = 3
+
+type TestBytes32MerkleCircuit struct {
+	Leaves    [1 << testDepth][32]vars.Byte
+	Root      [32]vars.Byte
+	Proof     [testDepth][32]vars.Byte
+	Index     vars.Variable
+	UseKeccak bool `gnark:"-"`
+}
+
+func (circuit *TestBytes32MerkleCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	hasher := SHA256
+	if circuit.UseKeccak {
+		hasher = Keccak256
+	}
+	root := ComputeRoot(*succinctAPI, hasher, circuit.Leaves[:])
+	hasher.AssertIsEqual(*succinctAPI, root, circuit.Root)
+
+	// The leaf at the index is selected with the bits of the index.
+	bits := succinctAPI.ToBinaryLE(circuit.Index, testDepth)
+	leaves := circuit.Leaves[:]
+	for i := testDepth - 1; i >= 0; i-- {
+		next := make([][32]vars.Byte, len(leaves)/2)
+		for j := range next {
+			next[j] = succinctAPI.SelectBytes32(bits[i], leaves[j+len(next)], leaves[j])
+		}
+		leaves = next
+	}
+	VerifyProof(*succinctAPI, hasher, circuit.Root, leaves[0], circuit.Proof[:], circuit.Index)
+	return nil
+}
+
+// Returns the root of the tree with the leaves and the proof of the leaf at the index.
+func newTestTree(leaves [][32]byte, index int, hash func([]byte) [32]byte) ([32]byte, [][32]byte) {
+	var proof [][32]byte
+	layer := leaves
+	for len(layer) > 1 {
+		proof = append(proof, layer[index^1])
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = hash(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+		index /= 2
+	}
+	return layer[0], proof
+}
+
+func keccak256Sum(in []byte) [32]byte {
+	var result [32]byte
+	copy(result[:], crypto.Keccak256(in))
+	return result
+}
+
+func TestBytes32Merkle(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	leaves := make([][32]byte, 1<<testDepth)
+	for i := range leaves {
+		leaves[i] = gosha256.Sum256([]byte{byte(i)})
+	}
+	newCircuit := func(index int, useKeccak bool) *TestBytes32MerkleCircuit {
+		hash := gosha256.Sum256
+		if useKeccak {
+			hash = keccak256Sum
+		}
+		root, proof := newTestTree(leaves, index, hash)
+		circuit := &TestBytes32MerkleCircuit{Index: vars.NewVariableFromInt(index), UseKeccak: useKeccak}
+		for i := range leaves {
+			vars.SetBytes32(&circuit.Leaves[i], leaves[i])
+		}
+		vars.SetBytes32(&circuit.Root, root)
+		for i := range proof {
+			vars.SetBytes32(&circuit.Proof[i], proof[i])
+		}
+		return circuit
+	}
+
+	for _, useKeccak := range []bool{false, true} {
+		for _, index := range []int{0, 5, 7} {
+			circuit := newCircuit(index, useKeccak)
+			assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+		}
+
+		// A proof for another index is rejected.
+		circuit := newCircuit(5, useKeccak)
+		circuit.Index = vars.NewVariableFromInt(4)
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+}
+
+type TestPoseidonMerkleCircuit struct {
+	Leaves     [1 << testDepth]vars.Variable
+	Index      vars.Variable
+	ProofIndex int `gnark:"-"`
+}
+
+func (circuit *TestPoseidonMerkleCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+
+	// The root of the tree of [1, 2] is the reference value of circomlib for Poseidon(1, 2).
+	expected := vars.NewVariableFromString("7853200120776062878684798364095072458815029376092732009249414926327459813530")
+	succinctAPI.AssertIsEqual(ComputeRoot(*succinctAPI, Poseidon, []vars.Variable{vars.ONE, vars.TWO}), expected)
+
+	// The proof of the leaf at ProofIndex is built in the circuit from the layers of the tree.
+	var proof []vars.Variable
+	layer := circuit.Leaves[:]
+	index := circuit.ProofIndex
+	for len(layer) > 1 {
+		proof = append(proof, layer[index^1])
+		next := make([]vars.Variable, len(layer)/2)
+		for i := range next {
+			next[i] = Poseidon.HashPair(*succinctAPI, layer[2*i], layer[2*i+1])
+		}
+		layer = next
+		index /= 2
+	}
+	root := ComputeRoot(*succinctAPI, Poseidon, circuit.Leaves[:])
+	VerifyProof(*succinctAPI, Poseidon, root, circuit.Leaves[circuit.ProofIndex], proof, circuit.Index)
+	return nil
+}
+
+func TestPoseidonMerkle(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newCircuit := func(proofIndex int, index int) *TestPoseidonMerkleCircuit {
+		circuit := &TestPoseidonMerkleCircuit{Index: vars.NewVariableFromInt(index), ProofIndex: proofIndex}
+		for i := range circuit.Leaves {
+			circuit.Leaves[i] = vars.NewVariableFromInt(i * 11)
+		}
+		return circuit
+	}
+
+	for _, index := range []int{0, 3, 6} {
+		circuit := newCircuit(index, index)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A proof for another index is rejected, and so is an index out of range.
+	circuit := newCircuit(3, 2)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	circuit = newCircuit(3, 3+(1<<testDepth))
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
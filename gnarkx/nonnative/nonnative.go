@@ -0,0 +1,176 @@
+// The API for arithmetic over emulated non-native fields, e.g. the base and scalar fields of
+// secp256k1, ed25519 and BLS12-381, whose elements are represented by limbs of native variables.
+// It wraps the emulated fields of gnark with conversions from and to byte variables, so that
+// gadgets only need to pick the parameters of their field from this package.
+package nonnative
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// An API used for arithmetic over the emulated field with the parameters T.
+type API[T emulated.FieldParams] struct {
+	api   builder.API
+	field *emulated.Field[T]
+}
+
+// Creates a new nonnative.API for the field with the parameters T.
+func NewAPI[T emulated.FieldParams](api *builder.API) *API[T] {
+	field, err := emulated.NewField[T](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	return &API[T]{api: *api, field: field}
+}
+
+// Returns the underlying emulated field of gnark, e.g. for the algebra packages of gnark.
+func (a *API[T]) Field() *emulated.Field[T] {
+	return a.field
+}
+
+// Returns the modulus of the field.
+func (a *API[T]) Modulus() *big.Int {
+	var params T
+	return params.Modulus()
+}
+
+// Returns the number of bytes of the canonical representation of an element.
+func (a *API[T]) NbBytes() int {
+	return (a.Modulus().BitLen() + 7) / 8
+}
+
+// Returns the constant element with the value v modulo the modulus.
+func (a *API[T]) Constant(v *big.Int) *emulated.Element[T] {
+	return a.field.NewElement(new(big.Int).Mod(v, a.Modulus()))
+}
+
+// Returns the element zero.
+func (a *API[T]) Zero() *emulated.Element[T] {
+	return a.field.Zero()
+}
+
+// Returns the element one.
+func (a *API[T]) One() *emulated.Element[T] {
+	return a.field.One()
+}
+
+// Computes i1 + i2.
+func (a *API[T]) Add(i1, i2 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.Add(i1, i2)
+}
+
+// Computes i1 - i2.
+func (a *API[T]) Sub(i1, i2 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.Sub(i1, i2)
+}
+
+// Computes -i1.
+func (a *API[T]) Neg(i1 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.Neg(i1)
+}
+
+// Computes i1 * i2, reducing the result.
+func (a *API[T]) Mul(i1, i2 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.MulMod(i1, i2)
+}
+
+// Computes i1 / i2, where i2 must not be zero.
+func (a *API[T]) Div(i1, i2 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.Div(i1, i2)
+}
+
+// Computes 1 / i1, where i1 must not be zero.
+func (a *API[T]) Inverse(i1 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.Inverse(i1)
+}
+
+// Returns i1 if selector is true and i2 otherwise.
+func (a *API[T]) Select(selector vars.Bool, i1, i2 *emulated.Element[T]) *emulated.Element[T] {
+	return a.field.Select(selector.Value.Value, i1, i2)
+}
+
+// Returns whether i1 is zero modulo the modulus.
+func (a *API[T]) IsZero(i1 *emulated.Element[T]) vars.Bool {
+	return vars.Bool{Value: vars.Variable{Value: a.field.IsZero(i1)}}
+}
+
+// Asserts that i1 and i2 are equal modulo the modulus.
+func (a *API[T]) AssertIsEqual(i1, i2 *emulated.Element[T]) {
+	a.field.AssertIsEqual(i1, i2)
+}
+
+// Converts big-endian bytes to an element. The bytes are range checked and may represent any
+// integer, which is reduced modulo the modulus if it has more bits than the limbs of an element.
+func (a *API[T]) FromBytesBE(in []vars.Byte) *emulated.Element[T] {
+	var params T
+	maxBytes := int(params.NbLimbs()*params.BitsPerLimb()) / 8
+	if len(in) <= maxBytes {
+		bits := make([]frontend.Variable, 8*len(in))
+		for i := 0; i < len(in); i++ {
+			byteBits := a.api.ToBitsFromByte(in[len(in)-1-i])
+			for j := 0; j < 8; j++ {
+				bits[8*i+j] = byteBits[j].Value.Value
+			}
+		}
+		return a.field.FromBits(bits...)
+	}
+
+	// Split the bytes into the low maxBytes bytes and the high bytes, so that the element is
+	// low + high * 2^(8 * maxBytes).
+	split := len(in) - maxBytes
+	low := a.FromBytesBE(in[split:])
+	high := a.FromBytesBE(in[:split])
+	shift := a.Constant(new(big.Int).Lsh(big.NewInt(1), uint(8*maxBytes)))
+	return a.Add(low, a.Mul(high, shift))
+}
+
+// Converts little-endian bytes to an element like FromBytesBE.
+func (a *API[T]) FromBytesLE(in []vars.Byte) *emulated.Element[T] {
+	return a.FromBytesBE(reverse(in))
+}
+
+// Converts an element to the NbBytes big-endian bytes of its canonical representation, i.e. the
+// value reduced to be less than the modulus.
+func (a *API[T]) ToBytesBE(i1 *emulated.Element[T]) []vars.Byte {
+	bits := a.canonicalBits(i1)
+	out := make([]vars.Byte, a.NbBytes())
+	for i := range out {
+		var byteBits [8]vars.Bool
+		for j := 0; j < 8; j++ {
+			if 8*i+j < len(bits) {
+				byteBits[j] = vars.Bool{Value: vars.Variable{Value: bits[8*i+j]}}
+			} else {
+				byteBits[j] = vars.FALSE
+			}
+		}
+		out[len(out)-1-i] = a.api.ToByteFromBits(byteBits)
+	}
+	return out
+}
+
+// Converts an element to the NbBytes little-endian bytes of its canonical representation.
+func (a *API[T]) ToBytesLE(i1 *emulated.Element[T]) []vars.Byte {
+	return reverse(a.ToBytesBE(i1))
+}
+
+// Returns the little-endian bits of the canonical representation of an element.
+func (a *API[T]) canonicalBits(i1 *emulated.Element[T]) []frontend.Variable {
+	reduced := a.field.Reduce(i1)
+	// The reduction only guarantees that the value is congruent to the input, so additionally
+	// assert that it is less than the modulus.
+	a.field.AssertIsLessOrEqual(reduced, a.field.NewElement(new(big.Int).Sub(a.Modulus(), big.NewInt(1))))
+	return a.field.ToBits(reduced)
+}
+
+func reverse(in []vars.Byte) []vars.Byte {
+	out := make([]vars.Byte, len(in))
+	for i := range in {
+		out[len(in)-1-i] = in[i]
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package nonnative
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestNonNativeCircuit[T emulated.FieldParams] struct {
+	X       []vars.Byte
+	Y       []vars.Byte
+	Wide    []vars.Byte
+	Product []vars.Byte
+	// The little-endian bytes of x / y.
+	Quotient []vars.Byte
+	// The little-endian bytes of wide modulo the modulus.
+	Reduced []vars.Byte
+}
+
+func (circuit *TestNonNativeCircuit[T]) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	field := NewAPI[T](succinctAPI)
+	x := field.FromBytesBE(circuit.X)
+	y := field.FromBytesBE(circuit.Y)
+	assertIsEqualBytes(succinctAPI, field.ToBytesBE(field.Mul(x, y)), circuit.Product)
+	assertIsEqualBytes(succinctAPI, field.ToBytesLE(field.Div(x, y)), circuit.Quotient)
+	assertIsEqualBytes(succinctAPI, field.ToBytesLE(field.FromBytesBE(circuit.Wide)), circuit.Reduced)
+	field.AssertIsEqual(field.FromBytesLE(circuit.Reduced), field.FromBytesBE(circuit.Wide))
+	succinctAPI.AssertIsEqualBool(field.IsZero(field.Sub(x, x)), vars.TRUE)
+	field.AssertIsEqual(field.Select(vars.FALSE, x, field.Neg(y)), field.Sub(field.Zero(), y))
+	field.AssertIsEqual(field.Mul(y, field.Inverse(y)), field.One())
+	return nil
+}
+
+func assertIsEqualBytes(api *builder.API, i1, i2 []vars.Byte) {
+	if len(i1) != len(i2) {
+		panic("lengths differ")
+	}
+	for i := range i1 {
+		api.AssertIsEqualByte(i1[i], i2[i])
+	}
+}
+
+func toBytesBE(i *big.Int, n int) []byte {
+	return i.FillBytes(make([]byte, n))
+}
+
+func toBytesLE(i *big.Int, n int) []byte {
+	b := toBytesBE(i, n)
+	for j := 0; j < n/2; j++ {
+		b[j], b[n-1-j] = b[n-1-j], b[j]
+	}
+	return b
+}
+
+func testNonNative[T emulated.FieldParams](t *testing.T) {
+	assert := test.NewAssert(t)
+	var params T
+	p := params.Modulus()
+	n := (p.BitLen() + 7) / 8
+
+	x := new(big.Int).Sub(p, big.NewInt(12345))
+	y := new(big.Int).Rsh(p, 3)
+	wide := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(8*(n+20))), big.NewInt(1))
+	product := new(big.Int).Mod(new(big.Int).Mul(x, y), p)
+	quotient := new(big.Int).Mod(new(big.Int).Mul(x, new(big.Int).ModInverse(y, p)), p)
+	reduced := new(big.Int).Mod(wide, p)
+
+	circuit := &TestNonNativeCircuit[T]{
+		X:        vars.NewBytesFrom(toBytesBE(x, n)),
+		Y:        vars.NewBytesFrom(toBytesBE(y, n)),
+		Wide:     vars.NewBytesFrom(toBytesBE(wide, n+20)),
+		Product:  vars.NewBytesFrom(toBytesBE(product, n)),
+		Quotient: vars.NewBytesFrom(toBytesLE(quotient, n)),
+		Reduced:  vars.NewBytesFrom(toBytesLE(reduced, n)),
+	}
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A product which is congruent but not canonical is rejected.
+	if product.BitLen() <= 8*n && new(big.Int).Add(product, p).BitLen() <= 8*n {
+		circuit.Product = vars.NewBytesFrom(toBytesBE(new(big.Int).Add(product, p), n))
+		assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestNonNative(t *testing.T) {
+	t.Run("secp256k1", testNonNative[Secp256k1Fp])
+	t.Run("ed25519", testNonNative[Ed25519Fr])
+	t.Run("bls12381", testNonNative[BLS12381Fp])
+}
@@ -0,0 +1,54 @@
+package nonnative
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// The parameters of the fields of the common curves, which fix the number of limbs and the bits
+// per limb of the emulated field elements.
+type (
+	// The base field of secp256k1.
+	Secp256k1Fp = emulated.Secp256k1Fp
+	// The scalar field of secp256k1.
+	Secp256k1Fr = emulated.Secp256k1Fr
+	// The base field of BN254.
+	BN254Fp = emulated.BN254Fp
+	// The scalar field of BN254, which is the native field of the circuits.
+	BN254Fr = emulated.BN254Fr
+	// The base field of BLS12-381.
+	BLS12381Fp = emulated.BLS12381Fp
+	// The scalar field of BLS12-381.
+	BLS12381Fr = emulated.BLS12381Fr
+)
+
+// The base field of Curve25519, i.e. integers modulo 2^255 - 19.
+type Ed25519Fp struct{}
+
+func (Ed25519Fp) NbLimbs() uint     { return 4 }
+func (Ed25519Fp) BitsPerLimb() uint { return 64 }
+func (Ed25519Fp) IsPrime() bool     { return true }
+func (Ed25519Fp) Modulus() *big.Int { return ed25519FpModulus }
+
+// The scalar field of the prime order subgroup of Curve25519, i.e. integers modulo
+// 2^252 + 27742317777372353535851937790883648493.
+type Ed25519Fr struct{}
+
+func (Ed25519Fr) NbLimbs() uint     { return 4 }
+func (Ed25519Fr) BitsPerLimb() uint { return 64 }
+func (Ed25519Fr) IsPrime() bool     { return true }
+func (Ed25519Fr) Modulus() *big.Int { return ed25519FrModulus }
+
+var ed25519FpModulus = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+var ed25519FrModulus, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// The field of the Poly1305 authenticator, i.e. integers modulo 2^130 - 5.
+type Poly1305Fp struct{}
+
+func (Poly1305Fp) NbLimbs() uint     { return 3 }
+func (Poly1305Fp) BitsPerLimb() uint { return 64 }
+func (Poly1305Fp) IsPrime() bool     { return true }
+func (Poly1305Fp) Modulus() *big.Int { return poly1305FpModulus }
+
+var poly1305FpModulus = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 130), big.NewInt(5))
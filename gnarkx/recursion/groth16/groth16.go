@@ -0,0 +1,116 @@
+// The API for verifying Groth16 proofs over BN254 inside a circuit over BN254, which allows proofs
+// of gnarkx circuits to be composed recursively. Since the curve of the proof is the same as the
+// one of the circuit, the pairing is computed over emulated field elements, so a single
+// verification costs a few million constraints.
+//
+// The verifier does not check the commitments of Groth16 proofs, so inner circuits must not have
+// any, and verifying keys and proofs with commitments are rejected. The gadgets of gnarkx do not
+// commit to the witness of R1CS circuits, see builder.API.SupportsCommitments, but gadgets of
+// gnark, e.g. its range checker, may.
+package groth16
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+var errCommitments = errors.New("proofs of circuits with commitments are not supported")
+
+// A Groth16 proof over BN254.
+type Proof = stdgroth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+
+// A Groth16 verifying key over BN254.
+type VerifyingKey = stdgroth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]
+
+// The public inputs of a Groth16 proof over BN254, which are emulated scalars.
+type Witness = stdgroth16.Witness[sw_bn254.Scalar]
+
+// Returns the proof as a value for the assignment of a circuit. Proofs with commitments are
+// rejected, since the verifier does not check them.
+func ValueOfProof(proof groth16.Proof) (Proof, error) {
+	if p, ok := proof.(*groth16_bn254.Proof); ok && len(p.Commitments) > 0 {
+		return Proof{}, errCommitments
+	}
+	return stdgroth16.ValueOfProof[sw_bn254.G1Affine, sw_bn254.G2Affine](proof)
+}
+
+// Returns the verifying key as a value for the assignment of a circuit. Verifying keys of circuits
+// with commitments are rejected, since the verifier does not check them.
+func ValueOfVerifyingKey(vk groth16.VerifyingKey) (VerifyingKey, error) {
+	if k, ok := vk.(*groth16_bn254.VerifyingKey); ok && len(k.PublicAndCommitmentCommitted) > 0 {
+		return VerifyingKey{}, errCommitments
+	}
+	return stdgroth16.ValueOfVerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](vk)
+}
+
+// Returns the public witness as a value for the assignment of a circuit.
+func ValueOfWitness(w witness.Witness) (Witness, error) {
+	return stdgroth16.ValueOfWitness[sw_bn254.Scalar, sw_bn254.G1Affine](w)
+}
+
+// Returns a placeholder for the verifying key of the inner circuit, which is needed to compile
+// the outer circuit since the size of the key depends on the number of public inputs.
+func PlaceholderVerifyingKey(ccs constraint.ConstraintSystem) VerifyingKey {
+	if len(ccs.GetCommitments().CommitmentIndexes()) > 0 {
+		panic(errCommitments)
+	}
+	return stdgroth16.PlaceholderVerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](ccs)
+}
+
+// Returns a placeholder for the public witness of the inner circuit.
+func PlaceholderWitness(ccs constraint.ConstraintSystem) Witness {
+	return stdgroth16.PlaceholderWitness[sw_bn254.Scalar](ccs)
+}
+
+// An API used for verifying Groth16 proofs.
+type API struct {
+	api      builder.API
+	fr       *emulated.Field[emulated.BN254Fr]
+	verifier *stdgroth16.Verifier[sw_bn254.Scalar, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]
+}
+
+// Creates a new groth16.API.
+func NewAPI(api *builder.API) *API {
+	frontendAPI := api.FrontendAPI()
+	fr, err := emulated.NewField[emulated.BN254Fr](frontendAPI)
+	if err != nil {
+		panic(err)
+	}
+	curve, err := sw_emulated.New[emulated.BN254Fp, emulated.BN254Fr](frontendAPI, sw_emulated.GetBN254Params())
+	if err != nil {
+		panic(err)
+	}
+	pairing, err := sw_bn254.NewPairing(frontendAPI)
+	if err != nil {
+		panic(err)
+	}
+	return &API{
+		api:      *api,
+		fr:       fr,
+		verifier: stdgroth16.NewVerifier[sw_bn254.Scalar, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](curve, pairing),
+	}
+}
+
+// Asserts that the proof is valid for the verifying key and the public inputs.
+func (a *API) VerifyProof(vk VerifyingKey, proof Proof, witness Witness) {
+	if err := a.verifier.AssertProof(vk, proof, witness); err != nil {
+		panic(err)
+	}
+}
+
+// Asserts that the i-th public input of the witness is equal to the native variable, which binds
+// the inputs of the inner proof to variables of the outer circuit.
+func (a *API) AssertPublicInputIsEqual(witness Witness, i int, value vars.Variable) {
+	bits := a.api.FrontendAPI().ToBinary(value.Value)
+	a.fr.AssertIsEqual(a.fr.FromBits(bits...), &witness.Public[i])
+}
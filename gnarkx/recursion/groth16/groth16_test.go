@@ -0,0 +1,127 @@
+package groth16
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Proves the knowledge of a factorization of N.
+type TestInnerCircuit struct {
+	P, Q vars.Variable
+	N    vars.Variable `gnark:",public"`
+}
+
+func (circuit *TestInnerCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	succinctAPI.AssertIsEqual(succinctAPI.Mul(circuit.P, circuit.Q), circuit.N)
+	succinctAPI.AssertIsDifferent(circuit.P, vars.ONE)
+	succinctAPI.AssertIsDifferent(circuit.Q, vars.ONE)
+	return nil
+}
+
+type TestVerifyProofCircuit struct {
+	VerifyingKey VerifyingKey
+	Proof        Proof
+	Witness      Witness
+	N            vars.Variable
+}
+
+func (circuit *TestVerifyProofCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	groth16API := NewAPI(succinctAPI)
+	groth16API.VerifyProof(circuit.VerifyingKey, circuit.Proof, circuit.Witness)
+	groth16API.AssertPublicInputIsEqual(circuit.Witness, 0, circuit.N)
+	return nil
+}
+
+func TestVerifyProof(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	field := ecc.BN254.ScalarField()
+	innerCcs, err := frontend.Compile(field, r1cs.NewBuilder, &TestInnerCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(innerCcs)
+	assert.NoError(err)
+	innerWitness, err := frontend.NewWitness(&TestInnerCircuit{
+		P: vars.NewVariableFromInt(3),
+		Q: vars.NewVariableFromInt(5),
+		N: vars.NewVariableFromInt(15),
+	}, field)
+	assert.NoError(err)
+	innerProof, err := groth16.Prove(innerCcs, pk, innerWitness)
+	assert.NoError(err)
+	innerPublicWitness, err := innerWitness.Public()
+	assert.NoError(err)
+
+	circuitVk, err := ValueOfVerifyingKey(vk)
+	assert.NoError(err)
+	circuitProof, err := ValueOfProof(innerProof)
+	assert.NoError(err)
+	circuitWitness, err := ValueOfWitness(innerPublicWitness)
+	assert.NoError(err)
+
+	circuit := &TestVerifyProofCircuit{
+		VerifyingKey: PlaceholderVerifyingKey(innerCcs),
+		Witness:      PlaceholderWitness(innerCcs),
+	}
+	assignment := &TestVerifyProofCircuit{
+		VerifyingKey: circuitVk,
+		Proof:        circuitProof,
+		Witness:      circuitWitness,
+		N:            vars.NewVariableFromInt(15),
+	}
+	assert.NoError(test.IsSolved(circuit, assignment, field))
+
+	// The public input must match the variable of the outer circuit.
+	assignment.N = vars.NewVariableFromInt(16)
+	assert.Error(test.IsSolved(circuit, assignment, field))
+
+	// The proof is not valid for another public input.
+	wrongWitness, err := frontend.NewWitness(&TestInnerCircuit{N: vars.NewVariableFromInt(21)}, field, frontend.PublicOnly())
+	assert.NoError(err)
+	assignment.Witness, err = ValueOfWitness(wrongWitness)
+	assert.NoError(err)
+	assignment.N = vars.NewVariableFromInt(21)
+	assert.Error(test.IsSolved(circuit, assignment, field))
+}
+
+// Commits to its input, which the verifier does not support.
+type TestCommitmentCircuit struct {
+	X vars.Variable `gnark:",public"`
+}
+
+func (circuit *TestCommitmentCircuit) Define(api frontend.API) error {
+	commitment, err := api.(frontend.Committer).Commit(circuit.X.Value)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, 0)
+	return nil
+}
+
+func TestRejectCommitments(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	field := ecc.BN254.ScalarField()
+	innerCcs, err := frontend.Compile(field, r1cs.NewBuilder, &TestCommitmentCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(innerCcs)
+	assert.NoError(err)
+	innerWitness, err := frontend.NewWitness(&TestCommitmentCircuit{X: vars.NewVariableFromInt(1)}, field)
+	assert.NoError(err)
+	innerProof, err := groth16.Prove(innerCcs, pk, innerWitness)
+	assert.NoError(err)
+
+	_, err = ValueOfVerifyingKey(vk)
+	assert.Error(err)
+	_, err = ValueOfProof(innerProof)
+	assert.Error(err)
+	assert.Panics(func() { PlaceholderVerifyingKey(innerCcs) })
+}
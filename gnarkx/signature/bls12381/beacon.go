@@ -0,0 +1,56 @@
+package bls12381
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The domain types of the beacon chain, see
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/phase0/beacon-chain.md#domain-types.
+var (
+	DOMAIN_BEACON_PROPOSER     = [4]byte{0x00, 0x00, 0x00, 0x00}
+	DOMAIN_BEACON_ATTESTER     = [4]byte{0x01, 0x00, 0x00, 0x00}
+	DOMAIN_RANDAO              = [4]byte{0x02, 0x00, 0x00, 0x00}
+	DOMAIN_DEPOSIT             = [4]byte{0x03, 0x00, 0x00, 0x00}
+	DOMAIN_VOLUNTARY_EXIT      = [4]byte{0x04, 0x00, 0x00, 0x00}
+	DOMAIN_SELECTION_PROOF     = [4]byte{0x05, 0x00, 0x00, 0x00}
+	DOMAIN_AGGREGATE_AND_PROOF = [4]byte{0x06, 0x00, 0x00, 0x00}
+	DOMAIN_SYNC_COMMITTEE      = [4]byte{0x07, 0x00, 0x00, 0x00}
+)
+
+// Computes the domain of a signature from its domain type, the fork version and the genesis
+// validators root, i.e. domainType || hash_tree_root(ForkData(forkVersion, root))[:28].
+func (a *API) ComputeDomain(domainType [4]byte, forkVersion [4]vars.Byte, genesisValidatorsRoot [32]vars.Byte) [32]vars.Byte {
+	// The fork data has two fields, so its root is the hash of the fork version padded to 32
+	// bytes and the genesis validators root.
+	in := make([]vars.Byte, 0, 64)
+	in = append(in, forkVersion[:]...)
+	for i := 4; i < 32; i++ {
+		in = append(in, vars.ZERO_BYTE)
+	}
+	in = append(in, genesisValidatorsRoot[:]...)
+	forkDataRoot := sha256.Hash(a.api, in)
+
+	var domain [32]vars.Byte
+	for i := 0; i < 4; i++ {
+		domain[i] = newByte(int(domainType[i]))
+	}
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// Computes the signing root of an object, i.e. hash_tree_root(SigningData(objectRoot, domain)),
+// which is the message signed by the validators.
+func (a *API) ComputeSigningRoot(objectRoot [32]vars.Byte, domain [32]vars.Byte) [32]vars.Byte {
+	in := make([]vars.Byte, 0, 64)
+	in = append(in, objectRoot[:]...)
+	in = append(in, domain[:]...)
+	return sha256.Hash(a.api, in)
+}
+
+// Asserts that sig is a valid aggregate signature of the object root in the given domain by the
+// participating public keys, e.g. the signature of a sync committee over a block root.
+func (a *API) VerifyAggregateWithDomain(pubKeys []PublicKey, participation []vars.Bool, objectRoot [32]vars.Byte, domain [32]vars.Byte, sig Signature) {
+	signingRoot := a.ComputeSigningRoot(objectRoot, domain)
+	a.VerifyAggregate(pubKeys, participation, signingRoot[:], sig)
+}
@@ -0,0 +1,98 @@
+// The API for verifying BLS signatures over BLS12-381 as used by the Ethereum consensus layer,
+// i.e. with public keys in G1, signatures in G2 and the proof of possession ciphersuite. The
+// pairing is computed over emulated field elements, so a single verification costs a few million
+// constraints.
+package bls12381
+
+import (
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A public key, which is a point in G1.
+type PublicKey = sw_bls12381.G1Affine
+
+// A signature, which is a point in G2.
+type Signature = sw_bls12381.G2Affine
+
+// An API used for operations related to BLS signatures.
+type API struct {
+	api     builder.API
+	fp      *emulated.Field[emulated.BLS12381Fp]
+	ext2    *fields_bls12381.Ext2
+	curve   *sw_emulated.Curve[emulated.BLS12381Fp, emulated.BLS12381Fr]
+	pairing *sw_bls12381.Pairing
+}
+
+// Creates a new bls12381.API.
+func NewAPI(api *builder.API) *API {
+	frontendAPI := api.FrontendAPI()
+	fp, err := emulated.NewField[emulated.BLS12381Fp](frontendAPI)
+	if err != nil {
+		panic(err)
+	}
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](frontendAPI, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pairing, err := sw_bls12381.NewPairing(frontendAPI)
+	if err != nil {
+		panic(err)
+	}
+	return &API{
+		api:     *api,
+		fp:      fp,
+		ext2:    fields_bls12381.NewExt2(frontendAPI),
+		curve:   curve,
+		pairing: pairing,
+	}
+}
+
+// Computes the sum of the public keys whose participation bit is set. The result is (0, 0) if no
+// public key participates. The public keys are assumed to be valid, i.e. in G1, as is the case for
+// the validator keys of the beacon chain.
+func (a *API) AggregatePublicKeys(pubKeys []PublicKey, participation []vars.Bool) PublicKey {
+	if len(pubKeys) != len(participation) {
+		panic("the number of public keys and participation bits must be equal")
+	}
+	result := &PublicKey{X: *a.fp.Zero(), Y: *a.fp.Zero()}
+	for i := range pubKeys {
+		sum := a.curve.AddUnified(result, &pubKeys[i])
+		result = a.curve.Select(participation[i].Value.Value, sum, result)
+	}
+	return *result
+}
+
+// Asserts that sig is a valid signature of the message by the public key, i.e. that
+// e(pubKey, H(msg)) == e(g1, sig). The signature is checked to be in G2.
+func (a *API) Verify(pubKey PublicKey, msg []vars.Byte, sig Signature) {
+	a.pairing.AssertIsOnG2(&sig)
+	hash := a.HashToG2(msg)
+	_, _, g1, _ := bls12381.Generators()
+	var negG1 bls12381.G1Affine
+	negG1.Neg(&g1)
+	negGenerator := sw_bls12381.NewG1Affine(negG1)
+	err := a.pairing.PairingCheck(
+		[]*sw_bls12381.G1Affine{&pubKey, &negGenerator},
+		[]*sw_bls12381.G2Affine{hash, &sig},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Asserts that sig is a valid aggregate signature of the message by the public keys whose
+// participation bit is set. At least one public key must participate.
+func (a *API) VerifyAggregate(pubKeys []PublicKey, participation []vars.Bool, msg []vars.Byte, sig Signature) {
+	var count vars.Variable = vars.ZERO
+	for i := range participation {
+		count = a.api.Add(count, participation[i].Value)
+	}
+	a.api.FrontendAPI().AssertIsDifferent(count.Value, 0)
+	a.Verify(a.AggregatePublicKeys(pubKeys, participation), msg, sig)
+}
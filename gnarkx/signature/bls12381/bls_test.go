@@ -0,0 +1,177 @@
+package bls12381
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestHashToG2Circuit struct {
+	Msg      []vars.Byte
+	Expected sw_bls12381.G2Affine
+}
+
+func (circuit *TestHashToG2Circuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bls := NewAPI(succinctAPI)
+	hash := bls.HashToG2(circuit.Msg)
+	bls.ext2.AssertIsEqual(&hash.X, &circuit.Expected.X)
+	bls.ext2.AssertIsEqual(&hash.Y, &circuit.Expected.Y)
+	return nil
+}
+
+func TestHashToG2(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := []byte("Succinct Labs")
+	expected, err := bls12381.HashToG2(msg, []byte(DST))
+	assert.NoError(err)
+
+	circuit := &TestHashToG2Circuit{Msg: vars.NewBytesFrom(msg), Expected: sw_bls12381.NewG2Affine(expected)}
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The hash of a different message does not match.
+	circuit.Msg = vars.NewBytesFrom([]byte("Succinct Lab$"))
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestBeaconCircuit struct {
+	PubKeys               []PublicKey
+	Participation         []vars.Bool
+	ForkVersion           [4]vars.Byte
+	GenesisValidatorsRoot [32]vars.Byte
+	ObjectRoot            [32]vars.Byte
+	Sig                   Signature
+}
+
+func (circuit *TestBeaconCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bls := NewAPI(succinctAPI)
+	domain := bls.ComputeDomain(DOMAIN_SYNC_COMMITTEE, circuit.ForkVersion, circuit.GenesisValidatorsRoot)
+	bls.VerifyAggregateWithDomain(circuit.PubKeys, circuit.Participation, circuit.ObjectRoot, domain, circuit.Sig)
+	return nil
+}
+
+func newBytes32(in []byte) [32]vars.Byte {
+	var result [32]vars.Byte
+	var value [32]byte
+	copy(value[:], in)
+	vars.SetBytes32(&result, value)
+	return result
+}
+
+// Computes the signing root of the object root in the sync committee domain natively.
+func signingRoot(forkVersion [4]byte, genesisValidatorsRoot, objectRoot [32]byte) [32]byte {
+	var forkData [64]byte
+	copy(forkData[:4], forkVersion[:])
+	copy(forkData[32:], genesisValidatorsRoot[:])
+	forkDataRoot := sha256.Sum256(forkData[:])
+	var signingData [64]byte
+	copy(signingData[:32], objectRoot[:])
+	copy(signingData[32:36], DOMAIN_SYNC_COMMITTEE[:])
+	copy(signingData[36:], forkDataRoot[:28])
+	return sha256.Sum256(signingData[:])
+}
+
+func newBeaconCircuit(participation []bool, objectRoot [32]byte) *TestBeaconCircuit {
+	forkVersion := [4]byte{0x03, 0x00, 0x00, 0x00}
+	genesisValidatorsRoot := sha256.Sum256([]byte("genesis"))
+	msg := signingRoot(forkVersion, genesisValidatorsRoot, sha256.Sum256([]byte("block")))
+	hash, err := bls12381.HashToG2(msg[:], []byte(DST))
+	if err != nil {
+		panic(err)
+	}
+
+	circuit := &TestBeaconCircuit{
+		GenesisValidatorsRoot: newBytes32(genesisValidatorsRoot[:]),
+		ObjectRoot:            newBytes32(objectRoot[:]),
+	}
+	for i := 0; i < 4; i++ {
+		circuit.ForkVersion[i] = vars.Byte{Value: vars.NewVariableFromInt(int(forkVersion[i]))}
+	}
+	var sig bls12381.G2Jac
+	for i := range participation {
+		sk := big.NewInt(int64(1000003 * (i + 1)))
+		var pk bls12381.G1Affine
+		pk.ScalarMultiplicationBase(sk)
+		circuit.PubKeys = append(circuit.PubKeys, sw_bls12381.NewG1Affine(pk))
+		circuit.Participation = append(circuit.Participation, vars.NewBool(participation[i]))
+		if participation[i] {
+			var partial bls12381.G2Affine
+			partial.ScalarMultiplication(&hash, sk)
+			var partialJac bls12381.G2Jac
+			partialJac.FromAffine(&partial)
+			sig.AddAssign(&partialJac)
+		}
+	}
+	var sigAffine bls12381.G2Affine
+	sigAffine.FromJacobian(&sig)
+	circuit.Sig = sw_bls12381.NewG2Affine(sigAffine)
+	return circuit
+}
+
+func TestVerifyAggregateWithDomain(t *testing.T) {
+	assert := test.NewAssert(t)
+	blockRoot := sha256.Sum256([]byte("block"))
+
+	circuit := newBeaconCircuit([]bool{true, false, true}, blockRoot)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The signature does not verify for a different participation or object root.
+	wrongParticipation := newBeaconCircuit([]bool{true, false, true}, blockRoot)
+	wrongParticipation.Participation[1] = vars.TRUE
+	assert.Error(test.IsSolved(wrongParticipation, wrongParticipation, ecc.BN254.ScalarField()))
+	wrongRoot := newBeaconCircuit([]bool{true, false, true}, sha256.Sum256([]byte("other block")))
+	assert.Error(test.IsSolved(wrongRoot, wrongRoot, ecc.BN254.ScalarField()))
+}
+
+type TestDecompressPublicKeyCircuit struct {
+	In       [PublicKeySize]vars.Byte
+	Expected PublicKey
+}
+
+func (circuit *TestDecompressPublicKeyCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	bls := NewAPI(succinctAPI)
+	pubKey := bls.DecompressPublicKey(circuit.In)
+	bls.fp.AssertIsEqual(&pubKey.X, &circuit.Expected.X)
+	bls.fp.AssertIsEqual(&pubKey.Y, &circuit.Expected.Y)
+	return nil
+}
+
+func newDecompressPublicKeyCircuit(compressed [PublicKeySize]byte, pk bls12381.G1Affine) *TestDecompressPublicKeyCircuit {
+	circuit := &TestDecompressPublicKeyCircuit{Expected: sw_bls12381.NewG1Affine(pk)}
+	copy(circuit.In[:], vars.NewBytesFrom(compressed[:]))
+	return circuit
+}
+
+func TestDecompressPublicKey(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Both signs of the y-coordinate are covered by a key and its negation.
+	var pk bls12381.G1Affine
+	pk.ScalarMultiplicationBase(big.NewInt(1000003))
+	var negPk bls12381.G1Affine
+	negPk.Neg(&pk)
+	for _, p := range []bls12381.G1Affine{pk, negPk} {
+		circuit := newDecompressPublicKeyCircuit(p.Bytes(), p)
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	// A key with the wrong sign or without the compression flag is rejected.
+	compressed := pk.Bytes()
+	compressed[0] ^= 1 << signFlagBit
+	circuit := newDecompressPublicKeyCircuit(compressed, pk)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	compressed = pk.Bytes()
+	compressed[0] ^= 1 << compressionFlagBit
+	circuit = newDecompressPublicKeyCircuit(compressed, pk)
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
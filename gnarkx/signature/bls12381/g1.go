@@ -0,0 +1,81 @@
+package bls12381
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+func init() {
+	solver.RegisterHint(sqrtHint)
+}
+
+// The size of a compressed public key.
+const PublicKeySize = 48
+
+// The flags in the three most significant bits of a compressed point, see
+// https://github.com/zkcrypto/pairing/tree/master/src/bls12_381#serialization.
+const (
+	compressionFlagBit = 7
+	infinityFlagBit    = 6
+	signFlagBit        = 5
+)
+
+// Decompresses a public key from its 48-byte serialization, i.e. the big-endian x-coordinate with
+// the compression, infinity and sign flags in its three most significant bits. Asserts that the
+// key is a compressed point on the curve other than the point at infinity. The key is not checked
+// to be in G1, which is left to the caller, e.g. the beacon chain validates the keys of its
+// validators upon deposit.
+func (a *API) DecompressPublicKey(in [PublicKeySize]vars.Byte) PublicKey {
+	frontendAPI := a.api.FrontendAPI()
+	bits := make([]frontend.Variable, 0, 8*PublicKeySize)
+	for i := PublicKeySize - 1; i >= 0; i-- {
+		byteBits := a.api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			bits = append(bits, byteBits[j].Value.Value)
+		}
+	}
+	flags := bits[8*PublicKeySize-8:]
+	frontendAPI.AssertIsEqual(flags[compressionFlagBit], 1)
+	frontendAPI.AssertIsEqual(flags[infinityFlagBit], 0)
+	x := a.fp.FromBits(bits[:8*PublicKeySize-3]...)
+
+	var params emulated.BLS12381Fp
+	pMinusOne := new(big.Int).Sub(params.Modulus(), big.NewInt(1))
+	a.fp.AssertIsLessOrEqual(x, a.fp.NewElement(pMinusOne))
+
+	// The hint returns the smaller square root y of x^3 + 4, and the sign flag is set iff the
+	// y-coordinate is the larger one, i.e. -y.
+	rhs := a.fp.Add(a.fp.Mul(a.fp.Mul(x, x), x), a.fp.NewElement(4))
+	outputs, err := a.fp.NewHint(sqrtHint, 1, rhs)
+	if err != nil {
+		panic(err)
+	}
+	y := outputs[0]
+	a.fp.AssertIsEqual(a.fp.Mul(y, y), rhs)
+	a.fp.AssertIsLessOrEqual(y, a.fp.NewElement(new(big.Int).Rsh(pMinusOne, 1)))
+	y = a.fp.Select(flags[signFlagBit], a.fp.Neg(y), y)
+	return PublicKey{X: *x, Y: *y}
+}
+
+// sqrtHint computes the square root of an element of Fp which is at most (p - 1) / 2.
+func sqrtHint(_ *big.Int, nativeInputs, nativeOutputs []*big.Int) error {
+	return emulated.UnwrapHint(nativeInputs, nativeOutputs, func(_ *big.Int, inputs, outputs []*big.Int) error {
+		var x, y, negY fp.Element
+		x.SetBigInt(inputs[0])
+		if y.Sqrt(&x) == nil {
+			outputs[0].SetUint64(0)
+			return nil
+		}
+		negY.Neg(&y)
+		if y.LexicographicallyLargest() {
+			y = negY
+		}
+		y.BigInt(outputs[0])
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package bls12381
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// The arithmetic on G2 points uses the incomplete affine formulas, which is sufficient for the
+// points in hashToG2 as they are derived from hash outputs and only hit an exceptional case with
+// negligible probability.
+
+type e2 = fields_bls12381.E2
+
+// The absolute value of the BLS parameter x = -0xd201000000010000.
+const seed = uint64(0xd201000000010000)
+
+var (
+	// A primitive third root of unity in Fp, which is used by ψ².
+	thirdRootOne = emulated.ValueOf[emulated.BLS12381Fp]("4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939436")
+
+	// The constants of the endomorphism ψ.
+	psiU = emulated.ValueOf[emulated.BLS12381Fp]("4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939437")
+	psiV = e2{
+		A0: emulated.ValueOf[emulated.BLS12381Fp]("2973677408986561043442465346520108879172042883009249989176415018091420807192182638567116318576472649347015917690530"),
+		A1: emulated.ValueOf[emulated.BLS12381Fp]("1028732146235106349975324479215795277384839936929757896155643118032610843298655225875571310552543014690878354869257"),
+	}
+)
+
+// Computes p + q, where p != ±q.
+func (a *API) addG2(p, q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	lambda := a.ext2.DivUnchecked(a.ext2.Sub(&q.Y, &p.Y), a.ext2.Sub(&q.X, &p.X))
+	x := a.ext2.Sub(a.ext2.Square(lambda), a.ext2.Add(&p.X, &q.X))
+	y := a.ext2.Sub(a.ext2.Mul(lambda, a.ext2.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+// Computes 2p, where p is not of order 2.
+func (a *API) doubleG2(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	xx3 := a.ext2.MulByConstElement(a.ext2.Square(&p.X), big.NewInt(3))
+	lambda := a.ext2.DivUnchecked(xx3, a.ext2.Double(&p.Y))
+	x := a.ext2.Sub(a.ext2.Square(lambda), a.ext2.Double(&p.X))
+	y := a.ext2.Sub(a.ext2.Mul(lambda, a.ext2.Sub(&p.X, x)), &p.Y)
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+// Computes -p.
+func (a *API) negG2(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return &sw_bls12381.G2Affine{X: p.X, Y: *a.ext2.Neg(&p.Y)}
+}
+
+// Computes p - q, where p != ±q.
+func (a *API) subG2(p, q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return a.addG2(p, a.negG2(q))
+}
+
+// Computes the untwist-Frobenius-twist endomorphism ψ(p).
+func (a *API) psi(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	x := a.ext2.MulByElement(&p.X, &psiU)
+	y := a.ext2.Mul(a.ext2.Conjugate(&p.Y), &psiV)
+	return &sw_bls12381.G2Affine{X: e2{A0: x.A1, A1: x.A0}, Y: *y}
+}
+
+// Computes ψ²(p) = (w * x, -y), where w is a primitive third root of unity.
+func (a *API) psi2(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	x := a.ext2.MulByElement(&p.X, &thirdRootOne)
+	return &sw_bls12381.G2Affine{X: *x, Y: *a.ext2.Neg(&p.Y)}
+}
+
+// Computes [x]p for the (negative) BLS parameter x by double-and-add over its bits.
+func (a *API) scalarMulBySeed(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	result := p
+	for i := 62; i >= 0; i-- {
+		result = a.doubleG2(result)
+		if seed&(1<<i) != 0 {
+			result = a.addG2(result, p)
+		}
+	}
+	return a.negG2(result)
+}
+
+// Maps a point of the curve to G2 by multiplying it with the effective cofactor, i.e.
+// [x² - x - 1]p + [x - 1]ψ(p) + ψ²(2p) following https://eprint.iacr.org/2017/419.pdf.
+func (a *API) clearCofactor(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	xp := a.scalarMulBySeed(p)
+	xxp := a.scalarMulBySeed(xp)
+	result := a.subG2(a.subG2(xxp, xp), p)
+	result = a.addG2(result, a.psi(a.subG2(xp, p)))
+	return a.addG2(result, a.psi2(a.doubleG2(p)))
+}
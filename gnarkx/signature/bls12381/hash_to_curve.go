@@ -0,0 +1,255 @@
+package bls12381
+
+import (
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// Hashing to G2 according to the BLS12381G2_XMD:SHA-256_SSWU_RO_ suite of
+// https://datatracker.ietf.org/doc/html/rfc9380.
+
+func init() {
+	solver.RegisterHint(mapToCurveHint)
+}
+
+// The domain separation tag of the proof of possession scheme, which is used by Ethereum.
+const DST = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+// The number of bytes expanded from the message, i.e. 2 field elements of Fp2 with 64 bytes each.
+const expandLength = 256
+
+func newE2(a0, a1 string) e2 {
+	return e2{A0: emulated.ValueOf[emulated.BLS12381Fp](a0), A1: emulated.ValueOf[emulated.BLS12381Fp](a1)}
+}
+
+var (
+	// The non-square Z and the coefficients of the curve E' isogenous to E2.
+	sswuZ = newE2("4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559785", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559786")
+	sswuA = newE2("0", "240")
+	sswuB = newE2("1012", "1012")
+
+	// The coefficients of the 3-isogeny map from E' to E2 from the lowest degree up. The
+	// denominators are monic, so their leading coefficient is omitted.
+	isoXNum = []e2{
+		newE2("889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542", "889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542"),
+		newE2("0", "2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706522"),
+		newE2("2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706526", "1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853261"),
+		newE2("3557697382419259905260257622876359250272784728834673675850718343221361467102966990615722337003569479144794908942033", "0"),
+	}
+	isoXDen = []e2{
+		newE2("0", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559715"),
+		newE2("12", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559775"),
+	}
+	isoYNum = []e2{
+		newE2("3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558", "3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558"),
+		newE2("0", "889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235518"),
+		newE2("2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706524", "1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853263"),
+		newE2("2816510427748580758331037284777117739799287910327449993381818688383577828123182200904113516794492504322962636245776", "0"),
+	}
+	isoYDen = []e2{
+		newE2("4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559355", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559355"),
+		newE2("0", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559571"),
+		newE2("18", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559769"),
+	}
+)
+
+// Hashes the message to a point in G2.
+func (a *API) HashToG2(msg []vars.Byte) *sw_bls12381.G2Affine {
+	u := a.hashToField(msg)
+	q0 := a.isogeny(a.mapToCurve(&u[0]))
+	q1 := a.isogeny(a.mapToCurve(&u[1]))
+	return a.clearCofactor(a.addG2(q0, q1))
+}
+
+// Hashes the message to two elements of Fp2.
+func (a *API) hashToField(msg []vars.Byte) [2]e2 {
+	uniform := a.expandMessage(msg)
+	var elements [4]*emulated.Element[emulated.BLS12381Fp]
+	for i := 0; i < 4; i++ {
+		elements[i] = a.fromBytes(uniform[64*i : 64*(i+1)])
+	}
+	return [2]e2{{A0: *elements[0], A1: *elements[1]}, {A0: *elements[2], A1: *elements[3]}}
+}
+
+// Computes expand_message_xmd with SHA-256, expanding the message into expandLength bytes.
+func (a *API) expandMessage(msg []vars.Byte) []vars.Byte {
+	dstPrime := append(vars.NewBytesFrom([]byte(DST)), newByte(len(DST)))
+
+	var in []vars.Byte
+	for i := 0; i < 64; i++ {
+		in = append(in, vars.ZERO_BYTE)
+	}
+	in = append(in, msg...)
+	in = append(in, newByte(expandLength>>8), newByte(expandLength&0xff), vars.ZERO_BYTE)
+	in = append(in, dstPrime...)
+	b0 := sha256.Hash(a.api, in)
+
+	var result []vars.Byte
+	b := make([]vars.Byte, 32)
+	for i := 1; i <= expandLength/32; i++ {
+		// b_1 = H(b_0 || 1 || DST'), and b_i = H((b_0 ^ b_(i - 1)) || i || DST').
+		in = make([]vars.Byte, 0, 32+1+len(dstPrime))
+		for j := 0; j < 32; j++ {
+			if i == 1 {
+				in = append(in, b0[j])
+			} else {
+				in = append(in, a.xorByte(b0[j], b[j]))
+			}
+		}
+		in = append(in, newByte(i))
+		in = append(in, dstPrime...)
+		digest := sha256.Hash(a.api, in)
+		copy(b, digest[:])
+		result = append(result, digest[:]...)
+	}
+	return result
+}
+
+func newByte(i int) vars.Byte {
+	return vars.Byte{Value: vars.NewVariableFromInt(i)}
+}
+
+func (a *API) xorByte(x, y vars.Byte) vars.Byte {
+	xBits := a.api.ToBitsFromByte(x)
+	yBits := a.api.ToBitsFromByte(y)
+	var bits [8]vars.Bool
+	for i := 0; i < 8; i++ {
+		bits[i] = a.api.Xor(xBits[i], yBits[i])
+	}
+	return a.api.ToByteFromBits(bits)
+}
+
+// Reduces 64 big-endian bytes modulo p by splitting them into the low 384 bits and the high 128
+// bits, so that the result is low + high * 2^384.
+func (a *API) fromBytes(in []vars.Byte) *emulated.Element[emulated.BLS12381Fp] {
+	bits := make([]frontend.Variable, 8*len(in))
+	for i := 0; i < len(in); i++ {
+		byteBits := a.api.ToBitsFromByte(in[len(in)-1-i])
+		for j := 0; j < 8; j++ {
+			bits[8*i+j] = byteBits[j].Value.Value
+		}
+	}
+	high := make([]frontend.Variable, 384)
+	for i := range high {
+		high[i] = 0
+	}
+	copy(high, bits[384:])
+	var params emulated.BLS12381Fp
+	shift := new(big.Int).Lsh(big.NewInt(1), 384)
+	shift.Mod(shift, params.Modulus())
+	result := a.fp.MulMod(a.fp.FromBits(high...), a.fp.NewElement(shift))
+	return a.fp.Add(a.fp.FromBits(bits[:384]...), result)
+}
+
+// Maps an element of Fp2 to the curve E' isogenous to E2 with the simplified SWU map. The point
+// is computed by a hint and then constrained to be on E', to have the x-coordinate x1 or x2 of
+// the map and to have the same sign as u. As exactly one of g(x1) and g(x2) is a square, this
+// determines the point uniquely.
+func (a *API) mapToCurve(u *e2) *sw_bls12381.G2Affine {
+	outputs, err := a.fp.NewHint(mapToCurveHint, 4, &u.A0, &u.A1)
+	if err != nil {
+		panic(err)
+	}
+	x := &e2{A0: *outputs[0], A1: *outputs[1]}
+	y := &e2{A0: *outputs[2], A1: *outputs[3]}
+
+	// y^2 = x^3 + A' * x + B'.
+	rhs := a.ext2.Mul(a.ext2.Square(x), x)
+	rhs = a.ext2.Add(rhs, a.ext2.Mul(&sswuA, x))
+	rhs = a.ext2.Add(rhs, &sswuB)
+	a.ext2.AssertIsEqual(a.ext2.Square(y), rhs)
+
+	// x = x1 = tv3 / tv4 or x = x2 = tv1 * x1, where tv1 = Z * u^2, tv2 = tv1^2 + tv1,
+	// tv3 = B' * (tv2 + 1) and tv4 = A' * (tv2 != 0 ? -tv2 : Z).
+	tv1 := a.ext2.Mul(&sswuZ, a.ext2.Square(u))
+	tv2 := a.ext2.Add(a.ext2.Square(tv1), tv1)
+	tv3 := a.ext2.Mul(&sswuB, a.ext2.Add(tv2, a.ext2.One()))
+	tv4 := a.ext2.Select(a.isZero(tv2), &sswuZ, a.ext2.Neg(tv2))
+	tv4 = a.ext2.Mul(&sswuA, tv4)
+	xTv4 := a.ext2.Mul(x, tv4)
+	isX1 := a.ext2.Sub(xTv4, tv3)
+	isX2 := a.ext2.Sub(xTv4, a.ext2.Mul(tv1, tv3))
+	a.ext2.AssertIsEqual(a.ext2.Mul(isX1, isX2), a.ext2.Zero())
+
+	a.api.FrontendAPI().AssertIsEqual(a.sgn0(y), a.sgn0(u))
+	return &sw_bls12381.G2Affine{X: *x, Y: *y}
+}
+
+// Maps a point of E' to E2 with the 3-isogeny.
+func (a *API) isogeny(p *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	xNum := a.evalPolynomial(isoXNum, false, &p.X)
+	xDen := a.evalPolynomial(isoXDen, true, &p.X)
+	yNum := a.ext2.Mul(a.evalPolynomial(isoYNum, false, &p.X), &p.Y)
+	yDen := a.evalPolynomial(isoYDen, true, &p.X)
+	return &sw_bls12381.G2Affine{X: *a.ext2.DivUnchecked(xNum, xDen), Y: *a.ext2.DivUnchecked(yNum, yDen)}
+}
+
+// Evaluates the polynomial with the given coefficients at x with Horner's method. If monic is
+// set, the polynomial has an additional leading coefficient 1.
+func (a *API) evalPolynomial(coefficients []e2, monic bool, x *e2) *e2 {
+	result := &coefficients[len(coefficients)-1]
+	if monic {
+		result = a.ext2.Add(result, x)
+	}
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = a.ext2.Add(a.ext2.Mul(result, x), &coefficients[i])
+	}
+	return result
+}
+
+// Returns the sign of an element of Fp2, i.e. the parity of A0, or of A1 if A0 is zero.
+func (a *API) sgn0(x *e2) frontend.Variable {
+	frontendAPI := a.api.FrontendAPI()
+	a0 := a.canonical(&x.A0)
+	a1 := a.canonical(&x.A1)
+	sign0 := frontendAPI.ToBinary(a0.Limbs[0], 64)[0]
+	sign1 := frontendAPI.ToBinary(a1.Limbs[0], 64)[0]
+	return frontendAPI.Or(sign0, frontendAPI.And(a.isZeroElement(a0), sign1))
+}
+
+// Returns whether an element of Fp2 is zero.
+func (a *API) isZero(x *e2) frontend.Variable {
+	return a.api.FrontendAPI().And(a.isZeroElement(a.canonical(&x.A0)), a.isZeroElement(a.canonical(&x.A1)))
+}
+
+// Returns whether a canonical element is zero.
+func (a *API) isZeroElement(x *emulated.Element[emulated.BLS12381Fp]) frontend.Variable {
+	frontendAPI := a.api.FrontendAPI()
+	var result frontend.Variable = 1
+	for _, limb := range x.Limbs {
+		result = frontendAPI.And(result, frontendAPI.IsZero(limb))
+	}
+	return result
+}
+
+// Reduces an element to its canonical representation in [0, p).
+func (a *API) canonical(x *emulated.Element[emulated.BLS12381Fp]) *emulated.Element[emulated.BLS12381Fp] {
+	reduced := a.fp.Reduce(x)
+	// The reduction only guarantees that the value is congruent to the input, so additionally
+	// assert that it is less than the modulus.
+	var params emulated.BLS12381Fp
+	a.fp.AssertIsLessOrEqual(reduced, a.fp.NewElement(new(big.Int).Sub(params.Modulus(), big.NewInt(1))))
+	return reduced
+}
+
+// mapToCurveHint computes the simplified SWU map of an element of Fp2 to E'.
+func mapToCurveHint(_ *big.Int, nativeInputs, nativeOutputs []*big.Int) error {
+	return emulated.UnwrapHint(nativeInputs, nativeOutputs, func(_ *big.Int, inputs, outputs []*big.Int) error {
+		var u bls12381.E2
+		u.A0.SetBigInt(inputs[0])
+		u.A1.SetBigInt(inputs[1])
+		p := bls12381.MapToCurve2(&u)
+		p.X.A0.BigInt(outputs[0])
+		p.X.A1.BigInt(outputs[1])
+		p.Y.A0.BigInt(outputs[2])
+		p.Y.A1.BigInt(outputs[3])
+		return nil
+	})
+}
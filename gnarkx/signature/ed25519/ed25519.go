@@ -0,0 +1,189 @@
+// The API for verifying ed25519 signatures according to https://datatracker.ietf.org/doc/html/rfc8032,
+// as used by Tendermint and Solana. The curve arithmetic is emulated with non-native field
+// elements and the challenge is computed with the SHA-512 gadget.
+package ed25519
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha512"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A signature as the encoded point R and the little-endian scalar S.
+type Signature struct {
+	R [32]vars.Byte
+	S [32]vars.Byte
+}
+
+// A point in affine coordinates on the twisted Edwards curve.
+type point struct {
+	x, y *emulated.Element[Ed25519Fp]
+}
+
+// An API used for operations related to ed25519 signatures.
+type API struct {
+	api builder.API
+	fp  *emulated.Field[Ed25519Fp]
+	fr  *emulated.Field[Ed25519Fr]
+}
+
+// Creates a new ed25519.API.
+func NewAPI(api *builder.API) *API {
+	fp, err := emulated.NewField[Ed25519Fp](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[Ed25519Fr](api.FrontendAPI())
+	if err != nil {
+		panic(err)
+	}
+	return &API{api: *api, fp: fp, fr: fr}
+}
+
+// Asserts that sig is a valid signature of the message by the encoded public key. Note that at
+// compile time of the circuit, len(msg) must be a constant.
+func (a *API) Verify(pubKey [32]vars.Byte, msg []vars.Byte, sig Signature) {
+	in := append(append(sig.R[:], pubKey[:]...), msg...)
+	a.verify(pubKey, sha512.Hash(a.api, in), sig, vars.ONE)
+}
+
+// Asserts that sig is a valid signature of msg[:length] by the encoded public key. The remaining
+// bytes of msg are ignored, so len(msg) is the maximum length of the message and must be a
+// constant at compile time of the circuit.
+func (a *API) VerifyVariable(pubKey [32]vars.Byte, msg []vars.Byte, length vars.Variable, sig Signature) {
+	in := append(append(sig.R[:], pubKey[:]...), msg...)
+	a.verify(pubKey, sha512.HashVariable(a.api, in, a.api.Add(length, vars.NewVariableFromInt(64))), sig, vars.ONE)
+}
+
+// Asserts that sigs[i] is a valid signature of msgs[i] by pubKeys[i] for each i whose enabled bit
+// is set, e.g. for the members of a validator set who signed a commit. The entries which are not
+// enabled may hold arbitrary bytes and message lengths, so the number of entries is a constant at
+// compile time of the circuit while the enabled entries are variables.
+func (a *API) VerifyBatch(pubKeys [][32]vars.Byte, msgs []vars.VariableBytes, sigs []Signature, isEnabled []vars.Bool) {
+	if len(msgs) != len(pubKeys) || len(sigs) != len(pubKeys) || len(isEnabled) != len(pubKeys) {
+		panic("the numbers of public keys, messages, signatures and enabled bits must be equal")
+	}
+	for i := range pubKeys {
+		// The public key, S and the message length of a disabled entry are replaced by the base
+		// point and zero, so that the decompression, the range check of S and the length
+		// constraints of the hash hold regardless of its values.
+		var pubKey [32]vars.Byte
+		var sig Signature
+		sig.R = sigs[i].R
+		for j := 0; j < 32; j++ {
+			pubKey[j] = a.api.SelectByte(isEnabled[i], pubKeys[i][j], vars.Byte{Value: vars.NewVariableFromInt(int(baseEncoding[j]))})
+			sig.S[j] = a.api.SelectByte(isEnabled[i], sigs[i].S[j], vars.ZERO_BYTE)
+		}
+		in := append(append(sig.R[:], pubKey[:]...), msgs[i].Data...)
+		length := a.api.Select(isEnabled[i], msgs[i].Length, vars.ZERO)
+		digest := sha512.HashVariable(a.api, in, a.api.Add(length, vars.NewVariableFromInt(64)))
+		a.verify(pubKey, digest, sig, isEnabled[i].Value)
+	}
+}
+
+// Checks the cofactorless verification equation [S]B = R + [k]A by computing [S]B - [k]A and
+// comparing its encoding with R, where k = SHA-512(R || A || M) mod L. The comparison is only
+// asserted if isEnabled is set.
+func (a *API) verify(pubKey [32]vars.Byte, digest [64]vars.Byte, sig Signature, isEnabled vars.Variable) {
+	pk := a.decompress(pubKey)
+
+	// S must be canonical, i.e. less than L, which also means that its top 3 bits are zero.
+	sBits := a.toBits(sig.S[:])
+	s := a.fr.FromBits(sBits...)
+	a.fr.AssertIsLessOrEqual(s, a.fr.NewElement(new(big.Int).Sub(frModulus, big.NewInt(1))))
+
+	// The digest is a 512-bit little-endian integer, so k = low + high * 2^256 mod L.
+	digestBits := a.toBits(digest[:])
+	low := a.fr.FromBits(digestBits[:256]...)
+	high := a.fr.FromBits(digestBits[256:]...)
+	shift := new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 256), frModulus)
+	k := a.fr.Add(low, a.fr.Mul(high, a.fr.NewElement(shift)))
+	kBits := canonicalBits(a.fr, k)
+
+	// Joint double-and-add over the table {O, B, -A, B - A}.
+	base := &point{x: a.fp.NewElement(baseX), y: a.fp.NewElement(baseY)}
+	negPk := &point{x: a.fp.Neg(pk.x), y: pk.y}
+	table := [4]*point{
+		{x: a.fp.Zero(), y: a.fp.One()},
+		base,
+		negPk,
+		a.add(base, negPk),
+	}
+	acc := table[0]
+	for i := 252; i >= 0; i-- {
+		acc = a.add(acc, acc)
+		acc = a.add(acc, &point{
+			x: a.fp.Lookup2(sBits[i], kBits[i], table[0].x, table[1].x, table[2].x, table[3].x),
+			y: a.fp.Lookup2(sBits[i], kBits[i], table[0].y, table[1].y, table[2].y, table[3].y),
+		})
+	}
+
+	// The encoding of a point is y in little-endian with the parity of x in the top bit.
+	xBits := canonicalBits(a.fp, acc.x)
+	yBits := canonicalBits(a.fp, acc.y)
+	rBits := a.toBits(sig.R[:])
+	frontendAPI := a.api.FrontendAPI()
+	for i := 0; i < 255; i++ {
+		frontendAPI.AssertIsEqual(frontendAPI.Mul(isEnabled.Value, frontendAPI.Sub(rBits[i], yBits[i])), 0)
+	}
+	frontendAPI.AssertIsEqual(frontendAPI.Mul(isEnabled.Value, frontendAPI.Sub(rBits[255], xBits[0])), 0)
+}
+
+// Decodes a point, asserting that the encoding is canonical and that the point is on the curve.
+func (a *API) decompress(in [32]vars.Byte) *point {
+	frontendAPI := a.api.FrontendAPI()
+	bits := a.toBits(in[:])
+	y := a.fp.FromBits(bits[:255]...)
+	a.fp.AssertIsLessOrEqual(y, a.fp.NewElement(new(big.Int).Sub(fpModulus, big.NewInt(1))))
+	sign := bits[255]
+
+	// x^2 = (y^2 - 1) / (d * y^2 + 1), where the denominator is never zero. The square root does
+	// not exist iff the point is not on the curve, in which case the circuit is not satisfiable.
+	y2 := a.fp.Mul(y, y)
+	u := a.fp.Sub(y2, a.fp.One())
+	v := a.fp.Add(a.fp.Mul(y2, a.fp.NewElement(curveD)), a.fp.One())
+	x := a.fp.Sqrt(a.fp.Div(u, v))
+
+	// Choose the root whose parity matches the sign bit. If x is zero, the sign bit must be zero.
+	parity := canonicalBits(a.fp, x)[0]
+	x = a.fp.Select(frontendAPI.Xor(parity, sign), a.fp.Neg(x), x)
+	frontendAPI.AssertIsEqual(canonicalBits(a.fp, x)[0], sign)
+	return &point{x: x, y: y}
+}
+
+// Adds two points with the complete addition formula for twisted Edwards curves with a = -1.
+func (a *API) add(p, q *point) *point {
+	x1y2 := a.fp.Mul(p.x, q.y)
+	y1x2 := a.fp.Mul(p.y, q.x)
+	x1x2 := a.fp.Mul(p.x, q.x)
+	y1y2 := a.fp.Mul(p.y, q.y)
+	dxy := a.fp.Mul(a.fp.Mul(x1x2, y1y2), a.fp.NewElement(curveD))
+	return &point{
+		x: a.fp.Div(a.fp.Add(x1y2, y1x2), a.fp.Add(a.fp.One(), dxy)),
+		y: a.fp.Div(a.fp.Add(y1y2, x1x2), a.fp.Sub(a.fp.One(), dxy)),
+	}
+}
+
+// Decomposes little-endian bytes into little-endian bits.
+func (a *API) toBits(in []vars.Byte) []frontend.Variable {
+	bits := make([]frontend.Variable, 8*len(in))
+	for i := 0; i < len(in); i++ {
+		byteBits := a.api.ToBitsFromByte(in[i])
+		for j := 0; j < 8; j++ {
+			bits[8*i+j] = byteBits[j].Value.Value
+		}
+	}
+	return bits
+}
+
+// Returns the little-endian bits of the canonical representation of an emulated field element.
+func canonicalBits[T emulated.FieldParams](field *emulated.Field[T], in *emulated.Element[T]) []frontend.Variable {
+	var params T
+	reduced := field.Reduce(in)
+	field.AssertIsLessOrEqual(reduced, field.NewElement(new(big.Int).Sub(params.Modulus(), big.NewInt(1))))
+	return field.ToBits(reduced)
+}
@@ -0,0 +1,128 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestEd25519Circuit struct {
+	PubKey   [32]vars.Byte
+	Msg      []vars.Byte
+	Length   vars.Variable
+	Sig      Signature
+	Variable bool `gnark:"-"`
+}
+
+func (circuit *TestEd25519Circuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	ed25519 := NewAPI(succinctAPI)
+	if circuit.Variable {
+		ed25519.VerifyVariable(circuit.PubKey, circuit.Msg, circuit.Length, circuit.Sig)
+	} else {
+		ed25519.Verify(circuit.PubKey, circuit.Msg, circuit.Sig)
+	}
+	return nil
+}
+
+func newBytes32(in []byte) [32]vars.Byte {
+	var result [32]vars.Byte
+	var value [32]byte
+	copy(value[:], in)
+	vars.SetBytes32(&result, value)
+	return result
+}
+
+func newEd25519Circuit(msg []byte, length int, variable bool) *TestEd25519Circuit {
+	key := ed25519.NewKeyFromSeed([]byte("succinct labs ed25519 test seed!"))
+	sig := ed25519.Sign(key, msg[:length])
+	return &TestEd25519Circuit{
+		PubKey:   newBytes32(key.Public().(ed25519.PublicKey)),
+		Msg:      vars.NewBytesFrom(msg),
+		Length:   vars.NewVariableFromInt(length),
+		Sig:      Signature{R: newBytes32(sig[:32]), S: newBytes32(sig[32:])},
+		Variable: variable,
+	}
+}
+
+func TestEd25519(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := []byte("Succinct Labs")
+
+	circuit := newEd25519Circuit(msg, len(msg), false)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// A signature of a different message is rejected.
+	witness := newEd25519Circuit(msg, len(msg), false)
+	witness.Msg = vars.NewBytesFrom([]byte("Succinct Lab$"))
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
+
+func TestEd25519Variable(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := []byte("jtguibas loves polynomials")
+
+	circuit := newEd25519Circuit(msg, 8, true)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	witness := newEd25519Circuit(msg, 8, true)
+	witness.Length = vars.NewVariableFromInt(9)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
+
+type TestEd25519BatchCircuit struct {
+	PubKeys   [][32]vars.Byte
+	Msgs      []vars.VariableBytes
+	Sigs      []Signature
+	IsEnabled []vars.Bool
+}
+
+func (circuit *TestEd25519BatchCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	ed25519 := NewAPI(succinctAPI)
+	ed25519.VerifyBatch(circuit.PubKeys, circuit.Msgs, circuit.Sigs, circuit.IsEnabled)
+	return nil
+}
+
+func TestEd25519Batch(t *testing.T) {
+	assert := test.NewAssert(t)
+	msgs := [][]byte{[]byte("block 1"), []byte("block 2 is longer"), []byte("block 3")}
+	newCircuit := func(isEnabled []bool) *TestEd25519BatchCircuit {
+		circuit := &TestEd25519BatchCircuit{}
+		for i := range msgs {
+			seed := make([]byte, ed25519.SeedSize)
+			seed[0] = byte(i)
+			key := ed25519.NewKeyFromSeed(seed)
+			sig := ed25519.Sign(key, msgs[i])
+			pubKey := newBytes32(key.Public().(ed25519.PublicKey))
+			if !isEnabled[i] {
+				// A disabled entry may hold a public key which is not on the curve.
+				pubKey = newBytes32(bytes.Repeat([]byte{0xff}, 32))
+			}
+			msg := vars.NewVariableBytesFrom(msgs[i], 32)
+			if !isEnabled[i] {
+				// It may also hold a message length which is out of range.
+				msg.Length = vars.NewVariableFromInt(1000)
+			}
+			circuit.PubKeys = append(circuit.PubKeys, pubKey)
+			circuit.Msgs = append(circuit.Msgs, msg)
+			circuit.Sigs = append(circuit.Sigs, Signature{R: newBytes32(sig[:32]), S: newBytes32(sig[32:])})
+			circuit.IsEnabled = append(circuit.IsEnabled, vars.NewBool(isEnabled[i]))
+		}
+		return circuit
+	}
+
+	circuit := newCircuit([]bool{true, false, true})
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The invalid entry is rejected once it is enabled.
+	witness := newCircuit([]bool{true, false, true})
+	witness.IsEnabled[1] = vars.TRUE
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,32 @@
+package ed25519
+
+import (
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/nonnative"
+)
+
+// The base and scalar fields of Curve25519.
+type Ed25519Fp = nonnative.Ed25519Fp
+type Ed25519Fr = nonnative.Ed25519Fr
+
+var fpModulus = Ed25519Fp{}.Modulus()
+var frModulus = Ed25519Fr{}.Modulus()
+
+// The coefficient d of the curve -x^2 + y^2 = 1 + d * x^2 * y^2.
+var curveD, _ = new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+
+// The coordinates of the base point.
+var baseX, _ = new(big.Int).SetString("15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+var baseY, _ = new(big.Int).SetString("46316835694926478169428394003475163141307993866256225615783033603165251855960", 10)
+
+// The encoding of the base point, i.e. y in little-endian with the parity of x in the top bit.
+var baseEncoding = func() [32]byte {
+	var encoding [32]byte
+	yBytes := baseY.Bytes()
+	for i := range yBytes {
+		encoding[i] = yBytes[len(yBytes)-1-i]
+	}
+	encoding[31] |= byte(baseX.Bit(0) << 7)
+	return encoding
+}()
@@ -0,0 +1,166 @@
+// The API for verifying RSA signatures with SHA-256 as used by TLS certificates and DKIM, with the
+// padding schemes PKCS #1 v1.5 and PSS of RFC 8017. The modular exponentiation is done with the
+// big integers of bignum, so the size of the keys is fixed when the circuit is compiled.
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/succinctlabs/succinctx/gnarkx/bignum"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The public exponent of the keys, which is the only one in common use.
+const PublicExponent = 65537
+
+// The length of the salt of PSS signatures, which is the length of the hash as recommended by
+// RFC 8017 and as produced by rsa.PSSSaltLengthEqualsHash.
+const SaltLength = 32
+
+// The DER encoding of the DigestInfo of SHA-256 without the digest, which prefixes the digest in
+// PKCS #1 v1.5 signatures.
+var sha256Prefix = []byte{
+	0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05,
+	0x00, 0x04, 0x20,
+}
+
+// A public key as the big-endian encoding of its modulus, whose most significant bit must be set,
+// i.e. a key of 2048 bits has a modulus of 256 bytes.
+type PublicKey struct {
+	N []vars.Byte
+}
+
+// Creates a new public key for moduli of nbBits bits, e.g. as a placeholder for a circuit.
+func NewPublicKey(nbBits int) PublicKey {
+	return PublicKey{N: vars.NewBytes(nbBits / 8)}
+}
+
+// A signature as its big-endian encoding of the same length as the modulus.
+type Signature []vars.Byte
+
+// Creates a new signature for moduli of nbBits bits.
+func NewSignature(nbBits int) Signature {
+	return vars.NewBytes(nbBits / 8)
+}
+
+// An API used for verifying RSA signatures.
+type API struct {
+	api    builder.API
+	bignum *bignum.API
+}
+
+// Creates a new rsa.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api, bignum: bignum.NewAPI(api)}
+}
+
+// Asserts that sig is a valid PKCS #1 v1.5 signature of the SHA-256 digest by the public key.
+func (a *API) VerifyPKCS1v15(pubKey PublicKey, digest [32]vars.Byte, sig Signature) {
+	em := a.encryptedMessage(pubKey, sig)
+
+	// The encoded message is 0x00 || 0x01 || 0xff... || 0x00 || DigestInfo || digest.
+	k := len(em)
+	nbPadding := k - 3 - len(sha256Prefix) - 32
+	if nbPadding < 8 {
+		panic(fmt.Sprintf("modulus of %d bytes is too small", k))
+	}
+	expected := []byte{0x00, 0x01}
+	for i := 0; i < nbPadding; i++ {
+		expected = append(expected, 0xff)
+	}
+	expected = append(append(expected, 0x00), sha256Prefix...)
+	for i := range expected {
+		a.api.AssertIsEqual(em[i].Value, vars.NewVariableFromInt(int(expected[i])))
+	}
+	for i := range digest {
+		a.api.AssertIsEqualByte(em[k-32+i], digest[i])
+	}
+}
+
+// Asserts that sig is a valid PSS signature of the SHA-256 digest by the public key, with MGF1
+// over SHA-256 and a salt of SaltLength bytes.
+func (a *API) VerifyPSS(pubKey PublicKey, digest [32]vars.Byte, sig Signature) {
+	em := a.encryptedMessage(pubKey, sig)
+
+	// Since the most significant bit of the modulus is set, the encoded message has one bit less,
+	// which is the most significant bit of em[0], and it is maskedDB || H || 0xbc.
+	k := len(em)
+	dbLength := k - 32 - 1
+	if dbLength < SaltLength+1 {
+		panic(fmt.Sprintf("modulus of %d bytes is too small", k))
+	}
+	a.api.AssertIsEqual(em[k-1].Value, vars.NewVariableFromInt(0xbc))
+	maskedDB := em[:dbLength]
+	h := em[dbLength : k-1]
+
+	// Unmask the data block, whose most significant bit is cleared.
+	mask := mgf1(a.api, h, dbLength)
+	db := make([][8]vars.Bool, dbLength)
+	for i := range db {
+		maskedBits := a.api.ToBitsFromByte(maskedDB[i])
+		maskBits := a.api.ToBitsFromByte(mask[i])
+		for j := range db[i] {
+			db[i][j] = a.api.Xor(maskedBits[j], maskBits[j])
+		}
+		if i == 0 {
+			a.api.AssertIsEqualBool(maskedBits[7], vars.FALSE)
+			db[i][7] = vars.FALSE
+		}
+	}
+
+	// The data block is 0x00... || 0x01 || salt.
+	nbPadding := dbLength - SaltLength - 1
+	for i := 0; i < nbPadding; i++ {
+		a.api.AssertIsEqualByte(a.api.ToByteFromBits(db[i]), vars.ZERO_BYTE)
+	}
+	a.api.AssertIsEqual(a.api.ToByteFromBits(db[nbPadding]).Value, vars.ONE)
+	salt := make([]vars.Byte, SaltLength)
+	for i := range salt {
+		salt[i] = a.api.ToByteFromBits(db[nbPadding+1+i])
+	}
+
+	// H is the hash of 0x00 * 8 || digest || salt.
+	message := vars.NewBytesFrom(make([]byte, 8))
+	message = append(append(message, digest[:]...), salt...)
+	expected := sha256.Hash(a.api, message)
+	for i := range expected {
+		a.api.AssertIsEqualByte(h[i], expected[i])
+	}
+}
+
+// Returns sig^e mod n as big-endian bytes of the same length as the modulus, asserting that the
+// signature is less than the modulus as required by RFC 8017.
+func (a *API) encryptedMessage(pubKey PublicKey, sig Signature) []vars.Byte {
+	k := len(pubKey.N)
+	if len(sig) != k {
+		panic(fmt.Sprintf("signature has %d bytes instead of %d", len(sig), k))
+	}
+	a.api.AssertIsEqualBool(a.api.ToBitsFromByte(pubKey.N[0])[7], vars.TRUE)
+	// The limbs are packed from the bytes without range checks, so a non-byte would change the
+	// value of the modulus or the signature.
+	for i := 0; i < k; i++ {
+		a.api.AssertIsByte(pubKey.N[i].Value)
+		a.api.AssertIsByte(sig[i].Value)
+	}
+	n := a.bignum.FromBytesBE(pubKey.N)
+	s := a.bignum.FromBytesBE(sig)
+	a.bignum.AssertIsLess(s, n)
+	m := a.bignum.ModExpConstant(s, big.NewInt(PublicExponent), n)
+	return a.bignum.ToBytesBE(m, k)
+}
+
+// Computes the mask of the given length from the seed with MGF1 over SHA-256, i.e. the
+// concatenation of sha256(seed || counter) for big-endian counters of 4 bytes.
+func mgf1(api builder.API, seed []vars.Byte, length int) []vars.Byte {
+	mask := make([]vars.Byte, 0, length+31)
+	for counter := 0; len(mask) < length; counter++ {
+		in := append([]vars.Byte{}, seed...)
+		in = append(in, vars.NewBytesFrom([]byte{byte(counter >> 24), byte(counter >> 16), byte(counter >> 8), byte(counter)})...)
+		digest := sha256.Hash(api, in)
+		mask = append(mask, digest[:]...)
+	}
+	return mask[:length]
+}
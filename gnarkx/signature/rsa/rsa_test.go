@@ -0,0 +1,96 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	gosha256 "crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestRSACircuit struct {
+	PubKey PublicKey
+	Digest [32]vars.Byte
+	Sig    Signature
+	PSS    bool
+}
+
+func (circuit *TestRSACircuit) Define(api frontend.API) error {
+	rsaAPI := NewAPI(builder.NewAPI(api))
+	if circuit.PSS {
+		rsaAPI.VerifyPSS(circuit.PubKey, circuit.Digest, circuit.Sig)
+	} else {
+		rsaAPI.VerifyPKCS1v15(circuit.PubKey, circuit.Digest, circuit.Sig)
+	}
+	return nil
+}
+
+func newRSACircuit(key *gorsa.PrivateKey, digest [32]byte, sig []byte, pss bool) *TestRSACircuit {
+	var digestBytes [32]vars.Byte
+	vars.SetBytes32(&digestBytes, digest)
+	return &TestRSACircuit{
+		PubKey: PublicKey{N: vars.NewBytesFrom(key.N.FillBytes(make([]byte, key.Size())))},
+		Digest: digestBytes,
+		Sig:    vars.NewBytesFrom(sig),
+		PSS:    pss,
+	}
+}
+
+func testRSA(t *testing.T, nbBits int, pss bool) {
+	assert := test.NewAssert(t)
+
+	key, err := gorsa.GenerateKey(rand.Reader, nbBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := gosha256.Sum256([]byte("succinct"))
+	var sig []byte
+	if pss {
+		sig, err = gorsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &gorsa.PSSOptions{SaltLength: gorsa.PSSSaltLengthEqualsHash})
+	} else {
+		sig, err = gorsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	circuit := &TestRSACircuit{PubKey: NewPublicKey(nbBits), Sig: NewSignature(nbBits), PSS: pss}
+	assignment := newRSACircuit(key, digest, sig, pss)
+	assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The signature of a different digest is rejected.
+	wrongDigest := gosha256.Sum256([]byte("wrong"))
+	assignment = newRSACircuit(key, wrongDigest, sig, pss)
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A signature with a byte out of range is rejected, even if it packs to the same integer.
+	i := len(sig) - 1
+	for sig[i-1] == 0 {
+		i--
+	}
+	assignment = newRSACircuit(key, digest, sig, pss)
+	assignment.Sig[i] = vars.Byte{Value: vars.NewVariableFromInt(int(sig[i]) + 256)}
+	assignment.Sig[i-1] = vars.Byte{Value: vars.NewVariableFromInt(int(sig[i-1]) - 1)}
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A modified signature is rejected.
+	sig[len(sig)-1] ^= 1
+	assignment = newRSACircuit(key, digest, sig, pss)
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+func TestVerifyPKCS1v15(t *testing.T) {
+	testRSA(t, 2048, false)
+	testRSA(t, 4096, false)
+}
+
+func TestVerifyPSS(t *testing.T) {
+	testRSA(t, 2048, true)
+	testRSA(t, 4096, true)
+}
@@ -0,0 +1,120 @@
+// The API for verifying ECDSA signatures over secp256k1 and recovering Ethereum addresses from
+// them. The curve arithmetic is emulated with non-native field elements, so a single
+// verification costs a few hundred thousand constraints.
+package secp256k1
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/evmprecompiles"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/nonnative"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A public key as the big-endian encodings of its affine coordinates.
+type PublicKey struct {
+	X [32]vars.Byte
+	Y [32]vars.Byte
+}
+
+// A signature as the big-endian encodings of r and s along with the recovery id v, which is 0 or
+// 1. Note that Ethereum transactions and the ecrecover precompile encode v as 27 or 28 instead.
+type Signature struct {
+	R [32]vars.Byte
+	S [32]vars.Byte
+	V vars.Variable
+}
+
+type point = sw_emulated.AffinePoint[emulated.Secp256k1Fp]
+
+// An API used for operations related to secp256k1 signatures.
+type API struct {
+	api   builder.API
+	fp    *nonnative.API[nonnative.Secp256k1Fp]
+	fr    *nonnative.API[nonnative.Secp256k1Fr]
+	curve *sw_emulated.Curve[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+}
+
+// Creates a new secp256k1.API.
+func NewAPI(api *builder.API) *API {
+	frontendAPI := api.FrontendAPI()
+	curve, err := sw_emulated.New[emulated.Secp256k1Fp, emulated.Secp256k1Fr](frontendAPI, sw_emulated.GetSecp256k1Params())
+	if err != nil {
+		panic(err)
+	}
+	return &API{api: *api, fp: nonnative.NewAPI[nonnative.Secp256k1Fp](api), fr: nonnative.NewAPI[nonnative.Secp256k1Fr](api), curve: curve}
+}
+
+// Asserts that sig is a valid signature of the message hash by the public key, which must be on
+// the curve. If strict is set, s must be at most (n - 1) / 2, which rejects the malleated copy of a
+// signature with s replaced by n - s. Otherwise, both low and high values of s are accepted.
+func (a *API) VerifySignature(pubKey PublicKey, msgHash [32]vars.Byte, sig Signature, strict bool) {
+	pk := a.toPoint(pubKey)
+	a.curve.AssertIsOnCurve(pk)
+	msg := a.fr.FromBytesBE(msgHash[:])
+	signature := ecdsa.Signature[emulated.Secp256k1Fr]{
+		R: *a.fr.FromBytesBE(sig.R[:]),
+		S: *a.fr.FromBytesBE(sig.S[:]),
+	}
+	if strict {
+		halfN := new(big.Int).Rsh(a.fr.Modulus(), 1)
+		a.fr.Field().AssertIsLessOrEqual(&signature.S, a.fr.Constant(halfN))
+	}
+	ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr](*pk).Verify(
+		a.api.FrontendAPI(), sw_emulated.GetSecp256k1Params(), msg, &signature,
+	)
+}
+
+// Recovers the public key which signed the message hash, like the ecrecover precompile. If
+// strict is set, s must be at most (n - 1) / 2 as required for Ethereum transactions. The recovery
+// id sig.V must be 0 or 1.
+func (a *API) RecoverPublicKey(msgHash [32]vars.Byte, sig Signature, strict bool) PublicKey {
+	// The precompile of gnark also accepts 2 and 3, i.e. an x-coordinate of r + n.
+	a.api.AssertIsBoolean(sig.V)
+	msg := a.fr.FromBytesBE(msgHash[:])
+	r := a.fr.FromBytesBE(sig.R[:])
+	s := a.fr.FromBytesBE(sig.S[:])
+	v := a.api.Add(sig.V, vars.NewVariableFromInt(27))
+	var strictRange frontend.Variable = 0
+	if strict {
+		strictRange = 1
+	}
+	pk := evmprecompiles.ECRecover(a.api.FrontendAPI(), *msg, v.Value, *r, *s, strictRange)
+	var pubKey PublicKey
+	copy(pubKey.X[:], a.fp.ToBytesBE(&pk.X))
+	copy(pubKey.Y[:], a.fp.ToBytesBE(&pk.Y))
+	return pubKey
+}
+
+// Returns the Ethereum address of the public key, i.e. the last 20 bytes of
+// keccak256(x || y).
+func (a *API) ToAddress(pubKey PublicKey) [20]vars.Byte {
+	digest := keccak256.Hash(a.api, append(pubKey.X[:], pubKey.Y[:]...))
+	var address [20]vars.Byte
+	copy(address[:], digest[12:])
+	return address
+}
+
+// Recovers the Ethereum address which signed the message hash. See RecoverPublicKey.
+func (a *API) RecoverAddress(msgHash [32]vars.Byte, sig Signature, strict bool) [20]vars.Byte {
+	return a.ToAddress(a.RecoverPublicKey(msgHash, sig, strict))
+}
+
+// Asserts that sig is a valid signature of the message hash by the owner of the address. See
+// RecoverPublicKey.
+func (a *API) VerifyAddressSignature(address [20]vars.Byte, msgHash [32]vars.Byte, sig Signature, strict bool) {
+	recovered := a.RecoverAddress(msgHash, sig, strict)
+	for i := 0; i < 20; i++ {
+		a.api.AssertIsEqualByte(recovered[i], address[i])
+	}
+}
+
+func (a *API) toPoint(pubKey PublicKey) *point {
+	return &point{X: *a.fp.FromBytesBE(pubKey.X[:]), Y: *a.fp.FromBytesBE(pubKey.Y[:])}
+}
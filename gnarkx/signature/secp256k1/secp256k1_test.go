@@ -0,0 +1,91 @@
+package secp256k1
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestSecp256k1Circuit struct {
+	PubKey  PublicKey
+	MsgHash [32]vars.Byte
+	Sig     Signature
+	Address [20]vars.Byte
+	Strict  bool `gnark:"-"`
+}
+
+func (circuit *TestSecp256k1Circuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	secp256k1 := NewAPI(succinctAPI)
+	secp256k1.VerifySignature(circuit.PubKey, circuit.MsgHash, circuit.Sig, circuit.Strict)
+	secp256k1.VerifyAddressSignature(circuit.Address, circuit.MsgHash, circuit.Sig, circuit.Strict)
+	return nil
+}
+
+func newBytes32(in []byte) [32]vars.Byte {
+	var result [32]vars.Byte
+	var value [32]byte
+	copy(value[32-len(in):], in)
+	vars.SetBytes32(&result, value)
+	return result
+}
+
+// Returns the circuit for the signature of the message, or for its malleated copy with s replaced by
+// n - s if highS is set.
+func newSecp256k1Circuit(t *testing.T, msg string, highS bool) *TestSecp256k1Circuit {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("succinct")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgHash := crypto.Keccak256([]byte(msg))
+	sig, err := crypto.Sign(msgHash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if highS {
+		s := new(big.Int).SetBytes(sig[32:64])
+		s.Sub(crypto.S256().Params().N, s).FillBytes(sig[32:64])
+		sig[64] ^= 1
+	}
+
+	var address [20]vars.Byte
+	addressBytes := crypto.PubkeyToAddress(key.PublicKey)
+	for i := 0; i < 20; i++ {
+		address[i].Set(addressBytes[i])
+	}
+	return &TestSecp256k1Circuit{
+		PubKey: PublicKey{
+			X: newBytes32(key.PublicKey.X.Bytes()),
+			Y: newBytes32(key.PublicKey.Y.Bytes()),
+		},
+		MsgHash: newBytes32(msgHash),
+		Sig: Signature{
+			R: newBytes32(sig[:32]),
+			S: newBytes32(sig[32:64]),
+			V: vars.NewVariableFromInt(int(sig[64])),
+		},
+		Address: address,
+	}
+}
+
+func TestSecp256k1(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	witness := newSecp256k1Circuit(t, "Succinct Labs", false)
+	assert.NoError(test.IsSolved(&TestSecp256k1Circuit{Strict: true}, witness, ecc.BN254.ScalarField()))
+
+	// The malleated signature with a high s is only accepted if the check is not strict.
+	highS := newSecp256k1Circuit(t, "Succinct Labs", true)
+	assert.NoError(test.IsSolved(&TestSecp256k1Circuit{}, highS, ecc.BN254.ScalarField()))
+	assert.Error(test.IsSolved(&TestSecp256k1Circuit{Strict: true}, highS, ecc.BN254.ScalarField()))
+
+	// A signature of a different message is rejected.
+	witness.MsgHash = newSecp256k1Circuit(t, "jtguibas", false).MsgHash
+	assert.Error(test.IsSolved(&TestSecp256k1Circuit{Strict: true}, witness, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,211 @@
+// The API for sorting arrays and checking permutations of arrays in a circuit, e.g. to deduplicate
+// entries or to join two arrays by a key.
+//
+// Short arrays are sorted in the circuit by a bitonic sorting network, which costs O(n log^2 n)
+// comparisons. For longer arrays, the sorted array is a witness whose order is checked with n - 1
+// comparisons, while a grand product argument with a random challenge derived from a commitment to
+// both arrays proves that it is a permutation of the input. Without commitments, i.e. in R1CS, see
+// builder.API.SupportsCommitments, arrays of any length are sorted by the network, and permutations
+// are checked against indices given by a hint.
+package sort
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	gosort "sort"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/multicommit"
+	"github.com/consensys/gnark/std/selector"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The maximum length of an array which is sorted by a sorting network.
+const MaxNetworkLength = 16
+
+// An API used for sorting and permutation checks.
+type API struct {
+	api builder.API
+}
+
+// Creates a new sort.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api}
+}
+
+// Returns the values in ascending order. The values must be in [0, 2^nbBits), where nbBits plus the
+// number of bits of len(in) is less than the size of the field.
+func (a *API) Sort(in []vars.Variable, nbBits int) []vars.Variable {
+	if len(in) <= MaxNetworkLength || !a.api.SupportsCommitments() {
+		return a.sortingNetwork(in, nbBits)
+	}
+	sorted := a.api.Hint(sortHint, len(in), in...)
+	a.AssertIsSortedPermutation(in, sorted, nbBits)
+	return sorted
+}
+
+// Asserts that sorted is the permutation of in in ascending order. The values of in must be in
+// [0, 2^nbBits), where nbBits plus the number of bits of len(in) is less than the size of the field.
+func (a *API) AssertIsSortedPermutation(in []vars.Variable, sorted []vars.Variable, nbBits int) {
+	if len(in) != len(sorted) {
+		panic("arrays must have the same length")
+	}
+	if len(in) <= MaxNetworkLength || !a.api.SupportsCommitments() {
+		expected := a.sortingNetwork(in, nbBits)
+		for i := range expected {
+			a.api.AssertIsEqual(expected[i], sorted[i])
+		}
+		return
+	}
+	a.AssertIsPermutation(in, sorted)
+	a.AssertIsSorted(sorted, nbBits)
+}
+
+// Asserts that the values are in ascending order and in [0, 2^nbBits), where nbBits plus the
+// number of bits of len(in) is less than the size of the field.
+func (a *API) AssertIsSorted(in []vars.Variable, nbBits int) {
+	if len(in) == 0 {
+		return
+	}
+	// The differences sum up to less than the size of the field, so if the first and the last value
+	// are in range, then so is every value in between.
+	if nbBits+bits.Len(uint(len(in))) >= a.api.FrontendAPI().Compiler().FieldBitLen() {
+		panic(fmt.Sprintf("cannot check the order of %d values of %d bits", len(in), nbBits))
+	}
+	a.api.ToBinaryLE(in[0], nbBits)
+	for i := 1; i < len(in); i++ {
+		a.api.ToBinaryLE(a.api.Sub(in[i], in[i-1]), nbBits)
+	}
+	a.api.ToBinaryLE(in[len(in)-1], nbBits)
+}
+
+// Asserts that y is a permutation of x, i.e. that they are equal as multisets. The check is a
+// grand product argument, i.e. prod(r - x[i]) == prod(r - y[i]) for a random challenge r, which is
+// sound except with probability len(x) / |F|. Without commitments, the check costs O(n^2)
+// constraints instead, see assertIsPermutationByIndices.
+func (a *API) AssertIsPermutation(x []vars.Variable, y []vars.Variable) {
+	if len(x) != len(y) {
+		panic("arrays must have the same length")
+	}
+	if len(x) == 0 {
+		return
+	}
+	if !a.api.SupportsCommitments() {
+		a.assertIsPermutationByIndices(x, y)
+		return
+	}
+	committed := make([]frontend.Variable, 0, 2*len(x))
+	for i := range x {
+		committed = append(committed, x[i].Value, y[i].Value)
+	}
+	multicommit.WithCommitment(a.api.FrontendAPI(), func(_ frontend.API, commitment frontend.Variable) error {
+		challenge := vars.Variable{Value: commitment}
+		productX, productY := vars.ONE, vars.ONE
+		for i := range x {
+			productX = a.api.Mul(productX, a.api.Sub(challenge, x[i]))
+			productY = a.api.Mul(productY, a.api.Sub(challenge, y[i]))
+		}
+		a.api.AssertIsEqual(productX, productY)
+		return nil
+	}, committed...)
+}
+
+// Asserts that y is a permutation of x without committing to them. A hint returns the index in x
+// of every value of y, the indices are checked to be a permutation of 0, ..., n - 1 by sorting them
+// with the network, and every value of y is checked to be the value of x at its index.
+func (a *API) assertIsPermutationByIndices(x []vars.Variable, y []vars.Variable) {
+	inputs := make([]vars.Variable, 0, 2*len(x))
+	inputs = append(inputs, x...)
+	inputs = append(inputs, y...)
+	indices := a.api.Hint(permutationHint, len(y), inputs...)
+
+	// The sorted indices are a permutation of the indices, so if they are 0, ..., n - 1, then so are
+	// the indices, whatever values the hint returned.
+	sorted := a.sortingNetwork(indices, bits.Len(uint(len(x))))
+	for i := range sorted {
+		a.api.AssertIsEqual(sorted[i], vars.NewVariableFromInt(i))
+	}
+
+	values := make([]frontend.Variable, len(x))
+	for i := range x {
+		values[i] = x[i].Value
+	}
+	for i := range y {
+		value := selector.Mux(a.api.FrontendAPI(), indices[i].Value, values...)
+		a.api.AssertIsEqual(y[i], vars.Variable{Value: value})
+	}
+}
+
+// Sorts the values with a bitonic sorting network, where the array is padded with the maximum
+// value 2^nbBits - 1 to a power of two.
+func (a *API) sortingNetwork(in []vars.Variable, nbBits int) []vars.Variable {
+	n := 1
+	for n < len(in) {
+		n *= 2
+	}
+	values := make([]vars.Variable, n)
+	copy(values, in)
+	maxValue := vars.Variable{Value: new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(nbBits)), big.NewInt(1))}
+	for i := len(in); i < n; i++ {
+		values[i] = maxValue
+	}
+
+	for k := 2; k <= n; k *= 2 {
+		for j := k / 2; j > 0; j /= 2 {
+			for i := 0; i < n; i++ {
+				l := i ^ j
+				if l <= i {
+					continue
+				}
+				if i&k == 0 {
+					values[i], values[l] = a.compareAndSwap(values[i], values[l], nbBits)
+				} else {
+					values[l], values[i] = a.compareAndSwap(values[l], values[i], nbBits)
+				}
+			}
+		}
+	}
+	return values[:len(in)]
+}
+
+// Returns the minimum and the maximum of x and y, which must be in [0, 2^nbBits).
+func (a *API) compareAndSwap(x, y vars.Variable, nbBits int) (vars.Variable, vars.Variable) {
+	// x - y + 2^nbBits is in [0, 2^(nbBits + 1)), and its top bit is set iff x >= y.
+	offset := vars.Variable{Value: new(big.Int).Lsh(big.NewInt(1), uint(nbBits))}
+	bits := a.api.ToBinaryLE(a.api.Add(a.api.Sub(x, y), offset), nbBits+1)
+	isGreaterOrEqual := bits[nbBits]
+	min := a.api.Select(isGreaterOrEqual, y, x)
+	max := a.api.Sub(a.api.Add(x, y), min)
+	return min, max
+}
+
+// Sorts its inputs in ascending order.
+var sortHint = builder.NewHint("sort.sort", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	sorted := make([]*big.Int, len(inputs))
+	copy(sorted, inputs)
+	gosort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	for i := range outputs {
+		outputs[i].Set(sorted[i])
+	}
+	return nil
+})
+
+// Returns, for every value of the second half of the inputs, the index of a distinct equal value in
+// the first half. Values without one get index 0, so that the circuit is not satisfiable.
+var permutationHint = builder.NewHint("sort.permutation", func(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	n := len(inputs) / 2
+	used := make([]bool, n)
+	for i := range outputs {
+		outputs[i].SetUint64(0)
+		for j := 0; j < n; j++ {
+			if !used[j] && inputs[j].Cmp(inputs[n+i]) == 0 {
+				used[j] = true
+				outputs[i].SetUint64(uint64(j))
+				break
+			}
+		}
+	}
+	return nil
+})
@@ -0,0 +1,204 @@
+package sort
+
+import (
+	"math/rand"
+	gosort "sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/gnarkxtest"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestSortCircuit struct {
+	In     []vars.Variable
+	Sorted []vars.Variable
+}
+
+func (circuit *TestSortCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sortAPI := NewAPI(succinctAPI)
+	sorted := sortAPI.Sort(circuit.In, 16)
+	for i := range sorted {
+		succinctAPI.AssertIsEqual(sorted[i], circuit.Sorted[i])
+	}
+	return nil
+}
+
+type TestAssertIsSortedPermutationCircuit struct {
+	In     []vars.Variable
+	Sorted []vars.Variable
+}
+
+func (circuit *TestAssertIsSortedPermutationCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	sortAPI := NewAPI(succinctAPI)
+	sortAPI.AssertIsSortedPermutation(circuit.In, circuit.Sorted, 16)
+	return nil
+}
+
+type TestAssertIsPermutationCircuit struct {
+	X []vars.Variable
+	Y []vars.Variable
+}
+
+func (circuit *TestAssertIsPermutationCircuit) Define(api frontend.API) error {
+	NewAPI(builder.NewAPI(api)).AssertIsPermutation(circuit.X, circuit.Y)
+	return nil
+}
+
+type TestAssertIsSortedCircuit struct {
+	In     []vars.Variable
+	NbBits int `gnark:"-"`
+}
+
+func (circuit *TestAssertIsSortedCircuit) Define(api frontend.API) error {
+	NewAPI(builder.NewAPI(api)).AssertIsSorted(circuit.In, circuit.NbBits)
+	return nil
+}
+
+func newVariables(values []int) []vars.Variable {
+	variables := make([]vars.Variable, len(values))
+	for i := range values {
+		variables[i] = vars.NewVariableFromInt(values[i])
+	}
+	return variables
+}
+
+// Returns a pseudorandom array of n values with duplicates and the array in ascending order.
+func newArrays(n int) ([]int, []int) {
+	in := make([]int, n)
+	counts := make([]int, 64)
+	for i := range in {
+		in[i] = (i*37 + 11) % 64 * 1000
+		counts[in[i]/1000]++
+	}
+	sorted := make([]int, 0, n)
+	for i := range counts {
+		for j := 0; j < counts[i]; j++ {
+			sorted = append(sorted, i*1000)
+		}
+	}
+	return in, sorted
+}
+
+func TestSort(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Lengths sorted by the network, including one which is not a power of two, and by the
+	// permutation argument.
+	for _, n := range []int{1, 5, 16, 100} {
+		in, sorted := newArrays(n)
+		circuit := &TestSortCircuit{In: make([]vars.Variable, n), Sorted: make([]vars.Variable, n)}
+		assignment := &TestSortCircuit{In: newVariables(in), Sorted: newVariables(sorted)}
+		assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		if n > 1 {
+			sorted[0], sorted[n-1] = sorted[n-1], sorted[0]
+			assignment = &TestSortCircuit{In: newVariables(in), Sorted: newVariables(sorted)}
+			assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+		}
+	}
+}
+
+func TestAssertIsSortedPermutation(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, n := range []int{7, 100} {
+		in, sorted := newArrays(n)
+		circuit := &TestAssertIsSortedPermutationCircuit{In: make([]vars.Variable, n), Sorted: make([]vars.Variable, n)}
+		assignment := &TestAssertIsSortedPermutationCircuit{In: newVariables(in), Sorted: newVariables(sorted)}
+		assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// A sorted array which is not a permutation of the input is rejected.
+		wrong := append([]int{}, sorted...)
+		wrong[n-1]++
+		assignment = &TestAssertIsSortedPermutationCircuit{In: newVariables(in), Sorted: newVariables(wrong)}
+		assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+		// A permutation of the input which is not sorted is rejected.
+		wrong = append([]int{}, sorted...)
+		wrong[0], wrong[n-1] = wrong[n-1], wrong[0]
+		assignment = &TestAssertIsSortedPermutationCircuit{In: newVariables(in), Sorted: newVariables(wrong)}
+		assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestSortR1CS(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// In R1CS, long arrays are sorted by the network and permutations are checked against indices,
+	// without committing to the witness.
+	n := 40
+	in, sorted := newArrays(n)
+	solve := func(circuit, assignment frontend.Circuit) error {
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+		assert.NoError(err)
+		assert.Empty(ccs.GetCommitments().CommitmentIndexes())
+		witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		return ccs.IsSolved(witness)
+	}
+
+	circuit := &TestSortCircuit{In: make([]vars.Variable, n), Sorted: make([]vars.Variable, n)}
+	assert.NoError(solve(circuit, &TestSortCircuit{In: newVariables(in), Sorted: newVariables(sorted)}))
+	wrong := append([]int{}, sorted...)
+	wrong[0], wrong[n-1] = wrong[n-1], wrong[0]
+	assert.Error(solve(circuit, &TestSortCircuit{In: newVariables(in), Sorted: newVariables(wrong)}))
+
+	permutation := &TestAssertIsPermutationCircuit{X: make([]vars.Variable, n), Y: make([]vars.Variable, n)}
+	assert.NoError(solve(permutation, &TestAssertIsPermutationCircuit{X: newVariables(in), Y: newVariables(wrong)}))
+	wrong[n-1]++
+	assert.Error(solve(permutation, &TestAssertIsPermutationCircuit{X: newVariables(in), Y: newVariables(wrong)}))
+}
+
+func TestAssertIsSortedBits(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The differences of 8 values of 249 bits sum up to less than the size of the field, but not
+	// those of 8 values of 250 bits.
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestAssertIsSortedCircuit{In: make([]vars.Variable, 8), NbBits: 249})
+	assert.NoError(err)
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TestAssertIsSortedCircuit{In: make([]vars.Variable, 8), NbBits: 250})
+	assert.Error(err)
+}
+
+func TestSortFuzz(t *testing.T) {
+	for _, n := range []int{5, 40} {
+		n := n
+		generate := func(rng *rand.Rand) frontend.Circuit {
+			in := make([]int, n)
+			for i := range in {
+				in[i] = rng.Intn(1 << 16)
+			}
+			sorted := append([]int{}, in...)
+			gosort.Ints(sorted)
+			return &TestSortCircuit{In: newVariables(in), Sorted: newVariables(sorted)}
+		}
+		tester := gnarkxtest.New(t, &TestSortCircuit{In: make([]vars.Variable, n), Sorted: make([]vars.Variable, n)})
+		tester.Fuzz(0, 20, generate)
+		tester.FuzzMutations(0, 20, generate)
+	}
+}
+
+func TestAssertIsPermutationFuzz(t *testing.T) {
+	// The tester compiles to R1CS, where permutations are checked against the indices of the hint.
+	n := 40
+	generate := func(rng *rand.Rand) frontend.Circuit {
+		x := make([]int, n)
+		for i := range x {
+			x[i] = rng.Intn(1 << 16)
+		}
+		y := append([]int{}, x...)
+		rng.Shuffle(n, func(i, j int) { y[i], y[j] = y[j], y[i] })
+		return &TestAssertIsPermutationCircuit{X: newVariables(x), Y: newVariables(y)}
+	}
+	tester := gnarkxtest.New(t, &TestAssertIsPermutationCircuit{X: make([]vars.Variable, n), Y: make([]vars.Variable, n)})
+	tester.Fuzz(0, 20, generate)
+	tester.FuzzMutations(0, 20, generate)
+	tester.FuzzHintMutations(0, 20, generate, permutationHint)
+}
@@ -0,0 +1,123 @@
+package succinct
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// The size of a compiled circuit.
+type CircuitStats struct {
+	NbConstraints  int `json:"nbConstraints"`
+	NbPublicInputs int `json:"nbPublicInputs"`
+	NbSecretInputs int `json:"nbSecretInputs"`
+}
+
+// The limits a circuit must stay within, e.g. in CI. Limits which are zero are not checked.
+type ConstraintBudget struct {
+	MaxConstraints  int `json:"maxConstraints"`
+	MaxPublicInputs int `json:"maxPublicInputs"`
+	// The maximum relative growth of the number of constraints compared to a baseline, e.g. 0.1
+	// allows 10% more constraints than the baseline.
+	MaxGrowth float64 `json:"maxGrowth"`
+}
+
+// Compiles the circuit to R1CS and returns its size.
+func CompileStats(circuit frontend.Circuit) (*CircuitStats, error) {
+	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, err
+	}
+	return &CircuitStats{
+		NbConstraints: r1cs.GetNbConstraints(),
+		// The public variables of an R1CS include the constant one wire, which is not an input.
+		NbPublicInputs: r1cs.GetNbPublicVariables() - 1,
+		NbSecretInputs: r1cs.GetNbSecretVariables(),
+	}, nil
+}
+
+// Compiles the circuit function and returns its size.
+func (circuit *CircuitFunction) Stats() (*CircuitStats, error) {
+	return CompileStats(circuit)
+}
+
+// Returns an error if the circuit exceeds the budget, either on its own or compared to the
+// baseline, which may be nil. The error lists every exceeded limit along with a diff against the
+// baseline.
+func (s *CircuitStats) CheckBudget(budget ConstraintBudget, baseline *CircuitStats) error {
+	var violations []string
+	if budget.MaxConstraints > 0 && s.NbConstraints > budget.MaxConstraints {
+		violations = append(violations, fmt.Sprintf("%d constraints exceed the budget of %d", s.NbConstraints, budget.MaxConstraints))
+	}
+	if budget.MaxPublicInputs > 0 && s.NbPublicInputs > budget.MaxPublicInputs {
+		violations = append(violations, fmt.Sprintf("%d public inputs exceed the budget of %d", s.NbPublicInputs, budget.MaxPublicInputs))
+	}
+	if baseline != nil && budget.MaxGrowth > 0 {
+		maxConstraints := int(float64(baseline.NbConstraints) * (1 + budget.MaxGrowth))
+		if s.NbConstraints > maxConstraints {
+			violations = append(violations, fmt.Sprintf(
+				"%d constraints exceed the baseline of %d by more than %.2f%%",
+				s.NbConstraints, baseline.NbConstraints, 100*budget.MaxGrowth,
+			))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	message := strings.Join(violations, "\n")
+	if baseline != nil {
+		message += "\n" + s.Diff(baseline)
+	}
+	return errors.New(message)
+}
+
+// Returns the changes of the size of the circuit compared to the baseline, one line per metric.
+func (s *CircuitStats) Diff(baseline *CircuitStats) string {
+	var diff strings.Builder
+	line := func(name string, before, after int) {
+		change := ""
+		if before != 0 {
+			change = fmt.Sprintf(" (%+.2f%%)", 100*float64(after-before)/float64(before))
+		}
+		fmt.Fprintf(&diff, "%s: %d -> %d%s\n", name, before, after, change)
+	}
+	line("constraints", baseline.NbConstraints, s.NbConstraints)
+	line("public inputs", baseline.NbPublicInputs, s.NbPublicInputs)
+	line("secret inputs", baseline.NbSecretInputs, s.NbSecretInputs)
+	return diff.String()
+}
+
+// Exports the stats to a JSON file, e.g. to store them as the baseline.
+func (s *CircuitStats) Export(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	return nil
+}
+
+// Imports the stats from a JSON file.
+func ImportCircuitStats(file string) (*CircuitStats, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	stats := &CircuitStats{}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stats: %w", err)
+	}
+	return stats, nil
+}
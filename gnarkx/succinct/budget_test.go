@@ -0,0 +1,44 @@
+package succinct
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBudget(t *testing.T) {
+	c := NewCircuitFunction(NewTestCircuit())
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.NbPublicInputs)
+	assert.Greater(t, stats.NbConstraints, 0)
+
+	// The stats survive a round trip through the baseline file.
+	file := filepath.Join(t.TempDir(), "build", "stats.json")
+	assert.NoError(t, stats.Export(file))
+	baseline, err := ImportCircuitStats(file)
+	assert.NoError(t, err)
+	assert.Equal(t, stats, baseline)
+
+	budget := ConstraintBudget{MaxConstraints: stats.NbConstraints, MaxPublicInputs: 2, MaxGrowth: 0.1}
+	assert.NoError(t, stats.CheckBudget(budget, baseline))
+	assert.NoError(t, stats.CheckBudget(budget, nil))
+
+	// A circuit which has more constraints than the budget allows is rejected.
+	budget.MaxConstraints = stats.NbConstraints - 1
+	err = stats.CheckBudget(budget, nil)
+	assert.ErrorContains(t, err, "exceed the budget")
+
+	// A circuit which has grown too much over the baseline is rejected with a diff.
+	budget.MaxConstraints = 0
+	baseline = &CircuitStats{NbConstraints: stats.NbConstraints / 2, NbPublicInputs: 2, NbSecretInputs: stats.NbSecretInputs}
+	err = stats.CheckBudget(budget, baseline)
+	assert.ErrorContains(t, err, "exceed the baseline")
+	assert.ErrorContains(t, err, "constraints: ")
+	assert.ErrorContains(t, err, "public inputs: 2 -> 2 (+0.00%)")
+
+	// The number of public inputs is limited as well.
+	budget = ConstraintBudget{MaxPublicInputs: 1}
+	assert.ErrorContains(t, stats.CheckBudget(budget, nil), "2 public inputs exceed the budget of 1")
+}
@@ -1,7 +1,10 @@
 package succinct
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -13,8 +16,22 @@ type CircuitBuild struct {
 	pk   groth16.ProvingKey
 	vk   groth16.VerifyingKey
 	r1cs constraint.ConstraintSystem
+
+	// Whether the keys come from the deterministic setup of DevSetup.
+	devSetup bool
+
+	// The metadata of the circuit, which is nil for builds exported without it.
+	metadata *CircuitMetadata
+}
+
+// Returns the metadata of the circuit of the build, or nil if the build was exported without it.
+func (build *CircuitBuild) Metadata() *CircuitMetadata {
+	return build.metadata
 }
 
+// The file marking the build directory of a dev setup, whose keys must not be deployed.
+const devSetupMarker = "build/UNSAFE_DEV_SETUP"
+
 // Export exports the R1CS, proving key, and verifying key to files.
 func (build *CircuitBuild) Export() {
 	// Make build directory.
@@ -82,6 +99,27 @@ func (build *CircuitBuild) Export() {
 		return
 	}
 
+	// Write the metadata of the circuit next to the R1CS.
+	if build.metadata != nil {
+		if err := build.metadata.Export(metadataFile); err != nil {
+			fmt.Println("Failed to export metadata:", err)
+			return
+		}
+	}
+
+	// Mark the keys of a dev setup, and remove the mark of a previous dev setup otherwise.
+	if build.devSetup {
+		err = os.WriteFile(devSetupMarker, []byte("The keys in this directory were generated with a publicly known seed.\nAnyone can forge proofs for them, do not deploy them to production.\n"), 0644)
+	} else {
+		err = os.Remove(devSetupMarker)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		fmt.Println("Failed to mark dev setup:", err)
+		return
+	}
 }
 
 // ImportCircuitBuild imports the R1CS, proving key, and verifying key from files.
@@ -122,15 +160,28 @@ func ImportCircuitBuild() (*CircuitBuild, error) {
 	}
 	defer r1csFile.Close()
 
-	// Deserialize the R1CS.
-	_, err = r1cs.ReadFrom(r1csFile)
+	// Deserialize the R1CS, hashing it to check it against the metadata.
+	h := sha256.New()
+	_, err = r1cs.ReadFrom(io.TeeReader(r1csFile, h))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data: %w", err)
 	}
 
+	// Read the metadata of the circuit, which builds exported before it was added do not have.
+	metadata, err := ImportCircuitMetadata(metadataFile)
+	if errors.Is(err, os.ErrNotExist) {
+		metadata = nil
+	} else if err != nil {
+		return nil, err
+	} else if err := metadata.CheckR1CSHash(h.Sum(nil)); err != nil {
+		return nil, err
+	}
+
 	return &CircuitBuild{
-		pk:   pk,
-		vk:   vk,
-		r1cs: r1cs,
+		pk:       pk,
+		vk:       vk,
+		r1cs:     r1cs,
+		devSetup: metadata != nil && metadata.DevSetup,
+		metadata: metadata,
 	}, nil
 }
@@ -1,9 +1,7 @@
 package succinct
 
 import (
-	"bytes"
 	"fmt"
-	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
@@ -24,15 +22,30 @@ type CircuitFunction struct {
 	// The output hash is the hash of all outputs from the function.
 	OutputHash vars.Variable `gnark:"outputHash,public"`
 
+	// The input bytes are the onchain inputs into the function, which are read by the circuit.
+	InputBytes []vars.Byte
+
+	// The output bytes are the outputs from the function, which are written by the circuit.
+	OutputBytes []vars.Byte
+
 	// The circuit definies the computation of the function.
 	Circuit Circuit
+
+	// The schema of the inputs and outputs of the circuit, and the number of instances of each
+	// sub-circuit, which are set once it has been defined.
+	schema      *builder.Schema
+	subCircuits map[string]int
 }
 
-// Creates a new circuit function based on a circuit that implements the Circuit interface.
+// Creates a new circuit function based on a circuit that implements the Circuit interface. The
+// input and output bytes are allocated with the sizes declared by the circuit.
 func NewCircuitFunction(c Circuit) CircuitFunction {
+	io := c.DefineIO()
 	function := CircuitFunction{}
 	function.InputHash = vars.NewVariable()
 	function.OutputHash = vars.NewVariable()
+	function.InputBytes = vars.NewBytes(io.NbInputBytes)
+	function.OutputBytes = vars.NewBytes(io.NbOutputBytes)
 	function.Circuit = c
 	return function
 }
@@ -41,35 +54,59 @@ func NewCircuitFunction(c Circuit) CircuitFunction {
 // input hash and output hash variables (which will be public values). Recall that all functions
 // have the form f(inputs, witness) = outputs. Both inputsHash and outputsHash are h(inputs) and
 // h(outputs) respectively, where h is a hash function.
-func (f *CircuitFunction) SetWitness(inputBytes []byte) {
-	// Set the input bytes.
-	vars.SetBytes(f.Circuit.GetInputBytes(), inputBytes)
+func (f *CircuitFunction) SetWitness(inputBytes []byte) error {
+	if len(inputBytes) != len(f.InputBytes) {
+		return fmt.Errorf("expected %d input bytes, got %d", len(f.InputBytes), len(inputBytes))
+	}
+
+	// Assign the circuit, which computes the output bytes.
+	outputBytes, err := f.Circuit.Assign(inputBytes)
+	if err != nil {
+		return fmt.Errorf("failed to assign circuit: %w", err)
+	}
+	if len(outputBytes) != len(f.OutputBytes) {
+		return fmt.Errorf("expected %d output bytes, got %d", len(f.OutputBytes), len(outputBytes))
+	}
 
-	// Assign the circuit.
-	f.Circuit.SetWitness(inputBytes)
+	// Set the input and output bytes.
+	vars.SetBytes(&f.InputBytes, inputBytes)
+	vars.SetBytes(&f.OutputBytes, outputBytes)
 
 	// Set inputHash = sha256(inputBytes) && ((1 << 253) - 1).
-	inputHash := sha256utils.HashAndTruncate(inputBytes, 253)
+	inputHash := sha256utils.HashAndTruncate(inputBytes, builder.IOHashBits)
 	f.InputHash.Set(inputHash)
 
 	// Set outputHash = sha256(outputBytes) && ((1 << 253) - 1).
-	outputBytes := f.Circuit.GetOutputBytes()
-	outputBytesValues := vars.GetValuesUnsafe(*outputBytes)
-	outputHash := sha256utils.HashAndTruncate(outputBytesValues, 253)
+	outputHash := sha256utils.HashAndTruncate(outputBytes, builder.IOHashBits)
 	f.OutputHash.Set(outputHash)
+	return nil
 }
 
 // Define the circuit. All circuit functions automatically constraint h(inputBytes) == inputHash
 // and h(outputBytes) == outputHash.
 func (f *CircuitFunction) Define(baseApi frontend.API) error {
-	// Define the circuit using the Gnark standard API. Ideally, we would pass in builder.API
-	// but we can't becaues this is handled by Gnark internally.
-	f.Circuit.Define(baseApi)
+	api := builder.NewAPI(baseApi)
+
+	// Define the circuit, which reads the input bytes and writes the output bytes.
+	endScope := api.Scope("define")
+	inputs := builder.NewInputReader(*api, f.InputBytes)
+	outputs := builder.NewOutputWriter(*api)
+	if err := f.Circuit.Define(api, inputs, outputs); err != nil {
+		endScope()
+		return err
+	}
+	outputs.Close(f.OutputBytes)
+	endScope()
+	f.schema = api.Schema()
+	f.subCircuits = api.SubCircuitInstances()
 
 	// Automatically handle the input and output hashes and assert that they must be consistent.
-	api := builder.NewAPI(baseApi)
-	inputHash := sha256.HashAndTruncate(*api, *f.Circuit.GetInputBytes(), 253)
-	outputHash := sha256.HashAndTruncate(*api, *f.Circuit.GetOutputBytes(), 253)
+	endScope = api.Scope("inputHash")
+	inputHash := api.HashInput(sha256.HashAndTruncate)
+	endScope()
+	endScope = api.Scope("outputHash")
+	outputHash := api.HashOutput(sha256.HashAndTruncate)
+	endScope()
 	api.AssertIsEqual(f.InputHash, inputHash)
 	api.AssertIsEqual(f.OutputHash, outputHash)
 	return nil
@@ -87,17 +124,100 @@ func (circuit *CircuitFunction) Build() (*CircuitBuild, error) {
 		return nil, err
 	}
 
+	metadata, err := circuit.metadata(r1cs, false)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CircuitBuild{
-		pk:   pk,
-		vk:   vk,
-		r1cs: r1cs,
+		pk:       pk,
+		vk:       vk,
+		r1cs:     r1cs,
+		metadata: metadata,
 	}, nil
 }
 
+// Build the circuit with the deterministic setup of DevSetup, which is unsafe for production.
+func (circuit *CircuitFunction) BuildDev(seed []byte) (*CircuitBuild, error) {
+	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, vk, err := DevSetup(r1cs, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := circuit.metadata(r1cs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CircuitBuild{
+		pk:       pk,
+		vk:       vk,
+		r1cs:     r1cs,
+		devSetup: true,
+		metadata: metadata,
+	}, nil
+}
+
+// Compiles the circuit with a profiler, which attributes the constraints to the scopes opened
+// with builder.API.Scope.
+func (circuit *CircuitFunction) Profile() (*builder.Profiler, error) {
+	profiler := builder.StartProfiler()
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	profiler.Stop()
+	if err != nil {
+		return nil, err
+	}
+	return profiler, nil
+}
+
+// Compiles the circuit with a tracer and solves it for the input bytes without proving, which is
+// the debug mode of witness solving. If a constraint is not satisfied, the error is a
+// *builder.TraceError with the scopes of the gadgets which added it.
+func (f *CircuitFunction) Debug(inputBytes []byte) error {
+	tracer := builder.StartTracer()
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, f)
+	tracer.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to compile circuit: %w", err)
+	}
+	if err := f.SetWitness(inputBytes); err != nil {
+		return err
+	}
+	witness, err := frontend.NewWitness(f, ecc.BN254.ScalarField())
+	if err != nil {
+		return fmt.Errorf("failed to create witness: %w", err)
+	}
+	return tracer.Explain(ccs.IsSolved(witness))
+}
+
+// Compiles the circuit and returns the schema of its inputs and outputs, as read and written by
+// builder.InputReader and builder.OutputWriter.
+func (circuit *CircuitFunction) Schema() (*builder.Schema, error) {
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return nil, err
+	}
+	return circuit.schema, nil
+}
+
 // Generates a proof for f(inputs, witness) = outputs based on a circuit.
 func (f *CircuitFunction) Prove(inputBytes []byte, build *CircuitBuild) (*types.Groth16Proof, error) {
+	// Check the input against the circuit the build was compiled from, if its metadata is known.
+	if build.metadata != nil {
+		if err := build.metadata.CheckInput(inputBytes); err != nil {
+			return nil, fmt.Errorf("invalid input: %w", err)
+		}
+	}
+
 	// Fill in the witness values.
-	f.SetWitness(inputBytes)
+	if err := f.SetWitness(inputBytes); err != nil {
+		return nil, err
+	}
 
 	// Calculate the actual witness.
 	witness, err := frontend.NewWitness(f, ecc.BN254.ScalarField())
@@ -111,32 +231,24 @@ func (f *CircuitFunction) Prove(inputBytes []byte, build *CircuitBuild) (*types.
 		return nil, fmt.Errorf("failed to generate proof: %w", err)
 	}
 
-	const fpSize = 4 * 8
-	var buf bytes.Buffer
-	proof.WriteRawTo(&buf)
-	proofBytes := buf.Bytes()
-	output := &types.Groth16Proof{}
-	output.A[0] = new(big.Int).SetBytes(proofBytes[fpSize*0 : fpSize*1])
-	output.A[1] = new(big.Int).SetBytes(proofBytes[fpSize*1 : fpSize*2])
-	output.B[0][0] = new(big.Int).SetBytes(proofBytes[fpSize*2 : fpSize*3])
-	output.B[0][1] = new(big.Int).SetBytes(proofBytes[fpSize*3 : fpSize*4])
-	output.B[1][0] = new(big.Int).SetBytes(proofBytes[fpSize*4 : fpSize*5])
-	output.B[1][1] = new(big.Int).SetBytes(proofBytes[fpSize*5 : fpSize*6])
-	output.C[0] = new(big.Int).SetBytes(proofBytes[fpSize*6 : fpSize*7])
-	output.C[1] = new(big.Int).SetBytes(proofBytes[fpSize*7 : fpSize*8])
-
+	output, err := types.NewGroth16Proof(proof)
+	if err != nil {
+		return nil, err
+	}
 	output.Input = inputBytes
-	output.Output = vars.GetValuesUnsafe(*f.Circuit.GetOutputBytes())
+	output.Output = vars.GetValuesUnsafe(f.OutputBytes)
 
 	return output, nil
 }
 
 // Generates a JSON fixture for use in Solidity tests with MockSuccinctGateway.sol.
 func (f *CircuitFunction) GenerateFixture(inputBytes []byte) (types.Fixture, error) {
-	f.SetWitness(inputBytes)
+	if err := f.SetWitness(inputBytes); err != nil {
+		return types.Fixture{}, err
+	}
 	fixture := types.Fixture{
 		Input:  inputBytes,
-		Output: vars.GetValuesUnsafe(*f.Circuit.GetOutputBytes()),
+		Output: vars.GetValuesUnsafe(f.OutputBytes),
 	}
 	return fixture, nil
 }
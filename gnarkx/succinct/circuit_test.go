@@ -3,63 +3,45 @@ package succinct
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"math/big"
+	"os"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/stretchr/testify/assert"
 	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/types"
 	"github.com/succinctlabs/succinctx/gnarkx/utils/byteutils"
-	"github.com/succinctlabs/succinctx/gnarkx/vars"
 )
 
-type TestCircuit struct {
-	InputBytes  []vars.Byte
-	OutputBytes []vars.Byte
-}
+type TestCircuit struct{}
 
 var _ Circuit = (*TestCircuit)(nil)
 
 func NewTestCircuit() *TestCircuit {
-	return &TestCircuit{
-		InputBytes:  vars.NewBytes(16),
-		OutputBytes: vars.NewBytes(8),
-	}
-}
-
-func (c *TestCircuit) Assign(in []byte) error {
-	return nil
+	return &TestCircuit{}
 }
 
-func (c *TestCircuit) GetInputBytes() *[]vars.Byte {
-	return &c.InputBytes
+func (c *TestCircuit) DefineIO() IO {
+	return IO{NbInputBytes: 16, NbOutputBytes: 8}
 }
 
-func (c *TestCircuit) GetOutputBytes() *[]vars.Byte {
-	return &c.OutputBytes
-}
-
-func (c *TestCircuit) Define(baseAPI frontend.API) error {
-	api := builder.NewAPI(baseAPI)
-	inputReader := builder.NewInputReader(*api, c.InputBytes)
-
-	a := inputReader.ReadUint64()
-	b := inputReader.ReadUint64()
-
-	outputWriter := builder.NewOutputWriter(*api)
+func (c *TestCircuit) Define(api *builder.API, inputs *builder.InputReader, outputs *builder.OutputWriter) error {
+	a := inputs.ReadUint64()
+	b := inputs.ReadUint64()
 
 	sum := api.AddU64(a, b)
 
-	outputWriter.WriteU64(sum)
-
-	outputWriter.Close(c.OutputBytes)
+	outputs.WriteU64(sum)
 
 	return nil
 }
 
-func (c *TestCircuit) SetWitness(inputBytes []byte) {
-	vars.SetBytes(&c.InputBytes, inputBytes)
-
+func (c *TestCircuit) Assign(inputBytes []byte) ([]byte, error) {
 	a := new(big.Int).SetBytes(inputBytes[:8])
 	b := new(big.Int).SetBytes(inputBytes[8:])
 
@@ -67,7 +49,7 @@ func (c *TestCircuit) SetWitness(inputBytes []byte) {
 	outputBytes := make([]byte, 8)
 	sum.FillBytes(outputBytes)
 
-	vars.SetBytes(&c.OutputBytes, outputBytes[:])
+	return outputBytes, nil
 }
 
 func TestSimpleCircuit(t *testing.T) {
@@ -113,3 +95,172 @@ func TestSimpleCircuit(t *testing.T) {
 	assert.True(t, bytes.Equal(output, expectedOutput))
 	assert.True(t, bytes.Equal(outputHash, truncatedOutputHash[:]))
 }
+
+func TestBuildDev(t *testing.T) {
+	vkBytes := func(seed string) []byte {
+		c := NewCircuitFunction(NewTestCircuit())
+		build, err := c.BuildDev([]byte(seed))
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		_, err = build.vk.WriteTo(&buf)
+		assert.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	// The same seed builds the same keys, and another seed other keys.
+	assert.Equal(t, vkBytes(DevSetupSeed), vkBytes(DevSetupSeed))
+	assert.NotEqual(t, vkBytes(DevSetupSeed), vkBytes("other"))
+
+	// Proofs of a dev build verify.
+	c := NewCircuitFunction(NewTestCircuit())
+	build, err := c.BuildDev([]byte(DevSetupSeed))
+	assert.NoError(t, err)
+	input, err := hex.DecodeString("00000000000001a40000000000000045")
+	assert.NoError(t, err)
+	assert.NoError(t, c.SetWitness(input))
+	witness, err := frontend.NewWitness(&c, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	proof, err := groth16.Prove(build.r1cs, build.pk, witness)
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, build.vk, publicWitness))
+}
+
+func TestSchema(t *testing.T) {
+	c := NewCircuitFunction(NewTestCircuit())
+
+	schema, err := c.Schema()
+	assert.NoError(t, err)
+	assert.Equal(t, []builder.SchemaField{
+		{Name: "input0", Type: builder.Uint64Field, Offset: 0, Width: 8},
+		{Name: "input1", Type: builder.Uint64Field, Offset: 8, Width: 8},
+	}, schema.Inputs)
+	assert.Equal(t, []builder.SchemaField{
+		{Name: "output0", Type: builder.Uint64Field, Offset: 0, Width: 8},
+	}, schema.Outputs)
+}
+
+func TestSetWitness(t *testing.T) {
+	c := NewCircuitFunction(NewTestCircuit())
+
+	// The input bytes must match the size declared by the circuit.
+	assert.Error(t, c.SetWitness(make([]byte, 15)))
+	assert.NoError(t, c.SetWitness(make([]byte, 16)))
+}
+
+// A circuit whose assignment computes the wrong output.
+type TestBuggyCircuit struct {
+	TestCircuit
+}
+
+func (c *TestBuggyCircuit) Assign(inputBytes []byte) ([]byte, error) {
+	outputBytes, err := c.TestCircuit.Assign(inputBytes)
+	outputBytes[7]++
+	return outputBytes, err
+}
+
+func TestDebug(t *testing.T) {
+	input, err := hex.DecodeString("00000000000001a40000000000000045")
+	assert.NoError(t, err)
+	c := NewCircuitFunction(NewTestCircuit())
+	assert.NoError(t, c.Debug(input))
+
+	// The mismatch of the output bytes is attributed to the definition of the circuit.
+	c = NewCircuitFunction(&TestBuggyCircuit{})
+	err = c.Debug(input)
+	var traceErr *builder.TraceError
+	assert.True(t, errors.As(err, &traceErr))
+	assert.Equal(t, "define", traceErr.Scopes[0].Name)
+	assert.Contains(t, traceErr.Scopes[0].Location, "circuit.go")
+}
+
+func TestGroth16ProofEncoding(t *testing.T) {
+	c := NewCircuitFunction(NewTestCircuit())
+	build, err := c.Build()
+	assert.NoError(t, err)
+	input, err := hex.DecodeString("00000000000001a40000000000000045")
+	assert.NoError(t, err)
+	assert.NoError(t, c.SetWitness(input))
+	witness, err := frontend.NewWitness(&c, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	proof, err := groth16.Prove(build.r1cs, build.pk, witness)
+	assert.NoError(t, err)
+
+	// The words of the Solidity encoding are the coordinates in the raw encoding of gnark, of the
+	// proof with normalized signs.
+	output, err := types.NewGroth16Proof(proof)
+	assert.NoError(t, err)
+	normalized, err := output.ToGnark()
+	assert.NoError(t, err)
+	var raw bytes.Buffer
+	_, err = normalized.WriteRawTo(&raw)
+	assert.NoError(t, err)
+	assert.Equal(t, raw.Bytes()[:8*32], output.MarshalSolidity())
+}
+
+// The test circuit with range checks on its inputs and output.
+type TestRangeCheckCircuit struct {
+	TestCircuit
+}
+
+func (c *TestRangeCheckCircuit) Define(api *builder.API, inputs *builder.InputReader, outputs *builder.OutputWriter) error {
+	a := inputs.ReadUint64()
+	b := inputs.ReadUint64()
+	api.AssertIsU64(a.Value)
+	api.AssertIsU64(b.Value)
+	sum := api.AddU64(a, b)
+	api.AssertIsU64(sum.Value)
+	outputs.WriteU64(sum)
+	return nil
+}
+
+func TestRangeChecksWithoutCommitments(t *testing.T) {
+	// The range checks do not add commitments, which the Solidity verifier does not support.
+	c := NewCircuitFunction(&TestRangeCheckCircuit{})
+	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &c)
+	assert.NoError(t, err)
+	assert.Empty(t, r1cs.GetCommitments().CommitmentIndexes())
+}
+
+func TestCircuitMetadata(t *testing.T) {
+	c := NewCircuitFunction(NewTestCircuit())
+	build, err := c.BuildDev([]byte(DevSetupSeed))
+	assert.NoError(t, err)
+	metadata := build.Metadata()
+	assert.Equal(t, 16, metadata.NbInputBytes)
+	assert.Equal(t, 8, metadata.NbOutputBytes)
+	assert.Len(t, metadata.Schema.Inputs, 2)
+	assert.Equal(t, builder.IOHashBits, metadata.IOHashBits)
+	assert.True(t, metadata.DevSetup)
+	assert.Contains(t, metadata.Modules, "github.com/consensys/gnark")
+	assert.Len(t, metadata.R1CSHash, 32)
+
+	// Inputs of another shape than the circuit expects are rejected before proving.
+	assert.NoError(t, metadata.CheckInput(make([]byte, 16)))
+	_, err = c.Prove(make([]byte, 15), build)
+	assert.ErrorContains(t, err, "invalid input: expected 16 input bytes, got 15")
+
+	// The metadata is exported next to the R1CS, and the R1CS is checked against it on import.
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+	build.Export()
+	imported, err := ImportCircuitBuild()
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, imported.Metadata())
+	assert.True(t, imported.devSetup)
+
+	other := *metadata
+	other.R1CSHash = make([]byte, 32)
+	assert.NoError(t, other.Export(metadataFile))
+	_, err = ImportCircuitBuild()
+	assert.ErrorContains(t, err, "but the metadata is for 0x0000")
+
+	// Builds exported without metadata are still imported.
+	assert.NoError(t, os.Remove(metadataFile))
+	imported, err = ImportCircuitBuild()
+	assert.NoError(t, err)
+	assert.Nil(t, imported.Metadata())
+}
@@ -3,6 +3,7 @@ package succinct
 import (
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
@@ -10,7 +11,18 @@ import (
 func Run(c Circuit) {
 	proveFlag := flag.Bool("prove", false, "prove the circuit")
 	fixtureFlag := flag.Bool("fixture", false, "generate a test fixture")
+	profileFlag := flag.Bool("profile", false, "report the constraints of the circuit per scope")
+	debugFlag := flag.Bool("debug", false, "solve the circuit for the input and explain an unsatisfied constraint")
+	schemaFlag := flag.Bool("schema", false, "export the schema of the inputs and outputs of the circuit")
 	inputStr := flag.String("input", "", "input bytes to prove with 0x prefix")
+	budgetFlag := flag.Bool("budget", false, "check that the circuit stays within the constraint budget")
+	maxConstraints := flag.Int("max-constraints", 0, "the maximum number of constraints, or 0 for no limit")
+	maxPublicInputs := flag.Int("max-public-inputs", 0, "the maximum number of public inputs, or 0 for no limit")
+	maxGrowth := flag.Float64("max-growth", 0, "the maximum relative growth of the constraints over the baseline, or 0 for no limit")
+	baselinePath := flag.String("baseline", "build/stats.json", "the file of the baseline stats of the circuit")
+	updateBaseline := flag.Bool("update-baseline", false, "store the stats of the circuit as the new baseline")
+	devSetupFlag := flag.Bool("dev-setup", false, "UNSAFE: build with a setup seeded deterministically, for reproducible artifacts in development and CI")
+	devSeed := flag.String("dev-seed", DevSetupSeed, "the seed of the setup with -dev-setup")
 	flag.Parse()
 
 	circuit := NewCircuitFunction(c)
@@ -33,6 +45,16 @@ func Run(c Circuit) {
 		return
 	}
 
+	if *debugFlag {
+		fmt.Println("solving circuit for input:", hexutil.Encode([]byte(*inputStr)))
+		if err := circuit.Debug([]byte(*inputStr)); err != nil {
+			fmt.Println("Failed to solve circuit:", err)
+			os.Exit(1)
+		}
+		fmt.Println("circuit is satisfied")
+		return
+	}
+
 	if *fixtureFlag {
 		fmt.Println("generating fixture for input:", hexutil.Encode([]byte(*inputStr)))
 		fixture, err := circuit.GenerateFixture([]byte(*inputStr))
@@ -46,8 +68,84 @@ func Run(c Circuit) {
 		return
 	}
 
+	if *budgetFlag || *updateBaseline {
+		fmt.Println("compiling circuit to check the constraint budget")
+		stats, err := circuit.Stats()
+		if err != nil {
+			fmt.Println("Failed to compile circuit:", err)
+			os.Exit(1)
+		}
+		if *updateBaseline {
+			if err := stats.Export(*baselinePath); err != nil {
+				fmt.Println("Failed to export baseline:", err)
+				os.Exit(1)
+			}
+			return
+		}
+		baseline, err := ImportCircuitStats(*baselinePath)
+		if err != nil {
+			fmt.Println("No baseline found:", err)
+			baseline = nil
+		}
+		budget := ConstraintBudget{MaxConstraints: *maxConstraints, MaxPublicInputs: *maxPublicInputs, MaxGrowth: *maxGrowth}
+		if err := stats.CheckBudget(budget, baseline); err != nil {
+			fmt.Println("Circuit exceeds the constraint budget:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if baseline != nil {
+			fmt.Print(stats.Diff(baseline))
+		}
+		fmt.Println("circuit is within the constraint budget")
+		return
+	}
+
+	if *schemaFlag {
+		fmt.Println("compiling circuit to export its schema")
+		schema, err := circuit.Schema()
+		if err != nil {
+			fmt.Println("Failed to compile circuit:", err)
+			return
+		}
+		if err := os.MkdirAll("build", 0755); err != nil {
+			fmt.Println("Failed to create directory:", err)
+			return
+		}
+		if err := schema.Export("build/schema.json"); err != nil {
+			fmt.Println("Failed to export schema:", err)
+		}
+		return
+	}
+
+	if *profileFlag {
+		fmt.Println("compiling circuit with profiling")
+		profiler, err := circuit.Profile()
+		if err != nil {
+			fmt.Println("Failed to profile circuit:", err)
+			return
+		}
+		fmt.Print(profiler.Report())
+		profileFile, err := os.Create("profile.folded")
+		if err != nil {
+			fmt.Println("Failed to create file:", err)
+			return
+		}
+		defer profileFile.Close()
+		if err := profiler.WriteFolded(profileFile); err != nil {
+			fmt.Println("Failed to write profile:", err)
+		}
+		return
+	}
+
 	fmt.Println("compiling and building circuit artifacts")
-	build, err := circuit.Build()
+	var build *CircuitBuild
+	var err error
+	if *devSetupFlag {
+		fmt.Println("WARNING: using a deterministic dev setup, anyone can forge proofs for these keys, do not use them in production")
+		build, err = circuit.BuildDev([]byte(*devSeed))
+	} else {
+		build, err = circuit.Build()
+	}
 	if err != nil {
 		fmt.Println("Failed to build circuit:", err)
 		return
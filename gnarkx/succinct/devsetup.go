@@ -0,0 +1,166 @@
+package succinct
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"golang.org/x/crypto/chacha20"
+)
+
+// DevSetupSeed is the seed of the setup with -dev-setup, so that every checkout builds the same
+// keys by default.
+const DevSetupSeed = "succinctx-dev-setup"
+
+// The environment variable which makes a process run the dev setup of the R1CS in the directory it
+// names and exit, see DevSetup.
+const devSetupEnv = "SUCCINCTX_DEV_SETUP_DIR"
+
+// The files of a dev setup in its directory.
+const (
+	devSetupR1CSFile = "r1cs.bin"
+	devSetupSeedFile = "seed.bin"
+	devSetupPKFile   = "pkey.bin"
+	devSetupVKFile   = "vkey.bin"
+)
+
+func init() {
+	dir := os.Getenv(devSetupEnv)
+	if dir == "" {
+		return
+	}
+	if err := runDevSetup(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// seededReader is a deterministic stream of bytes: the ChaCha20 keystream of the SHA-256 hash of a
+// seed.
+type seededReader struct {
+	cipher *chacha20.Cipher
+}
+
+func newSeededReader(seed []byte) (*seededReader, error) {
+	key := sha256.Sum256(seed)
+	cipher, err := chacha20.NewUnauthenticatedCipher(key[:], make([]byte, chacha20.NonceSize))
+	if err != nil {
+		return nil, err
+	}
+	return &seededReader{cipher: cipher}, nil
+}
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	r.cipher.XORKeyStream(p, p)
+	return len(p), nil
+}
+
+// DevSetup runs the groth16 setup with toxic waste derived from the seed, so local development
+// and CI produce identical proving and verifying keys across runs, and cached artifacts and golden
+// proofs stay valid.
+//
+// The setup of gnark reads its toxic waste from crypto/rand.Reader, so it runs in a child process
+// of the current executable whose reader is replaced by the seeded one, and which exits right after
+// the setup. The reader of the current process is never replaced.
+//
+// UNSAFE: anyone who knows the seed knows the toxic waste and can forge proofs. Keys from a dev
+// setup must never be deployed to production.
+func DevSetup(r1cs constraint.ConstraintSystem, seed []byte) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	dir, err := os.MkdirTemp("", "succinctx-dev-setup")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeDevSetupFile(dir, devSetupR1CSFile, r1cs.WriteTo); err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, devSetupSeedFile), seed, 0600); err != nil {
+		return nil, nil, fmt.Errorf("failed to write seed: %w", err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find executable: %w", err)
+	}
+	cmd := exec.Command(executable)
+	cmd.Env = append(os.Environ(), devSetupEnv+"="+dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, nil, fmt.Errorf("failed to run dev setup: %w: %s", err, output)
+	}
+
+	pk := groth16.NewProvingKey(ecc.BN254)
+	if err := readDevSetupFile(dir, devSetupPKFile, pk.ReadFrom); err != nil {
+		return nil, nil, err
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	if err := readDevSetupFile(dir, devSetupVKFile, vk.ReadFrom); err != nil {
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+// Runs the dev setup of the R1CS in the directory and writes the keys into it. This replaces
+// crypto/rand.Reader, so it must only run in the child process started by DevSetup.
+func runDevSetup(dir string) error {
+	seed, err := os.ReadFile(filepath.Join(dir, devSetupSeedFile))
+	if err != nil {
+		return fmt.Errorf("failed to read seed: %w", err)
+	}
+	reader, err := newSeededReader(seed)
+	if err != nil {
+		return fmt.Errorf("failed to seed setup: %w", err)
+	}
+	r1cs := groth16.NewCS(ecc.BN254)
+	if err := readDevSetupFile(dir, devSetupR1CSFile, r1cs.ReadFrom); err != nil {
+		return err
+	}
+
+	rand.Reader = reader
+	pk, vk, err := groth16.Setup(r1cs)
+	if err != nil {
+		return fmt.Errorf("failed to run setup: %w", err)
+	}
+
+	if err := writeDevSetupFile(dir, devSetupPKFile, pk.WriteRawTo); err != nil {
+		return err
+	}
+	return writeDevSetupFile(dir, devSetupVKFile, vk.WriteRawTo)
+}
+
+// Writes a file of a dev setup with the function, e.g. the WriteTo method of a key.
+func writeDevSetupFile(dir string, name string, write func(w io.Writer) (int64, error)) error {
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+	if _, err := write(file); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Reads a file of a dev setup with the function, e.g. the ReadFrom method of a key.
+func readDevSetupFile(dir string, name string, read func(r io.Reader) (int64, error)) error {
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	if _, err := read(file); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
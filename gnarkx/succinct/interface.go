@@ -1,17 +1,29 @@
 package succinct
 
 import (
-	"github.com/consensys/gnark/frontend"
-	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
 )
 
-// Circuit is the interface a circuit interacting with the Succinct Hub must implement.
-// These methods are used for loading witnesses into the circuit, defining constraints, and
-// reading and writing data to Ethereum.
+// Circuit is the interface a circuit interacting with the Succinct Hub must implement. The
+// methods are hooks into the lifecycle of a CircuitFunction, which manages the input and output
+// bytes of the circuit along with their hashes:
+//
+//   - DefineIO declares the number of input and output bytes, for which the circuit function
+//     allocates its variables.
+//   - Define defines the constraints of the circuit, reading the inputs from the InputReader and
+//     writing the outputs to the OutputWriter. The circuit function constrains the outputs written
+//     to match its output bytes, and the hashes of the input and output bytes to match its public
+//     InputHash and OutputHash.
+//   - Assign assigns the witness of the circuit for the input bytes and returns the output bytes
+//     the circuit writes for them.
 type Circuit interface {
-	GetInputBytes() *[]vars.Byte
-	GetOutputBytes() *[]vars.Byte
-	SetWitness(inputBytes []byte)
-	Assign(inputBytes []byte) error
-	Define(BaseApi frontend.API) error
+	DefineIO() IO
+	Define(api *builder.API, inputs *builder.InputReader, outputs *builder.OutputWriter) error
+	Assign(inputBytes []byte) ([]byte, error)
+}
+
+// The sizes of the inputs and outputs of a circuit in bytes.
+type IO struct {
+	NbInputBytes  int
+	NbOutputBytes int
 }
@@ -0,0 +1,150 @@
+package succinct
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+// The file of the metadata of the circuit, which is exported along with the R1CS.
+const metadataFile = "build/metadata.json"
+
+// The metadata of a compiled circuit, which is stored next to its R1CS so that a prover can check
+// requests against the circuit it proves, and check that the R1CS is the one the metadata was
+// exported with.
+type CircuitMetadata struct {
+	NbInputBytes  int `json:"nbInputBytes"`
+	NbOutputBytes int `json:"nbOutputBytes"`
+	// The schema of the inputs and outputs of the circuit.
+	Schema *builder.Schema `json:"schema"`
+	// The number of instances of each sub-circuit used by the circuit, by name.
+	SubCircuits map[string]int `json:"subCircuits"`
+	// The versions of the modules the gadgets of the circuit are defined in, by module path.
+	Modules map[string]string `json:"modules"`
+	// The options the circuit was built with.
+	Backend    string `json:"backend"`
+	Curve      string `json:"curve"`
+	IOHashBits int    `json:"ioHashBits"`
+	DevSetup   bool   `json:"devSetup"`
+	// The SHA-256 hash of the serialized R1CS.
+	R1CSHash hexutil.Bytes `json:"r1csHash"`
+}
+
+// The modules whose gadgets add the constraints of a circuit.
+var gadgetModules = []string{"github.com/succinctlabs/succinctx", "github.com/consensys/gnark"}
+
+// Returns the versions of the gadget modules linked into the binary, which are the version and the
+// checksum of a dependency, or the VCS revision of the main module if it is known.
+func gadgetVersions() map[string]string {
+	versions := make(map[string]string)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return versions
+	}
+	for _, path := range gadgetModules {
+		if info.Main.Path == path {
+			version := info.Main.Version
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					version += " " + setting.Value
+				}
+			}
+			versions[path] = version
+			continue
+		}
+		for _, dep := range info.Deps {
+			if dep.Path != path {
+				continue
+			}
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			versions[path] = dep.Version + " " + dep.Sum
+		}
+	}
+	return versions
+}
+
+// Returns the SHA-256 hash of the serialized R1CS.
+func hashR1CS(r1cs constraint.ConstraintSystem) ([]byte, error) {
+	h := sha256.New()
+	if _, err := r1cs.WriteTo(h); err != nil {
+		return nil, fmt.Errorf("failed to serialize r1cs: %w", err)
+	}
+	return h.Sum(nil), nil
+}
+
+// Returns the metadata of the circuit function compiled to the R1CS.
+func (f *CircuitFunction) metadata(r1cs constraint.ConstraintSystem, devSetup bool) (*CircuitMetadata, error) {
+	r1csHash, err := hashR1CS(r1cs)
+	if err != nil {
+		return nil, err
+	}
+	return &CircuitMetadata{
+		NbInputBytes:  len(f.InputBytes),
+		NbOutputBytes: len(f.OutputBytes),
+		Schema:        f.schema,
+		SubCircuits:   f.subCircuits,
+		Modules:       gadgetVersions(),
+		Backend:       "groth16",
+		Curve:         "bn254",
+		IOHashBits:    builder.IOHashBits,
+		DevSetup:      devSetup,
+		R1CSHash:      r1csHash,
+	}, nil
+}
+
+// Returns an error if the input bytes do not have the shape of the inputs of the circuit.
+func (m *CircuitMetadata) CheckInput(inputBytes []byte) error {
+	if len(inputBytes) != m.NbInputBytes {
+		return fmt.Errorf("expected %d input bytes, got %d", m.NbInputBytes, len(inputBytes))
+	}
+	if m.Schema != nil && m.Schema.InputSize() > len(inputBytes) {
+		return fmt.Errorf("the schema reads %d input bytes, got %d", m.Schema.InputSize(), len(inputBytes))
+	}
+	return nil
+}
+
+// Returns an error if the hash of the serialized R1CS is not the one of the metadata.
+func (m *CircuitMetadata) CheckR1CSHash(r1csHash []byte) error {
+	if !bytes.Equal(r1csHash, m.R1CSHash) {
+		return fmt.Errorf("the r1cs has hash %s, but the metadata is for %s", hexutil.Encode(r1csHash), m.R1CSHash)
+	}
+	return nil
+}
+
+// Exports the metadata to a JSON file.
+func (m *CircuitMetadata) Export(file string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// Imports the metadata from a JSON file.
+func ImportCircuitMetadata(file string) (*CircuitMetadata, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	metadata := &CircuitMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	return metadata, nil
+}
@@ -2,17 +2,30 @@ package succinct
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 
 	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 )
 
 type SuccinctVerifyingKey struct {
 	groth16.VerifyingKey
 }
 
+// ErrCommitmentsUnsupported is returned when exporting the Solidity verifier of a circuit with
+// commitments. The Groth16 verifier of gnark takes the commitment wires as public inputs, but does
+// not hash them from the commitments, add the commitments to the public input point, or check
+// their proof of knowledge, so it would accept forged proofs.
+var ErrCommitmentsUnsupported = errors.New("the solidity verifier does not support circuits with commitments")
+
 func (svk *SuccinctVerifyingKey) ExportIFunctionVerifierSolidity(w io.Writer) error {
+	if vk, ok := svk.VerifyingKey.(*groth16_bn254.VerifyingKey); ok && len(vk.PublicAndCommitmentCommitted) > 0 {
+		return fmt.Errorf("%w: the circuit has %d commitments", ErrCommitmentsUnsupported, len(vk.PublicAndCommitmentCommitted))
+	}
+
 	// Create a new buffer and export the VerifyingKey into it as a Solidity contract and
 	// convert the buffer content to a string for further manipulation.
 	buf := new(bytes.Buffer)
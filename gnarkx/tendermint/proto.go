@@ -0,0 +1,92 @@
+package tendermint
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The wire types of protobuf, which are the low 3 bits of the key of a field.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// Returns the key of a field, i.e. its number and wire type as a single byte varint.
+func fieldKey(number int, wireType int) byte {
+	return byte(number<<3 | wireType)
+}
+
+// Returns constant variable bytes.
+func constantBytes(b []byte) vars.VariableBytes {
+	data := make([]vars.Byte, len(b))
+	for i := range b {
+		data[i] = vars.Byte{Value: vars.NewVariableFromInt(int(b[i]))}
+	}
+	return vars.VariableBytes{Data: data, Length: vars.NewVariableFromInt(len(b))}
+}
+
+// Encodes a value of at most nbBits bits as a varint, i.e. in groups of 7 bits from the least
+// significant one, where the top bit of each byte but the last is set.
+func (a *API) encodeVarint(value vars.Variable, nbBits int) vars.VariableBytes {
+	nbBytes := (nbBits + 6) / 7
+	bits := a.api.ToBinaryLE(value, nbBits)
+
+	// hasMore[i] is set iff a bit after the first 7 * (i + 1) bits is set.
+	hasMore := make([]vars.Variable, nbBytes)
+	hasMore[nbBytes-1] = vars.ZERO
+	for i := nbBytes - 2; i >= 0; i-- {
+		hasMore[i] = hasMore[i+1]
+		for j := 7 * (i + 1); j < 7*(i+2) && j < nbBits; j++ {
+			isUnset := a.api.And(a.api.Not(vars.Bool{Value: hasMore[i]}), a.api.Not(bits[j]))
+			hasMore[i] = a.api.Not(isUnset).Value
+		}
+	}
+
+	data := make([]vars.Byte, nbBytes)
+	length := vars.ONE
+	for i := range data {
+		value := a.api.Mul(hasMore[i], vars.NewVariableFromInt(0x80))
+		for j := 0; j < 7 && 7*i+j < nbBits; j++ {
+			value = a.api.Add(value, a.api.Mul(bits[7*i+j].Value, vars.NewVariableFromInt(1<<j)))
+		}
+		data[i] = vars.Byte{Value: value}
+		if i > 0 {
+			length = a.api.Add(length, hasMore[i-1])
+		}
+	}
+	return vars.VariableBytes{Data: data, Length: length}
+}
+
+// Encodes a varint field, which is omitted if the value is zero as in proto3.
+func (a *API) encodeVarintField(number int, value vars.Variable, nbBits int) vars.VariableBytes {
+	field := a.api.ConcatVariableBytes(constantBytes([]byte{fieldKey(number, wireVarint)}), a.encodeVarint(value, nbBits))
+	return a.omitIf(field, a.api.IsZero(value))
+}
+
+// Encodes an sfixed64 field of a non-negative value, which is omitted if the value is zero.
+func (a *API) encodeFixed64Field(number int, value vars.U64) vars.VariableBytes {
+	field := constantBytes([]byte{fieldKey(number, wireFixed64)})
+	field.Data = append(field.Data, a.api.ToBytesLE(value.Value, 8)...)
+	field.Length = vars.NewVariableFromInt(9)
+	return a.omitIf(field, a.api.IsZero(value.Value))
+}
+
+// Encodes a length-delimited field, e.g. bytes or an embedded message, whose maximum length must
+// be less than 2^14 so that its length is a varint of at most 2 bytes.
+func (a *API) encodeBytesField(number int, content vars.VariableBytes) vars.VariableBytes {
+	return a.api.ConcatVariableBytes(
+		constantBytes([]byte{fieldKey(number, wireBytes)}),
+		a.encodeVarint(content.Length, 14),
+		content,
+	)
+}
+
+// Returns empty variable bytes of the same maximum length if the condition is set.
+func (a *API) omitIf(b vars.VariableBytes, condition vars.Bool) vars.VariableBytes {
+	isPresent := a.api.Not(condition).Value
+	data := make([]vars.Byte, len(b.Data))
+	for i := range b.Data {
+		data[i] = vars.Byte{Value: a.api.Mul(isPresent, b.Data[i].Value)}
+	}
+	return vars.VariableBytes{Data: data, Length: a.api.Mul(isPresent, b.Length)}
+}
@@ -0,0 +1,79 @@
+// The API for the components of a Tendermint (CometBFT) light client, i.e. encoding the votes of
+// a commit as signed by the validators, hashing validator sets and verifying that a commit for a
+// block is signed by more than 2/3 of the voting power of a validator set. See
+// https://github.com/cometbft/cometbft/blob/main/spec/light-client/verification/verification.md.
+package tendermint
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/signature/ed25519"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The signature of a validator in a commit, whose vote differs from the votes of the other
+// validators only in its timestamp. Signed is not set if the validator did not vote for the block.
+type CommitSig struct {
+	Signed    vars.Bool
+	Timestamp Timestamp
+	Signature ed25519.Signature
+}
+
+// An API used for the components of a light client.
+type API struct {
+	api     builder.API
+	ed25519 *ed25519.API
+}
+
+// Creates a new tendermint.API.
+func NewAPI(api *builder.API) *API {
+	return &API{api: *api, ed25519: ed25519.NewAPI(api)}
+}
+
+// Verifies the commit of the block with the given ID at the height and the round of a chain by
+// the validator set whose first nbValidators entries are the validators and whose hash is
+// validatorsHash. Asserts that every signature which is marked as signed is valid and that the
+// signers hold more than 2/3 of the total voting power. The commit signatures are in the order of
+// the validators, so len(sigs) must equal len(validators).
+func (a *API) VerifyCommit(
+	validators []Validator,
+	nbValidators vars.Variable,
+	validatorsHash [32]vars.Byte,
+	chainID string,
+	height vars.U64,
+	round vars.U64,
+	blockID BlockID,
+	sigs []CommitSig,
+) {
+	defer a.api.Scope("verify_commit")()
+	if len(sigs) != len(validators) {
+		panic("the numbers of validators and commit signatures must be equal")
+	}
+	hash := a.HashValidatorSet(validators, nbValidators)
+	for i := range hash {
+		a.api.AssertIsEqualByte(hash[i], validatorsHash[i])
+	}
+
+	isValidator := a.api.PrefixMask(nbValidators, len(validators))
+	pubKeys := make([][32]vars.Byte, len(validators))
+	msgs := make([]vars.VariableBytes, len(validators))
+	edSigs := make([]ed25519.Signature, len(validators))
+	isSigned := make([]vars.Bool, len(validators))
+	totalPower, signedPower := vars.ZERO, vars.ZERO
+	for i := range validators {
+		vote := Vote{Height: height, Round: round, BlockID: blockID, Timestamp: sigs[i].Timestamp}
+		pubKeys[i] = validators[i].PubKey
+		msgs[i] = a.EncodeVote(vote, chainID)
+		edSigs[i] = sigs[i].Signature
+		isSigned[i] = a.api.And(sigs[i].Signed, vars.Bool{Value: isValidator[i]})
+		totalPower = a.api.Add(totalPower, a.api.Mul(isValidator[i], validators[i].VotingPower.Value))
+		signedPower = a.api.Add(signedPower, a.api.Mul(isSigned[i].Value, validators[i].VotingPower.Value))
+	}
+	a.ed25519.VerifyBatch(pubKeys, msgs, edSigs, isSigned)
+
+	// 3 * signedPower > 2 * totalPower, where both sides are far less than the field size as the
+	// total voting power of a validator set is less than 2^60.
+	a.api.AssertIsLessOrEqual(
+		a.api.Add(a.api.Mul(totalPower, vars.TWO), vars.ONE),
+		a.api.Mul(signedPower, vars.THREE),
+	)
+}
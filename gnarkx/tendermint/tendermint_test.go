@@ -0,0 +1,304 @@
+package tendermint
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const testChainID = "mocha-4"
+
+type testVote struct {
+	height, round uint64
+	hash          [32]byte
+	partSetTotal  uint32
+	partSetHash   [32]byte
+	seconds       uint64
+	nanos         uint32
+}
+
+// Encodes the sign bytes of the vote natively, where zero fields are omitted as in proto3.
+func (v testVote) signBytes(chainID string) []byte {
+	var partSetHeader []byte
+	if v.partSetTotal != 0 {
+		partSetHeader = protowire.AppendTag(partSetHeader, 1, protowire.VarintType)
+		partSetHeader = protowire.AppendVarint(partSetHeader, uint64(v.partSetTotal))
+	}
+	partSetHeader = protowire.AppendTag(partSetHeader, 2, protowire.BytesType)
+	partSetHeader = protowire.AppendBytes(partSetHeader, v.partSetHash[:])
+	var blockID []byte
+	blockID = protowire.AppendTag(blockID, 1, protowire.BytesType)
+	blockID = protowire.AppendBytes(blockID, v.hash[:])
+	blockID = protowire.AppendTag(blockID, 2, protowire.BytesType)
+	blockID = protowire.AppendBytes(blockID, partSetHeader)
+	var timestamp []byte
+	if v.seconds != 0 {
+		timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, v.seconds)
+	}
+	if v.nanos != 0 {
+		timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(v.nanos))
+	}
+
+	var vote []byte
+	vote = protowire.AppendTag(vote, 1, protowire.VarintType)
+	vote = protowire.AppendVarint(vote, precommitType)
+	if v.height != 0 {
+		vote = protowire.AppendTag(vote, 2, protowire.Fixed64Type)
+		vote = protowire.AppendFixed64(vote, v.height)
+	}
+	if v.round != 0 {
+		vote = protowire.AppendTag(vote, 3, protowire.Fixed64Type)
+		vote = protowire.AppendFixed64(vote, v.round)
+	}
+	vote = protowire.AppendTag(vote, 4, protowire.BytesType)
+	vote = protowire.AppendBytes(vote, blockID)
+	vote = protowire.AppendTag(vote, 5, protowire.BytesType)
+	vote = protowire.AppendBytes(vote, timestamp)
+	if chainID != "" {
+		vote = protowire.AppendTag(vote, 6, protowire.BytesType)
+		vote = protowire.AppendString(vote, chainID)
+	}
+	return protowire.AppendBytes(nil, vote)
+}
+
+func newBlockID(v testVote) BlockID {
+	blockID := BlockID{PartSetTotal: vars.NewVariableFromInt(int(v.partSetTotal))}
+	vars.SetBytes32(&blockID.Hash, v.hash)
+	vars.SetBytes32(&blockID.PartSetHash, v.partSetHash)
+	return blockID
+}
+
+func newTimestamp(seconds uint64, nanos uint32) Timestamp {
+	timestamp := Timestamp{Nanos: vars.NewVariableFromInt(int(nanos))}
+	timestamp.Seconds.Set(seconds)
+	return timestamp
+}
+
+func (v testVote) toVars() Vote {
+	vote := Vote{BlockID: newBlockID(v), Timestamp: newTimestamp(v.seconds, v.nanos)}
+	vote.Height.Set(v.height)
+	vote.Round.Set(v.round)
+	return vote
+}
+
+var testVoteValue = testVote{
+	height:       1_234_567,
+	hash:         sha256.Sum256([]byte("block")),
+	partSetTotal: 1,
+	partSetHash:  sha256.Sum256([]byte("parts")),
+	seconds:      1_700_000_000,
+	nanos:        123_456_789,
+}
+
+type TestEncodeVoteCircuit struct {
+	Vote      Vote
+	SignBytes vars.VariableBytes
+}
+
+func (circuit *TestEncodeVoteCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	tendermint := NewAPI(succinctAPI)
+	signBytes := tendermint.EncodeVote(circuit.Vote, testChainID)
+	succinctAPI.AssertIsEqualVariableBytes(succinctAPI.ResizeVariableBytes(signBytes, 256), circuit.SignBytes)
+	return nil
+}
+
+func TestEncodeVote(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Cover the omitted zero fields and varints of different lengths.
+	votes := []testVote{testVoteValue, testVoteValue, testVoteValue}
+	votes[1].round = 3
+	votes[1].partSetTotal = 300
+	votes[2].nanos = 0
+	for _, v := range votes {
+		circuit := &TestEncodeVoteCircuit{Vote: v.toVars(), SignBytes: vars.NewVariableBytesFrom(v.signBytes(testChainID), 256)}
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+	}
+
+	circuit := &TestEncodeVoteCircuit{Vote: testVoteValue.toVars(), SignBytes: vars.NewVariableBytesFrom(votes[1].signBytes(testChainID), 256)}
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type testValidator struct {
+	key   ed25519.PrivateKey
+	power uint64
+}
+
+func newTestValidators(powers []uint64) []testValidator {
+	validators := make([]testValidator, len(powers))
+	for i := range powers {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(i + 1)
+		validators[i] = testValidator{key: ed25519.NewKeyFromSeed(seed), power: powers[i]}
+	}
+	return validators
+}
+
+// Computes the hash of the validator set natively, splitting the tree at the largest power of two
+// less than the number of leaves as in RFC 6962.
+func hashValidatorSet(validators []testValidator) [32]byte {
+	leaves := make([][32]byte, len(validators))
+	for i, v := range validators {
+		var pubKey []byte
+		pubKey = protowire.AppendTag(pubKey, 1, protowire.BytesType)
+		pubKey = protowire.AppendBytes(pubKey, v.key.Public().(ed25519.PublicKey))
+		encoded := []byte{0x00}
+		encoded = protowire.AppendTag(encoded, 1, protowire.BytesType)
+		encoded = protowire.AppendBytes(encoded, pubKey)
+		encoded = protowire.AppendTag(encoded, 2, protowire.VarintType)
+		encoded = protowire.AppendVarint(encoded, v.power)
+		leaves[i] = sha256.Sum256(encoded)
+	}
+	var root func(nodes [][32]byte) [32]byte
+	root = func(nodes [][32]byte) [32]byte {
+		if len(nodes) == 1 {
+			return nodes[0]
+		}
+		split := 1
+		for 2*split < len(nodes) {
+			split *= 2
+		}
+		left, right := root(nodes[:split]), root(nodes[split:])
+		return sha256.Sum256(append(append([]byte{0x01}, left[:]...), right[:]...))
+	}
+	return root(leaves)
+}
+
+// Returns the validators padded to maxValidators entries with arbitrary validators.
+func newValidators(validators []testValidator, maxValidators int) []Validator {
+	result := make([]Validator, maxValidators)
+	for i := range result {
+		var pubKey [32]byte
+		power := uint64(1)
+		if i < len(validators) {
+			copy(pubKey[:], validators[i].key.Public().(ed25519.PublicKey))
+			power = validators[i].power
+		}
+		vars.SetBytes32(&result[i].PubKey, pubKey)
+		result[i].VotingPower.Set(power)
+	}
+	return result
+}
+
+type TestHashValidatorSetCircuit struct {
+	Validators   []Validator
+	NbValidators vars.Variable
+	Hash         [32]vars.Byte
+}
+
+func (circuit *TestHashValidatorSetCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	tendermint := NewAPI(succinctAPI)
+	hash := tendermint.HashValidatorSet(circuit.Validators, circuit.NbValidators)
+	for i := range hash {
+		succinctAPI.AssertIsEqualByte(hash[i], circuit.Hash[i])
+	}
+	return nil
+}
+
+func TestHashValidatorSet(t *testing.T) {
+	assert := test.NewAssert(t)
+	const maxValidators = 6
+	validators := newTestValidators([]uint64{100, 200, 300, 1 << 40, 5, 128})
+	for _, n := range []int{1, 2, 3, 5, 6} {
+		circuit := &TestHashValidatorSetCircuit{
+			Validators:   newValidators(validators[:n], maxValidators),
+			NbValidators: vars.NewVariableFromInt(n),
+		}
+		vars.SetBytes32(&circuit.Hash, hashValidatorSet(validators[:n]))
+		assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()), "%d validators", n)
+	}
+
+	// The hash of a set with a validator less does not match.
+	circuit := &TestHashValidatorSetCircuit{
+		Validators:   newValidators(validators[:5], maxValidators),
+		NbValidators: vars.NewVariableFromInt(4),
+	}
+	vars.SetBytes32(&circuit.Hash, hashValidatorSet(validators[:5]))
+	assert.Error(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+}
+
+type TestVerifyCommitCircuit struct {
+	Validators     []Validator
+	NbValidators   vars.Variable
+	ValidatorsHash [32]vars.Byte
+	Height         vars.U64
+	Round          vars.U64
+	BlockID        BlockID
+	Sigs           []CommitSig
+}
+
+func (circuit *TestVerifyCommitCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	tendermint := NewAPI(succinctAPI)
+	tendermint.VerifyCommit(
+		circuit.Validators,
+		circuit.NbValidators,
+		circuit.ValidatorsHash,
+		testChainID,
+		circuit.Height,
+		circuit.Round,
+		circuit.BlockID,
+		circuit.Sigs,
+	)
+	return nil
+}
+
+// Signs the test vote with the validators whose signed bit is set, where each validator votes
+// with its own timestamp.
+func newVerifyCommitCircuit(validators []testValidator, signed []bool, maxValidators int) *TestVerifyCommitCircuit {
+	circuit := &TestVerifyCommitCircuit{
+		Validators:   newValidators(validators, maxValidators),
+		NbValidators: vars.NewVariableFromInt(len(validators)),
+		BlockID:      newBlockID(testVoteValue),
+	}
+	vars.SetBytes32(&circuit.ValidatorsHash, hashValidatorSet(validators))
+	circuit.Height.Set(testVoteValue.height)
+	circuit.Round.Set(testVoteValue.round)
+	for i := 0; i < maxValidators; i++ {
+		vote := testVoteValue
+		vote.nanos += uint32(i)
+		var sig [64]byte
+		isSigned := i < len(signed) && signed[i]
+		if isSigned {
+			copy(sig[:], ed25519.Sign(validators[i].key, vote.signBytes(testChainID)))
+		}
+		var r, s [32]byte
+		copy(r[:], sig[:32])
+		copy(s[:], sig[32:])
+		commitSig := CommitSig{Signed: vars.NewBool(isSigned), Timestamp: newTimestamp(vote.seconds, vote.nanos)}
+		vars.SetBytes32(&commitSig.Signature.R, r)
+		vars.SetBytes32(&commitSig.Signature.S, s)
+		circuit.Sigs = append(circuit.Sigs, commitSig)
+	}
+	return circuit
+}
+
+func TestVerifyCommit(t *testing.T) {
+	assert := test.NewAssert(t)
+	const maxValidators = 4
+	validators := newTestValidators([]uint64{100, 50, 30})
+
+	// The signers hold 150 of 180, which is more than 2/3.
+	circuit := newVerifyCommitCircuit(validators, []bool{true, true, false}, maxValidators)
+	assert.NoError(test.IsSolved(circuit, circuit, ecc.BN254.ScalarField()))
+
+	// The signers hold 80 of 180, which is not enough.
+	witness := newVerifyCommitCircuit(validators, []bool{false, true, true}, maxValidators)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+	// A signature over another timestamp is rejected.
+	witness = newVerifyCommitCircuit(validators, []bool{true, true, false}, maxValidators)
+	witness.Sigs[1].Timestamp.Nanos = vars.NewVariableFromInt(int(testVoteValue.nanos))
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,64 @@
+package tendermint
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// A validator with its ed25519 public key and voting power, which is positive and less than 2^63.
+type Validator struct {
+	PubKey      [32]vars.Byte
+	VotingPower vars.U64
+}
+
+// The prefix of the leaf of a validator in the tree of a validator set, i.e. the leaf
+// prefix 0x00 and the protobuf encoding of the public key of a SimpleValidator up to the key bytes.
+var validatorLeafPrefix = []byte{0x00, fieldKey(1, wireBytes), 34, fieldKey(1, wireBytes), 32}
+
+// Computes the hash of the validator set whose first nbValidators entries are the validators,
+// i.e. the root of the RFC 6962 Merkle tree of the encoded SimpleValidators, which is the
+// validators hash of a header. The number of validators must be in [1, len(validators)].
+func (a *API) HashValidatorSet(validators []Validator, nbValidators vars.Variable) [32]vars.Byte {
+	defer a.api.Scope("hash_validator_set")()
+	a.api.AssertIsEqual(a.api.IsZero(nbValidators).Value, vars.ZERO)
+	isValidator := a.api.PrefixMask(nbValidators, len(validators))
+
+	// The leaf of a validator is SHA256(0x00 || SimpleValidator), where the varint of the voting
+	// power is the last field, so the encoding is a fixed prefix followed by the varint.
+	layer := make([][32]vars.Byte, len(validators))
+	for i := range validators {
+		data := constantBytes(validatorLeafPrefix).Data
+		data = append(data, validators[i].PubKey[:]...)
+		data = append(data, vars.Byte{Value: vars.NewVariableFromInt(int(fieldKey(2, wireVarint)))})
+		power := a.encodeVarint(validators[i].VotingPower.Value, 63)
+		data = append(data, power.Data...)
+		length := a.api.Add(vars.NewVariableFromInt(len(validatorLeafPrefix)+33), power.Length)
+		layer[i] = sha256.HashVariable(a.api, data, length)
+	}
+
+	// The tree of n leaves splits at the largest power of two less than n, which is equivalent to
+	// hashing the nodes in pairs level by level, where the last node of a level is promoted to the
+	// next level if it has no sibling. A node exists iff its left child exists.
+	exists := isValidator
+	for len(layer) > 1 {
+		if len(layer)%2 == 1 {
+			layer = append(layer, vars.NewBytes32())
+			exists = append(exists, vars.ZERO)
+		}
+		next := make([][32]vars.Byte, len(layer)/2)
+		nextExists := make([]vars.Variable, len(layer)/2)
+		for i := range next {
+			in := []vars.Byte{{Value: vars.ONE}}
+			in = append(in, layer[2*i][:]...)
+			in = append(in, layer[2*i+1][:]...)
+			parent := sha256.Hash(a.api, in)
+			hasSibling := vars.Bool{Value: exists[2*i+1]}
+			for j := 0; j < 32; j++ {
+				next[i][j] = a.api.SelectByte(hasSibling, parent[j], layer[2*i][j])
+			}
+			nextExists[i] = exists[2*i]
+		}
+		layer, exists = next, nextExists
+	}
+	return layer[0]
+}
@@ -0,0 +1,61 @@
+package tendermint
+
+import (
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The type of a precommit vote, which is the type of the votes of a commit.
+const precommitType = 2
+
+// The identifier of a block, i.e. its hash and the header of its part set.
+type BlockID struct {
+	Hash         [32]vars.Byte
+	PartSetTotal vars.Variable
+	PartSetHash  [32]vars.Byte
+}
+
+// A timestamp as seconds and nanoseconds since the Unix epoch.
+type Timestamp struct {
+	Seconds vars.U64
+	Nanos   vars.Variable
+}
+
+// The fields of a precommit vote for a block. The height, the round and the seconds of the
+// timestamp must be non-negative, which holds for every vote of a valid commit.
+type Vote struct {
+	Height    vars.U64
+	Round     vars.U64
+	BlockID   BlockID
+	Timestamp Timestamp
+}
+
+// Encodes the sign bytes of a precommit vote on the chain with the given ID, i.e. the
+// length-delimited protobuf encoding of the CanonicalVote, which is the message signed by the
+// validators.
+func (a *API) EncodeVote(vote Vote, chainID string) vars.VariableBytes {
+	defer a.api.Scope("encode_vote")()
+	partSetHeader := a.api.ConcatVariableBytes(
+		a.encodeVarintField(1, vote.BlockID.PartSetTotal, 32),
+		a.encodeBytesField(2, vars.VariableBytes{Data: vote.BlockID.PartSetHash[:], Length: vars.NewVariableFromInt(32)}),
+	)
+	blockID := a.api.ConcatVariableBytes(
+		a.encodeBytesField(1, vars.VariableBytes{Data: vote.BlockID.Hash[:], Length: vars.NewVariableFromInt(32)}),
+		a.encodeBytesField(2, partSetHeader),
+	)
+	timestamp := a.api.ConcatVariableBytes(
+		a.encodeVarintField(1, vote.Timestamp.Seconds.Value, 63),
+		a.encodeVarintField(2, vote.Timestamp.Nanos, 30),
+	)
+	parts := []vars.VariableBytes{
+		constantBytes([]byte{fieldKey(1, wireVarint), precommitType}),
+		a.encodeFixed64Field(2, vote.Height),
+		a.encodeFixed64Field(3, vote.Round),
+		a.encodeBytesField(4, blockID),
+		a.encodeBytesField(5, timestamp),
+	}
+	if chainID != "" {
+		parts = append(parts, a.encodeBytesField(6, constantBytes([]byte(chainID))))
+	}
+	msg := a.api.ConcatVariableBytes(parts...)
+	return a.api.ConcatVariableBytes(a.encodeVarint(msg.Length, 14), msg)
+}
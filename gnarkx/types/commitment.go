@@ -0,0 +1,97 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Groth16CommitmentWires recomputes the values of the commitment wires of a proof, which follow
+// the public inputs in the public witness the verifier checks the proof against. The value of a
+// commitment wire is the hash of the commitment and of the wires listed for it in
+// PublicAndCommitmentCommitted of the verifying key, which are indexed from one, after the wire of
+// the constant one, into the public inputs followed by the commitment wires. The commitments are
+// hashed in order, so a commitment may depend on the commitment wires before it, as is the case in
+// circuits with nested commitments.
+func Groth16CommitmentWires(vk *groth16_bn254.VerifyingKey, proof *Groth16Proof, publicInputs fr.Vector) (fr.Vector, error) {
+	transcript, err := Groth16CommitmentTranscript(vk, proof, publicInputs)
+	if err != nil {
+		return nil, err
+	}
+	wires := make(fr.Vector, len(transcript))
+	for i, step := range transcript {
+		wires[i].SetBigInt(step.Challenge.ToInt())
+	}
+	return wires, nil
+}
+
+// Groth16CommitmentTranscript returns the hashes of Groth16CommitmentWires, with the prehash, the
+// domain separation tag and the resulting value of each commitment wire.
+func Groth16CommitmentTranscript(vk *groth16_bn254.VerifyingKey, proof *Groth16Proof, publicInputs fr.Vector) ([]TranscriptStep, error) {
+	nbCommitments := len(vk.PublicAndCommitmentCommitted)
+	if len(proof.Commitments) != nbCommitments {
+		return nil, fmt.Errorf("%w: expected %d commitments, got %d", ErrProofInvalid, nbCommitments, len(proof.Commitments))
+	}
+	if nbPublicInputs := len(vk.G1.K) - 1 - nbCommitments; len(publicInputs) != nbPublicInputs {
+		return nil, fmt.Errorf("%w: expected %d public inputs, got %d", ErrProofInvalid, nbPublicInputs, len(publicInputs))
+	}
+
+	wires := make(fr.Vector, len(publicInputs), len(publicInputs)+nbCommitments)
+	copy(wires, publicInputs)
+	transcript := make([]TranscriptStep, 0, nbCommitments)
+	for i, committed := range vk.PublicAndCommitmentCommitted {
+		var commitment bn254.G1Affine
+		commitment.X.SetBigInt(proof.Commitments[i][0])
+		commitment.Y.SetBigInt(proof.Commitments[i][1])
+		prehash := commitment.Marshal()
+		for _, index := range committed {
+			// A commitment can only depend on the public inputs and the commitments before it.
+			if index < 1 || index > len(wires) {
+				return nil, fmt.Errorf("commitment %d depends on wire %d, which is not known before it", i, index)
+			}
+			value := wires[index-1].Bytes()
+			prehash = append(prehash, value[:]...)
+		}
+		hash, err := fr.Hash(prehash, []byte(constraint.CommitmentDst), 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash commitment %d: %w", i, err)
+		}
+		wires = append(wires, hash[0])
+		transcript = append(transcript, TranscriptStep{
+			Name:      fmt.Sprintf("commitment[%d]", i),
+			DST:       constraint.CommitmentDst,
+			Prehash:   prehash,
+			Challenge: (*hexutil.Big)(hash[0].BigInt(new(big.Int))),
+		})
+	}
+	return transcript, nil
+}
+
+// Groth16VerifierInputs returns the public inputs of the Solidity verifier of the key for the proof
+// and the public witness, i.e. the public inputs followed by the values of the commitment wires.
+func Groth16VerifierInputs(vk groth16.VerifyingKey, proof *Groth16Proof, publicWitness witness.Witness) ([]*big.Int, error) {
+	_vk, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported verifying key %T", vk)
+	}
+	publicInputs, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public witness %T", publicWitness.Vector())
+	}
+	commitmentWires, err := Groth16CommitmentWires(_vk, proof, publicInputs)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]*big.Int, 0, len(publicInputs)+len(commitmentWires))
+	for _, value := range append(append(fr.Vector{}, publicInputs...), commitmentWires...) {
+		inputs = append(inputs, value.BigInt(new(big.Int)))
+	}
+	return inputs, nil
+}
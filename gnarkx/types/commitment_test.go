@@ -0,0 +1,140 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCommitmentCircuit commits twice, where the second commitment depends on the first one.
+type testCommitmentCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+	Z frontend.Variable
+}
+
+func (c *testCommitmentCircuit) Define(api frontend.API) error {
+	committer := api.(frontend.Committer)
+	first, err := committer.Commit(c.X, c.Y)
+	if err != nil {
+		return err
+	}
+	second, err := committer.Commit(first, c.Z)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(first, c.Y)
+	api.AssertIsDifferent(second, c.Z)
+	return nil
+}
+
+// checkGroth16Pairing checks the pairing equation of the Groth16 verifier with commitments, i.e.
+// e(A, B) = e(α, β)⋅e(L, γ)⋅e(C, δ), where L is the public input point including the commitments.
+func checkGroth16Pairing(vk *groth16_bn254.VerifyingKey, proof *groth16_bn254.Proof, inputs []*big.Int) (bool, error) {
+	var l bn254.G1Jac
+	l.FromAffine(&vk.G1.K[0])
+	for i, input := range inputs {
+		var term bn254.G1Affine
+		term.ScalarMultiplication(&vk.G1.K[i+1], input)
+		l.AddMixed(&term)
+	}
+	for i := range proof.Commitments {
+		l.AddMixed(&proof.Commitments[i])
+	}
+	var lAffine, alphaNeg, lNeg, cNeg bn254.G1Affine
+	lAffine.FromJacobian(&l)
+	alphaNeg.Neg(&vk.G1.Alpha)
+	lNeg.Neg(&lAffine)
+	cNeg.Neg(&proof.Krs)
+	return bn254.PairingCheck(
+		[]bn254.G1Affine{proof.Ar, alphaNeg, lNeg, cNeg},
+		[]bn254.G2Affine{proof.Bs, vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta},
+	)
+}
+
+func TestGroth16Commitments(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testCommitmentCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&testCommitmentCircuit{X: 1, Y: 2, Z: 3}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	gnarkProof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+
+	proof, err := NewGroth16Proof(gnarkProof)
+	assert.NoError(t, err)
+	assert.Len(t, proof.Commitments, 2)
+	assert.NotNil(t, proof.CommitmentPok)
+
+	// The commitments survive the JSON, Solidity and CBOR encodings, and the decoded proofs verify.
+	data, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	var fromJSON Groth16Proof
+	assert.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, *proof, fromJSON)
+	solidity := proof.MarshalSolidity()
+	assert.Len(t, solidity, Groth16ProofSolidityLength+3*64)
+	var fromSolidity Groth16Proof
+	assert.NoError(t, fromSolidity.UnmarshalSolidity(solidity))
+	assert.Equal(t, *proof, fromSolidity)
+	data, err = proof.MarshalCBOR()
+	assert.NoError(t, err)
+	var fromCBOR Groth16Proof
+	assert.NoError(t, fromCBOR.UnmarshalCBOR(data))
+	assert.Equal(t, *proof, fromCBOR)
+	reconstructed, err := fromSolidity.ToGnark()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(reconstructed, vk, publicWitness))
+
+	// An encoding with a single extra word pair has a proof of knowledge without commitments.
+	assert.ErrorIs(t, fromSolidity.UnmarshalSolidity(solidity[:Groth16ProofSolidityLength+64]), ErrSerialization)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"a":[1,2],"b":[[0,0],[0,0]],"c":[1,2],"commitments":[[1,2]]}`), &fromJSON), ErrSerialization)
+
+	// The public input point of the recomputed commitment wires satisfies the pairing equation,
+	// and of other wires does not.
+	inputs, err := Groth16VerifierInputs(vk, proof, publicWitness)
+	assert.NoError(t, err)
+	assert.Len(t, inputs, 3)
+	assert.Equal(t, big.NewInt(1), inputs[0])
+	_vk, _proof := vk.(*groth16_bn254.VerifyingKey), gnarkProof.(*groth16_bn254.Proof)
+	ok, err := checkGroth16Pairing(_vk, _proof, inputs)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	inputs[2] = new(big.Int).Add(inputs[2], big.NewInt(1))
+	ok, err = checkGroth16Pairing(_vk, _proof, inputs)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// The transcript has the prehash of each commitment wire, where the second commitment hashes
+	// the first commitment wire.
+	transcript, err := Groth16CommitmentTranscript(_vk, proof, publicWitness.Vector().(fr.Vector))
+	assert.NoError(t, err)
+	assert.Len(t, transcript, 2)
+	assert.Equal(t, "commitment[1]", transcript[1].Name)
+	assert.Equal(t, "bsb22-commitment", transcript[1].DST)
+	firstWire := make([]byte, 32)
+	transcript[0].Challenge.ToInt().FillBytes(firstWire)
+	assert.Equal(t, firstWire, []byte(transcript[1].Prehash[len(transcript[1].Prehash)-32:]))
+
+	// Commitments may only depend on the wires before them.
+	forward := *_vk
+	forward.PublicAndCommitmentCommitted = [][]int{{1, 3}, {}}
+	_, err = Groth16CommitmentWires(&forward, proof, publicWitness.Vector().(fr.Vector))
+	assert.ErrorContains(t, err, "commitment 0 depends on wire 3")
+	withoutCommitments := *proof
+	withoutCommitments.Commitments = nil
+	_, err = Groth16VerifierInputs(vk, &withoutCommitments, publicWitness)
+	assert.ErrorIs(t, err, ErrProofInvalid)
+}
@@ -0,0 +1,259 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Proofs and their results can also be encoded in CBOR and protobuf, for consumers like mobile light
+// clients and embedded relayers which cannot afford to parse JSON with large numbers and hex
+// strings. In both encodings the points of a proof are its Solidity encoding, i.e. the 32 byte
+// words of A, B and C followed by the words of its commitments, if any, and the fields are numbered
+// as in the protobuf schema in proof.proto, with CBOR maps keyed by the field numbers.
+
+// groth16ProofCBOR is the CBOR encoding of a proof.
+type groth16ProofCBOR struct {
+	Proof  []byte `cbor:"1,keyasint"`
+	Input  []byte `cbor:"2,keyasint,omitempty"`
+	Output []byte `cbor:"3,keyasint,omitempty"`
+}
+
+// MarshalCBOR encodes the proof as a CBOR map of its Solidity encoding and its input and output.
+func (g Groth16Proof) MarshalCBOR() ([]byte, error) {
+	if err := g.checkWords(); err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(groth16ProofCBOR{Proof: g.MarshalSolidity(), Input: g.Input, Output: g.Output})
+}
+
+// UnmarshalCBOR decodes the proof from a CBOR map of its Solidity encoding and its input and output.
+func (g *Groth16Proof) UnmarshalCBOR(data []byte) error {
+	var decoded groth16ProofCBOR
+	if err := cbor.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("%w: %w", ErrSerialization, err)
+	}
+	if err := g.UnmarshalSolidity(decoded.Proof); err != nil {
+		return err
+	}
+	g.Input, g.Output = decoded.Input, decoded.Output
+	return nil
+}
+
+// MarshalProto encodes the proof as a Groth16Proof protobuf message.
+func (g *Groth16Proof) MarshalProto() ([]byte, error) {
+	if err := g.checkWords(); err != nil {
+		return nil, err
+	}
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, g.MarshalSolidity())
+	if len(g.Input) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, g.Input)
+	}
+	if len(g.Output) > 0 {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, g.Output)
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes the proof from a Groth16Proof protobuf message.
+func (g *Groth16Proof) UnmarshalProto(data []byte) error {
+	var proof, input, output []byte
+	err := consumeProto(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeProtoBytes(typ, b, &proof)
+		case 2:
+			return consumeProtoBytes(typ, b, &input)
+		case 3:
+			return consumeProtoBytes(typ, b, &output)
+		}
+		return -1, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := g.UnmarshalSolidity(proof); err != nil {
+		return err
+	}
+	g.Input, g.Output = input, output
+	return nil
+}
+
+// checkWords checks that the coordinates of the proof fit in its Solidity encoding.
+func (g *Groth16Proof) checkWords() error {
+	for i, word := range g.words() {
+		if word == nil {
+			return fmt.Errorf("%w: coordinate %d of the proof is missing", ErrSerialization, i)
+		}
+		if word.Sign() < 0 || word.BitLen() > 256 {
+			return fmt.Errorf("%w: coordinate %d of the proof does not fit in a uint256", ErrSerialization, i)
+		}
+	}
+	return nil
+}
+
+// proofResultCBOR is the CBOR encoding of a proof result.
+type proofResultCBOR struct {
+	Version          int    `cbor:"1,keyasint,omitempty"`
+	Proof            []byte `cbor:"2,keyasint"`
+	Output           []byte `cbor:"3,keyasint"`
+	CircuitDigest    []byte `cbor:"4,keyasint,omitempty"`
+	VerifyingKeyHash []byte `cbor:"5,keyasint,omitempty"`
+	ProverVersion    string `cbor:"6,keyasint,omitempty"`
+	Timestamp        uint64 `cbor:"7,keyasint,omitempty"`
+}
+
+// MarshalCBOR encodes the result as a CBOR map keyed by the numbers of its fields.
+func (r ProofResult) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(proofResultCBOR{
+		Version:          r.Version,
+		Proof:            r.Proof,
+		Output:           r.Output,
+		CircuitDigest:    r.CircuitDigest,
+		VerifyingKeyHash: r.VerifyingKeyHash,
+		ProverVersion:    r.ProverVersion,
+		Timestamp:        r.Timestamp,
+	})
+}
+
+// UnmarshalCBOR decodes the result from a CBOR map keyed by the numbers of its fields. Like with
+// JSON, results of newer versions are rejected.
+func (r *ProofResult) UnmarshalCBOR(data []byte) error {
+	var decoded proofResultCBOR
+	if err := cbor.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("%w: %w", ErrSerialization, err)
+	}
+	if decoded.Version < 0 || decoded.Version > ProofResultVersion {
+		return fmt.Errorf("%w: unsupported proof result version %d", ErrSerialization, decoded.Version)
+	}
+	*r = ProofResult{
+		Version:          decoded.Version,
+		Proof:            decoded.Proof,
+		Output:           decoded.Output,
+		CircuitDigest:    decoded.CircuitDigest,
+		VerifyingKeyHash: decoded.VerifyingKeyHash,
+		ProverVersion:    decoded.ProverVersion,
+		Timestamp:        decoded.Timestamp,
+	}
+	return nil
+}
+
+// MarshalProto encodes the result as a ProofResult protobuf message.
+func (r *ProofResult) MarshalProto() ([]byte, error) {
+	if r.Version < 0 {
+		return nil, fmt.Errorf("%w: invalid proof result version %d", ErrSerialization, r.Version)
+	}
+	var b []byte
+	appendBytes := func(num protowire.Number, v []byte) {
+		if len(v) > 0 {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendBytes(b, v)
+		}
+	}
+	if r.Version != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(r.Version))
+	}
+	appendBytes(2, r.Proof)
+	appendBytes(3, r.Output)
+	appendBytes(4, r.CircuitDigest)
+	appendBytes(5, r.VerifyingKeyHash)
+	appendBytes(6, []byte(r.ProverVersion))
+	if r.Timestamp != 0 {
+		b = protowire.AppendTag(b, 7, protowire.VarintType)
+		b = protowire.AppendVarint(b, r.Timestamp)
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes the result from a ProofResult protobuf message. Like with JSON, results of
+// newer versions are rejected.
+func (r *ProofResult) UnmarshalProto(data []byte) error {
+	var decoded ProofResult
+	var version uint64
+	var proof, output, circuitDigest, vkHash, proverVersion []byte
+	err := consumeProto(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeProtoVarint(typ, b, &version)
+		case 2:
+			return consumeProtoBytes(typ, b, &proof)
+		case 3:
+			return consumeProtoBytes(typ, b, &output)
+		case 4:
+			return consumeProtoBytes(typ, b, &circuitDigest)
+		case 5:
+			return consumeProtoBytes(typ, b, &vkHash)
+		case 6:
+			return consumeProtoBytes(typ, b, &proverVersion)
+		case 7:
+			return consumeProtoVarint(typ, b, &decoded.Timestamp)
+		}
+		return -1, nil
+	})
+	if err != nil {
+		return err
+	}
+	if version > ProofResultVersion {
+		return fmt.Errorf("%w: unsupported proof result version %d", ErrSerialization, version)
+	}
+	decoded.Version = int(version)
+	decoded.Proof, decoded.Output = proof, output
+	decoded.CircuitDigest, decoded.VerifyingKeyHash = circuitDigest, vkHash
+	decoded.ProverVersion = string(proverVersion)
+	*r = decoded
+	return nil
+}
+
+// consumeProto calls field for each field of the protobuf message, which returns the length of the
+// value it consumed, or -1 for unknown fields, which are skipped.
+func consumeProto(data []byte, field func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("%w: invalid protobuf tag: %w", ErrSerialization, protowire.ParseError(n))
+		}
+		data = data[n:]
+		n, err := field(num, typ, data)
+		if err != nil {
+			return fmt.Errorf("%w: invalid protobuf field %d: %w", ErrSerialization, num, err)
+		}
+		if n < 0 {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return fmt.Errorf("%w: invalid protobuf field %d: %w", ErrSerialization, num, protowire.ParseError(n))
+			}
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func consumeProtoBytes(typ protowire.Type, b []byte, v *[]byte) (int, error) {
+	if typ != protowire.BytesType {
+		return 0, fmt.Errorf("expected bytes, got wire type %d", typ)
+	}
+	value, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = append([]byte(nil), value...)
+	return n, nil
+}
+
+func consumeProtoVarint(typ protowire.Type, b []byte, v *uint64) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, fmt.Errorf("expected varint, got wire type %d", typ)
+	}
+	value, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = value
+	return n, nil
+}
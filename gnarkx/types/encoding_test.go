@@ -0,0 +1,71 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestGroth16ProofCompactEncodings(t *testing.T) {
+	one, two := big.NewInt(1), big.NewInt(2)
+	zero := new(big.Int)
+	proof := Groth16Proof{A: [2]*big.Int{one, two}, B: [2][2]*big.Int{{zero, zero}, {zero, zero}}, C: [2]*big.Int{one, two}, Output: []byte{3}}
+
+	data, err := cbor.Marshal(proof)
+	assert.NoError(t, err)
+	var decoded Groth16Proof
+	assert.NoError(t, cbor.Unmarshal(data, &decoded))
+	assert.Equal(t, proof.MarshalSolidity(), decoded.MarshalSolidity())
+	assert.Equal(t, proof.Output, decoded.Output)
+	assert.NoError(t, decoded.Validate())
+
+	data, err = proof.MarshalProto()
+	assert.NoError(t, err)
+	decoded = Groth16Proof{}
+	assert.NoError(t, decoded.UnmarshalProto(data))
+	assert.Equal(t, proof.MarshalSolidity(), decoded.MarshalSolidity())
+	assert.Equal(t, proof.Output, decoded.Output)
+
+	// Unknown fields of newer schemas are skipped.
+	data = protowire.AppendTag(data, 15, protowire.VarintType)
+	data = protowire.AppendVarint(data, 42)
+	assert.NoError(t, decoded.UnmarshalProto(data))
+	assert.Equal(t, proof.MarshalSolidity(), decoded.MarshalSolidity())
+	assert.Equal(t, proof.Output, decoded.Output)
+
+	assert.ErrorIs(t, decoded.UnmarshalProto([]byte{0x0a, 0x02, 0x01}), ErrSerialization)
+	proof.C[0] = nil
+	_, err = proof.MarshalProto()
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestProofResultCompactEncodings(t *testing.T) {
+	result := NewProofResult(make([]byte, Groth16ProofSolidityLength), []byte{3}, 1700000000)
+	result.CircuitDigest = []byte{4}
+	result.VerifyingKeyHash = []byte{5}
+	result.ProverVersion = "v1.0.0"
+
+	data, err := cbor.Marshal(result)
+	assert.NoError(t, err)
+	var decoded ProofResult
+	assert.NoError(t, cbor.Unmarshal(data, &decoded))
+	assert.Equal(t, *result, decoded)
+
+	data, err = result.MarshalProto()
+	assert.NoError(t, err)
+	decoded = ProofResult{}
+	assert.NoError(t, decoded.UnmarshalProto(data))
+	assert.Equal(t, *result, decoded)
+
+	// Results of a newer version are rejected.
+	result.Version = ProofResultVersion + 1
+	data, err = cbor.Marshal(result)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, cbor.Unmarshal(data, &decoded), "unsupported proof result version")
+	data, err = result.MarshalProto()
+	assert.NoError(t, err)
+	assert.ErrorContains(t, decoded.UnmarshalProto(data), "unsupported proof result version")
+}
@@ -0,0 +1,27 @@
+package types
+
+import "errors"
+
+// The classes of errors of proving and verifying, which the errors of this package and of the
+// plonky2x verifier wrap, so callers can branch on them with errors.Is.
+var (
+	// ErrArtifactMissing is the class of errors of a file of a data or circuit directory, e.g. a
+	// proving key or a plonky2 proof, which does not exist.
+	ErrArtifactMissing = errors.New("artifact missing")
+
+	// ErrDigestMismatch is the class of errors of data which does not hash to its expected digest,
+	// e.g. a corrupted artifact or a receipt for another proof.
+	ErrDigestMismatch = errors.New("digest mismatch")
+
+	// ErrWitnessUnsatisfied is the class of errors of an assignment which does not satisfy the
+	// constraints of the circuit, so it can never be proven.
+	ErrWitnessUnsatisfied = errors.New("witness unsatisfied")
+
+	// ErrProofInvalid is the class of errors of a proof which does not verify, or is not a valid
+	// proof at all, e.g. one whose points are not on the curve.
+	ErrProofInvalid = errors.New("proof invalid")
+
+	// ErrSerialization is the class of errors of data which cannot be encoded or decoded, e.g. a
+	// truncated proof or an unsupported version.
+	ErrSerialization = errors.New("serialization failed")
+)
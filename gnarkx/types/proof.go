@@ -6,15 +6,296 @@ import (
 	"math/big"
 	"os"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
+// A Groth16 proof over BN254, with the coordinates of its points in the order of the Solidity
+// verifier, where the coordinates of B in Fp2 are ordered (imaginary, real). Proofs of circuits
+// with commitments also have a point per commitment, and the batched proof of knowledge of them.
 type Groth16Proof struct {
-	A      [2]*big.Int    `json:"a"`
-	B      [2][2]*big.Int `json:"b"`
-	C      [2]*big.Int    `json:"c"`
-	Input  hexutil.Bytes  `json:"input,omitempty"`
-	Output hexutil.Bytes  `json:"output,omitempty"`
+	A             [2]*big.Int    `json:"a"`
+	B             [2][2]*big.Int `json:"b"`
+	C             [2]*big.Int    `json:"c"`
+	Commitments   [][2]*big.Int  `json:"commitments,omitempty"`
+	CommitmentPok *[2]*big.Int   `json:"commitment_pok,omitempty"`
+	Input         hexutil.Bytes  `json:"input,omitempty"`
+	Output        hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// The size of the Solidity encoding of a proof without commitments, i.e. of uint256[8]. Each
+// commitment adds two words, and the proof of knowledge of the commitments two more.
+const Groth16ProofSolidityLength = 8 * 32
+
+// NewGroth16Proof reads the coordinates of the points of the proof of gnark, including its
+// commitments, of which there may be any number. The proof is normalized, see Normalize.
+func NewGroth16Proof(proof groth16.Proof) (*Groth16Proof, error) {
+	p, ok := proof.(*groth16_bn254.Proof)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported proof %T", ErrSerialization, proof)
+	}
+	g := &Groth16Proof{}
+	g.setPoints(p)
+	ar, bs := normalizeSigns(p.Ar, p.Bs)
+	g.setAB(&ar, &bs)
+	return g, nil
+}
+
+// Normalize canonicalizes the encoding of the proof. Since e(-A, -B) = e(A, B), the verifier
+// accepts (-A, -B, C) whenever it accepts (A, B, C), so every proof has a second, equally valid
+// encoding. Of the two, the normalized one is where the y coordinate of A is not lexicographically
+// largest, i.e. at most (p-1)/2, so systems keying on the bytes of proofs, like deduplication and
+// receipts, see a single encoding of each proof. Proofs whose points are invalid, including those
+// with coordinates which are not reduced, are rejected, see Validate.
+func (g *Groth16Proof) Normalize() error {
+	proof, err := g.ToGnark()
+	if err != nil {
+		return err
+	}
+	p := proof.(*groth16_bn254.Proof)
+	ar, bs := normalizeSigns(p.Ar, p.Bs)
+	g.setAB(&ar, &bs)
+	return nil
+}
+
+// IsNormalized reports whether the proof is valid and normalized, see Normalize.
+func (g *Groth16Proof) IsNormalized() bool {
+	proof, err := g.ToGnark()
+	if err != nil {
+		return false
+	}
+	return !proof.(*groth16_bn254.Proof).Ar.Y.LexicographicallyLargest()
+}
+
+// normalizeSigns returns the points A and B of a proof, negated if the y coordinate of A is
+// lexicographically largest.
+func normalizeSigns(ar bn254.G1Affine, bs bn254.G2Affine) (bn254.G1Affine, bn254.G2Affine) {
+	if ar.Y.LexicographicallyLargest() {
+		ar.Neg(&ar)
+		bs.Neg(&bs)
+	}
+	return ar, bs
+}
+
+// setAB sets the coordinates of the points A and B of the proof.
+func (g *Groth16Proof) setAB(ar *bn254.G1Affine, bs *bn254.G2Affine) {
+	g.A[0] = ar.X.BigInt(new(big.Int))
+	g.A[1] = ar.Y.BigInt(new(big.Int))
+	g.B[0][0] = bs.X.A1.BigInt(new(big.Int))
+	g.B[0][1] = bs.X.A0.BigInt(new(big.Int))
+	g.B[1][0] = bs.Y.A1.BigInt(new(big.Int))
+	g.B[1][1] = bs.Y.A0.BigInt(new(big.Int))
+}
+
+// setPoints sets the coordinates of the points of the proof to those of the proof of gnark.
+func (g *Groth16Proof) setPoints(p *groth16_bn254.Proof) {
+	g.setAB(&p.Ar, &p.Bs)
+	g.C[0] = p.Krs.X.BigInt(new(big.Int))
+	g.C[1] = p.Krs.Y.BigInt(new(big.Int))
+	g.Commitments, g.CommitmentPok = nil, nil
+	if len(p.Commitments) > 0 {
+		for _, commitment := range p.Commitments {
+			g.Commitments = append(g.Commitments, [2]*big.Int{commitment.X.BigInt(new(big.Int)), commitment.Y.BigInt(new(big.Int))})
+		}
+		g.CommitmentPok = &[2]*big.Int{p.CommitmentPok.X.BigInt(new(big.Int)), p.CommitmentPok.Y.BigInt(new(big.Int))}
+	}
+}
+
+// words returns the coordinates of the proof in the order of the Solidity encoding, i.e. A, B and
+// C, followed by the commitments and their proof of knowledge, if the proof has commitments.
+func (g *Groth16Proof) words() []*big.Int {
+	words := []*big.Int{g.A[0], g.A[1], g.B[0][0], g.B[0][1], g.B[1][0], g.B[1][1], g.C[0], g.C[1]}
+	if len(g.Commitments) > 0 || g.CommitmentPok != nil {
+		for _, commitment := range g.Commitments {
+			words = append(words, commitment[0], commitment[1])
+		}
+		if g.CommitmentPok == nil {
+			words = append(words, nil, nil)
+		} else {
+			words = append(words, g.CommitmentPok[0], g.CommitmentPok[1])
+		}
+	}
+	return words
+}
+
+// MarshalSolidity returns the ABI encoding of the proof as the uint256[8] proof argument of the
+// Groth16 verifier, i.e. A, B and C as 32 byte big-endian words, written directly into the result.
+// The commitments and their proof of knowledge follow as further words, if the proof has any.
+func (g *Groth16Proof) MarshalSolidity() []byte {
+	words := g.words()
+	result := make([]byte, 32*len(words))
+	for i, word := range words {
+		word.FillBytes(result[32*i : 32*(i+1)])
+	}
+	return result
+}
+
+// UnmarshalSolidity decodes the points of the proof from the ABI encoding of uint256[8], followed
+// by the commitments and their proof of knowledge, if any. The input and output bytes are left as
+// they are.
+func (g *Groth16Proof) UnmarshalSolidity(data []byte) error {
+	// Proofs with commitments have at least one commitment and the proof of knowledge.
+	extra := len(data) - Groth16ProofSolidityLength
+	if extra < 0 || extra%64 != 0 || extra == 64 {
+		return fmt.Errorf("%w: proof must be %d bytes and two words per commitment and its proof of knowledge, got %d", ErrSerialization, Groth16ProofSolidityLength, len(data))
+	}
+	word := func(i int) *big.Int {
+		return new(big.Int).SetBytes(data[32*i : 32*(i+1)])
+	}
+	g.A = [2]*big.Int{word(0), word(1)}
+	g.B = [2][2]*big.Int{{word(2), word(3)}, {word(4), word(5)}}
+	g.C = [2]*big.Int{word(6), word(7)}
+	g.Commitments, g.CommitmentPok = nil, nil
+	if extra > 0 {
+		nbCommitments := extra/64 - 1
+		for i := 0; i < nbCommitments; i++ {
+			g.Commitments = append(g.Commitments, [2]*big.Int{word(8 + 2*i), word(9 + 2*i)})
+		}
+		g.CommitmentPok = &[2]*big.Int{word(8 + 2*nbCommitments), word(9 + 2*nbCommitments)}
+	}
+	return nil
+}
+
+// ToGnark converts the proof to a proof of gnark, which can be verified with groth16.Verify. The
+// points are validated, see Validate.
+func (g *Groth16Proof) ToGnark() (groth16.Proof, error) {
+	for i, word := range g.words() {
+		if word == nil {
+			return nil, fmt.Errorf("%w: coordinate %d of the proof is missing", ErrProofInvalid, i)
+		}
+		if word.Sign() < 0 || word.Cmp(fp.Modulus()) >= 0 {
+			return nil, fmt.Errorf("%w: coordinate %d of the proof is not a field element", ErrProofInvalid, i)
+		}
+	}
+	p := &groth16_bn254.Proof{}
+	p.Ar.X.SetBigInt(g.A[0])
+	p.Ar.Y.SetBigInt(g.A[1])
+	p.Bs.X.A1.SetBigInt(g.B[0][0])
+	p.Bs.X.A0.SetBigInt(g.B[0][1])
+	p.Bs.Y.A1.SetBigInt(g.B[1][0])
+	p.Bs.Y.A0.SetBigInt(g.B[1][1])
+	p.Krs.X.SetBigInt(g.C[0])
+	p.Krs.Y.SetBigInt(g.C[1])
+	for _, commitment := range g.Commitments {
+		var point bn254.G1Affine
+		point.X.SetBigInt(commitment[0])
+		point.Y.SetBigInt(commitment[1])
+		p.Commitments = append(p.Commitments, point)
+	}
+	if g.CommitmentPok != nil {
+		p.CommitmentPok.X.SetBigInt(g.CommitmentPok[0])
+		p.CommitmentPok.Y.SetBigInt(g.CommitmentPok[1])
+	}
+	if !p.Ar.IsOnCurve() || !p.Ar.IsInSubGroup() {
+		return nil, fmt.Errorf("%w: point A of the proof is not in G1", ErrProofInvalid)
+	}
+	if !p.Bs.IsOnCurve() || !p.Bs.IsInSubGroup() {
+		return nil, fmt.Errorf("%w: point B of the proof is not in G2", ErrProofInvalid)
+	}
+	if !p.Krs.IsOnCurve() || !p.Krs.IsInSubGroup() {
+		return nil, fmt.Errorf("%w: point C of the proof is not in G1", ErrProofInvalid)
+	}
+	for i := range p.Commitments {
+		if !p.Commitments[i].IsOnCurve() || !p.Commitments[i].IsInSubGroup() {
+			return nil, fmt.Errorf("%w: commitment %d of the proof is not in G1", ErrProofInvalid, i)
+		}
+	}
+	if !p.CommitmentPok.IsOnCurve() || !p.CommitmentPok.IsInSubGroup() {
+		return nil, fmt.Errorf("%w: the proof of knowledge of the commitments is not in G1", ErrProofInvalid)
+	}
+	return p, nil
+}
+
+// Validate checks that the coordinates of the proof are field elements, and that its points are on
+// the curve and in the prime order subgroups.
+func (g *Groth16Proof) Validate() error {
+	_, err := g.ToGnark()
+	return err
+}
+
+// groth16ProofJSON is the JSON encoding of a proof, where the coordinates are numbers.
+type groth16ProofJSON struct {
+	A             [2]*big.Int    `json:"a"`
+	B             [2][2]*big.Int `json:"b"`
+	C             [2]*big.Int    `json:"c"`
+	Commitments   [][2]*big.Int  `json:"commitments,omitempty"`
+	CommitmentPok *[2]*big.Int   `json:"commitment_pok,omitempty"`
+	Input         hexutil.Bytes  `json:"input,omitempty"`
+	Output        hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// MarshalJSON encodes the coordinates of the proof as JSON numbers.
+func (g Groth16Proof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(groth16ProofJSON(g))
+}
+
+// UnmarshalJSON decodes a proof whose coordinates are JSON numbers, or strings of decimal or 0x
+// prefixed hexadecimal numbers, since JavaScript consumers may not be able to publish large numbers.
+func (g *Groth16Proof) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		A             [2]json.RawMessage    `json:"a"`
+		B             [2][2]json.RawMessage `json:"b"`
+		C             [2]json.RawMessage    `json:"c"`
+		Commitments   [][2]json.RawMessage  `json:"commitments,omitempty"`
+		CommitmentPok *[2]json.RawMessage   `json:"commitment_pok,omitempty"`
+		Input         hexutil.Bytes         `json:"input,omitempty"`
+		Output        hexutil.Bytes         `json:"output,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %w", ErrSerialization, err)
+	}
+	if (len(raw.Commitments) > 0) != (raw.CommitmentPok != nil) {
+		return fmt.Errorf("%w: commitments and their proof of knowledge must be given together", ErrSerialization)
+	}
+	coordinates := []json.RawMessage{raw.A[0], raw.A[1], raw.B[0][0], raw.B[0][1], raw.B[1][0], raw.B[1][1], raw.C[0], raw.C[1]}
+	for _, commitment := range raw.Commitments {
+		coordinates = append(coordinates, commitment[0], commitment[1])
+	}
+	if raw.CommitmentPok != nil {
+		coordinates = append(coordinates, raw.CommitmentPok[0], raw.CommitmentPok[1])
+	}
+	words := make([]*big.Int, len(coordinates))
+	for i, coordinate := range coordinates {
+		word, err := unmarshalJSONInt(coordinate)
+		if err != nil {
+			return fmt.Errorf("%w: invalid coordinate %d of the proof: %w", ErrSerialization, i, err)
+		}
+		words[i] = word
+	}
+	g.A = [2]*big.Int{words[0], words[1]}
+	g.B = [2][2]*big.Int{{words[2], words[3]}, {words[4], words[5]}}
+	g.C = [2]*big.Int{words[6], words[7]}
+	g.Commitments, g.CommitmentPok = nil, nil
+	for i := range raw.Commitments {
+		g.Commitments = append(g.Commitments, [2]*big.Int{words[8+2*i], words[9+2*i]})
+	}
+	if raw.CommitmentPok != nil {
+		g.CommitmentPok = &[2]*big.Int{words[len(words)-2], words[len(words)-1]}
+	}
+	g.Input, g.Output = raw.Input, raw.Output
+	return nil
+}
+
+func unmarshalJSONInt(data json.RawMessage) (*big.Int, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, fmt.Errorf("missing")
+	}
+	var s string
+	if data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+	} else {
+		s = string(data)
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("%s is not a non-negative integer", s)
+	}
+	return n, nil
 }
 
 // Export saves the proof to a file.
@@ -43,7 +324,42 @@ func (g *Groth16Proof) Export(file string) error {
 	return nil
 }
 
+// The version of the ProofResult schema. Results of version 0 were written before the schema was
+// versioned, and only have a proof and an output.
+const ProofResultVersion = 1
+
+// ProofResult is the proof of a function and its output, as archived and sent onchain, along with
+// the metadata needed to interpret it after the circuit has been upgraded.
 type ProofResult struct {
-	Proof  hexutil.Bytes `json:"proof"`
-	Output hexutil.Bytes `json:"output"`
+	Version int           `json:"version"`
+	Proof   hexutil.Bytes `json:"proof"`
+	Output  hexutil.Bytes `json:"output"`
+	// The digest of the circuit which was proven, and the hash of the verifying key of the wrapper
+	// circuit the proof is verified with.
+	CircuitDigest    hexutil.Bytes `json:"circuit_digest,omitempty"`
+	VerifyingKeyHash hexutil.Bytes `json:"vk_hash,omitempty"`
+	// The version of the prover which generated the proof, and when, in seconds since the epoch.
+	ProverVersion string `json:"prover_version,omitempty"`
+	Timestamp     uint64 `json:"timestamp,omitempty"`
+}
+
+// NewProofResult creates a result of the current version for the proof and the output, generated
+// at the given time. The metadata of the circuit is filled in by the caller.
+func NewProofResult(proof []byte, output []byte, timestamp uint64) *ProofResult {
+	return &ProofResult{Version: ProofResultVersion, Proof: proof, Output: output, Timestamp: timestamp}
+}
+
+// UnmarshalJSON decodes results of the current and of previous versions, where results without a
+// version are of version 0. Results of newer versions are rejected rather than partially decoded.
+func (r *ProofResult) UnmarshalJSON(data []byte) error {
+	type proofResult ProofResult
+	var decoded proofResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("%w: %w", ErrSerialization, err)
+	}
+	if decoded.Version < 0 || decoded.Version > ProofResultVersion {
+		return fmt.Errorf("%w: unsupported proof result version %d", ErrSerialization, decoded.Version)
+	}
+	*r = ProofResult(decoded)
+	return nil
 }
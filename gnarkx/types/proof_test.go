@@ -0,0 +1,144 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type testProofCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *testProofCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.X, api.Mul(c.Y, c.Y))
+	return nil
+}
+
+func TestGroth16ProofRoundTrip(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testProofCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&testProofCircuit{X: 9, Y: 3}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	gnarkProof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+
+	proof, err := NewGroth16Proof(gnarkProof)
+	assert.NoError(t, err)
+	proof.Input = []byte{1, 2}
+
+	// The proof published as JSON is verified locally.
+	data, err := json.Marshal(proof)
+	assert.NoError(t, err)
+	var decoded Groth16Proof
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *proof, decoded)
+	reconstructed, err := decoded.ToGnark()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(reconstructed, vk, publicWitness))
+
+	// So is the proof decoded from its Solidity encoding.
+	var fromSolidity Groth16Proof
+	assert.NoError(t, fromSolidity.UnmarshalSolidity(proof.MarshalSolidity()))
+	assert.Equal(t, proof.A, fromSolidity.A)
+	assert.Equal(t, proof.B, fromSolidity.B)
+	assert.Equal(t, proof.C, fromSolidity.C)
+	assert.ErrorIs(t, fromSolidity.UnmarshalSolidity(make([]byte, 255)), ErrSerialization)
+
+	// Coordinates may be given as decimal or hexadecimal strings.
+	data = []byte(`{"a":["` + proof.A[0].String() + `","0x` + proof.A[1].Text(16) + `"],"b":[[` + proof.B[0][0].String() + `,` + proof.B[0][1].String() + `],[` + proof.B[1][0].String() + `,` + proof.B[1][1].String() + `]],"c":[` + proof.C[0].String() + `,` + proof.C[1].String() + `]}`)
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, proof.A, decoded.A)
+	assert.Error(t, json.Unmarshal([]byte(`{"a":["-1","2"]}`), &decoded))
+}
+
+func TestGroth16ProofValidate(t *testing.T) {
+	one, two := big.NewInt(1), big.NewInt(2)
+	zero := new(big.Int)
+	// The point at infinity is encoded as (0, 0), and the generator of G1 is (1, 2).
+	proof := &Groth16Proof{A: [2]*big.Int{one, two}, B: [2][2]*big.Int{{zero, zero}, {zero, zero}}, C: [2]*big.Int{one, two}}
+	assert.NoError(t, proof.Validate())
+
+	offCurve := *proof
+	offCurve.C = [2]*big.Int{one, one}
+	assert.ErrorContains(t, offCurve.Validate(), "point C")
+	assert.ErrorIs(t, offCurve.Validate(), ErrProofInvalid)
+
+	notReduced := *proof
+	notReduced.A = [2]*big.Int{new(big.Int).Add(ecc.BN254.BaseField(), one), two}
+	assert.ErrorContains(t, notReduced.Validate(), "not a field element")
+
+	missing := *proof
+	missing.B = [2][2]*big.Int{{zero, nil}, {zero, zero}}
+	assert.ErrorContains(t, missing.Validate(), "missing")
+}
+
+func TestGroth16ProofNormalize(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testProofCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&testProofCircuit{X: 9, Y: 3}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	gnarkProof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+
+	proof, err := NewGroth16Proof(gnarkProof)
+	assert.NoError(t, err)
+	assert.True(t, proof.IsNormalized())
+
+	// Negating A and B gives another encoding of the proof, which verifies too, and normalizes to
+	// the same bytes.
+	p := ecc.BN254.BaseField()
+	negated := *proof
+	negated.A = [2]*big.Int{proof.A[0], new(big.Int).Sub(p, proof.A[1])}
+	negated.B = [2][2]*big.Int{proof.B[0], {new(big.Int).Sub(p, proof.B[1][0]), new(big.Int).Sub(p, proof.B[1][1])}}
+	assert.False(t, negated.IsNormalized())
+	reconstructed, err := negated.ToGnark()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(reconstructed, vk, publicWitness))
+	assert.NotEqual(t, proof.MarshalSolidity(), negated.MarshalSolidity())
+	assert.NoError(t, negated.Normalize())
+	assert.True(t, negated.IsNormalized())
+	assert.Equal(t, proof.MarshalSolidity(), negated.MarshalSolidity())
+	reconstructed, err = negated.ToGnark()
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(reconstructed, vk, publicWitness))
+
+	// Coordinates which are not reduced are not canonical, and are rejected.
+	notReduced := *proof
+	notReduced.C = [2]*big.Int{new(big.Int).Add(proof.C[0], p), proof.C[1]}
+	assert.False(t, notReduced.IsNormalized())
+	assert.ErrorIs(t, notReduced.Normalize(), ErrProofInvalid)
+}
+
+func TestProofResultVersions(t *testing.T) {
+	result := NewProofResult([]byte{1, 2}, []byte{3}, 1700000000)
+	result.CircuitDigest = []byte{4}
+	result.ProverVersion = "v1.0.0"
+	data, err := json.Marshal(result)
+	assert.NoError(t, err)
+	var decoded ProofResult
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *result, decoded)
+
+	// Results written before the schema was versioned are still decoded.
+	assert.NoError(t, json.Unmarshal([]byte(`{"proof":"0x0102","output":"0x03"}`), &decoded))
+	assert.Equal(t, ProofResult{Proof: []byte{1, 2}, Output: []byte{3}}, decoded)
+
+	// Results of a newer version are rejected.
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"version":2,"proof":"0x0102","output":"0x03"}`), &decoded), "unsupported proof result version 2")
+}
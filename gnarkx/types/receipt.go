@@ -0,0 +1,134 @@
+package types
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Receipt attributes a proof to the operator that produced it. The operator signs the circuit
+// digest, input hash, output hash, proof hash and timestamp, so consumers can check who generated
+// a proof and detect any tampering in transit.
+type Receipt struct {
+	CircuitDigest common.Hash    `json:"circuit_digest"`
+	InputHash     common.Hash    `json:"input_hash"`
+	OutputHash    common.Hash    `json:"output_hash"`
+	ProofHash     common.Hash    `json:"proof_hash"`
+	Timestamp     uint64         `json:"timestamp"`
+	Prover        common.Address `json:"prover"`
+	Signature     hexutil.Bytes  `json:"signature"`
+}
+
+// NewReceipt creates an unsigned receipt for a proof. The proof hash is keccak256(proof).
+func NewReceipt(circuitDigest, inputHash, outputHash common.Hash, proof []byte, timestamp uint64) *Receipt {
+	return &Receipt{
+		CircuitDigest: circuitDigest,
+		InputHash:     inputHash,
+		OutputHash:    outputHash,
+		ProofHash:     crypto.Keccak256Hash(proof),
+		Timestamp:     timestamp,
+	}
+}
+
+// Message returns the signed message, abi.encodePacked(circuitDigest, inputHash, outputHash,
+// proofHash, uint64(timestamp)).
+func (r *Receipt) Message() []byte {
+	msg := make([]byte, 0, 4*32+8)
+	msg = append(msg, r.CircuitDigest.Bytes()...)
+	msg = append(msg, r.InputHash.Bytes()...)
+	msg = append(msg, r.OutputHash.Bytes()...)
+	msg = append(msg, r.ProofHash.Bytes()...)
+	msg = binary.BigEndian.AppendUint64(msg, r.Timestamp)
+	return msg
+}
+
+// Digest returns the EIP-191 hash of the message that is signed, so the signature can also be
+// checked on-chain with ecrecover.
+func (r *Receipt) Digest() common.Hash {
+	return common.BytesToHash(accounts.TextHash(crypto.Keccak256(r.Message())))
+}
+
+// Sign signs the receipt with the prover key and sets the prover address.
+func (r *Receipt) Sign(key *ecdsa.PrivateKey) error {
+	return r.SignWith(context.Background(), NewPrivateKeySigner(key))
+}
+
+// SignWith signs the receipt with the signer, e.g. a KMS key, and sets the prover address.
+func (r *Receipt) SignWith(ctx context.Context, signer Signer) error {
+	signature, err := signer.SignHash(ctx, r.Digest())
+	if err != nil {
+		return fmt.Errorf("failed to sign receipt: %w", err)
+	}
+	r.Prover = signer.Address()
+	r.Signature = signature
+	return nil
+}
+
+// Verify checks that the receipt was signed by its prover.
+func (r *Receipt) Verify() error {
+	if len(r.Signature) != crypto.SignatureLength {
+		return fmt.Errorf("invalid signature length: %d", len(r.Signature))
+	}
+	pubKey, err := crypto.SigToPub(r.Digest().Bytes(), r.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover signer: %w", err)
+	}
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if signer != r.Prover {
+		return fmt.Errorf("receipt signed by %s, expected %s", signer.Hex(), r.Prover.Hex())
+	}
+	return nil
+}
+
+// VerifyProof checks the receipt signature and that it was issued for the given proof.
+func (r *Receipt) VerifyProof(proof []byte) error {
+	if crypto.Keccak256Hash(proof) != r.ProofHash {
+		return fmt.Errorf("%w: proof hash does not match receipt", ErrDigestMismatch)
+	}
+	return r.Verify()
+}
+
+// Export saves the receipt to a file, like the proof next to it: the receipt is written to a
+// temporary file in the same directory, which is synced and renamed to file, so that a crash never
+// leaves a truncated receipt.
+func (r *Receipt) Export(file string) error {
+	jsonReceipt, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal receipt: %w", err)
+	}
+	dir, base := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary receipt: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(jsonReceipt)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set permissions of receipt: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("failed to move receipt into place: %w", err)
+	}
+	return nil
+}
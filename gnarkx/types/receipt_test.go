@@ -0,0 +1,63 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiptSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	proof := []byte{1, 2, 3, 4}
+	receipt := NewReceipt(common.Hash{1}, common.Hash{2}, common.Hash{3}, proof, 1700000000)
+	assert.NoError(t, receipt.Sign(key))
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), receipt.Prover)
+	assert.NoError(t, receipt.VerifyProof(proof))
+
+	// A different proof does not match the receipt.
+	assert.ErrorIs(t, receipt.VerifyProof([]byte{1, 2, 3, 5}), ErrDigestMismatch)
+
+	// Tampering with any signed field invalidates the signature.
+	tampered := *receipt
+	tampered.OutputHash = common.Hash{4}
+	assert.Error(t, tampered.Verify())
+
+	tampered = *receipt
+	tampered.Timestamp++
+	assert.Error(t, tampered.Verify())
+
+	// Claiming another prover invalidates the signature.
+	other, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	tampered = *receipt
+	tampered.Prover = crypto.PubkeyToAddress(other.PublicKey)
+	assert.Error(t, tampered.Verify())
+}
+
+func TestReceiptExport(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	receipt := NewReceipt(common.Hash{1}, common.Hash{2}, common.Hash{3}, []byte{1, 2, 3, 4}, 1700000000)
+	assert.NoError(t, receipt.Sign(key))
+
+	// The receipt replaces the one of a previous proof, and no temporary file is left behind.
+	dir := t.TempDir()
+	file := filepath.Join(dir, "receipt.json")
+	assert.NoError(t, os.WriteFile(file, []byte("{"), 0644))
+	assert.NoError(t, receipt.Export(file))
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	var exported Receipt
+	assert.NoError(t, json.Unmarshal(data, &exported))
+	assert.Equal(t, *receipt, exported)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"context"
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs digests with a secp256k1 key, e.g. a local private key or a key held in a KMS, so
+// operators do not need raw private keys on the prover hosts.
+type Signer interface {
+	// Address returns the address of the key.
+	Address() common.Address
+	// SignHash signs the digest, and returns the signature in the [R || S || V] format of
+	// crypto.Sign, where V is 0 or 1.
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+// PrivateKeySigner signs with a private key held in memory.
+type PrivateKeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{key: key}
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *PrivateKeySigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash.Bytes(), s.key)
+}
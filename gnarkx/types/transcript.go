@@ -0,0 +1,20 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// TranscriptStep is a hash a verifier computes to derive a Fiat-Shamir challenge or the value of a
+// commitment wire. The steps of the verifier in Go and of the one onchain are compared to debug
+// proofs which verify in Go but not onchain.
+type TranscriptStep struct {
+	// The name of the challenge, e.g. gamma, or of the commitment, e.g. commitment[1].
+	Name string `json:"name"`
+	// The domain separation tag, for hashes to the field.
+	DST     string        `json:"dst,omitempty"`
+	Prehash hexutil.Bytes `json:"prehash"`
+	// The hash of the prehash, which challenges are derived from by reducing it.
+	Digest hexutil.Bytes `json:"digest,omitempty"`
+	// The challenge or commitment wire as a field element.
+	Challenge *hexutil.Big `json:"challenge,omitempty"`
+}
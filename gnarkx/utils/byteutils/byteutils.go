@@ -1,6 +1,9 @@
 package byteutils
 
-import "math/big"
+import (
+	"fmt"
+	"math/big"
+)
 
 func ReverseBytes(data []byte) []byte {
 	length := len(data)
@@ -59,3 +62,47 @@ func TruncateBytes32(data [32]byte, nbBits int) [32]byte {
 	out := make([]byte, 32)
 	return [32]byte(result.FillBytes(out))
 }
+
+// The number of bytes packed into a field element by PackBytes.
+const BytesPerElement = 31
+
+// Returns the number of field elements nbBytes bytes are packed into by PackBytes.
+func NbPackedElements(nbBytes int) int {
+	return (nbBytes + BytesPerElement - 1) / BytesPerElement
+}
+
+// Packs the bytes into field elements like builder.API.PackBytes does in a circuit: each element
+// is the big-endian value of 31 consecutive bytes, except for the last one, which is the
+// big-endian value of the remaining bytes.
+func PackBytes(data []byte) []*big.Int {
+	result := make([]*big.Int, 0, NbPackedElements(len(data)))
+	for start := 0; start < len(data); start += BytesPerElement {
+		end := start + BytesPerElement
+		if end > len(data) {
+			end = len(data)
+		}
+		result = append(result, new(big.Int).SetBytes(data[start:end]))
+	}
+	return result
+}
+
+// Unpacks nbBytes bytes from field elements packed by PackBytes, and fails if the elements are
+// not a packing of nbBytes bytes.
+func UnpackBytes(elements []*big.Int, nbBytes int) ([]byte, error) {
+	if nbElements := NbPackedElements(nbBytes); len(elements) != nbElements {
+		return nil, fmt.Errorf("%d bytes are packed into %d elements, not %d", nbBytes, nbElements, len(elements))
+	}
+	result := make([]byte, nbBytes)
+	for i, element := range elements {
+		start := i * BytesPerElement
+		end := start + BytesPerElement
+		if end > nbBytes {
+			end = nbBytes
+		}
+		if element.Sign() < 0 || element.BitLen() > 8*(end-start) {
+			return nil, fmt.Errorf("element %d does not fit into %d bytes", i, end-start)
+		}
+		element.FillBytes(result[start:end])
+	}
+	return result, nil
+}
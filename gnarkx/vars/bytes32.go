@@ -0,0 +1,53 @@
+package vars
+
+import "fmt"
+
+// A variable in a circuit representing a bytes32, e.g. a hash or a storage slot, with its bytes in
+// big-endian order like in Solidity. Since it is a [32]Byte, it is interchangeable with the bytes32
+// taken and returned by the gadgets.
+type Bytes32 [32]Byte
+
+// Sets the bytes32 from a bytes32 value, e.g. a common.Hash.
+func (b *Bytes32) Set(i1 [32]byte) {
+	SetBytes32((*[32]Byte)(b), i1)
+}
+
+// Returns the bytes32 of a slice of 32 bytes.
+func ToBytes32(b []Byte) Bytes32 {
+	if len(b) != 32 {
+		panic(fmt.Sprintf("expected 32 bytes, got %d", len(b)))
+	}
+	var result Bytes32
+	copy(result[:], b)
+	return result
+}
+
+// A variable in a circuit representing an Ethereum address of 20 bytes. Since it is a [20]Byte,
+// it is interchangeable with the addresses taken and returned by the gadgets.
+type Address [20]Byte
+
+// Creates a new address as a variable in a circuit.
+func NewAddress() Address {
+	var result Address
+	for i := range result {
+		result[i] = ZERO_BYTE
+	}
+	return result
+}
+
+// Sets the address from an address value, e.g. a common.Address.
+func (a *Address) Set(i1 [20]byte) {
+	for i := range a {
+		a[i].Set(i1[i])
+	}
+}
+
+// Returns the address of a slice of 20 bytes.
+func ToAddress(b []Byte) Address {
+	if len(b) != 20 {
+		panic(fmt.Sprintf("expected 20 bytes, got %d", len(b)))
+	}
+	var result Address
+	copy(result[:], b)
+	return result
+}
@@ -0,0 +1,34 @@
+package vars
+
+import (
+	"math/big"
+)
+
+// A variable in a circuit representing a u128. Under the hood, the value is two u64 limbs in
+// little-endian order, i.e. the value is Limbs[0] + 2^64 * Limbs[1].
+type U128 struct {
+	Limbs [2]U64
+}
+
+// Creates a new u128 as a variable in a circuit.
+func NewU128() U128 {
+	return U128{Limbs: [2]U64{NewU64(), NewU64()}}
+}
+
+// Sets the u128 from a big integer, which must be in [0, 2^128).
+func (u *U128) Set(i1 *big.Int) {
+	setLimbs(u.Limbs[:], i1)
+}
+
+// setLimbs sets little-endian u64 limbs from a big integer.
+func setLimbs(limbs []U64, i1 *big.Int) {
+	if i1.Sign() < 0 || i1.BitLen() > 64*len(limbs) {
+		panic("value does not fit in the limbs")
+	}
+	mask := new(big.Int).SetUint64(^uint64(0))
+	value := new(big.Int).Set(i1)
+	for i := range limbs {
+		limbs[i] = U64{Value: Variable{Value: new(big.Int).And(value, mask)}}
+		value.Rsh(value, 64)
+	}
+}
@@ -0,0 +1,21 @@
+package vars
+
+import (
+	"math/big"
+)
+
+// A variable in a circuit representing a u256. Under the hood, the value is four u64 limbs in
+// little-endian order, i.e. the value is Limbs[0] + 2^64 * Limbs[1] + ... + 2^192 * Limbs[3].
+type U256 struct {
+	Limbs [4]U64
+}
+
+// Creates a new u256 as a variable in a circuit.
+func NewU256() U256 {
+	return U256{Limbs: [4]U64{NewU64(), NewU64(), NewU64(), NewU64()}}
+}
+
+// Sets the u256 from a big integer, which must be in [0, 2^256).
+func (u *U256) Set(i1 *big.Int) {
+	setLimbs(u.Limbs[:], i1)
+}
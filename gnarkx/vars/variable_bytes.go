@@ -0,0 +1,42 @@
+package vars
+
+// A variable in a circuit representing a byte array of variable length. The maximum length is
+// len(Data) and is a constant at compile time of the circuit, while Length is a variable in
+// [0, len(Data)]. The bytes of Data after Length are zero, which the APIs operating on variable
+// bytes rely on and preserve.
+type VariableBytes struct {
+	Data   []Byte
+	Length Variable
+}
+
+// Creates a new empty variable byte array with the given maximum length.
+func NewVariableBytes(maxLength int) VariableBytes {
+	return VariableBytes{Data: NewBytes(maxLength), Length: ZERO}
+}
+
+// Creates a new variable byte array with the given maximum length from bytes.
+func NewVariableBytesFrom(bytes []byte, maxLength int) VariableBytes {
+	b := NewVariableBytes(maxLength)
+	b.Set(bytes)
+	return b
+}
+
+// Sets the variable byte array from bytes, which are padded with zeros to the maximum length.
+func (b *VariableBytes) Set(bytes []byte) {
+	if len(bytes) > len(b.Data) {
+		panic("length of bytes exceeds the maximum length")
+	}
+	for i := range b.Data {
+		if i < len(bytes) {
+			b.Data[i].Set(bytes[i])
+		} else {
+			b.Data[i].Set(0)
+		}
+	}
+	b.Length = NewVariableFromInt(len(bytes))
+}
+
+// Returns the maximum length of the variable byte array.
+func (b *VariableBytes) MaxLength() int {
+	return len(b.Data)
+}
@@ -0,0 +1,83 @@
+package witness
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+// Encodes the input bytes of a circuit function from a JSON document, which has a value for every
+// input of the schema.
+func EncodeInputsJSON(schema *builder.Schema, data []byte) ([]byte, error) {
+	document, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeInputs(schema, document)
+}
+
+// Encodes the input bytes of a circuit function from a YAML document, which has a value for every
+// input of the schema.
+func EncodeInputsYAML(schema *builder.Schema, data []byte) ([]byte, error) {
+	document, err := decodeYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeInputs(schema, document)
+}
+
+// Encodes the input bytes of a circuit function from a decoded document, which has a value for
+// every input of the schema. The inputs are looked up by their name, ignoring the case, and are
+// decoded like the variables of Load, i.e. a uint64 takes an integer and a bytes32 takes a
+// 0x-prefixed hexadecimal string.
+func EncodeInputs(schema *builder.Schema, document map[string]any) ([]byte, error) {
+	out := make([]byte, schema.InputSize())
+	matched := make(map[string]bool)
+	for _, field := range schema.Inputs {
+		var value any
+		found := false
+		for key := range document {
+			if strings.EqualFold(key, field.Name) {
+				value, found = document[key], true
+				matched[key] = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: missing input", field.Name)
+		}
+		b, err := encodeField(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.Name, err)
+		}
+		copy(out[field.Offset:], b)
+	}
+	for key := range document {
+		if !matched[key] {
+			return nil, fmt.Errorf("%s: no such input in the schema", key)
+		}
+	}
+	return out, nil
+}
+
+// Encodes the value as the bytes of the field.
+func encodeField(field builder.SchemaField, value any) ([]byte, error) {
+	switch field.Type {
+	case builder.Uint64Field:
+		i, err := toInt(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return i.FillBytes(make([]byte, field.Width)), nil
+	case builder.Bytes32Field:
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != field.Width {
+			return nil, fmt.Errorf("expected %d bytes, got %d", field.Width, len(b))
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", field.Type)
+}
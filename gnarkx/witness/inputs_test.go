@@ -0,0 +1,41 @@
+package witness
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+)
+
+var testSchema = &builder.Schema{
+	Inputs: []builder.SchemaField{
+		{Name: "root", Type: builder.Bytes32Field, Offset: 0, Width: 32},
+		{Name: "number", Type: builder.Uint64Field, Offset: 32, Width: 8},
+	},
+}
+
+func TestEncodeInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	expected := hexutil.MustDecode("0xab000000000000000000000000000000000000000000000000000000000000cd000000000012d687")
+
+	input, err := EncodeInputsJSON(testSchema, []byte(`{"root": "0xab000000000000000000000000000000000000000000000000000000000000cd", "Number": 1234567}`))
+	assert.NoError(err)
+	assert.Equal(expected, input)
+
+	input, err = EncodeInputsYAML(testSchema, []byte("root: \"0xab000000000000000000000000000000000000000000000000000000000000cd\"\nnumber: \"0x12d687\"\n"))
+	assert.NoError(err)
+	assert.Equal(expected, input)
+
+	// Documents which do not match the schema are rejected.
+	for _, document := range []string{
+		`{"number": 1}`,
+		`{"root": "0xab", "number": 1}`,
+		`{"root": "0xab000000000000000000000000000000000000000000000000000000000000cd", "number": -1}`,
+		`{"root": "0xab000000000000000000000000000000000000000000000000000000000000cd", "number": 1, "other": 2}`,
+	} {
+		_, err := EncodeInputsJSON(testSchema, []byte(document))
+		assert.Error(err, document)
+	}
+}
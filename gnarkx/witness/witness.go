@@ -0,0 +1,281 @@
+// The loader for assigning the variables of a circuit from a JSON or YAML document, so that
+// witnesses can be produced without writing Go code for every circuit.
+//
+// The document is matched against the circuit by reflection. Every exported field of the circuit
+// is looked up in the document by its json tag or, if it has none, by its name, ignoring the case.
+// Fields which are missing from the document are left unchanged, while keys of the document which
+// do not match any field are an error. The values are decoded by the type of the field:
+//
+//   - vars.Variable, vars.Byte, vars.U32, vars.U64, vars.U128 and vars.U256 take integers, given as
+//     numbers or as decimal or 0x-prefixed hexadecimal strings.
+//   - vars.Bool takes a boolean or the integers 0 and 1.
+//   - Arrays and slices of vars.Byte, e.g. a bytes32, take 0x-prefixed hexadecimal strings of
+//     exactly their length, and vars.VariableBytes takes one of at most its maximum length.
+//   - Other arrays and slices take lists of their length, and structs take nested documents.
+//
+// Slices which are nil are allocated with the length of the value, while slices which are already
+// allocated, e.g. because their length is fixed by the circuit, must match it.
+package witness
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+	"gopkg.in/yaml.v3"
+)
+
+// Assigns the variables of the circuit, which must be a pointer to a struct, from a JSON document.
+func LoadJSON(circuit any, data []byte) error {
+	document, err := decodeJSON(data)
+	if err != nil {
+		return err
+	}
+	return Load(circuit, document)
+}
+
+// Assigns the variables of the circuit, which must be a pointer to a struct, from a YAML document.
+func LoadYAML(circuit any, data []byte) error {
+	document, err := decodeYAML(data)
+	if err != nil {
+		return err
+	}
+	return Load(circuit, document)
+}
+
+// Decodes a JSON document, keeping its numbers exact.
+func decodeJSON(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return document, nil
+}
+
+// Decodes a YAML document.
+func decodeYAML(data []byte) (map[string]any, error) {
+	var document map[string]any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode yaml: %w", err)
+	}
+	return document, nil
+}
+
+// Assigns the variables of the circuit, which must be a pointer to a struct, from a decoded
+// document.
+func Load(circuit any, document map[string]any) error {
+	v := reflect.ValueOf(circuit)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("circuit must be a pointer to a struct, got %T", circuit)
+	}
+	return assign(v.Elem(), document, "")
+}
+
+var (
+	variableType      = reflect.TypeOf(vars.Variable{})
+	boolType          = reflect.TypeOf(vars.Bool{})
+	byteType          = reflect.TypeOf(vars.Byte{})
+	u32Type           = reflect.TypeOf(vars.U32{})
+	u64Type           = reflect.TypeOf(vars.U64{})
+	u128Type          = reflect.TypeOf(vars.U128{})
+	u256Type          = reflect.TypeOf(vars.U256{})
+	variableBytesType = reflect.TypeOf(vars.VariableBytes{})
+)
+
+// Assigns the value to v, where path is the location of the value in the document for errors.
+func assign(v reflect.Value, value any, path string) error {
+	switch v.Type() {
+	case variableType:
+		i, err := toInt(value, -1)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.Set(reflect.ValueOf(vars.Variable{Value: i}))
+		return nil
+	case boolType:
+		b, ok := value.(bool)
+		if !ok {
+			i, err := toInt(value, 1)
+			if err != nil {
+				return fmt.Errorf("%s: expected a boolean: %w", path, err)
+			}
+			b = i.Sign() != 0
+		}
+		v.Set(reflect.ValueOf(vars.NewBool(b)))
+		return nil
+	case byteType, u32Type, u64Type:
+		nbBits := map[reflect.Type]int{byteType: 8, u32Type: 32, u64Type: 64}[v.Type()]
+		i, err := toInt(value, nbBits)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.Field(0).Set(reflect.ValueOf(vars.Variable{Value: i}))
+		return nil
+	case u128Type:
+		i, err := toInt(value, 128)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		u := vars.NewU128()
+		u.Set(i)
+		v.Set(reflect.ValueOf(u))
+		return nil
+	case u256Type:
+		i, err := toInt(value, 256)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		u := vars.NewU256()
+		u.Set(i)
+		v.Set(reflect.ValueOf(u))
+		return nil
+	case variableBytesType:
+		b, err := toBytes(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		variableBytes := v.Addr().Interface().(*vars.VariableBytes)
+		if len(b) > variableBytes.MaxLength() {
+			return fmt.Errorf("%s: %d bytes exceed the maximum length of %d", path, len(b), variableBytes.MaxLength())
+		}
+		variableBytes.Set(b)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if s, ok := value.(string); ok && v.Type().Elem() == byteType {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			return assignList(v, len(b), func(i int) any { return uint64(b[i]) }, path)
+		}
+		list, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected a list, got %T", path, value)
+		}
+		return assignList(v, len(list), func(i int) any { return list[i] }, path)
+	case reflect.Struct:
+		document, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", path, value)
+		}
+		return assignStruct(v, document, path)
+	}
+	return fmt.Errorf("%s: unsupported type %s", path, v.Type())
+}
+
+// Assigns the n elements given by value to the array or slice.
+func assignList(v reflect.Value, n int, value func(i int) any, path string) error {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	}
+	if v.Len() != n {
+		return fmt.Errorf("%s: expected %d elements, got %d", path, v.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if err := assign(v.Index(i), value(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Assigns the exported fields of the struct from the document.
+func assignStruct(v reflect.Value, document map[string]any, path string) error {
+	matched := make(map[string]bool)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("gnark") == "-" {
+			continue
+		}
+		name := fieldName(field)
+		for key, value := range document {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			matched[key] = true
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if err := assign(v.Field(i), value, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	for key := range document {
+		if !matched[key] {
+			if path != "" {
+				key = path + "." + key
+			}
+			return fmt.Errorf("%s: no such variable in the circuit", key)
+		}
+	}
+	return nil
+}
+
+// Returns the name of the field in a document, which is its json tag if it has one.
+func fieldName(field reflect.StructField) string {
+	if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" {
+		return tag
+	}
+	return field.Name
+}
+
+// Returns the value as a non-negative integer of at most nbBits bits, or as any integer if nbBits
+// is negative.
+func toInt(value any, nbBits int) (*big.Int, error) {
+	i := new(big.Int)
+	switch value := value.(type) {
+	case json.Number:
+		if _, ok := i.SetString(value.String(), 10); !ok {
+			return nil, fmt.Errorf("expected an integer, got %s", value)
+		}
+	case string:
+		var ok bool
+		if strings.HasPrefix(value, "0x") {
+			_, ok = i.SetString(value[2:], 16)
+		} else {
+			_, ok = i.SetString(value, 10)
+		}
+		if !ok {
+			return nil, fmt.Errorf("expected an integer, got %q", value)
+		}
+	case int:
+		i.SetInt64(int64(value))
+	case int64:
+		i.SetInt64(value)
+	case uint64:
+		i.SetUint64(value)
+	case float64:
+		if value != math.Trunc(value) || math.Abs(value) > 1<<53 {
+			return nil, fmt.Errorf("expected an integer, got %v", value)
+		}
+		i.SetInt64(int64(value))
+	default:
+		return nil, fmt.Errorf("expected an integer, got %T", value)
+	}
+	if nbBits >= 0 && (i.Sign() < 0 || i.BitLen() > nbBits) {
+		return nil, fmt.Errorf("%s does not fit into %d bits", i, nbBits)
+	}
+	return i, nil
+}
+
+// Returns the value as bytes, which must be a 0x-prefixed hexadecimal string.
+func toBytes(value any) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a hex string, got %T", value)
+	}
+	return hexutil.Decode(s)
+}
@@ -0,0 +1,99 @@
+package witness
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+type TestPair struct {
+	Key   vars.U64
+	Value vars.Bool
+}
+
+type TestLoadCircuit struct {
+	Root    [32]vars.Byte `json:"root"`
+	Number  vars.U64
+	Balance vars.U256
+	Flag    vars.Bool
+	Pairs   []TestPair
+	Data    vars.VariableBytes
+	Scalar  vars.Variable `gnark:",public"`
+	Ignored int           `gnark:"-"`
+}
+
+func (circuit *TestLoadCircuit) Define(api frontend.API) error {
+	succinctAPI := builder.NewAPI(api)
+	succinctAPI.AssertIsEqualByte(circuit.Root[0], vars.Byte{Value: vars.NewVariableFromInt(0xab)})
+	succinctAPI.AssertIsEqualByte(circuit.Root[31], vars.Byte{Value: vars.NewVariableFromInt(0xcd)})
+	succinctAPI.AssertIsEqualU64(circuit.Number, vars.U64{Value: vars.NewVariableFromInt(1234567)})
+	balance := vars.NewU256()
+	balance.Set(new(big.Int).Lsh(big.NewInt(1), 200))
+	succinctAPI.AssertIsEqualU256(circuit.Balance, balance)
+	succinctAPI.AssertIsEqualBool(circuit.Flag, vars.TRUE)
+	succinctAPI.AssertIsEqualU64(circuit.Pairs[1].Key, vars.U64{Value: vars.NewVariableFromInt(7)})
+	succinctAPI.AssertIsEqualBool(circuit.Pairs[1].Value, vars.TRUE)
+	succinctAPI.AssertIsEqual(circuit.Data.Length, vars.NewVariableFromInt(3))
+	succinctAPI.AssertIsEqualByte(circuit.Data.Data[2], vars.Byte{Value: vars.NewVariableFromInt(3)})
+	succinctAPI.AssertIsEqual(circuit.Scalar, vars.NewVariableFromInt(42))
+	return nil
+}
+
+func newTestCircuit() *TestLoadCircuit {
+	return &TestLoadCircuit{Pairs: make([]TestPair, 2), Data: vars.NewVariableBytes(8)}
+}
+
+const testJSON = `{
+	"root": "0xab000000000000000000000000000000000000000000000000000000000000cd",
+	"number": 1234567,
+	"balance": "0x100000000000000000000000000000000000000000000000000",
+	"flag": true,
+	"pairs": [{"key": "3", "value": 0}, {"key": 7, "value": true}],
+	"data": "0x010203",
+	"scalar": "42"
+}`
+
+const testYAML = `
+root: "0xab000000000000000000000000000000000000000000000000000000000000cd"
+number: 1234567
+balance: "1606938044258990275541962092341162602522202993782792835301376"
+flag: true
+pairs:
+  - key: 3
+    value: false
+  - key: 7
+    value: 1
+data: "0x010203"
+scalar: 42
+`
+
+func TestLoad(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := newTestCircuit()
+	assert.NoError(LoadJSON(circuit, []byte(testJSON)))
+	assert.NoError(test.IsSolved(newTestCircuit(), circuit, ecc.BN254.ScalarField()))
+
+	circuit = newTestCircuit()
+	assert.NoError(LoadYAML(circuit, []byte(testYAML)))
+	assert.NoError(test.IsSolved(newTestCircuit(), circuit, ecc.BN254.ScalarField()))
+
+	// Documents which do not match the circuit are rejected.
+	for _, document := range []string{
+		`{"unknown": 1}`,
+		`{"number": "18446744073709551616"}`,
+		`{"number": 1.5}`,
+		`{"root": "0xab"}`,
+		`{"pairs": [{"key": 1}]}`,
+		`{"pairs": [{"key": 1, "other": 2}, {}]}`,
+		`{"data": "0x000102030405060708"}`,
+		`{"flag": 2}`,
+	} {
+		assert.Error(LoadJSON(newTestCircuit(), []byte(document)), document)
+	}
+}
@@ -147,7 +147,7 @@ impl<C: Circuit> Plonky2xFunction for C {
                 let child_process = std::process::Command::new(
                     path::Path::new(&args.wrapper_path).join("verifier"),
                 )
-                .arg("-prove")
+                .arg("prove")
                 .arg("-data")
                 .arg(path::Path::new(&args.wrapper_path))
                 .stdout(std::process::Stdio::inherit())
@@ -285,6 +285,17 @@ impl<C: Circuit> Plonky2xFunction for C {
     }
 
     fn verifier(circuit_digest: &str, wrapper_path: &str) -> String {
+        // If the wrapper was compiled with -fixed-digest, the circuit digest is a constant of the
+        // wrapper circuit and the FunctionVerifier was generated along with it.
+        let fixed_verifier_path = format!("{}/FixedFunctionVerifier.sol", wrapper_path);
+        if let Ok(fixed_verifier_contract) = fs::read_to_string(fixed_verifier_path) {
+            assert!(
+                fixed_verifier_contract.contains(circuit_digest),
+                "the wrapper was compiled for a different circuit"
+            );
+            return fixed_verifier_contract;
+        }
+
         let wrapper_verifier_path = format!("{}/Verifier.sol", wrapper_path);
         let wrapper_verifier_contract = fs::read_to_string(wrapper_verifier_path)
             .expect("Failed to read wrapper_verifier_path");
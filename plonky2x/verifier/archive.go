@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// The statuses of the proofs in a ProofArchive.
+const (
+	ProofStatusProven = "proven"
+	ProofStatusFailed = "failed"
+)
+
+// ErrProofNotArchived is returned by ProofArchive.Get for requests which are not in the archive.
+var ErrProofNotArchived = errors.New("proof not in archive")
+
+// The key prefixes of the archive. Records are stored by request ID, and indexed by input hash.
+const (
+	archiveRequestPrefix = "request/"
+	archiveInputPrefix   = "input/"
+)
+
+// ProofRecord is the entry of a proving request in a ProofArchive.
+type ProofRecord struct {
+	// The ID of the request, e.g. the name of a drop of the directory watcher, or the circuit path
+	// of the prove command.
+	RequestID string `json:"request_id"`
+	// The input hash of the proof, which is empty if the plonky2 proof could not be read.
+	InputHash string `json:"input_hash,omitempty"`
+	Status    string `json:"status"`
+	// The directory the artifacts of the proof were saved to, and their file names.
+	Dir   string   `json:"dir,omitempty"`
+	Files []string `json:"files,omitempty"`
+	// The error of a failed request.
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProofArchive is a local index of the produced proofs, so operators can tell whether an input was
+// already proven and where its artifacts are without searching the output directories. It is
+// stored in a LevelDB database, which can only be opened by a single process at a time.
+type ProofArchive struct {
+	db *leveldb.DB
+}
+
+// OpenProofArchive opens the archive at path, creating it if it does not exist.
+func OpenProofArchive(path string) (*ProofArchive, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open proof archive: %w", err)
+	}
+	return &ProofArchive{db: db}, nil
+}
+
+func (a *ProofArchive) Close() error {
+	return a.db.Close()
+}
+
+// Put records the request, replacing a previous record of the same request ID.
+func (a *ProofArchive) Put(record ProofRecord) error {
+	if record.RequestID == "" {
+		return errors.New("proof record has no request id")
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal proof record: %w", err)
+	}
+
+	batch := new(leveldb.Batch)
+	previous, err := a.Get(record.RequestID)
+	if err == nil && previous.InputHash != record.InputHash {
+		batch.Delete(archiveInputKey(previous.InputHash, record.RequestID))
+	} else if err != nil && !errors.Is(err, ErrProofNotArchived) {
+		return err
+	}
+	batch.Put([]byte(archiveRequestPrefix+record.RequestID), data)
+	if record.InputHash != "" {
+		batch.Put(archiveInputKey(record.InputHash, record.RequestID), []byte(record.RequestID))
+	}
+	if err := a.db.Write(batch, nil); err != nil {
+		return fmt.Errorf("failed to write proof record: %w", err)
+	}
+	return nil
+}
+
+// Get returns the record of the request, or ErrProofNotArchived.
+func (a *ProofArchive) Get(requestID string) (*ProofRecord, error) {
+	data, err := a.db.Get([]byte(archiveRequestPrefix+requestID), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrProofNotArchived, requestID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof record: %w", err)
+	}
+	var record ProofRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse proof record: %w", serializationError(err))
+	}
+	return &record, nil
+}
+
+// FindByInputHash returns the records of the requests with the input hash, most recent first.
+func (a *ProofArchive) FindByInputHash(inputHash common.Hash) ([]ProofRecord, error) {
+	var records []ProofRecord
+	iter := a.db.NewIterator(util.BytesPrefix(archiveInputKey(inputHash.Hex(), "")), nil)
+	defer iter.Release()
+	for iter.Next() {
+		record, err := a.Get(string(iter.Value()))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read proof archive: %w", err)
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+// List returns the records with the status, or all records if status is empty, most recent first.
+func (a *ProofArchive) List(status string) ([]ProofRecord, error) {
+	var records []ProofRecord
+	iter := a.db.NewIterator(util.BytesPrefix([]byte(archiveRequestPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var record ProofRecord
+		if err := json.Unmarshal(iter.Value(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse proof record: %w", serializationError(err))
+		}
+		if status == "" || record.Status == status {
+			records = append(records, record)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read proof archive: %w", err)
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+// Lookup returns the records of the key, which is either an input hash or a request ID.
+func (a *ProofArchive) Lookup(key string) ([]ProofRecord, error) {
+	if strings.HasPrefix(key, "0x") && len(key) == 2+2*common.HashLength {
+		records, err := a.FindByInputHash(common.HexToHash(key))
+		if err != nil || len(records) > 0 {
+			return records, err
+		}
+	}
+	record, err := a.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return []ProofRecord{*record}, nil
+}
+
+// hashHex returns the hex of the input hash, or an empty string if it is unknown.
+func hashHex(inputHash common.Hash) string {
+	if inputHash == (common.Hash{}) {
+		return ""
+	}
+	return inputHash.Hex()
+}
+
+func archiveInputKey(inputHash string, requestID string) []byte {
+	return []byte(archiveInputPrefix + inputHash + "/" + requestID)
+}
+
+func sortRecords(records []ProofRecord) {
+	sort.SliceStable(records, func(i, j int) bool { return records[i].UpdatedAt.After(records[j].UpdatedAt) })
+}
+
+// publicInputHash returns the input hash in the public witness of the verifier circuit.
+func publicInputHash(publicWitness witness.Witness) (common.Hash, error) {
+	labeled, err := LabelPublicWitness(publicWitness)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	for _, input := range labeled {
+		if input.Name == "inputHash" {
+			return common.BigToHash(input.Value.ToInt()), nil
+		}
+	}
+	return common.Hash{}, errors.New("public witness has no input hash")
+}
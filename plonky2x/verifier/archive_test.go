@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive")
+	archive, err := OpenProofArchive(path)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	hash := common.Hash{1}
+	assert.NoError(t, archive.Put(ProofRecord{RequestID: "a", InputHash: hash.Hex(), Status: ProofStatusProven, Dir: "/proofs/a", Files: proofFiles, UpdatedAt: start}))
+	assert.NoError(t, archive.Put(ProofRecord{RequestID: "b", InputHash: hash.Hex(), Status: ProofStatusFailed, Error: "bad proof", UpdatedAt: start.Add(time.Second)}))
+	assert.NoError(t, archive.Put(ProofRecord{RequestID: "c", Status: ProofStatusFailed, UpdatedAt: start.Add(2 * time.Second)}))
+	assert.Error(t, archive.Put(ProofRecord{Status: ProofStatusProven}))
+
+	// Both requests of the input hash are found, most recent first.
+	records, err := archive.Lookup(hash.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, requestIDs(records))
+	assert.Equal(t, "/proofs/a", records[1].Dir)
+
+	records, err = archive.List(ProofStatusFailed)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "b"}, requestIDs(records))
+
+	// A request proven again replaces its record, and is no longer indexed by its previous hash.
+	assert.NoError(t, archive.Put(ProofRecord{RequestID: "b", InputHash: common.Hash{2}.Hex(), Status: ProofStatusProven}))
+	records, err = archive.FindByInputHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, requestIDs(records))
+
+	// The records persist across reopening the archive.
+	assert.NoError(t, archive.Close())
+	archive, err = OpenProofArchive(path)
+	assert.NoError(t, err)
+	defer archive.Close()
+	record, err := archive.Get("b")
+	assert.NoError(t, err)
+	assert.Equal(t, ProofStatusProven, record.Status)
+	_, err = archive.Lookup(common.Hash{3}.Hex())
+	assert.ErrorIs(t, err, ErrProofNotArchived)
+}
+
+func TestPublicInputHash(t *testing.T) {
+	hash, err := publicInputHash(newTestPublicWitness(t, 1, 255, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(255)), hash)
+}
+
+func requestIDs(records []ProofRecord) []string {
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.RequestID
+	}
+	return ids
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DataDog/zstd"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// Compression is the format the large artifacts of a data directory, i.e. the constraint system
+// and the proving key, are written in. Compressed artifacts are distributed faster, and are
+// decompressed on the fly while they are loaded.
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// The file name extensions of the compression formats.
+var compressionExtensions = map[Compression]string{
+	CompressionNone: "",
+	CompressionGzip: ".gz",
+	CompressionZstd: ".zst",
+}
+
+// ParseCompression parses the name of a compression format, where "none" and "" are no compression.
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case string(CompressionGzip), string(CompressionZstd):
+		return Compression(name), nil
+	}
+	return CompressionNone, fmt.Errorf("unknown compression %q, expected none, gzip or zstd", name)
+}
+
+// The magic bytes which start a gzip and a zstd stream.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// artifactReader reads an artifact of a data directory, which is decompressed on the fly if it has
+// been compressed.
+type artifactReader struct {
+	io.Reader
+	file         *os.File
+	decompressor io.Closer
+	compression  Compression
+}
+
+// artifactPath returns the path of the artifact with the name in the directory, i.e. of the plain
+// file or of its compressed variants, in this order.
+func artifactPath(dir string, name string) (string, error) {
+	for _, compression := range []Compression{CompressionNone, CompressionZstd, CompressionGzip} {
+		path := dir + "/" + name + compressionExtensions[compression]
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s not found in %s", types.ErrArtifactMissing, name, dir)
+}
+
+// openArtifact opens the file for buffered reading, and detects a compressed file by its magic
+// bytes rather than its name, so a compressed artifact can also replace the plain one in place.
+func openArtifact(path string) (*artifactReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReaderSize(file, 1<<20)
+	magic, err := reader.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		file.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		return &artifactReader{Reader: bufio.NewReaderSize(gzipReader, 1<<20), file: file, decompressor: gzipReader, compression: CompressionGzip}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zstdReader := zstd.NewReader(reader)
+		return &artifactReader{Reader: bufio.NewReaderSize(zstdReader, 1<<20), file: file, decompressor: zstdReader, compression: CompressionZstd}, nil
+	}
+	return &artifactReader{Reader: reader, file: file}, nil
+}
+
+func (r *artifactReader) Close() error {
+	if r.decompressor != nil {
+		r.decompressor.Close()
+	}
+	return r.file.Close()
+}
+
+// artifactWriter writes an artifact of a data directory in a compression format.
+type artifactWriter struct {
+	*bufio.Writer
+	file       *os.File
+	compressor io.WriteCloser
+}
+
+// createArtifact creates the artifact with the name in the directory, where the name is extended
+// by the extension of the compression format. Other variants of the artifact are removed, since
+// the plain file would take precedence over the new one when loading it.
+func createArtifact(dir string, name string, compression Compression) (*artifactWriter, error) {
+	extension, ok := compressionExtensions[compression]
+	if !ok {
+		return nil, fmt.Errorf("unknown compression %q", compression)
+	}
+	for other, otherExtension := range compressionExtensions {
+		if other == compression {
+			continue
+		}
+		if err := os.Remove(dir + "/" + name + otherExtension); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale %s: %w", name+otherExtension, err)
+		}
+	}
+	file, err := os.Create(dir + "/" + name + extension)
+	if err != nil {
+		return nil, err
+	}
+	w := &artifactWriter{file: file}
+	switch compression {
+	case CompressionGzip:
+		w.compressor = gzip.NewWriter(file)
+	case CompressionZstd:
+		w.compressor = zstd.NewWriterLevel(file, zstd.DefaultCompression)
+	}
+	if w.compressor != nil {
+		w.Writer = bufio.NewWriterSize(w.compressor, 1<<20)
+	} else {
+		w.Writer = bufio.NewWriterSize(file, 1<<20)
+	}
+	return w, nil
+}
+
+// Close flushes the artifact and closes its file.
+func (w *artifactWriter) Close() error {
+	err := w.Flush()
+	if w.compressor != nil {
+		if closeErr := w.compressor.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"compress/gzip"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipFile(t *testing.T, path string) {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	writer := gzip.NewWriter(file)
+	_, err = writer.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, file.Close())
+}
+
+func TestLoadGzippedArtifacts(t *testing.T) {
+	path := saveTestCircuit(t, CompressionNone)
+	r1cs, pk, err := LoadProverData(path, false)
+	assert.NoError(t, err)
+	vk, err := LoadVerifierKey(path, false)
+	assert.NoError(t, err)
+
+	// Gzipped artifacts are decompressed while they are read, and keep their checksums.
+	for _, file := range checksummedArtifacts {
+		gzipFile(t, path+"/"+file)
+	}
+	gzippedR1CS, gzippedPK, err := LoadProverData(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, r1cs.GetNbConstraints(), gzippedR1CS.GetNbConstraints())
+	assert.Equal(t, pk, gzippedPK)
+	gzippedVK, err := LoadVerifierKey(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, vk, gzippedVK)
+
+	// An error loading either file is reported once both have been read.
+	assert.NoError(t, os.Remove(path+"/r1cs.bin"))
+	_, _, err = LoadProverData(path, false)
+	assert.ErrorContains(t, err, "failed to read r1cs file")
+}
+
+func TestSaveCompressedArtifacts(t *testing.T) {
+	path := saveTestCircuit(t, CompressionNone)
+	r1cs, pk, err := LoadProverData(path, false)
+	assert.NoError(t, err)
+
+	for _, compression := range []Compression{CompressionZstd, CompressionGzip} {
+		compressedPath := saveTestCircuit(t, compression)
+		extension := compressionExtensions[compression]
+		assert.FileExists(t, compressedPath+"/pk.bin"+extension)
+		assert.NoFileExists(t, compressedPath+"/pk.bin")
+		plain, err := os.Stat(path + "/pk.bin")
+		assert.NoError(t, err)
+		compressed, err := os.Stat(compressedPath + "/pk.bin" + extension)
+		assert.NoError(t, err)
+		assert.Less(t, compressed.Size(), plain.Size())
+
+		compressedR1CS, compressedPK, err := LoadProverData(compressedPath, true)
+		assert.NoError(t, err)
+		assert.Equal(t, r1cs.GetNbConstraints(), compressedR1CS.GetNbConstraints())
+		assert.Equal(t, pk, compressedPK)
+	}
+
+	_, err = ParseCompression("brotli")
+	assert.Error(t, err)
+}
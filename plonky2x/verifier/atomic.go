@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrOutputExists is returned when saving a proof would overwrite the output of a previous one and
+// overwriting is disabled.
+var ErrOutputExists = errors.New("output file already exists")
+
+// The files SaveProofTo writes to its directory.
+var proofFiles = []string{"proof.json", "proof_with_witness.json", "public_witness.bin", "public_witness.json"}
+
+// checkOutputsAbsent returns ErrOutputExists if any of the files exists in dir.
+func checkOutputsAbsent(dir string, files ...string) error {
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		_, err := os.Stat(path)
+		if err == nil {
+			return fmt.Errorf("%w: %s", ErrOutputExists, path)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to check %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// prepareOutputs checks that the outputs of a previous proof in dir may be replaced. The receipt of
+// a previous proof is also an output of it, even if no receipt is saved with the new proof, so
+// without overwrite it must not exist, and otherwise it is removed, as it would be taken for a
+// receipt of the new proof.
+func prepareOutputs(dir string, overwrite bool, withReceipt bool) error {
+	files := append(proofFiles[:len(proofFiles):len(proofFiles)], "receipt.json")
+	if !overwrite {
+		return checkOutputsAbsent(dir, files...)
+	}
+	if withReceipt {
+		return nil
+	}
+	err := os.Remove(filepath.Join(dir, "receipt.json"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove the receipt of a previous proof: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes the file with write, so that readers of path see either its previous
+// contents or the complete new ones, even if the process crashes. The contents are written to a
+// temporary file in the same directory, which is synced to disk and then renamed to path. Without
+// overwrite, the file is linked to path instead, which fails with ErrOutputExists if path exists.
+func writeFileAtomic(path string, overwrite bool, write func(w io.Writer) error) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+
+	if overwrite {
+		err = os.Rename(tmp.Name(), path)
+	} else {
+		err = os.Link(tmp.Name(), path)
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%w: %s", ErrOutputExists, path)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir syncs the directory, so that the files renamed into it survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "proof.json")
+	write := func(contents string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, contents)
+			return err
+		}
+	}
+
+	assert.NoError(t, writeFileAtomic(path, false, write("first")))
+	assert.NoError(t, writeFileAtomic(path, true, write("second")))
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(contents))
+
+	// Without overwrite, the existing file is kept.
+	assert.ErrorIs(t, writeFileAtomic(path, false, write("third")), ErrOutputExists)
+	assert.ErrorIs(t, checkOutputsAbsent(dir, "proof.json"), ErrOutputExists)
+	assert.NoError(t, checkOutputsAbsent(dir, "public_witness.bin"))
+
+	// A failed write leaves the previous contents in place.
+	assert.Error(t, writeFileAtomic(path, true, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "trunc")
+		return errors.New("crashed")
+	}))
+	contents, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(contents))
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPrepareOutputs(t *testing.T) {
+	dir := t.TempDir()
+	receipt := filepath.Join(dir, "receipt.json")
+	assert.NoError(t, os.WriteFile(receipt, []byte("{}"), 0644))
+
+	// The receipt of a previous proof is an output of it.
+	assert.ErrorIs(t, prepareOutputs(dir, false, false), ErrOutputExists)
+
+	// It is replaced by the receipt of the new proof, or removed if there is none.
+	assert.NoError(t, prepareOutputs(dir, true, true))
+	assert.FileExists(t, receipt)
+	assert.NoError(t, prepareOutputs(dir, true, false))
+	assert.NoFileExists(t, receipt)
+	assert.NoError(t, prepareOutputs(dir, true, false))
+	assert.NoError(t, prepareOutputs(dir, false, false))
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type clientIdentityKey struct{}
+
+// APIKeyHeader is the header clients authenticate with an API key in.
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator authenticates the clients of the proving service. A request is accepted if it
+// carries a known API key in the X-API-Key header, a valid HS256 JWT in the Authorization header,
+// or a client certificate verified by the TLS layer when mutual TLS is enabled.
+type Authenticator struct {
+	// The API keys are stored hashed and mapped to the name of the client they belong to.
+	apiKeys map[[32]byte]string
+
+	// JWTSecret is the HMAC key bearer tokens are signed with. If empty, tokens are rejected.
+	JWTSecret []byte
+
+	now func() time.Time
+}
+
+func NewAuthenticator() *Authenticator {
+	return &Authenticator{apiKeys: make(map[[32]byte]string), now: time.Now}
+}
+
+// AddAPIKey registers an API key for the named client.
+func (a *Authenticator) AddAPIKey(client string, key string) error {
+	if client == "" || key == "" {
+		return fmt.Errorf("client name and api key must not be empty")
+	}
+	a.apiKeys[sha256.Sum256([]byte(key))] = client
+	return nil
+}
+
+// LoadAPIKeys reads API keys from a file with one "client:key" pair per line. Empty lines and lines
+// starting with # are skipped.
+func (a *Authenticator) LoadAPIKeys(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open api keys file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		client, key, ok := strings.Cut(entry, ":")
+		if !ok {
+			return fmt.Errorf("invalid api key on line %d, expected client:key", line)
+		}
+		err := a.AddAPIKey(strings.TrimSpace(client), strings.TrimSpace(key))
+		if err != nil {
+			return fmt.Errorf("invalid api key on line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read api keys file: %w", err)
+	}
+	return nil
+}
+
+// Authenticate returns the identity of the client that sent the request.
+func (a *Authenticator) Authenticate(r *http.Request) (string, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		client, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return "", fmt.Errorf("unknown api key")
+		}
+		return "key:" + client, nil
+	}
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok {
+			return "", fmt.Errorf("unsupported authorization scheme")
+		}
+		subject, err := a.verifyJWT(token)
+		if err != nil {
+			return "", err
+		}
+		return "jwt:" + subject, nil
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName, nil
+	}
+	return "", fmt.Errorf("missing credentials")
+}
+
+// Middleware rejects unauthenticated requests with 401 Unauthorized. The client identity is passed
+// on in the request context, so quotas are tracked per authenticated client. A nil authenticator
+// lets every request through.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, err := a.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, statusResponse{Status: "error", Error: err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIdentityKey{}, client)))
+	})
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+// verifyJWT checks an HS256 token signed with the JWT secret and returns its subject.
+func (a *Authenticator) verifyJWT(token string) (string, error) {
+	if len(a.JWTSecret) == 0 {
+		return "", fmt.Errorf("bearer tokens are not accepted")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return "", err
+	}
+	if header.Alg != "HS256" {
+		return "", fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed token signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, a.JWTSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if subtle.ConstantTimeCompare(signature, mac.Sum(nil)) != 1 {
+		return "", fmt.Errorf("invalid token signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return "", err
+	}
+	now := a.now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return "", fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return "", fmt.Errorf("token not yet valid")
+	}
+	if claims.Subject == "" {
+		return "", fmt.Errorf("token has no subject")
+	}
+	return claims.Subject, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("malformed token: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("malformed token: %w", err)
+	}
+	return nil
+}
+
+// LoadTLSConfig loads the server certificate. If clientCAFile is set, mutual TLS is enabled and
+// clients must present a certificate signed by one of its CAs.
+func LoadTLSConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		caPEM, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client ca file")
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signJWT(secret []byte, header string, claims string) string {
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticator(t *testing.T) {
+	auth := NewAuthenticator()
+	auth.JWTSecret = []byte("secret")
+	auth.now = func() time.Time { return time.Unix(1000, 0) }
+	assert.NoError(t, auth.AddAPIKey("alice", "alice-key"))
+
+	authenticate := func(header string, value string) (string, error) {
+		req := httptest.NewRequest(http.MethodPost, "/prove", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		return auth.Authenticate(req)
+	}
+
+	client, err := authenticate(APIKeyHeader, "alice-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "key:alice", client)
+	_, err = authenticate(APIKeyHeader, "mallory-key")
+	assert.Error(t, err)
+	_, err = authenticate("", "")
+	assert.Error(t, err)
+
+	hs256 := `{"alg":"HS256","typ":"JWT"}`
+	client, err = authenticate("Authorization", "Bearer "+signJWT(auth.JWTSecret, hs256, `{"sub":"bob","exp":2000}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt:bob", client)
+
+	// Expired tokens, tokens without expiry, forged tokens and other algorithms are rejected.
+	_, err = authenticate("Authorization", "Bearer "+signJWT(auth.JWTSecret, hs256, `{"sub":"bob","exp":500}`))
+	assert.Error(t, err)
+	_, err = authenticate("Authorization", "Bearer "+signJWT(auth.JWTSecret, hs256, `{"sub":"bob"}`))
+	assert.Error(t, err)
+	_, err = authenticate("Authorization", "Bearer "+signJWT([]byte("other"), hs256, `{"sub":"bob","exp":2000}`))
+	assert.Error(t, err)
+	_, err = authenticate("Authorization", "Bearer "+signJWT(auth.JWTSecret, `{"alg":"none"}`, `{"sub":"bob","exp":2000}`))
+	assert.Error(t, err)
+}
+
+func TestProverServerAuth(t *testing.T) {
+	registry := NewCircuitRegistry()
+	_, err := registry.Register("default", t.TempDir(), "./data/dummy")
+	assert.NoError(t, err)
+	server := NewProverServer(registry)
+	server.Auth = NewAuthenticator()
+	assert.NoError(t, server.Auth.AddAPIKey("alice", "alice-key"))
+	handler := server.Handler()
+
+	send := func(path string, apiKey string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Health probes do not need credentials, proving requests do.
+	assert.Equal(t, http.StatusOK, send("/healthz", ""))
+	assert.Equal(t, http.StatusUnauthorized, send("/prove", ""))
+	assert.Equal(t, http.StatusUnauthorized, send("/prove", "mallory-key"))
+	assert.Equal(t, http.StatusBadRequest, send("/prove", "alice-key"))
+}
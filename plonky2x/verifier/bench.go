@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+)
+
+// BenchReport is the timing and memory report of the stages of the proving pipeline, each run a
+// number of times.
+type BenchReport struct {
+	Runs      int          `json:"runs"`
+	GoVersion string       `json:"go_version"`
+	NumCPU    int          `json:"num_cpu"`
+	Stages    []BenchStage `json:"stages"`
+}
+
+// BenchStage reports the durations of the runs of a stage, and the peak resident set size of the
+// process during its runs, which is only measured on Linux.
+type BenchStage struct {
+	Name         string        `json:"name"`
+	Mean         time.Duration `json:"mean_ns"`
+	Median       time.Duration `json:"median_ns"`
+	P99          time.Duration `json:"p99_ns"`
+	PeakRSSBytes uint64        `json:"peak_rss_bytes,omitempty"`
+}
+
+// benchPipeline is the proving pipeline of a circuit broken into the stages which are benchmarked.
+type benchPipeline struct {
+	compile func() (constraint.ConstraintSystem, error)
+	setup   func(constraint.ConstraintSystem) (plonk.ProvingKey, error)
+	// The assignment whose witness is generated, like in GenerateProof.
+	assignment func(constraint.ConstraintSystem) frontend.Circuit
+}
+
+// BenchmarkPipeline benchmarks compiling the verifier circuit for the plonky2 circuit in
+// dummyCircuitPath, setting it up, generating the witness for the proof in circuitPath and proving
+// it, running each stage n times.
+func BenchmarkPipeline(dummyCircuitPath string, circuitPath string, fixedDigest bool, n int) (*BenchReport, error) {
+	srs, err := LoadSRS()
+	if err != nil {
+		return nil, err
+	}
+	config, err := NewCircuitConfig(dummyCircuitPath, fixedDigest)
+	if err != nil {
+		return nil, err
+	}
+	assignment := LoadAssignment(circuitPath)
+	return runBench(benchPipeline{
+		compile: func() (constraint.ConstraintSystem, error) {
+			return CompileConstraintSystem(dummyCircuitPath, fixedDigest)
+		},
+		setup: func(r1cs constraint.ConstraintSystem) (plonk.ProvingKey, error) {
+			pk, _, err := plonk.Setup(r1cs, srs)
+			return pk, err
+		},
+		assignment: func(r1cs constraint.ConstraintSystem) frontend.Circuit {
+			return config.Circuit(assignment)
+		},
+	}, n)
+}
+
+// runBench runs each stage of the pipeline n times, where each stage works on the output of the
+// last run of the previous one.
+func runBench(p benchPipeline, n int) (*BenchReport, error) {
+	log := logger.Logger()
+	if n < 1 {
+		return nil, fmt.Errorf("number of runs must be positive, got %d", n)
+	}
+	report := &BenchReport{Runs: n, GoVersion: runtime.Version(), NumCPU: runtime.NumCPU()}
+
+	var r1cs constraint.ConstraintSystem
+	var pk plonk.ProvingKey
+	var w witness.Witness
+	stages := []struct {
+		name string
+		run  func() error
+	}{
+		{"compile", func() (err error) {
+			r1cs, err = p.compile()
+			return err
+		}},
+		{"setup", func() (err error) {
+			pk, err = p.setup(r1cs)
+			return err
+		}},
+		{"witness", func() (err error) {
+			w, err = frontend.NewWitness(p.assignment(r1cs), ecc.BN254.ScalarField())
+			return err
+		}},
+		{"prove", func() error {
+			_, err := plonk.Prove(r1cs, pk, w)
+			return err
+		}},
+	}
+	for _, stage := range stages {
+		resetPeakRSS()
+		durations := make([]time.Duration, n)
+		for i := range durations {
+			start := time.Now()
+			if err := stage.run(); err != nil {
+				return nil, fmt.Errorf("failed to run %s: %w", stage.name, err)
+			}
+			durations[i] = time.Since(start)
+			log.Debug().Msg(fmt.Sprintf("Benchmark %s run %d/%d, time: %s", stage.name, i+1, n, durations[i]))
+		}
+		result := summarizeDurations(stage.name, durations)
+		result.PeakRSSBytes = peakRSS()
+		report.Stages = append(report.Stages, result)
+	}
+	return report, nil
+}
+
+// summarizeDurations computes the mean, the median and the 99th percentile, by nearest rank, of
+// the durations.
+func summarizeDurations(name string, durations []time.Duration) BenchStage {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	p99 := (99*len(sorted) + 99) / 100
+	return BenchStage{
+		Name:   name,
+		Mean:   total / time.Duration(len(sorted)),
+		Median: median,
+		P99:    sorted[p99-1],
+	}
+}
+
+// resetPeakRSS resets the peak resident set size of the process, on Linux.
+func resetPeakRSS() {
+	os.WriteFile("/proc/self/clear_refs", []byte("5"), 0)
+}
+
+// peakRSS returns the peak resident set size of the process since it was last reset, or 0 if it
+// cannot be read.
+func peakRSS() uint64 {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "VmHWM:" && fields[2] == "kB" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
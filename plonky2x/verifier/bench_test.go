@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBench(t *testing.T) {
+	report, err := runBench(benchPipeline{
+		compile: func() (constraint.ConstraintSystem, error) {
+			return frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: 10})
+		},
+		setup: func(ccs constraint.ConstraintSystem) (plonk.ProvingKey, error) {
+			srs, err := test.NewKZGSRS(ccs)
+			if err != nil {
+				return nil, err
+			}
+			pk, _, err := plonk.Setup(ccs, srs)
+			return pk, err
+		},
+		assignment: func(constraint.ConstraintSystem) frontend.Circuit {
+			return &fingerprintCircuit{X: 177147, Y: 3, N: 10}
+		},
+	}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Runs)
+	var names []string
+	for _, stage := range report.Stages {
+		names = append(names, stage.Name)
+		assert.True(t, stage.Median > 0 && stage.Median <= stage.P99)
+	}
+	assert.Equal(t, []string{"compile", "setup", "witness", "prove"}, names)
+
+	_, err = runBench(benchPipeline{}, 0)
+	assert.Error(t, err)
+}
+
+func TestSummarizeDurations(t *testing.T) {
+	durations := make([]time.Duration, 100)
+	for i := range durations {
+		durations[i] = time.Duration(100-i) * time.Millisecond
+	}
+	stage := summarizeDurations("prove", durations)
+	assert.Equal(t, 50500*time.Microsecond, stage.Mean)
+	assert.Equal(t, 50500*time.Microsecond, stage.Median)
+	assert.Equal(t, 99*time.Millisecond, stage.P99)
+
+	stage = summarizeDurations("prove", []time.Duration{3 * time.Second, time.Second, 2 * time.Second})
+	assert.Equal(t, 2*time.Second, stage.Median)
+	assert.Equal(t, 3*time.Second, stage.P99)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/succinctlabs/gnark-plonky2-verifier/types"
+)
+
+// The plonky2 proof and verifier data can be read from their native binary serialization, i.e. the
+// output of ProofWithPublicInputs::to_bytes and VerifierOnlyCircuitData::to_bytes, instead of JSON.
+// The binary files are several times smaller and much faster to parse for large proofs. Like in
+// plonky2, most lengths are not serialized and are derived from the common circuit data.
+const (
+	proofWithPublicInputsBinFile   = "proof_with_public_inputs.bin"
+	verifierOnlyCircuitDataBinFile = "verifier_only_circuit_data.bin"
+)
+
+// Field elements are serialized as little-endian u64s, extension field elements as D of them and
+// Poseidon BN254 hashes as the 32 little-endian bytes of the BN254 scalar.
+const (
+	extensionDegree = 2
+	hashBytes       = 32
+)
+
+type queryRoundRaw = struct {
+	InitialTreesProof struct {
+		EvalsProofs []types.EvalProofRaw `json:"evals_proofs"`
+	} `json:"initial_trees_proof"`
+	Steps []queryStepRaw `json:"steps"`
+}
+
+type queryStepRaw = struct {
+	Evals       [][]uint64 `json:"evals"`
+	MerkleProof struct {
+		Siblings []string `json:"siblings"`
+	} `json:"merkle_proof"`
+}
+
+// plonky2Reader decodes plonky2's Buffer serialization. The first error is kept and every
+// subsequent read returns zero values, so it only has to be checked once at the end.
+type plonky2Reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *plonky2Reader) next(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("unexpected end of data at byte %d", r.pos)
+		return make([]byte, n)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *plonky2Reader) readU8() uint8 {
+	return r.next(1)[0]
+}
+
+func (r *plonky2Reader) readU64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
+
+func (r *plonky2Reader) readFieldVec(length uint64) []uint64 {
+	if r.err == nil && length > uint64(len(r.data)-r.pos)/8 {
+		r.err = fmt.Errorf("vector of %d elements exceeds the remaining data", length)
+	}
+	if r.err != nil {
+		return nil
+	}
+	v := make([]uint64, length)
+	for i := range v {
+		v[i] = r.readU64()
+	}
+	return v
+}
+
+func (r *plonky2Reader) readFieldExtVec(length uint64) [][]uint64 {
+	v := make([][]uint64, length)
+	for i := range v {
+		v[i] = r.readFieldVec(extensionDegree)
+	}
+	return v
+}
+
+// readHash returns the hash as a decimal string, like in the JSON serialization.
+func (r *plonky2Reader) readHash() string {
+	le := r.next(hashBytes)
+	be := make([]byte, hashBytes)
+	for i := range le {
+		be[hashBytes-1-i] = le[i]
+	}
+	return new(big.Int).SetBytes(be).String()
+}
+
+func (r *plonky2Reader) readMerkleCap(capHeight uint64) []string {
+	hashes := make([]string, 1<<capHeight)
+	for i := range hashes {
+		hashes[i] = r.readHash()
+	}
+	return hashes
+}
+
+func (r *plonky2Reader) readMerkleProof() []string {
+	siblings := make([]string, r.readU8())
+	for i := range siblings {
+		siblings[i] = r.readHash()
+	}
+	return siblings
+}
+
+func (r *plonky2Reader) finish() error {
+	if r.err == nil && r.pos != len(r.data) {
+		r.err = fmt.Errorf("%d trailing bytes", len(r.data)-r.pos)
+	}
+	return r.err
+}
+
+// DecodeProofWithPublicInputs decodes a binary plonky2 proof of the circuit described by the
+// common circuit data.
+func DecodeProofWithPublicInputs(data []byte, common types.CommonCircuitData) (types.ProofWithPublicInputsRaw, error) {
+	var raw types.ProofWithPublicInputsRaw
+	r := &plonky2Reader{data: data}
+	config := common.Config
+	capHeight := config.FriConfig.CapHeight
+	proof := &raw.Proof
+
+	proof.WiresCap = r.readMerkleCap(capHeight)
+	proof.PlonkZsPartialProductsCap = r.readMerkleCap(capHeight)
+	proof.QuotientPolysCap = r.readMerkleCap(capHeight)
+
+	// The lookup openings, serialized after PlonkZsNext, are empty since circuits with lookups are
+	// rejected.
+	proof.Openings.Constants = r.readFieldExtVec(common.NumConstants)
+	proof.Openings.PlonkSigmas = r.readFieldExtVec(config.NumRoutedWires)
+	proof.Openings.Wires = r.readFieldExtVec(config.NumWires)
+	proof.Openings.PlonkZs = r.readFieldExtVec(config.NumChallenges)
+	proof.Openings.PlonkZsNext = r.readFieldExtVec(config.NumChallenges)
+	proof.Openings.PartialProducts = r.readFieldExtVec(common.NumPartialProducts * config.NumChallenges)
+	proof.Openings.QuotientPolys = r.readFieldExtVec(common.QuotientDegreeFactor * config.NumChallenges)
+
+	openingProof := &proof.OpeningProof
+	openingProof.CommitPhaseMerkleCaps = make([][]string, len(common.FriParams.ReductionArityBits))
+	for i := range openingProof.CommitPhaseMerkleCaps {
+		openingProof.CommitPhaseMerkleCaps[i] = r.readMerkleCap(capHeight)
+	}
+
+	// The leaves of the constants/sigmas, wires, Z/partial products and quotient oracles.
+	oracleWidths := []uint64{
+		common.NumConstants + config.NumRoutedWires,
+		config.NumWires,
+		config.NumChallenges * (1 + common.NumPartialProducts),
+		config.NumChallenges * common.QuotientDegreeFactor,
+	}
+	openingProof.QueryRoundProofs = make([]queryRoundRaw, config.FriConfig.NumQueryRounds)
+	for i := range openingProof.QueryRoundProofs {
+		round := &openingProof.QueryRoundProofs[i]
+		round.InitialTreesProof.EvalsProofs = make([]types.EvalProofRaw, len(oracleWidths))
+		for j, width := range oracleWidths {
+			round.InitialTreesProof.EvalsProofs[j].LeafElements = r.readFieldVec(width)
+			round.InitialTreesProof.EvalsProofs[j].MerkleProof.Hash = r.readMerkleProof()
+		}
+		round.Steps = make([]queryStepRaw, len(common.FriParams.ReductionArityBits))
+		for j, arityBits := range common.FriParams.ReductionArityBits {
+			round.Steps[j].Evals = r.readFieldExtVec(1 << arityBits)
+			round.Steps[j].MerkleProof.Siblings = r.readMerkleProof()
+		}
+	}
+	openingProof.FinalPoly.Coeffs = r.readFieldExtVec(uint64(common.FriParams.FinalPolyLen()))
+	openingProof.PowWitness = r.readU64()
+
+	raw.PublicInputs = r.readFieldVec(r.readU64())
+
+	if err := r.finish(); err != nil {
+		return raw, fmt.Errorf("failed to decode proof: %w", serializationError(err))
+	}
+	return raw, nil
+}
+
+// DecodeVerifierOnlyCircuitData decodes binary plonky2 verifier data.
+func DecodeVerifierOnlyCircuitData(data []byte) (types.VerifierOnlyCircuitDataRaw, error) {
+	var raw types.VerifierOnlyCircuitDataRaw
+	r := &plonky2Reader{data: data}
+	capHeight := r.readU64()
+	if capHeight > 32 {
+		return raw, fmt.Errorf("failed to decode verifier data: %w", serializationError(fmt.Errorf("invalid cap height %d", capHeight)))
+	}
+	raw.ConstantsSigmasCap = r.readMerkleCap(capHeight)
+	raw.CircuitDigest = r.readHash()
+	if err := r.finish(); err != nil {
+		return raw, fmt.Errorf("failed to decode verifier data: %w", serializationError(err))
+	}
+	return raw, nil
+}
+
+// readPlonky2Data reads the proof and verifier data in circuitPath, from the binary files if they
+// exist and from the JSON files otherwise. The binary proof needs common_circuit_data.json to be
+// decoded.
+func readPlonky2Data(circuitPath string) (types.ProofWithPublicInputsRaw, types.VerifierOnlyCircuitDataRaw, error) {
+	proofPath := circuitPath + "/" + proofWithPublicInputsBinFile
+	if _, err := os.Stat(proofPath); os.IsNotExist(err) {
+		return types.ReadProofWithPublicInputs(circuitPath + "/proof_with_public_inputs.json"),
+			types.ReadVerifierOnlyCircuitData(circuitPath + "/verifier_only_circuit_data.json"),
+			nil
+	}
+
+	var proofWithPis types.ProofWithPublicInputsRaw
+	var verifierData types.VerifierOnlyCircuitDataRaw
+	common, err := ReadCommonCircuitData(circuitPath + "/common_circuit_data.json")
+	if err != nil {
+		return proofWithPis, verifierData, err
+	}
+	proofBytes, err := os.ReadFile(proofPath)
+	if err != nil {
+		return proofWithPis, verifierData, fmt.Errorf("failed to read proof: %w", missingArtifact(err))
+	}
+	proofWithPis, err = DecodeProofWithPublicInputs(proofBytes, common)
+	if err != nil {
+		return proofWithPis, verifierData, err
+	}
+	verifierBytes, err := os.ReadFile(circuitPath + "/" + verifierOnlyCircuitDataBinFile)
+	if err != nil {
+		return proofWithPis, verifierData, fmt.Errorf("failed to read verifier data: %w", missingArtifact(err))
+	}
+	verifierData, err = DecodeVerifierOnlyCircuitData(verifierBytes)
+	return proofWithPis, verifierData, err
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/gnark-plonky2-verifier/types"
+	"github.com/succinctlabs/gnark-plonky2-verifier/variables"
+)
+
+// plonky2Writer mirrors plonky2's Buffer serialization to build binary fixtures.
+type plonky2Writer struct {
+	data    []byte
+	counter uint64
+}
+
+func (w *plonky2Writer) field() uint64 {
+	w.counter++
+	w.data = binary.LittleEndian.AppendUint64(w.data, w.counter)
+	return w.counter
+}
+
+func (w *plonky2Writer) fieldVec(length uint64) []uint64 {
+	v := make([]uint64, length)
+	for i := range v {
+		v[i] = w.field()
+	}
+	return v
+}
+
+func (w *plonky2Writer) fieldExtVec(length uint64) [][]uint64 {
+	v := make([][]uint64, length)
+	for i := range v {
+		v[i] = w.fieldVec(extensionDegree)
+	}
+	return v
+}
+
+func (w *plonky2Writer) hash() string {
+	w.counter++
+	value := new(big.Int).Lsh(big.NewInt(int64(w.counter)), 200)
+	be := value.FillBytes(make([]byte, hashBytes))
+	for i := len(be) - 1; i >= 0; i-- {
+		w.data = append(w.data, be[i])
+	}
+	return value.String()
+}
+
+func (w *plonky2Writer) hashes(n int) []string {
+	v := make([]string, n)
+	for i := range v {
+		v[i] = w.hash()
+	}
+	return v
+}
+
+func (w *plonky2Writer) merkleProof(n int) []string {
+	w.data = append(w.data, byte(n))
+	return w.hashes(n)
+}
+
+// encodeTestProof builds a proof with the shape given by the common circuit data and returns it
+// with its binary serialization.
+func encodeTestProof(common types.CommonCircuitData) (types.ProofWithPublicInputsRaw, []byte) {
+	config := common.Config
+	capSize := 1 << config.FriConfig.CapHeight
+
+	var expected types.ProofWithPublicInputsRaw
+	w := &plonky2Writer{}
+	proof := &expected.Proof
+	proof.WiresCap = w.hashes(capSize)
+	proof.PlonkZsPartialProductsCap = w.hashes(capSize)
+	proof.QuotientPolysCap = w.hashes(capSize)
+	proof.Openings.Constants = w.fieldExtVec(common.NumConstants)
+	proof.Openings.PlonkSigmas = w.fieldExtVec(config.NumRoutedWires)
+	proof.Openings.Wires = w.fieldExtVec(config.NumWires)
+	proof.Openings.PlonkZs = w.fieldExtVec(config.NumChallenges)
+	proof.Openings.PlonkZsNext = w.fieldExtVec(config.NumChallenges)
+	proof.Openings.PartialProducts = w.fieldExtVec(common.NumPartialProducts * config.NumChallenges)
+	proof.Openings.QuotientPolys = w.fieldExtVec(common.QuotientDegreeFactor * config.NumChallenges)
+	for range common.FriParams.ReductionArityBits {
+		proof.OpeningProof.CommitPhaseMerkleCaps = append(proof.OpeningProof.CommitPhaseMerkleCaps, w.hashes(capSize))
+	}
+	merkleProofLen := int(common.FriParams.LdeBits()) - int(config.FriConfig.CapHeight)
+	for i := uint64(0); i < config.FriConfig.NumQueryRounds; i++ {
+		var round queryRoundRaw
+		for _, width := range []uint64{
+			common.NumConstants + config.NumRoutedWires,
+			config.NumWires,
+			config.NumChallenges * (1 + common.NumPartialProducts),
+			config.NumChallenges * common.QuotientDegreeFactor,
+		} {
+			var evalProof types.EvalProofRaw
+			evalProof.LeafElements = w.fieldVec(width)
+			evalProof.MerkleProof.Hash = w.merkleProof(merkleProofLen)
+			round.InitialTreesProof.EvalsProofs = append(round.InitialTreesProof.EvalsProofs, evalProof)
+		}
+		for _, arityBits := range common.FriParams.ReductionArityBits {
+			var step queryStepRaw
+			step.Evals = w.fieldExtVec(1 << arityBits)
+			merkleProofLen -= int(arityBits)
+			step.MerkleProof.Siblings = w.merkleProof(merkleProofLen)
+			round.Steps = append(round.Steps, step)
+		}
+		merkleProofLen = int(common.FriParams.LdeBits()) - int(config.FriConfig.CapHeight)
+		proof.OpeningProof.QueryRoundProofs = append(proof.OpeningProof.QueryRoundProofs, round)
+	}
+	proof.OpeningProof.FinalPoly.Coeffs = w.fieldExtVec(uint64(common.FriParams.FinalPolyLen()))
+	proof.OpeningProof.PowWitness = w.field()
+	w.data = binary.LittleEndian.AppendUint64(w.data, 64)
+	expected.PublicInputs = w.fieldVec(64)
+	return expected, w.data
+}
+
+func TestDecodeProofWithPublicInputs(t *testing.T) {
+	common, err := ReadCommonCircuitData("./testdata/common_circuit_data.json")
+	assert.NoError(t, err)
+	expected, data := encodeTestProof(common)
+
+	decoded, err := DecodeProofWithPublicInputs(data, common)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, decoded)
+
+	// Truncated and oversized proofs are rejected.
+	_, err = DecodeProofWithPublicInputs(data[:len(data)-1], common)
+	assert.Error(t, err)
+	_, err = DecodeProofWithPublicInputs(append(data, 0), common)
+	assert.Error(t, err)
+}
+
+func TestDecodeVerifierOnlyCircuitData(t *testing.T) {
+	w := &plonky2Writer{}
+	w.data = binary.LittleEndian.AppendUint64(w.data, 2)
+	cap := w.hashes(4)
+	digest := w.hash()
+
+	decoded, err := DecodeVerifierOnlyCircuitData(w.data)
+	assert.NoError(t, err)
+	assert.Equal(t, types.VerifierOnlyCircuitDataRaw{ConstantsSigmasCap: cap, CircuitDigest: digest}, decoded)
+}
+
+func TestDeserializeProofWithPublicInputs(t *testing.T) {
+	common, err := ReadCommonCircuitData("./testdata/common_circuit_data.json")
+	assert.NoError(t, err)
+	raw, _ := encodeTestProof(common)
+
+	// The parallel deserialization matches the one of gnark-plonky2-verifier.
+	assert.Equal(t, variables.DeserializeProofWithPublicInputs(raw), DeserializeProofWithPublicInputs(raw))
+
+	// A malformed query round panics on the calling goroutine, where it can be recovered.
+	raw.Proof.OpeningProof.QueryRoundProofs[1].Steps[0].Evals[0] = []uint64{1}
+	assert.Panics(t, func() { DeserializeProofWithPublicInputs(raw) })
+}
+
+func TestParallelFor(t *testing.T) {
+	var sum atomic.Int64
+	parallelFor(100, func(i int) { sum.Add(int64(i)) })
+	assert.Equal(t, int64(4950), sum.Load())
+
+	assert.PanicsWithValue(t, "malformed", func() {
+		parallelFor(100, func(i int) {
+			if i == 42 {
+				panic("malformed")
+			}
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// The file of the checksums of the artifacts in a data directory, written by SaveVerifierCircuit.
+const checksumsFileName = "checksums.json"
+
+// The artifacts whose checksums are written along with them.
+var checksummedArtifacts = []string{"r1cs.bin", "pk.bin", "vk.bin"}
+
+// WriteChecksums writes the sha256 checksums of the given artifacts in the directory to its
+// checksums file, keyed by the name of the plain file.
+func WriteChecksums(path string, files []string) error {
+	checksums := make(map[string]hexutil.Bytes)
+	for _, file := range files {
+		checksum, err := fileChecksum(path, file)
+		if err != nil {
+			return err
+		}
+		checksums[file] = checksum
+	}
+	data, err := json.MarshalIndent(checksums, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checksums: %w", err)
+	}
+	if err := os.WriteFile(path+"/"+checksumsFileName, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checksums file: %w", err)
+	}
+	return nil
+}
+
+// VerifyChecksum checks the file in the directory against the checksum recorded in its checksums
+// file. Artifacts are only deserialized without subgroup checks once they pass this check, which
+// makes sure they are the ones written on a trusted machine and not corrupted or replaced since.
+func VerifyChecksum(path string, file string) error {
+	data, err := os.ReadFile(path + "/" + checksumsFileName)
+	if err != nil {
+		return fmt.Errorf("failed to read checksums file: %w", missingArtifact(err))
+	}
+	var checksums map[string]hexutil.Bytes
+	if err := json.Unmarshal(data, &checksums); err != nil {
+		return fmt.Errorf("failed to parse checksums file: %w", serializationError(err))
+	}
+	expected, ok := checksums[file]
+	if !ok {
+		return fmt.Errorf("no checksum recorded for %s", file)
+	}
+	checksum, err := fileChecksum(path, file)
+	if err != nil {
+		return err
+	}
+	if checksum.String() != expected.String() {
+		return fmt.Errorf("%w: checksum of %s is %s, expected %s", types.ErrDigestMismatch, file, checksum, expected)
+	}
+	return nil
+}
+
+// fileChecksum hashes the contents of the artifact in the directory, after decompressing it if it
+// has been compressed, so the checksums stay valid when the artifacts are compressed.
+func fileChecksum(dir string, name string) (hexutil.Bytes, error) {
+	path, err := artifactPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := openArtifact(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer reader.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return nil, fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	return hasher.Sum(nil), nil
+}
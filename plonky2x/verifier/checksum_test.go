@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// saveTestCircuit saves the artifacts of a small circuit to a temporary data directory.
+func saveTestCircuit(t *testing.T, compression Compression) string {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: 10})
+	assert.NoError(t, err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(t, err)
+	pk, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(t, err)
+	path := t.TempDir()
+	assert.NoError(t, SaveVerifierCircuit(path, ccs, pk, vk, compression))
+	return path
+}
+
+func TestUnsafeDeserialize(t *testing.T) {
+	path := saveTestCircuit(t, CompressionNone)
+
+	// The keys read without subgroup checks are the same as the checked ones.
+	_, safePK, err := LoadProverData(path, false)
+	assert.NoError(t, err)
+	_, unsafePK, err := LoadProverData(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, safePK, unsafePK)
+	safeVK, err := LoadVerifierKey(path, false)
+	assert.NoError(t, err)
+	unsafeVK, err := LoadVerifierKey(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, safeVK, unsafeVK)
+
+	// A modified key is rejected before it is deserialized.
+	data, err := os.ReadFile(path + "/vk.bin")
+	assert.NoError(t, err)
+	data[len(data)-1] ^= 1
+	assert.NoError(t, os.WriteFile(path+"/vk.bin", data, 0644))
+	_, err = LoadVerifierKey(path, true)
+	assert.ErrorContains(t, err, "checksum of vk.bin")
+	assert.ErrorIs(t, err, types.ErrDigestMismatch)
+
+	// So are keys without a recorded checksum.
+	assert.NoError(t, os.Remove(path+"/"+checksumsFileName))
+	_, _, err = LoadProverData(path, true)
+	assert.ErrorContains(t, err, "failed to read checksums file")
+	assert.ErrorIs(t, err, types.ErrArtifactMissing)
+	_, _, err = LoadProverData(path, false)
+	assert.NoError(t, err)
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"math/big"
 	"os"
 	"time"
 
@@ -35,6 +34,7 @@ type Plonky2xVerifierCircuit struct {
 
 	// Circuit configuration that is not part of the circuit itself.
 	CommonCircuitData types.CommonCircuitData `gnark:"-"`
+	IOCommitment      IOCommitment            `gnark:"-"`
 }
 
 func (c *Plonky2xVerifierCircuit) Define(api frontend.API) error {
@@ -43,30 +43,15 @@ func (c *Plonky2xVerifierCircuit) Define(api frontend.API) error {
 	// verify the plonky2 proof
 	verifierChip.Verify(c.ProofWithPis.Proof, c.ProofWithPis.PublicInputs, c.VerifierData)
 
-	// We assume that the publicInputs have 64 bytes
-	// publicInputs[0:32] is a big-endian representation of a SHA256 hash that has been truncated to 253 bits.
-	// Note that this truncation happens in the `WrappedCircuit` when computing the `input_hash`
-	// The reason for truncation is that we only want 1 public input on-chain for the input hash
-	// to save on gas costs
-	publicInputs := c.ProofWithPis.PublicInputs
-
-	if len(publicInputs) != 64 {
-		return fmt.Errorf("expected 64 public inputs, got %d", len(publicInputs))
-	}
-
-	inputDigest := frontend.Variable(0)
-	for i := 0; i < 32; i++ {
-		pubByte := publicInputs[31-i].Limb
-		inputDigest = api.Add(inputDigest, api.Mul(pubByte, frontend.Variable(new(big.Int).Lsh(big.NewInt(1), uint(8*i)))))
-
+	// The public inputs commit to the inputs and outputs of the function, by default as the bytes of
+	// their SHA256 hashes truncated to 253 bits. The truncation happens in the `WrappedCircuit` when
+	// computing the `input_hash`, so that each hash is a single public input onchain to save on gas
+	// costs.
+	inputDigest, outputDigest, err := c.IOCommitment.digests(api, c.ProofWithPis.PublicInputs)
+	if err != nil {
+		return err
 	}
 	api.AssertIsEqual(c.InputHash, inputDigest)
-
-	outputDigest := frontend.Variable(0)
-	for i := 0; i < 32; i++ {
-		pubByte := publicInputs[63-i].Limb
-		outputDigest = api.Add(outputDigest, api.Mul(pubByte, frontend.Variable(new(big.Int).Lsh(big.NewInt(1), uint(8*i)))))
-	}
 	api.AssertIsEqual(c.OutputHash, outputDigest)
 
 	// We have to assert that the VerifierData we verified the proof with
@@ -76,7 +61,54 @@ func (c *Plonky2xVerifierCircuit) Define(api frontend.API) error {
 	return nil
 }
 
-func CompileVerifierCircuit(dummyCircuitPath string) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+// The number of public inputs of Plonky2xFixedVerifierCircuit, which has no VerifierDigest.
+const fixedVerifierNbPublicInputs = 2
+
+// Plonky2xFixedVerifierCircuit verifies proofs of a single plonky2x circuit, whose digest is baked
+// into the circuit as a constant instead of being a public input. The verifier is cheaper onchain,
+// but it has to be compiled for every plonky2x circuit.
+type Plonky2xFixedVerifierCircuit struct {
+	InputHash  frontend.Variable `gnark:"inputHash,public"`
+	OutputHash frontend.Variable `gnark:"outputHash,public"`
+
+	ProofWithPis variables.ProofWithPublicInputs
+	VerifierData variables.VerifierOnlyCircuitData
+
+	VerifierDigest    frontend.Variable       `gnark:"-"`
+	CommonCircuitData types.CommonCircuitData `gnark:"-"`
+	IOCommitment      IOCommitment            `gnark:"-"`
+}
+
+func (c *Plonky2xFixedVerifierCircuit) Define(api frontend.API) error {
+	circuit := Plonky2xVerifierCircuit{
+		VerifierDigest:    c.VerifierDigest,
+		InputHash:         c.InputHash,
+		OutputHash:        c.OutputHash,
+		ProofWithPis:      c.ProofWithPis,
+		VerifierData:      c.VerifierData,
+		CommonCircuitData: c.CommonCircuitData,
+		IOCommitment:      c.IOCommitment,
+	}
+	return circuit.Define(api)
+}
+
+// Fixed returns the assignment of the fixed verifier circuit for the same proof.
+func (c *Plonky2xVerifierCircuit) Fixed() *Plonky2xFixedVerifierCircuit {
+	return &Plonky2xFixedVerifierCircuit{
+		InputHash:         c.InputHash,
+		OutputHash:        c.OutputHash,
+		ProofWithPis:      c.ProofWithPis,
+		VerifierData:      c.VerifierData,
+		VerifierDigest:    c.VerifierDigest,
+		CommonCircuitData: c.CommonCircuitData,
+		IOCommitment:      c.IOCommitment,
+	}
+}
+
+// CompileConstraintSystem compiles the verifier circuit for the plonky2 circuit in dummyCircuitPath,
+// without running the setup. If fixedDigest is set, the digest of that circuit is baked into the
+// verifier circuit, which then only verifies proofs of it, see Plonky2xFixedVerifierCircuit.
+func CompileConstraintSystem(dummyCircuitPath string, fixedDigest bool) (constraint.ConstraintSystem, error) {
 	log := logger.Logger()
 	verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(
 		types.ReadVerifierOnlyCircuitData(dummyCircuitPath + "/verifier_only_circuit_data.json"),
@@ -84,7 +116,14 @@ func CompileVerifierCircuit(dummyCircuitPath string) (constraint.ConstraintSyste
 	proofWithPis := variables.DeserializeProofWithPublicInputs(
 		types.ReadProofWithPublicInputs(dummyCircuitPath + "/proof_with_public_inputs.json"),
 	)
-	commonCircuitData := types.ReadCommonCircuitData(dummyCircuitPath + "/common_circuit_data.json")
+	commonCircuitData, err := ReadCommonCircuitData(dummyCircuitPath + "/common_circuit_data.json")
+	if err != nil {
+		return nil, err
+	}
+	ioCommitment, err := ReadIOCommitment(dummyCircuitPath)
+	if err != nil {
+		return nil, err
+	}
 
 	circuit := Plonky2xVerifierCircuit{
 		ProofWithPis:      proofWithPis,
@@ -93,30 +132,33 @@ func CompileVerifierCircuit(dummyCircuitPath string) (constraint.ConstraintSyste
 		InputHash:         new(frontend.Variable),
 		OutputHash:        new(frontend.Variable),
 		CommonCircuitData: commonCircuitData,
+		IOCommitment:      ioCommitment,
+	}
+	var compiled frontend.Circuit = &circuit
+	if fixedDigest {
+		fixed := circuit.Fixed()
+		fixed.VerifierDigest = verifierOnlyCircuitData.CircuitDigest
+		compiled = fixed
 	}
-	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &circuit)
+	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, compiled)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to compile circuit: %w", err)
+		return nil, fmt.Errorf("failed to compile circuit: %w", err)
 	}
 	log.Info().Msg("Successfully compiled verifier circuit")
+	return r1cs, nil
+}
 
-	log.Info().Msg("Loading SRS")
-	fileName := "srs_setup"
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		trusted_setup.DownloadAndSaveAztecIgnitionSrs(174, fileName)
-	}
-	fSRS, err := os.Open(fileName)
+func CompileVerifierCircuit(dummyCircuitPath string, fixedDigest bool) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	log := logger.Logger()
+	r1cs, err := CompileConstraintSystem(dummyCircuitPath, fixedDigest)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to open srs file: %w", err)
+		return nil, nil, nil, err
 	}
 
-	var srs kzg.SRS = kzg.NewSRS(ecc.BN254)
-	_, err = srs.ReadFrom(fSRS)
+	srs, err := LoadSRS()
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to read srs file: %w", err)
+		return nil, nil, nil, err
 	}
-	fSRS.Close()
-	log.Info().Msg("Successfully loaded SRS")
 
 	log.Info().Msg("Running circuit setup")
 	start := time.Now()
@@ -130,28 +172,36 @@ func CompileVerifierCircuit(dummyCircuitPath string) (constraint.ConstraintSyste
 	return r1cs, pk, vk, nil
 }
 
-func SaveVerifierCircuit(path string, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey) error {
+// SaveVerifierCircuit writes the constraint system, the proving key and the verifying key to the
+// data directory, along with their checksums. The constraint system and the proving key are
+// compressed in the given format, e.g. as pk.bin.zst.
+func SaveVerifierCircuit(path string, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey, compression Compression) error {
 	log := logger.Logger()
 	os.MkdirAll(path, 0755)
-	log.Info().Msg("Saving circuit constraints to " + path + "/r1cs.bin")
-	r1csFile, err := os.Create(path + "/r1cs.bin")
+	extension := compressionExtensions[compression]
+	log.Info().Msg("Saving circuit constraints to " + path + "/r1cs.bin" + extension)
+	r1csFile, err := createArtifact(path, "r1cs.bin", compression)
 	if err != nil {
 		return fmt.Errorf("failed to create r1cs file: %w", err)
 	}
 	start := time.Now()
 	r1cs.WriteTo(r1csFile)
-	r1csFile.Close()
+	if err := r1csFile.Close(); err != nil {
+		return fmt.Errorf("failed to write r1cs file: %w", err)
+	}
 	elapsed := time.Since(start)
 	log.Debug().Msg("Successfully saved circuit constraints, time: " + elapsed.String())
 
-	log.Info().Msg("Saving proving key to " + path + "/pk.bin")
-	pkFile, err := os.Create(path + "/pk.bin")
+	log.Info().Msg("Saving proving key to " + path + "/pk.bin" + extension)
+	pkFile, err := createArtifact(path, "pk.bin", compression)
 	if err != nil {
 		return fmt.Errorf("failed to create pk file: %w", err)
 	}
 	start = time.Now()
 	pk.WriteRawTo(pkFile)
-	pkFile.Close()
+	if err := pkFile.Close(); err != nil {
+		return fmt.Errorf("failed to write pk file: %w", err)
+	}
 	elapsed = time.Since(start)
 	log.Debug().Msg("Successfully saved proving key, time: " + elapsed.String())
 
@@ -166,5 +216,30 @@ func SaveVerifierCircuit(path string, r1cs constraint.ConstraintSystem, pk plonk
 	elapsed = time.Since(start)
 	log.Info().Msg("Successfully saved verifying key, time: " + elapsed.String())
 
-	return nil
+	// The checksums allow trusted artifacts to be loaded with -unsafe-deserialize.
+	return WriteChecksums(path, checksummedArtifacts)
+}
+
+// LoadSRS reads the Aztec Ignition SRS the verifier circuit is set up with from srs_setup in the
+// current working directory, and downloads it first if it is missing.
+func LoadSRS() (kzg.SRS, error) {
+	log := logger.Logger()
+	log.Info().Msg("Loading SRS")
+	fileName := "srs_setup"
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		trusted_setup.DownloadAndSaveAztecIgnitionSrs(174, fileName)
+	}
+	fSRS, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open srs file: %w", missingArtifact(err))
+	}
+	defer fSRS.Close()
+
+	var srs kzg.SRS = kzg.NewSRS(ecc.BN254)
+	_, err = srs.ReadFrom(fSRS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read srs file: %w", serializationError(err))
+	}
+	log.Info().Msg("Successfully loaded SRS")
+	return srs, nil
 }
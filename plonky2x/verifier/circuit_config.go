@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// The file next to r1cs.bin which records the configuration the verifier circuit was compiled with.
+const circuitConfigFile = "circuit_config.json"
+
+// CircuitConfig is the configuration a verifier circuit was compiled with, which its constraint
+// system does not record. It is saved to the data directory by setup, so that provers loading the
+// circuit reject the plonky2 proofs it was not compiled for, instead of proving them with another
+// configuration than the one they were generated with.
+type CircuitConfig struct {
+	IOCommitment IOCommitment `json:"io_commitment"`
+
+	// The circuit digest baked into a fixed verifier circuit, see Plonky2xFixedVerifierCircuit, or
+	// nil if the digest is a public input.
+	FixedDigest *hexutil.Big `json:"fixed_digest,omitempty"`
+}
+
+// NewCircuitConfig returns the configuration of the verifier circuit compiled for the plonky2
+// circuit in dummyCircuitPath, see CompileConstraintSystem.
+func NewCircuitConfig(dummyCircuitPath string, fixedDigest bool) (CircuitConfig, error) {
+	ioCommitment, err := ReadIOCommitment(dummyCircuitPath)
+	if err != nil {
+		return CircuitConfig{}, err
+	}
+	config := CircuitConfig{IOCommitment: ioCommitment}
+	if fixedDigest {
+		_, verifierData, err := readPlonky2Data(dummyCircuitPath)
+		if err != nil {
+			return CircuitConfig{}, fmt.Errorf("failed to read the dummy verifier data: %w", err)
+		}
+		circuitDigest, ok := new(big.Int).SetString(verifierData.CircuitDigest, 10)
+		if !ok {
+			return CircuitConfig{}, fmt.Errorf("invalid circuit digest %q", verifierData.CircuitDigest)
+		}
+		config.FixedDigest = (*hexutil.Big)(circuitDigest)
+	}
+	return config, nil
+}
+
+// Fixed returns whether the verifier circuit is a Plonky2xFixedVerifierCircuit.
+func (c CircuitConfig) Fixed() bool {
+	return c.FixedDigest != nil
+}
+
+// Circuit returns the assignment of the verifier circuit of the config for the plonky2 proof.
+func (c CircuitConfig) Circuit(assignment *Plonky2xVerifierCircuit) frontend.Circuit {
+	if c.Fixed() {
+		return assignment.Fixed()
+	}
+	return assignment
+}
+
+// SaveCircuitConfig writes the configuration to circuit_config.json in the data directory.
+func SaveCircuitConfig(path string, config CircuitConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode circuit config: %w", err)
+	}
+	return os.WriteFile(filepath.Join(path, circuitConfigFile), data, 0644)
+}
+
+// ReadCircuitConfig reads the configuration of the verifier circuit in the data directory. Data
+// directories set up before the configuration was saved have the default one, as the IO commitment
+// could not be configured then. Fixed verifier circuits set up then must be set up again.
+func ReadCircuitConfig(path string) (CircuitConfig, error) {
+	var config CircuitConfig
+	data, err := os.ReadFile(filepath.Join(path, circuitConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read circuit config: %w", err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse circuit config: %w", serializationError(err))
+	}
+	if err := config.IOCommitment.validate(); err != nil {
+		return config, fmt.Errorf("invalid circuit config: %w", err)
+	}
+	return config, nil
+}
+
+// CheckAssignment checks that the verifier circuit was compiled for the plonky2 proof of the
+// assignment, and fails with a WitnessError otherwise.
+func (c CircuitConfig) CheckAssignment(assignment *Plonky2xVerifierCircuit) error {
+	if assignment.IOCommitment != c.IOCommitment {
+		return &WitnessError{Err: fmt.Errorf("the plonky2 proof commits to its io with %+v, but the circuit was compiled for %+v", assignment.IOCommitment, c.IOCommitment)}
+	}
+	// A fixed verifier circuit only proves the plonky2 proofs of the circuit it was compiled for.
+	if c.Fixed() && c.FixedDigest.ToInt().Cmp(assignment.VerifierDigest.(*big.Int)) != 0 {
+		return &WitnessError{Err: fmt.Errorf("%w: the circuit only proves plonky2 proofs with verifierDigest %#x, got %#x", types.ErrDigestMismatch, c.FixedDigest.ToInt(), assignment.VerifierDigest)}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+func TestCircuitConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	// Data directories without a config were compiled with the default one.
+	config, err := ReadCircuitConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitConfig{}, config)
+
+	expected := CircuitConfig{IOCommitment: IOCommitment{Hash: IOHashKeccak256, HashInCircuit: true, NumInputBytes: 4}}
+	assert.NoError(t, SaveCircuitConfig(dir, expected))
+	config, err = ReadCircuitConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, config)
+
+	// Proofs committing to their io in another way than the circuit was compiled for are rejected.
+	assignment := &Plonky2xVerifierCircuit{IOCommitment: expected.IOCommitment}
+	assert.NoError(t, config.CheckAssignment(assignment))
+	assignment.IOCommitment.NumInputBytes = 5
+	var witnessErr *WitnessError
+	assert.True(t, errors.As(config.CheckAssignment(assignment), &witnessErr))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, circuitConfigFile), []byte(`{"io_commitment":{"hash":"blake2b"}}`), 0644))
+	_, err = ReadCircuitConfig(dir)
+	assert.Error(t, err)
+
+	// A fixed verifier circuit only proves the plonky2 proofs of its circuit, without the digest as
+	// a public input.
+	config = CircuitConfig{FixedDigest: (*hexutil.Big)(big.NewInt(5))}
+	assignment = &Plonky2xVerifierCircuit{VerifierDigest: big.NewInt(5)}
+	assert.NoError(t, config.CheckAssignment(assignment))
+	assert.IsType(t, &Plonky2xFixedVerifierCircuit{}, config.Circuit(assignment))
+	assignment.VerifierDigest = big.NewInt(3)
+	assert.ErrorIs(t, config.CheckAssignment(assignment), types.ErrDigestMismatch)
+	assert.Equal(t, assignment, CircuitConfig{}.Circuit(assignment))
+}
@@ -2,77 +2,312 @@ package main
 
 import (
 	"bufio"
+	"context"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
 )
 
-func main() {
-	circuitPath := flag.String("circuit", "", "circuit data directory")
-	dataPath := flag.String("data", "", "data directory")
-	proofFlag := flag.Bool("prove", false, "create a proof")
-	verifyFlag := flag.Bool("verify", false, "verify a proof")
-	compileFlag := flag.Bool("compile", false, "Compile and save the universal verifier circuit")
-	contractFlag := flag.Bool("contract", true, "Generate solidity contract")
-	flag.Parse()
+// The plonky2x circuit the verifier circuit is compiled for, whose proofs it verifies.
+const dummyCircuitPath = "./data/dummy"
 
-	log := logger.Logger()
+// The prefix of the environment variables the flags of a command are read from when they are not
+// given on the command line, e.g. VERIFIER_DATA for -data.
+const envPrefix = "VERIFIER_"
+
+// command is a subcommand of the verifier, e.g. verifier prove -data ./data.
+type command struct {
+	name    string
+	summary string
+	// flags defines the flags of the command, and returns the function running the command once
+	// they are parsed, with the remaining arguments.
+	flags func(fs *flag.FlagSet) func(args []string) error
+}
 
-	if *circuitPath == "" {
-		log.Info().Msg("no circuitPath flag found, so user must input circuitPath via stdin")
+func commands() []command {
+	return []command{
+		{"setup", "compile the verifier circuit, and save it with its keys and solidity verifier", setupCommand},
+		{"prove", "prove the plonky2 proof in -circuit, or in the circuit path read from stdin, or with -pipe from json on stdin to stdout", proveCommand},
+		{"verify", "verify the proof in proof.json", verifyCommand},
+		{"serve", "serve proving requests over http", serveCommand},
+		{"watch", "watch the gateway for requests, prove and fulfill them", watchCommand},
+		{"watch-dir", "prove the plonky2 proofs dropped into a directory, and write their proofs to another", watchDirCommand},
+		{"export", "export the verifying key", exportCommand},
+		{"migrate", "compare the artifacts of two versions of the circuit and print a compatibility report", migrateCommand},
+		{"bench", "benchmark the stages of proving the proof in -circuit", benchCommand},
+		{"stats", "compile the verifier circuit and print a constraint and cost report", statsCommand},
+		{"fingerprint", "compile the verifier circuit and print a hash of the constraint system and the versions it was compiled with", fingerprintCommand},
+		{"registration", "print the function id and verifier address the gateway registers, and check them if -rpc is set", registrationCommand},
+		{"list", "list the proofs in the -archive, optionally only those with -status", listCommand},
+		{"get", "print the archived proofs of an input hash or request id", getCommand},
+		{"completion", "print the shell completion script for bash, zsh or fish", completionCommand},
 	}
+}
 
-	if *dataPath == "" {
-		log.Error().Msg("please specify a path to data dir (where the compiled gnark circuit data will be)")
+func main() {
+	log := logger.Logger()
+	err := run(os.Args[1:], os.Stderr)
+	if err != nil {
+		log.Err(err).Msg("command failed")
 		os.Exit(1)
 	}
+}
+
+// run runs the command named by the first argument with the other arguments.
+func run(args []string, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return errors.New("no command given")
+		}
+		return nil
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		printUsage(stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	fs, runCommand := newFlagSet(cmd)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if err := bindEnv(fs); err != nil {
+		return err
+	}
+	return runCommand(fs.Args())
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// newFlagSet defines the flags of the command on a new flag set.
+func newFlagSet(cmd command) (*flag.FlagSet, func(args []string) error) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	runCommand := cmd.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: verifier %s [flags]\n\n%s\n\nflags, also read from %s<FLAG> environment variables:\n", cmd.name, cmd.summary, envPrefix)
+		fs.PrintDefaults()
+	}
+	return fs, runCommand
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: verifier <command> [flags]\n\ncommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nrun verifier <command> -h for the flags of a command")
+}
+
+// envName returns the environment variable of the flag, e.g. VERIFIER_TLS_CERT for -tls-cert.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// bindEnv sets the flags which are not given on the command line from their environment variables.
+func bindEnv(fs *flag.FlagSet) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok || given[f.Name] || err != nil {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", envName(f.Name), setErr)
+		}
+	})
+	return err
+}
+
+// The flags shared by several commands.
+
+func dataFlag(fs *flag.FlagSet) *string {
+	return fs.String("data", "", "data directory of the compiled verifier circuit")
+}
+
+func circuitFlag(fs *flag.FlagSet) *string {
+	return fs.String("circuit", "", "circuit data directory of the plonky2 proof")
+}
+
+func fixedDigestFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("fixed-digest", false, "bake the digest of the dummy circuit into the verifier circuit instead of exposing it as a public input")
+}
+
+func unsafeDeserializeFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("unsafe-deserialize", false, "load the proving and verifying keys without subgroup checks after verifying their checksums, only for trusted artifacts")
+}
+
+func functionsFlag(fs *flag.FlagSet) *string {
+	return fs.String("functions", "", "circuits to host as functionId=dataPath[=dummyCircuitPath],..., instead of the one in -data")
+}
+
+func retriesFlag(fs *flag.FlagSet) *int {
+	return fs.Int("retries", 3, "attempts for proofs failing for transient reasons, invalid proofs are never retried")
+}
+
+func transcriptFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("log-transcript", false, "log the fiat-shamir transcript of the verifier, and compare it with the one of the solidity verifier if its bytecode is given")
+}
+
+func archiveFlag(fs *flag.FlagSet) *string {
+	return fs.String("archive", "", "proof archive database recording the produced proofs, see the list and get commands")
+}
+
+// openArchive opens the proof archive at path, or returns nil if path is empty.
+func openArchive(path string) (*ProofArchive, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return OpenProofArchive(path)
+}
 
-	log.Debug().Msg("Circuit path: " + *circuitPath)
-	log.Debug().Msg("Data path: " + *dataPath)
+func requireDataPath(dataPath string) error {
+	if dataPath == "" {
+		return errors.New("please specify a path to data dir (where the compiled gnark circuit data will be)")
+	}
+	return nil
+}
 
-	if *compileFlag {
+// printJSON prints the report as indented JSON to stdout.
+func printJSON(report interface{}) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", report, err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func setupCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	fixedDigest := fixedDigestFlag(fs)
+	contract := fs.Bool("contract", true, "generate the solidity verifier")
+	compressionName := fs.String("compression", "none", "compression of the saved constraint system and proving key: none, gzip or zstd")
+	return func([]string) error {
+		log := logger.Logger()
+		if err := requireDataPath(*dataPath); err != nil {
+			return err
+		}
+		compression, err := ParseCompression(*compressionName)
+		if err != nil {
+			return err
+		}
 		log.Info().Msg("compiling verifier circuit")
-		r1cs, pk, vk, err := CompileVerifierCircuit("./data/dummy")
+		r1cs, pk, vk, err := CompileVerifierCircuit(dummyCircuitPath, *fixedDigest)
+		if err != nil {
+			return fmt.Errorf("failed to compile verifier circuit: %w", err)
+		}
+		err = SaveVerifierCircuit(*dataPath, r1cs, pk, vk, compression)
+		if err != nil {
+			return fmt.Errorf("failed to save verifier circuit: %w", err)
+		}
+		config, err := NewCircuitConfig(dummyCircuitPath, *fixedDigest)
 		if err != nil {
-			log.Error().Msg("failed to compile verifier circuit:" + err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to read the circuit config: %w", err)
 		}
-		err = SaveVerifierCircuit(*dataPath, r1cs, pk, vk)
+		err = SaveCircuitConfig(*dataPath, config)
 		if err != nil {
-			log.Error().Msg("failed to save verifier circuit:" + err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to save the circuit config: %w", err)
+		}
+		if !*contract {
+			return nil
 		}
 
-		if *contractFlag {
-			log.Info().Msg("generating solidity contract")
-			err := ExportIFunctionVerifierSolidity(*dataPath, vk)
+		log.Info().Msg("generating solidity contract")
+		err = ExportIFunctionVerifierSolidity(*dataPath, vk)
+		if err != nil {
+			return fmt.Errorf("failed to generate solidity contract: %w", err)
+		}
+		if config.Fixed() {
+			err = ExportFixedFunctionVerifierSolidity(*dataPath, vk, config)
 			if err != nil {
-				log.Error().Msg("failed to generate solidity contract:" + err.Error())
-				os.Exit(1)
+				return fmt.Errorf("failed to generate solidity contract: %w", err)
 			}
 		}
+		return nil
 	}
+}
 
-	if *proofFlag {
+func proveCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	circuitPath := circuitFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	retries := retriesFlag(fs)
+	evmVerifyFile := fs.String("evm-verify", "", "file with the hex creation bytecode of the compiled Verifier.sol to verify the saved proof with in an in-process evm")
+	uploadURL := fs.String("upload-url", "", "resumable upload initiation url to upload the proof artifacts to, with {name} replaced by the file name")
+	archivePath := archiveFlag(fs)
+	receiptSignerSpec := fs.String("receipt-signer", "", "signer of the receipt saved with the proof, see -signer of watch; env:RECEIPT_PRIVATE_KEY if that is set")
+	noOverwrite := fs.Bool("no-overwrite", false, "refuse to overwrite the outputs of a previous proof in the working directory")
+	pipe := fs.Bool("pipe", false, "read the plonky2 proof json from stdin and write the proof bundle json to stdout, with all logs on stderr")
+	gasReport := fs.Bool("gas-report", false, "report the onchain verification gas and calldata bytes of the proof in gas_report.json, requires -evm-verify")
+	logTranscriptFlag := transcriptFlag(fs)
+	return func([]string) error {
+		// In pipe mode stdout only carries the proof bundle, so the logs are moved to stderr
+		// before anything is logged.
+		if *pipe {
+			logger.SetOutput(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "15:04:05"})
+		}
+		log := logger.Logger()
+		if err := requireDataPath(*dataPath); err != nil {
+			return err
+		}
+		if *gasReport && *evmVerifyFile == "" {
+			return fmt.Errorf("-gas-report requires the verifier bytecode of -evm-verify")
+		}
 		log.Info().Msg("loading the plonk proving key, circuit data and verifying key")
-		r1cs, pk, err := LoadProverData(*dataPath)
+		r1cs, pk, err := LoadProverData(*dataPath, *unsafeDeserialize)
+		if err != nil {
+			return fmt.Errorf("failed to load the verifier circuit: %w", err)
+		}
+		vk, err := LoadVerifierKey(*dataPath, *unsafeDeserialize)
 		if err != nil {
-			log.Err(err).Msg("failed to load the verifier circuit")
-			os.Exit(1)
+			return fmt.Errorf("failed to load the verifier key: %w", err)
 		}
-		vk, err := LoadVerifierKey(*dataPath)
+		config, err := ReadCircuitConfig(*dataPath)
 		if err != nil {
-			log.Err(err).Msg("failed to load the verifier key")
-			os.Exit(1)
+			return fmt.Errorf("failed to load the circuit config: %w", err)
 		}
 
-		// If the circuitPath is "" and not provided as part of the CLI flags, then we wait
-		// for user input.
+		if *pipe {
+			log.Info().Msg("Proving the plonky2 proof read from stdin")
+			if err := ProvePipe(os.Stdin, os.Stdout, config, r1cs, pk, vk); err != nil {
+				return fmt.Errorf("failed to create the proof: %w", err)
+			}
+			log.Info().Msg("Successfully wrote the proof bundle to stdout")
+			return nil
+		}
+
+		// If the circuitPath is not provided as a flag, then we wait for user input, so the
+		// artifacts are loaded while the plonky2 proof is generated.
 		if *circuitPath == "" {
 			log.Info().Msg("Waiting for user to provide circuitPath from stdin")
 			reader := bufio.NewReader(os.Stdin)
@@ -80,49 +315,527 @@ func main() {
 			if err != nil {
 				log.Err(err).Msg("failed to parse the user provided circuitPath")
 			}
-			trimmed := strings.TrimSuffix(str, "\n")
-			circuitPath = &trimmed
+			*circuitPath = strings.TrimSuffix(str, "\n")
+		}
+
+		// If a prover key is configured, a signed receipt is saved alongside the proof.
+		var receiptSigner types.Signer
+		if *receiptSignerSpec == "" && os.Getenv("RECEIPT_PRIVATE_KEY") != "" {
+			*receiptSignerSpec = "env:RECEIPT_PRIVATE_KEY"
+		}
+		if *receiptSignerSpec != "" {
+			receiptSigner, err = NewSigner(context.Background(), *receiptSignerSpec)
+			if err != nil {
+				return fmt.Errorf("failed to create the receipt signer: %w", err)
+			}
+		}
+
+		// The archive is opened before proving, so a locked or corrupted archive fails the command
+		// before the proving time is spent.
+		archive, err := openArchive(*archivePath)
+		if err != nil {
+			return err
+		}
+		if archive != nil {
+			defer archive.Close()
 		}
 
 		log.Info().Msg(fmt.Sprintf("Generating the proof with circuitPath %s", *circuitPath))
-		proof, publicWitness, err := Prove(*circuitPath, r1cs, pk)
+		proof, publicWitness, err := Prove(context.Background(), RetryPolicy{MaxAttempts: *retries, Backoff: 5 * time.Second}, *circuitPath, config, r1cs, pk, receiptSigner, !*noOverwrite)
 		if err != nil {
-			log.Err(err).Msg("failed to create the proof")
-			os.Exit(1)
+			if archive != nil && !errors.Is(err, ErrOutputExists) {
+				if archiveErr := archive.Put(ProofRecord{RequestID: *circuitPath, Status: ProofStatusFailed, Error: err.Error()}); archiveErr != nil {
+					log.Err(archiveErr).Msg("failed to archive the proof")
+				}
+			}
+			return fmt.Errorf("failed to create the proof: %w", err)
+		}
+
+		if *logTranscriptFlag {
+			if err := logTranscript(proof, vk, publicWitness, *evmVerifyFile); err != nil {
+				return fmt.Errorf("failed to log the transcript: %w", err)
+			}
 		}
 
 		log.Info().Msg("Verifying proof")
-		err = plonk.Verify(proof, vk, publicWitness)
+		err = VerifyProof(proof, vk, publicWitness)
 		if err != nil {
-			log.Err(err).Msg("failed to verify proof")
-			os.Exit(1)
+			return fmt.Errorf("failed to verify proof: %w", err)
 		}
 		log.Info().Msg("Successfully verified proof")
+
+		if *evmVerifyFile != "" {
+			log.Info().Msg("Verifying proof in evm")
+			report, err := VerifyProofFileInEVM(*evmVerifyFile, publicWitness)
+			if err != nil {
+				return fmt.Errorf("failed to verify proof in evm: %w", err)
+			}
+			log.Info().Msg("Successfully verified proof in evm")
+			if *gasReport {
+				log.Info().Msg(fmt.Sprintf("Verification costs %d gas (%d execution, %d intrinsic) with %d calldata bytes", report.TotalGas, report.ExecutionGas, report.IntrinsicGas, report.CalldataBytes))
+				err = writeFileAtomic(gasReportFileName, !*noOverwrite, func(w io.Writer) error {
+					encoder := json.NewEncoder(w)
+					encoder.SetIndent("", "  ")
+					return encoder.Encode(report)
+				})
+				if err != nil {
+					return fmt.Errorf("failed to save the gas report: %w", err)
+				}
+			}
+		}
+
+		if archive != nil {
+			err = archiveProof(archive, *circuitPath, publicWitness, receiptSigner != nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Large artifacts are uploaded in resumable chunks. The access token is read from the
+		// UPLOAD_TOKEN environment variable.
+		if *uploadURL != "" {
+			uploader := NewResumableUploader(os.Getenv("UPLOAD_TOKEN"))
+			files := proofFiles
+			if receiptSigner != nil {
+				files = append(files[:len(files):len(files)], "receipt.json")
+			}
+			err = uploader.UploadArtifacts(context.Background(), *uploadURL, files)
+			if err != nil {
+				return fmt.Errorf("failed to upload the proof artifacts: %w", err)
+			}
+		}
+		return nil
 	}
+}
 
-	if *verifyFlag {
+func verifyCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	circuitPath := circuitFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	logTranscriptFlag := transcriptFlag(fs)
+	bytecodeFile := fs.String("bytecode", "", "file with the hex creation bytecode of the compiled Verifier.sol, whose transcript is logged and compared with -log-transcript")
+	return func([]string) error {
+		log := logger.Logger()
+		if err := requireDataPath(*dataPath); err != nil {
+			return err
+		}
 		log.Info().Msg("loading the proof, verifying key and public inputs")
-		vk, err := LoadVerifierKey(*dataPath)
+		vk, err := LoadVerifierKey(*dataPath, *unsafeDeserialize)
 		if err != nil {
-			log.Err(err).Msg("failed to load the verifier key")
-			os.Exit(1)
+			return fmt.Errorf("failed to load the verifier key: %w", err)
 		}
 		publicWitness, err := LoadPublicWitness(*circuitPath)
 		if err != nil {
-			log.Err(err).Msg("failed to load the public witness")
-			os.Exit(1)
+			return fmt.Errorf("failed to load the public witness: %w", err)
 		}
-
 		proof, err := LoadProof()
 		if err != nil {
-			log.Err(err).Msg("failed to load the proof")
-			os.Exit(1)
+			return fmt.Errorf("failed to load the proof: %w", err)
+		}
+		if *logTranscriptFlag {
+			if err := logTranscript(proof, vk, publicWitness, *bytecodeFile); err != nil {
+				return fmt.Errorf("failed to log the transcript: %w", err)
+			}
 		}
-		err = plonk.Verify(proof, vk, publicWitness)
+		err = VerifyProof(proof, vk, publicWitness)
 		if err != nil {
-			log.Err(err).Msg("failed to verify proof")
-			os.Exit(1)
+			return fmt.Errorf("failed to verify proof: %w", err)
 		}
 		log.Info().Msg("Successfully verified proof")
+		return nil
+	}
+}
+
+func serveCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	functions := functionsFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	retries := retriesFlag(fs)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	proofsDir := fs.String("proofs", "", "directory the plonky2 proofs of proving requests are read from, in a folder named by the requestId")
+	rate := fs.Float64("rate", 0, "proving requests per second allowed per client, 0 for no limit")
+	burst := fs.Int("burst", 1, "proving requests a client may burst above the rate")
+	maxConcurrent := fs.Int("max-concurrent", 0, "in-flight proving requests allowed per client, 0 for no limit")
+	tlsCert := fs.String("tls-cert", "", "tls certificate file to serve with")
+	tlsKey := fs.String("tls-key", "", "tls private key file to serve with")
+	tlsClientCA := fs.String("tls-client-ca", "", "ca file to verify client certificates with, enables mutual tls")
+	apiKeysFile := fs.String("api-keys", "", "file of client:key api keys accepted")
+	return func([]string) error {
+		if *tlsCert == "" && *tlsClientCA != "" {
+			return errors.New("-tls-client-ca requires -tls-cert, as client certificates are only verified over tls")
+		}
+		if *functions == "" {
+			if err := requireDataPath(*dataPath); err != nil {
+				return err
+			}
+		}
+		if *proofsDir == "" {
+			return errors.New("please specify the directory of the plonky2 proofs with -proofs")
+		}
+		registry, err := newRegistry(*functions, *dataPath, *unsafeDeserialize)
+		if err != nil {
+			return fmt.Errorf("failed to register circuits: %w", err)
+		}
+		server := NewProverServer(registry)
+		server.ProofsDir = *proofsDir
+		server.Retry = RetryPolicy{MaxAttempts: *retries, Backoff: 5 * time.Second}
+		if *tlsCert != "" {
+			server.TLSConfig, err = LoadTLSConfig(*tlsCert, *tlsKey, *tlsClientCA)
+			if err != nil {
+				return fmt.Errorf("failed to load tls config: %w", err)
+			}
+		}
+		// Requests are authenticated if any credentials are configured. JWTs are signed with the
+		// JWT_SECRET environment variable.
+		jwtSecret := os.Getenv("JWT_SECRET")
+		if *apiKeysFile != "" || jwtSecret != "" || *tlsClientCA != "" {
+			server.Auth = NewAuthenticator()
+			server.Auth.JWTSecret = []byte(jwtSecret)
+			if *apiKeysFile != "" {
+				err = server.Auth.LoadAPIKeys(*apiKeysFile)
+				if err != nil {
+					return fmt.Errorf("failed to load api keys: %w", err)
+				}
+			}
+		}
+		if *rate > 0 || *maxConcurrent > 0 {
+			server.Limiter = NewRateLimiter(*rate, *burst, *maxConcurrent)
+		}
+		err = server.ListenAndServe(*addr)
+		if err != nil {
+			return fmt.Errorf("prover server stopped: %w", err)
+		}
+		return nil
+	}
+}
+
+func watchCommand(fs *flag.FlagSet) func([]string) error {
+	functions := functionsFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	retries := retriesFlag(fs)
+	rpcURL := fs.String("rpc", "", "websocket rpc url of the chain the gateway is deployed on")
+	gatewayAddress := fs.String("gateway", "", "address of the SuccinctGateway contract")
+	artifactsPath := fs.String("artifacts", "", "directory containing the plonky2 proofs for gateway requests")
+	signerSpec := fs.String("signer", "env:PRIVATE_KEY", "signer of the fulfillment transactions: env:<VAR>, keystore:<path>, aws-kms:<key id> or gcp-kms:<key version>")
+	return func([]string) error {
+		ctx := context.Background()
+		// Requests name the function they are for, so each one must be proven with its own
+		// circuit rather than a single default one.
+		if *functions == "" {
+			return errors.New("please specify the circuits of the gateway functions with -functions")
+		}
+		if !common.IsHexAddress(*gatewayAddress) {
+			return errors.New("please specify a valid gateway address")
+		}
+		registry, err := newRegistry(*functions, "", *unsafeDeserialize)
+		if err != nil {
+			return fmt.Errorf("failed to register circuits: %w", err)
+		}
+		err = registry.LoadAll()
+		if err != nil {
+			return fmt.Errorf("failed to load circuits: %w", err)
+		}
+		signer, err := NewSigner(ctx, *signerSpec)
+		if err != nil {
+			return fmt.Errorf("failed to create the operator signer: %w", err)
+		}
+		client, err := ethclient.DialContext(ctx, *rpcURL)
+		if err != nil {
+			return fmt.Errorf("failed to dial rpc: %w", err)
+		}
+		fulfiller, err := NewFulfiller(ctx, client, common.HexToAddress(*gatewayAddress), signer)
+		if err != nil {
+			return fmt.Errorf("failed to create fulfiller: %w", err)
+		}
+		watcher, err := NewWatcher(common.HexToAddress(*gatewayAddress), client, registry, DirectoryArtifactSource{Root: *artifactsPath}, fulfiller)
+		if err != nil {
+			return fmt.Errorf("failed to create watcher: %w", err)
+		}
+		watcher.Retry.MaxAttempts = *retries
+		err = watcher.Run(ctx)
+		if err != nil {
+			return fmt.Errorf("watcher stopped: %w", err)
+		}
+		return nil
+	}
+}
+
+func watchDirCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	retries := retriesFlag(fs)
+	input := fs.String("input", "", "directory the plonky2 proofs are dropped into, each in a folder with its verifier data")
+	output := fs.String("output", "", "directory the proofs are written to, in a folder named like the drop")
+	poll := fs.Duration("poll", 5*time.Second, "interval the input directory is scanned at")
+	archivePath := archiveFlag(fs)
+	return func([]string) error {
+		if err := requireDataPath(*dataPath); err != nil {
+			return err
+		}
+		if *input == "" || *output == "" {
+			return errors.New("please specify the input and output directories")
+		}
+		r1cs, pk, err := LoadProverData(*dataPath, *unsafeDeserialize)
+		if err != nil {
+			return fmt.Errorf("failed to load the verifier circuit: %w", err)
+		}
+		vk, err := LoadVerifierKey(*dataPath, *unsafeDeserialize)
+		if err != nil {
+			return fmt.Errorf("failed to load the verifier key: %w", err)
+		}
+		watcher := NewDirectoryWatcher(*input, *output, r1cs, pk, vk)
+		watcher.Config, err = ReadCircuitConfig(*dataPath)
+		if err != nil {
+			return fmt.Errorf("failed to load the circuit config: %w", err)
+		}
+		watcher.PollInterval = *poll
+		watcher.Retry = RetryPolicy{MaxAttempts: *retries, Backoff: 5 * time.Second}
+		watcher.Archive, err = openArchive(*archivePath)
+		if err != nil {
+			return err
+		}
+		if watcher.Archive != nil {
+			defer watcher.Archive.Close()
+		}
+		err = watcher.Run(context.Background())
+		if err != nil {
+			return fmt.Errorf("directory watcher stopped: %w", err)
+		}
+		return nil
+	}
+}
+
+func exportCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	out := fs.String("out", "", "directory to export the verifying key to")
+	format := fs.String("format", "all", "format of the exported verifying key: binary, json, solidity, gateway or all")
+	return func([]string) error {
+		if err := requireDataPath(*dataPath); err != nil {
+			return err
+		}
+		if *out == "" {
+			return errors.New("please specify the directory to export the verifying key to")
+		}
+		vk, err := LoadVerifierKey(*dataPath, *unsafeDeserialize)
+		if err != nil {
+			return fmt.Errorf("failed to load the verifier key: %w", err)
+		}
+		err = ExportVerifyingKey(*out, vk, *format)
+		if err != nil {
+			return fmt.Errorf("failed to export the verifying key: %w", err)
+		}
+		return nil
+	}
+}
+
+func benchCommand(fs *flag.FlagSet) func([]string) error {
+	circuitPath := circuitFlag(fs)
+	fixedDigest := fixedDigestFlag(fs)
+	runs := fs.Int("runs", 5, "number of runs of each stage")
+	return func([]string) error {
+		if *circuitPath == "" {
+			return errors.New("please specify the circuit path of the proof to benchmark")
+		}
+		report, err := BenchmarkPipeline(dummyCircuitPath, *circuitPath, *fixedDigest, *runs)
+		if err != nil {
+			return fmt.Errorf("failed to run benchmark: %w", err)
+		}
+		return printJSON(report)
+	}
+}
+
+func statsCommand(fs *flag.FlagSet) func([]string) error {
+	fixedDigest := fixedDigestFlag(fs)
+	calibrate := fs.Bool("calibrate", false, "prove a small circuit to estimate the proving time")
+	return func([]string) error {
+		stats, err := ComputeCircuitStats(dummyCircuitPath, *fixedDigest, 25, *calibrate)
+		if err != nil {
+			return fmt.Errorf("failed to compute circuit stats: %w", err)
+		}
+		return printJSON(stats)
+	}
+}
+
+func fingerprintCommand(fs *flag.FlagSet) func([]string) error {
+	fixedDigest := fixedDigestFlag(fs)
+	return func([]string) error {
+		r1cs, err := CompileConstraintSystem(dummyCircuitPath, *fixedDigest)
+		if err != nil {
+			return fmt.Errorf("failed to compile verifier circuit: %w", err)
+		}
+		fingerprint, err := ComputeFingerprint(r1cs)
+		if err != nil {
+			return fmt.Errorf("failed to compute fingerprint: %w", err)
+		}
+		return printJSON(fingerprint)
+	}
+}
+
+func registrationCommand(fs *flag.FlagSet) func([]string) error {
+	dataPath := dataFlag(fs)
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	gatewayAddress := fs.String("gateway", "", "address of the SuccinctGateway contract")
+	owner := fs.String("owner", "", "owner of the function to compute the registration of")
+	salt := fs.String("salt", "", "salt of the function to compute the registration of")
+	bytecodeFile := fs.String("bytecode", "", "file with the hex creation bytecode of the function verifier to compute the registration of")
+	rpcURL := fs.String("rpc", "", "rpc url of the chain the gateway is deployed on, to check the registration against")
+	return func([]string) error {
+		log := logger.Logger()
+		if !common.IsHexAddress(*gatewayAddress) || !common.IsHexAddress(*owner) {
+			return errors.New("please specify valid gateway and owner addresses")
+		}
+		var bytecode []byte
+		if *bytecodeFile != "" {
+			data, err := os.ReadFile(*bytecodeFile)
+			if err != nil {
+				return fmt.Errorf("failed to read the bytecode: %w", err)
+			}
+			bytecode = common.FromHex(strings.TrimSpace(string(data)))
+		}
+		var vk plonk.VerifyingKey
+		if *dataPath != "" {
+			var err error
+			vk, err = LoadVerifierKey(*dataPath, *unsafeDeserialize)
+			if err != nil {
+				return fmt.Errorf("failed to load the verifier key: %w", err)
+			}
+		}
+		gateway := common.HexToAddress(*gatewayAddress)
+		registration, err := PredictRegistration(gateway, common.HexToAddress(*owner), common.HexToHash(*salt), bytecode, vk)
+		if err != nil {
+			return fmt.Errorf("failed to compute the registration: %w", err)
+		}
+		if err := printJSON(registration); err != nil {
+			return err
+		}
+		if *rpcURL == "" {
+			return nil
+		}
+		client, err := ethclient.DialContext(context.Background(), *rpcURL)
+		if err != nil {
+			return fmt.Errorf("failed to dial rpc: %w", err)
+		}
+		err = CheckRegistration(context.Background(), client, gateway, registration)
+		if err != nil {
+			return fmt.Errorf("registration does not match the gateway: %w", err)
+		}
+		log.Info().Msg("Registration matches the gateway")
+		return nil
+	}
+}
+
+func migrateCommand(fs *flag.FlagSet) func([]string) error {
+	unsafeDeserialize := unsafeDeserializeFlag(fs)
+	oldData := fs.String("old", "", "data directory of the deployed circuit")
+	newData := fs.String("new", "", "data directory of the upgraded circuit")
+	oldCircuit := fs.String("old-circuit", "", "plonky2 circuit path the deployed circuit was compiled for, to compare circuit digests")
+	newCircuit := fs.String("new-circuit", "", "plonky2 circuit path the upgraded circuit was compiled for, to compare circuit digests")
+	strict := fs.Bool("strict", false, "fail if the upgrade is incompatible with the deployed verifiers, for release gating")
+	return func([]string) error {
+		if *oldData == "" || *newData == "" {
+			return errors.New("please specify the old and new data directories")
+		}
+		before, err := ReadCircuitArtifacts(*oldData, *oldCircuit, *unsafeDeserialize)
+		if err != nil {
+			return err
+		}
+		after, err := ReadCircuitArtifacts(*newData, *newCircuit, *unsafeDeserialize)
+		if err != nil {
+			return err
+		}
+		report := CompareCircuits(before, after)
+		if err := printJSON(report); err != nil {
+			return err
+		}
+		if *strict && !report.Compatible {
+			return ErrIncompatibleUpgrade
+		}
+		return nil
+	}
+}
+
+func listCommand(fs *flag.FlagSet) func([]string) error {
+	archivePath := archiveFlag(fs)
+	status := fs.String("status", "", "only list the proofs with the status, proven or failed")
+	return func([]string) error {
+		if *archivePath == "" {
+			return errors.New("please specify the proof archive")
+		}
+		archive, err := OpenProofArchive(*archivePath)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		records, err := archive.List(*status)
+		if err != nil {
+			return err
+		}
+		return printJSON(records)
+	}
+}
+
+func getCommand(fs *flag.FlagSet) func([]string) error {
+	archivePath := archiveFlag(fs)
+	return func(args []string) error {
+		if *archivePath == "" {
+			return errors.New("please specify the proof archive")
+		}
+		if len(args) != 1 {
+			return errors.New("please specify the input hash or request id to look up")
+		}
+		archive, err := OpenProofArchive(*archivePath)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		records, err := archive.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		return printJSON(records)
+	}
+}
+
+func completionCommand(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errors.New("please specify the shell to print the completion script for: bash, zsh or fish")
+		}
+		script, err := CompletionScript(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	}
+}
+
+// archiveProof records the proof saved to the working directory by the prove command.
+func archiveProof(archive *ProofArchive, circuitPath string, publicWitness witness.Witness, withReceipt bool) error {
+	inputHash, err := publicInputHash(publicWitness)
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get the working directory: %w", err)
+	}
+	files := proofFiles
+	if withReceipt {
+		files = append(files[:len(files):len(files)], "receipt.json")
+	}
+	return archive.Put(ProofRecord{RequestID: circuitPath, InputHash: inputHash.Hex(), Status: ProofStatusProven, Dir: dir, Files: files})
+}
+
+// newRegistry registers the circuits given by the -functions flag, or the single circuit in
+// dataPath if the flag is empty.
+func newRegistry(functions string, dataPath string, unsafeDeserialize bool) (*CircuitRegistry, error) {
+	registry := NewCircuitRegistry()
+	registry.UnsafeDeserialize = unsafeDeserialize
+	if functions == "" {
+		_, err := registry.Register("default", dataPath, dummyCircuitPath)
+		return registry, err
 	}
+	return registry, registry.ParseFunctionSpecs(functions, dummyCircuitPath)
 }
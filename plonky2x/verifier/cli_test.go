@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindEnv(t *testing.T) {
+	cmd, ok := findCommand("serve")
+	assert.True(t, ok)
+	fs, _ := newFlagSet(cmd)
+	t.Setenv("VERIFIER_ADDR", ":9090")
+	t.Setenv("VERIFIER_DATA", "./env-data")
+	t.Setenv("VERIFIER_UNSAFE_DESERIALIZE", "true")
+
+	// Flags given on the command line take precedence over the environment.
+	assert.NoError(t, fs.Parse([]string{"-data", "./flag-data"}))
+	assert.NoError(t, bindEnv(fs))
+	assert.Equal(t, ":9090", fs.Lookup("addr").Value.String())
+	assert.Equal(t, "./flag-data", fs.Lookup("data").Value.String())
+	assert.Equal(t, "true", fs.Lookup("unsafe-deserialize").Value.String())
+
+	fs, _ = newFlagSet(cmd)
+	t.Setenv("VERIFIER_RATE", "fast")
+	assert.NoError(t, fs.Parse(nil))
+	assert.ErrorContains(t, bindEnv(fs), "VERIFIER_RATE")
+}
+
+func TestRunCommand(t *testing.T) {
+	var stderr bytes.Buffer
+	assert.ErrorContains(t, run([]string{"compile"}, &stderr), "unknown command")
+	assert.Contains(t, stderr.String(), "setup")
+
+	assert.NoError(t, run([]string{"prove", "-h"}, &stderr))
+	assert.Contains(t, stderr.String(), "-circuit")
+
+	assert.ErrorContains(t, run([]string{"verify"}, &stderr), "data dir")
+	assert.ErrorContains(t, run([]string{"serve", "-tls-client-ca", "ca.pem"}, &stderr), "requires -tls-cert")
+	assert.Error(t, run([]string{"completion", "powershell"}, &stderr))
+}
+
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script, err := CompletionScript(shell)
+		assert.NoError(t, err)
+		for _, cmd := range commands() {
+			assert.Contains(t, script, cmd.name)
+			fs, _ := newFlagSet(cmd)
+			fs.VisitAll(func(f *flag.Flag) {
+				assert.Contains(t, script, f.Name)
+			})
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/succinctlabs/gnark-plonky2-verifier/plonk/gates"
+	"github.com/succinctlabs/gnark-plonky2-verifier/types"
+)
+
+// commonCircuitDataRaw mirrors the serialization of plonky2's CommonCircuitData. Unlike the raw type
+// of gnark-plonky2-verifier, it accepts every FriReductionStrategy variant of newer plonky2 versions.
+// Their lookup fields are only parsed to reject the circuits which use lookups: the verifier circuit
+// of gnark-plonky2-verifier does not implement the lookup argument, so such circuits are rejected
+// with a clear error instead of a panic or an unsatisfiable witness.
+type commonCircuitDataRaw struct {
+	Config struct {
+		NumWires                uint64       `json:"num_wires"`
+		NumRoutedWires          uint64       `json:"num_routed_wires"`
+		NumConstants            uint64       `json:"num_constants"`
+		UseBaseArithmeticGate   bool         `json:"use_base_arithmetic_gate"`
+		SecurityBits            uint64       `json:"security_bits"`
+		NumChallenges           uint64       `json:"num_challenges"`
+		ZeroKnowledge           bool         `json:"zero_knowledge"`
+		MaxQuotientDegreeFactor uint64       `json:"max_quotient_degree_factor"`
+		FriConfig               friConfigRaw `json:"fri_config"`
+	} `json:"config"`
+	FriParams struct {
+		Config             friConfigRaw `json:"config"`
+		Hiding             bool         `json:"hiding"`
+		DegreeBits         uint64       `json:"degree_bits"`
+		ReductionArityBits []uint64     `json:"reduction_arity_bits"`
+	} `json:"fri_params"`
+	Gates         []string `json:"gates"`
+	SelectorsInfo struct {
+		SelectorIndices []uint64 `json:"selector_indices"`
+		Groups          []struct {
+			Start uint64 `json:"start"`
+			End   uint64 `json:"end"`
+		} `json:"groups"`
+	} `json:"selectors_info"`
+	QuotientDegreeFactor uint64   `json:"quotient_degree_factor"`
+	NumGateConstraints   uint64   `json:"num_gate_constraints"`
+	NumConstants         uint64   `json:"num_constants"`
+	NumPublicInputs      uint64   `json:"num_public_inputs"`
+	KIs                  []uint64 `json:"k_is"`
+	NumPartialProducts   uint64   `json:"num_partial_products"`
+
+	// Lookup arguments, added in newer plonky2 versions, which cannot be verified.
+	NumLookupPolys     uint64            `json:"num_lookup_polys"`
+	NumLookupSelectors uint64            `json:"num_lookup_selectors"`
+	Luts               []json.RawMessage `json:"luts"`
+}
+
+type friConfigRaw struct {
+	RateBits        uint64 `json:"rate_bits"`
+	CapHeight       uint64 `json:"cap_height"`
+	ProofOfWorkBits uint64 `json:"proof_of_work_bits"`
+	NumQueryRounds  uint64 `json:"num_query_rounds"`
+
+	// The reduction strategy is only used to compute the reduction arity bits, which are serialized
+	// in the FRI params, so any variant (ConstantArityBits, Fixed, MinSize) is accepted.
+	ReductionStrategy json.RawMessage `json:"reduction_strategy"`
+}
+
+func (c friConfigRaw) friConfig() types.FriConfig {
+	return types.FriConfig{
+		RateBits:        c.RateBits,
+		CapHeight:       c.CapHeight,
+		ProofOfWorkBits: c.ProofOfWorkBits,
+		NumQueryRounds:  c.NumQueryRounds,
+	}
+}
+
+// ReadCommonCircuitData reads and validates the common circuit data of a plonky2 circuit.
+func ReadCommonCircuitData(path string) (types.CommonCircuitData, error) {
+	var commonCircuitData types.CommonCircuitData
+
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		return commonCircuitData, fmt.Errorf("failed to read common circuit data: %w", missingArtifact(err))
+	}
+	var raw commonCircuitDataRaw
+	err = json.Unmarshal(rawBytes, &raw)
+	if err != nil {
+		return commonCircuitData, fmt.Errorf("failed to parse common circuit data: %w", serializationError(err))
+	}
+	err = raw.validate()
+	if err != nil {
+		return commonCircuitData, fmt.Errorf("unsupported plonky2 circuit: %w", err)
+	}
+
+	commonCircuitData.Config = types.CircuitConfig{
+		NumWires:                raw.Config.NumWires,
+		NumRoutedWires:          raw.Config.NumRoutedWires,
+		NumConstants:            raw.Config.NumConstants,
+		UseBaseArithmeticGate:   raw.Config.UseBaseArithmeticGate,
+		SecurityBits:            raw.Config.SecurityBits,
+		NumChallenges:           raw.Config.NumChallenges,
+		ZeroKnowledge:           raw.Config.ZeroKnowledge,
+		MaxQuotientDegreeFactor: raw.Config.MaxQuotientDegreeFactor,
+		FriConfig:               raw.Config.FriConfig.friConfig(),
+	}
+	commonCircuitData.FriParams = types.FriParams{
+		Config:             raw.FriParams.Config.friConfig(),
+		Hiding:             raw.FriParams.Hiding,
+		DegreeBits:         raw.FriParams.DegreeBits,
+		ReductionArityBits: raw.FriParams.ReductionArityBits,
+	}
+	commonCircuitData.DegreeBits = raw.FriParams.DegreeBits
+	commonCircuitData.GateIds = raw.Gates
+
+	selectorGroupStart := []uint64{}
+	selectorGroupEnd := []uint64{}
+	for _, group := range raw.SelectorsInfo.Groups {
+		selectorGroupStart = append(selectorGroupStart, group.Start)
+		selectorGroupEnd = append(selectorGroupEnd, group.End)
+	}
+	commonCircuitData.SelectorsInfo = *gates.NewSelectorsInfo(
+		raw.SelectorsInfo.SelectorIndices,
+		selectorGroupStart,
+		selectorGroupEnd,
+	)
+
+	commonCircuitData.QuotientDegreeFactor = raw.QuotientDegreeFactor
+	commonCircuitData.NumGateConstraints = raw.NumGateConstraints
+	commonCircuitData.NumConstants = raw.NumConstants
+	commonCircuitData.NumPublicInputs = raw.NumPublicInputs
+	commonCircuitData.KIs = raw.KIs
+	commonCircuitData.NumPartialProducts = raw.NumPartialProducts
+
+	return commonCircuitData, nil
+}
+
+// validate rejects the features the in-circuit verifier does not implement, i.e. zero knowledge,
+// lookups and gates it does not know. Verifying lookups needs the lookup argument in the verifier of
+// gnark-plonky2-verifier, so plonky2 circuits must be built without lookup tables to be wrapped.
+func (raw *commonCircuitDataRaw) validate() error {
+	if raw.FriParams.Hiding || raw.Config.ZeroKnowledge {
+		return fmt.Errorf("zero knowledge (hiding) circuits are not supported")
+	}
+	if raw.NumLookupPolys > 0 || raw.NumLookupSelectors > 0 || len(raw.Luts) > 0 {
+		return fmt.Errorf("lookup tables are not supported, the verifier circuit does not implement the lookup argument (%d luts, %d lookup polynomials)", len(raw.Luts), raw.NumLookupPolys)
+	}
+	if len(raw.SelectorsInfo.SelectorIndices) != len(raw.Gates) {
+		return fmt.Errorf("%d gates but %d selector indices", len(raw.Gates), len(raw.SelectorsInfo.SelectorIndices))
+	}
+	for _, gateID := range raw.Gates {
+		if err := validateGate(gateID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateGate(gateID string) (err error) {
+	if strings.HasPrefix(gateID, "LookupGate") || strings.HasPrefix(gateID, "LookupTableGate") {
+		return fmt.Errorf("lookup gate %q is not supported, the verifier circuit does not implement the lookup argument", gateID)
+	}
+	// The gate parser panics on unknown or malformed gate IDs.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gate %q is not supported: %v", gateID, r)
+		}
+	}()
+	gates.GateInstanceFromId(gateID)
+	return nil
+}
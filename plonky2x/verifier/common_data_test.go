@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/succinctlabs/gnark-plonky2-verifier/types"
+)
+
+func TestReadCommonCircuitData(t *testing.T) {
+	path := "./testdata/common_circuit_data.json"
+	commonCircuitData, err := ReadCommonCircuitData(path)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReadCommonCircuitData(path), commonCircuitData)
+
+	rawBytes, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	read := func(modify func(raw map[string]interface{})) (types.CommonCircuitData, error) {
+		// Numbers are kept as json.Number so that the k_is keep their precision.
+		var raw map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(rawBytes))
+		decoder.UseNumber()
+		assert.NoError(t, decoder.Decode(&raw))
+		modify(raw)
+		modified, err := json.Marshal(raw)
+		assert.NoError(t, err)
+		modifiedPath := filepath.Join(t.TempDir(), "common_circuit_data.json")
+		assert.NoError(t, os.WriteFile(modifiedPath, modified, 0644))
+		return ReadCommonCircuitData(modifiedPath)
+	}
+	addGate := func(gateID string) func(raw map[string]interface{}) {
+		return func(raw map[string]interface{}) {
+			raw["gates"] = append(raw["gates"].([]interface{}), gateID)
+			selectors := raw["selectors_info"].(map[string]interface{})
+			selectors["selector_indices"] = append(selectors["selector_indices"].([]interface{}), 0)
+		}
+	}
+
+	// Newer reduction strategy variants are accepted.
+	modified, err := read(func(raw map[string]interface{}) {
+		raw["config"].(map[string]interface{})["fri_config"].(map[string]interface{})["reduction_strategy"] = "MinSize"
+		raw["num_lookup_polys"] = 0
+		raw["luts"] = []interface{}{}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, commonCircuitData, modified)
+
+	// Lookups and unknown gates are rejected with a clear error.
+	_, err = read(func(raw map[string]interface{}) {
+		raw["num_lookup_polys"] = 4
+		raw["luts"] = []interface{}{[]interface{}{[]interface{}{0, 1}}}
+	})
+	assert.ErrorContains(t, err, "lookup tables are not supported")
+	_, err = read(addGate("LookupGate { num_slots: 26, lut_hash: [0, 0, 0, 0] }"))
+	assert.ErrorContains(t, err, "lookup gate")
+	_, err = read(addGate("U32AddManyGate { num_addends: 3, num_ops: 5 }"))
+	assert.ErrorContains(t, err, "U32AddManyGate")
+
+	_, err = read(func(raw map[string]interface{}) {
+		raw["fri_params"].(map[string]interface{})["hiding"] = true
+	})
+	assert.ErrorContains(t, err, "zero knowledge")
+}
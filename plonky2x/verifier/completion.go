@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// CompletionScript returns the script completing the commands of the verifier and their flags in
+// the shell, i.e. bash, zsh or fish, e.g. source <(verifier completion bash).
+func CompletionScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion(), nil
+	case "zsh":
+		// zsh runs the bash completion through its bash compatibility layer.
+		return "autoload -U +X bashcompinit && bashcompinit\n" + bashCompletion(), nil
+	case "fish":
+		return fishCompletion(), nil
+	}
+	return "", fmt.Errorf("unknown shell %q, expected bash, zsh or fish", shell)
+}
+
+// commandFlags returns the flags of the command.
+func commandFlags(cmd command) []*flag.Flag {
+	fs, _ := newFlagSet(cmd)
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+func bashCompletion() string {
+	var b strings.Builder
+	var names []string
+	for _, cmd := range commands() {
+		names = append(names, cmd.name)
+	}
+	b.WriteString("_verifier() {\n")
+	b.WriteString("    local cur=${COMP_WORDS[COMP_CWORD]}\n")
+	b.WriteString("    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(names, " "))
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    case ${COMP_WORDS[1]} in\n")
+	for _, cmd := range commands() {
+		var flags []string
+		for _, f := range commandFlags(cmd) {
+			flags = append(flags, "-"+f.Name)
+		}
+		if cmd.name == "completion" {
+			flags = []string{"bash", "zsh", "fish"}
+		}
+		fmt.Fprintf(&b, "    %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", cmd.name, strings.Join(flags, " "))
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	// Values of flags, e.g. directories, fall back to the default file completion.
+	b.WriteString("complete -o default -F _verifier verifier\n")
+	return b.String()
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	for _, cmd := range commands() {
+		fmt.Fprintf(&b, "complete -c verifier -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(cmd.summary))
+	}
+	for _, cmd := range commands() {
+		condition := fishQuote("__fish_seen_subcommand_from " + cmd.name)
+		if cmd.name == "completion" {
+			fmt.Fprintf(&b, "complete -c verifier -n %s -a \"bash zsh fish\"\n", condition)
+		}
+		for _, f := range commandFlags(cmd) {
+			// Go flags are long options with a single dash, which are old style options in fish.
+			requiresValue := " -r"
+			if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+				requiresValue = ""
+			}
+			fmt.Fprintf(&b, "complete -c verifier -n %s -o %s%s -d %s\n", condition, f.Name, requiresValue, fishQuote(f.Usage))
+		}
+	}
+	return b.String()
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'"
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+
+	gl "github.com/succinctlabs/gnark-plonky2-verifier/goldilocks"
+	"github.com/succinctlabs/gnark-plonky2-verifier/types"
+	"github.com/succinctlabs/gnark-plonky2-verifier/variables"
+)
+
+// DeserializeProofWithPublicInputs converts a raw plonky2 proof to the circuit variables, like
+// variables.DeserializeProofWithPublicInputs. The FRI query rounds, which make up most of the
+// proof, are converted in parallel on every available core so that witness assignment does not
+// become the bottleneck for large proofs.
+func DeserializeProofWithPublicInputs(raw types.ProofWithPublicInputsRaw) variables.ProofWithPublicInputs {
+	var proofWithPis variables.ProofWithPublicInputs
+	proof := &proofWithPis.Proof
+	proof.WiresCap = variables.DeserializeMerkleCap(raw.Proof.WiresCap)
+	proof.PlonkZsPartialProductsCap = variables.DeserializeMerkleCap(raw.Proof.PlonkZsPartialProductsCap)
+	proof.QuotientPolysCap = variables.DeserializeMerkleCap(raw.Proof.QuotientPolysCap)
+	proof.Openings = variables.DeserializeOpeningSet(struct {
+		Constants       [][]uint64
+		PlonkSigmas     [][]uint64
+		Wires           [][]uint64
+		PlonkZs         [][]uint64
+		PlonkZsNext     [][]uint64
+		PartialProducts [][]uint64
+		QuotientPolys   [][]uint64
+	}(raw.Proof.Openings))
+
+	rawOpeningProof := raw.Proof.OpeningProof
+	openingProof := &proof.OpeningProof
+	openingProof.PowWitness = gl.NewVariable(rawOpeningProof.PowWitness)
+	openingProof.FinalPoly.Coeffs = gl.Uint64ArrayToQuadraticExtensionArray(rawOpeningProof.FinalPoly.Coeffs)
+	openingProof.CommitPhaseMerkleCaps = make([]variables.FriMerkleCap, len(rawOpeningProof.CommitPhaseMerkleCaps))
+	for i, merkleCap := range rawOpeningProof.CommitPhaseMerkleCaps {
+		openingProof.CommitPhaseMerkleCaps[i] = variables.StringArrayToHashBN254Array(merkleCap)
+	}
+
+	openingProof.QueryRoundProofs = make([]variables.FriQueryRound, len(rawOpeningProof.QueryRoundProofs))
+	parallelFor(len(rawOpeningProof.QueryRoundProofs), func(i int) {
+		openingProof.QueryRoundProofs[i] = deserializeQueryRound(rawOpeningProof.QueryRoundProofs[i])
+	})
+
+	proofWithPis.PublicInputs = gl.Uint64ArrayToVariableArray(raw.PublicInputs)
+	return proofWithPis
+}
+
+func deserializeQueryRound(raw queryRoundRaw) variables.FriQueryRound {
+	var round variables.FriQueryRound
+	round.InitialTreesProof.EvalsProofs = make([]variables.FriEvalProof, len(raw.InitialTreesProof.EvalsProofs))
+	for i, evalProof := range raw.InitialTreesProof.EvalsProofs {
+		round.InitialTreesProof.EvalsProofs[i].Elements = gl.Uint64ArrayToVariableArray(evalProof.LeafElements)
+		round.InitialTreesProof.EvalsProofs[i].MerkleProof.Siblings = variables.StringArrayToHashBN254Array(evalProof.MerkleProof.Hash)
+	}
+	round.Steps = make([]variables.FriQueryStep, len(raw.Steps))
+	for i, step := range raw.Steps {
+		round.Steps[i].Evals = gl.Uint64ArrayToQuadraticExtensionArray(step.Evals)
+		round.Steps[i].MerkleProof.Siblings = variables.StringArrayToHashBN254Array(step.MerkleProof.Siblings)
+	}
+	return round
+}
+
+// parallelFor calls f for every index in [0, n) on GOMAXPROCS workers. A panic of f, e.g. on a
+// malformed proof, stops its worker and is raised again on the calling goroutine once all workers
+// are done, so that callers can recover from it like from a panic of a sequential loop.
+func parallelFor(n int, f func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked any
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicked = r })
+				}
+			}()
+			for i := w; i < n; i += workers {
+				f(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// The folders of the input directory of a DirectoryWatcher the drops are moved to once they are
+// processed.
+const (
+	dropsDoneDir   = "done"
+	dropsFailedDir = "failed"
+)
+
+// The file the error of a drop which cannot be proven is written to, in its folder in failed/.
+const dropErrorFile = "error.txt"
+
+// DirectoryWatcher proves the plonky2 proofs dropped into an input directory, for batch pipelines
+// which exchange files rather than requests. A drop is a circuit path, i.e. a folder with the
+// proof_with_public_inputs.json and the verifier data of a plonky2 proof, and is picked up once its
+// proof is there, so drops should be written elsewhere and then moved into the input directory.
+//
+// The proof of a drop is saved like with SaveProof to the folder of the same name in the output
+// directory, and the drop is then moved to done/ in the input directory. Drops which can never be
+// proven are moved to failed/, with the error in error.txt. Drops which fail for transient
+// reasons, e.g. of the prover or the disk, are left in place, and retried in the next poll.
+type DirectoryWatcher struct {
+	Input  string
+	Output string
+
+	// The delay between two scans of the input directory.
+	PollInterval time.Duration
+
+	// Retry retries proofs which fail for transient reasons within a poll.
+	Retry RetryPolicy
+
+	// Archive records the proven and unprovable drops by their names. If nil, drops are not
+	// recorded.
+	Archive *ProofArchive
+
+	// Config is the configuration the verifier circuit was compiled with, drops of plonky2 proofs
+	// it was not compiled for are failed.
+	Config CircuitConfig
+
+	// prove proves the drop in circuitPath, saves the proof to dir and returns its input hash.
+	prove func(ctx context.Context, circuitPath string, dir string) (common.Hash, error)
+}
+
+// NewDirectoryWatcher creates a watcher proving the drops in the input directory with the verifier
+// circuit and its keys.
+func NewDirectoryWatcher(input string, output string, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey) *DirectoryWatcher {
+	w := &DirectoryWatcher{
+		Input:        input,
+		Output:       output,
+		PollInterval: 5 * time.Second,
+	}
+	w.prove = func(ctx context.Context, circuitPath string, dir string) (common.Hash, error) {
+		assignment, err := TryLoadAssignment(circuitPath)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := w.Config.CheckAssignment(assignment); err != nil {
+			return common.Hash{}, err
+		}
+		inputHash := common.BigToHash(assignment.InputHash.(*big.Int))
+		proof, publicWitness, err := GenerateProofWithRetry(ctx, w.Retry, assignment, w.Config, r1cs, pk)
+		if err != nil {
+			return inputHash, err
+		}
+		err = VerifyProof(proof, vk, publicWitness)
+		if err != nil {
+			return inputHash, fmt.Errorf("failed to verify proof: %w", err)
+		}
+		return inputHash, SaveProofTo(dir, true, assignment, proof, publicWitness, vk)
+	}
+	return w
+}
+
+// Run proves the drops in the input directory until the context is cancelled.
+func (w *DirectoryWatcher) Run(ctx context.Context) error {
+	log := logger.Logger()
+	for _, dir := range []string{w.Output, filepath.Join(w.Input, dropsDoneDir), filepath.Join(w.Input, dropsFailedDir)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	log.Info().Msg("Watching " + w.Input + " for plonky2 proofs")
+	for {
+		if _, err := w.ProcessPending(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.PollInterval):
+		}
+	}
+}
+
+// ProcessPending proves the drops in the input directory, in the order of their names, and returns
+// the number of drops moved to done/ or failed/. Errors are only returned if the directories
+// cannot be accessed.
+func (w *DirectoryWatcher) ProcessPending(ctx context.Context) (int, error) {
+	log := logger.Logger()
+	entries, err := os.ReadDir(w.Input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read input directory: %w", missingArtifact(err))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	processed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == dropsDoneDir || name == dropsFailedDir || !dropReady(filepath.Join(w.Input, name)) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return processed, nil
+		}
+
+		log.Info().Msg("Proving drop " + name)
+		start := time.Now()
+		inputHash, err := w.process(ctx, name)
+		if err != nil && !unprovable(err) {
+			log.Err(err).Msg("failed to prove drop " + name + ", retrying in the next poll")
+			continue
+		}
+		if err != nil {
+			log.Err(err).Msg("rejected unprovable drop " + name)
+			if writeErr := os.WriteFile(filepath.Join(w.Input, name, dropErrorFile), []byte(err.Error()+"\n"), 0644); writeErr != nil {
+				return processed, fmt.Errorf("failed to write error of drop %s: %w", name, writeErr)
+			}
+			if err := moveDrop(filepath.Join(w.Input, name), filepath.Join(w.Input, dropsFailedDir, name)); err != nil {
+				return processed, err
+			}
+			w.archive(ProofRecord{RequestID: name, InputHash: hashHex(inputHash), Status: ProofStatusFailed, Error: err.Error()})
+		} else {
+			log.Info().Msg("Successfully proved drop " + name + ", time: " + time.Since(start).String())
+			if err := moveDrop(filepath.Join(w.Input, name), filepath.Join(w.Input, dropsDoneDir, name)); err != nil {
+				return processed, err
+			}
+			w.archive(ProofRecord{RequestID: name, InputHash: hashHex(inputHash), Status: ProofStatusProven, Dir: filepath.Join(w.Output, name), Files: proofFiles})
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// process proves the drop, and saves its proof to a temporary folder which is renamed into the
+// output directory, so the output of a drop is only visible once it is complete.
+func (w *DirectoryWatcher) process(ctx context.Context, name string) (common.Hash, error) {
+	tmp, err := os.MkdirTemp(w.Output, "."+name+"-")
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to create output folder: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	inputHash, err := w.prove(ctx, filepath.Join(w.Input, name), tmp)
+	if err != nil {
+		return inputHash, err
+	}
+	return inputHash, moveDrop(tmp, filepath.Join(w.Output, name))
+}
+
+// archive records the drop in the archive, if any. The archive is an index of the output
+// directory, so failing to update it does not fail the drop.
+func (w *DirectoryWatcher) archive(record ProofRecord) {
+	if w.Archive == nil {
+		return
+	}
+	log := logger.Logger()
+	if err := w.Archive.Put(record); err != nil {
+		log.Err(err).Msg("failed to archive drop " + record.RequestID)
+	}
+}
+
+// unprovable reports whether the error of a drop is permanent, i.e. its plonky2 proof is invalid
+// or its wrapper proof does not verify, rather than e.g. a prover or disk failure.
+func unprovable(err error) bool {
+	var witnessErr *WitnessError
+	return errors.As(err, &witnessErr) || errors.Is(err, types.ErrProofInvalid)
+}
+
+// dropReady reports whether the plonky2 proof of the drop, in JSON or binary, is there.
+func dropReady(circuitPath string) bool {
+	for _, file := range []string{"proof_with_public_inputs.json", proofWithPublicInputsBinFile} {
+		if _, err := os.Stat(filepath.Join(circuitPath, file)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// moveDrop moves the folder to dst, replacing the folder of a previous drop of the same name.
+func moveDrop(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", dst, err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryWatcher(t *testing.T) {
+	input, output := t.TempDir(), t.TempDir()
+	drop := func(name string, proof string) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(input, name), 0755))
+		if proof != "" {
+			assert.NoError(t, os.WriteFile(filepath.Join(input, name, "proof_with_public_inputs.json"), []byte(proof), 0644))
+		}
+	}
+	drop("a", "valid")
+	drop("b", "invalid")
+	drop("c", "busy")
+	drop("d", "")
+
+	archive, err := OpenProofArchive(filepath.Join(t.TempDir(), "archive"))
+	assert.NoError(t, err)
+	defer archive.Close()
+
+	w := &DirectoryWatcher{Input: input, Output: output, Archive: archive}
+	w.prove = func(ctx context.Context, circuitPath string, dir string) (common.Hash, error) {
+		proof, err := os.ReadFile(filepath.Join(circuitPath, "proof_with_public_inputs.json"))
+		assert.NoError(t, err)
+		switch string(proof) {
+		case "invalid":
+			return common.Hash{}, &WitnessError{Err: errors.New("bad proof")}
+		case "busy":
+			return common.Hash{2}, &ProverError{Err: errors.New("out of memory")}
+		}
+		return common.Hash{1}, os.WriteFile(filepath.Join(dir, "proof.json"), proof, 0644)
+	}
+	processed, err := w.ProcessPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, processed)
+
+	// The proven drop is moved to done/ and its proof is in the output directory.
+	assert.DirExists(t, filepath.Join(input, dropsDoneDir, "a"))
+	assert.FileExists(t, filepath.Join(output, "a", "proof.json"))
+
+	// The unprovable drop is moved to failed/ with its error.
+	errorText, err := os.ReadFile(filepath.Join(input, dropsFailedDir, "b", dropErrorFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(errorText), "bad proof")
+	assert.NoDirExists(t, filepath.Join(output, "b"))
+
+	// The drop which failed for a transient reason and the incomplete drop are left in place.
+	assert.DirExists(t, filepath.Join(input, "c"))
+	assert.DirExists(t, filepath.Join(input, "d"))
+	entries, err := os.ReadDir(output)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// The processed drops are recorded in the archive.
+	records, err := archive.Lookup(common.Hash{1}.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "a", records[0].RequestID)
+	assert.Equal(t, filepath.Join(output, "a"), records[0].Dir)
+	failed, err := archive.List(ProofStatusFailed)
+	assert.NoError(t, err)
+	assert.Len(t, failed, 1)
+	assert.Contains(t, failed[0].Error, "bad proof")
+	_, err = archive.Get("c")
+	assert.ErrorIs(t, err, ErrProofNotArchived)
+
+	// A later drop of the same name replaces the previous one.
+	drop("a", "valid again")
+	processed, err = w.ProcessPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	proof, err := os.ReadFile(filepath.Join(output, "a", "proof.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "valid again", string(proof))
+}
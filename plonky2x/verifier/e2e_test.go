@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// The end-to-end tests run the whole pipeline, i.e. compile, setup, prove, verify, and verify with
+// the Solidity verifier in an EVM, and compare the outputs with the golden files in testdata/e2e,
+// which are rewritten with -update-golden. The setup is deterministic, so the verifying key is
+// golden too, and so is the creation bytecode of its Solidity verifier, which is run in the EVM of
+// go-ethereum. Only rewriting the golden files needs solc, to compile the verifier.
+var updateGolden = flag.Bool("update-golden", false, "rewrite the golden files of the end-to-end tests")
+
+// The directory of the plonky2 fixture of TestE2EPlonky2, with the dummy circuit the verifier
+// circuit is compiled for in dummy/ and the proof to wrap in proof/. It is populated from the
+// wrapper test of plonky2x: cargo test test_wrapper -- --nocapture
+const e2ePlonky2Fixture = "testdata/e2e/plonky2"
+
+// e2eGolden is the golden output of an end-to-end test.
+type e2eGolden struct {
+	ConstraintSystemHash hexutil.Bytes        `json:"constraint_system_hash"`
+	NbConstraints        int                  `json:"nb_constraints"`
+	VerifyingKeyHash     common.Hash          `json:"vk_hash"`
+	PublicInputs         []LabeledPublicInput `json:"public_inputs"`
+}
+
+func TestE2ECircuit(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: 10})
+	assert.NoError(t, err)
+	assignment := &fingerprintCircuit{X: 177147, Y: 3, N: 10}
+	runE2E(t, "power", ccs, assignment, func(pk plonk.ProvingKey) (plonk.Proof, witness.Witness, error) {
+		full, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		if err != nil {
+			return nil, nil, err
+		}
+		proof, err := plonk.Prove(ccs, pk, full)
+		if err != nil {
+			return nil, nil, err
+		}
+		public, err := full.Public()
+		return proof, public, err
+	})
+}
+
+func TestE2EPlonky2(t *testing.T) {
+	if _, err := os.Stat(e2ePlonky2Fixture + "/proof"); err != nil {
+		t.Skip("no plonky2 fixture in " + e2ePlonky2Fixture)
+	}
+	ccs, err := CompileConstraintSystem(e2ePlonky2Fixture+"/dummy", false)
+	assert.NoError(t, err)
+	assignment := LoadAssignment(e2ePlonky2Fixture + "/proof")
+	runE2E(t, "plonky2", ccs, assignment, func(pk plonk.ProvingKey) (plonk.Proof, witness.Witness, error) {
+		return GenerateProof(assignment, CircuitConfig{}, ccs, pk)
+	})
+}
+
+// runE2E sets up the compiled circuit, proves the assignment with prove, verifies the proof in Go
+// and in the EVM, and compares the outputs with the golden file of the test.
+func runE2E(t *testing.T, name string, ccs constraint.ConstraintSystem, assignment frontend.Circuit, prove func(plonk.ProvingKey) (plonk.Proof, witness.Witness, error)) {
+	pk, vk, err := plonk.Setup(ccs, deterministicSRS(t, ccs))
+	assert.NoError(t, err)
+	proof, publicWitness, err := prove(pk)
+	assert.NoError(t, err)
+	assert.NoError(t, plonk.Verify(proof, vk, publicWitness))
+
+	fingerprint, err := ComputeFingerprint(ccs)
+	assert.NoError(t, err)
+	names, err := publicInputNames(assignment)
+	assert.NoError(t, err)
+	inputs := publicWitness.Vector().(fr.Vector)
+	assert.Len(t, inputs, len(names))
+	golden := e2eGolden{
+		ConstraintSystemHash: fingerprint.ConstraintSystemHash,
+		NbConstraints:        fingerprint.NbConstraints,
+		VerifyingKeyHash:     crypto.Keccak256Hash(VerifyingKeyRegistration(vk.(*plonk_bn254.VerifyingKey))),
+	}
+	for i, input := range inputs {
+		golden.PublicInputs = append(golden.PublicInputs, LabeledPublicInput{Name: names[i], Value: (*hexutil.Big)(input.BigInt(new(big.Int)))})
+	}
+	compareGolden(t, "testdata/e2e/"+name+".golden.json", golden)
+
+	verifySolidity(t, "testdata/e2e/"+name+".verifier.hex", vk, proof, publicWitness)
+}
+
+// deterministicSRS creates the KZG SRS for the constraint system like test.NewKZGSRS, but from a
+// fixed secret, so the verifying key is the same in every run.
+func deterministicSRS(t *testing.T, ccs constraint.ConstraintSystem) *kzg_bn254.SRS {
+	size := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+ccs.GetNbPublicVariables())) + 3
+	srs, err := kzg_bn254.NewSRS(size, big.NewInt(42))
+	assert.NoError(t, err)
+	return srs
+}
+
+// compareGolden compares the output with the golden file, or rewrites the file with -update-golden.
+func compareGolden(t *testing.T, path string, output e2eGolden) {
+	data, err := json.MarshalIndent(output, "", "  ")
+	assert.NoError(t, err)
+	if *updateGolden {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, append(data, '\n'), 0644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if !assert.NoError(t, err, "run with -update-golden to create the golden file") {
+		return
+	}
+	assert.JSONEq(t, string(expected), string(data))
+}
+
+// verifySolidity verifies the proof in the EVM with the Solidity verifier of the key, whose creation
+// bytecode is the golden file at path. With -update-golden, the verifier is compiled with solc and
+// the golden file rewritten.
+func verifySolidity(t *testing.T, path string, vk plonk.VerifyingKey, proof plonk.Proof, publicWitness witness.Witness) {
+	if *updateGolden {
+		bytecode, err := compileSolidityVerifier(t, vk)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, os.WriteFile(path, []byte(hex.EncodeToString(bytecode)+"\n"), 0644))
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("no verifier bytecode in " + path + ", run with -update-golden and solc installed to create it")
+	}
+	bytecode, err := readBytecodeFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	solidityProof := proof.(*plonk_bn254.Proof).MarshalSolidity()
+	assert.NoError(t, VerifyInEVM(bytecode, solidityProof, publicWitness))
+
+	// The proof is rejected for other public inputs.
+	vector := publicWitness.Vector().(fr.Vector)
+	other := make(fr.Vector, len(vector))
+	copy(other, vector)
+	other[0].SetUint64(1).Add(&other[0], &vector[0])
+	otherWitness, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	values := make(chan any, len(other))
+	for _, v := range other {
+		values <- v
+	}
+	close(values)
+	assert.NoError(t, otherWitness.Fill(len(other), 0, values))
+	assert.Error(t, VerifyInEVM(bytecode, solidityProof, otherWitness))
+}
+
+// compileSolidityVerifier compiles the Solidity verifier of the key with solc, and returns its
+// creation bytecode.
+func compileSolidityVerifier(t *testing.T, vk plonk.VerifyingKey) ([]byte, error) {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		return nil, fmt.Errorf("solc is required to compile the verifier: %w", err)
+	}
+	var verifier bytes.Buffer
+	if err := vk.ExportSolidity(&verifier); err != nil {
+		return nil, err
+	}
+	contract := filepath.Join(t.TempDir(), "Verifier.sol")
+	if err := os.WriteFile(contract, verifier.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	out, err := exec.Command(solc, "--optimize", "--bin", contract).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the verifier: %w", err)
+	}
+	match := regexp.MustCompile(`PlonkVerifier =======\s+Binary:\s+([0-9a-f]+)`).FindSubmatch(out)
+	if match == nil {
+		return nil, errors.New("no PlonkVerifier bytecode in the solc output")
+	}
+	return common.FromHex(string(match[1])), nil
+}
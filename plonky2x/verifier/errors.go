@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// The errors of the verifier are wrapped in the error classes of the types package, see
+// types.ErrArtifactMissing, so callers can tell e.g. a missing proving key from a corrupted one.
+
+// missingArtifact wraps the error of reading a file which does not exist in ErrArtifactMissing,
+// and returns other errors as they are.
+func missingArtifact(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", types.ErrArtifactMissing, err)
+	}
+	return err
+}
+
+// serializationError wraps the error of decoding data in ErrSerialization.
+func serializationError(err error) error {
+	return fmt.Errorf("%w: %w", types.ErrSerialization, err)
+}
+
+// VerifyProof verifies the proof with the verifying key, and wraps its failure in ErrProofInvalid.
+func VerifyProof(proof plonk.Proof, vk plonk.VerifyingKey, publicWitness witness.Witness) error {
+	if err := plonk.Verify(proof, vk, publicWitness); err != nil {
+		return fmt.Errorf("%w: %w", types.ErrProofInvalid, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"runtime"
+	"time"
+
+	"github.com/consensys/gnark/constraint"
+)
+
+// The estimates are extrapolated from a micro-benchmark proving a small circuit on the machine,
+// assuming the proving time grows like n log n in the domain size n, and with the number of
+// polynomials which are committed to. They are meant for scheduling proofs on workers and setting
+// their timeouts, not as a guarantee.
+
+// The number of polynomials the prover commits to without commitments in the circuit, i.e. the
+// wires L, R, O, the permutation Z and the three parts of the quotient. Each commitment of the
+// circuit adds one more.
+const baseProverCommitments = 7
+
+// The number of domain sized vectors of field elements held by the prover on the coset of four times
+// the size of the domain, on top of the proving key, when it computes the quotient.
+const proverWorkingVectors = 4 * 16
+
+// The factor the estimated proving time is multiplied with for the timeout of a proof.
+const provingTimeoutFactor = 2
+
+// CircuitShape is what the cost of proving a circuit depends on.
+type CircuitShape struct {
+	NbConstraints     int `json:"nb_constraints"`
+	NbPublicVariables int `json:"nb_public_variables"`
+	NbCommitments     int `json:"nb_commitments"`
+}
+
+// ShapeOf returns the shape of the constraint system.
+func ShapeOf(r1cs constraint.ConstraintSystem) CircuitShape {
+	return CircuitShape{
+		NbConstraints:     r1cs.GetNbConstraints(),
+		NbPublicVariables: r1cs.GetNbPublicVariables(),
+		NbCommitments:     len(r1cs.GetCommitments().CommitmentIndexes()),
+	}
+}
+
+// DomainSize returns the size of the evaluation domain of the circuit.
+func (s CircuitShape) DomainSize() uint64 {
+	return domainSize(s.NbConstraints + s.NbPublicVariables)
+}
+
+// MachineCalibration is the proving speed of a machine, measured by Calibrate.
+type MachineCalibration struct {
+	NumCPU int `json:"num_cpu"`
+	// The time to prove the calibration circuit, whose domain has CalibrationDomainSize rows.
+	CalibrationTime       time.Duration `json:"calibration_time_ns"`
+	CalibrationDomainSize uint64        `json:"calibration_domain_size"`
+}
+
+// Calibrate measures the proving speed of this machine by proving a small circuit, which takes
+// about a second.
+func Calibrate() (*MachineCalibration, error) {
+	elapsed, err := calibrateProvingTime()
+	if err != nil {
+		return nil, err
+	}
+	return &MachineCalibration{
+		NumCPU:                runtime.NumCPU(),
+		CalibrationTime:       elapsed,
+		CalibrationDomainSize: domainSize(calibrationConstraints),
+	}, nil
+}
+
+// ProvingEstimate is the estimated cost of proving a circuit on a machine.
+type ProvingEstimate struct {
+	ProvingTime time.Duration `json:"proving_time_ns"`
+	Timeout     time.Duration `json:"timeout_ns"`
+	MemoryBytes uint64        `json:"memory_bytes"`
+}
+
+// Estimate estimates the cost of proving a circuit of the shape on the calibrated machine.
+func (m *MachineCalibration) Estimate(shape CircuitShape) ProvingEstimate {
+	n := shape.DomainSize()
+	provingTime := scaleProvingTime(m.CalibrationTime, m.CalibrationDomainSize, n)
+	provingTime = provingTime * time.Duration(baseProverCommitments+shape.NbCommitments) / baseProverCommitments
+	return ProvingEstimate{
+		ProvingTime: provingTime,
+		Timeout:     provingTimeoutFactor * provingTime,
+		MemoryBytes: estimateProvingMemoryBytes(n, shape.NbCommitments),
+	}
+}
+
+// estimateProvingMemoryBytes estimates the peak memory of the prover, i.e. the proving key and the
+// vectors the prover works on.
+func estimateProvingMemoryBytes(domainSize uint64, nbCommitments int) uint64 {
+	const frBytes = 32
+	return estimateProvingKeyBytes(domainSize, nbCommitments) + proverWorkingVectors*domainSize*frBytes
+}
+
+// Worker is a machine proofs are scheduled on.
+type Worker struct {
+	Name        string             `json:"name"`
+	MemoryBytes uint64             `json:"memory_bytes"`
+	Calibration MachineCalibration `json:"calibration"`
+}
+
+// PickWorker returns the worker estimated to prove a circuit of the shape the fastest among the
+// workers with enough memory for it, along with the estimate on that worker.
+func PickWorker(workers []Worker, shape CircuitShape) (*Worker, ProvingEstimate, error) {
+	var best *Worker
+	var bestEstimate ProvingEstimate
+	for i := range workers {
+		estimate := workers[i].Calibration.Estimate(shape)
+		if estimate.MemoryBytes > workers[i].MemoryBytes {
+			continue
+		}
+		if best == nil || estimate.ProvingTime < bestEstimate.ProvingTime {
+			best, bestEstimate = &workers[i], estimate
+		}
+	}
+	if best == nil {
+		return nil, ProvingEstimate{}, fmt.Errorf("no worker has the %d bytes of memory to prove a circuit of %d constraints", estimateProvingMemoryBytes(shape.DomainSize(), shape.NbCommitments), shape.NbConstraints)
+	}
+	return best, bestEstimate, nil
+}
+
+func domainSize(nbRows int) uint64 {
+	if nbRows <= 1 {
+		return 1
+	}
+	return 1 << bits.Len64(uint64(nbRows-1))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvingEstimate(t *testing.T) {
+	calibration := MachineCalibration{NumCPU: 8, CalibrationTime: 10 * time.Second, CalibrationDomainSize: 1 << 9}
+	shape := CircuitShape{NbConstraints: 1000, NbPublicVariables: 3}
+	assert.Equal(t, uint64(1<<10), shape.DomainSize())
+
+	estimate := calibration.Estimate(shape)
+	assert.Equal(t, 22*time.Second, estimate.ProvingTime)
+	assert.Equal(t, 44*time.Second, estimate.Timeout)
+	assert.Greater(t, estimate.MemoryBytes, estimateProvingKeyBytes(1<<10, 0))
+
+	// Commitments add polynomials to commit to, and memory.
+	shape.NbCommitments = 7
+	withCommitments := calibration.Estimate(shape)
+	assert.Equal(t, 44*time.Second, withCommitments.ProvingTime)
+	assert.Greater(t, withCommitments.MemoryBytes, estimate.MemoryBytes)
+}
+
+func TestPickWorker(t *testing.T) {
+	shape := CircuitShape{NbConstraints: 1 << 20}
+	memory := estimateProvingMemoryBytes(shape.DomainSize(), 0)
+	workers := []Worker{
+		{Name: "small", MemoryBytes: memory / 2, Calibration: MachineCalibration{CalibrationTime: time.Second, CalibrationDomainSize: 1 << 14}},
+		{Name: "slow", MemoryBytes: memory, Calibration: MachineCalibration{CalibrationTime: 3 * time.Second, CalibrationDomainSize: 1 << 14}},
+		{Name: "fast", MemoryBytes: 2 * memory, Calibration: MachineCalibration{CalibrationTime: 2 * time.Second, CalibrationDomainSize: 1 << 14}},
+	}
+	worker, estimate, err := PickWorker(workers, shape)
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", worker.Name)
+	assert.Equal(t, workers[2].Calibration.Estimate(shape), estimate)
+
+	_, _, err = PickWorker(workers[:1], shape)
+	assert.ErrorContains(t, err, "no worker")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// The ABI of the Verify function of the PLONK verifier generated by gnark, see Verifier.sol.
+const plonkVerifierABI = `[{"type":"function","name":"Verify","stateMutability":"view","inputs":[{"name":"proof","type":"bytes"},{"name":"public_inputs","type":"uint256[]"}],"outputs":[{"name":"success","type":"bool"}]}]`
+
+// PlonkVerifierCalldata returns the calldata of the Verify call of the PLONK verifier for the
+// Solidity encoding of a proof and the public witness.
+func PlonkVerifierCalldata(proof []byte, publicWitness witness.Witness) ([]byte, error) {
+	vector, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public witness %T", publicWitness.Vector())
+	}
+	publicInputs := make([]*big.Int, len(vector))
+	for i := range vector {
+		publicInputs[i] = vector[i].BigInt(new(big.Int))
+	}
+	verifierABI, err := abi.JSON(strings.NewReader(plonkVerifierABI))
+	if err != nil {
+		return nil, err
+	}
+	return verifierABI.Pack("Verify", proof, publicInputs)
+}
+
+// The file the prove command saves the gas report of a proof in with -gas-report.
+const gasReportFileName = "gas_report.json"
+
+// GasReport is the onchain cost of verifying a proof with the PLONK verifier, so circuit authors
+// see the impact of adding public inputs or commitments.
+type GasReport struct {
+	// The gas of the whole Verify transaction, i.e. the intrinsic gas and the execution gas.
+	TotalGas uint64 `json:"total_gas"`
+	// The gas of the transaction and its calldata, which is charged before the verifier runs.
+	IntrinsicGas uint64 `json:"intrinsic_gas"`
+	// The gas used by the verifier, measured in an in-process EVM.
+	ExecutionGas uint64 `json:"execution_gas"`
+
+	CalldataBytes        int `json:"calldata_bytes"`
+	CalldataZeroBytes    int `json:"calldata_zero_bytes"`
+	CalldataNonZeroBytes int `json:"calldata_nonzero_bytes"`
+	ProofBytes           int `json:"proof_bytes"`
+	PublicInputs         int `json:"public_inputs"`
+}
+
+// newGasReport returns the report of a Verify call with the calldata, whose execution used the gas.
+func newGasReport(calldata []byte, proof []byte, publicInputs int, executionGas uint64) *GasReport {
+	report := &GasReport{
+		ExecutionGas:  executionGas,
+		CalldataBytes: len(calldata),
+		ProofBytes:    len(proof),
+		PublicInputs:  publicInputs,
+	}
+	for _, b := range calldata {
+		if b == 0 {
+			report.CalldataZeroBytes++
+		} else {
+			report.CalldataNonZeroBytes++
+		}
+	}
+	report.IntrinsicGas = params.TxGas + uint64(report.CalldataZeroBytes)*params.TxDataZeroGas + uint64(report.CalldataNonZeroBytes)*params.TxDataNonZeroGasEIP2028
+	report.TotalGas = report.IntrinsicGas + report.ExecutionGas
+	return report
+}
+
+// VerifyInEVM deploys the PLONK verifier with the creation bytecode in an in-process EVM, and
+// verifies the Solidity encoding of a proof with it, so encoding mismatches between the prover and
+// the verifier are caught before the proof is sent onchain.
+func VerifyInEVM(bytecode []byte, proof []byte, publicWitness witness.Witness) error {
+	_, err := MeasureInEVM(bytecode, proof, publicWitness)
+	return err
+}
+
+// MeasureInEVM verifies the proof like VerifyInEVM, and returns the gas and calldata it costs to
+// verify it onchain.
+func MeasureInEVM(bytecode []byte, proof []byte, publicWitness witness.Witness) (*GasReport, error) {
+	calldata, err := PlonkVerifierCalldata(proof, publicWitness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode calldata: %w", err)
+	}
+	cfg := &runtime.Config{}
+	_, address, _, err := runtime.Create(bytecode, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy verifier: %w", err)
+	}
+	ret, gasLeft, err := runtime.Call(address, calldata, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: verifier reverted: %w", types.ErrProofInvalid, err)
+	}
+	verifierABI, err := abi.JSON(strings.NewReader(plonkVerifierABI))
+	if err != nil {
+		return nil, err
+	}
+	result, err := verifierABI.Unpack("Verify", ret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode verifier result: %w", err)
+	}
+	if success, ok := result[0].(bool); !ok || !success {
+		return nil, fmt.Errorf("%w: verifier rejected the proof", types.ErrProofInvalid)
+	}
+	report := newGasReport(calldata, proof, publicWitness.Vector().(fr.Vector).Len(), cfg.GasLimit-gasLeft)
+	log := logger.Logger()
+	log.Debug().Msg(fmt.Sprintf("Successfully verified proof in evm, gas: %d", report.ExecutionGas))
+	return report, nil
+}
+
+// VerifyProofFileInEVM verifies the proof in proof.json of the current working directory with the
+// PLONK verifier whose hex creation bytecode, e.g. compiled from Verifier.sol, is in bytecodeFile,
+// and returns the cost of verifying it onchain.
+func VerifyProofFileInEVM(bytecodeFile string, publicWitness witness.Witness) (*GasReport, error) {
+	bytecode, err := readBytecodeFile(bytecodeFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile("proof.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof file: %w", err)
+	}
+	var result types.ProofResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse proof file: %w", err)
+	}
+	return MeasureInEVM(bytecode, result.Proof, publicWitness)
+}
+
+// readBytecodeFile reads the hex creation bytecode of a contract.
+func readBytecodeFile(bytecodeFile string) ([]byte, error) {
+	data, err := os.ReadFile(bytecodeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bytecode file: %w", err)
+	}
+	bytecode := common.FromHex(strings.TrimSpace(string(data)))
+	if len(bytecode) == 0 {
+		return nil, fmt.Errorf("bytecode file %s is empty", bytecodeFile)
+	}
+	return bytecode, nil
+}
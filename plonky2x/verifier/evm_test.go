@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// evmContract returns the creation bytecode of a contract with the runtime bytecode, which must be
+// shorter than 256 bytes.
+func evmContract(runtime string) []byte {
+	code := common.FromHex(runtime)
+	// CODECOPY the runtime bytecode after the 12 bytes of this prefix to memory and RETURN it.
+	prefix := []byte{0x60, byte(len(code)), 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, byte(len(code)), 0x60, 0x00, 0xf3}
+	return append(prefix, code...)
+}
+
+func TestVerifyInEVM(t *testing.T) {
+	publicWitness := newTestPublicWitness(t, 1, 2, 3)
+	proof := []byte{0xaa, 0xbb}
+
+	calldata, err := PlonkVerifierCalldata(proof, publicWitness)
+	assert.NoError(t, err)
+	// The selector of Verify(bytes,uint256[]), the offsets of both arguments, the proof and the
+	// public inputs.
+	assert.Equal(t, 4+2*32+2*32+4*32, len(calldata))
+	assert.Equal(t, common.Hex2Bytes("00000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000001"), calldata[4+4*32:4+6*32])
+
+	// Verifiers which return true, return false and revert.
+	assert.NoError(t, VerifyInEVM(evmContract("600160005260206000f3"), proof, publicWitness))
+	err = VerifyInEVM(evmContract("600060005260206000f3"), proof, publicWitness)
+	assert.ErrorContains(t, err, "rejected")
+	assert.ErrorIs(t, err, types.ErrProofInvalid)
+	assert.ErrorContains(t, VerifyInEVM(evmContract("60006000fd"), proof, publicWitness), "reverted")
+}
+
+func TestMeasureInEVM(t *testing.T) {
+	publicWitness := newTestPublicWitness(t, 1, 2, 3)
+	proof := []byte{0xaa, 0xbb}
+
+	// PUSH1 1, PUSH1 0, MSTORE, PUSH1 32, PUSH1 0, RETURN costs 3+3+6+3+3 gas, with the memory.
+	report, err := MeasureInEVM(evmContract("600160005260206000f3"), proof, publicWitness)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(18), report.ExecutionGas)
+	assert.Equal(t, 4+2*32+2*32+4*32, report.CalldataBytes)
+	assert.Equal(t, report.CalldataBytes, report.CalldataZeroBytes+report.CalldataNonZeroBytes)
+	assert.Equal(t, uint64(21000+4*report.CalldataZeroBytes+16*report.CalldataNonZeroBytes), report.IntrinsicGas)
+	assert.Equal(t, report.IntrinsicGas+report.ExecutionGas, report.TotalGas)
+	assert.Equal(t, 2, report.ProofBytes)
+	assert.Equal(t, 3, report.PublicInputs)
+
+	// Every public input adds a word of calldata.
+	more, err := MeasureInEVM(evmContract("600160005260206000f3"), proof, newTestPublicWitness(t, 1, 2, 3, 4))
+	assert.NoError(t, err)
+	assert.Equal(t, report.CalldataBytes+32, more.CalldataBytes)
+	assert.Greater(t, more.IntrinsicGas, report.IntrinsicGas)
+
+	_, err = MeasureInEVM(evmContract("600060005260206000f3"), proof, publicWitness)
+	assert.ErrorIs(t, err, types.ErrProofInvalid)
+}
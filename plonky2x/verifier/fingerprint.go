@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The modules that determine the compiled constraint system.
+var fingerprintModules = []string{
+	"github.com/consensys/gnark",
+	"github.com/consensys/gnark-crypto",
+	"github.com/succinctlabs/gnark-plonky2-verifier",
+}
+
+// Fingerprint identifies a compiled constraint system. Two machines that report the same
+// fingerprint produced byte-identical constraint systems, so they can share a proving key.
+type Fingerprint struct {
+	ConstraintSystemHash hexutil.Bytes     `json:"constraint_system_hash"`
+	NbConstraints        int               `json:"nb_constraints"`
+	NbPublicVariables    int               `json:"nb_public_variables"`
+	NbSecretVariables    int               `json:"nb_secret_variables"`
+	GoVersion            string            `json:"go_version"`
+	Modules              map[string]string `json:"modules"`
+}
+
+// ComputeFingerprint hashes the serialized constraint system with sha256 and records the versions of
+// the modules it was compiled with.
+func ComputeFingerprint(r1cs constraint.ConstraintSystem) (*Fingerprint, error) {
+	hasher := sha256.New()
+	_, err := r1cs.WriteTo(hasher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize constraint system: %w", err)
+	}
+	return &Fingerprint{
+		ConstraintSystemHash: hasher.Sum(nil),
+		NbConstraints:        r1cs.GetNbConstraints(),
+		NbPublicVariables:    r1cs.GetNbPublicVariables(),
+		NbSecretVariables:    r1cs.GetNbSecretVariables(),
+		GoVersion:            runtime.Version(),
+		Modules:              moduleVersions(fingerprintModules),
+	}, nil
+}
+
+// moduleVersions returns the versions of the given modules the binary was built with. Replaced
+// modules report the version or path of their replacement.
+func moduleVersions(paths []string) map[string]string {
+	versions := make(map[string]string)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return versions
+	}
+	for _, dep := range info.Deps {
+		for _, path := range paths {
+			if dep.Path != path {
+				continue
+			}
+			version := dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			versions[path] = version
+		}
+	}
+	return versions
+}
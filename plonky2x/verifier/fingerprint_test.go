@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/assert"
+)
+
+type fingerprintCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+	N int `gnark:"-"`
+}
+
+func (c *fingerprintCircuit) Define(api frontend.API) error {
+	acc := c.Y
+	for i := 0; i < c.N; i++ {
+		acc = api.Mul(acc, c.Y)
+	}
+	api.AssertIsEqual(c.X, acc)
+	return nil
+}
+
+func TestFingerprint(t *testing.T) {
+	compile := func(n int) *Fingerprint {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: n})
+		assert.NoError(t, err)
+		fingerprint, err := ComputeFingerprint(cs)
+		assert.NoError(t, err)
+		return fingerprint
+	}
+
+	// Compiling the same circuit twice gives the same fingerprint.
+	a, b := compile(10), compile(10)
+	assert.Equal(t, a.ConstraintSystemHash, b.ConstraintSystemHash)
+	assert.Len(t, a.ConstraintSystemHash, 32)
+	assert.Equal(t, 1, a.NbPublicVariables)
+
+	// A different circuit does not.
+	assert.NotEqual(t, a.ConstraintSystemHash, compile(11).ConstraintSystemHash)
+}
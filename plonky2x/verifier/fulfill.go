@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/succinctlabs/succinctx/bindings"
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// FulfillBackend is the subset of an Ethereum client needed to build and send fulfillment
+// transactions. It is implemented by *ethclient.Client.
+type FulfillBackend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error
+}
+
+// CallbackRequest is a request made through SuccinctGateway.requestCallback.
+type CallbackRequest struct {
+	Nonce            uint32
+	FunctionID       [32]byte
+	InputHash        [32]byte
+	CallbackAddress  common.Address
+	CallbackSelector [4]byte
+	CallbackGasLimit uint32
+	Context          []byte
+}
+
+// CallRequest is a request made through SuccinctGateway.requestCall.
+type CallRequest struct {
+	FunctionID      [32]byte
+	Input           []byte
+	CallbackAddress common.Address
+	CallbackData    []byte
+}
+
+// Fulfiller builds, signs and sends the fulfillment transactions to the SuccinctGateway contract.
+// Nonces are tracked locally so that several fulfillments can be in flight at once. A transaction
+// only takes its nonce once it is sent, so a built transaction which is never sent leaves no gap.
+type Fulfiller struct {
+	backend FulfillBackend
+	gateway common.Address
+	abi     *abi.ABI
+	signer  types.Signer
+	from    common.Address
+	chainID *big.Int
+
+	// The gas limit is set to the estimate scaled by GasLimitMultiplier. If GasLimit is non-zero,
+	// estimation is skipped and it is used as is.
+	GasLimit           uint64
+	GasLimitMultiplier float64
+
+	mu        sync.Mutex
+	nextNonce *uint64
+
+	// Serializes building and sending in FulfillCallback and FulfillCall, so that concurrent
+	// fulfillments do not build transactions with the same nonce.
+	sendMu sync.Mutex
+}
+
+// NewFulfiller creates a fulfiller sending the transactions from the address of the signer, see
+// NewSigner for the backends the operator key can be held in.
+func NewFulfiller(ctx context.Context, backend FulfillBackend, gateway common.Address, signer types.Signer) (*Fulfiller, error) {
+	gatewayAbi, err := bindings.SuccinctGatewayMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gateway abi: %w", err)
+	}
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	return &Fulfiller{
+		backend:            backend,
+		gateway:            gateway,
+		abi:                gatewayAbi,
+		signer:             signer,
+		from:               signer.Address(),
+		chainID:            chainID,
+		GasLimitMultiplier: 1.2,
+	}, nil
+}
+
+// From returns the address the fulfillment transactions are sent from.
+func (f *Fulfiller) From() common.Address {
+	return f.from
+}
+
+// BuildFulfillCallback builds and signs the fulfillCallback transaction for the proof result
+// without sending it. It has the nonce following the last transaction sent, see Send.
+func (f *Fulfiller) BuildFulfillCallback(ctx context.Context, req CallbackRequest, result types.ProofResult) (*ethtypes.Transaction, error) {
+	data, err := f.abi.Pack(
+		"fulfillCallback",
+		req.Nonce,
+		req.FunctionID,
+		req.InputHash,
+		req.CallbackAddress,
+		req.CallbackSelector,
+		req.CallbackGasLimit,
+		req.Context,
+		[]byte(result.Output),
+		[]byte(result.Proof),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack fulfillCallback: %w", err)
+	}
+	return f.buildTransaction(ctx, data)
+}
+
+// BuildFulfillCall builds and signs the fulfillCall transaction for the proof result without
+// sending it. It has the nonce following the last transaction sent, see Send.
+func (f *Fulfiller) BuildFulfillCall(ctx context.Context, req CallRequest, result types.ProofResult) (*ethtypes.Transaction, error) {
+	data, err := f.abi.Pack(
+		"fulfillCall",
+		req.FunctionID,
+		req.Input,
+		[]byte(result.Output),
+		[]byte(result.Proof),
+		req.CallbackAddress,
+		req.CallbackData,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack fulfillCall: %w", err)
+	}
+	return f.buildTransaction(ctx, data)
+}
+
+// FulfillCallback builds, signs and sends the fulfillCallback transaction.
+func (f *Fulfiller) FulfillCallback(ctx context.Context, req CallbackRequest, result types.ProofResult) (*ethtypes.Transaction, error) {
+	f.sendMu.Lock()
+	defer f.sendMu.Unlock()
+	tx, err := f.BuildFulfillCallback(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	return tx, f.Send(ctx, tx)
+}
+
+// FulfillCall builds, signs and sends the fulfillCall transaction.
+func (f *Fulfiller) FulfillCall(ctx context.Context, req CallRequest, result types.ProofResult) (*ethtypes.Transaction, error) {
+	f.sendMu.Lock()
+	defer f.sendMu.Unlock()
+	tx, err := f.BuildFulfillCall(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	return tx, f.Send(ctx, tx)
+}
+
+// Send broadcasts a signed transaction, after which the next transaction is built with the
+// following nonce. If it is rejected, the local nonce is dropped so that it is re-read from the
+// node for the next transaction.
+func (f *Fulfiller) Send(ctx context.Context, tx *ethtypes.Transaction) error {
+	log := logger.Logger()
+	err := f.backend.SendTransaction(ctx, tx)
+	if err != nil {
+		f.ResetNonce()
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	f.mu.Lock()
+	if f.nextNonce != nil && *f.nextNonce <= tx.Nonce() {
+		next := tx.Nonce() + 1
+		f.nextNonce = &next
+	}
+	f.mu.Unlock()
+	log.Info().Msg(fmt.Sprintf("Sent fulfillment transaction %s with nonce %d", tx.Hash().Hex(), tx.Nonce()))
+	return nil
+}
+
+// ResetNonce discards the locally tracked nonce.
+func (f *Fulfiller) ResetNonce() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextNonce = nil
+}
+
+func (f *Fulfiller) buildTransaction(ctx context.Context, data []byte) (*ethtypes.Transaction, error) {
+	gasLimit := f.GasLimit
+	if gasLimit == 0 {
+		estimate, err := f.backend.EstimateGas(ctx, ethereum.CallMsg{From: f.from, To: &f.gateway, Data: data})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		gasLimit = uint64(float64(estimate) * f.GasLimitMultiplier)
+	}
+	gasPrice, err := f.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.nextNonce == nil {
+		nonce, err := f.backend.PendingNonceAt(ctx, f.from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nonce: %w", err)
+		}
+		f.nextNonce = &nonce
+	}
+
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    *f.nextNonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       &f.gateway,
+		Data:     data,
+	})
+	txSigner := ethtypes.LatestSignerForChainID(f.chainID)
+	signature, err := f.signer.SignHash(ctx, txSigner.Hash(tx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	signedTx, err := tx.WithSignature(txSigner, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signedTx, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+type mockFulfillBackend struct {
+	nonce   uint64
+	sent    []*ethtypes.Transaction
+	sendErr error
+}
+
+func (b *mockFulfillBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(5), nil
+}
+
+func (b *mockFulfillBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.nonce, nil
+}
+
+func (b *mockFulfillBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), nil
+}
+
+func (b *mockFulfillBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (b *mockFulfillBackend) SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error {
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	b.sent = append(b.sent, tx)
+	b.nonce++
+	return nil
+}
+
+func TestFulfillCallback(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	backend := &mockFulfillBackend{nonce: 7}
+	gateway := common.HexToAddress("0x6c7a05e0AE641c6559fD76ac56641778B6eCd776")
+
+	fulfiller, err := NewFulfiller(ctx, backend, gateway, types.NewPrivateKeySigner(key))
+	assert.NoError(t, err)
+
+	req := CallbackRequest{
+		Nonce:            3,
+		FunctionID:       [32]byte{1},
+		InputHash:        [32]byte{2},
+		CallbackAddress:  common.HexToAddress("0x01"),
+		CallbackSelector: [4]byte{0xde, 0xad, 0xbe, 0xef},
+		CallbackGasLimit: 500000,
+		Context:          []byte{0x42},
+	}
+	result := types.ProofResult{Proof: []byte{0xaa}, Output: []byte{0xbb}}
+
+	tx, err := fulfiller.FulfillCallback(ctx, req, result)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, uint64(120000), tx.Gas())
+	assert.Equal(t, gateway, *tx.To())
+
+	// The transaction is signed by the operator key for the backend's chain.
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(big.NewInt(5)), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, fulfiller.From(), sender)
+
+	// The calldata decodes back to the request.
+	method, err := fulfiller.abi.MethodById(tx.Data()[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, "fulfillCallback", method.Name)
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, req.Nonce, args[0])
+	assert.Equal(t, req.FunctionID, args[1])
+	assert.Equal(t, []byte(result.Proof), args[8])
+
+	// Nonces are tracked locally across in-flight transactions.
+	backend.nonce = 7
+	tx, err = fulfiller.BuildFulfillCall(ctx, CallRequest{FunctionID: [32]byte{1}, Input: []byte{1}}, result)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), tx.Nonce())
+
+	// A transaction which is built but not sent does not take its nonce.
+	tx, err = fulfiller.BuildFulfillCall(ctx, CallRequest{FunctionID: [32]byte{2}, Input: []byte{1}}, result)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), tx.Nonce())
+	assert.NoError(t, fulfiller.Send(ctx, tx))
+	tx, err = fulfiller.BuildFulfillCall(ctx, CallRequest{FunctionID: [32]byte{1}, Input: []byte{1}}, result)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), tx.Nonce())
+
+	// A rejected transaction resyncs the nonce from the node.
+	backend.sendErr = errors.New("nonce too low")
+	assert.Error(t, fulfiller.Send(ctx, tx))
+	backend.sendErr = nil
+	backend.nonce = 8
+	tx, err = fulfiller.BuildFulfillCall(ctx, CallRequest{FunctionID: [32]byte{1}, Input: []byte{1}}, result)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), tx.Nonce())
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -46,6 +47,7 @@ type Groth16ProofData struct {
 }
 
 func TestGroth16(t *testing.T) {
+	dir := t.TempDir()
 
 	circuit := MyCircuit{DoRangeCheck: false}
 
@@ -65,7 +67,7 @@ func TestGroth16(t *testing.T) {
 	}
 	content := buf.String()
 
-	contractFile, err := os.Create("VerifierGroth16.sol")
+	contractFile, err := os.Create(filepath.Join(dir, "VerifierGroth16.sol"))
 	if err != nil {
 		panic(err)
 	}
@@ -121,7 +123,7 @@ func TestGroth16(t *testing.T) {
 	}
 
 	// Write the JSON to a file
-	err = ioutil.WriteFile("groth16_proof_data.json", jsonData, 0644)
+	err = ioutil.WriteFile(filepath.Join(dir, "groth16_proof_data.json"), jsonData, 0644)
 	if err != nil {
 		fmt.Println("Error writing to file:", err)
 	}
@@ -133,6 +135,7 @@ type PlonkProofData struct {
 }
 
 func TestPlonk(t *testing.T) {
+	dir := t.TempDir()
 
 	range_check := true
 
@@ -162,7 +165,7 @@ func TestPlonk(t *testing.T) {
 	if range_check {
 		filename = "VerifierPlonkRangeCheck.sol"
 	}
-	contractFile, err := os.Create(filename)
+	contractFile, err := os.Create(filepath.Join(dir, filename))
 	if err != nil {
 		panic(err)
 	}
@@ -221,7 +224,7 @@ func TestPlonk(t *testing.T) {
 	}
 
 	// Write the JSON to a file
-	err = ioutil.WriteFile(filename, jsonData, 0644)
+	err = ioutil.WriteFile(filepath.Join(dir, filename), jsonData, 0644)
 	if err != nil {
 		fmt.Println("Error writing to file:", err)
 	}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/succinctlabs/gnark-plonky2-verifier/goldilocks"
+	"github.com/succinctlabs/succinctx/gnarkx/builder"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/blake3"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/keccak256"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/sha256"
+	"github.com/succinctlabs/succinctx/gnarkx/vars"
+)
+
+// The optional file next to common_circuit_data.json that describes how the plonky2 circuit commits
+// to its inputs and outputs. Without it, the IO is committed like the plonky2x WrappedCircuit does.
+const ioCommitmentFile = "io_commitment.json"
+
+// IOHash is the hash function the inputs and outputs of a function are committed with onchain. Only
+// hash functions the verifier circuit can compute are supported, so that the commitment is checked
+// the same way whether the plonky2 circuit or the verifier circuit hashes the IO.
+type IOHash int
+
+const (
+	IOHashSHA256 IOHash = iota
+	IOHashKeccak256
+	IOHashBlake3
+)
+
+var ioHashNames = map[IOHash]string{
+	IOHashSHA256:    "sha256",
+	IOHashKeccak256: "keccak256",
+	IOHashBlake3:    "blake3",
+}
+
+func (h IOHash) String() string {
+	if name, ok := ioHashNames[h]; ok {
+		return name
+	}
+	return fmt.Sprintf("IOHash(%d)", int(h))
+}
+
+func (h IOHash) MarshalText() ([]byte, error) {
+	if _, ok := ioHashNames[h]; !ok {
+		return nil, fmt.Errorf("unknown io hash %d", int(h))
+	}
+	return []byte(h.String()), nil
+}
+
+func (h *IOHash) UnmarshalText(text []byte) error {
+	for candidate, name := range ioHashNames {
+		if name == string(text) {
+			*h = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown io hash %q", text)
+}
+
+// IOTruncation maps a 256-bit digest to a single BN254 scalar, so that the input and output hashes
+// each take one public input onchain.
+type IOTruncation int
+
+const (
+	// IOTruncateMask zeroes the top 3 bits of the big-endian digest, i.e. digest & (2^253 - 1).
+	IOTruncateMask IOTruncation = iota
+	// IOTruncateReduce reduces the digest modulo the BN254 scalar field, i.e. digest % r.
+	IOTruncateReduce
+)
+
+var ioTruncationNames = map[IOTruncation]string{
+	IOTruncateMask:   "mask253",
+	IOTruncateReduce: "mod",
+}
+
+func (t IOTruncation) String() string {
+	if name, ok := ioTruncationNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("IOTruncation(%d)", int(t))
+}
+
+func (t IOTruncation) MarshalText() ([]byte, error) {
+	if _, ok := ioTruncationNames[t]; !ok {
+		return nil, fmt.Errorf("unknown io truncation %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *IOTruncation) UnmarshalText(text []byte) error {
+	for truncation, name := range ioTruncationNames {
+		if name == string(text) {
+			*t = truncation
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown io truncation %q", text)
+}
+
+// IOCommitment describes how the public inputs of the plonky2 proof are turned into the InputHash
+// and OutputHash of the verifier circuit. The zero value matches the plonky2x WrappedCircuit: the
+// public inputs are the bytes of the SHA-256 input and output digests, already masked to 253 bits.
+type IOCommitment struct {
+	Hash       IOHash       `json:"hash"`
+	Truncation IOTruncation `json:"truncation"`
+
+	// If set, the public inputs are the raw input bytes followed by the raw output bytes, and the
+	// digests are computed in the verifier circuit. This is how circuits whose IO is committed with
+	// Keccak or Blake3, which plonky2x cannot hash efficiently, are wrapped.
+	HashInCircuit bool `json:"hash_in_circuit"`
+	NumInputBytes int  `json:"num_input_bytes"`
+}
+
+// ReadIOCommitment reads the IO commitment of the plonky2 circuit in circuitPath, or returns the
+// default one if the circuit does not have an io_commitment.json.
+func ReadIOCommitment(circuitPath string) (IOCommitment, error) {
+	var commitment IOCommitment
+	data, err := os.ReadFile(circuitPath + "/" + ioCommitmentFile)
+	if os.IsNotExist(err) {
+		return commitment, nil
+	}
+	if err != nil {
+		return commitment, fmt.Errorf("failed to read io commitment: %w", err)
+	}
+	err = json.Unmarshal(data, &commitment)
+	if err != nil {
+		return commitment, fmt.Errorf("failed to parse io commitment: %w", serializationError(err))
+	}
+	return commitment, commitment.validate()
+}
+
+func (c IOCommitment) validate() error {
+	if _, ok := ioHashNames[c.Hash]; !ok {
+		return fmt.Errorf("unknown io hash %d", int(c.Hash))
+	}
+	if _, ok := ioTruncationNames[c.Truncation]; !ok {
+		return fmt.Errorf("unknown io truncation %d", int(c.Truncation))
+	}
+	if c.NumInputBytes < 0 {
+		return fmt.Errorf("invalid number of input bytes %d", c.NumInputBytes)
+	}
+	return nil
+}
+
+// split returns the public inputs that commit to the inputs and to the outputs.
+func (c IOCommitment) split(nbPublicInputs int) (int, error) {
+	if !c.HashInCircuit {
+		if nbPublicInputs != 64 {
+			return 0, fmt.Errorf("expected 64 public inputs, got %d", nbPublicInputs)
+		}
+		return 32, nil
+	}
+	if c.NumInputBytes > nbPublicInputs {
+		return 0, fmt.Errorf("expected at least %d public inputs, got %d", c.NumInputBytes, nbPublicInputs)
+	}
+	return c.NumInputBytes, nil
+}
+
+// Digests computes the input and output hashes committed to by the public inputs of a plonky2 proof.
+func (c IOCommitment) Digests(publicInputs []uint64) (*big.Int, *big.Int, error) {
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	split, err := c.split(len(publicInputs))
+	if err != nil {
+		return nil, nil, err
+	}
+	inputHash, err := c.digest(publicInputs[:split])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid input hash: %w", err)
+	}
+	outputHash, err := c.digest(publicInputs[split:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid output hash: %w", err)
+	}
+	return inputHash, outputHash, nil
+}
+
+// Commit computes the hash the gateway commits to raw input or output bytes with, i.e. the
+// InputHash or OutputHash a proof for those bytes must have.
+func (c IOCommitment) Commit(data []byte) (*big.Int, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	publicInputs := make([]uint64, len(data))
+	for i, b := range data {
+		publicInputs[i] = uint64(b)
+	}
+	c.HashInCircuit = true
+	return c.digest(publicInputs)
+}
+
+func (c IOCommitment) digest(publicInputs []uint64) (*big.Int, error) {
+	data := make([]byte, len(publicInputs))
+	for i, v := range publicInputs {
+		if v > 0xff {
+			return nil, fmt.Errorf("public input %d is not a byte", i)
+		}
+		data[i] = byte(v)
+	}
+
+	if c.HashInCircuit {
+		var sum [32]byte
+		switch c.Hash {
+		case IOHashSHA256:
+			sum = sha256.HashNative(data)
+		case IOHashKeccak256:
+			sum = keccak256.HashNative(data)
+		case IOHashBlake3:
+			sum = blake3.HashNative(data)
+		}
+		data = sum[:]
+		if c.Truncation == IOTruncateMask {
+			data[0] &= 0x1f
+		}
+	}
+
+	digest := new(big.Int).SetBytes(data)
+	switch c.Truncation {
+	case IOTruncateMask:
+		if digest.BitLen() > 253 {
+			return nil, fmt.Errorf("digest must be at most 253 bits")
+		}
+	case IOTruncateReduce:
+		digest.Mod(digest, ecc.BN254.ScalarField())
+	}
+	return digest, nil
+}
+
+// digests is the in-circuit counterpart of Digests.
+func (c IOCommitment) digests(api frontend.API, publicInputs []gl.Variable) (frontend.Variable, frontend.Variable, error) {
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	split, err := c.split(len(publicInputs))
+	if err != nil {
+		return nil, nil, err
+	}
+	inputHash, err := c.circuitDigest(api, publicInputs[:split])
+	if err != nil {
+		return nil, nil, err
+	}
+	outputHash, err := c.circuitDigest(api, publicInputs[split:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return inputHash, outputHash, nil
+}
+
+func (c IOCommitment) circuitDigest(api frontend.API, publicInputs []gl.Variable) (frontend.Variable, error) {
+	digest := make([]frontend.Variable, len(publicInputs))
+	for i := range publicInputs {
+		digest[i] = publicInputs[i].Limb
+	}
+
+	if c.HashInCircuit {
+		// The hashers of gnarkx decompose every byte with api.ToBinary, which also checks that the
+		// public inputs are bytes. The byte hashers of gnark are not used, as they rely on the range
+		// checker of gnark, whose checks are not sound in the version of gnark the circuit is compiled
+		// with.
+		b := builder.NewAPI(api)
+		data := make([]vars.Byte, len(digest))
+		for i := range digest {
+			data[i] = vars.Byte{Value: vars.Variable{Value: digest[i]}}
+		}
+		var sum [32]vars.Byte
+		switch c.Hash {
+		case IOHashSHA256:
+			sum = sha256.Hash(*b, data)
+		case IOHashKeccak256:
+			sum = keccak256.Hash(*b, data)
+		case IOHashBlake3:
+			sum = blake3.Hash(*b, data)
+		}
+		digest = make([]frontend.Variable, len(sum))
+		for i := range sum {
+			digest[i] = sum[i].Value.Value
+		}
+		if c.Truncation == IOTruncateMask {
+			bits := api.ToBinary(digest[0], 8)
+			digest[0] = api.FromBinary(bits[:5]...)
+		}
+	} else if c.Truncation == IOTruncateMask {
+		// The plonky2 circuit masked the digest, check that it did. The range checker of gnark is not
+		// used, as its checks are not sound in the version of gnark the circuit is compiled with.
+		api.ToBinary(digest[0], 5)
+	}
+
+	// The digest is read as a big-endian integer. With IOTruncateReduce, the sum wraps around the
+	// scalar field, which is exactly the reduction modulo r.
+	packed := frontend.Variable(0)
+	for i := range digest {
+		shift := new(big.Int).Lsh(big.NewInt(1), uint(8*(len(digest)-1-i)))
+		packed = api.Add(packed, api.Mul(digest[i], shift))
+	}
+	return packed, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	gl "github.com/succinctlabs/gnark-plonky2-verifier/goldilocks"
+	"github.com/succinctlabs/succinctx/gnarkx/hash/blake3"
+)
+
+type ioCommitmentCircuit struct {
+	InputHash    frontend.Variable `gnark:",public"`
+	OutputHash   frontend.Variable `gnark:",public"`
+	PublicInputs []gl.Variable
+	Commitment   IOCommitment `gnark:"-"`
+}
+
+func (c *ioCommitmentCircuit) Define(api frontend.API) error {
+	inputHash, outputHash, err := c.Commitment.digests(api, c.PublicInputs)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.InputHash, inputHash)
+	api.AssertIsEqual(c.OutputHash, outputHash)
+	return nil
+}
+
+func bytesToPublicInputs(data ...[]byte) []uint64 {
+	var publicInputs []uint64
+	for _, d := range data {
+		for _, b := range d {
+			publicInputs = append(publicInputs, uint64(b))
+		}
+	}
+	return publicInputs
+}
+
+func checkIOCommitment(t *testing.T, commitment IOCommitment, publicInputs []uint64) (*big.Int, *big.Int) {
+	inputHash, outputHash, err := commitment.Digests(publicInputs)
+	assert.NoError(t, err)
+
+	circuit := &ioCommitmentCircuit{
+		PublicInputs: make([]gl.Variable, len(publicInputs)),
+		Commitment:   commitment,
+	}
+	assignment := &ioCommitmentCircuit{
+		InputHash:    inputHash,
+		OutputHash:   outputHash,
+		PublicInputs: gl.Uint64ArrayToVariableArray(publicInputs),
+	}
+	assert.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	assignment.InputHash = new(big.Int).Add(inputHash, big.NewInt(1))
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	return inputHash, outputHash
+}
+
+func TestIOCommitmentPrecomputed(t *testing.T) {
+	input := sha256.Sum256([]byte("input"))
+	output := sha256.Sum256([]byte("output"))
+
+	// The plonky2 circuit commits to the full digests, which are reduced by the verifier circuit.
+	inputHash, outputHash := checkIOCommitment(t, IOCommitment{Truncation: IOTruncateReduce}, bytesToPublicInputs(input[:], output[:]))
+	r := ecc.BN254.ScalarField()
+	assert.Equal(t, new(big.Int).Mod(new(big.Int).SetBytes(input[:]), r), inputHash)
+	assert.Equal(t, new(big.Int).Mod(new(big.Int).SetBytes(output[:]), r), outputHash)
+
+	// The plonky2x WrappedCircuit masks the digests to 253 bits.
+	input[0] &= 0x1f
+	output[0] &= 0x1f
+	inputHash, outputHash = checkIOCommitment(t, IOCommitment{}, bytesToPublicInputs(input[:], output[:]))
+	assert.Equal(t, new(big.Int).SetBytes(input[:]), inputHash)
+	assert.Equal(t, new(big.Int).SetBytes(output[:]), outputHash)
+
+	// An unmasked digest is rejected.
+	input[0] |= 0x80
+	_, _, err := IOCommitment{}.Digests(bytesToPublicInputs(input[:], output[:]))
+	assert.Error(t, err)
+	circuit := &ioCommitmentCircuit{PublicInputs: make([]gl.Variable, 64)}
+	assignment := &ioCommitmentCircuit{
+		InputHash:    new(big.Int).SetBytes(input[:]),
+		OutputHash:   outputHash,
+		PublicInputs: gl.Uint64ArrayToVariableArray(bytesToPublicInputs(input[:], output[:])),
+	}
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+func TestIOCommitmentHashInCircuit(t *testing.T) {
+	input := []byte("some function input")
+	output := []byte("output")
+	publicInputs := bytesToPublicInputs(input, output)
+
+	commitment := IOCommitment{Hash: IOHashKeccak256, HashInCircuit: true, NumInputBytes: len(input)}
+	inputHash, _ := checkIOCommitment(t, commitment, publicInputs)
+	expected := crypto.Keccak256(input)
+	expected[0] &= 0x1f
+	assert.Equal(t, new(big.Int).SetBytes(expected), inputHash)
+
+	commitment = IOCommitment{Hash: IOHashSHA256, Truncation: IOTruncateReduce, HashInCircuit: true, NumInputBytes: len(input)}
+	_, outputHash := checkIOCommitment(t, commitment, publicInputs)
+	sum := sha256.Sum256(output)
+	assert.Equal(t, new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), ecc.BN254.ScalarField()), outputHash)
+
+	commitment = IOCommitment{Hash: IOHashBlake3, HashInCircuit: true, NumInputBytes: len(input)}
+	inputHash, _ = checkIOCommitment(t, commitment, publicInputs)
+	sum = blake3.HashNative(input)
+	sum[0] &= 0x1f
+	assert.Equal(t, new(big.Int).SetBytes(sum[:]), inputHash)
+
+	// Public inputs which are not bytes are rejected by the verifier circuit too, even if they are
+	// equal to a byte modulo 256.
+	commitment = IOCommitment{HashInCircuit: true, NumInputBytes: 1}
+	inputHash, outputHash = checkIOCommitment(t, commitment, []uint64{0})
+	circuit := &ioCommitmentCircuit{PublicInputs: make([]gl.Variable, 1), Commitment: commitment}
+	assignment := &ioCommitmentCircuit{
+		InputHash:    inputHash,
+		OutputHash:   outputHash,
+		PublicInputs: gl.Uint64ArrayToVariableArray([]uint64{256}),
+	}
+	assert.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+func TestIOCommitmentValidate(t *testing.T) {
+	_, _, err := IOCommitment{Hash: IOHash(3), HashInCircuit: true}.Digests(nil)
+	assert.Error(t, err)
+	_, _, err = IOCommitment{Hash: IOHashKeccak256, HashInCircuit: true, NumInputBytes: 3}.Digests([]uint64{1, 2})
+	assert.Error(t, err)
+	_, _, err = IOCommitment{}.Digests(make([]uint64, 63))
+	assert.Error(t, err)
+	_, _, err = IOCommitment{Hash: IOHashKeccak256, HashInCircuit: true}.Digests([]uint64{256})
+	assert.Error(t, err)
+}
+
+func TestReadIOCommitment(t *testing.T) {
+	dir := t.TempDir()
+	commitment, err := ReadIOCommitment(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, IOCommitment{}, commitment)
+
+	expected := IOCommitment{Hash: IOHashKeccak256, Truncation: IOTruncateReduce, HashInCircuit: true, NumInputBytes: 32}
+	data, err := json.Marshal(expected)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"hash":"keccak256","truncation":"mod","hash_in_circuit":true,"num_input_bytes":32}`, string(data))
+	assert.NoError(t, os.WriteFile(dir+"/"+ioCommitmentFile, data, 0644))
+	commitment, err = ReadIOCommitment(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, commitment)
+
+	assert.NoError(t, os.WriteFile(dir+"/"+ioCommitmentFile, []byte(`{"hash":"blake2b"}`), 0644))
+	_, err = ReadIOCommitment(dir)
+	assert.Error(t, err)
+	assert.NoError(t, os.WriteFile(dir+"/"+ioCommitmentFile, []byte(`{"hash":"md5"}`), 0644))
+	_, err = ReadIOCommitment(dir)
+	assert.Error(t, err)
+}
+
+func TestIOCommitmentCommit(t *testing.T) {
+	input := []byte("some function input")
+
+	// The commitment to raw bytes is the digest the verifier circuit computes from them.
+	for _, commitment := range []IOCommitment{{}, {Hash: IOHashKeccak256, Truncation: IOTruncateReduce}, {Hash: IOHashBlake3}} {
+		inputHash, err := commitment.Commit(input)
+		assert.NoError(t, err)
+		hashInCircuit := commitment
+		hashInCircuit.HashInCircuit = true
+		hashInCircuit.NumInputBytes = len(input)
+		expected, _, err := hashInCircuit.Digests(bytesToPublicInputs(input))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, inputHash)
+	}
+}
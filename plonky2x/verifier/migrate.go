@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"sort"
+
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrIncompatibleUpgrade is returned by the migrate command with -strict if the new circuit
+// breaks the verifiers deployed for the old one.
+var ErrIncompatibleUpgrade = errors.New("circuit upgrade is incompatible with the deployed verifiers")
+
+// CircuitArtifacts is what a circuit upgrade is checked on: the public input layout and the
+// verifying key of a data directory, the checksums of its artifacts, and the digest of the
+// plonky2 circuit it verifies proofs of, if known.
+type CircuitArtifacts struct {
+	DataPath         string                   `json:"data_path"`
+	PublicInputs     []string                 `json:"public_inputs"`
+	VerifyingKeyHash common.Hash              `json:"vk_hash"`
+	CircuitDigest    *hexutil.Big             `json:"circuit_digest,omitempty"`
+	Checksums        map[string]hexutil.Bytes `json:"checksums,omitempty"`
+}
+
+// ReadCircuitArtifacts reads the artifacts of the data directory, and the circuit digest of the
+// plonky2 circuit in circuitPath, if it is not empty. Only the verifying key and the checksums are
+// read, so large constraint systems and proving keys can be compared quickly.
+func ReadCircuitArtifacts(dataPath string, circuitPath string, unsafeDeserialize bool) (*CircuitArtifacts, error) {
+	vk, err := LoadVerifierKey(dataPath, unsafeDeserialize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the verifying key of %s: %w", dataPath, err)
+	}
+	_vk, ok := vk.(*plonk_bn254.VerifyingKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported verifying key %T", vk)
+	}
+	publicInputs, err := publicInputLabels(int(_vk.NbPublicVariables))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a verifier circuit: %w", dataPath, err)
+	}
+	artifacts := &CircuitArtifacts{
+		DataPath:         dataPath,
+		PublicInputs:     publicInputs,
+		VerifyingKeyHash: crypto.Keccak256Hash(VerifyingKeyRegistration(_vk)),
+	}
+
+	// Data directories written before checksums were recorded are compared without them.
+	data, err := os.ReadFile(dataPath + "/" + checksumsFileName)
+	if err == nil {
+		if err := json.Unmarshal(data, &artifacts.Checksums); err != nil {
+			return nil, fmt.Errorf("failed to parse checksums file: %w", serializationError(err))
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read checksums file: %w", err)
+	}
+
+	if circuitPath != "" {
+		_, verifierData, err := readPlonky2Data(circuitPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the verifier data of %s: %w", circuitPath, err)
+		}
+		circuitDigest, ok := new(big.Int).SetString(verifierData.CircuitDigest, 10)
+		if !ok {
+			return nil, serializationError(fmt.Errorf("invalid circuit digest %q", verifierData.CircuitDigest))
+		}
+		artifacts.CircuitDigest = (*hexutil.Big)(circuitDigest)
+	}
+	return artifacts, nil
+}
+
+// CompatibilityReport is the machine readable result of comparing the artifacts of a circuit
+// before and after an upgrade, for gating releases.
+type CompatibilityReport struct {
+	// Compatible reports whether the upgrade can be rolled out without deploying and registering
+	// new verifiers.
+	Compatible bool `json:"compatible"`
+
+	Old *CircuitArtifacts `json:"old"`
+	New *CircuitArtifacts `json:"new"`
+
+	PublicInputsChanged  bool     `json:"public_inputs_changed"`
+	AddedPublicInputs    []string `json:"added_public_inputs,omitempty"`
+	RemovedPublicInputs  []string `json:"removed_public_inputs,omitempty"`
+	VerifyingKeyChanged  bool     `json:"vk_changed"`
+	CircuitDigestChanged bool     `json:"circuit_digest_changed"`
+	// The artifacts whose checksums differ, or which only one of the directories has a checksum of.
+	ChangedArtifacts []string `json:"changed_artifacts,omitempty"`
+
+	// VerifierCompatible reports whether the deployed Verifier.sol of the old circuit accepts the
+	// proofs of the new one, i.e. whether the verifying key is unchanged.
+	VerifierCompatible bool `json:"verifier_compatible"`
+	// FunctionVerifierCompatible reports whether the function verifier registered on the gateway
+	// for the old circuit accepts the proofs of the new one, which additionally requires the same
+	// public inputs and the same circuit digest, which the function verifier checks.
+	FunctionVerifierCompatible bool `json:"function_verifier_compatible"`
+	// The reasons the upgrade requires new verifiers to be deployed and registered.
+	BreakingChanges []string `json:"breaking_changes,omitempty"`
+	// Warnings are the checks which could not be made, e.g. of unknown circuit digests.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CompareCircuits compares the artifacts of the circuit before and after the upgrade.
+func CompareCircuits(before *CircuitArtifacts, after *CircuitArtifacts) *CompatibilityReport {
+	report := &CompatibilityReport{Old: before, New: after}
+
+	report.AddedPublicInputs = missingFrom(after.PublicInputs, before.PublicInputs)
+	report.RemovedPublicInputs = missingFrom(before.PublicInputs, after.PublicInputs)
+	report.PublicInputsChanged = !equalStrings(before.PublicInputs, after.PublicInputs)
+	if report.PublicInputsChanged {
+		report.BreakingChanges = append(report.BreakingChanges, fmt.Sprintf("public inputs changed from %v to %v", before.PublicInputs, after.PublicInputs))
+	}
+
+	report.VerifyingKeyChanged = before.VerifyingKeyHash != after.VerifyingKeyHash
+	if report.VerifyingKeyChanged {
+		report.BreakingChanges = append(report.BreakingChanges, fmt.Sprintf("verifying key changed from %s to %s", before.VerifyingKeyHash.Hex(), after.VerifyingKeyHash.Hex()))
+	}
+
+	if before.CircuitDigest == nil || after.CircuitDigest == nil {
+		report.Warnings = append(report.Warnings, "circuit digests are unknown, pass the plonky2 circuits of both versions to compare them")
+	} else if before.CircuitDigest.ToInt().Cmp(after.CircuitDigest.ToInt()) != 0 {
+		report.CircuitDigestChanged = true
+		report.BreakingChanges = append(report.BreakingChanges, fmt.Sprintf("circuit digest changed from %s to %s", before.CircuitDigest, after.CircuitDigest))
+	}
+
+	if before.Checksums == nil || after.Checksums == nil {
+		report.Warnings = append(report.Warnings, "artifact checksums are missing, artifacts were not compared")
+	} else {
+		files := make(map[string]bool)
+		for file := range before.Checksums {
+			files[file] = true
+		}
+		for file := range after.Checksums {
+			files[file] = true
+		}
+		for file := range files {
+			if before.Checksums[file].String() != after.Checksums[file].String() {
+				report.ChangedArtifacts = append(report.ChangedArtifacts, file)
+			}
+		}
+		sort.Strings(report.ChangedArtifacts)
+	}
+
+	report.VerifierCompatible = !report.VerifyingKeyChanged
+	report.FunctionVerifierCompatible = report.VerifierCompatible && !report.PublicInputsChanged && !report.CircuitDigestChanged
+	report.Compatible = report.FunctionVerifierCompatible
+	return report
+}
+
+// missingFrom returns the values of a which are not in b.
+func missingFrom(a []string, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, value := range b {
+		in[value] = true
+	}
+	var missing []string
+	for _, value := range a {
+		if !in[value] {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
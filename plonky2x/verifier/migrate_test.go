@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// migrationCircuit has the public inputs of the verifier circuit, or of the fixed verifier circuit,
+// and a number of constraints which depends on N.
+type migrationCircuit struct {
+	Public []frontend.Variable `gnark:",public"`
+	Y      frontend.Variable
+	N      int `gnark:"-"`
+}
+
+func (c *migrationCircuit) Define(api frontend.API) error {
+	acc := c.Y
+	for i := 0; i < c.N; i++ {
+		acc = api.Mul(acc, c.Y)
+	}
+	for _, input := range c.Public {
+		api.AssertIsDifferent(input, acc)
+	}
+	return nil
+}
+
+func saveMigrationCircuit(t *testing.T, nbPublicInputs int, n int) string {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &migrationCircuit{Public: make([]frontend.Variable, nbPublicInputs), N: n})
+	assert.NoError(t, err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(t, err)
+	pk, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(t, err)
+	path := t.TempDir()
+	assert.NoError(t, SaveVerifierCircuit(path, ccs, pk, vk, CompressionNone))
+	return path
+}
+
+func TestCompareCircuits(t *testing.T) {
+	read := func(path string) *CircuitArtifacts {
+		artifacts, err := ReadCircuitArtifacts(path, "", false)
+		assert.NoError(t, err)
+		return artifacts
+	}
+	deployed := read(saveMigrationCircuit(t, 3, 10))
+	assert.Equal(t, []string{"verifierDigest", "inputHash", "outputHash"}, deployed.PublicInputs)
+
+	// The same artifacts are compatible.
+	report := CompareCircuits(deployed, deployed)
+	assert.True(t, report.Compatible)
+	assert.Empty(t, report.BreakingChanges)
+	assert.Empty(t, report.ChangedArtifacts)
+	assert.Len(t, report.Warnings, 1)
+
+	// A new verifying key requires a new verifier.
+	report = CompareCircuits(deployed, read(saveMigrationCircuit(t, 3, 20)))
+	assert.False(t, report.Compatible)
+	assert.False(t, report.VerifierCompatible)
+	assert.True(t, report.VerifyingKeyChanged)
+	assert.False(t, report.PublicInputsChanged)
+	assert.Contains(t, report.ChangedArtifacts, "vk.bin")
+
+	// Switching to a fixed verifier circuit removes the verifier digest public input.
+	report = CompareCircuits(deployed, read(saveMigrationCircuit(t, 2, 10)))
+	assert.True(t, report.PublicInputsChanged)
+	assert.Equal(t, []string{"verifierDigest"}, report.RemovedPublicInputs)
+	assert.Empty(t, report.AddedPublicInputs)
+
+	// A new circuit digest breaks the function verifier, even with the same verifying key.
+	before, after := *deployed, *deployed
+	before.CircuitDigest = (*hexutil.Big)(big.NewInt(1))
+	after.CircuitDigest = (*hexutil.Big)(big.NewInt(2))
+	report = CompareCircuits(&before, &after)
+	assert.True(t, report.VerifierCompatible)
+	assert.False(t, report.FunctionVerifierCompatible)
+	assert.True(t, report.CircuitDigestChanged)
+	assert.Empty(t, report.Warnings)
+
+	_, err := ReadCircuitArtifacts(saveMigrationCircuit(t, 1, 10), "", false)
+	assert.ErrorContains(t, err, "not a verifier circuit")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gnark_verifier_types "github.com/succinctlabs/gnark-plonky2-verifier/types"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// PipeInput is the plonky2 proof read from stdin by prove -pipe, i.e. the JSON files of a circuit
+// path in a single object. Without an io commitment, the default one is used, like for circuit
+// paths without io_commitment.json.
+type PipeInput struct {
+	ProofWithPublicInputs   gnark_verifier_types.ProofWithPublicInputsRaw   `json:"proof_with_public_inputs"`
+	VerifierOnlyCircuitData gnark_verifier_types.VerifierOnlyCircuitDataRaw `json:"verifier_only_circuit_data"`
+	IOCommitment            IOCommitment                                    `json:"io_commitment"`
+}
+
+// ProofBundle is the proof written to stdout by prove -pipe, i.e. the contents of proof.json,
+// proof_with_witness.json and public_witness.json saved by SaveProof in a single object.
+type ProofBundle struct {
+	Result         *types.ProofResult   `json:"result"`
+	InputHash      hexutil.Bytes        `json:"input_hash"`
+	OutputHash     hexutil.Bytes        `json:"output_hash"`
+	VerifierDigest hexutil.Bytes        `json:"verifier_digest"`
+	PublicInputs   []LabeledPublicInput `json:"public_inputs"`
+}
+
+// ReadPipeInput decodes the plonky2 proof from r, and builds the assignment of the verifier circuit
+// for it. Malformed proofs fail with a WitnessError.
+func ReadPipeInput(r io.Reader) (assignment *Plonky2xVerifierCircuit, err error) {
+	var input PipeInput
+	if err := json.NewDecoder(r).Decode(&input); err != nil {
+		return nil, &WitnessError{Err: fmt.Errorf("failed to parse plonky2 proof: %w", serializationError(err))}
+	}
+	if input.VerifierOnlyCircuitData.CircuitDigest == "" {
+		return nil, &WitnessError{Err: serializationError(errors.New("verifier_only_circuit_data is missing"))}
+	}
+	if err := input.IOCommitment.validate(); err != nil {
+		return nil, &WitnessError{Err: fmt.Errorf("invalid io commitment: %w", err)}
+	}
+	defer recoverWitnessError(&err)
+	assignment, err = NewAssignment(input.ProofWithPublicInputs, input.VerifierOnlyCircuitData, input.IOCommitment)
+	if err != nil {
+		return nil, &WitnessError{Err: err}
+	}
+	return assignment, nil
+}
+
+// NewProofBundle creates the bundle of the proof of the assignment.
+func NewProofBundle(assignment *Plonky2xVerifierCircuit, proof plonk.Proof, publicWitness witness.Witness, vk plonk.VerifyingKey) (*ProofBundle, error) {
+	// Output will be filled in by plonky2x CLI, like in proof.json.
+	result, err := NewProofResult(assignment, proof, vk, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	labeled, err := LabelPublicWitness(publicWitness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to label public witness: %w", err)
+	}
+	return &ProofBundle{
+		Result:         result,
+		InputHash:      assignment.InputHash.(*big.Int).Bytes(),
+		OutputHash:     assignment.OutputHash.(*big.Int).Bytes(),
+		VerifierDigest: assignment.VerifierDigest.(*big.Int).Bytes(),
+		PublicInputs:   labeled,
+	}, nil
+}
+
+// ProvePipe proves the plonky2 proof read from r, see PipeInput, verifies the proof, and writes its
+// bundle as a line of JSON to w, without any temporary files.
+func ProvePipe(r io.Reader, w io.Writer, config CircuitConfig, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey) error {
+	assignment, err := ReadPipeInput(r)
+	if err != nil {
+		return err
+	}
+	if err := config.CheckAssignment(assignment); err != nil {
+		return err
+	}
+	proof, publicWitness, err := GenerateProof(assignment, config, r1cs, pk)
+	if err != nil {
+		return err
+	}
+	err = VerifyProof(proof, vk, publicWitness)
+	if err != nil {
+		return fmt.Errorf("failed to verify proof: %w", err)
+	}
+	bundle, err := NewProofBundle(assignment, proof, publicWitness, vk)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(bundle); err != nil {
+		return fmt.Errorf("failed to write proof bundle: %w", err)
+	}
+	return nil
+}
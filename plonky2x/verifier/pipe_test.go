@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+func TestReadPipeInput(t *testing.T) {
+	var witnessErr *WitnessError
+
+	_, err := ReadPipeInput(strings.NewReader("not json"))
+	assert.ErrorAs(t, err, &witnessErr)
+	assert.ErrorIs(t, err, types.ErrSerialization)
+
+	_, err = ReadPipeInput(strings.NewReader(`{"proof_with_public_inputs": {}}`))
+	assert.ErrorAs(t, err, &witnessErr)
+	assert.ErrorContains(t, err, "verifier_only_circuit_data is missing")
+
+	// A truncated proof fails with a WitnessError rather than panicking in the deserializer.
+	_, err = ReadPipeInput(strings.NewReader(`{"verifier_only_circuit_data": {"circuit_digest": "1"}}`))
+	assert.ErrorAs(t, err, &witnessErr)
+}
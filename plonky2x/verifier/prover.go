@@ -1,20 +1,27 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
-	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/plonk"
 	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/logger"
 	gnark_verifier_types "github.com/succinctlabs/gnark-plonky2-verifier/types"
@@ -23,118 +30,273 @@ import (
 	"github.com/succinctlabs/succinctx/gnarkx/types"
 )
 
-func LoadProverData(path string) (constraint.ConstraintSystem, plonk.ProvingKey, error) {
+// LoadProverData reads the constraint system and the proving key in the data directory. Both are
+// read concurrently, and may be compressed, e.g. as r1cs.bin.zst. With unsafeDeserialize, the
+// proving key is read without subgroup checks, which takes about half the time, after its checksum
+// has been verified against the checksums file of the directory.
+func LoadProverData(path string, unsafeDeserialize bool) (constraint.ConstraintSystem, plonk.ProvingKey, error) {
 	log := logger.Logger()
-	r1csFile, err := os.Open(path + "/r1cs.bin")
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open r1cs file: %w", err)
+	if unsafeDeserialize {
+		start := time.Now()
+		if err := VerifyChecksum(path, "pk.bin"); err != nil {
+			return nil, nil, fmt.Errorf("refusing to deserialize pk file without subgroup checks: %w", err)
+		}
+		log.Debug().Msg("Successfully verified proving key checksum, time: " + time.Since(start).String())
 	}
+
 	r1cs := plonk.NewCS(ecc.BN254)
-	start := time.Now()
-	r1csReader := bufio.NewReader(r1csFile)
-	_, err = r1cs.ReadFrom(r1csReader)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read r1cs file: %w", err)
+	pk := plonk.NewProvingKey(ecc.BN254)
+	var r1csErr, pkErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		r1csErr = loadArtifact(path, "r1cs.bin", "constraint system", r1cs.ReadFrom)
+	}()
+	go func() {
+		defer wg.Done()
+		readFrom := pk.ReadFrom
+		if unsafeDeserialize {
+			readFrom = pk.UnsafeReadFrom
+		}
+		pkErr = loadArtifact(path, "pk.bin", "proving key", readFrom)
+	}()
+	wg.Wait()
+	if r1csErr != nil {
+		return nil, nil, fmt.Errorf("failed to read r1cs file: %w", r1csErr)
 	}
-	r1csFile.Close()
-	elapsed := time.Since(start)
-	log.Debug().Msg("Successfully loaded constraint system, time: " + elapsed.String())
+	if pkErr != nil {
+		return nil, nil, fmt.Errorf("failed to read pk file: %w", pkErr)
+	}
+	return r1cs, pk, nil
+}
 
-	pkFile, err := os.Open(path + "/pk.bin")
+// loadArtifact reads the artifact with the name in the directory, which may be compressed, with
+// readFrom and logs how long it took.
+func loadArtifact(dir string, name string, description string, readFrom func(io.Reader) (int64, error)) error {
+	log := logger.Logger()
+	start := time.Now()
+	path, err := artifactPath(dir, name)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open pk file: %w", err)
+		return err
 	}
-	pk := plonk.NewProvingKey(ecc.BN254)
-	start = time.Now()
-	pkReader := bufio.NewReader(pkFile)
-	_, err = pk.ReadFrom(pkReader)
+	reader, err := openArtifact(path)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read pk file: %w", err)
+		return err
 	}
-	pkFile.Close()
-	elapsed = time.Since(start)
-	log.Debug().Msg("Successfully loaded proving key, time: " + elapsed.String())
-
-	return r1cs, pk, nil
+	defer reader.Close()
+	if _, err := readFrom(reader); err != nil {
+		return serializationError(err)
+	}
+	msg := "Successfully loaded " + description + ", time: " + time.Since(start).String()
+	if reader.compression != CompressionNone {
+		msg += " (" + string(reader.compression) + ")"
+	}
+	log.Debug().Msg(msg)
+	return nil
 }
 
 func GetInputHashOutputHash(proofWithPis gnark_verifier_types.ProofWithPublicInputsRaw) (*big.Int, *big.Int) {
-	publicInputs := proofWithPis.PublicInputs
-	if len(publicInputs) != 64 {
-		panic("publicInputs must be 64 bytes")
+	inputHash, outputHash, err := IOCommitment{}.Digests(proofWithPis.PublicInputs)
+	if err != nil {
+		panic(err)
 	}
-	publicInputsBytes := make([]byte, 64)
-	for i, v := range publicInputs {
-		publicInputsBytes[i] = byte(v & 0xFF)
+	return inputHash, outputHash
+}
+
+// LoadAssignment reads the plonky2x proof and verifier data under circuitPath, in binary or JSON
+// form, and builds the corresponding witness assignment for the verifier circuit.
+func LoadAssignment(circuitPath string) *Plonky2xVerifierCircuit {
+	proofWithPis, verifierOnlyCircuitDataRaw, err := readPlonky2Data(circuitPath)
+	if err != nil {
+		panic(err)
 	}
-	inputHash := new(big.Int).SetBytes(publicInputsBytes[0:32])
-	outputHash := new(big.Int).SetBytes(publicInputsBytes[32:64])
-	if inputHash.BitLen() > 253 {
-		panic("inputHash must be at most 253 bits")
+	ioCommitment, err := ReadIOCommitment(circuitPath)
+	if err != nil {
+		panic(err)
 	}
-	if outputHash.BitLen() > 253 {
-		panic("outputHash must be at most 253 bits")
+	assignment, err := NewAssignment(proofWithPis, verifierOnlyCircuitDataRaw, ioCommitment)
+	if err != nil {
+		panic(err)
 	}
-	return inputHash, outputHash
+	return assignment
 }
 
-func Prove(circuitPath string, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey) (plonk.Proof, witness.Witness, error) {
-	log := logger.Logger()
-
-	verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(
-		gnark_verifier_types.ReadVerifierOnlyCircuitData(circuitPath + "/verifier_only_circuit_data.json"),
-	)
-	proofWithPis := gnark_verifier_types.ReadProofWithPublicInputs(circuitPath + "/proof_with_public_inputs.json")
-	proofWithPisVariable := variables.DeserializeProofWithPublicInputs(proofWithPis)
+// NewAssignment builds the witness assignment of the verifier circuit for the plonky2x proof, the
+// verifier data of its circuit and the commitment to its IO. It returns an error if the public
+// inputs of the proof are not committed to as the IO commitment describes. The deserializers of the
+// proof and the verifier data panic if they are malformed, which callers recover from with
+// recoverWitnessError, see TryLoadAssignment and ReadPipeInput.
+func NewAssignment(proofWithPis gnark_verifier_types.ProofWithPublicInputsRaw, verifierOnlyCircuitDataRaw gnark_verifier_types.VerifierOnlyCircuitDataRaw, ioCommitment IOCommitment) (*Plonky2xVerifierCircuit, error) {
+	verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(verifierOnlyCircuitDataRaw)
+	proofWithPisVariable := DeserializeProofWithPublicInputs(proofWithPis)
 
-	inputHash, outputHash := GetInputHashOutputHash(proofWithPis)
+	inputHash, outputHash, err := ioCommitment.Digests(proofWithPis.PublicInputs)
+	if err != nil {
+		return nil, err
+	}
 
-	// Circuit assignment
-	assignment := &Plonky2xVerifierCircuit{
+	return &Plonky2xVerifierCircuit{
 		ProofWithPis:   proofWithPisVariable,
 		VerifierData:   verifierOnlyCircuitData,
 		VerifierDigest: verifierOnlyCircuitData.CircuitDigest,
 		InputHash:      frontend.Variable(inputHash),
 		OutputHash:     frontend.Variable(outputHash),
+		IOCommitment:   ioCommitment,
+	}, nil
+}
+
+// TryLoadAssignment is LoadAssignment, but returns a WitnessError instead of panicking if the
+// plonky2 data under circuitPath is missing or malformed.
+func TryLoadAssignment(circuitPath string) (assignment *Plonky2xVerifierCircuit, err error) {
+	defer recoverWitnessError(&err)
+	return LoadAssignment(circuitPath), nil
+}
+
+// recoverWitnessError recovers from the panic of a plonky2 data reader, and sets err to a
+// WitnessError.
+func recoverWitnessError(err *error) {
+	if r := recover(); r != nil {
+		// The readers panic with the error of a missing or malformed file, which is kept.
+		cause, ok := r.(error)
+		if !ok {
+			cause = fmt.Errorf("%v", r)
+		}
+		*err = &WitnessError{Err: fmt.Errorf("failed to load plonky2 proof: %w", cause)}
 	}
+}
+
+// GenerateProof computes the witness for the assignment and creates a proof for it with the verifier
+// circuit of the config, without writing anything to disk.
+func GenerateProof(assignment *Plonky2xVerifierCircuit, config CircuitConfig, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey) (plonk.Proof, witness.Witness, error) {
+	return GenerateProofWithRetry(context.Background(), RetryPolicy{}, assignment, config, r1cs, pk)
+}
+
+// GenerateProofWithRetry is GenerateProof, retrying the prover with the policy. The witness is only
+// generated once and reused by the retries. Invalid assignments fail with a WitnessError, which is
+// never retried, and other failures of the prover with a ProverError.
+func GenerateProofWithRetry(ctx context.Context, policy RetryPolicy, assignment *Plonky2xVerifierCircuit, config CircuitConfig, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey) (plonk.Proof, witness.Witness, error) {
+	log := logger.Logger()
+
+	// The fixed verifier circuit has no VerifierDigest public input.
+	circuit := config.Circuit(assignment)
 
 	log.Debug().Msg("Generating witness")
 	start := time.Now()
-	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	witness, err := frontend.NewWitness(circuit, ecc.BN254.ScalarField())
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate witness: %w", err)
+		return nil, nil, &WitnessError{Err: fmt.Errorf("failed to generate witness: %w: %w", types.ErrWitnessUnsatisfied, err)}
 	}
 	elapsed := time.Since(start)
 	log.Debug().Msg("Successfully generated witness, time: " + elapsed.String())
 
-	log.Debug().Msg("Creating proof")
-	start = time.Now()
-	proof, err := plonk.Prove(r1cs, pk, witness)
+	var proof plonk.Proof
+	err = policy.Do(ctx, func() error {
+		log.Debug().Msg("Creating proof")
+		start = time.Now()
+		proof, err = plonk.Prove(r1cs, pk, witness)
+		if err != nil {
+			return classifyProverError(fmt.Errorf("failed to create proof: %w", err))
+		}
+		elapsed = time.Since(start)
+		log.Info().Msg("Successfully created proof, time: " + elapsed.String())
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicWitness, err := witness.Public()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create proof: %w", err)
+		return nil, nil, fmt.Errorf("failed to get public witness: %w", err)
 	}
-	elapsed = time.Since(start)
-	log.Info().Msg("Successfully created proof, time: " + elapsed.String())
+
+	return proof, publicWitness, nil
+}
+
+// classifyProverError wraps an error of plonk.Prove in a WitnessError if the witness does not
+// satisfy the constraints, which proving it again cannot fix, and in a ProverError otherwise.
+func classifyProverError(err error) error {
+	var unsatisfied *cs_bn254.UnsatisfiedConstraintError
+	if errors.As(err, &unsatisfied) {
+		return &WitnessError{Err: fmt.Errorf("%w: %w", types.ErrWitnessUnsatisfied, err)}
+	}
+	return &ProverError{Err: err}
+}
+
+// Prove generates the wrapper proof for the plonky2 proof in circuitPath with the verifier circuit of
+// the config, retrying the prover with the policy, and saves it to the current working directory.
+// If receiptSigner is not nil, a receipt signed with it is saved as well. Missing or malformed
+// plonky2 data fails with a WitnessError. Without overwrite, Prove fails with ErrOutputExists
+// before proving if the outputs of a previous proof are in the directory.
+func Prove(ctx context.Context, policy RetryPolicy, circuitPath string, config CircuitConfig, r1cs constraint.ConstraintSystem, pk plonk.ProvingKey, receiptSigner types.Signer, overwrite bool) (plonk.Proof, witness.Witness, error) {
+	if err := prepareOutputs(".", overwrite, receiptSigner != nil); err != nil {
+		return nil, nil, err
+	}
+
+	assignment, err := TryLoadAssignment(circuitPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := config.CheckAssignment(assignment); err != nil {
+		return nil, nil, err
+	}
+
+	proof, publicWitness, err := GenerateProofWithRetry(ctx, policy, assignment, config, r1cs, pk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = SaveProofTo(".", overwrite, assignment, proof, publicWitness, pk.VerifyingKey().(plonk.VerifyingKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if receiptSigner != nil {
+		err = SaveReceipt(assignment, proof, receiptSigner)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return proof, publicWitness, nil
+}
+
+// SaveProof writes proof.json, proof_with_witness.json, public_witness.bin and its labeled JSON
+// export public_witness.json to the current working directory. The proof result in proof.json
+// records the circuit and the verifying key vk the proof was generated for.
+func SaveProof(assignment *Plonky2xVerifierCircuit, proof plonk.Proof, publicWitness witness.Witness, vk plonk.VerifyingKey) error {
+	return SaveProofTo(".", true, assignment, proof, publicWitness, vk)
+}
+
+// SaveProofTo is SaveProof, writing the files to dir instead of the current working directory.
+// Each file is written atomically, see writeFileAtomic, so a crash cannot leave a truncated proof
+// behind. Without overwrite, files of a previous proof are not replaced, and ErrOutputExists is
+// returned instead.
+func SaveProofTo(dir string, overwrite bool, assignment *Plonky2xVerifierCircuit, proof plonk.Proof, publicWitness witness.Witness, vk plonk.VerifyingKey) error {
+	log := logger.Logger()
 
 	_proof := proof.(*plonk_bn254.Proof)
 	log.Info().Msg("Saving proof to proof.json")
-	jsonProof, err := json.Marshal(types.ProofResult{
-		// Output will be filled in by plonky2x CLI
-		Output: []byte{},
-		Proof:  _proof.MarshalSolidity(),
-	})
+	// Output will be filled in by plonky2x CLI
+	result, err := NewProofResult(assignment, proof, vk, []byte{})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal proof: %w", err)
+		return err
 	}
-	proofFile, err := os.Create("proof.json")
+	jsonProof, err := json.Marshal(result)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create proof file: %w", err)
+		return fmt.Errorf("failed to marshal proof: %w", err)
 	}
-	_, err = proofFile.Write(jsonProof)
+	err = writeFileAtomic(filepath.Join(dir, "proof.json"), overwrite, func(w io.Writer) error {
+		_, err := w.Write(jsonProof)
+		if err != nil {
+			return fmt.Errorf("failed to write proof file: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to write proof file: %w", err)
+		return err
 	}
-	proofFile.Close()
 	log.Info().Msg("Successfully saved proof")
 
 	// Write proof with all the public inputs and save to disk.
@@ -144,43 +306,101 @@ func Prove(circuitPath string, r1cs constraint.ConstraintSystem, pk plonk.Provin
 		VerifierDigest hexutil.Bytes `json:"verifier_digest"`
 		Proof          hexutil.Bytes `json:"proof"`
 	}{
-		InputHash:      inputHash.Bytes(),
-		OutputHash:     outputHash.Bytes(),
-		VerifierDigest: (verifierOnlyCircuitData.CircuitDigest).(*big.Int).Bytes(),
+		InputHash:      assignment.InputHash.(*big.Int).Bytes(),
+		OutputHash:     assignment.OutputHash.(*big.Int).Bytes(),
+		VerifierDigest: assignment.VerifierDigest.(*big.Int).Bytes(),
 		Proof:          _proof.MarshalSolidity(),
 	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal proof with witness: %w", err)
+		return fmt.Errorf("failed to marshal proof with witness: %w", err)
 	}
-	proofFile, err = os.Create("proof_with_witness.json")
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create proof_with_witness file: %w", err)
-	}
-	_, err = proofFile.Write(jsonProofWithWitness)
+	err = writeFileAtomic(filepath.Join(dir, "proof_with_witness.json"), overwrite, func(w io.Writer) error {
+		_, err := w.Write(jsonProofWithWitness)
+		if err != nil {
+			return fmt.Errorf("failed to write proof_with_witness file: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to write proof_with_witness file: %w", err)
+		return err
 	}
-	proofFile.Close()
 	log.Info().Msg("Proof with witness")
 	log.Info().Msg(string(jsonProofWithWitness))
 	log.Info().Msg("Successfully saved proof_with_witness")
 
-	publicWitness, err := witness.Public()
+	log.Info().Msg("Saving public witness to public_witness.bin and public_witness.json")
+	err = writeFileAtomic(filepath.Join(dir, "public_witness.bin"), overwrite, func(w io.Writer) error {
+		_, err := publicWitness.WriteTo(w)
+		if err != nil {
+			return fmt.Errorf("failed to write public witness file: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get public witness: %w", err)
+		return err
 	}
-
-	log.Info().Msg("Saving public witness to public_witness.bin")
-	witnessFile, err := os.Create("public_witness.bin")
+	err = savePublicWitnessJSON(filepath.Join(dir, "public_witness.json"), overwrite, publicWitness)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create public witness file: %w", err)
+		return err
+	}
+	log.Info().Msg("Successfully saved public witness")
+
+	return nil
+}
+
+// NewProofResult creates the proof result of the wrapper proof of the assignment with the output,
+// along with the digest of the plonky2 circuit, the hash of the verifying key and the version of
+// the prover, so the result can be interpreted after the circuits have been upgraded.
+func NewProofResult(assignment *Plonky2xVerifierCircuit, proof plonk.Proof, vk plonk.VerifyingKey, output []byte) (*types.ProofResult, error) {
+	vkHash := sha256.New()
+	if _, err := vk.WriteRawTo(vkHash); err != nil {
+		return nil, fmt.Errorf("failed to hash verifying key: %w", err)
+	}
+	result := types.NewProofResult(proof.(*plonk_bn254.Proof).MarshalSolidity(), output, uint64(time.Now().Unix()))
+	result.CircuitDigest = common.BigToHash(assignment.VerifierDigest.(*big.Int)).Bytes()
+	result.VerifyingKeyHash = vkHash.Sum(nil)
+	result.ProverVersion = proverVersion()
+	return result, nil
+}
+
+// proverVersion returns the module version of the prover binary, followed by the revision it was
+// built from if it was built from a repository.
+func proverVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			version += "+" + setting.Value
+		}
 	}
-	_, err = publicWitness.WriteTo(witnessFile)
+	return version
+}
+
+// SaveReceipt signs a receipt for the proof with the prover key and saves it to receipt.json,
+// next to proof.json.
+func SaveReceipt(assignment *Plonky2xVerifierCircuit, proof plonk.Proof, signer types.Signer) error {
+	log := logger.Logger()
+
+	receipt := types.NewReceipt(
+		common.BigToHash(assignment.VerifierDigest.(*big.Int)),
+		common.BigToHash(assignment.InputHash.(*big.Int)),
+		common.BigToHash(assignment.OutputHash.(*big.Int)),
+		proof.(*plonk_bn254.Proof).MarshalSolidity(),
+		uint64(time.Now().Unix()),
+	)
+	err := receipt.SignWith(context.Background(), signer)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to write public witness file: %w", err)
+		return err
 	}
-	witnessFile.Close()
-	log.Info().Msg("Successfully saved public witness")
 
-	return proof, publicWitness, nil
+	log.Info().Msg("Saving receipt to receipt.json")
+	err = receipt.Export("receipt.json")
+	if err != nil {
+		return err
+	}
+	log.Info().Msg("Successfully saved receipt signed by " + receipt.Prover.Hex())
+	return nil
 }
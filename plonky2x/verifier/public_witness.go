@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// LabeledPublicInput is a public input of the verifier circuit with its name in the circuit, e.g.
+// inputHash.
+type LabeledPublicInput struct {
+	Name  string       `json:"name"`
+	Value *hexutil.Big `json:"value"`
+}
+
+// LabelPublicWitness labels the values of the public witness of the verifier circuit, or of the
+// fixed verifier circuit, in their order in the witness. Values beyond the public inputs of the
+// circuit are the commitments added by the backend, which are labeled commitment, commitment[1], ...
+func LabelPublicWitness(publicWitness witness.Witness) ([]LabeledPublicInput, error) {
+	vector, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public witness %T", publicWitness.Vector())
+	}
+	names, err := publicInputLabels(len(vector))
+	if err != nil {
+		return nil, err
+	}
+
+	labeled := make([]LabeledPublicInput, len(vector))
+	for i := range vector {
+		labeled[i] = LabeledPublicInput{Name: names[i], Value: (*hexutil.Big)(vector[i].BigInt(new(big.Int)))}
+	}
+	return labeled, nil
+}
+
+// publicInputLabels returns the labels of the n public values of the verifier circuit, or of the
+// fixed verifier circuit, see LabelPublicWitness.
+func publicInputLabels(n int) ([]string, error) {
+	var circuit frontend.Circuit = &Plonky2xVerifierCircuit{}
+	if n == fixedVerifierNbPublicInputs {
+		circuit = &Plonky2xFixedVerifierCircuit{}
+	}
+	names, err := publicInputNames(circuit)
+	if err != nil {
+		return nil, err
+	}
+	if n < len(names) {
+		return nil, fmt.Errorf("public witness has %d values, expected at least %d", n, len(names))
+	}
+	for i := 0; len(names) < n; i++ {
+		if i == 0 {
+			names = append(names, "commitment")
+		} else {
+			names = append(names, fmt.Sprintf("commitment[%d]", i))
+		}
+	}
+	return names, nil
+}
+
+// The type of the leaves of an assignment, i.e. frontend.Variable.
+var variableType = reflect.TypeOf((*frontend.Variable)(nil)).Elem()
+
+// publicInputNames returns the names of the public inputs of the circuit in the order of its
+// public witness.
+func publicInputNames(circuit frontend.Circuit) ([]string, error) {
+	var names []string
+	_, err := schema.Walk(circuit, variableType, func(leaf schema.LeafInfo, value reflect.Value) error {
+		if leaf.Visibility == schema.Public {
+			names = append(names, leaf.FullName())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk circuit: %w", err)
+	}
+	return names, nil
+}
+
+// SavePublicWitnessJSON writes the labeled values of the public witness to the file, so it can be
+// inspected by humans and verifiers which cannot read the binary witnesses of gnark.
+func SavePublicWitnessJSON(file string, publicWitness witness.Witness) error {
+	return savePublicWitnessJSON(file, true, publicWitness)
+}
+
+// savePublicWitnessJSON is SavePublicWitnessJSON, writing the file atomically, without replacing
+// an existing one unless overwrite is set.
+func savePublicWitnessJSON(file string, overwrite bool, publicWitness witness.Witness) error {
+	labeled, err := LabelPublicWitness(publicWitness)
+	if err != nil {
+		return fmt.Errorf("failed to label public witness: %w", err)
+	}
+	data, err := json.MarshalIndent(labeled, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal public witness: %w", err)
+	}
+	return writeFileAtomic(file, overwrite, func(w io.Writer) error {
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write public witness file: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPublicWitness(t *testing.T, values ...int) witness.Witness {
+	w, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	c := make(chan any, len(values))
+	for _, v := range values {
+		c <- v
+	}
+	close(c)
+	assert.NoError(t, w.Fill(len(values), 0, c))
+	return w
+}
+
+func TestLabelPublicWitness(t *testing.T) {
+	labeled, err := LabelPublicWitness(newTestPublicWitness(t, 1, 2, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"verifierDigest", "inputHash", "outputHash"}, labelNames(labeled))
+	assert.Equal(t, "0x2", labeled[1].Value.String())
+
+	// The fixed verifier circuit has no verifierDigest.
+	labeled, err = LabelPublicWitness(newTestPublicWitness(t, 2, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"inputHash", "outputHash"}, labelNames(labeled))
+
+	labeled, err = LabelPublicWitness(newTestPublicWitness(t, 1, 2, 3, 4, 5))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"verifierDigest", "inputHash", "outputHash", "commitment", "commitment[1]"}, labelNames(labeled))
+
+	_, err = LabelPublicWitness(newTestPublicWitness(t, 1))
+	assert.Error(t, err)
+}
+
+func TestSavePublicWitnessJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "public_witness.json")
+	assert.NoError(t, SavePublicWitnessJSON(path, newTestPublicWitness(t, 1, 255, 3)))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var decoded []map[string]string
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]string{"name": "inputHash", "value": "0xff"}, decoded[1])
+}
+
+func labelNames(labeled []LabeledPublicInput) []string {
+	names := make([]string, len(labeled))
+	for i, input := range labeled {
+		names[i] = input.Name
+	}
+	return names
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter enforces per-client quotas on the proving service, so that one client cannot starve
+// the others on a shared prover. Every client gets a token bucket refilled at Rate requests per
+// second up to Burst requests, and at most MaxConcurrent of its requests are in flight at once.
+//
+// Quotas are tracked per client authenticated by the Authenticator, and otherwise per remote
+// address. Credentials the Authenticator has not checked are ignored, as clients could otherwise
+// get a fresh quota for every request by making up API keys.
+type RateLimiter struct {
+	// Rate is the number of requests per second each client is allowed. Zero disables the limit.
+	Rate  float64
+	Burst int
+
+	// MaxConcurrent is the number of in-flight requests each client is allowed, including the ones
+	// waiting for the prover. Zero disables the limit.
+	MaxConcurrent int
+
+	mu        sync.Mutex
+	clients   map[string]*clientQuota
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// The interval the quotas of idle clients are forgotten at.
+const quotaSweepInterval = time.Minute
+
+type clientQuota struct {
+	tokens     float64
+	lastRefill time.Time
+	inFlight   int
+}
+
+func NewRateLimiter(rate float64, burst int, maxConcurrent int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		Rate:          rate,
+		Burst:         burst,
+		MaxConcurrent: maxConcurrent,
+		clients:       make(map[string]*clientQuota),
+		now:           time.Now,
+	}
+}
+
+// Acquire takes a token and a concurrency slot for the client. If the client is over quota, it
+// returns an error and how long the client should wait before retrying. On success the returned
+// release function must be called once the request is done.
+func (l *RateLimiter) Acquire(client string) (release func(), retryAfter time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= quotaSweepInterval {
+		l.sweep(now)
+	}
+	quota, ok := l.clients[client]
+	if !ok {
+		quota = &clientQuota{tokens: float64(l.Burst), lastRefill: now}
+		l.clients[client] = quota
+	}
+
+	if l.Rate > 0 {
+		elapsed := now.Sub(quota.lastRefill).Seconds()
+		quota.tokens = math.Min(float64(l.Burst), quota.tokens+elapsed*l.Rate)
+		quota.lastRefill = now
+		if quota.tokens < 1 {
+			wait := time.Duration((1 - quota.tokens) / l.Rate * float64(time.Second))
+			return nil, wait, fmt.Errorf("rate limit of %g requests per second exceeded", l.Rate)
+		}
+	}
+	if l.MaxConcurrent > 0 && quota.inFlight >= l.MaxConcurrent {
+		return nil, time.Second, fmt.Errorf("concurrency limit of %d requests exceeded", l.MaxConcurrent)
+	}
+
+	if l.Rate > 0 {
+		quota.tokens--
+	}
+	quota.inFlight++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		quota.inFlight--
+	}, 0, nil
+}
+
+// sweep forgets the quotas of the clients which have no request in flight and whose bucket is
+// full again, as they are the same as the quota of a new client. Without it, every address which
+// ever sent a request would be remembered.
+func (l *RateLimiter) sweep(now time.Time) {
+	for client, quota := range l.clients {
+		if quota.inFlight > 0 {
+			continue
+		}
+		if l.Rate > 0 && quota.tokens+now.Sub(quota.lastRefill).Seconds()*l.Rate < float64(l.Burst) {
+			continue
+		}
+		delete(l.clients, client)
+	}
+	l.lastSweep = now
+}
+
+// Middleware rejects requests from clients over their quota with 429 Too Many Requests. A nil
+// limiter lets every request through.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, retryAfter, err := l.Acquire(clientID(r))
+		if err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeJSON(w, http.StatusTooManyRequests, statusResponse{Status: "error", Error: err.Error()})
+			return
+		}
+		defer release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func clientID(r *http.Request) string {
+	if client, ok := r.Context().Value(clientIdentityKey{}).(string); ok {
+		return client
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(1, 2, 1)
+	limiter.now = func() time.Time { return now }
+
+	// The first request takes the only concurrency slot.
+	release, _, err := limiter.Acquire("a")
+	assert.NoError(t, err)
+	_, _, err = limiter.Acquire("a")
+	assert.Error(t, err)
+
+	// Other clients are not affected.
+	releaseB, _, err := limiter.Acquire("b")
+	assert.NoError(t, err)
+	releaseB()
+
+	// Once the slot is released, the burst allows one more request, then the bucket is empty.
+	release()
+	release, _, err = limiter.Acquire("a")
+	assert.NoError(t, err)
+	release()
+	_, retryAfter, err := limiter.Acquire("a")
+	assert.Error(t, err)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// The bucket refills over time.
+	now = now.Add(time.Second)
+	release, _, err = limiter.Acquire("a")
+	assert.NoError(t, err)
+	release()
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, 0)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(client string, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/prove", nil)
+		if client != "" {
+			req = req.WithContext(context.WithValue(req.Context(), clientIdentityKey{}, client))
+		}
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, send("key:alice", "").Code)
+	rec := send("key:alice", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// Quotas are tracked per authenticated client, and per address for anonymous clients, whose
+	// unchecked API keys do not give them another quota.
+	assert.Equal(t, http.StatusOK, send("key:bob", "").Code)
+	assert.Equal(t, http.StatusOK, send("", "key1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("", "key2").Code)
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(0.01, 2, 0)
+	limiter.now = func() time.Time { return now }
+
+	release, _, err := limiter.Acquire("a")
+	assert.NoError(t, err)
+	release()
+	release, _, err = limiter.Acquire("b")
+	assert.NoError(t, err)
+
+	// Clients with requests in flight or buckets which are not full yet are remembered.
+	now = now.Add(quotaSweepInterval)
+	_, _, err = limiter.Acquire("c")
+	assert.NoError(t, err)
+	assert.Len(t, limiter.clients, 3)
+
+	// Idle clients are forgotten once their bucket is full again.
+	release()
+	now = now.Add(quotaSweepInterval)
+	_, _, err = limiter.Acquire("d")
+	assert.NoError(t, err)
+	assert.Len(t, limiter.clients, 2)
+	assert.Contains(t, limiter.clients, "c")
+	assert.Contains(t, limiter.clients, "d")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/succinctlabs/succinctx/bindings"
+)
+
+// FunctionRegistration is the registration of a function verifier on the gateway, as predicted
+// before it is deployed. deployAndRegisterFunction deploys the verifier with CREATE2, salted with
+// the function ID, so its address follows from the gateway, the owner, the salt and the bytecode.
+type FunctionRegistration struct {
+	FunctionID       common.Hash    `json:"function_id"`
+	Owner            common.Address `json:"owner"`
+	Salt             common.Hash    `json:"salt"`
+	BytecodeHash     common.Hash    `json:"bytecode_hash,omitempty"`
+	VerifierAddress  common.Address `json:"verifier_address,omitempty"`
+	VerifyingKeyHash common.Hash    `json:"vk_hash,omitempty"`
+}
+
+// FunctionID computes the ID of the function registered by the owner with the salt, like
+// getFunctionId of the gateway, i.e. keccak256(abi.encode(owner, salt)).
+func FunctionID(owner common.Address, salt common.Hash) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), salt.Bytes())
+}
+
+// PredictRegistration predicts the registration of the function verifier with the creation
+// bytecode, deployed through the gateway by the owner with the salt. Without bytecode, only the
+// function ID is predicted. If vk is not nil, the hash of its registration bytes is recorded too,
+// see VerifyingKeyRegistration.
+func PredictRegistration(gateway common.Address, owner common.Address, salt common.Hash, bytecode []byte, vk plonk.VerifyingKey) (*FunctionRegistration, error) {
+	registration := &FunctionRegistration{
+		FunctionID: FunctionID(owner, salt),
+		Owner:      owner,
+		Salt:       salt,
+	}
+	if len(bytecode) > 0 {
+		registration.BytecodeHash = crypto.Keccak256Hash(bytecode)
+		registration.VerifierAddress = crypto.CreateAddress2(gateway, registration.FunctionID, registration.BytecodeHash.Bytes())
+	}
+	if vk != nil {
+		_vk, ok := vk.(*plonk_bn254.VerifyingKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported verifying key %T", vk)
+		}
+		registration.VerifyingKeyHash = crypto.Keccak256Hash(VerifyingKeyRegistration(_vk))
+	}
+	return registration, nil
+}
+
+// CheckRegistration checks the predicted registration against the gateway, i.e. that the function
+// is registered to the owner, with the predicted verifier if the bytecode was known.
+func CheckRegistration(ctx context.Context, backend bind.ContractCaller, gateway common.Address, registration *FunctionRegistration) error {
+	caller, err := bindings.NewSuccinctGatewayCaller(gateway, backend)
+	if err != nil {
+		return fmt.Errorf("failed to bind gateway: %w", err)
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	functionID, err := caller.GetFunctionId(opts, registration.Owner, registration.Salt)
+	if err != nil {
+		return fmt.Errorf("failed to get function id: %w", err)
+	}
+	if functionID != registration.FunctionID {
+		return fmt.Errorf("gateway computes function id %s, predicted %s", common.Hash(functionID), registration.FunctionID)
+	}
+	owner, err := caller.VerifierOwners(opts, functionID)
+	if err != nil {
+		return fmt.Errorf("failed to get verifier owner: %w", err)
+	}
+	if owner != registration.Owner {
+		return fmt.Errorf("function %s is owned by %s, expected %s", registration.FunctionID, owner, registration.Owner)
+	}
+	if registration.VerifierAddress != (common.Address{}) {
+		verifier, err := caller.Verifiers(opts, functionID)
+		if err != nil {
+			return fmt.Errorf("failed to get verifier: %w", err)
+		}
+		if verifier != registration.VerifierAddress {
+			return fmt.Errorf("function %s has verifier %s, predicted %s", registration.FunctionID, verifier, registration.VerifierAddress)
+		}
+	}
+	return nil
+}
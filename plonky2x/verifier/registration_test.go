@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/bindings"
+)
+
+// mockGatewayCaller answers the registry calls of the gateway for registered functions.
+type mockGatewayCaller struct {
+	owners    map[common.Hash]common.Address
+	verifiers map[common.Hash]common.Address
+}
+
+func (c *mockGatewayCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *mockGatewayCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	gatewayAbi, err := bindings.SuccinctGatewayMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := gatewayAbi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "getFunctionId":
+		salt := args[1].([32]byte)
+		return method.Outputs.Pack(crypto.Keccak256Hash(common.LeftPadBytes(args[0].(common.Address).Bytes(), 32), salt[:]))
+	case "verifierOwners":
+		return method.Outputs.Pack(c.owners[args[0].([32]byte)])
+	default:
+		return method.Outputs.Pack(c.verifiers[args[0].([32]byte)])
+	}
+}
+
+func TestFunctionRegistration(t *testing.T) {
+	ctx := context.Background()
+	gateway := common.HexToAddress("0x6c7a05e0AE641c6559fD76ac56641778B6eCd776")
+	owner := common.HexToAddress("0xDEd0000E32f8F40414d3ab3a830f735a3553E18e")
+	salt := common.HexToHash("0x01")
+	bytecode := []byte{0x60, 0x80, 0x60, 0x40}
+
+	vk, err := LoadVerifierKey(saveTestCircuit(t, CompressionNone), false)
+	assert.NoError(t, err)
+	registration, err := PredictRegistration(gateway, owner, salt, bytecode, vk)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(bytecode), registration.BytecodeHash)
+	assert.NotEqual(t, common.Hash{}, registration.VerifyingKeyHash)
+
+	caller := &mockGatewayCaller{
+		owners:    map[common.Hash]common.Address{registration.FunctionID: owner},
+		verifiers: map[common.Hash]common.Address{registration.FunctionID: registration.VerifierAddress},
+	}
+	assert.NoError(t, CheckRegistration(ctx, caller, gateway, registration))
+
+	// The verifier deployed with other bytecode is detected.
+	other, err := PredictRegistration(gateway, owner, salt, []byte{0x60}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, registration.FunctionID, other.FunctionID)
+	assert.ErrorContains(t, CheckRegistration(ctx, caller, gateway, other), "has verifier")
+
+	// So is a function registered by someone else.
+	unregistered, err := PredictRegistration(gateway, owner, common.HexToHash("0x02"), nil, nil)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, CheckRegistration(ctx, caller, gateway, unregistered), "is owned by")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/logger"
+)
+
+// The lifecycle states of a registered circuit, as reported by the readiness endpoint.
+const (
+	StateLoading  = "loading"
+	StateSelfTest = "self-test"
+	StateReady    = "ready"
+	StateFailed   = "failed"
+)
+
+// RegisteredCircuit is a wrapper circuit hosted by the prover under a function ID (or circuit
+// digest), together with its loaded artifacts.
+type RegisteredCircuit struct {
+	ID               string
+	DataPath         string
+	DummyCircuitPath string
+	// Whether the keys are deserialized without subgroup checks, see LoadProverData.
+	UnsafeDeserialize bool
+
+	mu    sync.RWMutex
+	state string
+	err   error
+
+	r1cs   constraint.ConstraintSystem
+	pk     plonk.ProvingKey
+	vk     plonk.VerifyingKey
+	config CircuitConfig
+}
+
+// Load loads the r1cs, proving key and verifying key of the circuit and runs a self-test proof
+// over the dummy circuit data. The circuit only becomes ready once the self-test has passed.
+func (c *RegisteredCircuit) Load() error {
+	log := logger.Logger()
+
+	log.Info().Msg("Loading circuit " + c.ID + " from " + c.DataPath)
+	r1cs, pk, err := LoadProverData(c.DataPath, c.UnsafeDeserialize)
+	if err != nil {
+		return c.fail(fmt.Errorf("failed to load the verifier circuit: %w", err))
+	}
+	vk, err := LoadVerifierKey(c.DataPath, c.UnsafeDeserialize)
+	if err != nil {
+		return c.fail(fmt.Errorf("failed to load the verifier key: %w", err))
+	}
+	config, err := ReadCircuitConfig(c.DataPath)
+	if err != nil {
+		return c.fail(fmt.Errorf("failed to load the circuit config: %w", err))
+	}
+
+	c.mu.Lock()
+	c.r1cs, c.pk, c.vk, c.config = r1cs, pk, vk, config
+	c.state = StateSelfTest
+	c.mu.Unlock()
+
+	log.Info().Msg("Running self-test proof for circuit " + c.ID + " with circuitPath " + c.DummyCircuitPath)
+	start := time.Now()
+	assignment, err := TryLoadAssignment(c.DummyCircuitPath)
+	if err != nil {
+		return c.fail(fmt.Errorf("self-test failed: %w", err))
+	}
+	if err := config.CheckAssignment(assignment); err != nil {
+		return c.fail(fmt.Errorf("self-test failed: %w", err))
+	}
+	proof, publicWitness, err := GenerateProof(assignment, config, r1cs, pk)
+	if err != nil {
+		return c.fail(fmt.Errorf("self-test failed to create proof: %w", err))
+	}
+	err = VerifyProof(proof, vk, publicWitness)
+	if err != nil {
+		return c.fail(fmt.Errorf("self-test failed to verify proof: %w", err))
+	}
+	log.Info().Msg("Successfully ran self-test for circuit " + c.ID + ", time: " + time.Since(start).String())
+
+	c.mu.Lock()
+	c.state = StateReady
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *RegisteredCircuit) fail(err error) error {
+	c.mu.Lock()
+	c.state = StateFailed
+	c.err = err
+	c.mu.Unlock()
+	return err
+}
+
+// Status returns the current lifecycle state and, if loading failed, the reason.
+func (c *RegisteredCircuit) Status() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state, c.err
+}
+
+// Artifacts returns the loaded artifacts of the circuit, or an error if it is not ready.
+func (c *RegisteredCircuit) Artifacts() (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.state != StateReady {
+		return nil, nil, nil, fmt.Errorf("circuit %s is not ready: %s", c.ID, c.state)
+	}
+	return c.r1cs, c.pk, c.vk, nil
+}
+
+// Config returns the configuration the circuit was compiled with, which is only known once the
+// circuit is loaded.
+func (c *RegisteredCircuit) Config() CircuitConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
+// CircuitRegistry holds all the wrapper circuits hosted by a single prover process, keyed by
+// function ID. Hosting several circuits in one process avoids paying for a separate process (and
+// runtime) per plonky2 function.
+type CircuitRegistry struct {
+	// Whether the circuits registered from now on deserialize their keys without subgroup checks.
+	UnsafeDeserialize bool
+
+	mu       sync.RWMutex
+	circuits map[string]*RegisteredCircuit
+}
+
+func NewCircuitRegistry() *CircuitRegistry {
+	return &CircuitRegistry{circuits: make(map[string]*RegisteredCircuit)}
+}
+
+// Function IDs are matched case-insensitively so that checksummed and lowercase hex agree.
+func normalizeFunctionID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// Register adds a circuit under the given function ID. The artifacts are not loaded until Load or
+// LoadAll is called.
+func (r *CircuitRegistry) Register(id string, dataPath string, dummyCircuitPath string) (*RegisteredCircuit, error) {
+	key := normalizeFunctionID(id)
+	if key == "" {
+		return nil, fmt.Errorf("function id must not be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.circuits[key]; ok {
+		return nil, fmt.Errorf("function id %s is already registered", id)
+	}
+	circuit := &RegisteredCircuit{
+		ID:                key,
+		DataPath:          dataPath,
+		DummyCircuitPath:  dummyCircuitPath,
+		UnsafeDeserialize: r.UnsafeDeserialize,
+		state:             StateLoading,
+	}
+	r.circuits[key] = circuit
+	return circuit, nil
+}
+
+// Get returns the circuit registered under the function ID. An empty ID is accepted when exactly
+// one circuit is registered, which keeps single-circuit deployments working unchanged.
+func (r *CircuitRegistry) Get(id string) (*RegisteredCircuit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := normalizeFunctionID(id)
+	if key == "" {
+		if len(r.circuits) != 1 {
+			return nil, fmt.Errorf("function id is required when %d circuits are registered", len(r.circuits))
+		}
+		for _, circuit := range r.circuits {
+			return circuit, nil
+		}
+	}
+	circuit, ok := r.circuits[key]
+	if !ok {
+		return nil, fmt.Errorf("no circuit registered for function id %s", id)
+	}
+	return circuit, nil
+}
+
+// Circuits returns all registered circuits ordered by function ID.
+func (r *CircuitRegistry) Circuits() []*RegisteredCircuit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	circuits := make([]*RegisteredCircuit, 0, len(r.circuits))
+	for _, circuit := range r.circuits {
+		circuits = append(circuits, circuit)
+	}
+	sort.Slice(circuits, func(i, j int) bool { return circuits[i].ID < circuits[j].ID })
+	return circuits
+}
+
+// LoadAll loads every registered circuit. Circuits are loaded one after the other to bound the
+// peak memory used while deserializing proving keys.
+func (r *CircuitRegistry) LoadAll() error {
+	var errs []string
+	for _, circuit := range r.Circuits() {
+		if err := circuit.Load(); err != nil {
+			errs = append(errs, circuit.ID+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load circuits: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Ready reports whether every registered circuit is ready to serve requests.
+func (r *CircuitRegistry) Ready() bool {
+	circuits := r.Circuits()
+	if len(circuits) == 0 {
+		return false
+	}
+	for _, circuit := range circuits {
+		if state, _ := circuit.Status(); state != StateReady {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFunctionSpecs parses a comma separated list of "functionId=dataPath[=dummyCircuitPath]"
+// entries and registers each circuit. Entries without a dummy path use defaultDummyPath.
+func (r *CircuitRegistry) ParseFunctionSpecs(spec string, defaultDummyPath string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "=")
+		if len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("invalid function spec %q, expected functionId=dataPath[=dummyCircuitPath]", entry)
+		}
+		dummyPath := defaultDummyPath
+		if len(parts) == 3 {
+			dummyPath = parts[2]
+		}
+		if _, err := r.Register(parts[0], parts[1], dummyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFunctionSpecs(t *testing.T) {
+	for _, tc := range []struct {
+		spec    string
+		paths   map[string][2]string
+		message string
+	}{
+		{"", map[string][2]string{}, ""},
+		{"0xAB=data/a", map[string][2]string{"0xab": {"data/a", "data/dummy"}}, ""},
+		{" 0xab=data/a=dummy/a , ,0xcd=data/c", map[string][2]string{"0xab": {"data/a", "dummy/a"}, "0xcd": {"data/c", "data/dummy"}}, ""},
+		{"0xab", nil, "invalid function spec \"0xab\""},
+		{"0xab=data/a=dummy/a=other", nil, "invalid function spec"},
+		{"=data/a", nil, "function id must not be empty"},
+		{"0xab=data/a,0xAB=data/b", nil, "function id 0xAB is already registered"},
+	} {
+		registry := NewCircuitRegistry()
+		err := registry.ParseFunctionSpecs(tc.spec, "data/dummy")
+		if tc.message != "" {
+			assert.ErrorContains(t, err, tc.message, tc.spec)
+			continue
+		}
+		assert.NoError(t, err, tc.spec)
+		paths := make(map[string][2]string)
+		for _, circuit := range registry.Circuits() {
+			paths[circuit.ID] = [2]string{circuit.DataPath, circuit.DummyCircuitPath}
+		}
+		assert.Equal(t, tc.paths, paths, tc.spec)
+	}
+}
+
+func TestCircuitRegistryGet(t *testing.T) {
+	single := NewCircuitRegistry()
+	_, err := single.Register("0xAB", "data/a", "data/dummy")
+	assert.NoError(t, err)
+	multiple := NewCircuitRegistry()
+	assert.NoError(t, multiple.ParseFunctionSpecs("0xab=data/a,0xcd=data/c", "data/dummy"))
+
+	for _, tc := range []struct {
+		registry *CircuitRegistry
+		id       string
+		expected string
+		message  string
+	}{
+		// Function IDs are matched case-insensitively.
+		{single, "0xab", "0xab", ""},
+		{single, " 0xAB ", "0xab", ""},
+		// An empty ID selects the only circuit, and is ambiguous otherwise.
+		{single, "", "0xab", ""},
+		{multiple, "", "", "function id is required when 2 circuits are registered"},
+		{NewCircuitRegistry(), "", "", "function id is required when 0 circuits are registered"},
+		{multiple, "0xCD", "0xcd", ""},
+		{multiple, "0xef", "", "no circuit registered for function id 0xef"},
+	} {
+		circuit, err := tc.registry.Get(tc.id)
+		if tc.message != "" {
+			assert.ErrorContains(t, err, tc.message, tc.id)
+			continue
+		}
+		assert.NoError(t, err, tc.id)
+		assert.Equal(t, tc.expected, circuit.ID)
+	}
+}
+
+func TestCircuitRegistryReady(t *testing.T) {
+	// A registry without circuits has nothing to serve.
+	registry := NewCircuitRegistry()
+	assert.False(t, registry.Ready())
+
+	a, err := registry.Register("0xab", "data/a", "data/dummy")
+	assert.NoError(t, err)
+	b, err := registry.Register("0xcd", "data/c", "data/dummy")
+	assert.NoError(t, err)
+	assert.False(t, registry.Ready())
+	state, _ := a.Status()
+	assert.Equal(t, StateLoading, state)
+	_, _, _, err = a.Artifacts()
+	assert.ErrorContains(t, err, "circuit 0xab is not ready: loading")
+
+	a.state, b.state = StateReady, StateReady
+	assert.True(t, registry.Ready())
+
+	// A circuit which fails to load makes the registry unready, and reports why.
+	b.DataPath = t.TempDir()
+	assert.ErrorContains(t, registry.LoadAll(), "0xcd: failed to load the verifier circuit")
+	state, err = b.Status()
+	assert.Equal(t, StateFailed, state)
+	assert.ErrorContains(t, err, "failed to load the verifier circuit")
+	assert.False(t, registry.Ready())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/consensys/gnark/logger"
+)
+
+// WitnessError is the error of a proof whose witness cannot be generated or does not satisfy the
+// verifier circuit, because the plonky2 proof or its public inputs are invalid. It is permanent, so
+// the proof is not retried.
+type WitnessError struct {
+	Err error
+}
+
+func (e *WitnessError) Error() string {
+	return "invalid witness: " + e.Err.Error()
+}
+
+func (e *WitnessError) Unwrap() error {
+	return e.Err
+}
+
+// ProverError is the error of a proof which failed after its witness was generated, e.g. because the
+// machine ran out of resources. It is transient, so the proof is retried.
+type ProverError struct {
+	Err error
+}
+
+func (e *ProverError) Error() string {
+	return "prover failed: " + e.Err.Error()
+}
+
+func (e *ProverError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether the proof which failed with the error may succeed when retried.
+func IsRetryable(err error) bool {
+	var proverErr *ProverError
+	return errors.As(err, &proverErr)
+}
+
+// RetryPolicy retries transient proving failures, see IsRetryable.
+type RetryPolicy struct {
+	// The number of attempts, where 0 is a single attempt.
+	MaxAttempts int
+	// The delay before the first retry, which doubles with every retry.
+	Backoff time.Duration
+}
+
+// Do runs fn until it succeeds, fails with an error which is not retryable, the attempts are
+// exhausted or the context is cancelled.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	log := logger.Logger()
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsRetryable(err) || attempt >= p.MaxAttempts {
+			return err
+		}
+		log.Err(err).Msg(fmt.Sprintf("attempt %d/%d failed, retrying in %s", attempt, p.MaxAttempts, backoff))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
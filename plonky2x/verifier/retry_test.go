@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+	attempts := func(err error) int {
+		n := 0
+		assert.Equal(t, err, policy.Do(context.Background(), func() error {
+			n++
+			return err
+		}))
+		return n
+	}
+	assert.Equal(t, 1, attempts(nil))
+	assert.Equal(t, 3, attempts(&ProverError{Err: errors.New("out of memory")}))
+	assert.Equal(t, 1, attempts(&WitnessError{Err: errors.New("bad proof")}))
+	assert.Equal(t, 1, attempts(errors.New("unknown")))
+
+	// A zero policy makes a single attempt.
+	n := 0
+	RetryPolicy{}.Do(context.Background(), func() error {
+		n++
+		return &ProverError{Err: errors.New("out of memory")}
+	})
+	assert.Equal(t, 1, n)
+}
+
+func TestClassifyProverError(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: 10})
+	assert.NoError(t, err)
+	pk, _, err := plonk.Setup(ccs, deterministicSRS(t, ccs))
+	assert.NoError(t, err)
+
+	// An assignment which does not satisfy the constraints is never retried.
+	w, err := frontend.NewWitness(&fingerprintCircuit{X: 1, Y: 3, N: 10}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	_, err = plonk.Prove(ccs, pk, w)
+	err = classifyProverError(err)
+	var witnessErr *WitnessError
+	assert.ErrorAs(t, err, &witnessErr)
+	assert.ErrorIs(t, err, types.ErrWitnessUnsatisfied)
+	assert.False(t, IsRetryable(err))
+
+	assert.True(t, IsRetryable(classifyProverError(errors.New("out of memory"))))
+}
+
+func TestTryLoadAssignment(t *testing.T) {
+	_, err := TryLoadAssignment(t.TempDir())
+	var witnessErr *WitnessError
+	assert.ErrorAs(t, err, &witnessErr)
+
+	// Prove fails with the WitnessError too, rather than panicking.
+	_, _, err = Prove(context.Background(), RetryPolicy{}, t.TempDir(), CircuitConfig{}, nil, nil, nil, false)
+	assert.ErrorAs(t, err, &witnessErr)
+	assert.False(t, IsRetryable(err))
+}
+
+func TestWriteProveError(t *testing.T) {
+	status := func(err error) (int, string) {
+		rec := httptest.NewRecorder()
+		writeProveError(rec, err)
+		return rec.Code, rec.Header().Get("Retry-After")
+	}
+	code, retryAfter := status(&WitnessError{Err: errors.New("bad proof")})
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Empty(t, retryAfter)
+	code, retryAfter = status(&ProverError{Err: errors.New("out of memory")})
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, proveRetryAfter, retryAfter)
+	code, _ = status(errors.New("unknown"))
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// ProverServer serves proving requests over HTTP for every circuit in its registry. The server
+// starts answering health checks immediately, but only reports itself as ready once all the
+// circuit artifacts are fully loaded and their self-test proofs have been generated and verified.
+type ProverServer struct {
+	registry *CircuitRegistry
+
+	// ProofsDir is the directory the plonky2 proofs of proving requests are read from, each in the
+	// folder named by the requestId of its request. Clients cannot name other paths on the server.
+	ProofsDir string
+
+	// Auth authenticates proving requests. If nil, requests are not authenticated.
+	Auth *Authenticator
+
+	// Limiter enforces the per-client quotas on proving requests. If nil, requests are not limited.
+	Limiter *RateLimiter
+
+	// TLSConfig enables TLS, and mutual TLS if it requires client certificates.
+	TLSConfig *tls.Config
+
+	// Retry retries proofs which fail for transient reasons before the request is failed.
+	Retry RetryPolicy
+
+	// Proving is memory bound, so requests are served one at a time.
+	proveMu sync.Mutex
+}
+
+type proveRequest struct {
+	// The function ID the request is routed by. It may be omitted if the server only hosts a
+	// single circuit.
+	FunctionID string `json:"functionId"`
+
+	// The folder of the plonky2 proof in the ProofsDir of the server.
+	RequestID string `json:"requestId"`
+
+	// The public inputs the client expects the proof to have. Each one which is given is checked
+	// against the plonky2 proof before proving.
+	InputHash      *hexutil.Big `json:"inputHash,omitempty"`
+	OutputHash     *hexutil.Big `json:"outputHash,omitempty"`
+	VerifierDigest *hexutil.Big `json:"verifierDigest,omitempty"`
+}
+
+type proveResponse struct {
+	FunctionID     string        `json:"function_id"`
+	InputHash      hexutil.Bytes `json:"input_hash"`
+	OutputHash     hexutil.Bytes `json:"output_hash"`
+	VerifierDigest hexutil.Bytes `json:"verifier_digest"`
+	Proof          hexutil.Bytes `json:"proof"`
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type readyResponse struct {
+	Status   string                    `json:"status"`
+	Circuits map[string]statusResponse `json:"circuits"`
+}
+
+func NewProverServer(registry *CircuitRegistry) *ProverServer {
+	return &ProverServer{registry: registry}
+}
+
+func (s *ProverServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	// The health probes are left unauthenticated for the orchestrator.
+	mux.Handle("/prove", s.Auth.Middleware(s.Limiter.Middleware(http.HandlerFunc(s.handleProve))))
+	return mux
+}
+
+// ListenAndServe starts the HTTP server and loads the artifacts in the background.
+func (s *ProverServer) ListenAndServe(addr string) error {
+	log := logger.Logger()
+	go func() {
+		if err := s.registry.LoadAll(); err != nil {
+			log.Err(err).Msg("prover server is not ready")
+		}
+	}()
+	server := &http.Server{Addr: addr, Handler: s.Handler(), TLSConfig: s.TLSConfig}
+	if s.TLSConfig != nil {
+		log.Info().Msg("Listening with TLS on " + addr)
+		return server.ListenAndServeTLS("", "")
+	}
+	log.Info().Msg("Listening on " + addr)
+	return server.ListenAndServe()
+}
+
+// The liveness probe only reports that the process is up and serving HTTP.
+func (s *ProverServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, statusResponse{Status: "ok"})
+}
+
+// The readiness probe reports 200 only once every circuit is loaded and its self-test passed. If a
+// circuit failed to load, it reports the failure, which is not recovered from without a restart.
+func (s *ProverServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	resp := readyResponse{Status: StateReady, Circuits: make(map[string]statusResponse)}
+	for _, circuit := range s.registry.Circuits() {
+		state, err := circuit.Status()
+		circuitStatus := statusResponse{Status: state}
+		if err != nil {
+			circuitStatus.Error = err.Error()
+		}
+		resp.Circuits[circuit.ID] = circuitStatus
+	}
+	if !s.registry.Ready() {
+		resp.Status = StateLoading
+		for _, circuitStatus := range resp.Circuits {
+			if circuitStatus.Status == StateFailed {
+				resp.Status = StateFailed
+			}
+		}
+		writeJSON(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *ProverServer) handleProve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, statusResponse{Status: "error", Error: "method not allowed"})
+		return
+	}
+
+	var req proveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, statusResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	circuitPath, err := s.requestPath(req.RequestID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, statusResponse{Status: "error", Error: err.Error()})
+		return
+	}
+
+	circuit, err := s.registry.Get(req.FunctionID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, statusResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	r1cs, pk, _, err := circuit.Artifacts()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, statusResponse{Status: "error", Error: err.Error()})
+		return
+	}
+
+	// The request is checked before it waits for the prover, so that bad requests fail fast
+	// instead of after minutes of proving.
+	config := circuit.Config()
+	assignment, err := TryLoadAssignment(circuitPath)
+	if err != nil {
+		writeProveError(w, err)
+		return
+	}
+	if err := checkPublicInputs(req, assignment, config.FixedDigest.ToInt()); err != nil {
+		writeJSON(w, http.StatusBadRequest, statusResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	if err := config.CheckAssignment(assignment); err != nil {
+		writeProveError(w, err)
+		return
+	}
+
+	s.proveMu.Lock()
+	defer s.proveMu.Unlock()
+
+	proof, _, err := GenerateProofWithRetry(r.Context(), s.Retry, assignment, config, r1cs, pk)
+	if err != nil {
+		writeProveError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, proveResponse{
+		FunctionID:     circuit.ID,
+		InputHash:      assignment.InputHash.(*big.Int).Bytes(),
+		OutputHash:     assignment.OutputHash.(*big.Int).Bytes(),
+		VerifierDigest: assignment.VerifierDigest.(*big.Int).Bytes(),
+		Proof:          proof.(*plonk_bn254.Proof).MarshalSolidity(),
+	})
+}
+
+// requestPath returns the directory of the plonky2 proof of the request in the ProofsDir. The
+// request ID must name a folder directly in it, so requests cannot read other paths.
+func (s *ProverServer) requestPath(requestID string) (string, error) {
+	if requestID == "" {
+		return "", errors.New("requestId is required")
+	}
+	if s.ProofsDir == "" {
+		return "", errors.New("the server does not accept plonky2 proofs")
+	}
+	if requestID == "." || filepath.Base(requestID) != requestID || !filepath.IsLocal(requestID) {
+		return "", fmt.Errorf("invalid requestId %q", requestID)
+	}
+	return filepath.Join(s.ProofsDir, requestID), nil
+}
+
+// checkPublicInputs checks that the public inputs of the assignment are elements of the BN254
+// scalar field, since larger values would be silently reduced in the witness, that they match the
+// ones claimed by the request, and that the circuit digest of the verifier data matches fixedDigest
+// if it is not nil.
+func checkPublicInputs(req proveRequest, assignment *Plonky2xVerifierCircuit, fixedDigest *big.Int) error {
+	publicInputs := []struct {
+		name    string
+		value   frontend.Variable
+		claimed *hexutil.Big
+	}{
+		{"inputHash", assignment.InputHash, req.InputHash},
+		{"outputHash", assignment.OutputHash, req.OutputHash},
+		{"verifierDigest", assignment.VerifierDigest, req.VerifierDigest},
+	}
+	modulus := ecc.BN254.ScalarField()
+	for _, input := range publicInputs {
+		if input.claimed != nil && (input.claimed.ToInt().Sign() < 0 || input.claimed.ToInt().Cmp(modulus) >= 0) {
+			return fmt.Errorf("%s %s is not an element of the BN254 scalar field", input.name, input.claimed)
+		}
+		value, ok := input.value.(*big.Int)
+		if !ok || value == nil {
+			return fmt.Errorf("the plonky2 proof has no valid %s", input.name)
+		}
+		if value.Sign() < 0 || value.Cmp(modulus) >= 0 {
+			return fmt.Errorf("%s %#x of the plonky2 proof is not an element of the BN254 scalar field", input.name, value)
+		}
+		if input.claimed != nil && input.claimed.ToInt().Cmp(value) != 0 {
+			return fmt.Errorf("%w: claimed %s %s, but the plonky2 proof has %#x", types.ErrDigestMismatch, input.name, input.claimed, value)
+		}
+	}
+	if fixedDigest != nil && fixedDigest.Cmp(assignment.VerifierDigest.(*big.Int)) != 0 {
+		return fmt.Errorf("%w: the circuit only proves plonky2 proofs with verifierDigest %#x, got %#x", types.ErrDigestMismatch, fixedDigest, assignment.VerifierDigest)
+	}
+	return nil
+}
+
+// The delay clients are asked to wait before retrying a proof which failed for transient reasons.
+const proveRetryAfter = "60"
+
+// writeProveError responds to a failed proof, with 422 if it can never be proven so the client
+// gives up, and with 503 if it may succeed when retried later.
+func writeProveError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var witnessErr *WitnessError
+	if errors.As(err, &witnessErr) {
+		status = http.StatusUnprocessableEntity
+	} else if IsRetryable(err) {
+		w.Header().Set("Retry-After", proveRetryAfter)
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, statusResponse{Status: "error", Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
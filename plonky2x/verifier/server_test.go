@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+func TestProverServerReadiness(t *testing.T) {
+	registry := NewCircuitRegistry()
+	_, err := registry.Register("default", t.TempDir(), "./data/dummy")
+	assert.NoError(t, err)
+	server := NewProverServer(registry)
+	handler := server.Handler()
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+	readyStatus := func() string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp readyResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Status
+	}
+
+	// The server is alive but not ready while the artifacts are loading.
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+	assert.Equal(t, StateLoading, readyStatus())
+
+	// Loading from an empty data directory fails and readiness never flips.
+	assert.Error(t, registry.LoadAll())
+	circuit, err := registry.Get("")
+	assert.NoError(t, err)
+	state, err := circuit.Status()
+	assert.Equal(t, StateFailed, state)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+	assert.Equal(t, StateFailed, readyStatus())
+}
+
+func TestCircuitRegistryRouting(t *testing.T) {
+	registry := NewCircuitRegistry()
+	assert.NoError(t, registry.ParseFunctionSpecs("0xAB=./a, 0xcd=./b=./b/dummy", "./data/dummy"))
+
+	// Function IDs are matched case-insensitively.
+	circuit, err := registry.Get("0xab")
+	assert.NoError(t, err)
+	assert.Equal(t, "./a", circuit.DataPath)
+	assert.Equal(t, "./data/dummy", circuit.DummyCircuitPath)
+
+	circuit, err = registry.Get("0xCD")
+	assert.NoError(t, err)
+	assert.Equal(t, "./b/dummy", circuit.DummyCircuitPath)
+
+	// With several circuits registered, requests must name the function.
+	_, err = registry.Get("")
+	assert.Error(t, err)
+	_, err = registry.Get("0xef")
+	assert.Error(t, err)
+
+	_, err = registry.Register("0xAb", "./c", "./data/dummy")
+	assert.Error(t, err)
+
+	// Requests for unknown functions are rejected before proving.
+	rec := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"functionId": "0xef", "requestId": "test_circuit"}`)
+	server := NewProverServer(registry)
+	server.ProofsDir = "./data"
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/prove", body))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestProverServerRequestPath(t *testing.T) {
+	server := NewProverServer(NewCircuitRegistry())
+	_, err := server.requestPath("test_circuit")
+	assert.ErrorContains(t, err, "does not accept plonky2 proofs")
+
+	server.ProofsDir = "./data"
+	path, err := server.requestPath("test_circuit")
+	assert.NoError(t, err)
+	assert.Equal(t, "data/test_circuit", path)
+
+	// Requests cannot name paths outside of the proofs directory.
+	for _, requestID := range []string{"", ".", "..", "../data", "a/b", "/etc"} {
+		_, err := server.requestPath(requestID)
+		assert.Error(t, err, requestID)
+	}
+}
+
+func TestCheckPublicInputs(t *testing.T) {
+	assignment := &Plonky2xVerifierCircuit{InputHash: big.NewInt(1), OutputHash: big.NewInt(2), VerifierDigest: big.NewInt(3)}
+	modulus := ecc.BN254.ScalarField()
+
+	// The claims are optional, and must match the plonky2 proof if given.
+	assert.NoError(t, checkPublicInputs(proveRequest{}, assignment, nil))
+	claims := proveRequest{InputHash: (*hexutil.Big)(big.NewInt(1)), OutputHash: (*hexutil.Big)(big.NewInt(2)), VerifierDigest: (*hexutil.Big)(big.NewInt(3))}
+	assert.NoError(t, checkPublicInputs(claims, assignment, big.NewInt(3)))
+	wrongOutput := claims
+	wrongOutput.OutputHash = (*hexutil.Big)(big.NewInt(4))
+	err := checkPublicInputs(wrongOutput, assignment, nil)
+	assert.ErrorIs(t, err, types.ErrDigestMismatch)
+	assert.ErrorContains(t, err, "claimed outputHash 0x4, but the plonky2 proof has 0x2")
+
+	// Claims which are not field elements are rejected, even if they are congruent.
+	outOfField := claims
+	outOfField.InputHash = (*hexutil.Big)(new(big.Int).Add(modulus, big.NewInt(1)))
+	assert.ErrorContains(t, checkPublicInputs(outOfField, assignment, nil), "inputHash 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000002 is not an element")
+
+	// So are the public inputs of the plonky2 proof, and a malformed circuit digest.
+	large := *assignment
+	large.OutputHash = modulus
+	assert.ErrorContains(t, checkPublicInputs(proveRequest{}, &large, nil), "outputHash 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001 of the plonky2 proof is not an element")
+	malformed := *assignment
+	malformed.VerifierDigest = (*big.Int)(nil)
+	assert.ErrorContains(t, checkPublicInputs(proveRequest{}, &malformed, nil), "no valid verifierDigest")
+
+	// A fixed verifier circuit only proves the plonky2 proofs of its circuit.
+	err = checkPublicInputs(proveRequest{}, assignment, big.NewInt(5))
+	assert.ErrorIs(t, err, types.ErrDigestMismatch)
+	assert.ErrorContains(t, err, "only proves plonky2 proofs with verifierDigest 0x5, got 0x3")
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// NewSigner creates the signer of the operator key given by the spec, which is one of
+//
+//	env:<VAR>             the hex private key in the environment variable VAR
+//	keystore:<path>       the encrypted JSON keystore at path, with the password in KEYSTORE_PASSWORD
+//	aws-kms:<key id>      the AWS KMS key, in AWS_REGION with the AWS_ACCESS_KEY_ID,
+//	                      AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN credentials
+//	gcp-kms:<key version> the GCP KMS key version, i.e. projects/.../cryptoKeyVersions/<n>, with
+//	                      the access token in GOOGLE_OAUTH_ACCESS_TOKEN or of the metadata server
+//
+// KMS keys must be secp256k1 signing keys, i.e. ECC_SECG_P256K1 keys in AWS and
+// EC_SIGN_SECP256K1_SHA256 keys in GCP.
+func NewSigner(ctx context.Context, spec string) (types.Signer, error) {
+	backend, arg, ok := strings.Cut(spec, ":")
+	if !ok || arg == "" {
+		return nil, fmt.Errorf("invalid signer %q, expected env:<VAR>, keystore:<path>, aws-kms:<key id> or gcp-kms:<key version>", spec)
+	}
+	switch backend {
+	case "env":
+		value := os.Getenv(arg)
+		if value == "" {
+			return nil, fmt.Errorf("%s is not set", arg)
+		}
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", arg, err)
+		}
+		return types.NewPrivateKeySigner(key), nil
+	case "keystore":
+		return LoadKeystoreSigner(arg, os.Getenv("KEYSTORE_PASSWORD"))
+	case "aws-kms":
+		return NewKMSSigner(ctx, &AWSKMS{
+			KeyID:           arg,
+			Region:          os.Getenv("AWS_REGION"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		})
+	case "gcp-kms":
+		return NewKMSSigner(ctx, &GCPKMS{KeyVersion: arg, AccessToken: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")})
+	}
+	return nil, fmt.Errorf("unknown signer backend %q", backend)
+}
+
+// LoadKeystoreSigner decrypts the key in the JSON keystore file, as written by geth account new.
+func LoadKeystoreSigner(path string, password string) (types.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", missingArtifact(err))
+	}
+	key, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return types.NewPrivateKeySigner(key.PrivateKey), nil
+}
+
+// KMSBackend is a key management service holding a secp256k1 key, which never leaves it.
+type KMSBackend interface {
+	// PublicKey returns the DER encoded SubjectPublicKeyInfo of the key.
+	PublicKey(ctx context.Context) ([]byte, error)
+	// Sign signs the digest, and returns the DER encoded ECDSA signature.
+	Sign(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// KMSSigner signs with a key of a KMSBackend. The signatures of the KMS are converted to the
+// format of crypto.Sign, with the low S values required by Ethereum.
+type KMSSigner struct {
+	backend   KMSBackend
+	publicKey *ecdsa.PublicKey
+}
+
+// NewKMSSigner fetches the public key of the KMS key, which the address is derived from.
+func NewKMSSigner(ctx context.Context, backend KMSBackend) (*KMSSigner, error) {
+	der, err := backend.PublicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kms public key: %w", err)
+	}
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, fmt.Errorf("failed to parse kms public key: %w", serializationError(err))
+	}
+	publicKey, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("kms key is not a secp256k1 key: %w", err)
+	}
+	return &KMSSigner{backend: backend, publicKey: publicKey}, nil
+}
+
+func (s *KMSSigner) Address() common.Address {
+	return crypto.PubkeyToAddress(*s.publicKey)
+}
+
+func (s *KMSSigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	der, err := s.backend.Sign(ctx, hash.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with kms: %w", err)
+	}
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse kms signature: %w", serializationError(err))
+	}
+
+	// Ethereum only accepts the lower of the two S values of a signature.
+	n := crypto.S256().Params().N
+	if sig.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		sig.S = new(big.Int).Sub(n, sig.S)
+	}
+	signature := make([]byte, crypto.SignatureLength)
+	sig.R.FillBytes(signature[:32])
+	sig.S.FillBytes(signature[32:64])
+
+	// The KMS does not return the recovery id, so it is found by recovering the key.
+	expected := crypto.FromECDSAPub(s.publicKey)
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+		recovered, err := crypto.Ecrecover(hash.Bytes(), signature)
+		if err == nil && bytes.Equal(recovered, expected) {
+			return signature, nil
+		}
+	}
+	return nil, errors.New("kms signature does not match the kms public key")
+}
+
+// AWSKMS is a key in AWS KMS. Requests are signed with AWS signature version 4.
+type AWSKMS struct {
+	KeyID  string
+	Region string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint overrides the regional endpoint of KMS, e.g. for VPC endpoints.
+	Endpoint string
+	Client   *http.Client
+}
+
+func (k *AWSKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	var response struct {
+		PublicKey []byte
+	}
+	err := k.call(ctx, "GetPublicKey", map[string]interface{}{"KeyId": k.KeyID}, &response)
+	return response.PublicKey, err
+}
+
+func (k *AWSKMS) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	var response struct {
+		Signature []byte
+	}
+	err := k.call(ctx, "Sign", map[string]interface{}{
+		"KeyId":            k.KeyID,
+		"Message":          digest,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &response)
+	return response.Signature, err
+}
+
+// call calls the action of the KMS JSON API. Byte slices are sent and received in base64, which
+// is how encoding/json encodes them.
+func (k *AWSKMS) call(ctx context.Context, action string, request interface{}, response interface{}) error {
+	if k.Region == "" {
+		return errors.New("aws region is not set")
+	}
+	if k.AccessKeyID == "" || k.SecretAccessKey == "" {
+		return errors.New("aws credentials are not set")
+	}
+	endpoint := k.Endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + k.Region + ".amazonaws.com"
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", action, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	k.signRequest(req, body, time.Now().UTC())
+	return doKMSRequest(k.Client, req, action, response)
+}
+
+// signRequest adds the AWS signature version 4 of the request to its headers.
+func (k *AWSKMS) signRequest(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if k.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", k.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + k.Region + "/kms/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + k.SecretAccessKey)
+	for _, part := range []string{date, k.Region, "kms", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		k.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// The metadata server of GCP instances, which provides the access token of their service account.
+const gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCPKMS is a key version in GCP Cloud KMS.
+type GCPKMS struct {
+	// The resource name of the key version, projects/.../cryptoKeyVersions/<n>.
+	KeyVersion string
+
+	// AccessToken authenticates the requests. If empty, the token of the service account of the
+	// instance is fetched from the metadata server, and refreshed before it expires.
+	AccessToken string
+
+	// Endpoint overrides the endpoint of Cloud KMS, https://cloudkms.googleapis.com.
+	Endpoint string
+	Client   *http.Client
+
+	mu            sync.Mutex
+	metadataToken string
+	expiry        time.Time
+}
+
+func (k *GCPKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	var response struct {
+		Pem string `json:"pem"`
+	}
+	err := k.call(ctx, http.MethodGet, "/publicKey", nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(response.Pem))
+	if block == nil {
+		return nil, serializationError(errors.New("public key is not pem encoded"))
+	}
+	return block.Bytes, nil
+}
+
+func (k *GCPKMS) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	var response struct {
+		Signature []byte `json:"signature"`
+	}
+	request := map[string]interface{}{"digest": map[string][]byte{"sha256": digest}}
+	err := k.call(ctx, http.MethodPost, ":asymmetricSign", request, &response)
+	return response.Signature, err
+}
+
+func (k *GCPKMS) call(ctx context.Context, method string, suffix string, request interface{}, response interface{}) error {
+	endpoint := k.Endpoint
+	if endpoint == "" {
+		endpoint = "https://cloudkms.googleapis.com"
+	}
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to marshal kms request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"/v1/"+k.KeyVersion+suffix, body)
+	if err != nil {
+		return err
+	}
+	token, err := k.token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return doKMSRequest(k.Client, req, strings.TrimPrefix(suffix, ":"), response)
+}
+
+func (k *GCPKMS) token(ctx context.Context) (string, error) {
+	if k.AccessToken != "" {
+		return k.AccessToken, nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.metadataToken != "" && time.Now().Before(k.expiry) {
+		return k.metadataToken, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := doKMSRequest(k.Client, req, "metadata token", &response); err != nil {
+		return "", fmt.Errorf("failed to get gcp access token: %w", err)
+	}
+	// The token is refreshed a minute before it expires, so requests do not race its expiry.
+	k.metadataToken = response.AccessToken
+	k.expiry = time.Now().Add(time.Duration(response.ExpiresIn)*time.Second - time.Minute)
+	return k.metadataToken, nil
+}
+
+// doKMSRequest sends the request, and decodes the JSON response. Errors of the service are
+// returned with their body, which holds the reason of the failure.
+func doKMSRequest(client *http.Client, req *http.Request, action string, response interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed with status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", action, serializationError(err))
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// testKMS holds a local key, and signs like a KMS, i.e. without recovery ids and with either of
+// the two S values.
+type testKMS struct {
+	key   *ecdsa.PrivateKey
+	highS bool
+}
+
+func (k *testKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	params, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+	if err != nil {
+		return nil, err
+	}
+	point := crypto.FromECDSAPub(&k.key.PublicKey)
+	return asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, Parameters: asn1.RawValue{FullBytes: params}},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+func (k *testKMS) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	signature, err := crypto.Sign(digest, k.key)
+	if err != nil {
+		return nil, err
+	}
+	s := new(big.Int).SetBytes(signature[32:64])
+	if k.highS {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(signature[:32]), s})
+}
+
+func TestKMSSigner(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	hash := crypto.Keccak256Hash([]byte("fulfillment"))
+
+	for _, highS := range []bool{false, true} {
+		signer, err := NewKMSSigner(ctx, &testKMS{key: key, highS: highS})
+		assert.NoError(t, err)
+		assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+		signature, err := signer.SignHash(ctx, hash)
+		assert.NoError(t, err)
+		recovered, err := crypto.SigToPub(hash.Bytes(), signature)
+		assert.NoError(t, err)
+		assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*recovered))
+		assert.True(t, crypto.ValidateSignatureValues(signature[64], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64]), true))
+	}
+
+	// Receipts signed with a KMS key verify like those signed with a local key.
+	signer, err := NewKMSSigner(ctx, &testKMS{key: key})
+	assert.NoError(t, err)
+	receipt := types.NewReceipt(common.Hash{1}, common.Hash{2}, common.Hash{3}, []byte{1, 2, 3}, 1700000000)
+	assert.NoError(t, receipt.SignWith(ctx, signer))
+	assert.NoError(t, receipt.Verify())
+}
+
+func TestAWSKMS(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	local := &testKMS{key: key}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/"), authorization)
+		assert.Contains(t, authorization, "/us-east-1/kms/aws4_request")
+		assert.Contains(t, authorization, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target")
+		var request struct {
+			KeyId   string
+			Message []byte
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "alias/operator", request.KeyId)
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			publicKey, err := local.PublicKey(r.Context())
+			assert.NoError(t, err)
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string][]byte{"PublicKey": publicKey}))
+		case "TrentService.Sign":
+			signature, err := local.Sign(r.Context(), request.Message)
+			assert.NoError(t, err)
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string][]byte{"Signature": signature}))
+		default:
+			http.Error(w, `{"__type":"UnknownOperationException"}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	signer, err := NewKMSSigner(ctx, &AWSKMS{
+		KeyID:           "alias/operator",
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        server.URL,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+	hash := crypto.Keccak256Hash([]byte("fulfillment"))
+	signature, err := signer.SignHash(ctx, hash)
+	assert.NoError(t, err)
+	recovered, err := crypto.SigToPub(hash.Bytes(), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*recovered))
+
+	_, err = NewKMSSigner(ctx, &AWSKMS{KeyID: "alias/operator", Endpoint: server.URL})
+	assert.ErrorContains(t, err, "region")
+}
+
+func TestGCPKMS(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	local := &testKMS{key: key}
+	keyVersion := "projects/p/locations/global/keyRings/r/cryptoKeys/operator/cryptoKeyVersions/1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1/" + keyVersion + "/publicKey":
+			publicKey, err := local.PublicKey(r.Context())
+			assert.NoError(t, err)
+			pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string]string{"pem": string(pemKey)}))
+		case "/v1/" + keyVersion + ":asymmetricSign":
+			var request struct {
+				Digest struct {
+					Sha256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			signature, err := local.Sign(r.Context(), request.Digest.Sha256)
+			assert.NoError(t, err)
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string][]byte{"signature": signature}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	signer, err := NewKMSSigner(ctx, &GCPKMS{KeyVersion: keyVersion, AccessToken: "token", Endpoint: server.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+	hash := crypto.Keccak256Hash([]byte("fulfillment"))
+	signature, err := signer.SignHash(ctx, hash)
+	assert.NoError(t, err)
+	recovered, err := crypto.SigToPub(hash.Bytes(), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*recovered))
+
+	_, err = NewKMSSigner(ctx, &GCPKMS{KeyVersion: "missing", AccessToken: "token", Endpoint: server.URL})
+	assert.ErrorContains(t, err, "status 404")
+}
+
+func TestNewSigner(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	t.Setenv("OPERATOR_KEY", common.Bytes2Hex(crypto.FromECDSA(key)))
+	signer, err := NewSigner(ctx, "env:OPERATOR_KEY")
+	assert.NoError(t, err)
+	assert.Equal(t, address, signer.Address())
+
+	account, err := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP).ImportECDSA(key, "password")
+	assert.NoError(t, err)
+	path := account.URL.Path
+	t.Setenv("KEYSTORE_PASSWORD", "password")
+	signer, err = NewSigner(ctx, "keystore:"+path)
+	assert.NoError(t, err)
+	assert.Equal(t, address, signer.Address())
+
+	t.Setenv("KEYSTORE_PASSWORD", "wrong")
+	_, err = NewSigner(ctx, "keystore:"+path)
+	assert.Error(t, err)
+
+	_, err = NewSigner(ctx, "env:MISSING_OPERATOR_KEY")
+	assert.ErrorContains(t, err, "MISSING_OPERATOR_KEY")
+	_, err = NewSigner(ctx, "vault:operator")
+	assert.ErrorContains(t, err, "unknown signer")
+	_, err = NewSigner(ctx, "operator")
+	assert.ErrorContains(t, err, "invalid signer")
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/test"
+	pprof "github.com/google/pprof/profile"
+)
+
+// The size of the circuit the proving time estimate is calibrated on.
+const calibrationConstraints = 1 << 14
+
+// CircuitStats is a constraint and cost report for the verifier circuit.
+type CircuitStats struct {
+	NbConstraints       int `json:"nb_constraints"`
+	NbPublicVariables   int `json:"nb_public_variables"`
+	NbSecretVariables   int `json:"nb_secret_variables"`
+	NbInternalVariables int `json:"nb_internal_variables"`
+	NbCommitments       int `json:"nb_commitments"`
+
+	// The size of the evaluation domain, i.e. the number of rows of the PLONK trace.
+	DomainSize uint64 `json:"domain_size"`
+
+	// The estimated size of pk.bin, and the estimated proving time and memory on this machine, see
+	// MachineCalibration.Estimate.
+	EstimatedProvingKeyBytes    uint64 `json:"estimated_proving_key_bytes"`
+	EstimatedProvingTime        string `json:"estimated_proving_time,omitempty"`
+	EstimatedProvingMemoryBytes uint64 `json:"estimated_proving_memory_bytes,omitempty"`
+
+	// The constraints attributed to each package, e.g. poseidon or fri, and to each function.
+	Packages  []GadgetStats `json:"packages"`
+	Functions []GadgetStats `json:"functions"`
+}
+
+type GadgetStats struct {
+	Name          string  `json:"name"`
+	NbConstraints int     `json:"nb_constraints"`
+	Percent       float64 `json:"percent"`
+}
+
+// ComputeCircuitStats compiles the verifier circuit for the plonky2 circuit in dummyCircuitPath, like
+// CompileConstraintSystem, with the gnark profiler enabled and reports its size and costs.
+// Constraints are attributed to the innermost function of the verifier circuit or
+// gnark-plonky2-verifier they were created in. If calibrate is set, a small circuit is proven to
+// estimate the proving time.
+func ComputeCircuitStats(dummyCircuitPath string, fixedDigest bool, nbFunctions int, calibrate bool) (*CircuitStats, error) {
+	log := logger.Logger()
+
+	profilePath := filepath.Join(os.TempDir(), fmt.Sprintf("plonky2x-verifier-%d.pprof", os.Getpid()))
+	defer os.Remove(profilePath)
+	p := profile.Start(profile.WithPath(profilePath))
+	r1cs, err := CompileConstraintSystem(dummyCircuitPath, fixedDigest)
+	p.Stop()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CircuitStats{
+		NbConstraints:       r1cs.GetNbConstraints(),
+		NbPublicVariables:   r1cs.GetNbPublicVariables(),
+		NbSecretVariables:   r1cs.GetNbSecretVariables(),
+		NbInternalVariables: r1cs.GetNbInternalVariables(),
+		NbCommitments:       len(r1cs.GetCommitments().CommitmentIndexes()),
+	}
+	shape := ShapeOf(r1cs)
+	stats.DomainSize = shape.DomainSize()
+	stats.EstimatedProvingKeyBytes = estimateProvingKeyBytes(stats.DomainSize, stats.NbCommitments)
+
+	packages, functions, err := readProfile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	stats.Packages = topGadgets(packages, 0)
+	stats.Functions = topGadgets(functions, nbFunctions)
+
+	if calibrate {
+		log.Info().Msg("Calibrating the proving time estimate")
+		calibration, err := Calibrate()
+		if err != nil {
+			return nil, err
+		}
+		estimate := calibration.Estimate(shape)
+		stats.EstimatedProvingTime = estimate.ProvingTime.String()
+		stats.EstimatedProvingMemoryBytes = estimate.MemoryBytes
+	}
+
+	return stats, nil
+}
+
+// estimateProvingKeyBytes estimates the size of the raw PLONK proving key. It is dominated by the
+// two KZG SRS in canonical and Lagrange form (uncompressed G1 points), the selector and permutation
+// polynomials, and the permutation itself.
+func estimateProvingKeyBytes(domainSize uint64, nbCommitments int) uint64 {
+	const g1Bytes, frBytes, indexBytes = 64, 32, 8
+	srs := (2*domainSize + 3) * g1Bytes
+	polynomials := domainSize * uint64(8+nbCommitments) * frBytes
+	permutation := 3 * domainSize * indexBytes
+	return srs + polynomials + permutation
+}
+
+// scaleProvingTime extrapolates the proving time measured on a domain of calibrationSize rows to a
+// domain of domainSize rows, assuming it grows like n log n.
+func scaleProvingTime(elapsed time.Duration, calibrationSize uint64, domainSize uint64) time.Duration {
+	cost := func(n uint64) float64 {
+		return float64(n) * float64(bits.Len64(n))
+	}
+	return time.Duration(float64(elapsed) * cost(domainSize) / cost(calibrationSize))
+}
+
+type calibrationCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *calibrationCircuit) Define(api frontend.API) error {
+	acc := c.Y
+	for i := 0; i < calibrationConstraints-2; i++ {
+		acc = api.Mul(acc, c.Y)
+	}
+	api.AssertIsDifferent(acc, c.X)
+	return nil
+}
+
+func calibrateProvingTime() (time.Duration, error) {
+	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &calibrationCircuit{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to compile calibration circuit: %w", err)
+	}
+	srs, err := test.NewKZGSRS(r1cs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create calibration srs: %w", err)
+	}
+	pk, _, err := plonk.Setup(r1cs, srs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run calibration setup: %w", err)
+	}
+	witness, err := frontend.NewWitness(&calibrationCircuit{X: 0, Y: 2}, ecc.BN254.ScalarField())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create calibration witness: %w", err)
+	}
+	start := time.Now()
+	_, err = plonk.Prove(r1cs, pk, witness)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prove calibration circuit: %w", err)
+	}
+	return time.Since(start), nil
+}
+
+// readProfile attributes the constraints recorded in the pprof file to packages and functions.
+func readProfile(path string) (map[string]int, map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open profile: %w", err)
+	}
+	defer f.Close()
+	prof, err := pprof.Parse(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	packages := make(map[string]int)
+	functions := make(map[string]int)
+	for _, sample := range prof.Sample {
+		function := attributeSample(sample)
+		packages[functionPackage(function)] += int(sample.Value[0])
+		functions[function] += int(sample.Value[0])
+	}
+	return packages, functions, nil
+}
+
+// attributeSample returns the innermost function of the sample's stack that belongs to the verifier
+// circuit, skipping the gnark internals.
+func attributeSample(sample *pprof.Sample) string {
+	for _, location := range sample.Location {
+		for _, line := range location.Line {
+			name := line.Function.SystemName
+			if strings.HasPrefix(name, "main.") || strings.HasPrefix(name, "github.com/succinctlabs/") {
+				return name
+			}
+		}
+	}
+	return "other"
+}
+
+// functionPackage returns the package of a function name, e.g. poseidon for
+// github.com/succinctlabs/gnark-plonky2-verifier/poseidon.(*GoldilocksChip).Poseidon.
+func functionPackage(function string) string {
+	function = strings.TrimPrefix(function, "github.com/succinctlabs/gnark-plonky2-verifier/")
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+func topGadgets(counts map[string]int, limit int) []GadgetStats {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	gadgets := make([]GadgetStats, 0, len(counts))
+	for name, count := range counts {
+		gadgets = append(gadgets, GadgetStats{
+			Name:          name,
+			NbConstraints: count,
+			Percent:       100 * float64(count) / float64(total),
+		})
+	}
+	sort.Slice(gadgets, func(i, j int) bool {
+		if gadgets[i].NbConstraints != gadgets[j].NbConstraints {
+			return gadgets[i].NbConstraints > gadgets[j].NbConstraints
+		}
+		return gadgets[i].Name < gadgets[j].Name
+	})
+	if limit > 0 && len(gadgets) > limit {
+		gadgets = gadgets[:limit]
+	}
+	return gadgets
+}
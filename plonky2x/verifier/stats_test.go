@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitStatsProfile(t *testing.T) {
+	profilePath := filepath.Join(t.TempDir(), "gnark.pprof")
+	p := profile.Start(profile.WithPath(profilePath))
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: 10})
+	p.Stop()
+	assert.NoError(t, err)
+
+	packages, functions, err := readProfile(profilePath)
+	assert.NoError(t, err)
+	gadgets := topGadgets(functions, 1)
+	assert.Len(t, gadgets, 1)
+	assert.Contains(t, gadgets[0].Name, "fingerprintCircuit).Define")
+	assert.Equal(t, p.NbConstraints(), gadgets[0].NbConstraints)
+	assert.Equal(t, 100.0, topGadgets(packages, 0)[0].Percent)
+}
+
+func TestCircuitStatsEstimates(t *testing.T) {
+	assert.Equal(t, uint64(1), domainSize(1))
+	assert.Equal(t, uint64(1024), domainSize(1024))
+	assert.Equal(t, uint64(2048), domainSize(1025))
+
+	assert.Equal(t, "poseidon", functionPackage("github.com/succinctlabs/gnark-plonky2-verifier/poseidon.(*GoldilocksChip).Poseidon"))
+	assert.Equal(t, "plonk/gates", functionPackage("github.com/succinctlabs/gnark-plonky2-verifier/plonk/gates.(*PoseidonGate).EvalUnfiltered"))
+	assert.Equal(t, "main", functionPackage("main.(*Plonky2xVerifierCircuit).Define"))
+
+	// Doubling the domain a bit more than doubles the proving time.
+	assert.Equal(t, 22*time.Second, scaleProvingTime(10*time.Second, 1<<9, 1<<10))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// The domain separation tag the PLONK verifier hashes the BSB22 commitments to the field with.
+const bsb22DST = "BSB22-Plonk"
+
+// The challenges of the PLONK verifier, in the order they are derived.
+var plonkChallenges = []string{"gamma", "beta", "alpha", "zeta"}
+
+// The address of the SHA-256 precompile, which the Solidity PLONK verifier derives its challenges
+// with.
+var sha256Precompile = common.BytesToAddress([]byte{0x02})
+
+// PlonkTranscript recomputes the Fiat-Shamir transcript of the PLONK verifier for the proof: the
+// hashes of the BSB22 commitments, followed by the SHA-256 prehashes the challenges gamma, beta,
+// alpha and zeta are derived from, as the Go verifier and the Solidity verifier hash them.
+func PlonkTranscript(vk *plonk_bn254.VerifyingKey, proof *plonk_bn254.Proof, publicInputs fr.Vector) ([]types.TranscriptStep, error) {
+	if len(proof.Bsb22Commitments) != len(vk.Qcp) {
+		return nil, fmt.Errorf("%w: expected %d commitments, got %d", types.ErrProofInvalid, len(vk.Qcp), len(proof.Bsb22Commitments))
+	}
+	var transcript []types.TranscriptStep
+	for i := range proof.Bsb22Commitments {
+		prehash := proof.Bsb22Commitments[i].Marshal()
+		hash, err := fr.Hash(prehash, []byte(bsb22DST), 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash commitment %d: %w", i, err)
+		}
+		transcript = append(transcript, types.TranscriptStep{
+			Name:      fmt.Sprintf("commitment[%d]", i),
+			DST:       bsb22DST,
+			Prehash:   prehash,
+			Challenge: (*hexutil.Big)(hash[0].BigInt(new(big.Int))),
+		})
+	}
+
+	points := func(points ...bn254.G1Affine) []byte {
+		var data []byte
+		for i := range points {
+			data = append(data, points[i].Marshal()...)
+		}
+		return data
+	}
+	var gamma []byte
+	gamma = append(gamma, points(vk.S[0], vk.S[1], vk.S[2], vk.Ql, vk.Qr, vk.Qm, vk.Qo, vk.Qk)...)
+	gamma = append(gamma, points(vk.Qcp...)...)
+	for i := range publicInputs {
+		gamma = append(gamma, publicInputs[i].Marshal()...)
+	}
+	gamma = append(gamma, points(proof.LRO[:]...)...)
+	bindings := map[string][]byte{
+		"gamma": gamma,
+		"beta":  nil,
+		"alpha": append(points(proof.Bsb22Commitments...), points(proof.Z)...),
+		"zeta":  points(proof.H[:]...),
+	}
+
+	// Each challenge hashes its name, the digest of the previous challenge and its bindings.
+	var previous []byte
+	for _, name := range plonkChallenges {
+		prehash := append(append([]byte(name), previous...), bindings[name]...)
+		digest := sha256.Sum256(prehash)
+		transcript = append(transcript, newChallengeStep(name, prehash, digest[:]))
+		previous = digest[:]
+	}
+	return transcript, nil
+}
+
+// newChallengeStep returns the step of a challenge derived from the digest of the prehash.
+func newChallengeStep(name string, prehash []byte, digest []byte) types.TranscriptStep {
+	var challenge fr.Element
+	challenge.SetBytes(digest)
+	return types.TranscriptStep{
+		Name:      name,
+		Prehash:   prehash,
+		Digest:    digest,
+		Challenge: (*hexutil.Big)(challenge.BigInt(new(big.Int))),
+	}
+}
+
+// transcriptTracer records the inputs and outputs of the calls of the SHA-256 precompile.
+type transcriptTracer struct {
+	// The inputs of the calls entered and not exited yet, which are nil for other contracts.
+	inputs     [][]byte
+	transcript []types.TranscriptStep
+}
+
+func (t *transcriptTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// The input points into the memory of the caller, so it is copied.
+	var recorded []byte
+	if to == sha256Precompile {
+		recorded = append([]byte{}, input...)
+	}
+	t.inputs = append(t.inputs, recorded)
+}
+
+func (t *transcriptTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	input := t.inputs[len(t.inputs)-1]
+	t.inputs = t.inputs[:len(t.inputs)-1]
+	if input == nil || err != nil {
+		return
+	}
+	// The challenges are named by the prefix of their prehash, other hashes, e.g. of hashes to
+	// the field, are named after the precompile.
+	name := "sha256"
+	for _, challenge := range plonkChallenges {
+		if bytes.HasPrefix(input, []byte(challenge)) {
+			name = challenge
+			break
+		}
+	}
+	t.transcript = append(t.transcript, newChallengeStep(name, input, append([]byte{}, output...)))
+}
+
+func (t *transcriptTracer) CaptureTxStart(gasLimit uint64) {}
+func (t *transcriptTracer) CaptureTxEnd(restGas uint64)    {}
+func (t *transcriptTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+func (t *transcriptTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+func (t *transcriptTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *transcriptTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// EVMTranscript verifies the Solidity encoding of a proof with the PLONK verifier with the creation
+// bytecode in an in-process EVM like VerifyInEVM, and returns the SHA-256 hashes the verifier
+// computed, i.e. its Fiat-Shamir transcript. The transcript is returned along with the error if
+// the proof is rejected, since that is when it is needed.
+func EVMTranscript(bytecode []byte, proof []byte, publicWitness witness.Witness) ([]types.TranscriptStep, error) {
+	calldata, err := PlonkVerifierCalldata(proof, publicWitness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode calldata: %w", err)
+	}
+	cfg := &runtime.Config{}
+	_, address, _, err := runtime.Create(bytecode, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy verifier: %w", err)
+	}
+	tracer := &transcriptTracer{}
+	cfg.EVMConfig.Tracer = tracer
+	ret, _, err := runtime.Call(address, calldata, cfg)
+	if err != nil {
+		return tracer.transcript, fmt.Errorf("%w: verifier reverted: %w", types.ErrProofInvalid, err)
+	}
+	if len(ret) != 32 || new(big.Int).SetBytes(ret).Cmp(big.NewInt(1)) != 0 {
+		return tracer.transcript, fmt.Errorf("%w: verifier rejected the proof", types.ErrProofInvalid)
+	}
+	return tracer.transcript, nil
+}
+
+// CompareTranscripts compares the challenges of the transcript of the Go verifier with the first
+// ones of the same name in the transcript of the onchain verifier, and reports the first which
+// differs, and where its prehashes differ.
+func CompareTranscripts(expected []types.TranscriptStep, actual []types.TranscriptStep) error {
+	for _, step := range expected {
+		if step.Digest == nil {
+			continue
+		}
+		var found *types.TranscriptStep
+		for i := range actual {
+			if actual[i].Name == step.Name {
+				found = &actual[i]
+				break
+			}
+		}
+		if found == nil {
+			return fmt.Errorf("the onchain verifier did not derive %s", step.Name)
+		}
+		if bytes.Equal(step.Prehash, found.Prehash) {
+			continue
+		}
+		offset := 0
+		for offset < len(step.Prehash) && offset < len(found.Prehash) && step.Prehash[offset] == found.Prehash[offset] {
+			offset++
+		}
+		return fmt.Errorf("the prehashes of %s differ at byte %d, with %d bytes in go and %d bytes onchain", step.Name, offset, len(step.Prehash), len(found.Prehash))
+	}
+	return nil
+}
+
+// logTranscript logs the transcript of the PLONK verifier of the proof, and if bytecodeFile is not
+// empty, the transcript of the onchain verifier with its bytecode and where they differ.
+func logTranscript(proof plonk.Proof, vk plonk.VerifyingKey, publicWitness witness.Witness, bytecodeFile string) error {
+	log := logger.Logger()
+	_proof, ok := proof.(*plonk_bn254.Proof)
+	if !ok {
+		return fmt.Errorf("unsupported proof %T", proof)
+	}
+	_vk, ok := vk.(*plonk_bn254.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("unsupported verifying key %T", vk)
+	}
+	publicInputs, ok := publicWitness.Vector().(fr.Vector)
+	if !ok {
+		return fmt.Errorf("unsupported public witness %T", publicWitness.Vector())
+	}
+	expected, err := PlonkTranscript(_vk, _proof, publicInputs)
+	if err != nil {
+		return fmt.Errorf("failed to compute the transcript: %w", err)
+	}
+	logSteps := func(verifier string, transcript []types.TranscriptStep) {
+		for _, step := range transcript {
+			log.Info().Str("verifier", verifier).Str("name", step.Name).Str("dst", step.DST).
+				Str("prehash", step.Prehash.String()).Str("digest", step.Digest.String()).
+				Str("challenge", step.Challenge.String()).Msg("Transcript")
+		}
+	}
+	logSteps("go", expected)
+	if bytecodeFile == "" {
+		return nil
+	}
+
+	bytecode, err := readBytecodeFile(bytecodeFile)
+	if err != nil {
+		return err
+	}
+	actual, verifyErr := EVMTranscript(bytecode, _proof.MarshalSolidity(), publicWitness)
+	logSteps("evm", actual)
+	if err := CompareTranscripts(expected, actual); err != nil {
+		log.Warn().Msg(fmt.Sprintf("The transcripts differ: %s", err))
+	} else {
+		log.Info().Msg("The challenges of the transcripts match")
+	}
+	if verifyErr != nil {
+		log.Warn().Msg(fmt.Sprintf("The onchain verifier rejected the proof: %s", verifyErr))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// transcriptCircuit has a BSB22 commitment, which is bound in the transcript.
+type transcriptCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *transcriptCircuit) Define(api frontend.API) error {
+	commitment, err := api.(frontend.Committer).Commit(c.X, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, c.Y)
+	api.AssertIsEqual(c.X, api.Mul(c.Y, c.Y))
+	return nil
+}
+
+func TestPlonkTranscript(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &transcriptCircuit{})
+	assert.NoError(t, err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(t, err)
+	pk, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(t, err)
+	full, err := frontend.NewWitness(&transcriptCircuit{X: 9, Y: 3}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	publicWitness, err := full.Public()
+	assert.NoError(t, err)
+	proof, err := plonk.Prove(ccs, pk, full)
+	assert.NoError(t, err)
+	_vk, _proof := vk.(*plonk_bn254.VerifyingKey), proof.(*plonk_bn254.Proof)
+
+	transcript, err := PlonkTranscript(_vk, _proof, publicWitness.Vector().(fr.Vector))
+	assert.NoError(t, err)
+	var names []string
+	for _, step := range transcript {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"commitment[0]", "gamma", "beta", "alpha", "zeta"}, names)
+	assert.Equal(t, "BSB22-Plonk", transcript[0].DST)
+
+	// The challenges are those of the transcript of the verifier of gnark.
+	fs := fiatshamir.NewTranscript(sha256.New(), "gamma", "beta", "alpha", "zeta")
+	bind := func(challenge string, data []byte) {
+		assert.NoError(t, fs.Bind(challenge, data))
+	}
+	for _, p := range []bn254.G1Affine{_vk.S[0], _vk.S[1], _vk.S[2], _vk.Ql, _vk.Qr, _vk.Qm, _vk.Qo, _vk.Qk} {
+		bind("gamma", p.Marshal())
+	}
+	for i := range _vk.Qcp {
+		bind("gamma", _vk.Qcp[i].Marshal())
+	}
+	for _, input := range publicWitness.Vector().(fr.Vector) {
+		bind("gamma", input.Marshal())
+	}
+	for i := range _proof.LRO {
+		bind("gamma", _proof.LRO[i].Marshal())
+	}
+	for i := range _proof.Bsb22Commitments {
+		bind("alpha", _proof.Bsb22Commitments[i].Marshal())
+	}
+	bind("alpha", _proof.Z.Marshal())
+	for i := range _proof.H {
+		bind("zeta", _proof.H[i].Marshal())
+	}
+	for i, name := range []string{"gamma", "beta", "alpha", "zeta"} {
+		digest, err := fs.ComputeChallenge(name)
+		assert.NoError(t, err)
+		assert.Equal(t, digest, []byte(transcript[1+i].Digest), name)
+	}
+}
+
+func TestEVMTranscript(t *testing.T) {
+	// A verifier which hashes "gamma" with the SHA-256 precompile, and returns true.
+	verifier := evmContract("6467616d6d61600052602060206005601b60025afa50600160005260206000f3")
+	transcript, err := EVMTranscript(verifier, []byte{0xaa}, newTestPublicWitness(t, 1, 2, 3))
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte("gamma"))
+	assert.Equal(t, []types.TranscriptStep{newChallengeStep("gamma", []byte("gamma"), digest[:])}, transcript)
+
+	// The first challenge whose prehash differs is reported.
+	assert.NoError(t, CompareTranscripts(transcript, transcript))
+	expected := []types.TranscriptStep{newChallengeStep("gamma", []byte("gammb"), digest[:])}
+	assert.ErrorContains(t, CompareTranscripts(expected, transcript), "prehashes of gamma differ at byte 4")
+	expected = []types.TranscriptStep{newChallengeStep("beta", []byte("beta"), digest[:])}
+	assert.ErrorContains(t, CompareTranscripts(expected, transcript), "did not derive beta")
+}
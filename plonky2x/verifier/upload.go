@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark/logger"
+)
+
+// ResumableUploader uploads proof artifacts to object storage using the resumable upload protocol
+// of Google Cloud Storage. Files are sent in chunks and after a network failure the upload resumes
+// from the last byte the server persisted, instead of starting over. The crc32c of the whole file
+// is sent with the last chunk so that the server rejects corrupted uploads.
+type ResumableUploader struct {
+	Client *http.Client
+
+	// Token is sent as a bearer token with every request, if set.
+	Token string
+
+	// ChunkSize must be a multiple of 256 KiB, except for the last chunk.
+	ChunkSize int64
+
+	// MaxRetries is the number of consecutive failed requests tolerated before giving up.
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+func NewResumableUploader(token string) *ResumableUploader {
+	return &ResumableUploader{
+		Client:     http.DefaultClient,
+		Token:      token,
+		ChunkSize:  8 << 20,
+		MaxRetries: 5,
+		RetryDelay: time.Second,
+	}
+}
+
+// Start initiates an upload session at the initiation url and returns the session url.
+func (u *ResumableUploader) Start(ctx context.Context, initURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, initURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	u.authorize(req)
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to start upload: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to start upload: %s", resp.Status)
+	}
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		return "", fmt.Errorf("failed to start upload: no session url returned")
+	}
+	return sessionURL, nil
+}
+
+// UploadFile uploads a file to an upload session.
+func (u *ResumableUploader) UploadFile(ctx context.Context, sessionURL string, path string) error {
+	log := logger.Logger()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	size := info.Size()
+
+	checksum := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	if _, err := io.Copy(checksum, file); err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+
+	var offset int64
+	failures := 0
+	for {
+		done, next, err := u.sendChunk(ctx, sessionURL, file, offset, size, checksum.Sum32())
+		if err == nil {
+			if done {
+				log.Info().Msg(fmt.Sprintf("Uploaded %s (%d bytes)", path, size))
+				return nil
+			}
+			offset = next
+			failures = 0
+			continue
+		}
+
+		var permanent *permanentUploadError
+		failures++
+		if errors.As(err, &permanent) || failures > u.MaxRetries {
+			return fmt.Errorf("failed to upload %s: %w", path, err)
+		}
+		log.Err(err).Msg(fmt.Sprintf("upload of %s interrupted at byte %d, retrying", path, offset))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(u.RetryDelay):
+		}
+
+		// Resume from what the server actually persisted, which may be less than what was sent.
+		done, next, err = u.queryStatus(ctx, sessionURL, size)
+		if err != nil {
+			continue
+		}
+		if done {
+			return nil
+		}
+		offset = next
+	}
+}
+
+// sendChunk sends the chunk starting at offset. It returns whether the upload is complete and, if
+// not, the offset to continue from.
+func (u *ResumableUploader) sendChunk(
+	ctx context.Context,
+	sessionURL string,
+	file io.ReaderAt,
+	offset int64,
+	size int64,
+	crc uint32,
+) (bool, int64, error) {
+	length := u.ChunkSize
+	if offset+length > size {
+		length = size - offset
+	}
+	chunk := make([]byte, length)
+	if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+		return false, offset, fmt.Errorf("failed to read chunk: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, bytes.NewReader(chunk))
+	if err != nil {
+		return false, offset, fmt.Errorf("failed to create chunk request: %w", err)
+	}
+	u.authorize(req)
+	if size == 0 {
+		req.Header.Set("Content-Range", "bytes */0")
+	} else {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size))
+	}
+	last := offset+length == size
+	if last {
+		var crcBytes [4]byte
+		binary.BigEndian.PutUint32(crcBytes[:], crc)
+		req.Header.Set("X-Goog-Hash", "crc32c="+base64.StdEncoding.EncodeToString(crcBytes[:]))
+	}
+	return u.do(req, size, crc, last)
+}
+
+// queryStatus asks the server how many bytes of the upload it persisted.
+func (u *ResumableUploader) queryStatus(ctx context.Context, sessionURL string, size int64) (bool, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to create status request: %w", err)
+	}
+	u.authorize(req)
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	return u.do(req, size, 0, false)
+}
+
+func (u *ResumableUploader) do(req *http.Request, size int64, crc uint32, checkCRC bool) (bool, int64, error) {
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		if checkCRC {
+			if err := verifyUploadChecksum(resp.Body, crc); err != nil {
+				return false, 0, err
+			}
+		}
+		return true, size, nil
+	case resp.StatusCode == http.StatusPermanentRedirect:
+		// 308 Resume Incomplete. The Range header holds the persisted bytes, if any.
+		persisted := resp.Header.Get("Range")
+		if persisted == "" {
+			return false, 0, nil
+		}
+		end, err := strconv.ParseInt(persisted[strings.LastIndex(persisted, "-")+1:], 10, 64)
+		if err != nil {
+			return false, 0, fmt.Errorf("invalid range %q: %w", persisted, err)
+		}
+		return false, end + 1, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return false, 0, fmt.Errorf("upload request failed: %s", resp.Status)
+	default:
+		// Client errors, including checksum mismatches, are not retried.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, 0, &permanentUploadError{fmt.Errorf("upload rejected: %s: %s", resp.Status, body)}
+	}
+}
+
+// verifyUploadChecksum compares the crc32c of the uploaded object, if the server returned it, with
+// the local one.
+func verifyUploadChecksum(body io.Reader, crc uint32) error {
+	var object struct {
+		Crc32c string `json:"crc32c"`
+	}
+	if err := json.NewDecoder(body).Decode(&object); err != nil || object.Crc32c == "" {
+		return nil
+	}
+	remote, err := base64.StdEncoding.DecodeString(object.Crc32c)
+	if err != nil || len(remote) != 4 || binary.BigEndian.Uint32(remote) != crc {
+		return &permanentUploadError{fmt.Errorf("uploaded object checksum %s does not match", object.Crc32c)}
+	}
+	return nil
+}
+
+type permanentUploadError struct {
+	err error
+}
+
+func (e *permanentUploadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentUploadError) Unwrap() error {
+	return e.err
+}
+
+func (u *ResumableUploader) authorize(req *http.Request) {
+	if u.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+}
+
+// UploadArtifacts uploads each of the files that exist. The initiation url of each file is
+// initURL with {name} replaced by the file name.
+func (u *ResumableUploader) UploadArtifacts(ctx context.Context, initURL string, files []string) error {
+	for _, file := range files {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+		sessionURL, err := u.Start(ctx, strings.ReplaceAll(initURL, "{name}", filepath.Base(file)))
+		if err != nil {
+			return err
+		}
+		if err := u.UploadFile(ctx, sessionURL, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUploadServer implements the resumable upload protocol and drops the first attempt at the
+// second chunk, after persisting only part of it.
+type fakeUploadServer struct {
+	data    []byte
+	dropped bool
+	hash    string
+}
+
+func (s *fakeUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		w.Header().Set("Location", "http://"+r.Host+"/session")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	contentRange := strings.TrimPrefix(r.Header.Get("Content-Range"), "bytes ")
+	span, total, _ := strings.Cut(contentRange, "/")
+	if span != "*" {
+		start, _ := strconv.Atoi(strings.Split(span, "-")[0])
+		if start != len(s.data) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if start > 0 && !s.dropped {
+			s.dropped = true
+			s.data = append(s.data, body[:len(body)/2]...)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		s.data = append(s.data, body...)
+		s.hash = r.Header.Get("X-Goog-Hash")
+	}
+	if strconv.Itoa(len(s.data)) == total {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(s.data)-1))
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+func TestResumableUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("public witness "), 1000)
+	path := filepath.Join(t.TempDir(), "public_witness.bin")
+	assert.NoError(t, os.WriteFile(path, content, 0644))
+
+	fake := &fakeUploadServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	uploader := NewResumableUploader("")
+	uploader.ChunkSize = 4096
+	uploader.RetryDelay = 0
+	assert.NoError(t, uploader.UploadArtifacts(context.Background(), server.URL+"/upload?name={name}", []string{path}))
+
+	// The upload resumed after the dropped chunk and the checksum was sent with the last chunk.
+	assert.True(t, fake.dropped)
+	assert.Equal(t, content, fake.data)
+	assert.True(t, strings.HasPrefix(fake.hash, "crc32c="))
+}
@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"time"
+	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/plonk"
@@ -15,21 +15,22 @@ import (
 	"github.com/consensys/gnark/logger"
 )
 
-func LoadVerifierKey(path string) (plonk.VerifyingKey, error) {
-	log := logger.Logger()
-	vkFile, err := os.Open(path + "/vk.bin")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open vk file: %w", err)
+// LoadVerifierKey reads the verifying key in the data directory. With unsafeDeserialize, it is read
+// without subgroup checks once its checksum has been verified, like the proving key.
+func LoadVerifierKey(path string, unsafeDeserialize bool) (plonk.VerifyingKey, error) {
+	if unsafeDeserialize {
+		if err := VerifyChecksum(path, "vk.bin"); err != nil {
+			return nil, fmt.Errorf("refusing to deserialize vk file without subgroup checks: %w", err)
+		}
 	}
 	vk := plonk.NewVerifyingKey(ecc.BN254)
-	start := time.Now()
-	_, err = vk.ReadFrom(vkFile)
-	if err != nil {
+	readFrom := vk.ReadFrom
+	if unsafeDeserialize {
+		readFrom = vk.UnsafeReadFrom
+	}
+	if err := loadArtifact(path, "vk.bin", "verifying key", readFrom); err != nil {
 		return nil, fmt.Errorf("failed to read vk file: %w", err)
 	}
-	vkFile.Close()
-	elapsed := time.Since(start)
-	log.Debug().Msg("Successfully loaded verifying key, time: " + elapsed.String())
 
 	return vk, nil
 }
@@ -38,7 +39,7 @@ func LoadPublicWitness(circuitPath string) (witness.Witness, error) {
 	log := logger.Logger()
 	witnessFile, err := os.Open(circuitPath + "/public_witness.bin")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open public witness file: %w", err)
+		return nil, fmt.Errorf("failed to open public witness file: %w", missingArtifact(err))
 	}
 	publicWitness, err := witness.New(ecc.BN254.ScalarField())
 	if err != nil {
@@ -55,7 +56,7 @@ func LoadProof() (plonk.Proof, error) {
 	log := logger.Logger()
 	proofFile, err := os.Open("/proof.json")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open proof file: %w", err)
+		return nil, fmt.Errorf("failed to open proof file: %w", missingArtifact(err))
 	}
 	proof := plonk.NewProof(ecc.BN254)
 	jsonProof, err := io.ReadAll(proofFile)
@@ -64,7 +65,7 @@ func LoadProof() (plonk.Proof, error) {
 	}
 	err = json.Unmarshal(jsonProof, proof)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read proof file: %w", err)
+		return nil, fmt.Errorf("failed to read proof file: %w", serializationError(err))
 	}
 	proofFile.Close()
 	log.Debug().Msg("Successfully loaded proof")
@@ -98,3 +99,64 @@ func ExportIFunctionVerifierSolidity(path string, vk plonk.VerifyingKey) error {
 	contractFile.Close()
 	return err
 }
+
+// The FunctionVerifier of a fixed verifier circuit. Unlike the one plonky2x generates for the
+// universal verifier circuit, the circuit digest is not passed to the PLONK verifier since it is a
+// constant of the circuit.
+const fixedFunctionVerifierSolidity = `
+interface IFunctionVerifier {
+    function verify(bytes32 _inputHash, bytes32 _outputHash, bytes memory _proof) external view returns (bool);
+
+    function verificationKeyHash() external pure returns (bytes32);
+}
+
+contract FunctionVerifier is IFunctionVerifier, PlonkVerifier {
+
+    bytes32 public constant CIRCUIT_DIGEST = {CIRCUIT_DIGEST};
+
+    function verify(bytes32 _inputHash, bytes32 _outputHash, bytes memory _proof) external view returns (bool) {
+        uint256[] memory input = new uint256[](2);
+        input[0] = {TRUNCATE_INPUT_HASH};
+        input[1] = {TRUNCATE_OUTPUT_HASH};
+
+        return this.verifyProof(_proof, input);
+    }
+
+    function verificationKeyHash() external pure returns (bytes32) {
+        return CIRCUIT_DIGEST;
+    }
+}
+`
+
+// truncateSolidity returns the Solidity expression truncating the bytes32 hash named by the variable
+// like the verifier circuit with the IO commitment does.
+func truncateSolidity(commitment IOCommitment, variable string) string {
+	if commitment.Truncation == IOTruncateReduce {
+		return fmt.Sprintf("uint256(%s) %% %s", variable, ecc.BN254.ScalarField())
+	}
+	return fmt.Sprintf("uint256(%s) & ((1 << 253) - 1)", variable)
+}
+
+// ExportFixedFunctionVerifierSolidity writes FixedFunctionVerifier.sol, the complete FunctionVerifier
+// contract of a fixed verifier circuit compiled with the config. plonky2x uses it instead of
+// generating the FunctionVerifier from Verifier.sol.
+func ExportFixedFunctionVerifierSolidity(path string, vk plonk.VerifyingKey, config CircuitConfig) error {
+	if !config.Fixed() {
+		return fmt.Errorf("the verifier circuit does not have a fixed circuit digest")
+	}
+	buf := new(bytes.Buffer)
+	err := vk.ExportSolidity(buf)
+	if err != nil {
+		return fmt.Errorf("failed to export verifying key to solidity: %w", err)
+	}
+	content := buf.String()
+	content = strings.ReplaceAll(content, "pragma solidity ^0.8.19;", "pragma solidity ^0.8.16;")
+	content = strings.ReplaceAll(content, "function Verify", "function verifyProof")
+	content += strings.NewReplacer(
+		"{CIRCUIT_DIGEST}", fmt.Sprintf("0x%064x", config.FixedDigest.ToInt()),
+		"{TRUNCATE_INPUT_HASH}", truncateSolidity(config.IOCommitment, "_inputHash"),
+		"{TRUNCATE_OUTPUT_HASH}", truncateSolidity(config.IOCommitment, "_outputHash"),
+	).Replace(fixedFunctionVerifierSolidity)
+
+	return os.WriteFile(path+"/FixedFunctionVerifier.sol", []byte(content), 0644)
+}
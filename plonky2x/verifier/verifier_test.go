@@ -1,11 +1,17 @@
 package main
 
 import (
+	"math/big"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/succinctlabs/gnark-plonky2-verifier/types"
 	"github.com/succinctlabs/gnark-plonky2-verifier/variables"
 )
@@ -85,3 +91,37 @@ func TestPlonky2xVerifierCircuit(t *testing.T) {
 		assert.Error(testCase(int64(i)))
 	}
 }
+
+func TestExportFixedFunctionVerifierSolidity(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	r1cs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &fingerprintCircuit{N: 1})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(r1cs)
+	assert.NoError(err)
+	_, vk, err := plonk.Setup(r1cs, srs)
+	assert.NoError(err)
+
+	dir := t.TempDir()
+	digest, _ := new(big.Int).SetString("1234567890123456789012345678901234567890", 10)
+	config := CircuitConfig{FixedDigest: (*hexutil.Big)(digest)}
+	assert.NoError(ExportFixedFunctionVerifierSolidity(dir, vk, config))
+
+	contract, err := os.ReadFile(dir + "/FixedFunctionVerifier.sol")
+	assert.NoError(err)
+	assert.True(strings.Contains(string(contract), "bytes32 public constant CIRCUIT_DIGEST = 0x00000000000000000000000000000003a0c92075c0dbf3b8acbc5f96ce3f0ad2;"))
+	assert.True(strings.Contains(string(contract), "function verifyProof(bytes calldata proof"))
+	assert.True(strings.Contains(string(contract), "new uint256[](2)"))
+	assert.True(strings.Contains(string(contract), "input[1] = uint256(_outputHash) & ((1 << 253) - 1);"))
+	assert.False(strings.Contains(string(contract), "pragma solidity ^0.8.19;"))
+
+	// The hashes are truncated like the verifier circuit of the config does.
+	config.IOCommitment.Truncation = IOTruncateReduce
+	assert.NoError(ExportFixedFunctionVerifierSolidity(dir, vk, config))
+	contract, err = os.ReadFile(dir + "/FixedFunctionVerifier.sol")
+	assert.NoError(err)
+	assert.True(strings.Contains(string(contract), "input[0] = uint256(_inputHash) % 21888242871839275222246405745257275088548364400416034343698204186575808495617;"))
+
+	// Only fixed verifier circuits have a fixed FunctionVerifier.
+	assert.Error(ExportFixedFunctionVerifierSolidity(dir, vk, CircuitConfig{}))
+}
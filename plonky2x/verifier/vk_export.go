@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The formats ExportVerifyingKey writes the verifying key in, and their files.
+var vkExportFiles = map[string]string{
+	"binary":   "vk.bin",
+	"json":     "vk.json",
+	"solidity": "VerifyingKeyConstants.sol",
+	"gateway":  "vk_registration.bin",
+}
+
+// ExportVerifyingKey writes the verifying key to the directory in the given formats, or in all of
+// them for "all":
+//   - binary, the serialization of gnark the key is loaded from,
+//   - json, the key with its named G1 and G2 points,
+//   - solidity, the constants of the key as they are declared in the PLONK verifier,
+//   - gateway, the bytes the verifier is registered with, see VerifyingKeyRegistration.
+func ExportVerifyingKey(dir string, vk plonk.VerifyingKey, format string) error {
+	log := logger.Logger()
+	_vk, ok := vk.(*plonk_bn254.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("unsupported verifying key %T", vk)
+	}
+	formats := []string{format}
+	if format == "all" {
+		formats = []string{"binary", "json", "solidity", "gateway"}
+	}
+	for _, format := range formats {
+		file, ok := vkExportFiles[format]
+		if !ok {
+			return fmt.Errorf("unknown verifying key format %q, expected binary, json, solidity, gateway or all", format)
+		}
+		var data []byte
+		var err error
+		switch format {
+		case "binary":
+			var buf bytes.Buffer
+			_, err = _vk.WriteTo(&buf)
+			data = buf.Bytes()
+		case "json":
+			data, err = json.MarshalIndent(newVerifyingKeyJSON(_vk), "", "  ")
+		case "solidity":
+			data = []byte(verifyingKeySolidity(_vk))
+		case "gateway":
+			data = VerifyingKeyRegistration(_vk)
+			log.Info().Msg("Verifying key hash: " + hexutil.Encode(crypto.Keccak256(data)))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode verifying key as %s: %w", format, err)
+		}
+		if err := os.WriteFile(dir+"/"+file, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		log.Info().Msg("Exported verifying key to " + dir + "/" + file)
+	}
+	return nil
+}
+
+// verifyingKeyConstant is a constant of the PLONK verifier which is taken from the verifying key.
+type verifyingKeyConstant struct {
+	Name  string
+	Value *big.Int
+}
+
+// verifyingKeyConstants returns the constants of the verifying key in the order and with the names
+// they are declared with in the PLONK verifier of gnark.
+func verifyingKeyConstants(vk *plonk_bn254.VerifyingKey) []verifyingKeyConstant {
+	var constants []verifyingKeyConstant
+	add := func(name string, value *big.Int) {
+		constants = append(constants, verifyingKeyConstant{name, value})
+	}
+	addG1 := func(prefix string, p bn254.G1Affine) {
+		add(prefix+"X", p.X.BigInt(new(big.Int)))
+		add(prefix+"Y", p.Y.BigInt(new(big.Int)))
+	}
+	for i, p := range vk.Kzg.G2 {
+		prefix := fmt.Sprintf("G2_SRS_%d_", i)
+		add(prefix+"X_0", p.X.A1.BigInt(new(big.Int)))
+		add(prefix+"X_1", p.X.A0.BigInt(new(big.Int)))
+		add(prefix+"Y_0", p.Y.A1.BigInt(new(big.Int)))
+		add(prefix+"Y_1", p.Y.A0.BigInt(new(big.Int)))
+	}
+	addG1("G1_SRS_", vk.Kzg.G1)
+	add("VK_NB_PUBLIC_INPUTS", new(big.Int).SetUint64(vk.NbPublicVariables))
+	add("VK_DOMAIN_SIZE", new(big.Int).SetUint64(vk.Size))
+	add("VK_INV_DOMAIN_SIZE", vk.SizeInv.BigInt(new(big.Int)))
+	add("VK_OMEGA", vk.Generator.BigInt(new(big.Int)))
+	addG1("VK_QL_COM_", vk.Ql)
+	addG1("VK_QR_COM_", vk.Qr)
+	addG1("VK_QM_COM_", vk.Qm)
+	addG1("VK_QO_COM_", vk.Qo)
+	addG1("VK_QK_COM_", vk.Qk)
+	for i, s := range vk.S {
+		addG1(fmt.Sprintf("VK_S%d_COM_", i+1), s)
+	}
+	add("VK_COSET_SHIFT", vk.CosetShift.BigInt(new(big.Int)))
+	for i, qcp := range vk.Qcp {
+		addG1(fmt.Sprintf("VK_QCP_%d_", i), qcp)
+	}
+	for i, index := range vk.CommitmentConstraintIndexes {
+		add(fmt.Sprintf("VK_INDEX_COMMIT_API%d", i), new(big.Int).SetUint64(index))
+	}
+	add("VK_NB_CUSTOM_GATES", big.NewInt(int64(len(vk.CommitmentConstraintIndexes))))
+	return constants
+}
+
+// verifyingKeySolidity declares the constants of the verifying key in a Solidity library, with the
+// values the PLONK verifier exported for the key is generated with.
+func verifyingKeySolidity(vk *plonk_bn254.VerifyingKey) string {
+	var b strings.Builder
+	b.WriteString("// SPDX-License-Identifier: MIT\npragma solidity ^0.8.16;\n\nlibrary VerifyingKeyConstants {\n")
+	for _, constant := range verifyingKeyConstants(vk) {
+		fmt.Fprintf(&b, "    uint256 internal constant %s = %s;\n", constant.Name, constant.Value)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// VerifyingKeyRegistration returns the bytes a verifier is registered with on the gateway, i.e. the
+// constants of the verifying key ABI encoded as uint256 words in the order of the PLONK verifier.
+// Their keccak256 hash is the verifying key hash.
+func VerifyingKeyRegistration(vk *plonk_bn254.VerifyingKey) []byte {
+	constants := verifyingKeyConstants(vk)
+	data := make([]byte, 32*len(constants))
+	for i, constant := range constants {
+		constant.Value.FillBytes(data[32*i : 32*(i+1)])
+	}
+	return data
+}
+
+// The JSON encoding of the points of a verifying key, where the coordinates of G2 points in Fp2 are
+// ordered (imaginary, real) like in the Solidity verifier.
+type g1PointJSON struct {
+	X *hexutil.Big `json:"x"`
+	Y *hexutil.Big `json:"y"`
+}
+
+type g2PointJSON struct {
+	X [2]*hexutil.Big `json:"x"`
+	Y [2]*hexutil.Big `json:"y"`
+}
+
+// verifyingKeyJSON is the JSON encoding of a verifying key.
+type verifyingKeyJSON struct {
+	Size                        uint64         `json:"size"`
+	SizeInv                     *hexutil.Big   `json:"size_inv"`
+	Generator                   *hexutil.Big   `json:"generator"`
+	NbPublicVariables           uint64         `json:"nb_public_variables"`
+	CosetShift                  *hexutil.Big   `json:"coset_shift"`
+	G1SRS                       g1PointJSON    `json:"g1_srs"`
+	G2SRS                       [2]g2PointJSON `json:"g2_srs"`
+	S                           [3]g1PointJSON `json:"s"`
+	Ql                          g1PointJSON    `json:"ql"`
+	Qr                          g1PointJSON    `json:"qr"`
+	Qm                          g1PointJSON    `json:"qm"`
+	Qo                          g1PointJSON    `json:"qo"`
+	Qk                          g1PointJSON    `json:"qk"`
+	Qcp                         []g1PointJSON  `json:"qcp"`
+	CommitmentConstraintIndexes []uint64       `json:"commitment_constraint_indexes"`
+}
+
+func newVerifyingKeyJSON(vk *plonk_bn254.VerifyingKey) verifyingKeyJSON {
+	frHex := func(e fr.Element) *hexutil.Big {
+		return (*hexutil.Big)(e.BigInt(new(big.Int)))
+	}
+	g1 := func(p bn254.G1Affine) g1PointJSON {
+		return g1PointJSON{(*hexutil.Big)(p.X.BigInt(new(big.Int))), (*hexutil.Big)(p.Y.BigInt(new(big.Int)))}
+	}
+	g2 := func(p bn254.G2Affine) g2PointJSON {
+		return g2PointJSON{
+			X: [2]*hexutil.Big{(*hexutil.Big)(p.X.A1.BigInt(new(big.Int))), (*hexutil.Big)(p.X.A0.BigInt(new(big.Int)))},
+			Y: [2]*hexutil.Big{(*hexutil.Big)(p.Y.A1.BigInt(new(big.Int))), (*hexutil.Big)(p.Y.A0.BigInt(new(big.Int)))},
+		}
+	}
+	encoded := verifyingKeyJSON{
+		Size:                        vk.Size,
+		SizeInv:                     frHex(vk.SizeInv),
+		Generator:                   frHex(vk.Generator),
+		NbPublicVariables:           vk.NbPublicVariables,
+		CosetShift:                  frHex(vk.CosetShift),
+		G1SRS:                       g1(vk.Kzg.G1),
+		G2SRS:                       [2]g2PointJSON{g2(vk.Kzg.G2[0]), g2(vk.Kzg.G2[1])},
+		Ql:                          g1(vk.Ql),
+		Qr:                          g1(vk.Qr),
+		Qm:                          g1(vk.Qm),
+		Qo:                          g1(vk.Qo),
+		Qk:                          g1(vk.Qk),
+		Qcp:                         []g1PointJSON{},
+		CommitmentConstraintIndexes: vk.CommitmentConstraintIndexes,
+	}
+	for i, s := range vk.S {
+		encoded.S[i] = g1(s)
+	}
+	for _, qcp := range vk.Qcp {
+		encoded.Qcp = append(encoded.Qcp, g1(qcp))
+	}
+	if encoded.CommitmentConstraintIndexes == nil {
+		encoded.CommitmentConstraintIndexes = []uint64{}
+	}
+	return encoded
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportVerifyingKey(t *testing.T) {
+	vk, err := LoadVerifierKey(saveTestCircuit(t, CompressionNone), false)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, ExportVerifyingKey(dir, vk, "all"))
+
+	// The binary export is the key in the data directory.
+	exported, err := LoadVerifierKey(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, vk, exported)
+
+	data, err := os.ReadFile(dir + "/vk.json")
+	assert.NoError(t, err)
+	var decoded verifyingKeyJSON
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	_vk := vk.(*plonk_bn254.VerifyingKey)
+	assert.Equal(t, _vk.NbPublicVariables, decoded.NbPublicVariables)
+	assert.Equal(t, _vk.Ql.X.BigInt(new(big.Int)), decoded.Ql.X.ToInt())
+
+	// The constants are the ones of the Solidity verifier generated by gnark.
+	var verifier strings.Builder
+	assert.NoError(t, vk.ExportSolidity(&verifier))
+	constants := verifyingKeyConstants(_vk)
+	for _, constant := range constants {
+		assert.Contains(t, verifier.String(), "uint256 private constant "+constant.Name+" = "+constant.Value.String()+";")
+	}
+	solidity, err := os.ReadFile(dir + "/VerifyingKeyConstants.sol")
+	assert.NoError(t, err)
+	assert.Contains(t, string(solidity), fmt.Sprintf("uint256 internal constant VK_DOMAIN_SIZE = %d;", _vk.Size))
+
+	registration, err := os.ReadFile(dir + "/vk_registration.bin")
+	assert.NoError(t, err)
+	assert.Len(t, registration, 32*len(constants))
+	assert.Equal(t, hexutil.Encode(registration), hexutil.Encode(VerifyingKeyRegistration(_vk)))
+
+	assert.ErrorContains(t, ExportVerifyingKey(dir, vk, "pem"), "unknown verifying key format")
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/consensys/gnark/logger"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/succinctlabs/succinctx/bindings"
+	"github.com/succinctlabs/succinctx/gnarkx/types"
+)
+
+// ArtifactSource provides the plonky2 proof for a request, i.e. the circuitPath directory the
+// wrapper proof is generated from, and the output bytes committed to by that proof.
+type ArtifactSource interface {
+	Fetch(ctx context.Context, functionID [32]byte, input []byte) (circuitPath string, output []byte, err error)
+}
+
+// DirectoryArtifactSource looks up plonky2 proofs in a local directory laid out as
+// <root>/<functionId>/<sha256(input)>/, where each request directory contains the plonky2
+// proof and verifier data and the raw output bytes in output.bin.
+type DirectoryArtifactSource struct {
+	Root string
+}
+
+func (s DirectoryArtifactSource) Fetch(ctx context.Context, functionID [32]byte, input []byte) (string, []byte, error) {
+	inputHash := sha256.Sum256(input)
+	circuitPath := filepath.Join(s.Root, hexutil.Encode(functionID[:]), hexutil.Encode(inputHash[:]))
+	output, err := os.ReadFile(filepath.Join(circuitPath, "output.bin"))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read output: %w", err)
+	}
+	return circuitPath, output, nil
+}
+
+// Watcher subscribes to the requests emitted by the SuccinctGateway contract, proves them with
+// the circuits in the registry and fulfills them on-chain.
+type Watcher struct {
+	gateway   *bindings.SuccinctGatewayFilterer
+	registry  *CircuitRegistry
+	source    ArtifactSource
+	fulfiller *Fulfiller
+
+	// The delay before resubscribing after the subscription drops.
+	RetryInterval time.Duration
+
+	// Retry retries proofs which fail for transient reasons. Requests which can never be proven,
+	// e.g. for malformed plonky2 proofs, are failed without retrying.
+	Retry RetryPolicy
+
+	// Logs can be delivered again after a resubscription, so the most recently handled logs are
+	// remembered, oldest first in seenOrder.
+	seen      map[string]bool
+	seenOrder []string
+}
+
+// The number of handled logs remembered by a Watcher. Logs are only redelivered shortly after a
+// resubscription, so older ones can be forgotten.
+const maxSeenLogs = 10000
+
+func NewWatcher(
+	gatewayAddress common.Address,
+	backend bind.ContractFilterer,
+	registry *CircuitRegistry,
+	source ArtifactSource,
+	fulfiller *Fulfiller,
+) (*Watcher, error) {
+	gateway, err := bindings.NewSuccinctGatewayFilterer(gatewayAddress, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind gateway: %w", err)
+	}
+	return &Watcher{
+		gateway:       gateway,
+		registry:      registry,
+		source:        source,
+		fulfiller:     fulfiller,
+		RetryInterval: 5 * time.Second,
+		Retry:         RetryPolicy{MaxAttempts: 3, Backoff: 30 * time.Second},
+		seen:          make(map[string]bool),
+	}, nil
+}
+
+// Run watches for requests until the context is cancelled. Requests are handled one at a time,
+// in the order they are received.
+func (w *Watcher) Run(ctx context.Context) error {
+	log := logger.Logger()
+	for {
+		err := w.watch(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Err(err).Msg("gateway subscription dropped, resubscribing in " + w.RetryInterval.String())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.RetryInterval):
+		}
+	}
+}
+
+func (w *Watcher) watch(ctx context.Context) error {
+	log := logger.Logger()
+	opts := &bind.WatchOpts{Context: ctx}
+
+	callbacks := make(chan *bindings.SuccinctGatewayRequestCallback)
+	callbackSub, err := w.gateway.WatchRequestCallback(opts, callbacks, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to RequestCallback: %w", err)
+	}
+	defer callbackSub.Unsubscribe()
+
+	calls := make(chan *bindings.SuccinctGatewayRequestCall)
+	callSub, err := w.gateway.WatchRequestCall(opts, calls, nil)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to RequestCall: %w", err)
+	}
+	defer callSub.Unsubscribe()
+
+	log.Info().Msg("Watching for gateway requests")
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-callbackSub.Err():
+			return err
+		case err := <-callSub.Err():
+			return err
+		case req := <-callbacks:
+			if w.markSeen(req.Raw.TxHash, req.Raw.Index) {
+				w.logResult(w.HandleRequestCallback(ctx, req), "callback", req.FunctionId)
+			}
+		case req := <-calls:
+			if w.markSeen(req.Raw.TxHash, req.Raw.Index) {
+				w.logResult(w.HandleRequestCall(ctx, req), "call", req.FunctionId)
+			}
+		}
+	}
+}
+
+func (w *Watcher) markSeen(txHash common.Hash, index uint) bool {
+	key := fmt.Sprintf("%s:%d", txHash.Hex(), index)
+	if w.seen[key] {
+		return false
+	}
+	w.seen[key] = true
+	w.seenOrder = append(w.seenOrder, key)
+	if len(w.seenOrder) > maxSeenLogs {
+		delete(w.seen, w.seenOrder[0])
+		w.seenOrder = w.seenOrder[1:]
+	}
+	return true
+}
+
+func (w *Watcher) logResult(err error, kind string, functionID [32]byte) {
+	log := logger.Logger()
+	var witnessErr *WitnessError
+	if errors.As(err, &witnessErr) {
+		log.Err(err).Msg("rejected unprovable " + kind + " request for function " + hexutil.Encode(functionID[:]))
+		return
+	}
+	if err != nil {
+		log.Err(err).Msg("failed to fulfill " + kind + " request for function " + hexutil.Encode(functionID[:]))
+		return
+	}
+	log.Info().Msg("Fulfilled " + kind + " request for function " + hexutil.Encode(functionID[:]))
+}
+
+// HandleRequestCallback proves and fulfills a single RequestCallback event.
+func (w *Watcher) HandleRequestCallback(ctx context.Context, req *bindings.SuccinctGatewayRequestCallback) error {
+	result, err := w.prove(ctx, req.FunctionId, req.Input)
+	if err != nil {
+		return err
+	}
+	_, err = w.fulfiller.FulfillCallback(ctx, CallbackRequest{
+		Nonce:            req.Nonce,
+		FunctionID:       req.FunctionId,
+		InputHash:        sha256.Sum256(req.Input),
+		CallbackAddress:  req.CallbackAddress,
+		CallbackSelector: req.CallbackSelector,
+		CallbackGasLimit: req.CallbackGasLimit,
+		Context:          req.Context,
+	}, *result)
+	return err
+}
+
+// HandleRequestCall proves and fulfills a single RequestCall event.
+func (w *Watcher) HandleRequestCall(ctx context.Context, req *bindings.SuccinctGatewayRequestCall) error {
+	result, err := w.prove(ctx, req.FunctionId, req.Input)
+	if err != nil {
+		return err
+	}
+	_, err = w.fulfiller.FulfillCall(ctx, CallRequest{
+		FunctionID:      req.FunctionId,
+		Input:           req.Input,
+		CallbackAddress: req.EntryAddress,
+		CallbackData:    req.EntryCalldata,
+	}, *result)
+	return err
+}
+
+func (w *Watcher) prove(ctx context.Context, functionID [32]byte, input []byte) (*types.ProofResult, error) {
+	circuit, err := w.registry.Get(hexutil.Encode(functionID[:]))
+	if err != nil {
+		return nil, fmt.Errorf("no circuit for function %s: %w", hexutil.Encode(functionID[:]), err)
+	}
+	r1cs, pk, vk, err := circuit.Artifacts()
+	if err != nil {
+		return nil, err
+	}
+
+	circuitPath, output, err := w.source.Fetch(ctx, functionID, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artifacts: %w", err)
+	}
+
+	assignment, err := TryLoadAssignment(circuitPath)
+	if err != nil {
+		return nil, err
+	}
+	config := circuit.Config()
+	err = config.CheckAssignment(assignment)
+	if err != nil {
+		return nil, err
+	}
+	err = checkIOHashes(circuitPath, assignment, input, output)
+	if err != nil {
+		return nil, &WitnessError{Err: err}
+	}
+	proof, publicWitness, err := GenerateProofWithRetry(ctx, w.Retry, assignment, config, r1cs, pk)
+	if err != nil {
+		return nil, err
+	}
+	err = VerifyProof(proof, vk, publicWitness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify proof: %w", err)
+	}
+
+	return NewProofResult(assignment, proof, vk, output)
+}
+
+// checkIOHashes checks that the plonky2 proof in circuitPath commits to the input of the request
+// and to the output it is fulfilled with, as the gateway rejects the proof otherwise.
+func checkIOHashes(circuitPath string, assignment *Plonky2xVerifierCircuit, input []byte, output []byte) error {
+	commitment, err := ReadIOCommitment(circuitPath)
+	if err != nil {
+		return err
+	}
+	inputHash, err := commitment.Commit(input)
+	if err != nil {
+		return fmt.Errorf("failed to hash input: %w", err)
+	}
+	if inputHash.Cmp(assignment.InputHash.(*big.Int)) != 0 {
+		return fmt.Errorf("proof commits to input hash %#x, but the request has %#x", assignment.InputHash, inputHash)
+	}
+	outputHash, err := commitment.Commit(output)
+	if err != nil {
+		return fmt.Errorf("failed to hash output: %w", err)
+	}
+	if outputHash.Cmp(assignment.OutputHash.(*big.Int)) != 0 {
+		return fmt.Errorf("proof commits to output hash %#x, but the output has %#x", assignment.OutputHash, outputHash)
+	}
+	return nil
+}
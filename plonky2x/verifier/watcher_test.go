@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcherUnknownFunction(t *testing.T) {
+	registry := NewCircuitRegistry()
+	_, err := registry.Register("default", "testdata", "")
+	assert.NoError(t, err)
+	w := &Watcher{registry: registry, seen: make(map[string]bool)}
+
+	// Requests for functions without a circuit are not proven with another circuit.
+	_, err = w.prove(context.Background(), [32]byte{1}, nil)
+	assert.ErrorContains(t, err, "no circuit for function 0x01")
+}
+
+func TestWatcherMarkSeen(t *testing.T) {
+	w := &Watcher{seen: make(map[string]bool)}
+	txHash := common.HexToHash("0x01")
+	assert.True(t, w.markSeen(txHash, 0))
+	assert.False(t, w.markSeen(txHash, 0))
+
+	// The oldest logs are forgotten once the limit is reached.
+	for i := 1; i <= maxSeenLogs; i++ {
+		assert.True(t, w.markSeen(txHash, uint(i)))
+	}
+	assert.Len(t, w.seen, maxSeenLogs)
+	assert.True(t, w.markSeen(txHash, 0))
+	assert.False(t, w.markSeen(txHash, maxSeenLogs))
+}
+
+func TestCheckIOHashes(t *testing.T) {
+	input := []byte("input")
+	output := []byte("output")
+	hash := func(data []byte) *big.Int {
+		sum := sha256.Sum256(data)
+		sum[0] &= 0x1f
+		return new(big.Int).SetBytes(sum[:])
+	}
+	assignment := &Plonky2xVerifierCircuit{InputHash: hash(input), OutputHash: hash(output)}
+	dir := t.TempDir()
+	assert.NoError(t, checkIOHashes(dir, assignment, input, output))
+
+	// Proofs of other inputs or outputs than the request's are not fulfilled.
+	err := checkIOHashes(dir, assignment, []byte("other"), output)
+	assert.ErrorContains(t, err, "input hash")
+	err = checkIOHashes(dir, assignment, input, []byte("other"))
+	assert.ErrorContains(t, err, fmt.Sprintf("output has %#x", hash([]byte("other"))))
+}